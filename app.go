@@ -4,19 +4,29 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"xlink-wails/internal/api"
+	"xlink-wails/internal/command"
 	"xlink-wails/internal/config"
 	"xlink-wails/internal/dns"
 	"xlink-wails/internal/engine"
 	"xlink-wails/internal/generator"
+	"xlink-wails/internal/i18n"
 	"xlink-wails/internal/logger"
+	"xlink-wails/internal/metrics"
 	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/syschange"
 	"xlink-wails/internal/system"
 )
 
@@ -31,22 +41,129 @@ type App struct {
 	engineManager   *engine.Manager
 	logManager      *logger.Manager
 	pingManager     *logger.PingManager
+	speedTester     *logger.SpeedTester
+	statsManager    *logger.StatsManager
 	dnsManager      *dns.Manager
 	tunManager      *dns.TUNManager
 	leakTester      *dns.LeakTester
+	leakHistory     *dns.LeakHistory
+	latencyHistory  *logger.LatencyHistory
+	serverHealth    *engine.ServerHealth
+	pingReportStore *logger.PingReportStore
+	localResolver   *dns.LocalResolver
+	serverMemory    *engine.ServerMemory
+	crashStore      *engine.CrashStore
 	autoStart       *system.AutoStartManager
 	notification    *system.NotificationManager
+	notifier        *notify.Router
+	notifyCenter    *notify.Center
 	proxyManager    *system.ProxyManager
+	tray            *system.TrayManager
+	commandBus      *command.Bus
+	apiServer       *api.Server
+	metricsServer   *metrics.Server
+	pacServer       *system.PACServer
+	geoData         *dns.GeoDataManager
+	ipGeo           *dns.IPGeoDB
+	journal         *syschange.Journal
+
+	// 启动参数中携带的控制命令（加载配置后执行）
+	pendingCommands []command.Command
+
+	// 自动IP策略测量结果 (key: NodeID)
+	ipProbes  map[string]*dns.IPFamilyProbe
+	ipProbeMu sync.Mutex
+
+	// 配套组件自检结果
+	components  []system.ComponentStatus
+	componentMu sync.Mutex
+
+	// 最近一次 UDP 转发测试结果 (key: NodeID)
+	udpProbes  map[string]*models.UDPProbeResult
+	udpProbeMu sync.Mutex
+
+	// 最近一次系统时间校验结果
+	clock   *system.ClockCheckResult
+	clockMu sync.Mutex
+
+	// 断线保护
+	killSwitch   killSwitchState
+	killSwitchMu sync.Mutex
+
+	// 定时泄露测试
+	leakTestCancel context.CancelFunc
+	leakTestHours  int
+	leakTestMu     sync.Mutex
+
+	// 自动选择最快节点
+	autoSelectState  AutoSelectState
+	autoSelectCancel context.CancelFunc
+	autoSelectMu     sync.Mutex
+
+	// 服务器池健康探测
+	healthState serverHealthState
+
+	// 手动批量测速
+	batchPingCancel context.CancelFunc
+	batchPingMu     sync.Mutex
+
+	// 睡眠唤醒后需要重新应用的系统修改
+	resume   resumeState
+	resumeMu sync.Mutex
+
+	// 已执行过启动钩子的节点（停止时执行停止钩子）
+	hookStarted map[string]bool
+	hookMu      sync.Mutex
+
+	// 各节点最近一次发送通知的状态（节点启动 / 停止 / 出错通知去重）
+	nodeNotified map[string]string
+	nodeNotifyMu sync.Mutex
+
+	// 活动节点的延迟与出口探测结果（迷你窗口）
+	live   liveState
+	liveMu sync.Mutex
+
+	// 各节点最近一次延迟测试结果（指标接口）
+	pingReports  map[string]logger.PingReport
+	pingReportMu sync.Mutex
+
+	// WebDAV 同步（同一时间只运行一次）
+	syncMu sync.Mutex
+
+	// 用户数据目录（启动时已完成旧布局迁移）
+	dataLayout *dataLayout
+
+	// 界面数据目录（启动时已完成迁移与待清理的缓存）
+	webview *system.WebviewPrepareResult
+
+	// 界面语言缓存 (string)
+	lang atomic.Value
+
+	// 正在退出（关闭窗口不再隐藏到托盘）
+	quitting atomic.Bool
+
+	// 内核程序正在更新
+	coreUpdating atomic.Bool
+
+	// 以 Windows 服务无界面运行（不调用 Wails 运行时）
+	headless bool
+
+	// 后台服务正在运行，界面只作为控制前端（节点由服务运行）
+	serviceFront atomic.Bool
 
 	// 取消函数（用于关闭时清理后台任务）
 	cancelFuncs []context.CancelFunc
 	cancelMu    sync.Mutex
+
+	// 本进程运行节点时才执行的后台任务（转为后台服务的控制前端时取消）
+	localCancel context.CancelFunc
 }
 
 // NewApp 创建新的应用实例
 func NewApp() *App {
 	return &App{
-		state: models.NewAppState(),
+		state:    models.NewAppState(),
+		ipProbes: make(map[string]*dns.IPFamilyProbe),
 	}
 }
 
@@ -59,22 +176,64 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
 	// 1. 初始化日志管理器
-	a.logManager = logger.NewManager(a.state.ExeDir)
-	a.logManager.SetCallback(func(entry models.LogEntry) {
-		runtime.EventsEmit(a.ctx, string(models.EventLogAppend), entry)
-	})
+	a.logManager = logger.NewManager(a.state.DataDir)
 
 	a.logManager.LogSystem(logger.LevelInfo, "Xlink 客户端正在启动 v"+models.AppVersion+"...")
+	a.logDataLayout(a.dataLayout)
+
+	// 后台服务运行时，系统修改与节点都归服务管理
+	if !a.headless && system.QueryService().Running() {
+		a.serviceFront.Store(true)
+		a.logManager.LogSystem(logger.LevelInfo, "后台服务正在运行，节点由服务运行")
+	}
+
+	// 撤销上次异常退出时残留的系统修改（先于其他任何操作）
+	if !a.serviceFront.Load() {
+		a.recoverSystemChanges()
+	}
 
 	// 2. 初始化各子模块
 	a.pingManager = logger.NewPingManager(a.state.ExeDir, a.logManager)
-	a.configManager = config.NewManager(a.state.ExeDir)
-	a.configGenerator = generator.NewGenerator(a.state.ExeDir)
+	a.speedTester = logger.NewSpeedTester()
+	a.statsManager = logger.NewStatsManager()
+	a.configManager = config.NewManager(a.state.DataDir)
+	if a.headless {
+		// 配置文件只由界面进程写入，服务只读取，避免两个进程互相覆盖
+		a.configManager.SetReadOnly(true)
+	}
+	a.configGenerator = generator.NewGenerator(a.state.DataDir)
 	a.engineManager = engine.NewManager(a.state.ExeDir)
+	a.serverMemory = engine.NewServerMemory(filepath.Join(a.state.DataDir, ServerMemoryFileName))
+	a.serverHealth = engine.NewServerHealth()
+	a.crashStore = engine.NewCrashStore(filepath.Join(a.state.DataDir, engine.CrashDirName))
+	a.latencyHistory = logger.NewLatencyHistory(filepath.Join(a.state.DataDir, logger.LatencyHistoryFileName))
+	a.pingReportStore = logger.NewPingReportStore(filepath.Join(a.state.DataDir, logger.PingReportDirName))
+	a.pingManager.SetReportCallback(func(report logger.PingReport) {
+		a.rememberPingReport(report)
+		a.rememberPingResult(report)
+		a.latencyHistory.Add(report)
+		if err := a.pingReportStore.Save(report); err != nil {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("保存测速报告失败: %v", err))
+		}
+	})
 	a.dnsManager = dns.NewManager(a.state.ExeDir)
+	a.localResolver = dns.NewLocalResolver(a.dnsManager)
 	a.leakTester = dns.NewLeakTester()
+	a.ipGeo = dns.NewIPGeoDB(a.state.ExeDir)
+	a.leakTester.SetGeoDB(a.ipGeo)
+	a.leakTester.SetClientFunc(a.egressClient)
+	a.leakHistory = dns.NewLeakHistory(filepath.Join(a.state.DataDir, LeakHistoryFileName))
 	a.proxyManager = system.NewProxyManager()
 	a.notification = system.NewNotificationManager(models.AppTitle)
+	a.tray = system.NewTrayManager()
+	a.initNotifications()
+	a.commandBus = command.NewBus()
+	a.registerCommands()
+	a.apiServer = api.NewServer(&apiBackend{app: a})
+	a.metricsServer = metrics.NewServer(a.metricsSnapshot)
+	a.pacServer = system.NewPACServer()
+	a.geoData = dns.NewGeoDataManager(a.state.ExeDir)
+	a.geoData.SetProgressCallback(a.onGeoDataProgress)
 
 	// 初始化 TUN 管理器
 	tunName := "XlinkTUN"
@@ -89,12 +248,30 @@ func (a *App) startup(ctx context.Context) {
 
 	// 3. 设置引擎回调
 	a.engineManager.SetLogCallback(func(nodeID, nodeName, level, category, message string) {
+		switch category {
+		case logger.CategoryStats:
+			// 智能分流时流量由 Xray 统计接口提供，日志只计连接数
+			if a.engineManager.CoreStatsEnabled(nodeID) {
+				a.statsManager.RecordConnection(nodeID, message)
+			} else {
+				a.statsManager.Record(nodeID, message)
+			}
+		case logger.CategoryTunnel:
+			a.rememberTunnel(nodeID, message)
+		}
 		a.logManager.LogNode(nodeID, nodeName, level, category, message)
 	})
+	a.engineManager.SetTrafficCallback(a.statsManager.RecordCounters)
+	a.startStatsLoop()
 
+	a.engineManager.SetCrashCallback(a.saveCrashReport)
 	a.engineManager.SetStatusCallback(func(nodeID, status string, err error) {
 		a.state.UpdateNodeStatus(nodeID, status, "")
 		a.emitNodeStatus(nodeID, status)
+		a.refreshTrayMenu()
+		a.onKillSwitchNodeStatus(nodeID, status)
+		a.onHookNodeStatus(nodeID, status, err)
+		a.onNotifyNodeStatus(nodeID, status, err)
 
 		if err != nil {
 			node := a.state.GetNode(nodeID)
@@ -113,11 +290,36 @@ func (a *App) startup(ctx context.Context) {
 
 	// 5. 加载用户配置
 	a.loadConfig()
+	if !a.serviceFront.Load() {
+		a.cleanupLastSession()
+		a.resumeKillSwitch()
+	}
+	go a.checkComponents()
+	go a.checkFirewall()
+	go a.checkClock()
+	a.startGeoDataLoop()
+	a.startCoreUpdateLoop()
+	a.startIPv6Watcher()
+	a.startResumeWatcher()
+	a.startIPStrategyLoop()
+	if a.headless {
+		a.enableServiceAPI()
+	}
+	if !a.serviceFront.Load() {
+		a.applyAPISettings()
+		a.startLocalTasks()
+	}
+	a.applyMetricsSettings()
+	a.applyServerHealthSettings()
+	if !a.headless {
+		a.startWebviewWatchdog()
+		a.startServiceStatusLoop()
+	}
 
 	// 🚀【核心逻辑】后端自动托管：恢复上次运行的节点
 	// 无论前端是否加载完成，后端都会独立启动代理
 	lastID := a.state.Config.LastRunningNodeID
-	if lastID != "" {
+	if lastID != "" && !a.serviceFront.Load() {
 		go func() {
 			// 稍等片刻，确保资源释放或环境就绪
 			time.Sleep(500 * time.Millisecond)
@@ -128,12 +330,21 @@ func (a *App) startup(ctx context.Context) {
 				if err := a.StartNode(lastID); err != nil {
 					a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("自动恢复失败: %v", err))
 				} else {
-					a.notification.Show(models.AppTitle, fmt.Sprintf("已恢复运行: %s", node.Name))
+					a.notify(notify.EventNode, fmt.Sprintf("已恢复运行: %s", node.Name))
 				}
 			}
 		}()
 	}
 
+	if !a.headless {
+		a.startTray()
+	}
+
+	// 执行命令行携带的控制命令
+	if len(a.pendingCommands) > 0 {
+		go a.runCommands(a.pendingCommands)
+	}
+
 	// 6. 处理系统级开机自启逻辑 (如需隐藏窗口等，可在此处扩展)
 	if a.state.IsAutoStart {
 		// 实际上有了上面的自动恢复，这里主要用于一些 UI 行为，比如自动最小化
@@ -143,32 +354,102 @@ func (a *App) startup(ctx context.Context) {
 	a.logManager.LogSystem(logger.LevelInfo, "系统初始化完成")
 }
 
+// startLocalTasks 启动只在运行节点的进程中执行的后台任务（本机 DNS、定时任务、备份、同步等），
+// 作为后台服务的控制前端时这些任务由服务执行，避免两个进程重复执行
+func (a *App) startLocalTasks() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.localCancel = cancel
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	a.applyLocalDNSSettings()
+	a.applyLeakTestSchedule()
+	a.startScheduler(ctx)
+	a.startBackupScheduler(ctx)
+	a.startLiveStatusLoop(ctx)
+	a.startSyncLoop(ctx)
+
+	a.state.Mu.RLock()
+	autoSelect := a.state.Config.AutoSelectEnabled
+	a.state.Mu.RUnlock()
+	if autoSelect {
+		a.startAutoSelect()
+	}
+}
+
+// stopLocalTasks 交给后台服务运行节点前停止本进程的后台任务
+func (a *App) stopLocalTasks() {
+	a.cancelMu.Lock()
+	cancel := a.localCancel
+	a.localCancel = nil
+	a.cancelMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	a.stopAutoSelect()
+	a.stopLeakTestSchedule()
+	a.localResolver.Stop()
+}
+
 // shutdown 应用关闭时调用
 func (a *App) shutdown(ctx context.Context) {
 	a.logManager.LogSystem(logger.LevelInfo, "正在关闭应用...")
 
-	// 停止 Ping 测试
+	// 关闭控制接口
+	if a.apiServer != nil {
+		a.apiServer.Stop()
+	}
+	if a.metricsServer != nil {
+		a.metricsServer.Stop()
+	}
+	if a.localResolver != nil {
+		a.localResolver.Stop()
+	}
+
+	// 停止自动选择与 Ping 测试
+	a.stopAutoSelect()
 	if a.pingManager != nil {
 		a.pingManager.StopPing()
 	}
+	if a.speedTester != nil {
+		a.speedTester.Stop()
+	}
 
 	// 停止引擎
 	if a.engineManager != nil {
 		a.engineManager.StopAll()
 	}
 
-	// 恢复系统代理
-	if a.proxyManager != nil {
-		a.proxyManager.RestoreSystemProxy()
+	// 恢复系统代理，解除断线保护（作为控制前端时由服务管理）
+	if a.proxyManager != nil && !a.serviceFront.Load() {
+		if err := a.proxyManager.RestoreSystemProxy(); err == nil {
+			a.journalResolve(journalKeyProxy)
+		}
+	}
+	if !a.serviceFront.Load() {
+		a.releaseKillSwitch()
+	}
+	if a.pacServer != nil {
+		a.pacServer.Stop()
+	}
+
+	// 移除托盘图标
+	if a.tray != nil {
+		a.tray.Stop()
 	}
 
-	// 清理临时文件
-	if a.configGenerator != nil {
-		a.configGenerator.CleanupAllConfigs()
+	// 清理生成的配置文件（按保留策略；服务运行中的节点仍在使用）
+	if a.configGenerator != nil && !a.serviceFront.Load() {
+		a.cleanupGeneratedFiles()
 	}
 
-	// 保存配置
+	// 保存配置与尚未写盘的服务器记忆
 	a.saveConfig()
+	if a.serverMemory != nil {
+		a.serverMemory.Flush()
+	}
 
 	// 停止日志
 	if a.logManager != nil {
@@ -188,6 +469,9 @@ func (a *App) shutdown(ctx context.Context) {
 // =============================================================================
 
 func (a *App) ShowWindow() {
+	if a.headless {
+		return
+	}
 	runtime.WindowShow(a.ctx)
 	runtime.WindowUnminimise(a.ctx)
 	runtime.WindowSetAlwaysOnTop(a.ctx, true)
@@ -195,10 +479,17 @@ func (a *App) ShowWindow() {
 }
 
 func (a *App) HideWindow() {
+	if a.headless {
+		return
+	}
 	runtime.WindowHide(a.ctx)
 }
 
 func (a *App) Quit() {
+	a.quitting.Store(true)
+	if a.headless {
+		return
+	}
 	runtime.Quit(a.ctx)
 }
 
@@ -206,7 +497,8 @@ func (a *App) Quit() {
 // 节点管理 API
 // =============================================================================
 
-func (a *App) GetNodes() []models.NodeConfig {
+// GetNodes 按条件筛选、排序并分页返回节点（query 为零值时返回全部节点）
+func (a *App) GetNodes(query models.NodeQuery) models.NodePage {
 	a.state.Mu.RLock()
 	defer a.state.Mu.RUnlock()
 
@@ -220,7 +512,7 @@ func (a *App) GetNodes() []models.NodeConfig {
 			nodes[i].Status = models.StatusStopped
 		}
 	}
-	return nodes
+	return models.QueryNodes(nodes, query)
 }
 
 func (a *App) GetNode(id string) *models.NodeConfig {
@@ -228,74 +520,145 @@ func (a *App) GetNode(id string) *models.NodeConfig {
 }
 
 func (a *App) AddNode(name string) (*models.NodeConfig, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 
-	if len(a.state.Config.Nodes) >= models.MaxNodes {
-		return nil, fmt.Errorf("节点数量已达上限 (%d)", models.MaxNodes)
+	if len(a.state.Config.Nodes) >= a.state.Config.NodeLimit() {
+		return nil, i18n.Errorf("节点数量已达上限 (%d)", a.state.Config.NodeLimit())
 	}
 
 	node := models.NewDefaultNode(name)
+	// 设置过全局IPv6开关时，新节点跟随全局
+	if models.HasGlobalIPv6Settings(a.state.Config) {
+		node.EnableIPv6, node.PreferIPv6, node.DisableIPv6 = a.state.Config.GlobalEnableIPv6, a.state.Config.GlobalPreferIPv6, a.state.Config.GlobalDisableIPv6
+	}
+	if err := models.ValidateIPv6Config(&node); err != nil {
+		return nil, err
+	}
 	a.state.Config.Nodes = append(a.state.Config.Nodes, node)
 
 	go a.saveConfig()
-	// 前端增删列表，需要通知
-	a.emitEvent(models.EventConfigChanged, nil)
+	a.emitNodeEvent(models.EventNodeAdded, node, nil)
 
 	return &node, nil
 }
 
-// UpdateNode 更新节点配置 (⚠️死循环阻断：不广播事件)
+// UpdateNode 更新节点配置
+// 只广播 node:updated（携带变化字段），不再广播 config:changed，避免前端全量刷新导致的死循环
+// IPv6 开关冲突时返回 *models.IPv6ConfigError，不保存
 func (a *App) UpdateNode(node models.NodeConfig) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := models.ValidateIPv6Config(&node); err != nil {
+		return err
+	}
+	if err := models.ValidateKeepAlive(&node); err != nil {
+		return err
+	}
+	if err := models.ValidateBandwidthLimit(&node); err != nil {
+		return err
+	}
+	if err := models.ValidateConnectionPolicy(node.ConnectionPolicy); err != nil {
+		return err
+	}
+	if _, err := dns.ParseXrayTemplate(node.XrayTemplate); err != nil {
+		return err
+	}
+	if err := models.ValidateTUNMTU(&node); err != nil {
+		return err
+	}
+	if err := models.ValidateFixedInternalPort(&node); err != nil {
+		return err
+	}
+	node.AppRoutingApps = models.NormalizeApps(node.AppRoutingApps)
+	if err := models.ValidateAppRouting(&node); err != nil {
+		return err
+	}
+	if err := models.ValidateFrontEngine(&node); err != nil {
+		return err
+	}
+	node.LANShare.Username = strings.TrimSpace(node.LANShare.Username)
+	node.LANShare.AllowedClients = models.NormalizeTags(node.LANShare.AllowedClients)
+	if err := models.ValidateLANShare(&node); err != nil {
+		return err
+	}
+	node.Tags = models.NormalizeTags(node.Tags)
+	node.Group = strings.TrimSpace(node.Group)
+
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 
+	if err := models.ValidateChain(a.state.Config.Nodes, &node); err != nil {
+		return err
+	}
+
 	for i := range a.state.Config.Nodes {
 		if a.state.Config.Nodes[i].ID == node.ID {
 			node.Status = a.state.Config.Nodes[i].Status
 			node.InternalPort = a.state.Config.Nodes[i].InternalPort
+			fields := models.DiffNodeFields(&a.state.Config.Nodes[i], &node)
 			a.state.Config.Nodes[i] = node
 
+			if len(fields) == 0 {
+				return nil
+			}
+
 			go a.saveConfig()
-			
-			// ❌ 不要广播，防止死循环
-			// a.emitEvent(models.EventConfigChanged, nil)
-			
+			a.emitNodeEvent(models.EventNodeUpdated, node, fields)
+			if bandwidthChanged(fields) {
+				go a.applyBandwidthLimit(node)
+			}
+			if serverPoolChanged(fields) {
+				go a.serverMemory.Forget(node.ID)
+			}
+
 			return nil
 		}
 	}
-	return fmt.Errorf("节点不存在: %s", node.ID)
+	return i18n.Errorf("节点不存在: %s", node.ID)
 }
 
 func (a *App) DeleteNode(id string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 
 	if es, ok := a.state.EngineStatuses[id]; ok && es.Status == models.StatusRunning {
-		return fmt.Errorf("请先停止节点再删除")
+		return i18n.Errorf("请先停止节点再删除")
+	}
+	if users := a.chainUsersLocked(id); len(users) > 0 {
+		return i18n.Errorf("节点 %s 以该节点为前置节点，请先修改", strings.Join(users, ", "))
 	}
 
 	for i := range a.state.Config.Nodes {
 		if a.state.Config.Nodes[i].ID == id {
+			a.backupBeforeChange(config.BackupReasonDelete)
 			a.state.Config.Nodes = append(a.state.Config.Nodes[:i], a.state.Config.Nodes[i+1:]...)
-			delete(a.state.EngineStatuses, id)
-			go a.configGenerator.CleanupConfigs(id)
+			a.forgetNode(id)
 			go a.saveConfig()
-			
-			// 删除操作需要通知前端刷新列表
-			a.emitEvent(models.EventConfigChanged, nil)
+
+			a.emitEvent(models.EventNodeDeleted, models.NodeEventPayload{NodeID: id})
 			return nil
 		}
 	}
-	return fmt.Errorf("节点不存在: %s", id)
+	return i18n.Errorf("节点不存在: %s", id)
 }
 
 func (a *App) DuplicateNode(id string) (*models.NodeConfig, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 
-	if len(a.state.Config.Nodes) >= models.MaxNodes {
-		return nil, fmt.Errorf("节点数量已达上限")
+	if len(a.state.Config.Nodes) >= a.state.Config.NodeLimit() {
+		return nil, i18n.Errorf("节点数量已达上限 (%d)", a.state.Config.NodeLimit())
 	}
 
 	var srcNode *models.NodeConfig
@@ -307,24 +670,126 @@ func (a *App) DuplicateNode(id string) (*models.NodeConfig, error) {
 	}
 
 	if srcNode == nil {
-		return nil, fmt.Errorf("节点不存在: %s", id)
+		return nil, i18n.Errorf("节点不存在: %s", id)
 	}
 
 	newNode := *srcNode
 	newNode.ID = models.GenerateUUID()
 	newNode.Name = srcNode.Name + " (副本)"
+	newNode.SubscriptionID = "" // 副本不随订阅刷新
 	newNode.Status = models.StatusStopped
 	newNode.Rules = make([]models.RoutingRule, len(srcNode.Rules))
 	copy(newNode.Rules, srcNode.Rules)
+	newNode.RuleGroupIDs = append([]string(nil), srcNode.RuleGroupIDs...)
 
 	a.state.Config.Nodes = append(a.state.Config.Nodes, newNode)
 
 	go a.saveConfig()
-	a.emitEvent(models.EventConfigChanged, nil)
+	a.emitNodeEvent(models.EventNodeAdded, newNode, nil)
 
 	return &newNode, nil
 }
 
+// FindDuplicateNodes 查找端点和凭据完全相同的节点，返回合并建议（不做修改）
+func (a *App) FindDuplicateNodes() []models.DuplicateGroup {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+
+	return config.FindDuplicates(a.state.Config.Nodes, func(id string) bool {
+		es, ok := a.state.EngineStatuses[id]
+		return ok && es.Status == models.StatusRunning
+	})
+}
+
+// DeduplicateNodes 执行前端确认后的合并建议，返回删除的节点数量
+// 保留节点的规则不变，重复节点中独有的规则会追加到保留节点；以重复节点为前置节点的节点改用保留节点
+func (a *App) DeduplicateNodes(groups []models.DuplicateGroup) (int, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
+	}
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	// 在副本上合并，全部分组校验通过后才替换配置，出错时不留下部分合并的结果
+	nodes := append([]models.NodeConfig(nil), a.state.Config.Nodes...)
+	indexOf := func(id string) int {
+		for i := range nodes {
+			if nodes[i].ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	survivorOf := make(map[string]string) // 重复节点 → 保留节点
+	for _, g := range groups {
+		si := indexOf(g.SurvivorID)
+		if si == -1 {
+			return 0, i18n.Errorf("节点不存在: %s", g.SurvivorID)
+		}
+		key := config.NodeFingerprint(&nodes[si])
+
+		var dups []models.NodeConfig
+		for _, id := range g.DuplicateIDs {
+			di := indexOf(id)
+			if di == -1 || id == g.SurvivorID {
+				continue
+			}
+			if config.NodeFingerprint(&nodes[di]) != key {
+				return 0, i18n.Errorf("节点 %s 与保留节点不一致，请重新检测", nodes[di].Name)
+			}
+			if es, ok := a.state.EngineStatuses[id]; ok && es.Status == models.StatusRunning {
+				return 0, i18n.Errorf("节点 %s 正在运行，请先停止", nodes[di].Name)
+			}
+			dups = append(dups, nodes[di])
+		}
+
+		survivor := &nodes[si]
+		survivor.Rules = append([]models.RoutingRule(nil), survivor.Rules...)
+		survivor.RuleGroupIDs = append([]string(nil), survivor.RuleGroupIDs...)
+		config.MergeRules(survivor, dups, a.state.Config.RuleLimit())
+		for _, d := range dups {
+			survivorOf[d.ID] = g.SurvivorID
+		}
+	}
+
+	if len(survivorOf) == 0 {
+		return 0, nil
+	}
+	// 备份取自磁盘上的配置文件，仍是合并规则之前的内容
+	a.backupBeforeChange(config.BackupReasonDelete)
+
+	before := make(map[string]models.NodeConfig, len(a.state.Config.Nodes))
+	for _, n := range a.state.Config.Nodes {
+		before[n.ID] = n
+	}
+	kept := nodes[:0]
+	for _, n := range nodes {
+		if _, dup := survivorOf[n.ID]; dup {
+			continue
+		}
+		if s, ok := survivorOf[n.ChainNodeID]; ok {
+			n.ChainNodeID = s
+		}
+		kept = append(kept, n)
+	}
+	a.state.Config.Nodes = kept
+
+	for id := range survivorOf {
+		a.forgetNode(id)
+		a.emitEvent(models.EventNodeDeleted, models.NodeEventPayload{NodeID: id})
+	}
+	for _, n := range kept {
+		old := before[n.ID]
+		if fields := models.DiffNodeFields(&old, &n); len(fields) > 0 {
+			a.emitNodeEvent(models.EventNodeUpdated, n, fields)
+		}
+	}
+	go a.saveConfig()
+
+	return len(survivorOf), nil
+}
+
 // =============================================================================
 // 节点控制 API (启动/停止)
 // =============================================================================
@@ -333,19 +798,13 @@ func (a *App) DuplicateNode(id string) (*models.NodeConfig, error) {
 func (a *App) StartNode(id string) error {
 	node := a.state.GetNode(id)
 	if node == nil {
-		return fmt.Errorf("节点不存在: %s", id)
+		return i18n.Errorf("节点不存在: %s", id)
 	}
-
-	a.logManager.LogNode(id, node.Name, logger.LevelInfo, logger.CategorySystem, "正在启动...")
-
-	configPath, err := a.generateNodeConfig(node)
-	if err != nil {
-		errMsg := fmt.Sprintf("生成配置失败: %v", err)
-		a.logManager.LogNode(id, node.Name, logger.LevelError, logger.CategorySystem, errMsg)
-		return fmt.Errorf(errMsg)
+	if a.serviceFront.Load() {
+		return a.forwardNodeStart(id)
 	}
 
-	if err := a.engineManager.StartNode(node, configPath); err != nil {
+	if err := a.startNodeChain(id); err != nil {
 		return err
 	}
 
@@ -358,11 +817,42 @@ func (a *App) StartNode(id string) error {
 	return nil
 }
 
-// StopNode 停止指定节点
+// startNodeChain 启动节点，前置节点未运行时先启动前置节点（不记录为上次运行的节点）
+func (a *App) startNodeChain(id string) error {
+	a.state.Mu.RLock()
+	nodes := make([]models.NodeConfig, len(a.state.Config.Nodes))
+	copy(nodes, a.state.Config.Nodes)
+	a.state.Mu.RUnlock()
+
+	return a.engineManager.StartChain(nodes, id, a.prepareNodeStart)
+}
+
+// prepareNodeStart 生成节点配置，返回要交给引擎启动的节点与配置文件路径
+func (a *App) prepareNodeStart(id string) (*models.NodeConfig, string, error) {
+	node := a.state.GetNode(id)
+	if node == nil {
+		return nil, "", i18n.Errorf("节点不存在: %s", id)
+	}
+
+	a.logManager.LogNode(id, node.Name, logger.LevelInfo, logger.CategorySystem, "正在启动...")
+
+	configPath, err := a.generateNodeConfig(node)
+	if err != nil {
+		errMsg := fmt.Sprintf("生成配置失败: %v", err)
+		a.logManager.LogNode(id, node.Name, logger.LevelError, logger.CategorySystem, errMsg)
+		return nil, "", i18n.Errorf("生成配置失败: %w", err)
+	}
+	return node, configPath, nil
+}
+
+// StopNode 停止指定节点（以该节点为前置节点的节点同时停止）
 func (a *App) StopNode(id string) error {
 	node := a.state.GetNode(id)
 	if node == nil {
-		return fmt.Errorf("节点不存在: %s", id)
+		return i18n.Errorf("节点不存在: %s", id)
+	}
+	if a.serviceFront.Load() {
+		return a.forwardNodeStop(id)
 	}
 
 	a.logManager.LogNode(id, node.Name, logger.LevelInfo, logger.CategorySystem, "正在停止...")
@@ -380,25 +870,37 @@ func (a *App) StopNode(id string) error {
 	return err
 }
 
-// StartAllNodes 启动所有节点
+// StartAllNodes 启动所有节点（前置节点先启动）
 func (a *App) StartAllNodes() error {
 	a.state.Mu.RLock()
 	nodes := make([]models.NodeConfig, len(a.state.Config.Nodes))
 	copy(nodes, a.state.Config.Nodes)
 	a.state.Mu.RUnlock()
 
+	order, invalid := engine.StartOrder(nodes)
 	var lastErr error
 	for _, node := range nodes {
-		if err := a.StartNode(node.ID); err != nil {
+		if err, ok := invalid[node.ID]; ok {
 			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("启动节点 %s 失败: %v", node.Name, err))
 			lastErr = err
 		}
 	}
+	for _, id := range order {
+		if err := a.StartNode(id); err != nil {
+			if node := a.state.GetNode(id); node != nil {
+				a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("启动节点 %s 失败: %v", node.Name, err))
+			}
+			lastErr = err
+		}
+	}
 	return lastErr
 }
 
 // StopAllNodes 停止所有节点
 func (a *App) StopAllNodes() error {
+	if a.serviceFront.Load() {
+		return a.forwardNodeStop("")
+	}
 	a.engineManager.StopAll()
 	
 	// 清除记录
@@ -414,7 +916,7 @@ func (a *App) StopAllNodes() error {
 func (a *App) PingTest(id string) error {
 	node := a.state.GetNode(id)
 	if node == nil {
-		return fmt.Errorf("节点不存在: %s", id)
+		return i18n.Errorf("节点不存在: %s", id)
 	}
 
 	a.logManager.LogNode(id, node.Name, logger.LevelInfo, logger.CategoryPing, "正在启动延迟测试...")
@@ -442,16 +944,27 @@ func (a *App) StopPingTest() {
 	a.pingManager.StopPing()
 }
 
+// BatchPingTest 批量测试全部节点（按配置的并发数同时测试），重复调用时取消上一次批量测试
 func (a *App) BatchPingTest() error {
 	a.state.Mu.RLock()
 	nodes := make([]*models.NodeConfig, len(a.state.Config.Nodes))
 	for i := range a.state.Config.Nodes {
-		nodes[i] = &a.state.Config.Nodes[i]
+		nodeCopy := a.state.Config.Nodes[i]
+		nodes[i] = &nodeCopy
 	}
 	a.state.Mu.RUnlock()
 
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.batchPingMu.Lock()
+	if a.batchPingCancel != nil {
+		a.batchPingCancel()
+	}
+	a.batchPingCancel = cancel
+	a.batchPingMu.Unlock()
+
 	go func() {
-		results := a.pingManager.BatchPing(nodes, func(current, total int, result logger.BatchPingResult) {
+		defer cancel()
+		results := a.pingManager.BatchPing(ctx, nodes, a.batchPingOptions(), func(current, total int, result logger.BatchPingResult) {
 			a.emitEvent(models.EventPingBatchProgress, map[string]interface{}{
 				"current": current,
 				"total":   total,
@@ -463,11 +976,81 @@ func (a *App) BatchPingTest() error {
 	return nil
 }
 
+// StopBatchPingTest 取消正在进行的批量测试（已完成的结果仍会随完成事件返回）
+func (a *App) StopBatchPingTest() {
+	a.batchPingMu.Lock()
+	defer a.batchPingMu.Unlock()
+	if a.batchPingCancel != nil {
+		a.batchPingCancel()
+		a.batchPingCancel = nil
+	}
+}
+
+// SetBatchPingOptions 设置批量测速的并发数和单个节点的超时（秒），0 表示使用默认值
+func (a *App) SetBatchPingOptions(concurrency, timeoutSeconds int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if concurrency < 0 || concurrency > logger.MaxBatchConcurrency {
+		return i18n.Errorf("并发数必须在 0-%d 之间", logger.MaxBatchConcurrency)
+	}
+	if timeoutSeconds < 0 {
+		return i18n.Errorf("超时不能为负数")
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.PingConcurrency = concurrency
+	a.state.Config.PingTimeout = timeoutSeconds
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// SetPingMode 设置测速方式（core / tcp / icmp），内核握手失败时可改用 TCP 或 ICMP 判断服务器是否可达
+func (a *App) SetPingMode(mode string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if !logger.IsValidPingMode(mode) {
+		return i18n.Errorf("未知的测速方式: %s", mode)
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.PingMode = mode
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.pingManager.SetMode(mode)
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// batchPingOptions 读取批量测速配置（未设置的项由 PingManager 补全默认值）
+func (a *App) batchPingOptions() logger.BatchPingOptions {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return logger.BatchPingOptions{
+		Concurrency: a.state.Config.PingConcurrency,
+		NodeTimeout: time.Duration(a.state.Config.PingTimeout) * time.Second,
+	}
+}
+
 func (a *App) GetNodeStatus(id string) string {
+	if a.serviceFront.Load() {
+		if st, ok := a.serviceStatuses()[id]; ok {
+			return st.Status
+		}
+		return models.StatusStopped
+	}
 	return a.engineManager.GetStatus(id)
 }
 
 func (a *App) GetAllNodeStatuses() map[string]models.EngineStatus {
+	if a.serviceFront.Load() {
+		return a.serviceStatuses()
+	}
 	return a.engineManager.GetAllStatuses()
 }
 
@@ -476,6 +1059,9 @@ func (a *App) GetAllNodeStatuses() map[string]models.EngineStatus {
 // =============================================================================
 
 func (a *App) AddRule(nodeID string, rule models.RoutingRule) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 	for i := range a.state.Config.Nodes {
@@ -483,13 +1069,17 @@ func (a *App) AddRule(nodeID string, rule models.RoutingRule) error {
 			rule.ID = models.GenerateUUID()
 			a.state.Config.Nodes[i].Rules = append(a.state.Config.Nodes[i].Rules, rule)
 			go a.saveConfig()
+			a.emitEvent(models.EventRuleAdded, models.RuleEventPayload{NodeID: nodeID, RuleID: rule.ID, Rule: &rule})
 			return nil
 		}
 	}
-	return fmt.Errorf("节点不存在")
+	return i18n.Errorf("节点不存在")
 }
 
 func (a *App) UpdateRule(nodeID string, rule models.RoutingRule) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 	for i := range a.state.Config.Nodes {
@@ -498,16 +1088,20 @@ func (a *App) UpdateRule(nodeID string, rule models.RoutingRule) error {
 				if a.state.Config.Nodes[i].Rules[j].ID == rule.ID {
 					a.state.Config.Nodes[i].Rules[j] = rule
 					go a.saveConfig()
+					a.emitEvent(models.EventRuleUpdated, models.RuleEventPayload{NodeID: nodeID, RuleID: rule.ID, Rule: &rule})
 					return nil
 				}
 			}
-			return fmt.Errorf("规则不存在")
+			return i18n.Errorf("规则不存在")
 		}
 	}
-	return fmt.Errorf("节点不存在")
+	return i18n.Errorf("节点不存在")
 }
 
 func (a *App) DeleteRule(nodeID, ruleID string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 	for i := range a.state.Config.Nodes {
@@ -517,13 +1111,25 @@ func (a *App) DeleteRule(nodeID, ruleID string) error {
 				if rules[j].ID == ruleID {
 					a.state.Config.Nodes[i].Rules = append(rules[:j], rules[j+1:]...)
 					go a.saveConfig()
+					a.emitEvent(models.EventRuleDeleted, models.RuleEventPayload{NodeID: nodeID, RuleID: ruleID})
 					return nil
 				}
 			}
-			return fmt.Errorf("规则不存在")
+			return i18n.Errorf("规则不存在")
 		}
 	}
-	return fmt.Errorf("节点不存在")
+	return i18n.Errorf("节点不存在")
+}
+
+// GetEffectiveRuleChain 返回节点最终生效的有序规则链（含内置规则）
+func (a *App) GetEffectiveRuleChain(nodeID string) ([]dns.RuleChainEntry, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+	hasGeosite := a.dnsManager.FileExists("geosite.dat")
+	hasGeoip := a.dnsManager.FileExists("geoip.dat")
+	return a.dnsManager.GetEffectiveRuleChain(a.ruleGroupNode(node), hasGeosite, hasGeoip), nil
 }
 
 func (a *App) GetPresetRules(presetName string) []string {
@@ -535,12 +1141,16 @@ func (a *App) GetAllPresets() []string {
 }
 
 func (a *App) ApplyPreset(nodeID, presetName string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	rules := generator.GetPresetRules(presetName)
-	if rules == nil { return fmt.Errorf("预设不存在") }
+	if rules == nil { return i18n.Errorf("预设不存在") }
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 	for i := range a.state.Config.Nodes {
 		if a.state.Config.Nodes[i].ID == nodeID {
+			a.backupBeforeChange(config.BackupReasonPreset)
 			for _, ruleStr := range rules {
 				parts := strings.SplitN(ruleStr, ",", 2)
 				if len(parts) != 2 { continue }
@@ -554,23 +1164,47 @@ func (a *App) ApplyPreset(nodeID, presetName string) error {
 				a.state.Config.Nodes[i].Rules = append(a.state.Config.Nodes[i].Rules, rule)
 			}
 			go a.saveConfig()
+			a.emitNodeEvent(models.EventNodeUpdated, a.state.Config.Nodes[i], []string{"rules"})
 			return nil
 		}
 	}
-	return fmt.Errorf("节点不存在")
+	return i18n.Errorf("节点不存在")
 }
 
 func (a *App) ImportFromClipboard() (int, error) {
-	text, err := runtime.ClipboardGetText(a.ctx)
-	if err != nil { return 0, err }
-	imported, err := a.configManager.ImportNodes(text)
+	result, err := a.ImportFromClipboardWithReport()
 	if err != nil { return 0, err }
+	return result.Count, nil
+}
+
+// ImportFromClipboardWithReport 从剪贴板导入节点，并返回第三方链接中被忽略的字段
+func (a *App) ImportFromClipboardWithReport() (*config.ImportResult, error) {
+	text, err := runtime.ClipboardGetText(a.ctx)
+	if err != nil { return nil, err }
+	return a.ImportNodesFromText(text)
+}
+
+// ImportNodesFromText 从文本导入节点（xlink:// vmess:// vless:// trojan:// ss://）
+func (a *App) ImportNodesFromText(text string) (*config.ImportResult, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	_, result, err := a.configManager.ImportNodesWithReport(text)
+	for _, issue := range result.Issues {
+		if issue.Error != "" {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("导入 %s 链接失败: %s", issue.Scheme, issue.Error))
+		} else {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("导入节点 [%s] 忽略了不支持的字段: %s", issue.Name, strings.Join(issue.Unsupported, ", ")))
+		}
+	}
+	if err != nil { return result, err }
+	a.logImportLimit(result.Skipped, result.Limit)
 	a.state.Mu.Lock()
 	a.state.Config = a.configManager.GetConfig()
 	a.state.Mu.Unlock()
 	go a.saveConfig()
 	a.emitEvent(models.EventConfigChanged, nil)
-	return len(imported), nil
+	return result, nil
 }
 
 func (a *App) ExportToClipboard(id string) error {
@@ -587,39 +1221,124 @@ func (a *App) ExportAllToClipboard() error {
 	for _, node := range nodes {
 		if uri, err := a.configManager.ExportNode(node.ID); err == nil { uris = append(uris, uri) }
 	}
-	if len(uris) == 0 { return fmt.Errorf("没有节点") }
+	if len(uris) == 0 { return i18n.Errorf("没有节点") }
 	return runtime.ClipboardSetText(a.ctx, strings.Join(uris, "\n"))
 }
 
 func (a *App) ListBackups() []string { return a.configManager.ListBackups() }
 
 func (a *App) RestoreBackup(backupName string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	if err := a.configManager.RestoreBackup(backupName); err != nil { return err }
+	a.reloadConfig()
+	return nil
+}
+
+// ExportBackup 将当前配置加密导出到文件（password 为空时使用本机配置密钥）
+func (a *App) ExportBackup(password string) (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: fmt.Sprintf("xlink_backup_%s.xbak", time.Now().Format("20060102")),
+		Filters:         []runtime.FileFilter{{DisplayName: "Xlink 备份 (*.xbak)", Pattern: "*.xbak"}},
+	})
+	if err != nil || path == "" { return "", err }
+	if err := a.configManager.ExportBackup(path, password); err != nil { return "", err }
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已导出加密备份: %s", path))
+	return path, nil
+}
+
+// ImportBackup 从导出的加密备份恢复配置
+func (a *App) ImportBackup(password string) (string, error) {
+	if err := a.checkWritable(); err != nil {
+		return "", err
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Filters: []runtime.FileFilter{{DisplayName: "Xlink 备份 (*.xbak)", Pattern: "*.xbak"}},
+	})
+	if err != nil || path == "" { return "", err }
+	if err := a.configManager.ImportBackup(path, password); err != nil { return "", err }
+	a.reloadConfig()
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已从备份恢复配置: %s", path))
+	return path, nil
+}
+
+// reloadConfig 配置被整体替换后同步到应用状态（恢复备份，或服务收到界面的修改通知）
+// 本机 DNS、指标接口与自动选择只在设置变化时重新启动，避免每次通知都中断服务
+func (a *App) reloadConfig() {
+	cfg := a.configManager.GetConfig()
 	a.state.Mu.Lock()
-	a.state.Config = a.configManager.GetConfig()
+	old := a.state.Config
+	a.state.Config = cfg
 	a.state.Mu.Unlock()
+	a.applyConfigSettings(cfg)
+
+	if old.LocalDNS.Enabled != cfg.LocalDNS.Enabled || !slices.Equal(old.LocalDNS.Upstream, cfg.LocalDNS.Upstream) {
+		a.applyLocalDNSSettings()
+	}
+	if old.Metrics != cfg.Metrics {
+		a.applyMetricsSettings()
+	}
+	a.applyLeakTestSchedule()
+	a.applyServerHealthSettings()
+	if old.AutoSelectEnabled != cfg.AutoSelectEnabled || old.AutoSelectInterval != cfg.AutoSelectInterval ||
+		old.AutoSelectThreshold != cfg.AutoSelectThreshold {
+		if cfg.AutoSelectEnabled {
+			a.startAutoSelect()
+		} else {
+			a.stopAutoSelect()
+		}
+	}
+
+	oldNodes := make(map[string]models.NodeConfig, len(old.Nodes))
+	for _, n := range old.Nodes {
+		oldNodes[n.ID] = n
+	}
+	for _, n := range cfg.Nodes {
+		if o, ok := oldNodes[n.ID]; ok && (o.UploadLimit != n.UploadLimit || o.DownloadLimit != n.DownloadLimit) {
+			a.applyBandwidthLimit(n)
+		}
+	}
 	a.emitEvent(models.EventConfigChanged, nil)
-	return nil
 }
 
 func (a *App) GetSettings() models.AppConfig {
 	a.state.Mu.RLock()
 	defer a.state.Mu.RUnlock()
-	return *a.state.Config
+	cfg := *a.state.Config
+	cfg.Kiosk.PasswordHash, cfg.Kiosk.PasswordSalt = "", "" // 密码哈希不发给前端
+	return cfg
 }
 
+// UpdateSettings 保存设置页的常规设置，只复制下列字段（节点、规则组和其他设置通过各自的专用接口维护）
 func (a *App) UpdateSettings(cfg models.AppConfig) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := models.ValidateGlobalIPv6Settings(&cfg); err != nil {
+		return err
+	}
 	a.state.Mu.Lock()
-	cfg.Nodes = a.state.Config.Nodes
-	cfg.LastRunningNodeID = a.state.Config.LastRunningNodeID // 保护运行记录
-	a.state.Config = &cfg
+	c := a.state.Config
+	c.AutoStart = cfg.AutoStart
+	c.MinimizeToTray = cfg.MinimizeToTray
+	c.Theme = cfg.Theme
+	c.GlobalDNSMode = cfg.GlobalDNSMode
+	c.TUNInterfaceName = cfg.TUNInterfaceName
+	c.GlobalEnableIPv6 = cfg.GlobalEnableIPv6
+	c.GlobalPreferIPv6 = cfg.GlobalPreferIPv6
+	c.GlobalDisableIPv6 = cfg.GlobalDisableIPv6
 	a.state.Mu.Unlock()
 	go a.saveConfig()
+	a.emitEvent(models.EventSettingsChanged, nil)
 	return nil
 }
 
 func (a *App) SetAutoStart(enabled bool) error {
-	if a.autoStart == nil { return fmt.Errorf("自启未初始化") }
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.autoStart == nil { return i18n.Errorf("自启未初始化") }
 	var err error
 	if enabled { err = a.autoStart.Enable() } else { err = a.autoStart.Disable() }
 	if err != nil { return err }
@@ -644,15 +1363,25 @@ func (a *App) GetDNSModes() []map[string]interface{} {
 }
 
 func (a *App) TestDNSLeak() (*dns.LeakTestResult, error) {
-	return a.leakTester.RunTest()
+	return a.runLeakTest(false)
 }
 
 func (a *App) QuickDNSLeakCheck(nodeID string) (map[string]interface{}, error) {
 	node := a.state.GetNode(nodeID)
-	if node == nil { return nil, fmt.Errorf("节点不存在") }
-	isChina, ip, err := a.leakTester.QuickLeakCheck(node.Listen)
+	if node == nil { return nil, i18n.Errorf("节点不存在") }
+	proxy := nodeSocksDialer(node)
+	exit, err := a.leakTester.QuickLeakCheck(proxy)
 	if err != nil { return nil, err }
-	return map[string]interface{}{"ip": ip, "is_leaked": isChina}, nil
+	probe := a.leakTester.ProbeDNS(proxy)
+	return map[string]interface{}{
+		"ip":           exit.IP,
+		"is_leaked":    exit.IsChina() || probe.Source.IsChina(),
+		"country_code": exit.CountryCode,
+		"country":      exit.Country,
+		"asn":          exit.ASN,
+		"as_org":       exit.ASOrg,
+		"dns_probe":    probe,
+	}, nil
 }
 
 func (a *App) IsTUNSupported() map[string]interface{} {
@@ -662,6 +1391,9 @@ func (a *App) IsTUNSupported() map[string]interface{} {
 }
 
 func (a *App) UpdateDNSConfig(nodeID string, mode int, enableSniffing bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	a.state.Mu.Lock()
 	defer a.state.Mu.Unlock()
 	for i := range a.state.Config.Nodes {
@@ -669,36 +1401,179 @@ func (a *App) UpdateDNSConfig(nodeID string, mode int, enableSniffing bool) erro
 			a.state.Config.Nodes[i].DNSMode = mode
 			a.state.Config.Nodes[i].EnableSniffing = enableSniffing
 			go a.saveConfig()
+			a.emitNodeEvent(models.EventNodeUpdated, a.state.Config.Nodes[i], []string{"dns_mode", "enable_sniffing"})
 			return nil
 		}
 	}
-	return fmt.Errorf("节点不存在")
+	return i18n.Errorf("节点不存在")
 }
 
 func (a *App) ClearFakeIPCache() { a.dnsManager.ClearFakeIPCache() }
-func (a *App) FlushDNSCache() error { return a.tunManager.FlushDNSCache() }
+func (a *App) FlushDNSCache() error { a.dnsManager.FlushDNSCache(); return a.tunManager.FlushDNSCache() }
 
-func (a *App) GetLogs(limit int) []models.LogEntry { return a.logManager.GetLogs(limit) }
-func (a *App) GetLogsByNode(nodeID string, limit int) []models.LogEntry { return a.logManager.GetLogsByNode(nodeID, limit) }
+func (a *App) GetLogs(limit int) []models.LogEntry { return logger.Localize(a.logManager.GetLogs(limit), a.language()) }
+func (a *App) GetLogsByNode(nodeID string, limit int) []models.LogEntry { return logger.Localize(a.logManager.GetLogsByNode(nodeID, limit), a.language()) }
+func (a *App) QueryLogs(filter models.LogFilter) []models.LogEntry { return logger.Localize(a.logManager.Query(filter), a.language()) }
+func (a *App) GetLogCategories() []logger.LogCategory { return logger.Categories(a.language()) }
 func (a *App) ClearLogs() { a.logManager.Clear() }
 func (a *App) ExportLogs(format string) (string, error) {
 	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{DefaultFilename: "logs." + format})
 	if err != nil || path == "" { return "", err }
-	return path, a.logManager.ExportToFile(path, format)
+	return path, a.logManager.ExportToFile(path, format, a.language())
+}
+
+// language 界面语言（用于日志类别等显示名称）
+// 日志回调可能在持有 state.Mu 时触发，因此单独缓存而不读取配置
+func (a *App) language() string {
+	if lang, ok := a.lang.Load().(string); ok && lang != "" {
+		return lang
+	}
+	return logger.DefaultLanguage
+}
+
+// GetLanguages 支持的界面语言
+func (a *App) GetLanguages() []string { return i18n.Languages() }
+
+// SetLanguage 设置界面语言（返回给界面的错误信息、日志类别名称随之切换）
+func (a *App) SetLanguage(lang string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if !i18n.Supported(lang) {
+		return i18n.Errorf("不支持的语言: %s", lang)
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.Language = lang
+	a.state.Mu.Unlock()
+	a.lang.Store(lang)
+	go a.saveConfig()
+
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// formatError 将返回给前端的错误翻译为界面语言
+func (a *App) formatError(err error) any {
+	return i18n.Translate(err, a.language())
 }
 func (a *App) OpenLogFolder() error { return system.OpenFolder(a.logManager.GetLogDir()) }
-func (a *App) OpenConfigFolder() error { return system.OpenFolder(a.state.ExeDir) }
+func (a *App) OpenConfigFolder() error { return system.OpenFolder(a.state.DataDir) }
 func (a *App) GetSystemInfo() system.SystemInfo { return system.GetSystemInfo() }
 func (a *App) SetSystemProxy(nodeID string) error {
 	node := a.state.GetNode(nodeID)
-	if node == nil { return fmt.Errorf("节点不存在") }
+	if node == nil { return i18n.Errorf("节点不存在") }
 	parts := strings.Split(node.Listen, ":")
 	var port int
 	fmt.Sscanf(parts[1], "%d", &port)
-	return a.proxyManager.SetSystemProxy(parts[0], port)
+
+	a.state.Mu.RLock()
+	mode := a.state.Config.SystemProxyMode
+	bypass := a.state.Config.EffectiveProxyBypass()
+	a.state.Mu.RUnlock()
+
+	// PAC 模式下系统只设置自动配置地址，由 PAC 按节点规则决定是否经过代理
+	if mode == models.SystemProxyModePAC {
+		_, err := a.setPACProxy(nodeID)
+		return err
+	}
+
+	a.pacServer.Stop() // 手动代理与 PAC 互斥
+
+	a.recordProxyChange("")
+
+	// 节点没有 HTTP 入站时，分协议模式自动退回仅 SOCKS
+	if err := a.proxyManager.SetSystemProxyWithOptions(system.ProxySettings{
+		Server:     parts[0],
+		Port:       port,
+		HTTPPort:   nodeHTTPPort(node),
+		Mode:       mode,
+		BypassList: bypass,
+	}); err != nil {
+		return err
+	}
+	a.rememberProxy(nodeID, false)
+	return nil
+}
+func (a *App) ClearSystemProxy() error { return a.clearPACProxy() }
+
+// GetWSLProxyGuide 生成 WSL2/Docker Desktop 使用指定节点的代理指引
+func (a *App) GetWSLProxyGuide(nodeID string) (*system.WSLProxyGuide, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+	host, port := splitListenAddr(node.Listen)
+	return system.BuildWSLProxyGuide(host, port, nodeHTTPPort(node)), nil
+}
+
+// ApplyWSLProxy 将代理环境写入 WSL 发行版（distro 为空时写入全部发行版）
+func (a *App) ApplyWSLProxy(nodeID, distro string) error {
+	guide, err := a.GetWSLProxyGuide(nodeID)
+	if err != nil {
+		return err
+	}
+	targets := []string{distro}
+	if distro == "" {
+		targets = guide.Distros
+	}
+	for _, d := range targets {
+		if err := system.ApplyWSLProxy(d, guide); err != nil {
+			return err
+		}
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已写入 WSL 代理配置: %s", d))
+	}
+	return nil
+}
+
+// GetLANSetupGuide 生成局域网设备（游戏机/电视）使用指定节点的配置指引
+func (a *App) GetLANSetupGuide(nodeID string) (*system.LANSetupGuide, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+	creds := lanShareCredentials(node)
+	listen := node.Listen
+	if node.LANShare.Enabled {
+		listen = models.LANShareListen(listen)
+	}
+	host, port := splitListenAddr(listen)
+	guide, err := system.BuildLANSetupGuide(host, port, nodeHTTPPort(node),
+		creds.Username, creds.Password, []string{dns.DNSAliDNS, dns.DNSCloudflare})
+	if err != nil {
+		return nil, err
+	}
+	guide.Warnings = append(guide.Warnings, firewallWarnings(a.GetFirewallStatus(), true)...)
+	return guide, nil
+}
+
+// TestLANReachability 从局域网网卡自检节点端口是否可被局域网设备访问
+func (a *App) TestLANReachability(nodeID string) (*system.LANReachabilityResult, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+	lanIP, err := system.GetLANIP()
+	if err != nil {
+		return nil, err
+	}
+	_, port := splitListenAddr(node.Listen)
+	creds := lanShareCredentials(node)
+	return system.TestLANReachability(lanIP, port, creds.Username, creds.Password), nil
+}
+
+// ApplyDockerProxy 将代理写入 Docker 客户端配置
+func (a *App) ApplyDockerProxy(nodeID string) error {
+	guide, err := a.GetWSLProxyGuide(nodeID)
+	if err != nil {
+		return err
+	}
+	return system.ApplyDockerProxy(guide)
+}
+func (a *App) ShowNotification(title, message string) error {
+	a.notifier.Notify(notify.EventSystem, title, message)
+	return nil
 }
-func (a *App) ClearSystemProxy() error { return a.proxyManager.ClearSystemProxy() }
-func (a *App) ShowNotification(title, message string) error { return a.notification.Show(title, message) }
 func (a *App) GetVersion() string { return models.AppVersion }
 func (a *App) GetAppTitle() string { return models.AppTitle }
 
@@ -717,6 +1592,20 @@ func (a *App) loadConfig() {
 	a.state.Mu.Lock()
 	a.state.Config = cfg
 	a.state.Mu.Unlock()
+	a.applyConfigSettings(cfg)
+}
+
+// applyConfigSettings 把配置中的设置同步到各子模块
+func (a *App) applyConfigSettings(cfg *models.AppConfig) {
+	a.lang.Store(cfg.Language)
+	a.dnsManager.SetGeoFallback(!cfg.DisableGeoFallback)
+	a.pingManager.SetMode(cfg.PingMode)
+	a.dnsManager.SetCustomRules(cfg.DNS)
+	a.engineManager.SetRestartPolicy(cfg.RestartPolicy)
+	a.engineManager.SetConnectionPolicy(cfg.ConnectionPolicy)
+	a.engineManager.SetPortRange(cfg.InternalPortRange)
+	a.logManager.SetDebug(cfg.DebugLog)
+	a.applyNotificationSettings()
 }
 
 func (a *App) saveConfig() {
@@ -727,27 +1616,115 @@ func (a *App) saveConfig() {
 }
 
 func (a *App) generateNodeConfig(node *models.NodeConfig) (string, error) {
+	return a.generateNodeConfigReusing(node, nil)
+}
+
+// generateNodeConfigReusing 生成节点配置；running 非空时沿用运行中实例的内部端口与限速转发地址（热重载），
+// 使只有规则等内容变化时生成的配置可以直接对比
+func (a *App) generateNodeConfigReusing(node *models.NodeConfig, running *models.NodeConfig) (string, error) {
 	if err := a.configGenerator.ValidateNodeConfig(node); err != nil { return "", err }
-	
+	if running == nil {
+		running = &models.NodeConfig{}
+	}
+	allocate := func(reuse int) int {
+		if reuse > 0 {
+			return reuse
+		}
+		return a.engineManager.FindFreePort()
+	}
+
 	listenAddr := node.Listen
+	node.StatsPort = 0
+	node.APIPort = 0
 	if node.RoutingMode == models.RoutingModeSmart {
-		node.InternalPort = a.engineManager.FindFreePort()
+		node.InternalPort = node.FixedInternalPort
+		if node.InternalPort == 0 {
+			node.InternalPort = allocate(running.InternalPort)
+		}
+		node.StatsPort = allocate(running.StatsPort)
+		if !models.IsSingBoxFront(node) {
+			node.APIPort = allocate(running.APIPort)
+			if node.APIPort == 0 {
+				return "", i18n.Errorf("内部端口范围中没有可用的端口")
+			}
+		}
+		if node.InternalPort == 0 || node.StatsPort == 0 {
+			return "", i18n.Errorf("内部端口范围中没有可用的端口")
+		}
 		listenAddr = fmt.Sprintf("127.0.0.1:%d", node.InternalPort)
 	}
 
-	xlinkPath, err := a.configGenerator.GenerateXlinkConfig(node, listenAddr)
+	// 自动IP策略按测量结果调整；IPv6 不可用时临时按仅IPv4生成；规则组展开为普通规则；
+	// 健康探测判定不可用的服务器暂时移除，最近可用的服务器排在前面；设置了前置节点时经其本地入站出站；局域网共享时在局域网地址上另建入站
+	genNode := a.lanShareNode(a.ipv6FallbackNode(a.autoIPStrategyNode(a.ruleGroupNode(a.preferredServerNode(a.healthyServerNode(a.chainNode(node)))))))
+	genNode = a.xrayTemplateNode(a.connectionPolicyNode(genNode))
+	node.LANInbounds = genNode.LANInbounds
+
+	// 带宽限制：前端进程改为监听内部地址，对外地址由引擎的限速转发占用
+	node.BandwidthRelays = nil
+	if models.HasBandwidthLimit(node) {
+		genNode, node.BandwidthRelays = a.bandwidthRelayNode(genNode, running.BandwidthRelays)
+		for _, internal := range node.BandwidthRelays {
+			if strings.HasSuffix(internal, ":0") {
+				return "", i18n.Errorf("内部端口范围中没有可用的端口")
+			}
+		}
+		if node.RoutingMode != models.RoutingModeSmart {
+			listenAddr = genNode.Listen
+		}
+	}
+
+	xlinkPath, err := a.configGenerator.GenerateXlinkConfig(genNode, listenAddr)
 	if err != nil { return "", err }
 
-	if node.RoutingMode == models.RoutingModeSmart {
-		xrayPath := filepath.Join(a.state.ExeDir, fmt.Sprintf(generator.XrayConfigTemplate, node.ID))
+	if models.IsSingBoxFront(node) {
+		_, skipped, err := a.configGenerator.GenerateSingBoxConfig(genNode, node.InternalPort)
+		if err != nil {
+			return "", err
+		}
+		if len(skipped) > 0 {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("[%s] 以下规则无法转换为 sing-box 规则，未生效: %s", node.Name, strings.Join(skipped, "; ")))
+		}
+	} else if node.RoutingMode == models.RoutingModeSmart {
+		xrayPath := filepath.Join(a.state.DataDir, fmt.Sprintf(generator.XrayConfigTemplate, node.ID))
 		hasGeosite := a.dnsManager.FileExists("geosite.dat")
 		hasGeoip := a.dnsManager.FileExists("geoip.dat")
-		cfg, err := a.dnsManager.GenerateFullXrayConfig(node, node.InternalPort, hasGeosite, hasGeoip)
+		cfg, err := a.dnsManager.GenerateFullXrayConfig(genNode, node.InternalPort, hasGeosite, hasGeoip)
 		if err != nil { return "", err }
+		a.reportSkippedGeoRules(genNode, hasGeosite, hasGeoip)
 		if err := a.dnsManager.WriteXrayConfig(cfg, xrayPath); err != nil { return "", err }
+		a.configGenerator.Track(node.ID, xrayPath)
 	}
 	return xlinkPath, nil
 }
 
-func (a *App) emitEvent(t models.EventType, p interface{}) { runtime.EventsEmit(a.ctx, string(t), p) }
+// splitListenAddr 拆分监听地址为主机和端口（支持 [::1]:port）
+func splitListenAddr(listen string) (string, int) {
+	host, portStr, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen, 0
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
+// nodeHTTPPort 节点 HTTP 入站端口，没有 HTTP 入站时为 0
+func nodeHTTPPort(node *models.NodeConfig) int {
+	_, port := splitListenAddr(models.GetHTTPListen(node))
+	return port
+}
+
+// emitEvent 向前端广播事件（以后台服务无界面运行时没有 Wails 运行时，不广播）
+func (a *App) emitEvent(t models.EventType, p interface{}) {
+	if a.headless {
+		return
+	}
+	runtime.EventsEmit(a.ctx, string(t), p)
+}
+
+// emitNodeEvent 广播节点变更事件（传入节点副本，避免并发读写）
+func (a *App) emitNodeEvent(t models.EventType, node models.NodeConfig, fields []string) {
+	a.emitEvent(t, models.NodeEventPayload{NodeID: node.ID, Node: &node, Fields: fields})
+}
+
 func (a *App) emitNodeStatus(id, s string) { a.emitEvent(models.EventNodeStatus, map[string]string{"node_id": id, "status": s}) }
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"xlink-wails/internal/api"
+	"xlink-wails/internal/command"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 本地 REST 控制接口
+// =============================================================================
+
+// apiBackend 将 App 适配为 api.Backend（避免把内部方法绑定到前端）
+type apiBackend struct {
+	app *App
+}
+
+func (b *apiBackend) GetNodes() []models.NodeConfig { return b.app.GetNodes(models.NodeQuery{}).Nodes }
+func (b *apiBackend) GetAllNodeStatuses() map[string]models.EngineStatus {
+	return b.app.GetAllNodeStatuses()
+}
+func (b *apiBackend) GetLogs(limit int) []models.LogEntry { return b.app.GetLogs(limit) }
+func (b *apiBackend) GetLogsByNode(nodeID string, limit int) []models.LogEntry {
+	return b.app.GetLogsByNode(nodeID, limit)
+}
+func (b *apiBackend) SubscribeLogs(filter models.LogFilter, deliver func(batch logger.LogBatch)) string {
+	return b.app.subscribeLogs(filter, deliver)
+}
+func (b *apiBackend) UnsubscribeLogs(id string)                 { b.app.UnsubscribeLogs(id) }
+func (b *apiBackend) GetAllTrafficStats() []models.TrafficStats { return b.app.GetAllTrafficStats() }
+func (b *apiBackend) DispatchCommand(cmd command.Command) (interface{}, error) {
+	return b.app.commandBus.Dispatch(cmd)
+}
+func (b *apiBackend) ReloadConfig() error { return b.app.reloadConfigFile() }
+
+func (b *apiBackend) PingNode(nodeRef string) (*logger.PingReport, error) {
+	node := b.app.resolveNodeRef(nodeRef)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在: %s", nodeRef)
+	}
+	nodeCopy := *node
+	return b.app.pingManager.PingAndWait(&nodeCopy, api.PingTimeout)
+}
+
+// APIStatus 控制接口状态
+type APIStatus struct {
+	Enabled bool   `json:"enabled"`
+	Running bool   `json:"running"`
+	Listen  string `json:"listen"`
+	Token   string `json:"token"`
+}
+
+// applyAPISettings 按当前配置启动或停止控制接口（作为后台服务的控制前端时由服务监听）
+func (a *App) applyAPISettings() {
+	if a.serviceFront.Load() {
+		return
+	}
+	a.state.Mu.Lock()
+	enabled := a.state.Config.APIEnabled
+	listen := a.state.Config.APIListen
+	if listen == "" {
+		listen = api.DefaultListen
+	}
+	if enabled && a.state.Config.APIToken == "" {
+		a.state.Config.APIToken = api.GenerateToken()
+		go a.saveConfig()
+	}
+	token := a.state.Config.APIToken
+	a.state.Mu.Unlock()
+
+	if !enabled {
+		if a.apiServer.IsRunning() {
+			a.apiServer.Stop()
+			a.logManager.LogSystem(logger.LevelInfo, "本地控制接口已关闭")
+		}
+		return
+	}
+
+	if err := a.apiServer.Start(listen, token); err != nil {
+		a.logManager.LogSystem(logger.LevelError, err.Error())
+		return
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("本地控制接口已启动: http://%s/api/v1/", a.apiServer.Addr()))
+
+	if host, _, err := net.SplitHostPort(listen); err == nil && !isLoopbackHost(host) {
+		a.logManager.LogSystem(logger.LevelWarn, "控制接口监听在非回环地址，局域网内持有令牌的设备均可控制本机")
+	}
+}
+
+// GetAPIStatus 获取控制接口状态
+func (a *App) GetAPIStatus() APIStatus {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+
+	listen := a.state.Config.APIListen
+	if listen == "" {
+		listen = api.DefaultListen
+	}
+	return APIStatus{
+		Enabled: a.state.Config.APIEnabled,
+		Running: a.apiServer.IsRunning(),
+		Listen:  listen,
+		Token:   a.state.Config.APIToken,
+	}
+}
+
+// SetAPIEnabled 启用或关闭控制接口
+func (a *App) SetAPIEnabled(enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	a.state.Config.APIEnabled = enabled
+	a.state.Mu.Unlock()
+
+	a.applyAPISettings()
+	go a.saveConfig()
+
+	if enabled && !a.apiServer.IsRunning() {
+		return i18n.Errorf("控制接口启动失败，请检查监听地址是否被占用")
+	}
+	return nil
+}
+
+// SetAPIListen 设置控制接口监听地址（为空时使用默认地址），接口已启用时立即改为监听新地址
+func (a *App) SetAPIListen(listen string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	listen = strings.TrimSpace(listen)
+	if listen != "" {
+		if _, _, err := net.SplitHostPort(listen); err != nil {
+			return i18n.Errorf("监听地址格式错误，应为 host:port")
+		}
+	}
+
+	a.state.Mu.Lock()
+	changed := a.state.Config.APIListen != listen
+	a.state.Config.APIListen = listen
+	enabled := a.state.Config.APIEnabled
+	a.state.Mu.Unlock()
+	if !changed {
+		return nil
+	}
+	go a.saveConfig()
+
+	if enabled {
+		a.applyAPISettings()
+		if !a.apiServer.IsRunning() && !a.serviceFront.Load() {
+			return i18n.Errorf("控制接口启动失败，请检查监听地址是否被占用")
+		}
+	}
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// RegenerateAPIToken 重新生成访问令牌（旧令牌立即失效）；只读模式或后台服务运行时返回当前令牌
+func (a *App) RegenerateAPIToken() string {
+	if a.checkWritable() != nil || a.serviceFront.Load() {
+		a.state.Mu.RLock()
+		defer a.state.Mu.RUnlock()
+		return a.state.Config.APIToken
+	}
+	a.state.Mu.Lock()
+	a.state.Config.APIToken = api.GenerateToken()
+	token := a.state.Config.APIToken
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.applyAPISettings()
+	return token
+}
+
+// isLoopbackHost 监听地址的主机部分是否只绑定回环（不含端口）
+func isLoopbackHost(host string) bool {
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
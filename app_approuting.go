@@ -0,0 +1,14 @@
+package main
+
+import (
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 按应用分流
+// =============================================================================
+
+// ListRunningApps 列出运行中的程序，供选择按应用分流的程序
+func (a *App) ListRunningApps() ([]system.RunningApp, error) {
+	return system.ListRunningApps()
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// 自动选择最快节点
+// =============================================================================
+
+const (
+	// defaultAutoSelectInterval 默认重新评估间隔（分钟）
+	defaultAutoSelectInterval = 10
+	// defaultAutoSelectThreshold 默认切换阈值（毫秒）
+	defaultAutoSelectThreshold = 100
+)
+
+// AutoSelectState 自动选择状态
+type AutoSelectState struct {
+	Enabled      bool           `json:"enabled"`
+	Testing      bool           `json:"testing"`        // 正在测速
+	ActiveNodeID string         `json:"active_node_id"` // 当前运行的节点
+	BestNodeID   string         `json:"best_node_id"`   // 最近一次测速最快的节点
+	Latencies    map[string]int `json:"latencies"`      // 各节点平均延迟（毫秒），-1 表示不可达
+	Interval     int            `json:"interval"`       // 重新评估间隔（分钟）
+	Threshold    int            `json:"threshold"`      // 切换阈值（毫秒）
+	LastCheck    time.Time      `json:"last_check"`
+	LastSwitch   time.Time      `json:"last_switch"`
+	LastReason   string         `json:"last_reason"` // 最近一次切换（或未切换）的原因
+}
+
+// EnableAutoSelect 启用/关闭自动选择最快节点
+// 启用后立即测速并运行最快的节点，之后按间隔重新评估
+func (a *App) EnableAutoSelect(enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	if enabled && len(a.state.Config.Nodes) == 0 {
+		a.state.Mu.Unlock()
+		return i18n.Errorf("没有可用的节点")
+	}
+	a.state.Config.AutoSelectEnabled = enabled
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	if enabled {
+		a.startAutoSelect()
+		a.logManager.LogSystem(logger.LevelInfo, "已启用自动选择最快节点")
+	} else {
+		a.stopAutoSelect()
+		a.logManager.LogSystem(logger.LevelInfo, "已关闭自动选择最快节点")
+	}
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// SetAutoSelect 设置重新评估间隔（分钟）和切换阈值（毫秒），0 表示使用默认值；已启用时按新设置重新开始
+func (a *App) SetAutoSelect(intervalMinutes, thresholdMs int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if intervalMinutes < 0 || thresholdMs < 0 {
+		return i18n.Errorf("间隔和阈值不能为负数")
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.AutoSelectInterval = intervalMinutes
+	a.state.Config.AutoSelectThreshold = thresholdMs
+	enabled := a.state.Config.AutoSelectEnabled
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	if enabled {
+		a.startAutoSelect()
+	}
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// GetAutoSelectState 获取自动选择状态
+func (a *App) GetAutoSelectState() AutoSelectState {
+	a.autoSelectMu.Lock()
+	state := a.autoSelectState
+	a.autoSelectMu.Unlock()
+
+	latencies := make(map[string]int, len(state.Latencies))
+	for id, ms := range state.Latencies {
+		latencies[id] = ms
+	}
+	state.Latencies = latencies
+
+	interval, threshold, enabled := a.autoSelectSettings()
+	state.Enabled = enabled
+	state.Interval = int(interval / time.Minute)
+	state.Threshold = threshold
+	state.ActiveNodeID = a.activeNodeID()
+	return state
+}
+
+// autoSelectSettings 读取自动选择配置（补全默认值）
+func (a *App) autoSelectSettings() (time.Duration, int, bool) {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+
+	interval := a.state.Config.AutoSelectInterval
+	if interval <= 0 {
+		interval = defaultAutoSelectInterval
+	}
+	threshold := a.state.Config.AutoSelectThreshold
+	if threshold <= 0 {
+		threshold = defaultAutoSelectThreshold
+	}
+	return time.Duration(interval) * time.Minute, threshold, a.state.Config.AutoSelectEnabled
+}
+
+// startAutoSelect 启动（或重启）自动选择循环
+func (a *App) startAutoSelect() {
+	a.stopAutoSelect()
+	if a.serviceFront.Load() {
+		return // 由服务自动选择
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.autoSelectMu.Lock()
+	a.autoSelectCancel = cancel
+	a.autoSelectMu.Unlock()
+
+	go a.autoSelectLoop(ctx)
+}
+
+// stopAutoSelect 停止自动选择循环（不影响正在运行的节点）
+func (a *App) stopAutoSelect() {
+	a.autoSelectMu.Lock()
+	cancel := a.autoSelectCancel
+	a.autoSelectCancel = nil
+	a.autoSelectMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (a *App) autoSelectLoop(ctx context.Context) {
+	// 首次评估：没有运行中的节点时直接启动最快节点
+	a.evaluateAutoSelect(ctx, true)
+
+	for {
+		interval, _, _ := a.autoSelectSettings()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		a.evaluateAutoSelect(ctx, false)
+	}
+}
+
+// evaluateAutoSelect 批量测速并在需要时切换节点
+// 周期评估时若用户已手动停止所有节点，则不再自动启动
+func (a *App) evaluateAutoSelect(ctx context.Context, initial bool) {
+	a.autoSelectMu.Lock()
+	if a.autoSelectState.Testing {
+		a.autoSelectMu.Unlock()
+		return
+	}
+	a.autoSelectState.Testing = true
+	a.autoSelectMu.Unlock()
+	a.emitEvent(models.EventAutoSelectUpdate, a.GetAutoSelectState())
+
+	a.state.Mu.RLock()
+	nodes := make([]*models.NodeConfig, len(a.state.Config.Nodes))
+	for i := range a.state.Config.Nodes {
+		nodeCopy := a.state.Config.Nodes[i]
+		nodes[i] = &nodeCopy
+	}
+	a.state.Mu.RUnlock()
+
+	results := a.pingManager.BatchPing(ctx, nodes, a.batchPingOptions(), nil)
+
+	latencies := make(map[string]int, len(results))
+	bestID, bestLatency := "", -1
+	for _, r := range results {
+		latency := -1
+		if r.Report != nil && r.Report.SuccessCount > 0 {
+			latency = r.Report.AvgLatency
+		}
+		latencies[r.NodeID] = latency
+		if latency >= 0 && (bestLatency < 0 || latency < bestLatency) {
+			bestID, bestLatency = r.NodeID, latency
+		}
+	}
+
+	_, threshold, _ := a.autoSelectSettings()
+	activeID := a.activeNodeID()
+	reason := ""
+	switchTo := ""
+
+	switch {
+	case ctx.Err() != nil:
+		// 测速期间已关闭自动选择
+	case bestID == "":
+		reason = "所有节点均不可达，保持当前状态"
+	case activeID == "":
+		if initial {
+			switchTo, reason = bestID, fmt.Sprintf("启动最快节点 (%dms)", bestLatency)
+		} else {
+			reason = "没有运行中的节点，跳过切换"
+		}
+	case activeID == bestID:
+		reason = "当前节点已是最快节点"
+	case latencies[activeID] < 0:
+		switchTo, reason = bestID, fmt.Sprintf("当前节点不可达，切换到最快节点 (%dms)", bestLatency)
+	case latencies[activeID]-bestLatency > threshold:
+		switchTo, reason = bestID, fmt.Sprintf("当前节点 %dms，最快节点 %dms，超过阈值 %dms",
+			latencies[activeID], bestLatency, threshold)
+	default:
+		reason = fmt.Sprintf("当前节点 %dms，与最快节点差距未超过阈值 %dms", latencies[activeID], threshold)
+	}
+
+	var switchErr error
+	if switchTo != "" {
+		switchErr = a.switchover(activeID, switchTo)
+		if switchErr != nil {
+			reason = fmt.Sprintf("切换失败: %v", switchErr)
+		}
+	}
+
+	a.autoSelectMu.Lock()
+	a.autoSelectState.Testing = false
+	a.autoSelectState.BestNodeID = bestID
+	a.autoSelectState.Latencies = latencies
+	a.autoSelectState.LastCheck = time.Now()
+	a.autoSelectState.LastReason = reason
+	if switchTo != "" && switchErr == nil {
+		a.autoSelectState.LastSwitch = time.Now()
+	}
+	a.autoSelectMu.Unlock()
+
+	if switchTo != "" {
+		level := logger.LevelInfo
+		if switchErr != nil {
+			level = logger.LevelError
+		}
+		a.logManager.LogSystem(level, "自动选择: "+reason)
+		if switchErr == nil {
+			if node := a.state.GetNode(switchTo); node != nil {
+				a.notifyNode(notify.EventNode, node.Name, models.StatusRunning, fmt.Sprintf("已自动切换到: %s", node.Name))
+			}
+		}
+	}
+	a.emitEvent(models.EventAutoSelectUpdate, a.GetAutoSelectState())
+}
+
+// switchover 从当前节点切换到目标节点
+// 两者监听地址不同时先启动新节点再停止旧节点，避免切换期间断流；
+// 监听地址相同时端口无法共存，只能先停后启
+func (a *App) switchover(fromID, toID string) error {
+	if fromID == "" {
+		return a.StartNode(toID)
+	}
+
+	from := a.state.GetNode(fromID)
+	to := a.state.GetNode(toID)
+	if from == nil || to == nil || from.Listen == to.Listen {
+		return a.SwitchNode(toID)
+	}
+
+	if err := a.StartNode(toID); err != nil {
+		return err
+	}
+	return a.StopNode(fromID)
+}
+
+// activeNodeID 当前运行的节点（优先上次启动的节点）
+func (a *App) activeNodeID() string {
+	statuses := a.engineManager.GetAllStatuses()
+
+	a.state.Mu.RLock()
+	lastID := a.state.Config.LastRunningNodeID
+	a.state.Mu.RUnlock()
+
+	if st, ok := statuses[lastID]; ok && st.Status == models.StatusRunning {
+		return lastID
+	}
+	for id, st := range statuses {
+		if st.Status == models.StatusRunning {
+			return id
+		}
+	}
+	return ""
+}
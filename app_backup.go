@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 自动备份（变更前备份、每日定时备份）
+// =============================================================================
+
+const (
+	backupStartDelay    = time.Minute // 启动后首次检查前的等待（等待配置加载与首次保存）
+	backupCheckInterval = time.Hour   // 检查当天是否已备份的间隔
+)
+
+// GetBackupSettings 获取自动备份设置
+func (a *App) GetBackupSettings() models.BackupSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.Backup
+}
+
+// SetBackupSettings 保存自动备份设置，下次创建备份时按新的保留份数清理
+func (a *App) SetBackupSettings(settings models.BackupSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if settings.KeepDaily < 0 || settings.KeepDaily > models.MaxBackupKeep ||
+		settings.KeepSnapshots < 0 || settings.KeepSnapshots > models.MaxBackupKeep {
+		return i18n.Errorf("备份保留份数应在 0-%d 之间", models.MaxBackupKeep)
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.Backup = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	return nil
+}
+
+// ListBackupDetails 列出所有备份及其创建时间、原因（最新的在前）
+func (a *App) ListBackupDetails() []config.BackupInfo {
+	return a.configManager.ListBackupInfos()
+}
+
+// CreateBackupNow 保存当前配置并立即创建一份手动备份，返回备份文件名
+func (a *App) CreateBackupNow() (string, error) {
+	a.saveConfig()
+	name, err := a.configManager.CreateBackup(config.BackupReasonManual)
+	if err != nil {
+		return "", i18n.Errorf("创建备份失败: %w", err)
+	}
+	a.logManager.LogSystem(logger.LevelInfo, "已创建配置备份: "+name)
+	return name, nil
+}
+
+// backupBeforeChange 在删除节点、应用预设等破坏性修改前备份当前配置
+func (a *App) backupBeforeChange(reason string) {
+	if _, err := a.configManager.CreateBackup(reason); err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("修改前备份配置失败: %v", err))
+	}
+}
+
+// startBackupScheduler 启动每日备份循环（启动一分钟后及之后每小时检查一次当天是否已备份）
+func (a *App) startBackupScheduler(ctx context.Context) {
+	go func() {
+		wait := backupStartDelay
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			a.runDailyBackup(time.Now())
+			wait = backupCheckInterval
+		}
+	}()
+}
+
+// runDailyBackup 当天还没有每日备份时创建一份
+func (a *App) runDailyBackup(now time.Time) {
+	a.state.Mu.RLock()
+	disabled := a.state.Config.Backup.DisableDaily
+	a.state.Mu.RUnlock()
+	if disabled {
+		return
+	}
+
+	last := a.configManager.LastBackupTime(config.BackupReasonDaily)
+	if y, m, d := last.Date(); !last.IsZero() && y == now.Year() && m == now.Month() && d == now.Day() {
+		return
+	}
+	name, err := a.configManager.CreateBackup(config.BackupReasonDaily)
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("每日备份失败: %v", err))
+		return
+	}
+	a.logManager.LogSystem(logger.LevelInfo, "已创建每日备份: "+name)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点带宽限制
+// =============================================================================
+
+// applyBandwidthLimit 节点的带宽限制修改后立即作用于运行中的节点
+// 启动时未设置限制的节点没有经过限速转发，需重新启动后生效
+func (a *App) applyBandwidthLimit(node models.NodeConfig) {
+	if a.serviceFront.Load() || a.engineManager.GetStatus(node.ID) != models.StatusRunning {
+		return
+	}
+	if a.engineManager.SetBandwidthLimit(node.ID, node.UploadLimit, node.DownloadLimit) {
+		return
+	}
+	if models.HasBandwidthLimit(&node) {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategorySystem, "带宽限制将在重新启动节点后生效")
+	}
+}
+
+// bandwidthChanged 变化字段中是否包含带宽限制
+func bandwidthChanged(fields []string) bool {
+	for _, f := range fields {
+		if f == "upload_limit" || f == "download_limit" {
+			return true
+		}
+	}
+	return false
+}
+
+// bandwidthRelayNode 返回前端进程改为监听内部地址的节点副本，以及对外地址到内部地址的转发表
+// （SOCKS 入站、独立的 HTTP 入站和局域网共享入站各占一个内部端口，reuse 中已有的对外地址沿用原来的内部地址）
+func (a *App) bandwidthRelayNode(node *models.NodeConfig, reuse map[string]string) (*models.NodeConfig, map[string]string) {
+	limited := *node
+	relays := make(map[string]string)
+	internal := func(listen string) string {
+		if addr, ok := reuse[listen]; ok {
+			relays[listen] = addr
+			return addr
+		}
+		addr := fmt.Sprintf("127.0.0.1:%d", a.engineManager.FindFreePort())
+		relays[listen] = addr
+		return addr
+	}
+
+	limited.Listen = internal(node.Listen)
+	if node.InboundMode == models.InboundSocksHTTP && node.HTTPListen != "" {
+		limited.HTTPListen = internal(node.HTTPListen)
+	}
+	if len(node.LANInbounds) > 0 {
+		limited.LANInbounds = make([]models.LANShareInbound, len(node.LANInbounds))
+		for i, in := range node.LANInbounds {
+			limited.LANInbounds[i] = models.LANShareInbound{Listen: internal(in.Listen), HTTP: in.HTTP}
+		}
+	}
+	return &limited, relays
+}
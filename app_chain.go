@@ -0,0 +1,34 @@
+package main
+
+import (
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点链路（经另一个节点转发出站）
+// =============================================================================
+
+// chainNode 设置了前置节点时，生成配置用的副本改为经前置节点的本地 SOCKS5 入站出站
+func (a *App) chainNode(node *models.NodeConfig) *models.NodeConfig {
+	if node.ChainNodeID == "" {
+		return node
+	}
+	upstream := a.state.GetNode(node.ChainNodeID)
+	if upstream == nil {
+		return node
+	}
+	chained := *node
+	chained.Socks5 = loopbackListen(upstream.Listen)
+	return &chained
+}
+
+// chainUsersLocked 以指定节点为前置节点的节点名称（调用方需持有锁）
+func (a *App) chainUsersLocked(nodeID string) []string {
+	var names []string
+	for _, node := range a.state.Config.Nodes {
+		if node.ChainNodeID == nodeID {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 系统时间校验
+// =============================================================================
+
+// clockCheckTimeout 经 HTTP 测量时间偏差的请求超时
+const clockCheckTimeout = 5 * time.Second
+
+// checkClock 启动时检查系统时间，偏差过大时提醒用户
+func (a *App) checkClock() {
+	result := a.CheckClockSync()
+	switch {
+	case result.Error != "":
+		a.logManager.LogSystem(logger.LevelDebug, fmt.Sprintf("无法校验系统时间: %s", result.Error))
+	case result.Skewed:
+		msg := fmt.Sprintf("系统时间与标准时间相差 %s，TLS / ECH 等协议可能无法连接，请同步系统时间", formatClockOffset(result.OffsetMs))
+		a.logManager.LogSystem(logger.LevelWarn, msg)
+		a.notify(notify.EventSystem, msg)
+	default:
+		a.logManager.LogSystem(logger.LevelDebug, fmt.Sprintf("系统时间正常（偏差 %d 毫秒，来源 %s）", result.OffsetMs, result.Source))
+	}
+}
+
+// CheckClockSync 测量系统时间与标准时间的偏差：优先直连 NTP，不可用时经内部请求出口读取 HTTP Date 头
+func (a *App) CheckClockSync() *system.ClockCheckResult {
+	result := system.CheckClock(system.DefaultNTPServers, a.egressClient(clockCheckTimeout), system.DefaultTimeURLs)
+
+	a.clockMu.Lock()
+	a.clock = result
+	a.clockMu.Unlock()
+	return result
+}
+
+// GetClockStatus 获取最近一次时间校验结果（尚未校验时返回 nil）
+func (a *App) GetClockStatus() *system.ClockCheckResult {
+	a.clockMu.Lock()
+	defer a.clockMu.Unlock()
+	return a.clock
+}
+
+// formatClockOffset 时间偏差的显示文本
+func formatClockOffset(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	direction := "快"
+	if d < 0 {
+		d, direction = -d, "慢"
+	}
+	return fmt.Sprintf("%s（本机偏%s）", d.Round(time.Second), direction)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"xlink-wails/internal/command"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 命令总线 (托盘 / 命令行 / 第二实例 / HTTP API 共用)
+// =============================================================================
+
+// registerCommands 注册所有控制命令
+func (a *App) registerCommands() {
+	a.commandBus.Register(command.CmdStart, func(cmd command.Command) (interface{}, error) {
+		node := a.resolveNodeRef(cmd.NodeRef)
+		if node == nil {
+			return nil, i18n.Errorf("节点不存在: %s", cmd.NodeRef)
+		}
+		return nil, a.StartNode(node.ID)
+	})
+
+	a.commandBus.Register(command.CmdStop, func(cmd command.Command) (interface{}, error) {
+		node := a.resolveNodeRef(cmd.NodeRef)
+		if node == nil {
+			return nil, i18n.Errorf("节点不存在: %s", cmd.NodeRef)
+		}
+		return nil, a.StopNode(node.ID)
+	})
+
+	a.commandBus.Register(command.CmdSwitch, func(cmd command.Command) (interface{}, error) {
+		node := a.resolveNodeRef(cmd.NodeRef)
+		if node == nil {
+			return nil, i18n.Errorf("节点不存在: %s", cmd.NodeRef)
+		}
+		return nil, a.SwitchNode(node.ID)
+	})
+
+	a.commandBus.Register(command.CmdStopAll, func(cmd command.Command) (interface{}, error) {
+		return nil, a.StopAllNodes()
+	})
+
+	a.commandBus.Register(command.CmdStatus, func(cmd command.Command) (interface{}, error) {
+		if cmd.NodeRef == "" {
+			return a.GetAllNodeStatuses(), nil
+		}
+		node := a.resolveNodeRef(cmd.NodeRef)
+		if node == nil {
+			return nil, i18n.Errorf("节点不存在: %s", cmd.NodeRef)
+		}
+		return map[string]string{"node_id": node.ID, "status": a.GetNodeStatus(node.ID)}, nil
+	})
+
+	a.commandBus.SetDispatchCallback(func(cmd command.Command, err error) {
+		if cmd.Name == command.CmdStatus {
+			return
+		}
+		if err != nil {
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("命令 [%s] %s %s 失败: %v", cmd.Source, cmd.Name, cmd.NodeRef, err))
+		} else {
+			a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("命令 [%s] %s %s", cmd.Source, cmd.Name, cmd.NodeRef))
+		}
+	})
+}
+
+// resolveNodeRef 按节点ID或名称查找节点
+func (a *App) resolveNodeRef(ref string) *models.NodeConfig {
+	if node := a.state.GetNode(ref); node != nil {
+		return node
+	}
+
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	for i := range a.state.Config.Nodes {
+		if a.state.Config.Nodes[i].Name == ref {
+			return &a.state.Config.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// ExecuteCommand 执行控制命令（前端调用入口）
+func (a *App) ExecuteCommand(name, nodeRef string) (interface{}, error) {
+	return a.commandBus.Dispatch(command.Command{
+		Name:    command.Name(name),
+		NodeRef: nodeRef,
+		Source:  command.SourceAPI,
+	})
+}
+
+// SwitchNode 切换到指定节点（停止其他运行中的节点）
+func (a *App) SwitchNode(id string) error {
+	if a.state.GetNode(id) == nil {
+		return i18n.Errorf("节点不存在: %s", id)
+	}
+
+	for nodeID, st := range a.engineManager.GetAllStatuses() {
+		if nodeID != id && st.Status != models.StatusStopped {
+			if err := a.StopNode(nodeID); err != nil {
+				return err
+			}
+		}
+	}
+	return a.StartNode(id)
+}
+
+// runCommands 依次执行一组命令
+func (a *App) runCommands(cmds []command.Command) {
+	for _, cmd := range cmds {
+		a.commandBus.Dispatch(cmd)
+	}
+}
+
+// handleSecondInstance 处理第二实例转发过来的启动参数
+func (a *App) handleSecondInstance(args []string) {
+	cmds := command.ParseArgs(args, command.SourceInstance)
+	if len(cmds) == 0 {
+		// 没有控制命令时，唤醒主窗口
+		a.ShowWindow()
+		return
+	}
+	go a.runCommands(cmds)
+}
+
+// =============================================================================
+// 托盘菜单
+// =============================================================================
+
+// refreshTrayMenu 根据节点状态重建托盘菜单
+func (a *App) refreshTrayMenu() {
+	if a.tray == nil {
+		return
+	}
+
+	statuses := a.engineManager.GetAllStatuses()
+
+	a.state.Mu.RLock()
+	nodes := make([]models.NodeConfig, len(a.state.Config.Nodes))
+	copy(nodes, a.state.Config.Nodes)
+	a.state.Mu.RUnlock()
+
+	dispatch := func(name command.Name, nodeID string) func() {
+		return func() {
+			go a.commandBus.Dispatch(command.Command{Name: name, NodeRef: nodeID, Source: command.SourceTray})
+		}
+	}
+	routing := func(nodeID string, mode int) func() {
+		return func() { a.setTrayRoutingMode(nodeID, mode) }
+	}
+
+	var items []system.TrayMenuItem
+	running := 0
+	for _, node := range nodes {
+		isRunning := false
+		if st, ok := statuses[node.ID]; ok && st.Status == models.StatusRunning {
+			isRunning = true
+			running++
+		}
+
+		items = append(items, system.TrayMenuItem{
+			ID:      "node:" + node.ID,
+			Label:   node.Name,
+			Enabled: true,
+			Checked: isRunning,
+			SubMenu: []system.TrayMenuItem{
+				{ID: "start:" + node.ID, Label: "启动", Enabled: !isRunning, OnClick: dispatch(command.CmdStart, node.ID)},
+				{ID: "stop:" + node.ID, Label: "停止", Enabled: isRunning, OnClick: dispatch(command.CmdStop, node.ID)},
+				{ID: "switch:" + node.ID, Label: "仅运行此节点", Enabled: true, OnClick: dispatch(command.CmdSwitch, node.ID)},
+				{Separator: true},
+				{ID: "global:" + node.ID, Label: "全局代理", Enabled: true, Checked: node.RoutingMode == models.RoutingModeGlobal,
+					OnClick: routing(node.ID, models.RoutingModeGlobal)},
+				{ID: "smart:" + node.ID, Label: "智能分流", Enabled: true, Checked: node.RoutingMode == models.RoutingModeSmart,
+					OnClick: routing(node.ID, models.RoutingModeSmart)},
+			},
+		})
+	}
+
+	proxyOn := a.systemProxyEnabled()
+	items = append(items,
+		system.TrayMenuItem{Separator: true},
+		system.TrayMenuItem{ID: "system-proxy", Label: "系统代理", Enabled: proxyOn || running > 0, Checked: proxyOn,
+			OnClick: func() { a.setTraySystemProxy(!proxyOn) }},
+		system.TrayMenuItem{ID: "stop-all", Label: "全部停止", Enabled: running > 0, OnClick: dispatch(command.CmdStopAll, "")},
+		system.TrayMenuItem{Separator: true},
+		system.TrayMenuItem{ID: "show", Label: "显示主窗口", Enabled: true, OnClick: a.ShowWindow},
+		system.TrayMenuItem{ID: "quit", Label: "退出", Enabled: true, OnClick: a.Quit},
+	)
+
+	a.tray.SetMenuItems(items)
+	a.tray.UpdateStatus(running > 0, running)
+}
+
+// startTray 创建托盘图标（不支持的平台只记录日志）
+func (a *App) startTray() {
+	a.tray.SetOnClick(a.ShowWindow)
+	a.tray.SetOnDoubleClick(a.ShowWindow)
+	a.tray.SetOnMenuOpen(a.refreshTrayMenu)
+	a.refreshTrayMenu()
+
+	if err := a.tray.Start(); err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("系统托盘不可用: %v", err))
+	}
+}
+
+// beforeClose 开启"最小化到托盘"且托盘可用时，关闭窗口改为隐藏
+func (a *App) beforeClose(ctx context.Context) bool {
+	if a.quitting.Load() || a.tray == nil || !a.tray.Running() {
+		return false
+	}
+
+	a.state.Mu.RLock()
+	minimize := a.state.Config.MinimizeToTray
+	a.state.Mu.RUnlock()
+	if !minimize {
+		return false
+	}
+
+	a.HideWindow()
+	return true
+}
+
+// setTrayRoutingMode 从托盘切换节点路由模式，节点运行中时重启生效
+func (a *App) setTrayRoutingMode(nodeID string, mode int) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return
+	}
+
+	a.state.Mu.RLock()
+	updated := *node
+	a.state.Mu.RUnlock()
+	if updated.RoutingMode == mode {
+		return
+	}
+	updated.RoutingMode = mode
+
+	if err := a.UpdateNode(updated); err != nil {
+		a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("切换路由模式失败: %v", err))
+		return
+	}
+	if a.engineManager.GetStatus(nodeID) == models.StatusRunning {
+		if err := a.StartNode(nodeID); err != nil {
+			a.logManager.LogNode(nodeID, updated.Name, logger.LevelError, logger.CategorySystem, fmt.Sprintf("重启失败: %v", err))
+		}
+	}
+	a.refreshTrayMenu()
+}
+
+// setTraySystemProxy 从托盘开启或关闭系统代理（开启时使用上次运行的节点，或任一运行中的节点）
+func (a *App) setTraySystemProxy(enable bool) {
+	var err error
+	if enable {
+		nodeID := a.systemProxyNode()
+		if nodeID == "" {
+			err = i18n.Errorf("没有运行中的节点")
+		} else {
+			err = a.SetSystemProxy(nodeID)
+		}
+	} else {
+		err = a.ClearSystemProxy()
+	}
+
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("设置系统代理失败: %v", err))
+		a.notify(notify.EventSystem, fmt.Sprintf("设置系统代理失败: %v", err))
+	}
+	a.refreshTrayMenu()
+}
+
+// systemProxyNode 开启系统代理时使用的节点
+func (a *App) systemProxyNode() string {
+	running := a.runningNodeIDs()
+
+	a.state.Mu.RLock()
+	last := a.state.Config.LastRunningNodeID
+	a.state.Mu.RUnlock()
+
+	for _, id := range running {
+		if id == last {
+			return id
+		}
+	}
+	if len(running) > 0 {
+		return running[0]
+	}
+	return ""
+}
+
+// systemProxyEnabled 系统代理（手动或 PAC）是否已开启
+func (a *App) systemProxyEnabled() bool {
+	if a.proxyManager.IsPACActive() {
+		return true
+	}
+	settings, err := a.proxyManager.GetSystemProxy()
+	return err == nil && settings != nil && settings.Enabled
+}
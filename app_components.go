@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 配套组件自检
+// =============================================================================
+
+// checkComponents 启动时检查配套组件，缺失必需组件时通知用户
+func (a *App) checkComponents() {
+	components := a.RefreshComponentStatus()
+
+	for _, c := range components {
+		switch {
+		case !c.Present && c.Required:
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("组件缺失: %s，%s", c.Name, c.Hint))
+			a.notify(notify.EventComponent, fmt.Sprintf("缺少 %s，请重新下载完整安装包", c.Name))
+		case c.Action != system.ComponentActionNone:
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("组件 %s: %s", c.Name, c.Hint))
+		default:
+			version := c.Version
+			if version == "" {
+				version = "未知版本"
+			}
+			a.logManager.LogSystem(logger.LevelDebug, fmt.Sprintf("组件 %s (%s) 正常", c.Name, version))
+		}
+	}
+}
+
+// GetComponentStatus 获取配套组件状态（使用启动时的检查结果）
+func (a *App) GetComponentStatus() []system.ComponentStatus {
+	a.componentMu.Lock()
+	components := a.components
+	a.componentMu.Unlock()
+
+	if components == nil {
+		return a.RefreshComponentStatus()
+	}
+	return components
+}
+
+// RefreshComponentStatus 重新检查配套组件（下载或替换文件后调用）
+func (a *App) RefreshComponentStatus() []system.ComponentStatus {
+	components := system.CheckComponents(a.state.ExeDir)
+
+	a.componentMu.Lock()
+	a.components = components
+	a.componentMu.Unlock()
+	return components
+}
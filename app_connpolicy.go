@@ -0,0 +1,45 @@
+package main
+
+import (
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 启动 / 停止超时与连接重试
+// =============================================================================
+
+// GetConnectionPolicy 获取全局连接策略（未设置的字段已填充默认值）
+func (a *App) GetConnectionPolicy() models.ConnectionPolicy {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.ConnectionPolicy.Normalize()
+}
+
+// SetConnectionPolicy 保存全局连接策略，节点中设置的字段优先；对之后启动的节点生效
+func (a *App) SetConnectionPolicy(policy models.ConnectionPolicy) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := models.ValidateConnectionPolicy(policy); err != nil {
+		return err
+	}
+	policy = policy.Normalize()
+
+	a.state.Mu.Lock()
+	a.state.Config.ConnectionPolicy = policy
+	a.state.Mu.Unlock()
+	a.engineManager.SetConnectionPolicy(policy)
+	go a.saveConfig()
+	return nil
+}
+
+// connectionPolicyNode 生成配置时使用节点生效的连接策略（全局策略叠加节点设置）
+func (a *App) connectionPolicyNode(node *models.NodeConfig) *models.NodeConfig {
+	a.state.Mu.RLock()
+	global := a.state.Config.ConnectionPolicy
+	a.state.Mu.RUnlock()
+
+	effective := *node
+	effective.ConnectionPolicy = global.Override(node.ConnectionPolicy).Normalize()
+	return &effective
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 内核程序自动更新
+// =============================================================================
+
+const (
+	// coreUpdateCheckInterval 自动更新的检查间隔
+	coreUpdateCheckInterval = 12 * time.Hour
+	// coreUpdateTimeout 单次检查+下载的总超时
+	coreUpdateTimeout = 10 * time.Minute
+)
+
+// 更新阶段
+const (
+	coreStageDownloading = "downloading"
+	coreStageInstalling  = "installing"
+	coreStageRestarting  = "restarting"
+)
+
+// CoreUpdateProgress 更新阶段变化
+type CoreUpdateProgress struct {
+	Name  string   `json:"name,omitempty"`
+	Stage string   `json:"stage"`
+	Nodes []string `json:"nodes,omitempty"` // 重启阶段涉及的节点
+}
+
+// CoreUpdateResult 单个内核程序的更新结果
+type CoreUpdateResult struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GetCoreUpdateSettings 获取内核更新设置
+func (a *App) GetCoreUpdateSettings() models.CoreUpdateSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.CoreUpdate
+}
+
+// SetCoreUpdateSettings 保存内核更新设置
+func (a *App) SetCoreUpdateSettings(settings models.CoreUpdateSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	feeds := make([]string, 0, len(settings.Feeds))
+	for _, feed := range settings.Feeds {
+		feed = strings.TrimSpace(feed)
+		if feed == "" {
+			continue
+		}
+		u, err := url.Parse(feed)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("更新源地址无效: %s", feed)
+		}
+		feeds = append(feeds, feed)
+	}
+	settings.Feeds = feeds
+
+	a.state.Mu.Lock()
+	a.state.Config.CoreUpdate = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	return nil
+}
+
+// CheckCoreUpdates 从更新源检查内核程序是否有新版本
+func (a *App) CheckCoreUpdates() ([]system.CoreUpdateInfo, error) {
+	feeds := a.GetCoreUpdateSettings().Feeds
+	if len(feeds) == 0 {
+		return nil, i18n.Errorf("未配置内核更新源")
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, coreUpdateTimeout)
+	defer cancel()
+
+	releases, err := system.FetchCoreReleases(ctx, a.egressClient(coreUpdateTimeout), feeds)
+	if err != nil {
+		return nil, err
+	}
+	return system.CheckCoreUpdates(a.state.ExeDir, releases), nil
+}
+
+// UpdateCore 下载并安装有更新的内核程序，names 为空时更新全部
+// 新文件校验通过后才停止受影响的节点，替换完成后重新启动它们
+func (a *App) UpdateCore(names []string) ([]CoreUpdateResult, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	return a.updateCore(names)
+}
+
+// updateCore 执行内核更新（自动更新按已保存的设置执行，只读模式下不暂停）
+func (a *App) updateCore(names []string) ([]CoreUpdateResult, error) {
+	if !a.coreUpdating.CompareAndSwap(false, true) {
+		return nil, i18n.Errorf("内核正在更新中")
+	}
+	defer a.coreUpdating.Store(false)
+
+	infos, err := a.CheckCoreUpdates()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, coreUpdateTimeout)
+	defer cancel()
+	client := a.egressClient(coreUpdateTimeout)
+
+	// 1. 下载并校验（失败不影响运行中的节点）
+	var results []CoreUpdateResult
+	staged := make(map[string]string) // 名称 -> 已校验的临时文件
+	for _, info := range infos {
+		if !info.Available || (len(names) > 0 && !containsString(names, info.Name)) {
+			continue
+		}
+		result := CoreUpdateResult{Name: info.Name, Version: info.Latest.Version}
+
+		a.emitEvent(models.EventCoreProgress, CoreUpdateProgress{Name: info.Name, Stage: coreStageDownloading})
+		tmp, err := system.DownloadCoreRelease(ctx, client, a.state.ExeDir, *info.Latest)
+		if err != nil {
+			result.Error = err.Error()
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("%s %s 下载失败: %v", info.Name, info.Latest.Version, err))
+		} else {
+			staged[info.Name] = tmp
+		}
+		results = append(results, result)
+	}
+	if len(staged) == 0 {
+		a.emitEvent(models.EventCoreUpdated, results)
+		return results, nil
+	}
+
+	// 2. 停止使用这些程序的节点后替换
+	affected := a.coreAffectedNodes(staged)
+	for _, id := range affected {
+		a.logNodeEvent(id, logger.LevelInfo, "内核更新，节点暂时停止")
+		a.engineManager.StopNode(id)
+	}
+
+	for i := range results {
+		tmp, ok := staged[results[i].Name]
+		if !ok {
+			continue
+		}
+		a.emitEvent(models.EventCoreProgress, CoreUpdateProgress{Name: results[i].Name, Stage: coreStageInstalling})
+		if err := system.SwapCoreBinary(a.state.ExeDir, results[i].Name, tmp); err != nil {
+			os.Remove(tmp)
+			results[i].Error = err.Error()
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("%s 替换失败: %v", results[i].Name, err))
+			continue
+		}
+		results[i].Success = true
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("%s 已更新到 %s", results[i].Name, results[i].Version))
+	}
+
+	// 3. 重新启动之前运行的节点
+	if len(affected) > 0 {
+		a.emitEvent(models.EventCoreProgress, CoreUpdateProgress{Stage: coreStageRestarting, Nodes: affected})
+	}
+	for _, id := range affected {
+		if err := a.StartNode(id); err != nil {
+			a.logNodeEvent(id, logger.LevelError, fmt.Sprintf("内核更新后重启失败: %v", err))
+		}
+	}
+
+	a.RefreshComponentStatus()
+	a.emitEvent(models.EventCoreUpdated, results)
+
+	var updated []string
+	for _, r := range results {
+		if r.Success {
+			updated = append(updated, r.Name+" "+r.Version)
+		}
+	}
+	if len(updated) > 0 {
+		a.notify(notify.EventComponent, "内核已更新: "+strings.Join(updated, ", "))
+	}
+	return results, nil
+}
+
+// coreAffectedNodes 替换指定程序前需要停止的运行中节点
+// 所有节点都使用 Xlink 核心；Xray 只在智能分流模式下使用
+func (a *App) coreAffectedNodes(staged map[string]string) []string {
+	_, xlink := staged[engine.XlinkBinaryName]
+	_, xray := staged[engine.XrayBinaryName]
+
+	var ids []string
+	for _, id := range a.runningNodeIDs() {
+		node := a.state.GetNode(id)
+		if node == nil {
+			continue
+		}
+		if xlink || (xray && node.RoutingMode == models.RoutingModeSmart) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// logNodeEvent 以节点名称记录系统类日志
+func (a *App) logNodeEvent(id, level, message string) {
+	name := id
+	if node := a.state.GetNode(id); node != nil {
+		name = node.Name
+	}
+	a.logManager.LogNode(id, name, level, logger.CategorySystem, message)
+}
+
+// startCoreUpdateLoop 启用自动更新时定期检查并安装
+func (a *App) startCoreUpdateLoop() {
+	system.CleanupCoreBackups(a.state.ExeDir)
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(coreUpdateCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			settings := a.GetCoreUpdateSettings()
+			if !settings.AutoUpdate || len(settings.Feeds) == 0 {
+				continue
+			}
+			if _, err := a.updateCore(nil); err != nil {
+				a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("内核自动更新失败: %v", err))
+			}
+		}
+	}()
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 崩溃报告
+// =============================================================================
+
+// saveCrashReport 内核异常退出时保存报告（最后输出、退出码、脱敏后的配置）并通知界面
+func (a *App) saveCrashReport(report engine.CrashReport) {
+	if err := a.crashStore.Save(report); err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("保存崩溃报告失败: %v", err))
+		return
+	}
+	a.logManager.LogNode(report.NodeID, report.NodeName, logger.LevelInfo, logger.CategorySystem,
+		fmt.Sprintf("已保存崩溃报告 %s（退出码 %d，最后 %d 行输出）", report.ID, report.ExitCode, len(report.Output)))
+	a.emitEvent(models.EventCrashReport, report)
+}
+
+// GetCrashReports 获取内核崩溃报告（最新的在前，最多保留 20 份）
+func (a *App) GetCrashReports() []engine.CrashReport {
+	return a.crashStore.List()
+}
+
+// ClearCrashReports 删除全部崩溃报告，返回删除的数量
+func (a *App) ClearCrashReports() (int, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
+	}
+	return a.crashStore.Clear()
+}
+
+// OpenCrashReportFolder 打开崩溃报告目录
+func (a *App) OpenCrashReportFolder() error {
+	return system.OpenFolder(a.crashStore.Dir())
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 用户数据目录（多用户隔离）
+// =============================================================================
+
+// legacyConfigFiles 旧版共享布局中保存在程序目录的配置文件（用于检测旧布局）
+var legacyConfigFiles = []string{config.ConfigFileNameEnc, config.ConfigFileName, "xlink_config.dat"}
+
+// legacyStateFiles 随配置一并迁移的用户数据
+var legacyStateFiles = []string{config.ConfigBackupDir, ServerMemoryFileName, LeakHistoryFileName}
+
+// movedStateFiles 切换存储模式时随配置一并移动的数据（生成的内核配置不移动，按保留策略在旧目录清理）
+var movedStateFiles = append(append([]string(nil), legacyStateFiles...),
+	logger.LogDirName, logger.LatencyHistoryFileName, SystemJournalFileName, engine.CrashDirName, system.WebviewDataDirName)
+
+// dataLayout 启动时确定的数据目录
+type dataLayout struct {
+	Dir       string
+	Portable  bool
+	Migrated  []string // 从程序目录迁移的文件
+	MovedFrom string   // 切换存储模式后，数据从该目录移动而来
+	Moved     []string // 切换存储模式后移动的文件
+	Err       error    // 创建目录或迁移失败
+}
+
+// 存储模式
+const (
+	StorageModePortable = "portable" // 保存在程序目录
+	StorageModeUser     = "user"     // 保存在当前用户的目录（%APPDATA%\XlinkClient）
+)
+
+// DataLocation 数据目录信息
+type DataLocation struct {
+	ExeDir   string `json:"exe_dir"`
+	DataDir  string `json:"data_dir"`
+	Portable bool   `json:"portable"` // 程序目录中有 portable 文件，数据保存在程序目录
+	// NextDir 已切换存储模式、重启后使用的目录（与 DataDir 相同时为空）
+	NextDir string `json:"next_dir,omitempty"`
+}
+
+// resolveDataLayout 确定用户数据目录：先完成切换存储模式后的数据移动，首次使用时从旧版共享布局迁移配置
+func resolveDataLayout(exeDir string) *dataLayout {
+	dir, portable, err := system.ResolveDataDir(exeDir)
+	layout := &dataLayout{Dir: dir, Portable: portable, Err: err}
+	if err != nil {
+		return layout
+	}
+	layout.MovedFrom, layout.Moved, layout.Err = system.MovePendingData(dir, legacyConfigFiles, movedStateFiles)
+	if layout.Err != nil || portable {
+		return layout
+	}
+	layout.Migrated, layout.Err = system.MigrateLegacyData(exeDir, dir, legacyConfigFiles, legacyStateFiles)
+	return layout
+}
+
+// GetDataLocation 获取程序目录与用户数据目录
+func (a *App) GetDataLocation() DataLocation {
+	return DataLocation{
+		ExeDir:   a.state.ExeDir,
+		DataDir:  a.state.DataDir,
+		Portable: a.state.DataDir == a.state.ExeDir,
+		NextDir:  a.nextDataDir(),
+	}
+}
+
+// SetStorageMode 切换存储模式（portable 或 user），重启后生效：下次启动时把配置、备份、日志等移动到新目录
+// 程序安装在 Program Files 等不可写的目录时不能使用便携模式；后台服务使用安装时的数据目录，须先卸载服务
+func (a *App) SetStorageMode(mode string) (DataLocation, error) {
+	if err := a.checkWritable(); err != nil {
+		return a.GetDataLocation(), err
+	}
+	if mode != StorageModePortable && mode != StorageModeUser {
+		return a.GetDataLocation(), i18n.Errorf("未知的存储模式: %s", mode)
+	}
+	if a.headless || system.QueryService().Installed {
+		return a.GetDataLocation(), i18n.Errorf("后台服务使用安装时的数据目录，请先卸载后台服务再切换存储模式")
+	}
+
+	next, err := system.SetStorageMode(a.state.ExeDir, a.state.DataDir, mode == StorageModePortable)
+	if err != nil {
+		return a.GetDataLocation(), i18n.Errorf("切换存储模式失败: %w", err)
+	}
+	if next != a.state.DataDir {
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("存储模式已切换，重启后数据将移动到 %s", next))
+	}
+	return a.GetDataLocation(), nil
+}
+
+// nextDataDir 按当前的便携模式标记，重启后使用的数据目录（与当前目录相同时为空）
+func (a *App) nextDataDir() string {
+	next := a.state.ExeDir
+	if !system.IsPortable(a.state.ExeDir) {
+		dir, err := system.UserDataDir()
+		if err != nil {
+			return ""
+		}
+		next = dir
+	}
+	if filepath.Clean(next) == filepath.Clean(a.state.DataDir) {
+		return ""
+	}
+	return next
+}
+
+// logDataLayout 记录启动时的数据目录与迁移结果（日志管理器初始化后调用）
+func (a *App) logDataLayout(layout *dataLayout) {
+	if layout == nil {
+		return
+	}
+	if layout.Portable {
+		a.logManager.LogSystem(logger.LevelInfo, "便携模式：数据保存在程序目录")
+	}
+	if len(layout.Moved) > 0 {
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("存储模式已切换，已从 %s 移动到 %s: %s",
+			layout.MovedFrom, layout.Dir, strings.Join(layout.Moved, ", ")))
+	}
+	if len(layout.Migrated) > 0 {
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已从程序目录迁移到用户数据目录 %s: %s（程序目录中的原文件保留，供其他用户迁移）",
+			layout.Dir, strings.Join(layout.Migrated, ", ")))
+	}
+	if layout.Err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, layout.Err.Error())
+	}
+}
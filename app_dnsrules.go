@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 自定义 DNS 规则与 DNS 配置包导入导出
+// =============================================================================
+
+// GetDNSSettings 获取自定义 DNS 规则
+func (a *App) GetDNSSettings() models.DNSSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.DNS
+}
+
+// SetDNSSettings 保存自定义 DNS 规则（本机 DNS 服务立即生效，运行中的节点重启后生效）
+func (a *App) SetDNSSettings(settings models.DNSSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := normalizeDNSSettings(&settings); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	a.state.Config.DNS = settings
+	a.state.Mu.Unlock()
+	a.dnsManager.SetCustomRules(settings)
+	go a.saveConfig()
+	return nil
+}
+
+// ExportDNSBundle 将 DNS 模式、本机 DNS 上游与自定义规则导出为文件，返回保存路径（取消时为空）
+func (a *App) ExportDNSBundle() (string, error) {
+	a.state.Mu.RLock()
+	bundle := config.NewDNSBundle(a.state.Config)
+	a.state.Mu.RUnlock()
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: "xlink_dns.json",
+		Filters:         []runtime.FileFilter{{DisplayName: "DNS 配置包 (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", i18n.Errorf("写入文件失败: %w", err)
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已导出 DNS 配置包: %s", path))
+	return path, nil
+}
+
+// ImportDNSBundle 选择 DNS 配置包文件并导入，返回文件路径（取消时为空）
+func (a *App) ImportDNSBundle() (string, error) {
+	if err := a.checkWritable(); err != nil {
+		return "", err
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Filters: []runtime.FileFilter{{DisplayName: "DNS 配置包 (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, a.ImportDNSBundleFile(path)
+}
+
+// ImportDNSBundleFile 导入 DNS 配置包：替换 DNS 模式、本机 DNS 上游与自定义规则，节点不变
+func (a *App) ImportDNSBundleFile(path string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return i18n.Errorf("读取文件失败: %w", err)
+	}
+	bundle, err := config.ParseDNSBundle(data)
+	if err != nil {
+		return err
+	}
+	upstream, err := normalizeUpstreamList(bundle.Upstream)
+	if err != nil {
+		return err
+	}
+	bundle.Upstream = upstream
+	if err := normalizeDNSSettings(&bundle.DNS); err != nil {
+		return err
+	}
+
+	a.backupBeforeChange(config.BackupReasonRestore)
+	a.state.Mu.Lock()
+	bundle.Apply(a.state.Config)
+	a.state.Mu.Unlock()
+	a.dnsManager.SetCustomRules(bundle.DNS)
+	go a.saveConfig()
+
+	if err := a.applyLocalDNSSettings(); err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("导入 DNS 配置包后重启本机 DNS 服务失败: %v", err))
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已导入 DNS 配置包: %s（运行中的节点重启后生效）", path))
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// normalizeUpstreamList 去除空白项并校验上游 DNS
+func normalizeUpstreamList(list []string) ([]string, error) {
+	upstream := make([]string, 0, len(list))
+	for _, addr := range list {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		if _, err := dns.NormalizeUpstream(addr); err != nil {
+			return nil, i18n.Errorf("上游 DNS 无效: %w", err)
+		}
+		upstream = append(upstream, addr)
+	}
+	return upstream, nil
+}
+
+// normalizeDNSSettings 去除空白项并校验 hosts 与预设
+func normalizeDNSSettings(s *models.DNSSettings) error {
+	filter := make([]string, 0, len(s.FakeIPFilter))
+	for _, pattern := range s.FakeIPFilter {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			filter = append(filter, pattern)
+		}
+	}
+	s.FakeIPFilter = filter
+
+	hosts := make(map[string]string, len(s.Hosts))
+	for domain, ip := range s.Hosts {
+		domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+		ip = strings.TrimSpace(ip)
+		if domain == "" {
+			continue
+		}
+		if net.ParseIP(ip) == nil {
+			return i18n.Errorf("hosts 中 %s 的地址无效: %s", domain, ip)
+		}
+		hosts[domain] = ip
+	}
+	s.Hosts = hosts
+
+	presets := make([]models.DNSServerPreset, 0, len(s.Presets))
+	for _, p := range s.Presets {
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" {
+			return i18n.Errorf("DNS 预设名称不能为空")
+		}
+		servers, err := normalizeUpstreamList(p.Servers)
+		if err != nil {
+			return err
+		}
+		if len(servers) == 0 {
+			return i18n.Errorf("DNS 预设 %s 没有服务器", p.Name)
+		}
+		p.Servers = servers
+		presets = append(presets, p)
+	}
+	s.Presets = presets
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 内部 HTTP 请求出口策略
+// =============================================================================
+
+// EgressStatus 内部请求当前实际使用的出口
+type EgressStatus struct {
+	Mode     int    `json:"mode"`      // 配置的策略 (models.Egress*)
+	NodeID   string `json:"node_id"`   // 实际使用的节点，直连时为空
+	NodeName string `json:"node_name"` // 实际使用的节点名称
+	Proxy    string `json:"proxy"`     // SOCKS5 代理地址，直连时为空
+	Fallback bool   `json:"fallback"`  // 所选出口不可用，已回退
+}
+
+// GetEgressStatus 获取内部请求的出口策略及当前实际出口
+func (a *App) GetEgressStatus() EgressStatus {
+	return a.resolveEgress()
+}
+
+// SetEgressPolicy 设置内部请求的出口策略；mode 为 EgressNode 时需指定 nodeID
+func (a *App) SetEgressPolicy(mode int, nodeID string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	switch mode {
+	case models.EgressDirect, models.EgressActiveNode:
+		nodeID = ""
+	case models.EgressNode:
+		if a.state.GetNode(nodeID) == nil {
+			return i18n.Errorf("节点不存在")
+		}
+	default:
+		return i18n.Errorf("无效的出口策略: %d", mode)
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.EgressMode = mode
+	a.state.Config.EgressNodeID = nodeID
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// resolveEgress 按策略选择出口：指定节点 → 当前运行的节点 → 直连
+func (a *App) resolveEgress() EgressStatus {
+	a.state.Mu.RLock()
+	mode := a.state.Config.EgressMode
+	preferred := a.state.Config.EgressNodeID
+	a.state.Mu.RUnlock()
+
+	status := EgressStatus{Mode: mode}
+	if mode == models.EgressDirect {
+		return status
+	}
+
+	id := ""
+	if mode == models.EgressNode {
+		if st, ok := a.engineManager.GetAllStatuses()[preferred]; ok && st.Status == models.StatusRunning {
+			id = preferred
+		} else {
+			status.Fallback = true
+		}
+	}
+	if id == "" {
+		id = a.activeNodeID()
+	}
+
+	node := a.state.GetNode(id)
+	if node == nil {
+		status.Fallback = true
+		return status
+	}
+	status.NodeID = node.ID
+	status.NodeName = node.Name
+	status.Proxy = loopbackListen(node.Listen)
+	return status
+}
+
+// egressClient 创建遵循出口策略的 HTTP 客户端（泄露测试、规则数据下载等内部请求统一使用）
+func (a *App) egressClient(timeout time.Duration) *http.Client {
+	egress := a.resolveEgress()
+	if egress.Fallback && egress.Mode != models.EgressDirect {
+		if egress.NodeID == "" {
+			a.logManager.LogSystem(logger.LevelDebug, "没有运行中的节点，内部请求改为直连")
+		} else {
+			a.logManager.LogSystem(logger.LevelDebug, fmt.Sprintf("指定的出口节点未运行，内部请求改经 %s", egress.NodeName))
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if egress.Proxy != "" {
+		transport.Proxy = http.ProxyURL(&url.URL{Scheme: "socks5", Host: egress.Proxy})
+	} else {
+		// 直连时不受系统代理环境变量影响
+		transport.Proxy = nil
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// loopbackListen 将通配监听地址转换为本机回环地址，便于本地连接
+func loopbackListen(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if ip != nil && ip.To4() == nil {
+			host = "::1"
+		} else {
+			host = "127.0.0.1"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"xlink-wails/internal/generator"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+)
+
+// =============================================================================
+// 导出为 Clash Meta / sing-box 配置
+// =============================================================================
+
+// exportFileUnsafe 文件名中不允许的字符
+var exportFileUnsafe = regexp.MustCompile(`[\\/:*?"<>|\s]+`)
+
+// ExportNodeConfig 将节点及其规则（含引用的规则组）转换为 Clash Meta YAML 或 sing-box JSON
+func (a *App) ExportNodeConfig(nodeID, format string) (*generator.ExportResult, error) {
+	if format != generator.ExportFormatClash && format != generator.ExportFormatSingBox {
+		return nil, i18n.Errorf("不支持的导出格式: %s", format)
+	}
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+	return generator.ExportNodeConfig(a.ruleGroupNode(node), format)
+}
+
+// SaveNodeConfigExport 转换节点配置并保存到文件，返回保存路径（取消时为空）
+func (a *App) SaveNodeConfigExport(nodeID, format string) (string, error) {
+	result, err := a.ExportNodeConfig(nodeID, format)
+	if err != nil {
+		return "", err
+	}
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return "", i18n.Errorf("节点不存在")
+	}
+
+	ext, filter := ".yaml", runtime.FileFilter{DisplayName: "Clash 配置 (*.yaml)", Pattern: "*.yaml;*.yml"}
+	if format == generator.ExportFormatSingBox {
+		ext, filter = ".json", runtime.FileFilter{DisplayName: "sing-box 配置 (*.json)", Pattern: "*.json"}
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: fmt.Sprintf("%s_%s%s", exportFileUnsafe.ReplaceAllString(node.Name, "_"), format, ext),
+		Filters:         []runtime.FileFilter{filter},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(result.Content), 0644); err != nil {
+		return "", i18n.Errorf("写入文件失败: %w", err)
+	}
+
+	for _, s := range result.Skipped {
+		a.logManager.LogSystem(logger.LevelWarn, "导出时跳过规则: "+s)
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已导出 %s 配置: %s", format, path))
+	return path, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/syschange"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// Windows 防火墙入站规则
+// =============================================================================
+
+// GetFirewallStatus 诊断核心程序的防火墙入站规则
+func (a *App) GetFirewallStatus() *system.FirewallStatus {
+	return system.GetFirewallStatus(a.state.ExeDir)
+}
+
+// FirewallChangeResult 添加/删除防火墙规则的结果
+type FirewallChangeResult struct {
+	Plan   *syschange.Plan        `json:"plan"`
+	Status *system.FirewallStatus `json:"status"` // 执行后的状态；预览模式下为当前状态
+}
+
+// AddFirewallRules 为核心程序创建入站允许规则（需要管理员授权，预览模式下只返回将执行的命令）
+func (a *App) AddFirewallRules() (*FirewallChangeResult, error) {
+	plan, err := system.PlanFirewallRules(a.state.ExeDir)
+	if err != nil {
+		return nil, err
+	}
+	return a.applyFirewallChange(plan, func() error { return system.AddFirewallRules(a.state.ExeDir) })
+}
+
+// RemoveFirewallRules 删除本程序创建的防火墙入站规则（需要管理员授权，预览模式下只返回将执行的命令）
+func (a *App) RemoveFirewallRules() (*FirewallChangeResult, error) {
+	plan, err := system.PlanRemoveFirewallRules(a.state.ExeDir)
+	if err != nil {
+		return nil, err
+	}
+	return a.applyFirewallChange(plan, func() error { return system.RemoveFirewallRules(a.state.ExeDir) })
+}
+
+func (a *App) applyFirewallChange(plan *syschange.Plan, run func() error) (*FirewallChangeResult, error) {
+	plan, err := a.applySystemChange(plan, run)
+	if err != nil {
+		return nil, err
+	}
+	return &FirewallChangeResult{Plan: plan, Status: a.GetFirewallStatus()}, nil
+}
+
+// checkFirewall 启动时检查核心是否被防火墙阻止
+func (a *App) checkFirewall() {
+	status := system.GetFirewallStatus(a.state.ExeDir)
+	if !status.Supported {
+		return
+	}
+	if status.Error != "" {
+		a.logManager.LogSystem(logger.LevelDebug, status.Error)
+		return
+	}
+	// 仅监听本机时不需要入站规则，启动时只提示被阻止的情况
+	for _, w := range firewallWarnings(status, false) {
+		a.logManager.LogSystem(logger.LevelWarn, w)
+	}
+}
+
+// firewallWarnings 根据诊断结果生成提示，includeMissing 时也提示缺少允许规则
+func firewallWarnings(status *system.FirewallStatus, includeMissing bool) []string {
+	var blocked, missing []string
+	for _, p := range status.Programs {
+		switch {
+		case !p.Present:
+		case p.Blocked:
+			blocked = append(blocked, p.Program)
+		case !p.Allowed:
+			missing = append(missing, p.Program)
+		}
+	}
+
+	var warnings []string
+	if len(blocked) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Windows 防火墙阻止了 %s 的入站连接，局域网共享和 UDP 将不可用，请在诊断中添加防火墙规则", strings.Join(blocked, "、")))
+	}
+	if includeMissing && len(missing) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s 没有防火墙入站允许规则，局域网设备可能无法连接", strings.Join(missing, "、")))
+	}
+	return warnings
+}
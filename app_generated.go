@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/generator"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 生成的配置文件
+// =============================================================================
+
+// GetGeneratedFilesSettings 获取生成文件的保留策略
+func (a *App) GetGeneratedFilesSettings() models.GeneratedFilesSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.GeneratedFiles
+}
+
+// SetGeneratedFilesSettings 保存生成文件的保留策略（下次退出时生效）
+func (a *App) SetGeneratedFilesSettings(settings models.GeneratedFilesSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	a.state.Config.GeneratedFiles = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	return nil
+}
+
+// GetGeneratedFiles 列出程序生成的内核配置文件
+func (a *App) GetGeneratedFiles() []generator.GeneratedFile {
+	return a.configGenerator.GeneratedFiles()
+}
+
+// PurgeGeneratedFiles 立即删除生成的配置文件（运行中节点的配置保留），返回删除的文件数
+// 作为后台服务的控制前端时按服务中的节点状态保留，无法获取状态时不删除
+func (a *App) PurgeGeneratedFiles() (int, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
+	}
+	statuses := a.engineManager.GetAllStatuses()
+	if a.serviceFront.Load() {
+		var err error
+		if statuses, err = a.newServiceClient().Status(); err != nil {
+			return 0, i18n.Errorf("后台服务无响应: %w", err)
+		}
+	}
+	removed, err := a.configGenerator.RemoveGenerated(func(nodeID string) bool {
+		st, ok := statuses[nodeID]
+		return ok && st.Status == models.StatusRunning
+	})
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已删除 %d 个生成的配置文件", removed))
+	return removed, err
+}
+
+// cleanupGeneratedFiles 启动和退出时按保留策略清理：保留最近配置时只删除已不存在的节点的文件，否则全部删除
+// 返回删除的文件数
+func (a *App) cleanupGeneratedFiles() int {
+	a.state.Mu.RLock()
+	keepLast := a.state.Config.GeneratedFiles.KeepLast
+	nodes := make(map[string]bool, len(a.state.Config.Nodes))
+	for _, n := range a.state.Config.Nodes {
+		nodes[n.ID] = true
+	}
+	a.state.Mu.RUnlock()
+
+	var keep func(string) bool
+	if keepLast {
+		keep = func(nodeID string) bool { return nodes[nodeID] }
+	}
+	removed, err := a.configGenerator.RemoveGenerated(keep)
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("清理生成的配置文件失败: %v", err))
+	}
+	return removed
+}
+
+// cleanupLastSession 启动时结束上次运行遗留的内核进程，删除遗留的配置和临时文件（节点由后台服务运行时不调用）
+// 上次崩溃后遗留的内核会继续占用端口，需在启动任何节点之前结束
+func (a *App) cleanupLastSession() {
+	killed, err := a.engineManager.CleanupOrphans()
+	for _, p := range killed {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("已结束上次运行遗留的内核进程 %s (PID %d)", p.Name, p.PID))
+	}
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("结束遗留的内核进程失败: %v", err))
+	}
+
+	if removed := a.cleanupGeneratedFiles() + a.engineManager.RemoveStaleTempFiles(); removed > 0 {
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已清理上次运行遗留的 %d 个配置文件", removed))
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// 规则数据 (geosite.dat / geoip.dat) 下载与更新
+// =============================================================================
+
+const (
+	// geoDataCheckInterval 检查规则数据是否过期的间隔
+	geoDataCheckInterval = 6 * time.Hour
+	// geoDataTimeout 单次更新的总超时
+	geoDataTimeout = 10 * time.Minute
+)
+
+// GeoDataStatus 规则数据状态
+type GeoDataStatus struct {
+	Updating   bool     `json:"updating"`
+	LastUpdate int64    `json:"last_update"` // 最旧文件的修改时间（Unix 秒），有文件缺失时为 0
+	Missing    []string `json:"missing"`
+	UpdateDays int      `json:"update_days"`
+	Mirrors    []string `json:"mirrors"` // 为空时使用内置镜像
+}
+
+// GetGeoDataStatus 获取规则数据状态
+func (a *App) GetGeoDataStatus() GeoDataStatus {
+	a.state.Mu.RLock()
+	days := a.state.Config.GeoDataUpdateDays
+	mirrors := append([]string(nil), a.state.Config.GeoDataMirrors...)
+	a.state.Mu.RUnlock()
+
+	status := GeoDataStatus{
+		Updating:   a.geoData.IsUpdating(),
+		Missing:    a.geoData.Missing(),
+		UpdateDays: days,
+		Mirrors:    mirrors,
+	}
+	if t := a.geoData.OldestUpdate(); !t.IsZero() {
+		status.LastUpdate = t.Unix()
+	}
+	return status
+}
+
+// SetGeoDataSettings 设置规则数据的下载镜像（为空时使用内置镜像）和自动更新间隔（天，0 表示关闭）
+func (a *App) SetGeoDataSettings(mirrors []string, updateDays int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if updateDays < 0 {
+		return i18n.Errorf("更新间隔不能为负数")
+	}
+	var cleaned []string
+	for _, m := range mirrors {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		if u, err := url.Parse(m); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("下载镜像地址无效: %s", m)
+		}
+		cleaned = append(cleaned, m)
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.GeoDataMirrors = cleaned
+	a.state.Config.GeoDataUpdateDays = updateDays
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// SetDisableGeoFallback 设置缺少规则数据时是否不使用内置的私有/中国地址列表代替，节点重新启动后生效
+func (a *App) SetDisableGeoFallback(disabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	a.state.Config.DisableGeoFallback = disabled
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.dnsManager.SetGeoFallback(!disabled)
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// GeoDataMissingInfo 生成节点配置时因缺少规则数据跳过的规则
+type GeoDataMissingInfo struct {
+	NodeID       string               `json:"node_id"`
+	NodeName     string               `json:"node_name"`
+	MissingFiles []string             `json:"missing_files"`
+	SkippedRules []dns.SkippedGeoRule `json:"skipped_rules"`
+	Fallback     bool                 `json:"fallback"` // 已使用内置列表代替部分规则
+}
+
+// UpdateGeoData 立即更新规则数据（后台执行，进度通过 geodata:progress 事件推送）
+func (a *App) UpdateGeoData() error {
+	if a.geoData.IsUpdating() {
+		return i18n.Errorf("规则数据正在更新中")
+	}
+	go a.runGeoDataUpdate()
+	return nil
+}
+
+// startGeoDataLoop 启动时补齐缺失文件，之后定期检查是否需要更新
+func (a *App) startGeoDataLoop() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	go func() {
+		if missing := a.geoData.Missing(); len(missing) > 0 {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("缺少规则数据 %s，智能分流将降级，正在自动下载", strings.Join(missing, ", ")))
+			a.runGeoDataUpdate(missing...)
+		}
+
+		ticker := time.NewTicker(geoDataCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			a.state.Mu.RLock()
+			days := a.state.Config.GeoDataUpdateDays
+			a.state.Mu.RUnlock()
+			if days <= 0 {
+				continue
+			}
+
+			oldest := a.geoData.OldestUpdate()
+			if oldest.IsZero() || time.Since(oldest) >= time.Duration(days)*24*time.Hour {
+				a.runGeoDataUpdate()
+			}
+		}
+	}()
+}
+
+// runGeoDataUpdate 执行一次更新并推送结果
+func (a *App) runGeoDataUpdate(files ...string) {
+	a.state.Mu.RLock()
+	mirrors := append([]string(nil), a.state.Config.GeoDataMirrors...)
+	a.state.Mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(a.ctx, geoDataTimeout)
+	defer cancel()
+
+	a.logManager.LogSystem(logger.LevelInfo, "开始更新规则数据...")
+	results, err := a.geoData.Update(ctx, a.egressClient(geoDataTimeout), mirrors, files...)
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, err.Error())
+		return
+	}
+
+	updated := 0
+	for _, r := range results {
+		if r.Success {
+			updated++
+			a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("%s 已更新 (%d KB, 来源 %s)", r.File, r.Size/1024, r.Mirror))
+		} else {
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("%s 更新失败: %s", r.File, r.Error))
+		}
+	}
+	a.emitEvent(models.EventGeoDataComplete, results)
+
+	if updated == 0 {
+		return
+	}
+	a.RefreshComponentStatus()
+
+	// 正在运行的智能分流节点需要重启才会加载新数据
+	if a.hasRunningSmartNode() {
+		a.notify(notify.EventGeoData, "规则数据已更新，重启智能分流节点后生效")
+	}
+}
+
+// reportSkippedGeoRules 缺少规则数据时记录并推送被跳过的规则，前端据此提示一键下载
+func (a *App) reportSkippedGeoRules(node *models.NodeConfig, hasGeosite, hasGeoip bool) {
+	skipped := a.dnsManager.SkippedGeoRules(node, hasGeosite, hasGeoip)
+	if len(skipped) == 0 {
+		return
+	}
+
+	info := GeoDataMissingInfo{NodeID: node.ID, NodeName: node.Name, SkippedRules: skipped}
+	if !hasGeosite {
+		info.MissingFiles = append(info.MissingFiles, "geosite.dat")
+	}
+	if !hasGeoip {
+		info.MissingFiles = append(info.MissingFiles, "geoip.dat")
+	}
+
+	names := make([]string, 0, len(skipped))
+	for _, r := range skipped {
+		if r.Substituted {
+			info.Fallback = true
+			names = append(names, r.Name+"（已用内置列表代替）")
+		} else {
+			names = append(names, r.Name)
+		}
+	}
+	a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("[%s] 缺少 %s，以下规则未生效: %s",
+		node.Name, strings.Join(info.MissingFiles, ", "), strings.Join(names, "; ")))
+	a.emitEvent(models.EventGeoDataMissing, info)
+}
+
+// onGeoDataProgress 转发下载进度
+func (a *App) onGeoDataProgress(p dns.GeoDataProgress) {
+	a.emitEvent(models.EventGeoDataProgress, p)
+}
+
+// hasRunningSmartNode 是否有运行中的智能分流节点
+func (a *App) hasRunningSmartNode() bool {
+	for _, id := range a.runningNodeIDs() {
+		if node := a.state.GetNode(id); node != nil && node.RoutingMode == models.RoutingModeSmart {
+			return true
+		}
+	}
+	return false
+}
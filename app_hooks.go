@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"xlink-wails/internal/hook"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点启动 / 停止钩子
+// =============================================================================
+
+// GetHookSettings 获取钩子命令设置
+func (a *App) GetHookSettings() models.HookSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.Hooks
+}
+
+// SetHookSettings 保存钩子命令设置，对之后启动 / 停止的节点生效
+func (a *App) SetHookSettings(settings models.HookSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if settings.Timeout < 0 || settings.Timeout > models.MaxHookTimeout {
+		return i18n.Errorf("钩子超时应在 0-%d 秒之间", models.MaxHookTimeout)
+	}
+	settings.PostStart = strings.TrimSpace(settings.PostStart)
+	settings.PostStop = strings.TrimSpace(settings.PostStop)
+
+	a.state.Mu.Lock()
+	a.state.Config.Hooks = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	return nil
+}
+
+// TestHook 以指定节点的信息立即执行一次钩子命令（event: post_start / post_stop），返回执行结果
+func (a *App) TestHook(event, nodeID string) (*hook.Result, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+	command, settings := a.hookCommand(event)
+	if command == "" {
+		return nil, i18n.Errorf("未设置该事件的钩子命令")
+	}
+
+	status := models.StatusRunning
+	if event == hook.EventPostStop {
+		status = models.StatusStopped
+	}
+	return a.runHook(command, settings, node, event, status, ""), nil
+}
+
+// onHookNodeStatus 节点状态变化时执行钩子：进入运行状态时执行启动钩子，
+// 运行过的节点停止、出错或异常退出等待重启时执行停止钩子（每个节点启动 / 停止各执行一次）
+func (a *App) onHookNodeStatus(nodeID, status string, err error) {
+	var event string
+	a.hookMu.Lock()
+	switch status {
+	case models.StatusRunning:
+		if !a.hookStarted[nodeID] {
+			if a.hookStarted == nil {
+				a.hookStarted = make(map[string]bool)
+			}
+			a.hookStarted[nodeID] = true
+			event = hook.EventPostStart
+		}
+	case models.StatusStopped, models.StatusError, models.StatusRestarting:
+		if a.hookStarted[nodeID] {
+			delete(a.hookStarted, nodeID)
+			event = hook.EventPostStop
+		}
+	}
+	a.hookMu.Unlock()
+	if event == "" {
+		return
+	}
+
+	command, settings := a.hookCommand(event)
+	node := a.state.GetNode(nodeID)
+	if command == "" || node == nil {
+		return
+	}
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	go a.runHook(command, settings, node, event, status, reason)
+}
+
+// hookCommand 事件对应的命令，未设置时为空
+func (a *App) hookCommand(event string) (string, models.HookSettings) {
+	a.state.Mu.RLock()
+	settings := a.state.Config.Hooks
+	a.state.Mu.RUnlock()
+
+	switch event {
+	case hook.EventPostStart:
+		return settings.PostStart, settings
+	case hook.EventPostStop:
+		return settings.PostStop, settings
+	}
+	return "", settings
+}
+
+// runHook 执行钩子命令，输出逐行记录到节点日志
+func (a *App) runHook(command string, settings models.HookSettings, node *models.NodeConfig, event, status, reason string) *hook.Result {
+	label := "启动钩子"
+	if event == hook.EventPostStop {
+		label = "停止钩子"
+	}
+
+	result := hook.Run(command, hook.Context{
+		Event:    event,
+		NodeID:   node.ID,
+		NodeName: node.Name,
+		Listen:   node.Listen,
+		Status:   status,
+		Error:    reason,
+	}, settings.TimeoutDuration())
+
+	for _, line := range result.Lines() {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategorySystem, fmt.Sprintf("[%s] %s", label, line))
+	}
+	if result.Error != "" {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelWarn, logger.CategorySystem,
+			fmt.Sprintf("%s执行失败: %s", label, result.Error))
+	} else {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategorySystem,
+			fmt.Sprintf("%s执行完成 (%d ms)", label, result.Duration))
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 从订阅地址导入
+// =============================================================================
+
+const (
+	// importFetchTimeout 下载订阅内容的超时
+	importFetchTimeout = 30 * time.Second
+	// importMaxSize 订阅内容大小上限
+	importMaxSize = 10 << 20
+)
+
+// URLImportResult 从地址导入的结果；预览时 Nodes 为将导入的节点，Count 为 0
+type URLImportResult struct {
+	Format  string               `json:"format"` // 识别出的内容格式 (config.ImportFormat*)
+	Nodes   []models.NodeConfig  `json:"nodes"`
+	Count   int                  `json:"count"`             // 实际导入的数量
+	Skipped int                  `json:"skipped,omitempty"` // 超出节点数量上限未导入的数量
+	Limit   int                  `json:"limit,omitempty"`   // 有节点被跳过时的节点数量上限
+	DryRun  bool                 `json:"dry_run"`           // 仅预览，未写入配置
+	Issues  []config.ImportIssue `json:"issues,omitempty"`
+}
+
+// PreviewImportFromURL 下载并解析订阅内容，返回将导入的节点（不修改配置）
+func (a *App) PreviewImportFromURL(rawURL string) (*URLImportResult, error) {
+	parsed, err := a.fetchImport(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &URLImportResult{Format: parsed.Format, Nodes: parsed.Nodes, DryRun: true, Issues: parsed.Issues}, nil
+}
+
+// ImportFromURL 下载订阅内容（经内部请求出口策略），识别 Base64 订阅 / Clash / sing-box / 链接列表后导入
+func (a *App) ImportFromURL(rawURL string) (*URLImportResult, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	parsed, err := a.fetchImport(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	count := a.configManager.AddNodes(parsed.Nodes)
+	result := &URLImportResult{Format: parsed.Format, Nodes: parsed.Nodes[:count], Count: count, Issues: parsed.Issues}
+	if count < len(parsed.Nodes) {
+		result.Skipped = len(parsed.Nodes) - count
+		result.Limit = a.configManager.NodeLimit()
+	}
+	if count == 0 {
+		return nil, i18n.Errorf("节点数量已达上限 (%d)", result.Limit)
+	}
+	a.state.Mu.Lock()
+	a.state.Config = a.configManager.GetConfig()
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	a.emitEvent(models.EventConfigChanged, nil)
+
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已从订阅导入 %d 个节点 (%s)", count, parsed.Format))
+	a.logImportLimit(result.Skipped, result.Limit)
+	return result, nil
+}
+
+// fetchImport 下载并解析订阅内容
+func (a *App) fetchImport(rawURL string) (*config.ParsedImport, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, i18n.Errorf("订阅地址无效: %s", rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, importFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Xlink/"+models.AppVersion)
+
+	resp, err := a.egressClient(importFetchTimeout).Do(req)
+	if err != nil {
+		return nil, i18n.Errorf("下载订阅失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, i18n.Errorf("下载订阅失败: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, importMaxSize+1))
+	if err != nil {
+		return nil, i18n.Errorf("下载订阅失败: %w", err)
+	}
+	if len(data) > importMaxSize {
+		return nil, i18n.Errorf("订阅内容超过大小上限")
+	}
+
+	parsed, err := config.ParseImport(data)
+	if err != nil {
+		return nil, i18n.Errorf("解析订阅失败: %w", err)
+	}
+	for _, issue := range parsed.Issues {
+		if issue.Error != "" {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("导入 %s 节点 [%s] 失败: %s", issue.Scheme, issue.Name, issue.Error))
+		}
+	}
+	return parsed, nil
+}
+
+// =============================================================================
+// 从二维码图片导入
+// =============================================================================
+
+// ImportFromQRImage 选择截图或图片文件，识别其中的二维码并导入节点
+func (a *App) ImportFromQRImage() (*config.QRImport, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Filters: []runtime.FileFilter{{DisplayName: "图片 (*.png;*.jpg;*.jpeg;*.gif)", Pattern: "*.png;*.jpg;*.jpeg;*.gif"}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return a.ImportFromQRImageFile(path)
+}
+
+// ImportFromQRImageFile 识别图片文件中的二维码（xlink:// 等分享链接或 Base64 订阅内容）并导入节点
+func (a *App) ImportFromQRImageFile(path string) (*config.QRImport, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	result, err := a.configManager.ImportQRImage(path)
+	if result != nil {
+		for _, issue := range result.Issues {
+			if issue.Error != "" {
+				a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("导入 %s 节点 [%s] 失败: %s", issue.Scheme, issue.Name, issue.Error))
+			}
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config = a.configManager.GetConfig()
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	a.emitEvent(models.EventConfigChanged, nil)
+
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已从 %d 个二维码导入 %d 个节点", result.Codes, result.Count))
+	a.logImportLimit(result.Skipped, result.Limit)
+	return result, nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// IPv6 状态监控与自动降级
+// =============================================================================
+
+// startIPv6Watcher 启动后台 IPv6 监控（网络变化时重新检测）
+func (a *App) startIPv6Watcher() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	watcher := dns.NewIPv6Watcher(a.dnsManager, a.onIPv6StatusChanged)
+	go watcher.Run(ctx)
+}
+
+// onIPv6StatusChanged 更新状态缓存、通知前端，并在 IPv6 断开/恢复时重启受影响的节点
+func (a *App) onIPv6StatusChanged(old, info *dns.IPv6SupportInfo) {
+	status := toIPv6Status(info)
+	a.state.UpdateIPv6Status(status)
+	a.emitEvent(models.EventIPv6StatusChanged, status)
+
+	if old == nil {
+		if !info.IPv6Connectivity {
+			a.logManager.LogSystem(logger.LevelInfo, "当前网络IPv6不可用，节点将以仅IPv4方式运行")
+		}
+		return
+	}
+	if old.IPv6Connectivity == info.IPv6Connectivity {
+		return
+	}
+
+	affected := a.runningIPv6Nodes()
+	if info.IPv6Connectivity {
+		a.logManager.LogSystem(logger.LevelInfo, "IPv6 已恢复，节点恢复原有IPv6设置")
+	} else {
+		a.logManager.LogSystem(logger.LevelWarn, "IPv6 连接已断开，节点临时降级为仅IPv4")
+		if len(affected) > 0 {
+			a.notify(notify.EventNetwork, fmt.Sprintf("IPv6 不可用，已将 %d 个节点临时切换为仅IPv4", len(affected)))
+		}
+	}
+
+	// 重新生成配置并重启（引擎会先停止再启动）
+	for _, id := range affected {
+		if err := a.StartNode(id); err != nil {
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("重启节点失败: %v", err))
+		}
+	}
+}
+
+// runningIPv6Nodes 返回正在运行且使用IPv6的节点ID
+func (a *App) runningIPv6Nodes() []string {
+	statuses := a.engineManager.GetAllStatuses()
+
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+
+	var ids []string
+	for i := range a.state.Config.Nodes {
+		node := &a.state.Config.Nodes[i]
+		st, ok := statuses[node.ID]
+		if !ok || st.Status != models.StatusRunning {
+			continue
+		}
+		if models.GetEffectiveIPVersion(node) != models.IPVersionIPv4 {
+			ids = append(ids, node.ID)
+		}
+	}
+	return ids
+}
+
+// ipv6FallbackNode IPv6 不可用时返回降级为仅IPv4的节点副本，否则返回原节点
+// 降级只作用于生成的内核配置，不写回用户配置
+func (a *App) ipv6FallbackNode(node *models.NodeConfig) *models.NodeConfig {
+	status := a.state.GetIPv6Status()
+	if status == nil || status.IPv6Connectivity || models.GetEffectiveIPVersion(node) == models.IPVersionIPv4 {
+		return node
+	}
+
+	fallback := *node
+	fallback.EnableIPv6 = false
+	fallback.PreferIPv6 = false
+	fallback.IPv6Only = false
+	fallback.DisableIPv6 = true
+	return &fallback
+}
+
+// =============================================================================
+// 自动 IPv4/IPv6 策略
+// =============================================================================
+
+const (
+	// ipStrategyInterval 运行中节点重新评估的间隔
+	ipStrategyInterval = 10 * time.Minute
+	// ipProbeFreshness 测量结果在此时间内可直接复用（避免重启节点时重复测量）
+	ipProbeFreshness = 30 * time.Second
+)
+
+// autoIPStrategyNode 节点启用自动IP策略时，测量后返回按决策调整的副本
+func (a *App) autoIPStrategyNode(node *models.NodeConfig) *models.NodeConfig {
+	if !node.AutoIPStrategy {
+		return node
+	}
+
+	a.ipProbeMu.Lock()
+	probe := a.ipProbes[node.ID]
+	a.ipProbeMu.Unlock()
+
+	if probe == nil || time.Since(probe.CheckedAt) > ipProbeFreshness {
+		probe = a.probeIPFamilies(node)
+		a.storeIPProbe(node.ID, probe)
+	}
+
+	adjusted := *node
+	probe.Apply(&adjusted)
+	return &adjusted
+}
+
+// probeIPFamilies 测量节点服务器的 IPv4/IPv6 延迟
+func (a *App) probeIPFamilies(node *models.NodeConfig) *dns.IPFamilyProbe {
+	ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+	defer cancel()
+
+	probe := dns.ProbeIPFamilies(ctx, node.Server)
+
+	a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategorySystem,
+		fmt.Sprintf("自动IP策略: IPv4 %dms / IPv6 %dms -> %s", probe.IPv4Latency, probe.IPv6Latency, probe.Decision))
+	return probe
+}
+
+// startIPStrategyLoop 定期重新评估运行中的自动策略节点，决策变化时重启节点
+func (a *App) startIPStrategyLoop() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(ipStrategyInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.reevaluateIPStrategy()
+			}
+		}
+	}()
+}
+
+func (a *App) reevaluateIPStrategy() {
+	statuses := a.engineManager.GetAllStatuses()
+
+	a.state.Mu.RLock()
+	var nodes []models.NodeConfig
+	for _, node := range a.state.Config.Nodes {
+		if st, ok := statuses[node.ID]; ok && st.Status == models.StatusRunning && node.AutoIPStrategy {
+			nodes = append(nodes, node)
+		}
+	}
+	a.state.Mu.RUnlock()
+
+	for i := range nodes {
+		node := &nodes[i]
+
+		a.ipProbeMu.Lock()
+		previous := a.ipProbes[node.ID]
+		a.ipProbeMu.Unlock()
+
+		// 只有决定切换时才替换缓存，缓存始终对应当前生效的配置
+		probe := a.probeIPFamilies(node)
+		if !probe.ShouldSwitch(previous) {
+			continue
+		}
+		a.storeIPProbe(node.ID, probe)
+
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategorySystem,
+			fmt.Sprintf("自动IP策略变化 (%s -> %s)，正在重启节点", previous.Decision, probe.Decision))
+		if err := a.StartNode(node.ID); err != nil {
+			a.logManager.LogNode(node.ID, node.Name, logger.LevelError, logger.CategorySystem, fmt.Sprintf("重启失败: %v", err))
+		}
+	}
+}
+
+func (a *App) storeIPProbe(nodeID string, probe *dns.IPFamilyProbe) {
+	a.ipProbeMu.Lock()
+	a.ipProbes[nodeID] = probe
+	a.ipProbeMu.Unlock()
+}
+
+// GetIPStrategyProbe 获取节点当前生效的自动IP策略测量结果
+func (a *App) GetIPStrategyProbe(nodeID string) *dns.IPFamilyProbe {
+	a.ipProbeMu.Lock()
+	defer a.ipProbeMu.Unlock()
+	return a.ipProbes[nodeID]
+}
+
+// GetIPv6Status 获取最近一次 IPv6 检测结果
+func (a *App) GetIPv6Status() *models.IPv6SupportStatus {
+	return a.state.GetIPv6Status()
+}
+
+// CheckIPv6Support 立即检测 IPv6 支持状态
+func (a *App) CheckIPv6Support() *models.IPv6SupportStatus {
+	old := a.state.GetIPv6Status()
+	info := a.dnsManager.CheckIPv6Support()
+
+	var oldInfo *dns.IPv6SupportInfo
+	if old != nil {
+		oldInfo = &dns.IPv6SupportInfo{
+			HasIPv6Interface: old.HasIPv6Interface,
+			HasIPv6Address:   old.HasIPv6Address,
+			HasIPv6Gateway:   old.HasIPv6Gateway,
+			IPv6Connectivity: old.IPv6Connectivity,
+			IPv6Addresses:    old.IPv6Addresses,
+		}
+	}
+	a.onIPv6StatusChanged(oldInfo, info)
+	return a.state.GetIPv6Status()
+}
+
+func toIPv6Status(info *dns.IPv6SupportInfo) *models.IPv6SupportStatus {
+	return &models.IPv6SupportStatus{
+		HasIPv6Interface: info.HasIPv6Interface,
+		HasIPv6Address:   info.HasIPv6Address,
+		HasIPv6Gateway:   info.HasIPv6Gateway,
+		IPv6Connectivity: info.IPv6Connectivity,
+		IPv6Addresses:    info.IPv6Addresses,
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/syschange"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 系统修改日志（异常退出后恢复）
+// =============================================================================
+
+// SystemJournalFileName 系统修改日志文件名
+const SystemJournalFileName = "system_changes.json"
+
+// 日志记录的 Key（同类修改只保留一条）
+const (
+	journalKeyProxy      = "proxy"
+	journalKeyRoute      = "route:default"
+	journalKeyKillSwitch = "kill_switch"
+)
+
+// proxyJournalData 系统代理的撤销数据
+type proxyJournalData struct {
+	Original *system.ProxySettings `json:"original"`
+	PACURL   string                `json:"pac_url,omitempty"`
+}
+
+// recoverSystemChanges 撤销上次异常退出时残留的系统修改（启动时在其他初始化之前调用）
+// 断线保护的阻止规则按设计在崩溃后保持生效，交给 resumeKillSwitch 处理
+func (a *App) recoverSystemChanges() {
+	journal, err := syschange.OpenJournal(filepath.Join(a.state.DataDir, SystemJournalFileName))
+	a.journal = journal
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, err.Error())
+	}
+
+	for _, e := range journal.Pending() {
+		if e.Kind == syschange.KindKillSwitch {
+			continue
+		}
+		if err := undoSystemChange(e); err != nil {
+			// 保留记录，下次启动时重试
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("撤销上次残留的系统修改失败: %s: %v", e.Title, err))
+			continue
+		}
+		journal.Resolve(e.Key)
+		a.logManager.LogSystem(logger.LevelWarn, "上次未正常退出，已撤销残留的系统修改: "+e.Title)
+	}
+}
+
+// undoSystemChange 执行一条记录的撤销
+func undoSystemChange(e syschange.Entry) error {
+	if e.Kind == syschange.KindProxy {
+		var data proxyJournalData
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return err
+		}
+		return system.NewProxyManager().RecoverSystemProxy(data.Original, data.PACURL)
+	}
+
+	if e.Undo == nil {
+		return nil
+	}
+	if errs := e.Undo.RunAll(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// journalRecord 修改系统设置前记录撤销方式（写入失败只记录日志，不阻止修改）
+func (a *App) journalRecord(kind, key, title string, undo *syschange.Plan, data interface{}) {
+	if err := a.journal.Record(kind, key, title, undo, data); err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("写入系统修改日志失败: %v", err))
+	}
+}
+
+// journalUndo 未撤销记录中的撤销命令，没有记录时返回 nil
+func (a *App) journalUndo(key string) *syschange.Plan {
+	for _, e := range a.journal.Pending() {
+		if e.Key == key {
+			return e.Undo
+		}
+	}
+	return nil
+}
+
+// journalResolve 修改已撤销，删除记录
+func (a *App) journalResolve(key string) {
+	if err := a.journal.Resolve(key); err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("写入系统修改日志失败: %v", err))
+	}
+}
+
+// recordProxyChange 修改系统代理前记录原始设置，pacURL 为即将设置的 PAC 地址
+func (a *App) recordProxyChange(pacURL string) {
+	data := proxyJournalData{Original: a.proxyManager.OriginalSettings(), PACURL: pacURL}
+	a.journalRecord(syschange.KindProxy, journalKeyProxy, "系统代理", nil, data)
+}
+
+// journalKeyDNS 网卡 DNS 记录的 Key
+func journalKeyDNS(interfaceName string) string {
+	return "dns:" + interfaceName
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/syschange"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 断线保护（Kill Switch）
+// =============================================================================
+
+// 节点运行中（用户未主动停止）核心进程异常退出时，通过防火墙阻止非代理出站流量，
+// 直到任意节点恢复运行、用户停止该节点或关闭保护。
+
+// KillSwitchStatus 断线保护状态
+type KillSwitchStatus struct {
+	Enabled   bool     `json:"enabled"`
+	Engaged   bool     `json:"engaged"`   // 当前正在阻止出站流量
+	Supported bool     `json:"supported"` // 当前平台支持
+	IsAdmin   bool     `json:"is_admin"`  // 修改防火墙策略需要管理员权限
+	NodeIDs   []string `json:"node_ids"`  // 触发保护的节点
+}
+
+// killSwitchState 断线保护运行时状态（由 killSwitchMu 保护）
+type killSwitchState struct {
+	connected map[string]bool // 应处于连接状态的节点（运行中或异常退出）
+	crashed   map[string]bool // 异常退出、正在等待恢复的节点
+}
+
+// GetKillSwitchStatus 获取断线保护状态
+func (a *App) GetKillSwitchStatus() KillSwitchStatus {
+	a.state.Mu.RLock()
+	status := KillSwitchStatus{
+		Enabled: a.state.Config.KillSwitchEnabled,
+		Engaged: a.state.Config.KillSwitchActive,
+	}
+	a.state.Mu.RUnlock()
+
+	_, err := system.PlanKillSwitchEngage(a.state.ExeDir)
+	status.Supported = err == nil
+	status.IsAdmin = system.IsAdmin()
+
+	a.killSwitchMu.Lock()
+	for id := range a.killSwitch.crashed {
+		status.NodeIDs = append(status.NodeIDs, id)
+	}
+	a.killSwitchMu.Unlock()
+	return status
+}
+
+// SetKillSwitch 开启或关闭断线保护；关闭时立即解除正在生效的阻止
+func (a *App) SetKillSwitch(enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if enabled {
+		if _, err := system.PlanKillSwitchEngage(a.state.ExeDir); err != nil {
+			return err
+		}
+		if !system.IsAdmin() {
+			return i18n.Errorf("断线保护需要修改防火墙策略，请以管理员身份运行")
+		}
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.KillSwitchEnabled = enabled
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	if !enabled {
+		a.killSwitchMu.Lock()
+		a.killSwitch.crashed = make(map[string]bool)
+		err := a.releaseKillSwitchLocked()
+		a.killSwitchMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	a.emitEvent(models.EventKillSwitchChanged, a.GetKillSwitchStatus())
+	return nil
+}
+
+// ReleaseKillSwitch 手动解除正在生效的阻止（保护保持开启）
+func (a *App) ReleaseKillSwitch() error {
+	a.killSwitchMu.Lock()
+	a.killSwitch.crashed = make(map[string]bool)
+	err := a.releaseKillSwitchLocked()
+	a.killSwitchMu.Unlock()
+
+	a.emitEvent(models.EventKillSwitchChanged, a.GetKillSwitchStatus())
+	return err
+}
+
+// PreviewKillSwitch 返回启用与解除断线保护时将执行的命令（不执行）
+func (a *App) PreviewKillSwitch() ([]*syschange.Plan, error) {
+	engage, err := system.PlanKillSwitchEngage(a.state.ExeDir)
+	if err != nil {
+		return nil, err
+	}
+	release := a.journalUndo(journalKeyKillSwitch)
+	if release == nil {
+		previous, _ := system.FirewallPolicies()
+		release = system.PlanKillSwitchRelease(a.state.ExeDir, previous)
+	}
+	engage.DryRun, release.DryRun = true, true
+	return []*syschange.Plan{engage, release}, nil
+}
+
+// onKillSwitchNodeStatus 根据节点状态变化启用或解除保护
+func (a *App) onKillSwitchNodeStatus(nodeID, status string) {
+	a.state.Mu.RLock()
+	enabled := a.state.Config.KillSwitchEnabled
+	active := a.state.Config.KillSwitchActive
+	a.state.Mu.RUnlock()
+
+	a.killSwitchMu.Lock()
+	defer a.killSwitchMu.Unlock()
+
+	if a.killSwitch.connected == nil {
+		a.killSwitch.connected = make(map[string]bool)
+		a.killSwitch.crashed = make(map[string]bool)
+	}
+
+	changed := false
+	switch status {
+	case models.StatusRunning:
+		a.killSwitch.connected[nodeID] = true
+		delete(a.killSwitch.crashed, nodeID)
+		// 任一节点恢复运行即可解除
+		if active {
+			a.killSwitch.crashed = make(map[string]bool)
+			a.releaseKillSwitchLocked()
+			changed = true
+		}
+
+	case models.StatusError:
+		if !a.killSwitch.connected[nodeID] {
+			return // 启动失败，节点从未连接
+		}
+		a.killSwitch.crashed[nodeID] = true
+		if enabled && !active {
+			a.engageKillSwitchLocked(nodeID)
+			changed = true
+		}
+
+	case models.StatusStopped:
+		// 重启节点时旧实例的停止通知可能晚于新实例的运行通知
+		if st, ok := a.engineManager.GetAllStatuses()[nodeID]; ok && st.Status == models.StatusRunning {
+			return
+		}
+		// 用户主动停止，不再需要保持连接
+		delete(a.killSwitch.connected, nodeID)
+		delete(a.killSwitch.crashed, nodeID)
+		if active && len(a.killSwitch.crashed) == 0 {
+			a.releaseKillSwitchLocked()
+			changed = true
+		}
+	}
+
+	if changed {
+		go func() { a.emitEvent(models.EventKillSwitchChanged, a.GetKillSwitchStatus()) }()
+	}
+}
+
+// engageKillSwitchLocked 启用阻止（需持有 killSwitchMu）
+func (a *App) engageKillSwitchLocked(nodeID string) {
+	nodeName := nodeID
+	if node := a.state.GetNode(nodeID); node != nil {
+		nodeName = node.Name
+	}
+
+	// 记录启用前的防火墙策略，解除时原样恢复（读取失败时解除后恢复 Windows 默认策略）
+	previous, err := system.FirewallPolicies()
+	if err != nil {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("读取防火墙策略失败，解除断线保护时将恢复默认策略: %v", err))
+	}
+	release := system.PlanKillSwitchRelease(a.state.ExeDir, previous)
+
+	plan, err := system.PlanKillSwitchEngage(a.state.ExeDir)
+	if err == nil {
+		plan, err = a.applySystemChange(plan, func() error {
+			a.journalRecord(syschange.KindKillSwitch, journalKeyKillSwitch, plan.Title, release, nil)
+			return plan.Run()
+		})
+	}
+	if err != nil {
+		// 部分规则可能已生效，回滚以免留下半截策略
+		release.RunAll()
+		a.journalResolve(journalKeyKillSwitch)
+		a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("启用断线保护失败: %v", err))
+		a.notify(notify.EventKillSwitch, fmt.Sprintf("%s 已断开，断线保护启用失败: %v", nodeName, err))
+		return
+	}
+	if plan.DryRun {
+		return // 预览模式：只记录将执行的命令
+	}
+
+	a.setKillSwitchActive(true)
+	a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("%s 异常退出，已启用断线保护，非代理流量将被阻止", nodeName))
+	a.notify(notify.EventKillSwitch, fmt.Sprintf("%s 已断开，断线保护已阻止直连流量", nodeName))
+}
+
+// releaseKillSwitchLocked 解除阻止（需持有 killSwitchMu）
+func (a *App) releaseKillSwitchLocked() error {
+	a.state.Mu.RLock()
+	active := a.state.Config.KillSwitchActive
+	a.state.Mu.RUnlock()
+	if !active {
+		return nil
+	}
+
+	// 优先使用启用时记录的撤销命令（恢复启用前的防火墙策略）
+	plan := a.journalUndo(journalKeyKillSwitch)
+	if plan == nil {
+		plan = system.PlanKillSwitchRelease(a.state.ExeDir, nil)
+	}
+	plan, err := a.applySystemChange(plan, plan.Run)
+	if err != nil {
+		return err
+	}
+	if plan.DryRun {
+		return nil // 预览模式：阻止保持生效
+	}
+	a.setKillSwitchActive(false)
+	a.journalResolve(journalKeyKillSwitch)
+	a.logManager.LogSystem(logger.LevelInfo, "断线保护已解除")
+	return nil
+}
+
+// resumeKillSwitch 启动时检查上次遗留的阻止（应用崩溃后重启），等待节点恢复后解除
+func (a *App) resumeKillSwitch() {
+	a.state.Mu.RLock()
+	enabled := a.state.Config.KillSwitchEnabled
+	active := a.state.Config.KillSwitchActive
+	a.state.Mu.RUnlock()
+
+	// 配置是异步保存的，崩溃前可能未写入；以系统修改日志为准
+	if !active && a.journal.Has(journalKeyKillSwitch) {
+		a.setKillSwitchActive(true)
+		active = true
+	}
+	if !active {
+		return
+	}
+
+	if !enabled {
+		a.releaseKillSwitch()
+		return
+	}
+	a.logManager.LogSystem(logger.LevelWarn, "断线保护仍在生效，节点恢复运行后自动解除")
+}
+
+// releaseKillSwitch 解除阻止（退出应用时调用；应用崩溃时不会执行，阻止保持生效）
+func (a *App) releaseKillSwitch() {
+	a.killSwitchMu.Lock()
+	defer a.killSwitchMu.Unlock()
+	a.releaseKillSwitchLocked()
+}
+
+// setKillSwitchActive 记录保护是否生效（持久化，应用意外退出后重启仍保持阻止）
+func (a *App) setKillSwitchActive(active bool) {
+	a.state.Mu.Lock()
+	a.state.Config.KillSwitchActive = active
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+}
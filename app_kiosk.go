@@ -0,0 +1,88 @@
+package main
+
+import (
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 只读模式（家庭 / 办公室共用电脑）
+// =============================================================================
+
+// KioskStatus 只读模式状态 (kiosk:changed)
+type KioskStatus struct {
+	Enabled     bool `json:"enabled"`
+	HasPassword bool `json:"has_password"` // 退出只读模式需要密码
+}
+
+// GetKioskStatus 获取只读模式状态
+func (a *App) GetKioskStatus() KioskStatus {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return kioskStatus(a.state.Config.Kiosk)
+}
+
+// EnableKioskMode 开启只读模式，password 非空时退出需要输入该密码
+func (a *App) EnableKioskMode(password string) error {
+	settings := models.KioskSettings{Enabled: true}
+	if password != "" {
+		hash, salt, err := config.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		settings.PasswordHash, settings.PasswordSalt = hash, salt
+	}
+
+	a.state.Mu.Lock()
+	if a.state.Config.Kiosk.Enabled {
+		a.state.Mu.Unlock()
+		return nil
+	}
+	a.state.Config.Kiosk = settings
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.logManager.LogSystem(logger.LevelInfo, "已开启只读模式")
+	a.emitEvent(models.EventKioskChanged, kioskStatus(settings))
+	return nil
+}
+
+// DisableKioskMode 退出只读模式（设置了密码时需校验）
+func (a *App) DisableKioskMode(password string) error {
+	a.state.Mu.RLock()
+	kiosk := a.state.Config.Kiosk
+	a.state.Mu.RUnlock()
+	if !kiosk.Enabled {
+		return nil
+	}
+	if kiosk.PasswordHash != "" && !config.VerifyPassword(password, kiosk.PasswordHash, kiosk.PasswordSalt) {
+		a.logManager.LogSystem(logger.LevelWarn, "退出只读模式失败：密码错误")
+		return i18n.Errorf("密码错误")
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.Kiosk = models.KioskSettings{}
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.logManager.LogSystem(logger.LevelInfo, "已退出只读模式")
+	a.emitEvent(models.EventKioskChanged, KioskStatus{})
+	return nil
+}
+
+// checkWritable 只读模式下拒绝修改配置的请求（启动 / 停止节点等操作不受影响）
+func (a *App) checkWritable() error {
+	a.state.Mu.RLock()
+	enabled := a.state.Config.Kiosk.Enabled
+	a.state.Mu.RUnlock()
+	if enabled {
+		return i18n.Errorf("只读模式下不能修改配置")
+	}
+	return nil
+}
+
+func kioskStatus(k models.KioskSettings) KioskStatus {
+	return KioskStatus{Enabled: k.Enabled, HasPassword: k.PasswordHash != ""}
+}
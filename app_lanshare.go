@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 局域网共享
+// =============================================================================
+
+// lanShareNode 开启局域网共享时返回带局域网入站的节点副本（本地入站不变；认证与客户端白名单在生成 Xray 配置时添加）
+func (a *App) lanShareNode(node *models.NodeConfig) *models.NodeConfig {
+	if !node.LANShare.Enabled {
+		return node
+	}
+	shared := *node
+	shared.LANInbounds = models.LANShareInbounds(node, lanAddresses())
+	return &shared
+}
+
+// lanAddresses 本机已启用网卡上的局域网地址（不含回环和链路本地地址）
+func lanAddresses() []netip.Addr {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var addrs []netip.Addr
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, ifAddr := range ifAddrs {
+			prefix, err := netip.ParsePrefix(ifAddr.String())
+			if err != nil {
+				continue
+			}
+			addr := prefix.Addr()
+			if addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+				continue
+			}
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// lanShareCredentials 局域网设备连接节点时使用的账号（未开启局域网共享时为空）
+func lanShareCredentials(node *models.NodeConfig) models.LANShareSettings {
+	if !node.LANShare.Enabled {
+		return models.LANShareSettings{}
+	}
+	return node.LANShare
+}
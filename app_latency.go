@@ -0,0 +1,54 @@
+package main
+
+import (
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+)
+
+// =============================================================================
+// 延迟历史
+// =============================================================================
+
+// GetLatencyHistory 获取节点自 since（Unix 秒，0 表示全部）起的测速记录，按时间先后排列，用于绘制延迟趋势
+// 记录保留 30 天，每个节点最多 2000 条
+func (a *App) GetLatencyHistory(nodeID string, since int64) []logger.LatencyPoint {
+	var from time.Time
+	if since > 0 {
+		from = time.Unix(since, 0)
+	}
+	return a.latencyHistory.Query(nodeID, from)
+}
+
+// ClearLatencyHistory 清空节点的延迟历史，nodeID 为空时清空全部
+func (a *App) ClearLatencyHistory(nodeID string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.latencyHistory.Clear(nodeID)
+	return nil
+}
+
+// ListPingReports 获取节点保存的测速报告（最新的在前，不含每个服务器的结果），用于对比不同时间的测速
+// 报告保留 30 天，每个节点最多 200 份
+func (a *App) ListPingReports(nodeID string) []logger.PingReport {
+	return a.pingReportStore.List(nodeID)
+}
+
+// GetPingReport 按 ID 读取完整的测速报告（含每个服务器的结果）
+func (a *App) GetPingReport(id string) (*logger.PingReport, error) {
+	report, err := a.pingReportStore.Get(id)
+	if err != nil {
+		return nil, i18n.Errorf("测速报告不存在: %s", id)
+	}
+	return report, nil
+}
+
+// ClearPingReports 删除节点保存的测速报告，nodeID 为空时删除全部
+func (a *App) ClearPingReports(nodeID string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	return a.pingReportStore.Clear(nodeID)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/socks5"
+)
+
+// =============================================================================
+// 定时泄露测试
+// =============================================================================
+
+// LeakHistoryFileName 泄露测试历史文件
+const LeakHistoryFileName = "leak_history.json"
+
+// SetLeakTestSchedule 设置定时泄露测试间隔（小时），0 表示关闭
+func (a *App) SetLeakTestSchedule(hours int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if hours < 0 {
+		return i18n.Errorf("测试间隔不能为负数")
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.LeakTestInterval = hours
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.applyLeakTestSchedule()
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// GetLeakTestHistory 获取泄露测试历史（最新的在前）
+func (a *App) GetLeakTestHistory() []dns.LeakHistoryEntry {
+	return a.leakHistory.List()
+}
+
+// ClearLeakTestHistory 清空泄露测试历史
+func (a *App) ClearLeakTestHistory() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.leakHistory.Clear()
+	return nil
+}
+
+// applyLeakTestSchedule 按当前配置启动或停止定时测试（间隔未变化时保持原有计时）
+func (a *App) applyLeakTestSchedule() {
+	a.state.Mu.RLock()
+	hours := a.state.Config.LeakTestInterval
+	a.state.Mu.RUnlock()
+	if a.serviceFront.Load() {
+		hours = 0 // 由服务定时测试
+	}
+
+	a.leakTestMu.Lock()
+	defer a.leakTestMu.Unlock()
+
+	if a.leakTestCancel != nil {
+		if hours == a.leakTestHours {
+			return
+		}
+		a.leakTestCancel()
+		a.leakTestCancel = nil
+	}
+	a.leakTestHours = hours
+	if hours <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.leakTestCancel = cancel
+
+	go a.leakTestLoop(ctx, time.Duration(hours)*time.Hour)
+}
+
+// stopLeakTestSchedule 停止定时测试
+func (a *App) stopLeakTestSchedule() {
+	a.leakTestMu.Lock()
+	defer a.leakTestMu.Unlock()
+	if a.leakTestCancel != nil {
+		a.leakTestCancel()
+		a.leakTestCancel = nil
+	}
+	a.leakTestHours = 0
+}
+
+func (a *App) leakTestLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// 没有节点运行时测试没有意义
+		if len(a.runningNodeIDs()) == 0 {
+			continue
+		}
+		if _, err := a.runLeakTest(true); err != nil {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("定时泄露测试失败: %v", err))
+		}
+	}
+}
+
+// runLeakTest 执行泄露测试并记录历史；结果由未泄露变为泄露时发出告警
+func (a *App) runLeakTest(scheduled bool) (*dns.LeakTestResult, error) {
+	result, err := a.leakTester.RunTest()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := a.leakHistory.Add(dns.LeakHistoryEntry{
+		LeakTestResult: *result,
+		NodeIDs:        a.runningNodeIDs(),
+		Scheduled:      scheduled,
+	})
+	a.emitEvent(models.EventLeakTestComplete, result)
+
+	if result.Leaked && (previous == nil || !previous.Leaked) {
+		a.logManager.LogSystem(logger.LevelWarn, "检测到DNS泄露: "+result.Conclusion)
+		a.notify(notify.EventLeak, "检测到DNS泄露，请检查节点的DNS模式")
+		a.emitEvent(models.EventLeakDetected, result)
+	}
+	return result, nil
+}
+
+// TestDNSLeakViaNode 经指定节点的本地入站执行完整的泄露测试，并与不经代理的基线对比（节点未运行时临时启动）
+func (a *App) TestDNSLeakViaNode(nodeID string) (*dns.NodeLeakReport, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+
+	release, err := a.ensureNodeRunning(a.ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	report, err := a.leakTester.CompareViaProxy(nodeSocksDialer(node))
+	if err != nil {
+		return nil, err
+	}
+	report.NodeID, report.NodeName = node.ID, node.Name
+
+	level := logger.LevelInfo
+	if report.Leaked {
+		level = logger.LevelWarn
+	}
+	a.logManager.LogNode(node.ID, node.Name, level, logger.CategorySystem, report.Conclusion)
+	return report, nil
+}
+
+// TestIPv6Leak 经活动节点和在代理之外分别访问仅 IPv6 的站点，检测 IPv6 流量是否绕过代理
+func (a *App) TestIPv6Leak() (*dns.IPv6LeakResult, error) {
+	node := a.state.GetNode(a.activeNodeID())
+	if node == nil {
+		return nil, i18n.Errorf("没有运行中的节点")
+	}
+	client := nodeSocksDialer(node).HTTPClient(10 * time.Second)
+
+	result := a.leakTester.RunIPv6Test(client)
+	if result.Leaked {
+		a.logManager.LogSystem(logger.LevelWarn, "检测到IPv6泄露: "+result.Conclusion)
+	}
+	return result, nil
+}
+
+// nodeSocksDialer 经节点本地 SOCKS5 入站发起检测请求的拨号器（本地入站无需认证）
+func nodeSocksDialer(node *models.NodeConfig) *socks5.Dialer {
+	return &socks5.Dialer{Addr: loopbackListen(node.Listen)}
+}
+
+// runningNodeIDs 正在运行的节点ID
+func (a *App) runningNodeIDs() []string {
+	var ids []string
+	for id, st := range a.engineManager.GetAllStatuses() {
+		if st.Status == models.StatusRunning {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点与规则数量上限
+// =============================================================================
+
+// GetLimitSettings 获取节点与规则数量上限（0 表示默认值）
+func (a *App) GetLimitSettings() models.LimitSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.Limits
+}
+
+// SetLimitSettings 设置节点与规则数量上限；不能低于当前已有的节点数和规则数
+func (a *App) SetLimitSettings(limits models.LimitSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := models.ValidateLimits(limits); err != nil {
+		return err
+	}
+
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	check := models.AppConfig{Limits: limits}
+	if n := len(a.state.Config.Nodes); n > check.NodeLimit() {
+		return i18n.Errorf("当前已有 %d 个节点，上限不能低于该数量", n)
+	}
+	for i := range a.state.Config.Nodes {
+		node := &a.state.Config.Nodes[i]
+		if n := len(models.ResolveNodeRules(node, a.state.Config.RuleGroups)); n > check.RuleLimit() {
+			return i18n.Errorf("节点 %s 已有 %d 条规则，上限不能低于该数量", node.Name, n)
+		}
+	}
+	for _, g := range a.state.Config.RuleGroups {
+		if n := len(g.Rules); n > check.RuleLimit() {
+			return i18n.Errorf("规则组 %s 已有 %d 条规则，上限不能低于该数量", g.Name, n)
+		}
+	}
+
+	a.state.Config.Limits = limits
+	go a.saveConfig()
+	return nil
+}
+
+// ruleLimit 当前的每个节点规则数量上限
+func (a *App) ruleLimit() int {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.RuleLimit()
+}
+
+// logImportLimit 导入时有节点因达到上限被跳过，记录并提醒
+func (a *App) logImportLimit(skipped, limit int) {
+	if skipped == 0 {
+		return
+	}
+	a.logManager.LogSystem(logger.LevelWarn,
+		fmt.Sprintf("节点数量已达上限 %d，%d 个节点未导入（可在设置中提高上限）", limit, skipped))
+}
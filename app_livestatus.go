@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 活动节点实时状态（迷你窗口 / 悬浮窗）
+// =============================================================================
+
+const (
+	// liveStatusInterval 推送 live:status 事件的间隔
+	liveStatusInterval = 5 * time.Second
+	// liveProbeInterval 经活动节点测量延迟的间隔
+	liveProbeInterval = time.Minute
+	// liveExitInterval 刷新出口 IP 与国家的间隔（活动节点变化时立即刷新）
+	liveExitInterval = 10 * time.Minute
+	// liveExitURL 查询出口 IP 与国家的地址
+	liveExitURL = "https://ipleak.net/json/"
+)
+
+// LiveStatus 活动节点的简要状态 (live:status)，没有运行中的节点时 NodeID 为空
+type LiveStatus struct {
+	NodeID      string `json:"node_id"`
+	NodeName    string `json:"node_name"`
+	UpSpeed     int64  `json:"up_speed"`     // 字节/秒
+	DownSpeed   int64  `json:"down_speed"`   // 字节/秒
+	Latency     int    `json:"latency"`      // 最近一次探测的延迟（毫秒），-1 表示失败，0 表示尚未探测
+	ProbedAt    int64  `json:"probed_at"`    // 最近一次探测的时间（Unix 秒）
+	ExitIP      string `json:"exit_ip"`      // 出口 IP，未知时为空
+	ExitCountry string `json:"exit_country"` // 出口国家 / 地区名称
+	CountryCode string `json:"country_code"` // ISO 3166 两位代码
+	ExitASN     uint32 `json:"exit_asn"`     // 出口所属自治系统（离线 ASN 数据，未知时为 0）
+	ExitASOrg   string `json:"exit_as_org"`  // 出口所属自治系统的组织名称
+	Uptime      int64  `json:"uptime"`       // 节点已运行的秒数
+}
+
+// liveState 活动节点的探测结果，活动节点变化时清空
+type liveState struct {
+	nodeID      string
+	latency     int
+	probedAt    time.Time
+	exitIP      string
+	exitCountry string
+	countryCode string
+	exitASN     uint32
+	exitASOrg   string
+	exitAt      time.Time
+	probing     bool
+	lastEmpty   bool // 上次推送时没有活动节点
+}
+
+// GetLiveStatus 获取活动节点的实时状态
+func (a *App) GetLiveStatus() LiveStatus {
+	status := LiveStatus{}
+	id := a.activeNodeID()
+	node := a.state.GetNode(id)
+	if node == nil {
+		return status
+	}
+
+	status.NodeID = node.ID
+	status.NodeName = node.Name
+	stats := a.statsManager.Get(id)
+	status.UpSpeed, status.DownSpeed = stats.UpSpeed, stats.DownSpeed
+	if es, ok := a.engineManager.GetAllStatuses()[id]; ok && !es.StartTime.IsZero() {
+		status.Uptime = int64(time.Since(es.StartTime).Seconds())
+	}
+
+	a.liveMu.Lock()
+	if a.live.nodeID == id {
+		status.Latency = a.live.latency
+		if !a.live.probedAt.IsZero() {
+			status.ProbedAt = a.live.probedAt.Unix()
+		}
+		status.ExitIP, status.ExitCountry, status.CountryCode = a.live.exitIP, a.live.exitCountry, a.live.countryCode
+		status.ExitASN, status.ExitASOrg = a.live.exitASN, a.live.exitASOrg
+	}
+	a.liveMu.Unlock()
+	return status
+}
+
+// startLiveStatusLoop 定时推送活动节点状态，并在需要时经节点测量延迟、查询出口
+func (a *App) startLiveStatusLoop(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(liveStatusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			a.refreshLiveStatus(ctx)
+		}
+	}()
+}
+
+// refreshLiveStatus 推送一次 live:status（连续没有活动节点时只推送一次）
+func (a *App) refreshLiveStatus(ctx context.Context) {
+	status := a.GetLiveStatus()
+
+	a.liveMu.Lock()
+	if a.live.nodeID != status.NodeID {
+		a.live = liveState{nodeID: status.NodeID}
+	}
+	empty := status.NodeID == ""
+	skip := empty && a.live.lastEmpty
+	a.live.lastEmpty = empty
+
+	now := time.Now()
+	probe := !empty && !a.live.probing &&
+		(now.Sub(a.live.probedAt) >= liveProbeInterval || now.Sub(a.live.exitAt) >= liveExitInterval)
+	if probe {
+		a.live.probing = true
+	}
+	a.liveMu.Unlock()
+
+	if probe {
+		go a.probeLiveNode(ctx, status.NodeID)
+	}
+	if !skip {
+		a.emitEvent(models.EventLiveStatus, status)
+	}
+}
+
+// probeLiveNode 经活动节点请求检测地址测量延迟，到期时查询出口 IP 与国家
+func (a *App) probeLiveNode(ctx context.Context, nodeID string) {
+	defer func() {
+		a.liveMu.Lock()
+		if a.live.nodeID == nodeID {
+			a.live.probing = false
+		}
+		a.liveMu.Unlock()
+	}()
+
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return
+	}
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(&url.URL{Scheme: "socks5", Host: loopbackListen(node.Listen)})},
+		Timeout:   resumeProbeTimeout,
+	}
+	defer client.CloseIdleConnections()
+
+	a.liveMu.Lock()
+	needProbe := time.Since(a.live.probedAt) >= liveProbeInterval
+	needExit := time.Since(a.live.exitAt) >= liveExitInterval
+	a.liveMu.Unlock()
+
+	latency := 0
+	if needProbe {
+		latency = measureLatency(ctx, client)
+	}
+	var exit exitInfo
+	var exitErr error
+	if needExit && latency >= 0 {
+		exit, exitErr = queryExitInfo(ctx, client)
+	}
+	var attr dns.IPAttribution
+	if exitErr == nil && exit.IP != "" {
+		// ASN 与国家代码以离线数据为准（查询服务给出的名称仍用于显示）
+		attrs, _ := a.ipGeo.Lookup([]string{exit.IP})
+		attr = attrs[exit.IP]
+		if attr.CountryCode != "" {
+			exit.CountryCode = attr.CountryCode
+		}
+	}
+
+	a.liveMu.Lock()
+	defer a.liveMu.Unlock()
+	if a.live.nodeID != nodeID {
+		return
+	}
+	now := time.Now()
+	if needProbe {
+		a.live.latency, a.live.probedAt = latency, now
+	}
+	if needExit && latency >= 0 {
+		// 查询失败时也记录时间，避免每次推送都重试
+		a.live.exitAt = now
+		if exitErr == nil {
+			a.live.exitIP, a.live.exitCountry, a.live.countryCode = exit.IP, exit.Country, exit.CountryCode
+			a.live.exitASN, a.live.exitASOrg = attr.ASN, attr.ASOrg
+		}
+	}
+}
+
+// measureLatency 请求检测地址，返回耗时（毫秒），失败时返回 -1
+func measureLatency(ctx context.Context, client *http.Client) int {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resumeProbeURL, nil)
+	if err != nil {
+		return -1
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return -1
+	}
+	resp.Body.Close()
+	if ms := int(time.Since(start).Milliseconds()); ms > 0 {
+		return ms
+	}
+	return 1
+}
+
+// exitInfo 出口查询结果
+type exitInfo struct {
+	IP          string `json:"ip"`
+	Country     string `json:"country_name"`
+	CountryCode string `json:"country_code"`
+}
+
+// queryExitInfo 查询经节点访问时的出口 IP 与国家
+func queryExitInfo(ctx context.Context, client *http.Client) (exitInfo, error) {
+	var info exitInfo
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, liveExitURL, nil)
+	if err != nil {
+		return info, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	err = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&info)
+	return info, err
+}
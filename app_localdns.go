@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 本机 DNS 服务
+// =============================================================================
+
+// LocalDNSStatus 本机 DNS 服务状态
+type LocalDNSStatus struct {
+	Enabled  bool                   `json:"enabled"`
+	Running  bool                   `json:"running"`
+	Addrs    []string               `json:"addrs"`    // 实际监听的地址
+	Upstream []string               `json:"upstream"` // 配置的上游，为空时使用内置地址
+	Stats    dns.LocalResolverStats `json:"stats"`
+}
+
+// applyLocalDNSSettings 按当前配置启动或停止本机 DNS 服务（作为后台服务的控制前端时由服务提供）
+func (a *App) applyLocalDNSSettings() error {
+	a.state.Mu.RLock()
+	settings := a.state.Config.LocalDNS
+	a.state.Mu.RUnlock()
+
+	if !settings.Enabled || a.serviceFront.Load() {
+		if a.localResolver.IsRunning() {
+			a.localResolver.Stop()
+			a.logManager.LogSystem(logger.LevelInfo, "本机 DNS 服务已关闭")
+		}
+		return nil
+	}
+
+	if err := a.localResolver.Start(settings.Upstream); err != nil {
+		a.logManager.LogSystem(logger.LevelError, err.Error())
+		return err
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("本机 DNS 服务已启动: %s", strings.Join(a.localResolver.Addrs(), ", ")))
+	return nil
+}
+
+// GetLocalDNSStatus 获取本机 DNS 服务状态
+func (a *App) GetLocalDNSStatus() LocalDNSStatus {
+	a.state.Mu.RLock()
+	settings := a.state.Config.LocalDNS
+	a.state.Mu.RUnlock()
+
+	return LocalDNSStatus{
+		Enabled:  settings.Enabled,
+		Running:  a.localResolver.IsRunning(),
+		Addrs:    a.localResolver.Addrs(),
+		Upstream: settings.Upstream,
+		Stats:    a.localResolver.Stats(),
+	}
+}
+
+// SetLocalDNSSettings 保存并应用本机 DNS 服务设置
+// 服务只负责应答，需要将系统或网卡的 DNS 设为 127.0.0.1 / ::1 后其他程序才会使用
+func (a *App) SetLocalDNSSettings(settings models.LocalDNSSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	upstream, err := normalizeUpstreamList(settings.Upstream)
+	if err != nil {
+		return err
+	}
+	settings.Upstream = upstream
+
+	a.state.Mu.Lock()
+	a.state.Config.LocalDNS = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	if err := a.applyLocalDNSSettings(); err != nil {
+		return i18n.Errorf("本机 DNS 服务启动失败，请检查 53 端口是否被占用: %w", err)
+	}
+	return nil
+}
+
+// GetDNSCacheStats 获取本机 DNS 服务的缓存统计（命中率、条目数）
+func (a *App) GetDNSCacheStats() dns.DNSCacheStats {
+	return a.dnsManager.GetDNSCacheStats()
+}
+
+// DumpDNSCache 列出本机 DNS 服务缓存的域名
+func (a *App) DumpDNSCache() []dns.DNSCacheEntry {
+	return a.dnsManager.DumpDNSCache()
+}
+
+// ClearDNSCache 删除指定域名（含子域名）的缓存，domains 为空时清空全部，返回删除的条目数
+func (a *App) ClearDNSCache(domains []string) int {
+	n := a.dnsManager.FlushDNSCache(domains...)
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已清除 %d 条 DNS 缓存", n))
+	return n
+}
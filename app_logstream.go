@@ -0,0 +1,64 @@
+package main
+
+import (
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 日志订阅
+// =============================================================================
+
+// SubscribeLogs 按过滤条件订阅日志，匹配的新条目通过 log:batch 事件批量推送，返回订阅ID
+func (a *App) SubscribeLogs(filter models.LogFilter) string {
+	return a.subscribeLogs(filter, func(batch logger.LogBatch) {
+		a.emitEvent(models.EventLogBatch, batch)
+	})
+}
+
+// UpdateLogSubscription 修改订阅的过滤条件
+func (a *App) UpdateLogSubscription(id string, filter models.LogFilter) error {
+	if !a.logManager.UpdateSubscription(id, filter) {
+		return i18n.Errorf("日志订阅不存在: %s", id)
+	}
+	return nil
+}
+
+// UnsubscribeLogs 取消日志订阅
+func (a *App) UnsubscribeLogs(id string) {
+	a.logManager.Unsubscribe(id)
+}
+
+// subscribeLogs 订阅日志，推送前按界面语言填充类别名称（前端与控制接口共用）
+func (a *App) subscribeLogs(filter models.LogFilter, deliver func(batch logger.LogBatch)) string {
+	return a.logManager.Subscribe(filter, func(batch logger.LogBatch) {
+		logger.Localize(batch.Entries, a.language())
+		deliver(batch)
+	})
+}
+
+// =============================================================================
+// 调试日志
+// =============================================================================
+
+// GetDebugLog 是否开启调试日志
+func (a *App) GetDebugLog() bool {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.DebugLog
+}
+
+// SetDebugLog 开启后不再折叠重复日志，缓冲区和日志文件保留内核的原始输出
+func (a *App) SetDebugLog(enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	a.state.Config.DebugLog = enabled
+	a.state.Mu.Unlock()
+	a.logManager.SetDebug(enabled)
+	go a.saveConfig()
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/metrics"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// Prometheus 指标接口
+// =============================================================================
+
+// MetricsStatus 指标接口状态
+type MetricsStatus struct {
+	Enabled bool   `json:"enabled"`
+	Running bool   `json:"running"`
+	Listen  string `json:"listen"`
+	URL     string `json:"url"` // 供 Prometheus 抓取的地址（运行中时）
+}
+
+// applyMetricsSettings 按当前配置启动或停止指标接口（作为后台服务的控制前端时由服务发布）
+func (a *App) applyMetricsSettings() error {
+	if a.serviceFront.Load() {
+		return nil
+	}
+	a.state.Mu.RLock()
+	settings := a.state.Config.Metrics
+	a.state.Mu.RUnlock()
+
+	if !settings.Enabled {
+		if a.metricsServer.IsRunning() {
+			a.metricsServer.Stop()
+			a.logManager.LogSystem(logger.LevelInfo, "指标接口已关闭")
+		}
+		return nil
+	}
+
+	if err := a.metricsServer.Start(settings.Listen); err != nil {
+		a.logManager.LogSystem(logger.LevelError, err.Error())
+		return err
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("指标接口已启动: http://%s%s", a.metricsServer.Addr(), metrics.Path))
+
+	if host, _, err := net.SplitHostPort(a.metricsServer.Addr()); err == nil && !isLoopbackHost(host) {
+		a.logManager.LogSystem(logger.LevelWarn, "指标接口监听在非回环地址，局域网内的设备均可读取节点名称与流量")
+	}
+	return nil
+}
+
+// GetMetricsStatus 获取指标接口状态
+func (a *App) GetMetricsStatus() MetricsStatus {
+	a.state.Mu.RLock()
+	settings := a.state.Config.Metrics
+	a.state.Mu.RUnlock()
+
+	status := MetricsStatus{
+		Enabled: settings.Enabled,
+		Running: a.metricsServer.IsRunning(),
+		Listen:  settings.Listen,
+	}
+	if status.Listen == "" {
+		status.Listen = metrics.DefaultListen
+	}
+	if addr := a.metricsServer.Addr(); addr != "" {
+		status.URL = "http://" + addr + metrics.Path
+	}
+	return status
+}
+
+// SetMetricsSettings 保存并应用指标接口设置
+func (a *App) SetMetricsSettings(settings models.MetricsSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	settings.Listen = strings.TrimSpace(settings.Listen)
+	if settings.Listen != "" {
+		if _, _, err := net.SplitHostPort(settings.Listen); err != nil {
+			return i18n.Errorf("监听地址格式错误，应为 host:port")
+		}
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.Metrics = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	if err := a.applyMetricsSettings(); err != nil {
+		return i18n.Errorf("指标接口启动失败，请检查监听地址是否被占用: %w", err)
+	}
+	return nil
+}
+
+// rememberPingResult 保存节点最近一次延迟测试的结果，供指标接口发布
+func (a *App) rememberPingResult(report logger.PingReport) {
+	report.Results = nil
+	a.pingReportMu.Lock()
+	defer a.pingReportMu.Unlock()
+	if a.pingReports == nil {
+		a.pingReports = make(map[string]logger.PingReport)
+	}
+	a.pingReports[report.NodeID] = report
+}
+
+// metricsSnapshot 汇总抓取时的节点状态、流量、延迟、自动重启次数与 Fake-IP 地址池使用情况
+func (a *App) metricsSnapshot() metrics.Snapshot {
+	statuses := a.engineManager.GetAllStatuses()
+	restarts := a.engineManager.RestartCounts()
+	traffic := make(map[string]models.TrafficStats)
+	for _, t := range a.statsManager.GetAll() {
+		traffic[t.NodeID] = t
+	}
+	a.pingReportMu.Lock()
+	pings := make(map[string]logger.PingReport, len(a.pingReports))
+	for id, r := range a.pingReports {
+		pings[id] = r
+	}
+	a.pingReportMu.Unlock()
+
+	snap := metrics.Snapshot{Version: models.AppVersion}
+	a.state.Mu.RLock()
+	for _, n := range a.state.Config.Nodes {
+		sample := metrics.NodeSample{ID: n.ID, Name: n.Name, Status: models.StatusStopped, Restarts: restarts[n.ID]}
+		if st, ok := statuses[n.ID]; ok {
+			sample.Status = st.Status
+			sample.Running = st.Status == models.StatusRunning
+		}
+		if t, ok := traffic[n.ID]; ok {
+			sample.UploadBytes, sample.DownloadBytes = t.Upload, t.Download
+			sample.UpSpeed, sample.DownSpeed = t.UpSpeed, t.DownSpeed
+			sample.Connections = t.Connections
+		}
+		if r, ok := pings[n.ID]; ok {
+			sample.PingAt = r.EndTime
+			sample.PingAvgMs, sample.PingMinMs, sample.PingMaxMs = r.AvgLatency, r.MinLatency, r.MaxLatency
+			sample.PingSuccess, sample.PingFailed = r.SuccessCount, r.FailCount
+		}
+		snap.Nodes = append(snap.Nodes, sample)
+	}
+	a.state.Mu.RUnlock()
+
+	ipv4, ipv6 := a.dnsManager.FakeIPCounts()
+	snap.FakeIP = []metrics.FakeIPPool{
+		{Family: "ipv4", Used: ipv4, Size: dns.FakeIPPoolSize},
+		{Family: "ipv6", Used: ipv6, Size: dns.FakeIPv6PoolSize},
+	}
+	return snap
+}
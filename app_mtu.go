@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// TUN MTU 自动调整
+// =============================================================================
+
+// mtuProbeDeadline 整个探测过程的超时
+const mtuProbeDeadline = time.Minute
+
+// AutoTuneMTU 探测到节点服务器的路径 MTU，写入节点的 TUN MTU（重新启动节点后生效）
+func (a *App) AutoTuneMTU(nodeID string) (*dns.MTUProbeResult, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+
+	server := a.mtuProbeServer(node)
+	if server == "" {
+		return nil, i18n.Errorf("节点未配置服务器")
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, mtuProbeDeadline)
+	defer cancel()
+	result, err := a.tunManager.ProbeMTU(ctx, server)
+	if err != nil {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelWarn, logger.CategoryPing,
+			fmt.Sprintf("MTU 探测失败: %v", err))
+		return nil, err
+	}
+
+	a.state.Mu.Lock()
+	var updated *models.NodeConfig
+	for i := range a.state.Config.Nodes {
+		if a.state.Config.Nodes[i].ID == nodeID {
+			a.state.Config.Nodes[i].TUNMTU = result.MTU
+			updated = &a.state.Config.Nodes[i]
+			break
+		}
+	}
+	var snapshot models.NodeConfig
+	if updated != nil {
+		snapshot = *updated
+	}
+	a.state.Mu.Unlock()
+	if updated == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+
+	go a.saveConfig()
+	a.emitNodeEvent(models.EventNodeUpdated, snapshot, []string{"tun_mtu"})
+	a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategoryPing,
+		fmt.Sprintf("到 %s 的路径 MTU 为 %d，TUN MTU 设为 %d", result.IP, result.PathMTU, result.MTU))
+	return result, nil
+}
+
+// mtuProbeServer 探测目标：节点指定的连接 IP，其次是最近可用的服务器，最后是地址池中的第一个
+func (a *App) mtuProbeServer(node *models.NodeConfig) string {
+	if node.IP != "" {
+		return node.IP
+	}
+	if preferred := a.serverMemory.Preferred(node.ID); len(preferred) > 0 {
+		return preferred[0]
+	}
+	if servers := models.SplitServers(node.Server); len(servers) > 0 {
+		return servers[0]
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点分组与标签
+// =============================================================================
+
+// GetNodeGroups 列出全部分组和标签，以及其中的节点数与运行中的节点数
+func (a *App) GetNodeGroups() models.NodeGroupList {
+	return models.ListNodeGroups(a.GetNodes(models.NodeQuery{}).Nodes)
+}
+
+// SetNodesGroup 批量设置节点分组（group 为空时移出分组）
+func (a *App) SetNodesGroup(ids []string, group string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	group = strings.TrimSpace(group)
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	a.state.Mu.Lock()
+	var changed []models.NodeConfig
+	for i := range a.state.Config.Nodes {
+		node := &a.state.Config.Nodes[i]
+		if want[node.ID] && node.Group != group {
+			node.Group = group
+			changed = append(changed, *node)
+		}
+	}
+	a.state.Mu.Unlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+	go a.saveConfig()
+	for _, node := range changed {
+		a.emitNodeEvent(models.EventNodeUpdated, node, []string{"group"})
+	}
+	return nil
+}
+
+// StartNodesByQuery 启动符合条件的节点（如某个分组或标签），已运行的节点不重启，返回启动的节点数
+// 前置节点先启动；不在条件内的前置节点随下游节点一起启动
+func (a *App) StartNodesByQuery(query models.NodeQuery) (int, error) {
+	matched, err := a.queryBatchNodes(query)
+	if err != nil {
+		return 0, err
+	}
+
+	order, invalid := engine.StartOrder(a.GetNodes(models.NodeQuery{}).Nodes)
+	started := 0
+	var lastErr error
+	for _, id := range order {
+		node, ok := matched[id]
+		if !ok || a.GetNodeStatus(id) == models.StatusRunning {
+			continue
+		}
+		if err := a.StartNode(id); err != nil {
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("启动节点 %s 失败: %v", node.Name, err))
+			lastErr = err
+			continue
+		}
+		started++
+	}
+	for _, id := range sortedKeys(invalid) {
+		if node, ok := matched[id]; ok {
+			err := invalid[id]
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("启动节点 %s 失败: %v", node.Name, err))
+			lastErr = err
+		}
+	}
+	return started, lastErr
+}
+
+// StopNodesByQuery 停止符合条件的节点，返回停止的节点数（经这些节点转发的下游节点同时停止）
+// 按启动顺序的逆序停止：下游节点先于前置节点，链路无效的节点按 ID 排在最后
+func (a *App) StopNodesByQuery(query models.NodeQuery) (int, error) {
+	matched, err := a.queryBatchNodes(query)
+	if err != nil {
+		return 0, err
+	}
+
+	order, invalid := engine.StartOrder(a.GetNodes(models.NodeQuery{}).Nodes)
+	ids := make([]string, 0, len(order)+len(invalid))
+	for i := len(order) - 1; i >= 0; i-- {
+		ids = append(ids, order[i])
+	}
+	ids = append(ids, sortedKeys(invalid)...)
+
+	stopped := 0
+	var lastErr error
+	for _, id := range ids {
+		node, ok := matched[id]
+		if !ok || a.GetNodeStatus(id) == models.StatusStopped {
+			continue
+		}
+		if err := a.StopNode(id); err != nil {
+			a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("停止节点 %s 失败: %v", node.Name, err))
+			lastErr = err
+			continue
+		}
+		stopped++
+	}
+	return stopped, lastErr
+}
+
+// sortedKeys 按字典序排列的 map 键
+func sortedKeys(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// queryBatchNodes 批量操作的节点（忽略分页），至少需要一个筛选条件，避免误操作全部节点
+func (a *App) queryBatchNodes(query models.NodeQuery) (map[string]models.NodeConfig, error) {
+	if query.Group == "" && query.Tag == "" && query.RuleGroupID == "" && query.Search == "" && query.Status == "" {
+		return nil, i18n.Errorf("请指定分组、标签或其他筛选条件")
+	}
+	query.Offset, query.Limit = 0, 0
+	nodes := a.GetNodes(query).Nodes
+	if len(nodes) == 0 {
+		return nil, i18n.Errorf("没有符合条件的节点")
+	}
+	matched := make(map[string]models.NodeConfig, len(nodes))
+	for _, n := range nodes {
+		matched[n.ID] = n
+	}
+	return matched, nil
+}
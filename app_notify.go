@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// 通知渠道
+// =============================================================================
+
+// notifyClientTimeout Webhook / Telegram 请求超时
+const notifyClientTimeout = notify.SendTimeout
+
+// NotificationChannelInfo 渠道及其可用状态（供设置界面展示）
+type NotificationChannelInfo struct {
+	Name       string `json:"name"`
+	Configured bool   `json:"configured"`
+}
+
+// NotificationOptions 可配置的事件类型与渠道
+type NotificationOptions struct {
+	Events         []notify.Event            `json:"events"`
+	Channels       []NotificationChannelInfo `json:"channels"`
+	WebhookPresets []notify.WebhookPreset    `json:"webhook_presets"`
+}
+
+// initNotifications 注册内置渠道（Webhook / Telegram 按配置注册）
+func (a *App) initNotifications() {
+	a.notifier = notify.NewRouter()
+	a.notifyCenter = notify.NewCenter(func(n notify.Notification) {
+		a.emitEvent(models.EventNotification, n)
+	})
+
+	a.notifier.Register(notify.NewFuncSink(notify.ChannelToast, func(n notify.Notification) error {
+		return a.notification.Show(n.Title, n.Message)
+	}))
+	a.notifier.Register(notify.NewFuncSink(notify.ChannelTray, func(n notify.Notification) error {
+		return a.tray.ShowBalloon(n.Title, n.Message)
+	}))
+	a.notifier.Register(a.notifyCenter)
+
+	a.notifier.SetErrorHandler(func(channel string, n notify.Notification, err error) {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("通知渠道 %s 发送失败: %v", channel, err))
+	})
+}
+
+// applyNotificationSettings 按配置更新路由和网络渠道
+func (a *App) applyNotificationSettings() {
+	a.state.Mu.RLock()
+	settings := a.state.Config.Notifications
+	a.state.Mu.RUnlock()
+
+	routes := make(map[notify.Event][]string, len(settings.Routes))
+	for event, channels := range settings.Routes {
+		routes[notify.Event(event)] = channels
+	}
+	a.notifier.SetRoutes(routes, settings.DefaultChannels)
+
+	client := func() *http.Client { return a.egressClient(notifyClientTimeout) }
+
+	if hooks := buildWebhooks(settings, client); len(hooks) > 0 {
+		a.notifier.Register(&notify.WebhookGroup{Hooks: hooks})
+	} else {
+		a.notifier.Unregister(notify.ChannelWebhook)
+	}
+
+	if settings.TelegramToken != "" && settings.TelegramChatID != "" {
+		a.notifier.Register(&notify.TelegramSink{Token: settings.TelegramToken, ChatID: settings.TelegramChatID, Client: client})
+	} else {
+		a.notifier.Unregister(notify.ChannelTelegram)
+	}
+}
+
+// buildWebhooks 由设置生成 Webhook 列表（设置已校验，模板解析失败的跳过）
+func buildWebhooks(settings models.NotificationSettings, client func() *http.Client) []*notify.WebhookSink {
+	var hooks []*notify.WebhookSink
+	if settings.WebhookURL != "" {
+		hooks = append(hooks, &notify.WebhookSink{URL: settings.WebhookURL, Client: client})
+	}
+	for _, w := range settings.Webhooks {
+		hook, err := newWebhookSink(w, client)
+		if err != nil {
+			continue
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks
+}
+
+// newWebhookSink 校验并创建单个 Webhook
+func newWebhookSink(w models.WebhookSettings, client func() *http.Client) (*notify.WebhookSink, error) {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, i18n.Errorf("Webhook 地址无效: %s", w.Name)
+	}
+	hook := &notify.WebhookSink{URL: w.URL, ContentType: w.ContentType, Client: client}
+	if strings.TrimSpace(w.Template) != "" {
+		if hook.Template, err = notify.ParseWebhookTemplate(w.Template); err != nil {
+			return nil, i18n.Errorf("Webhook %s 的模板有误: %w", w.Name, err)
+		}
+	}
+	for _, e := range w.Events {
+		if !notify.ValidEvent(notify.Event(e)) {
+			return nil, i18n.Errorf("未知的通知事件: %s", e)
+		}
+		hook.Events = append(hook.Events, notify.Event(e))
+	}
+	return hook, nil
+}
+
+// notify 发送应用通知（按事件类型路由到配置的渠道）
+func (a *App) notify(event notify.Event, message string) {
+	a.notifier.Notify(event, models.AppTitle, message)
+}
+
+// notifyNode 发送与节点相关的通知，Webhook 模板可使用 {{.Node}}、{{.Status}}
+func (a *App) notifyNode(event notify.Event, nodeName, status, message string) {
+	a.notifier.Dispatch(notify.Notification{
+		Event:   event,
+		Title:   models.AppTitle,
+		Message: message,
+		Node:    nodeName,
+		Status:  status,
+	})
+}
+
+// onNotifyNodeStatus 节点启动、停止、出错时发送通知（同一状态只通知一次，启动中、等待重启等中间状态不通知）
+func (a *App) onNotifyNodeStatus(nodeID, status string, err error) {
+	var format string
+	switch status {
+	case models.StatusRunning:
+		format = "节点已启动: %s"
+	case models.StatusStopped:
+		format = "节点已停止: %s"
+	case models.StatusError:
+		format = "节点出错: %s"
+	default:
+		return
+	}
+
+	a.nodeNotifyMu.Lock()
+	if a.nodeNotified == nil {
+		a.nodeNotified = make(map[string]string)
+	}
+	last := a.nodeNotified[nodeID]
+	a.nodeNotified[nodeID] = status
+	a.nodeNotifyMu.Unlock()
+	// 从未启动过的节点不发送停止通知
+	if last == status || (last == "" && status == models.StatusStopped) {
+		return
+	}
+
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return
+	}
+	message := fmt.Sprintf(format, node.Name)
+	if err != nil {
+		message += ": " + err.Error()
+	}
+	a.notifyNode(notify.EventNodeStatus, node.Name, status, message)
+}
+
+// GetNotificationSettings 获取通知设置
+func (a *App) GetNotificationSettings() models.NotificationSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.Notifications
+}
+
+// SetNotificationSettings 保存通知设置
+func (a *App) SetNotificationSettings(settings models.NotificationSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	for event, channels := range settings.Routes {
+		if !notify.ValidEvent(notify.Event(event)) {
+			return i18n.Errorf("未知的通知事件: %s", event)
+		}
+		if err := validateChannels(channels); err != nil {
+			return err
+		}
+	}
+	if err := validateChannels(settings.DefaultChannels); err != nil {
+		return err
+	}
+	if settings.WebhookURL != "" {
+		u, err := url.Parse(settings.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("Webhook 地址无效: %s", settings.WebhookURL)
+		}
+	}
+	for i := range settings.Webhooks {
+		w := &settings.Webhooks[i]
+		w.Name = strings.TrimSpace(w.Name)
+		w.URL = strings.TrimSpace(w.URL)
+		if w.Name == "" {
+			w.Name = fmt.Sprintf("Webhook %d", i+1)
+		}
+		if _, err := newWebhookSink(*w, nil); err != nil {
+			return err
+		}
+	}
+	if (settings.TelegramToken == "") != (settings.TelegramChatID == "") {
+		return i18n.Errorf("Telegram 需要同时填写 Bot Token 和会话ID")
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.Notifications = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.applyNotificationSettings()
+	return nil
+}
+
+// GetNotificationOptions 获取可配置的事件类型与渠道
+func (a *App) GetNotificationOptions() NotificationOptions {
+	settings := a.GetNotificationSettings()
+	configured := map[string]bool{
+		notify.ChannelToast:    true,
+		notify.ChannelTray:     a.tray.Running(),
+		notify.ChannelWebhook:  settings.WebhookURL != "" || len(settings.Webhooks) > 0,
+		notify.ChannelTelegram: settings.TelegramToken != "" && settings.TelegramChatID != "",
+		notify.ChannelCenter:   true,
+	}
+
+	opts := NotificationOptions{Events: notify.Events(), WebhookPresets: notify.WebhookPresets()}
+	for _, name := range notify.Channels() {
+		opts.Channels = append(opts.Channels, NotificationChannelInfo{Name: name, Configured: configured[name]})
+	}
+	return opts
+}
+
+// TestNotification 向指定渠道发送测试通知
+func (a *App) TestNotification(channel string) error {
+	return a.notifier.Test(channel, models.AppTitle, i18n.T(a.language(), "这是一条测试通知"))
+}
+
+// TestWebhook 按未保存的设置向单个 Webhook 发送测试通知（用于编辑时检查地址和模板）
+func (a *App) TestWebhook(w models.WebhookSettings) error {
+	hook, err := newWebhookSink(w, func() *http.Client { return a.egressClient(notifyClientTimeout) })
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notify.SendTimeout)
+	defer cancel()
+	return hook.Send(ctx, notify.Notification{
+		Event:   notify.EventNodeStatus,
+		Title:   models.AppTitle,
+		Message: i18n.T(a.language(), "这是一条测试通知"),
+		Time:    time.Now(),
+		Node:    "Test",
+		Status:  models.StatusRunning,
+	})
+}
+
+// GetNotifications 获取通知中心的通知（最新的在前）
+func (a *App) GetNotifications() []notify.Notification {
+	return a.notifyCenter.List()
+}
+
+// ClearNotifications 清空通知中心
+func (a *App) ClearNotifications() {
+	a.notifyCenter.Clear()
+}
+
+func validateChannels(channels []string) error {
+	for _, c := range channels {
+		if !notify.ValidChannel(c) {
+			return i18n.Errorf("未知的通知渠道: %s", c)
+		}
+	}
+	return nil
+}
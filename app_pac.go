@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// PAC 系统代理
+// =============================================================================
+
+// pacListen PAC 服务监听地址（随机端口，退出时恢复系统代理设置）
+const pacListen = "127.0.0.1:0"
+
+// SetPACProxy 按节点规则生成 PAC 并设置为系统自动代理，返回 PAC 地址
+// 与全局 SOCKS 系统代理不同，规则为直连的域名不会经过代理
+func (a *App) SetPACProxy(nodeID string) (string, error) {
+	if err := a.checkWritable(); err != nil {
+		return "", err
+	}
+	return a.setPACProxy(nodeID)
+}
+
+// setPACProxy 设置 PAC 自动代理（系统代理按 PAC 模式设置、唤醒后恢复时也经过这里）
+func (a *App) setPACProxy(nodeID string) (string, error) {
+	if _, err := a.GetPACScript(nodeID); err != nil {
+		return "", err
+	}
+
+	if err := a.pacServer.Start(pacListen, func() (string, error) {
+		return a.GetPACScript(nodeID)
+	}); err != nil {
+		return "", err
+	}
+
+	url := a.pacServer.URL()
+	a.recordProxyChange(url)
+	if err := a.proxyManager.SetAutoConfigURL(url); err != nil {
+		a.pacServer.Stop()
+		return "", i18n.Errorf("设置 PAC 失败: %w", err)
+	}
+
+	a.rememberProxy("", true)
+	a.logManager.LogSystem(logger.LevelInfo, "已设置 PAC 自动代理: "+url)
+	return url, nil
+}
+
+// SetSystemProxyMode 设置系统代理模式（仅 SOCKS / 分协议 / PAC 自动代理），已由本程序设置手动系统代理时按新模式重新设置
+func (a *App) SetSystemProxyMode(mode int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if mode < models.SystemProxyModeSocks || mode > models.SystemProxyModePAC {
+		return i18n.Errorf("无效的系统代理模式: %d", mode)
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.SystemProxyMode = mode
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.resumeMu.Lock()
+	proxyNodeID := a.resume.proxyNodeID
+	a.resumeMu.Unlock()
+	if proxyNodeID != "" {
+		if err := a.SetSystemProxy(proxyNodeID); err != nil {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("重新设置系统代理失败: %v", err))
+		}
+	}
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// ClearPACProxy 移除 PAC 自动代理
+func (a *App) ClearPACProxy() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	return a.clearPACProxy()
+}
+
+// clearPACProxy 移除系统代理（PAC 与手动代理）并停止 PAC 服务
+func (a *App) clearPACProxy() error {
+	err := a.proxyManager.ClearSystemProxy()
+	a.pacServer.Stop()
+	if err == nil {
+		a.journalResolve(journalKeyProxy)
+		a.rememberProxy("", false)
+	}
+	return err
+}
+
+// GetPACScript 生成节点的 PAC 脚本（供预览）
+func (a *App) GetPACScript(nodeID string) (string, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return "", i18n.Errorf("节点不存在")
+	}
+
+	host, portStr, err := net.SplitHostPort(node.Listen)
+	if err != nil {
+		return "", i18n.Errorf("监听地址格式错误: %s", node.Listen)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	a.state.Mu.RLock()
+	bypass := a.state.Config.EffectiveProxyBypass()
+	a.state.Mu.RUnlock()
+
+	return system.BuildPAC(a.ruleGroupNode(node).Rules, system.ProxySettings{
+		Server:     host,
+		Port:       port,
+		HTTPPort:   nodeHTTPPort(node),
+		BypassList: bypass,
+	}), nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 内部端口范围
+// =============================================================================
+
+// SetInternalPortRange 设置内部端口范围（如 20000-20099），min 与 max 都为 0 时恢复由系统分配；
+// 对之后启动的节点生效，运行中的节点重新启动后改用新范围内的端口
+func (a *App) SetInternalPortRange(min, max int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	r := models.PortRange{Min: min, Max: max}
+	if err := models.ValidatePortRange(r); err != nil {
+		return err
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.InternalPortRange = r
+	a.state.Mu.Unlock()
+	a.engineManager.SetPortRange(r)
+	go a.saveConfig()
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
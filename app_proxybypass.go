@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 系统代理不代理列表
+// =============================================================================
+
+// GetProxyBypass 获取系统代理的不代理列表（未设置时为默认列表）
+func (a *App) GetProxyBypass() []string {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.EffectiveProxyBypass()
+}
+
+// SetProxyBypass 设置系统代理的不代理列表：主机名（可用 * 通配，如 *.corp.example）、IP、
+// IPv4 通配写法（192.168.*）、CIDR 网段或 <local>（不含点的本地主机名）；列表为空时恢复默认列表。
+// 已由本程序设置手动系统代理时立即重新写入
+func (a *App) SetProxyBypass(list []string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	list = models.NormalizeProxyBypass(list)
+	if err := models.ValidateProxyBypass(list); err != nil {
+		return err
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.ProxyBypass = list
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.resumeMu.Lock()
+	proxyNodeID := a.resume.proxyNodeID
+	a.resumeMu.Unlock()
+	if proxyNodeID != "" {
+		if err := a.SetSystemProxy(proxyNodeID); err != nil {
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("重新设置系统代理失败: %v", err))
+		}
+	}
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
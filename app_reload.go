@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 热重载节点配置
+// =============================================================================
+
+// ReloadNode 将节点修改后的设置应用到运行中的节点：只有 Xray 路由规则变化时经 Xray API 更新，连接不中断；
+// 只有前端内核的配置变化时只重启前端内核；Xlink 核心配置变化时才完全重启。返回实际采用的方式
+func (a *App) ReloadNode(id string) (*engine.ReloadResult, error) {
+	node := a.state.GetNode(id)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在: %s", id)
+	}
+	if a.serviceFront.Load() {
+		if err := a.forwardNodeStart(id); err != nil {
+			return nil, err
+		}
+		return &engine.ReloadResult{NodeID: id, Method: engine.ReloadRestart, Reason: "节点由后台服务运行"}, nil
+	}
+
+	before, err := a.engineManager.SnapshotConfig(id)
+	if err != nil {
+		return nil, err
+	}
+	a.logManager.LogNode(id, node.Name, logger.LevelInfo, logger.CategorySystem, "正在重新加载配置...")
+
+	configPath, err := a.generateNodeConfigReusing(node, before.Node())
+	if err != nil {
+		a.logManager.LogNode(id, node.Name, logger.LevelError, logger.CategorySystem, fmt.Sprintf("生成配置失败: %v", err))
+		return nil, i18n.Errorf("生成配置失败: %w", err)
+	}
+	result, err := a.engineManager.ReloadNode(node, configPath, before)
+	if err != nil {
+		return nil, err
+	}
+	a.emitEvent(models.EventNodeReloaded, result)
+	return result, nil
+}
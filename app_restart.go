@@ -0,0 +1,35 @@
+package main
+
+import (
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 核心进程自动重启策略
+// =============================================================================
+
+// GetRestartPolicy 获取自动重启策略（未设置的字段已填充默认值）
+func (a *App) GetRestartPolicy() models.RestartPolicy {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.RestartPolicy.Normalize()
+}
+
+// SetRestartPolicy 保存自动重启策略，是否重启由各节点的 AutoRestart 开关决定
+func (a *App) SetRestartPolicy(policy models.RestartPolicy) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if policy.MaxRetries < 0 || policy.InitialDelay < 0 || policy.MaxDelay < 0 {
+		return i18n.Errorf("重启策略参数不能为负数")
+	}
+	policy = policy.Normalize()
+
+	a.state.Mu.Lock()
+	a.state.Config.RestartPolicy = policy
+	a.state.Mu.Unlock()
+	a.engineManager.SetRestartPolicy(policy)
+	go a.saveConfig()
+	return nil
+}
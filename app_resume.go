@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/syschange"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 睡眠唤醒后恢复
+// =============================================================================
+
+const (
+	// resumeSettleDelay 唤醒后等待网卡重新连接的时间
+	resumeSettleDelay = 5 * time.Second
+	// resumeProbeTimeout 节点健康检测的超时
+	resumeProbeTimeout = 8 * time.Second
+	// resumeProbeURL 经节点访问的检测地址
+	resumeProbeURL = "http://www.gstatic.com/generate_204"
+)
+
+// resumeState 唤醒后需要重新应用的系统修改
+type resumeState struct {
+	proxyNodeID string                     // 手动系统代理使用的节点
+	pac         bool                       // 已设置 PAC 自动代理
+	plans       map[string]*syschange.Plan // 已执行的 DNS / 路由修改 (key: 修改日志的 Key)
+	recovering  bool
+}
+
+// ResumeReport 一次唤醒恢复的结果
+type ResumeReport struct {
+	Slept     string   `json:"slept"`     // 估算的睡眠时长
+	Checked   int      `json:"checked"`   // 检测的节点数
+	Restarted []string `json:"restarted"` // 重启的节点名称
+	Reapplied int      `json:"reapplied"` // 重新应用的系统设置数
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// startResumeWatcher 启动睡眠唤醒检测
+func (a *App) startResumeWatcher() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	watcher := system.NewResumeWatcher(a.onSystemResume)
+	go watcher.Run(ctx)
+}
+
+// onSystemResume 系统唤醒后检测节点并重新应用系统设置（同一时间只执行一次恢复）
+func (a *App) onSystemResume(slept time.Duration) {
+	a.resumeMu.Lock()
+	if a.resume.recovering {
+		a.resumeMu.Unlock()
+		return
+	}
+	a.resume.recovering = true
+	a.resumeMu.Unlock()
+
+	go func() {
+		defer func() {
+			a.resumeMu.Lock()
+			a.resume.recovering = false
+			a.resumeMu.Unlock()
+		}()
+
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("检测到系统从睡眠中唤醒 (约 %s)，开始恢复", slept.Round(time.Second)))
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-time.After(resumeSettleDelay):
+		}
+		a.recoverAfterResume(slept)
+	}()
+}
+
+// recoverAfterResume 依次恢复节点、系统 DNS / 路由、系统代理并刷新 DNS 缓存，最后输出恢复报告
+func (a *App) recoverAfterResume(slept time.Duration) *ResumeReport {
+	report := &ResumeReport{Slept: slept.Round(time.Second).String()}
+
+	a.recoverNodes(report)
+
+	a.resumeMu.Lock()
+	plans := make(map[string]*syschange.Plan, len(a.resume.plans))
+	for key, plan := range a.resume.plans {
+		plans[key] = plan
+	}
+	proxyNodeID, pac := a.resume.proxyNodeID, a.resume.pac
+	a.resumeMu.Unlock()
+
+	for _, plan := range plans {
+		if errs := plan.RunAll(); len(errs) > 0 {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", plan.Title, errs[0]))
+			continue
+		}
+		report.Reapplied++
+	}
+
+	switch {
+	case pac && a.pacServer.IsRunning():
+		if err := a.proxyManager.SetAutoConfigURL(a.pacServer.URL()); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("PAC 自动代理: %v", err))
+		} else {
+			report.Reapplied++
+		}
+	case proxyNodeID != "":
+		if err := a.SetSystemProxy(proxyNodeID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("系统代理: %v", err))
+		} else {
+			report.Reapplied++
+		}
+	}
+
+	if err := a.FlushDNSCache(); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("刷新 DNS 缓存: %v", err))
+	}
+
+	a.logResumeReport(report)
+	return report
+}
+
+// recoverNodes 重启异常退出的节点，并检测运行中的节点，无法经其访问网络的也重启
+func (a *App) recoverNodes(report *ResumeReport) {
+	for id, st := range a.engineManager.GetAllStatuses() {
+		node := a.state.GetNode(id)
+		if node == nil {
+			continue
+		}
+		report.Checked++
+
+		switch st.Status {
+		case models.StatusRunning:
+			err := probeNodeHealth(node)
+			if err == nil {
+				continue
+			}
+			a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("唤醒后节点 %s 检测失败: %v", node.Name, err))
+		case models.StatusError, models.StatusRestarting:
+		default:
+			continue
+		}
+
+		if err := a.StartNode(id); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", node.Name, err))
+			continue
+		}
+		report.Restarted = append(report.Restarted, node.Name)
+	}
+}
+
+// probeNodeHealth 连接节点的本地监听地址并经其请求检测地址
+func probeNodeHealth(node *models.NodeConfig) error {
+	addr := loopbackListen(node.Listen)
+	conn, err := net.DialTimeout("tcp", addr, resumeProbeTimeout)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(&url.URL{Scheme: "socks5", Host: addr})},
+		Timeout:   resumeProbeTimeout,
+	}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get(resumeProbeURL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// logResumeReport 输出一行恢复报告，有节点重启或出现错误时发送通知
+func (a *App) logResumeReport(report *ResumeReport) {
+	summary := fmt.Sprintf("唤醒恢复完成 (睡眠 %s): 检测 %d 个节点，重启 %d 个，重新应用 %d 项系统设置",
+		report.Slept, report.Checked, len(report.Restarted), report.Reapplied)
+	level := logger.LevelInfo
+	if len(report.Errors) > 0 {
+		summary += fmt.Sprintf("，%d 项失败", len(report.Errors))
+		level = logger.LevelWarn
+	}
+	a.logManager.LogSystem(level, summary)
+	for _, e := range report.Errors {
+		a.logManager.LogSystem(logger.LevelWarn, "唤醒恢复失败: "+e)
+	}
+
+	if len(report.Restarted) > 0 || len(report.Errors) > 0 {
+		a.notify(notify.EventNetwork, summary)
+	}
+	a.emitEvent(models.EventSystemResumed, report)
+}
+
+// rememberSystemChange 记录已执行的 DNS / 路由修改，唤醒后重新执行
+func (a *App) rememberSystemChange(key string, plan *syschange.Plan) {
+	a.resumeMu.Lock()
+	defer a.resumeMu.Unlock()
+	if a.resume.plans == nil {
+		a.resume.plans = make(map[string]*syschange.Plan)
+	}
+	a.resume.plans[key] = plan
+}
+
+// forgetSystemChange 修改已撤销，唤醒后不再执行
+func (a *App) forgetSystemChange(key string) {
+	a.resumeMu.Lock()
+	defer a.resumeMu.Unlock()
+	delete(a.resume.plans, key)
+}
+
+// rememberProxy 记录当前的系统代理：pac 为 true 时为 PAC 自动代理，否则为 nodeID 的手动代理
+func (a *App) rememberProxy(nodeID string, pac bool) {
+	a.resumeMu.Lock()
+	defer a.resumeMu.Unlock()
+	a.resume.proxyNodeID = nodeID
+	a.resume.pac = pac
+}
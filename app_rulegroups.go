@@ -0,0 +1,235 @@
+package main
+
+import (
+	"strings"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 全局规则组
+// =============================================================================
+
+// GetRuleGroups 获取全部规则组
+func (a *App) GetRuleGroups() []models.RuleGroup {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+
+	groups := make([]models.RuleGroup, len(a.state.Config.RuleGroups))
+	copy(groups, a.state.Config.RuleGroups)
+	return groups
+}
+
+// AddRuleGroup 新建规则组，返回新规则组
+func (a *App) AddRuleGroup(group models.RuleGroup) (*models.RuleGroup, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := validateRuleGroup(&group, a.ruleLimit()); err != nil {
+		return nil, err
+	}
+	group.ID = models.GenerateUUID()
+	for i := range group.Rules {
+		group.Rules[i].ID = models.GenerateUUID()
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.RuleGroups = append(a.state.Config.RuleGroups, group)
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.emitEvent(models.EventRuleGroupAdded, models.RuleGroupEventPayload{GroupID: group.ID, Group: &group})
+	return &group, nil
+}
+
+// UpdateRuleGroup 更新规则组（所有引用它的节点在下次启动时生效）
+func (a *App) UpdateRuleGroup(group models.RuleGroup) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := validateRuleGroup(&group, a.ruleLimit()); err != nil {
+		return err
+	}
+	for i := range group.Rules {
+		if group.Rules[i].ID == "" {
+			group.Rules[i].ID = models.GenerateUUID()
+		}
+	}
+
+	a.state.Mu.Lock()
+	existing := models.FindRuleGroup(a.state.Config.RuleGroups, group.ID)
+	if existing == nil {
+		a.state.Mu.Unlock()
+		return i18n.Errorf("规则组不存在")
+	}
+	// 规则组变大后，引用它的节点合并后的规则数量可能超过上限
+	groups := append([]models.RuleGroup(nil), a.state.Config.RuleGroups...)
+	*models.FindRuleGroup(groups, group.ID) = group
+	for _, node := range a.state.Config.Nodes {
+		for _, id := range node.RuleGroupIDs {
+			if id != group.ID {
+				continue
+			}
+			if n := len(models.ResolveNodeRules(&node, groups)); n > a.state.Config.RuleLimit() {
+				a.state.Mu.Unlock()
+				return i18n.Errorf("修改后节点 %s 的规则数量 %d 超过上限 %d", node.Name, n, a.state.Config.RuleLimit())
+			}
+		}
+	}
+	*existing = group
+	nodeIDs := a.ruleGroupUsersLocked(group.ID)
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.emitEvent(models.EventRuleGroupUpdated, models.RuleGroupEventPayload{GroupID: group.ID, Group: &group, NodeIDs: nodeIDs})
+	return nil
+}
+
+// DeleteRuleGroup 删除规则组，并从所有节点中移除对它的引用
+func (a *App) DeleteRuleGroup(id string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	groups := a.state.Config.RuleGroups
+	idx := -1
+	for i := range groups {
+		if groups[i].ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		a.state.Mu.Unlock()
+		return i18n.Errorf("规则组不存在")
+	}
+
+	nodeIDs := a.ruleGroupUsersLocked(id)
+	a.state.Config.RuleGroups = append(groups[:idx], groups[idx+1:]...)
+	for i := range a.state.Config.Nodes {
+		a.state.Config.Nodes[i].RuleGroupIDs = removeString(a.state.Config.Nodes[i].RuleGroupIDs, id)
+	}
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.emitEvent(models.EventRuleGroupDeleted, models.RuleGroupEventPayload{GroupID: id, NodeIDs: nodeIDs})
+	return nil
+}
+
+// AttachRuleGroup 为节点添加规则组引用
+func (a *App) AttachRuleGroup(nodeID, groupID string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	if models.FindRuleGroup(a.state.Config.RuleGroups, groupID) == nil {
+		return i18n.Errorf("规则组不存在")
+	}
+	for i := range a.state.Config.Nodes {
+		node := &a.state.Config.Nodes[i]
+		if node.ID != nodeID {
+			continue
+		}
+		for _, id := range node.RuleGroupIDs {
+			if id == groupID {
+				return nil
+			}
+		}
+
+		node.RuleGroupIDs = append(node.RuleGroupIDs, groupID)
+		if n := len(models.ResolveNodeRules(node, a.state.Config.RuleGroups)); n > a.state.Config.RuleLimit() {
+			node.RuleGroupIDs = node.RuleGroupIDs[:len(node.RuleGroupIDs)-1]
+			return i18n.Errorf("添加后规则数量 %d 超过上限 %d", n, a.state.Config.RuleLimit())
+		}
+
+		go a.saveConfig()
+		a.emitNodeEvent(models.EventNodeUpdated, *node, []string{"rule_group_ids"})
+		return nil
+	}
+	return i18n.Errorf("节点不存在")
+}
+
+// DetachRuleGroup 移除节点的规则组引用
+func (a *App) DetachRuleGroup(nodeID, groupID string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	for i := range a.state.Config.Nodes {
+		node := &a.state.Config.Nodes[i]
+		if node.ID != nodeID {
+			continue
+		}
+		node.RuleGroupIDs = removeString(node.RuleGroupIDs, groupID)
+
+		go a.saveConfig()
+		a.emitNodeEvent(models.EventNodeUpdated, *node, []string{"rule_group_ids"})
+		return nil
+	}
+	return i18n.Errorf("节点不存在")
+}
+
+// ruleGroupNode 返回展开规则组后的节点副本（没有引用规则组时返回原节点）
+func (a *App) ruleGroupNode(node *models.NodeConfig) *models.NodeConfig {
+	if len(node.RuleGroupIDs) == 0 {
+		return node
+	}
+
+	a.state.Mu.RLock()
+	rules := models.ResolveNodeRules(node, a.state.Config.RuleGroups)
+	a.state.Mu.RUnlock()
+
+	resolved := *node
+	resolved.Rules = rules
+	resolved.RuleGroupIDs = nil
+	return &resolved
+}
+
+// ruleGroupUsersLocked 引用指定规则组的节点ID（调用方需持有锁）
+func (a *App) ruleGroupUsersLocked(groupID string) []string {
+	var ids []string
+	for _, node := range a.state.Config.Nodes {
+		for _, id := range node.RuleGroupIDs {
+			if id == groupID {
+				ids = append(ids, node.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// validateRuleGroup 校验规则组基本字段
+func validateRuleGroup(group *models.RuleGroup, maxRules int) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return i18n.Errorf("规则组名称不能为空")
+	}
+	if len(group.Name) > models.MaxNameLen {
+		return i18n.Errorf("规则组名称超过 %d 字节", models.MaxNameLen)
+	}
+	if len(group.Rules) > maxRules {
+		return i18n.Errorf("规则数量 %d 超过上限 %d", len(group.Rules), maxRules)
+	}
+	for _, r := range group.Rules {
+		if strings.TrimSpace(r.Match) == "" || strings.TrimSpace(r.Target) == "" {
+			return i18n.Errorf("规则匹配内容和目标不能为空")
+		}
+	}
+	return nil
+}
+
+func removeString(list []string, s string) []string {
+	result := list[:0]
+	for _, v := range list {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/command"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/scheduler"
+)
+
+// =============================================================================
+// 定时任务（定时启动/停止节点）
+// =============================================================================
+
+// ScheduleInfo 定时任务及其下次执行时间
+type ScheduleInfo struct {
+	models.ScheduleEntry
+	NextRun int64 `json:"next_run"` // Unix 秒，未启用或无法计算时为 0
+}
+
+// ScheduleRunPayload 定时任务执行结果 (schedule:run)
+type ScheduleRunPayload struct {
+	ScheduleID string `json:"schedule_id"`
+	Name       string `json:"name"`
+	Action     string `json:"action"`
+	NodeID     string `json:"node_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// GetSchedules 获取全部定时任务
+func (a *App) GetSchedules() []ScheduleInfo {
+	a.state.Mu.RLock()
+	entries := make([]models.ScheduleEntry, len(a.state.Config.Schedules))
+	copy(entries, a.state.Config.Schedules)
+	a.state.Mu.RUnlock()
+
+	now := time.Now()
+	result := make([]ScheduleInfo, 0, len(entries))
+	for _, e := range entries {
+		info := ScheduleInfo{ScheduleEntry: e}
+		if spec, err := scheduler.Parse(e.Spec); err == nil && e.Enabled {
+			if next := spec.Next(now); !next.IsZero() {
+				info.NextRun = next.Unix()
+			}
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// AddSchedule 新建定时任务
+func (a *App) AddSchedule(entry models.ScheduleEntry) (*models.ScheduleEntry, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := a.validateSchedule(&entry); err != nil {
+		return nil, err
+	}
+	entry.ID = models.GenerateUUID()
+
+	a.state.Mu.Lock()
+	a.state.Config.Schedules = append(a.state.Config.Schedules, entry)
+	a.state.Mu.Unlock()
+
+	go a.saveConfig()
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return &entry, nil
+}
+
+// UpdateSchedule 更新定时任务
+func (a *App) UpdateSchedule(entry models.ScheduleEntry) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := a.validateSchedule(&entry); err != nil {
+		return err
+	}
+
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	for i := range a.state.Config.Schedules {
+		if a.state.Config.Schedules[i].ID == entry.ID {
+			a.state.Config.Schedules[i] = entry
+			go a.saveConfig()
+			a.emitEvent(models.EventSettingsChanged, nil)
+			return nil
+		}
+	}
+	return i18n.Errorf("定时任务不存在")
+}
+
+// DeleteSchedule 删除定时任务
+func (a *App) DeleteSchedule(id string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	schedules := a.state.Config.Schedules
+	for i := range schedules {
+		if schedules[i].ID == id {
+			a.state.Config.Schedules = append(schedules[:i], schedules[i+1:]...)
+			go a.saveConfig()
+			a.emitEvent(models.EventSettingsChanged, nil)
+			return nil
+		}
+	}
+	return i18n.Errorf("定时任务不存在")
+}
+
+// removeNodeSchedulesLocked 删除以该节点为目标的定时任务（调用方持有 a.state.Mu）
+func (a *App) removeNodeSchedulesLocked(nodeID string) {
+	kept := a.state.Config.Schedules[:0]
+	for _, s := range a.state.Config.Schedules {
+		if s.NodeID != nodeID {
+			kept = append(kept, s)
+		}
+	}
+	a.state.Config.Schedules = kept
+}
+
+// validateSchedule 校验定时任务字段
+func (a *App) validateSchedule(entry *models.ScheduleEntry) error {
+	entry.Name = strings.TrimSpace(entry.Name)
+	entry.Spec = strings.TrimSpace(entry.Spec)
+
+	if _, err := scheduler.Parse(entry.Spec); err != nil {
+		return err
+	}
+
+	switch command.Name(entry.Action) {
+	case command.CmdStopAll:
+		entry.NodeID = ""
+		return nil
+	case command.CmdStart, command.CmdStop, command.CmdSwitch:
+	default:
+		return i18n.Errorf("不支持的定时动作: %s", entry.Action)
+	}
+
+	if a.state.GetNode(entry.NodeID) == nil {
+		return i18n.Errorf("节点不存在")
+	}
+	return nil
+}
+
+// startScheduler 启动定时任务循环（每分钟整点检查一次）
+func (a *App) startScheduler(ctx context.Context) {
+	go func() {
+		for {
+			now := time.Now()
+			wait := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			a.runDueSchedules(time.Now().Truncate(time.Minute))
+		}
+	}()
+}
+
+// runDueSchedules 执行在指定分钟到期的任务
+func (a *App) runDueSchedules(minute time.Time) {
+	a.state.Mu.RLock()
+	var due []models.ScheduleEntry
+	for _, e := range a.state.Config.Schedules {
+		if !e.Enabled {
+			continue
+		}
+		if spec, err := scheduler.Parse(e.Spec); err == nil && spec.Matches(minute) {
+			due = append(due, e)
+		}
+	}
+	a.state.Mu.RUnlock()
+
+	// 按配置顺序依次执行，保证同一分钟内 "停止 A、启动 B" 的先后关系
+	for _, e := range due {
+		_, err := a.commandBus.Dispatch(command.Command{
+			Name:    command.Name(e.Action),
+			NodeRef: e.NodeID,
+			Source:  command.SourceSchedule,
+		})
+
+		payload := ScheduleRunPayload{ScheduleID: e.ID, Name: e.Name, Action: e.Action, NodeID: e.NodeID}
+		if err != nil {
+			payload.Error = err.Error()
+			a.notify(notify.EventSchedule, fmt.Sprintf("定时任务 %s 执行失败: %v", e.Name, err))
+		}
+		a.emitEvent(models.EventScheduleRun, payload)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// 服务器池健康探测
+// =============================================================================
+
+// SetServerHealthCheck 设置后台服务器健康探测：定期以 TCP 连接探测运行中节点池内的每个服务器，
+// 不可用的服务器从生成的配置中暂时移除并重新加载节点。intervalSeconds 为 0 时使用默认间隔（60 秒）
+func (a *App) SetServerHealthCheck(enabled bool, intervalSeconds int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if intervalSeconds < 0 || (intervalSeconds > 0 && time.Duration(intervalSeconds)*time.Second < engine.MinHealthInterval) {
+		return i18n.Errorf("探测间隔不能少于 %d 秒", int(engine.MinHealthInterval/time.Second))
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.ServerHealthCheck = enabled
+	a.state.Config.ServerHealthInterval = intervalSeconds
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.applyServerHealthSettings()
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// GetServerHealth 获取节点服务器池的健康状态（按池中顺序，节点运行且开启探测后才有记录）
+func (a *App) GetServerHealth(nodeID string) []engine.ServerHealthStatus {
+	return a.serverHealth.Status(nodeID)
+}
+
+// serverHealthSettings 读取健康探测配置（补全默认值）
+func (a *App) serverHealthSettings() (time.Duration, bool) {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+
+	interval := time.Duration(a.state.Config.ServerHealthInterval) * time.Second
+	if interval <= 0 {
+		interval = engine.DefaultHealthInterval
+	}
+	return interval, a.state.Config.ServerHealthCheck
+}
+
+// serverHealthState 健康探测循环与各节点生成配置时使用的服务器池
+type serverHealthState struct {
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	interval time.Duration
+	pools    map[string]string // 节点 → 当前运行的配置中的服务器池（";" 分隔）
+}
+
+// applyServerHealthSettings 按当前配置启动或停止健康探测（间隔未变化时保持原有计时）
+// 关闭探测时清除状态，并重新加载移除过服务器的节点，恢复完整的服务器池
+func (a *App) applyServerHealthSettings() {
+	interval, enabled := a.serverHealthSettings()
+
+	h := &a.healthState
+	h.mu.Lock()
+	if h.cancel != nil {
+		if enabled && interval == h.interval {
+			h.mu.Unlock()
+			return
+		}
+		h.cancel()
+		h.cancel = nil
+	}
+	h.interval = interval
+	if enabled {
+		ctx, cancel := context.WithCancel(a.ctx)
+		h.cancel = cancel
+		h.mu.Unlock()
+		go a.serverHealthLoop(ctx, interval)
+		return
+	}
+	pools := h.pools
+	h.pools = nil
+	h.mu.Unlock()
+
+	for _, id := range a.serverHealth.NodeIDs() {
+		a.serverHealth.Forget(id)
+	}
+	for id, pool := range pools {
+		node := a.state.GetNode(id)
+		if node == nil || a.engineManager.GetStatus(id) != models.StatusRunning {
+			continue
+		}
+		if pool != strings.Join(models.SplitServers(node.Server), ";") {
+			a.reloadNodeServers(id, node.Name, "已关闭服务器健康探测，恢复完整的服务器池")
+		}
+	}
+}
+
+func (a *App) serverHealthLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		a.checkServerHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkServerHealth 探测一轮运行中的节点，服务器状态变化时通知，并在可用的服务器池变化时重新加载节点
+func (a *App) checkServerHealth(ctx context.Context) {
+	if a.serviceFront.Load() {
+		return
+	}
+
+	for _, id := range a.runningNodeIDs() {
+		node := a.state.GetNode(id)
+		if node == nil {
+			continue
+		}
+		nodeCopy := *node
+
+		results := probeNodeServers(ctx, &nodeCopy)
+		if ctx.Err() != nil {
+			return
+		}
+		changes := a.serverHealth.Update(id, results)
+		for _, c := range changes {
+			a.reportServerHealth(&nodeCopy, c)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		a.healthState.mu.Lock()
+		applied, ok := a.healthState.pools[id]
+		a.healthState.mu.Unlock()
+		if !ok {
+			applied = strings.Join(models.SplitServers(nodeCopy.Server), ";")
+		}
+		if a.serverHealth.HealthyPool(id, nodeCopy.Server) != applied {
+			a.reloadNodeServers(id, nodeCopy.Name, "可用的服务器发生变化，重新加载节点")
+		}
+	}
+
+	// 已停止的节点清除状态，下次启动时重新探测（正在启动、等待重启的保留）
+	for _, id := range a.serverHealth.NodeIDs() {
+		if st := a.engineManager.GetStatus(id); st == models.StatusStopped || st == models.StatusError {
+			a.serverHealth.Forget(id)
+			a.healthState.mu.Lock()
+			delete(a.healthState.pools, id)
+			a.healthState.mu.Unlock()
+		}
+	}
+}
+
+// probeNodeServers 同时探测节点池内的全部服务器，结果按池中顺序
+func probeNodeServers(ctx context.Context, node *models.NodeConfig) []models.PingResult {
+	servers := models.SplitServers(node.Server)
+	results := make([]models.PingResult, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server string) {
+			defer wg.Done()
+			results[i] = logger.ProbeServer(ctx, server, node.IP, logger.PingModeTCP)
+		}(i, server)
+	}
+	wg.Wait()
+	return results
+}
+
+// reportServerHealth 记录服务器状态变化并通知前端
+func (a *App) reportServerHealth(node *models.NodeConfig, c engine.ServerHealthChange) {
+	if c.Up {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategorySystem,
+			fmt.Sprintf("服务器 %s 已恢复", c.Server))
+		a.notifyNode(notify.EventNode, node.Name, "server_up", fmt.Sprintf("[%s] 服务器 %s 已恢复", node.Name, c.Server))
+	} else {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelWarn, logger.CategorySystem,
+			fmt.Sprintf("服务器 %s 连续 %d 次探测失败，暂时从服务器池中移除: %s", c.Server, engine.HealthFailThreshold, c.Error))
+		a.notifyNode(notify.EventNode, node.Name, "server_down", fmt.Sprintf("[%s] 服务器 %s 不可用: %s", node.Name, c.Server, c.Error))
+	}
+	a.emitEvent(models.EventServerHealth, c)
+}
+
+// reloadNodeServers 重新生成配置并重启节点，使服务器池的变化生效
+func (a *App) reloadNodeServers(id, name, reason string) {
+	a.logManager.LogNode(id, name, logger.LevelInfo, logger.CategorySystem, reason)
+	if err := a.StartNode(id); err != nil {
+		a.logManager.LogNode(id, name, logger.LevelError, logger.CategorySystem, fmt.Sprintf("重新加载节点失败: %v", err))
+	}
+}
+
+// healthyServerNode 开启健康探测时返回移除了不可用服务器的节点副本，并记录生成配置使用的服务器池
+// 移除只作用于生成的内核配置，不写回用户配置
+func (a *App) healthyServerNode(node *models.NodeConfig) *models.NodeConfig {
+	if _, enabled := a.serverHealthSettings(); !enabled {
+		return node
+	}
+
+	pool := a.serverHealth.HealthyPool(node.ID, node.Server)
+	a.healthState.mu.Lock()
+	if a.healthState.pools == nil {
+		a.healthState.pools = make(map[string]string)
+	}
+	a.healthState.pools[node.ID] = pool
+	a.healthState.mu.Unlock()
+
+	if pool == strings.Join(models.SplitServers(node.Server), ";") {
+		return node
+	}
+	healthy := *node
+	healthy.Server = pool
+	return &healthy
+}
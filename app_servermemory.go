@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 最近可用服务器记忆
+// =============================================================================
+
+// ServerMemoryFileName 最近可用服务器记录文件
+const ServerMemoryFileName = "server_memory.json"
+
+// GetPreferredServers 获取节点最近可用的服务器（下次启动时优先尝试）
+func (a *App) GetPreferredServers(nodeID string) []string {
+	return a.serverMemory.Preferred(nodeID)
+}
+
+// ResetPreferredServers 清除节点的服务器记忆，下次启动按原始顺序
+func (a *App) ResetPreferredServers(nodeID string) {
+	a.serverMemory.Forget(nodeID)
+}
+
+// serverPoolChanged 修改的字段中是否包含服务器地址池（变更后原有记忆不再可信）
+func serverPoolChanged(fields []string) bool {
+	for _, f := range fields {
+		if f == "server" {
+			return true
+		}
+	}
+	return false
+}
+
+// rememberTunnel 隧道建立成功时记录服务器
+func (a *App) rememberTunnel(nodeID, message string) {
+	a.serverMemory.Touch(nodeID, engine.TunnelServer(message))
+}
+
+// rememberPingReport 测速完成后按延迟记录可用服务器
+func (a *App) rememberPingReport(report logger.PingReport) {
+	var servers []string
+	for _, r := range report.Results {
+		if r.Latency >= 0 && r.Server != "" {
+			servers = append(servers, r.Server)
+		}
+	}
+	a.serverMemory.SetRanking(report.NodeID, servers)
+}
+
+// preferredServerNode 返回把最近可用服务器排在前面的节点副本
+func (a *App) preferredServerNode(node *models.NodeConfig) *models.NodeConfig {
+	preferred := a.serverMemory.Preferred(node.ID)
+	if len(preferred) == 0 {
+		return node
+	}
+
+	reordered := *node
+	reordered.Server = engine.ReorderServers(node.Server, preferred)
+	return &reordered
+}
+
+// =============================================================================
+// 按测速结果排序服务器池
+// =============================================================================
+
+// PingOrderingResult 重排服务器池的结果
+type PingOrderingResult struct {
+	ReportID string   `json:"report_id"` // 依据的测速报告
+	Servers  []string `json:"servers"`   // 重排后的服务器池
+	Dropped  []string `json:"dropped"`   // 删除的测速失败的服务器
+	Changed  bool     `json:"changed"`
+}
+
+// ApplyPingOrdering 按节点最近一次测速的结果重写服务器池（延迟低的在前），dropFailed 时删除测速失败的服务器
+// 负载策略（hash / rr / random）随后只在可用的服务器中选择；运行中的节点重新启动后生效
+func (a *App) ApplyPingOrdering(nodeID string, dropFailed bool) (*PingOrderingResult, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在: %s", nodeID)
+	}
+	reports := a.pingReportStore.List(nodeID)
+	if len(reports) == 0 {
+		return nil, i18n.Errorf("节点还没有测速结果，请先测速")
+	}
+	report, err := a.pingReportStore.Get(reports[0].ID)
+	if err != nil {
+		return nil, i18n.Errorf("测速报告不存在: %s", reports[0].ID)
+	}
+
+	pool, dropped := engine.RankServers(node.Server, report.Results, dropFailed)
+	if pool == "" {
+		return nil, i18n.Errorf("测速结果中没有可用的服务器，未修改服务器池")
+	}
+	result := &PingOrderingResult{ReportID: report.ID, Servers: models.SplitServers(pool), Dropped: dropped}
+
+	a.state.Mu.Lock()
+	var snapshot *models.NodeConfig
+	for i := range a.state.Config.Nodes {
+		n := &a.state.Config.Nodes[i]
+		if n.ID == nodeID {
+			result.Changed = strings.Join(models.SplitServers(n.Server), ";") != pool
+			n.Server = pool
+			nodeCopy := *n
+			snapshot = &nodeCopy
+			break
+		}
+	}
+	a.state.Mu.Unlock()
+	if snapshot == nil {
+		return nil, i18n.Errorf("节点不存在: %s", nodeID)
+	}
+	if !result.Changed {
+		return result, nil
+	}
+
+	go a.saveConfig()
+	a.emitNodeEvent(models.EventNodeUpdated, *snapshot, []string{"server"})
+	msg := fmt.Sprintf("已按测速结果重排服务器池: %s", strings.Join(result.Servers, ", "))
+	if len(dropped) > 0 {
+		msg += fmt.Sprintf("；删除测速失败的服务器: %s", strings.Join(dropped, ", "))
+	}
+	a.logManager.LogNode(snapshot.ID, snapshot.Name, logger.LevelInfo, logger.CategoryPing, msg)
+	return result, nil
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/api"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 后台服务（Windows 服务）
+// =============================================================================
+
+// 安装为服务后，节点由服务进程运行，用户注销或未登录桌面时保持连接。
+// 服务与界面共用安装时的数据目录：服务始终开启本地控制接口，界面检测到服务运行时
+// 成为控制前端，节点的启动 / 停止 / 状态查询经控制接口转发给服务，自身不再运行节点。
+// 配置文件只由界面进程写入：服务以只读方式加载，界面保存后通知服务重新读取。
+
+const (
+	// serviceAPIWait 服务启动后等待控制接口就绪的时间
+	serviceAPIWait = 10 * time.Second
+	// serviceStatusInterval 控制前端同步服务中节点状态的间隔
+	serviceStatusInterval = 3 * time.Second
+)
+
+// ServiceInfo 后台服务状态
+type ServiceInfo struct {
+	system.ServiceStatus
+	Frontend bool   `json:"frontend"` // 界面正作为服务的控制前端（节点由服务运行）
+	DataDir  string `json:"data_dir"` // 服务使用的数据目录
+}
+
+// parseServiceArg 解析 --service [--data-dir <dir>] 参数
+func parseServiceArg(args []string) (dataDir string, ok bool) {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == system.ServiceArg:
+			ok = true
+		case args[i] == system.ServiceDataDirArg && i+1 < len(args):
+			dataDir = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], system.ServiceDataDirArg+"="):
+			dataDir = strings.Trim(strings.TrimPrefix(args[i], system.ServiceDataDirArg+"="), `"`)
+		}
+	}
+	return dataDir, ok
+}
+
+// runService 以服务方式无界面运行，返回进程退出码
+func runService(exeDir, dataDir string) int {
+	layout := &dataLayout{Dir: dataDir}
+	if dataDir == "" {
+		layout = resolveDataLayout(exeDir)
+	} else if err := os.MkdirAll(dataDir, 0755); err != nil {
+		layout.Err = fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	app := NewApp()
+	app.headless = true
+	app.state.ExeDir = exeDir
+	app.state.DataDir = layout.Dir
+	app.dataLayout = layout
+
+	err := system.RunService(func(ctx context.Context) {
+		app.startup(context.Background())
+		<-ctx.Done()
+		app.shutdown(context.Background())
+	})
+	if err != nil {
+		msg := fmt.Sprintf("服务运行失败: %v", err)
+		system.ReportServiceError(msg)
+		if app.logManager != nil {
+			app.logManager.LogSystem(logger.LevelError, msg)
+		}
+		return 1
+	}
+	return 0
+}
+
+// GetServiceStatus 获取后台服务状态
+func (a *App) GetServiceStatus() ServiceInfo {
+	return ServiceInfo{
+		ServiceStatus: system.QueryService(),
+		Frontend:      a.serviceFront.Load(),
+		DataDir:       a.state.DataDir,
+	}
+}
+
+// InstallService 安装后台服务（开机自动启动，使用当前数据目录，需要管理员权限）
+func (a *App) InstallService() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return i18n.Errorf("无法获取程序路径: %w", err)
+	}
+	a.prepareServiceConfig()
+	if err := system.InstallService(exePath, a.state.DataDir); err != nil {
+		return err
+	}
+	a.logManager.LogSystem(logger.LevelInfo, "后台服务已安装")
+	return nil
+}
+
+// UninstallService 停止并卸载后台服务，节点改回由界面运行
+func (a *App) UninstallService() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := system.UninstallService(); err != nil {
+		return err
+	}
+	a.leaveServiceFrontend()
+	a.logManager.LogSystem(logger.LevelInfo, "后台服务已卸载")
+	return nil
+}
+
+// StartService 启动后台服务：界面停止自身运行的节点，由服务接管（服务恢复上次运行的节点）
+func (a *App) StartService() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.serviceFront.Load() {
+		return nil
+	}
+
+	// 保留上次运行的节点记录，服务启动后按该记录恢复
+	a.engineManager.StopAll()
+	a.apiServer.Stop()
+	a.metricsServer.Stop()
+	a.stopLocalTasks()
+	a.prepareServiceConfig()
+
+	if err := system.StartService(); err != nil {
+		a.applyAPISettings()
+		a.applyMetricsSettings()
+		a.startLocalTasks()
+		return err
+	}
+	if err := a.enterServiceFrontend(); err != nil {
+		return err
+	}
+	a.logManager.LogSystem(logger.LevelInfo, "后台服务已启动，节点由服务运行")
+	return nil
+}
+
+// StopService 停止后台服务（服务中的节点随之停止）
+func (a *App) StopService() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if err := system.StopService(); err != nil {
+		return err
+	}
+	a.leaveServiceFrontend()
+	a.logManager.LogSystem(logger.LevelInfo, "后台服务已停止")
+	return nil
+}
+
+// enableServiceAPI 服务模式下始终开启控制接口，界面经它转发命令（只修改内存中的配置，
+// 令牌由界面在安装 / 启动服务前写入配置文件）
+func (a *App) enableServiceAPI() {
+	a.state.Mu.Lock()
+	a.state.Config.APIEnabled = true
+	a.state.Mu.Unlock()
+}
+
+// prepareServiceConfig 启动服务前由界面开启控制接口并生成令牌写入配置文件，服务读取后即可被界面连接
+func (a *App) prepareServiceConfig() {
+	a.state.Mu.Lock()
+	a.state.Config.APIEnabled = true
+	if a.state.Config.APIToken == "" {
+		a.state.Config.APIToken = api.GenerateToken()
+	}
+	a.state.Mu.Unlock()
+	a.saveConfig()
+}
+
+// enterServiceFrontend 等待服务的控制接口就绪后切换为控制前端
+func (a *App) enterServiceFrontend() error {
+	deadline := time.Now().Add(serviceAPIWait)
+	for {
+		if _, err := a.newServiceClient().Status(); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return i18n.Errorf("后台服务已启动，但控制接口无响应")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	a.serviceFront.Store(true)
+	a.stopLocalTasks()
+	a.syncServiceStatuses()
+	return nil
+}
+
+// leaveServiceFrontend 服务停止后恢复由界面运行节点
+func (a *App) leaveServiceFrontend() {
+	if !a.serviceFront.Swap(false) {
+		return
+	}
+	a.applyAPISettings()
+	a.applyMetricsSettings()
+	a.startLocalTasks()
+	for _, n := range a.GetNodes(models.NodeQuery{}).Nodes {
+		a.state.UpdateNodeStatus(n.ID, models.StatusStopped, "")
+		a.emitNodeStatus(n.ID, models.StatusStopped)
+	}
+	a.refreshTrayMenu()
+}
+
+// reloadConfigFile 重新读取配置文件（服务收到界面的通知后调用）
+func (a *App) reloadConfigFile() error {
+	if _, err := a.configManager.Load(); err != nil {
+		return err
+	}
+	a.reloadConfig()
+	return nil
+}
+
+// newServiceClient 使用共用配置中的地址和令牌连接服务的控制接口
+func (a *App) newServiceClient() *api.Client {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return api.NewClient(a.state.Config.APIListen, a.state.Config.APIToken)
+}
+
+// forwardToService 控制前端把节点操作转发给服务：先保存配置并通知服务重新读取，再执行操作
+func (a *App) forwardToService(op func(c *api.Client) error) error {
+	a.saveConfig()
+	c := a.newServiceClient()
+	if err := c.Reload(); err != nil {
+		return i18n.Errorf("后台服务无响应: %w", err)
+	}
+	err := op(c)
+	a.syncServiceStatuses()
+	return err
+}
+
+// forwardNodeStart 控制前端经服务启动节点
+func (a *App) forwardNodeStart(id string) error {
+	a.state.Mu.Lock()
+	a.state.Config.LastRunningNodeID = id
+	a.state.Mu.Unlock()
+	return a.forwardToService(func(c *api.Client) error { return c.StartNode(id) })
+}
+
+// forwardNodeStop 控制前端经服务停止节点（id 为空时停止所有节点）
+func (a *App) forwardNodeStop(id string) error {
+	a.state.Mu.Lock()
+	if id == "" || a.state.Config.LastRunningNodeID == id {
+		a.state.Config.LastRunningNodeID = ""
+	}
+	a.state.Mu.Unlock()
+	return a.forwardToService(func(c *api.Client) error {
+		if id == "" {
+			return c.StopAll()
+		}
+		return c.StopNode(id)
+	})
+}
+
+// serviceStatuses 服务中所有节点的运行状态
+func (a *App) serviceStatuses() map[string]models.EngineStatus {
+	statuses, err := a.newServiceClient().Status()
+	if err != nil {
+		return map[string]models.EngineStatus{}
+	}
+	return statuses
+}
+
+// syncServiceStatuses 把服务中节点状态的变化同步到界面
+func (a *App) syncServiceStatuses() {
+	statuses := a.serviceStatuses()
+	changed := false
+	for _, n := range a.GetNodes(models.NodeQuery{}).Nodes {
+		status := models.StatusStopped
+		if st, ok := statuses[n.ID]; ok {
+			status = st.Status
+		}
+		if n.Status == status {
+			continue
+		}
+		a.state.UpdateNodeStatus(n.ID, status, "")
+		a.emitNodeStatus(n.ID, status)
+		changed = true
+	}
+	if changed {
+		a.refreshTrayMenu()
+	}
+}
+
+// startServiceStatusLoop 控制前端定期同步服务中的节点状态（服务自动重启节点等变化）
+func (a *App) startServiceStatusLoop() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(serviceStatusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if a.serviceFront.Load() {
+				a.syncServiceStatuses()
+			}
+		}
+	}()
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/api"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 带宽测速
+// =============================================================================
+
+// SpeedTest 测试节点的下载/上传带宽（异步，进度通过 speedtest:progress 推送）
+// 节点未运行时会临时启动，测试结束后停止
+func (a *App) SpeedTest(nodeID string) error {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return i18n.Errorf("节点不存在")
+	}
+
+	ctx, done, err := a.speedTester.Begin(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	a.state.Mu.RLock()
+	opts := logger.SpeedTestOptions{
+		DownloadURL: a.state.Config.SpeedTestDownloadURL,
+		UploadURL:   a.state.Config.SpeedTestUploadURL,
+	}
+	a.state.Mu.RUnlock()
+	if opts.UploadURL == "" {
+		opts.UploadURL = logger.DefaultSpeedTestUploadURL
+	}
+
+	go func() {
+		defer done()
+		a.runSpeedTest(ctx, node, opts)
+	}()
+	return nil
+}
+
+// SetSpeedTestURLs 设置带宽测速的下载和上传地址，为空时使用内置地址
+func (a *App) SetSpeedTestURLs(downloadURL, uploadURL string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	downloadURL, uploadURL = strings.TrimSpace(downloadURL), strings.TrimSpace(uploadURL)
+	for _, raw := range []string{downloadURL, uploadURL} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("测速地址无效: %s", raw)
+		}
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.SpeedTestDownloadURL = downloadURL
+	a.state.Config.SpeedTestUploadURL = uploadURL
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// StopSpeedTest 停止正在进行的测速
+func (a *App) StopSpeedTest() {
+	a.speedTester.Stop()
+}
+
+// runSpeedTest 执行测速并推送结果
+func (a *App) runSpeedTest(ctx context.Context, node *models.NodeConfig, opts logger.SpeedTestOptions) {
+	a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategoryPing, "正在进行带宽测速...")
+
+	result := &logger.SpeedTestResult{NodeID: node.ID, NodeName: node.Name, StartTime: time.Now()}
+	finish := func(err error) {
+		result.NodeID, result.NodeName = node.ID, node.Name
+		if err != nil {
+			result.Error = err.Error()
+			a.logManager.LogNode(node.ID, node.Name, logger.LevelError, logger.CategoryPing, fmt.Sprintf("带宽测速失败: %v", err))
+		} else {
+			a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategoryPing,
+				fmt.Sprintf("带宽测速完成: 下载 %.1f Mbps, 上传 %.1f Mbps", result.DownloadMbps, result.UploadMbps))
+		}
+		a.emitEvent(models.EventSpeedTestComplete, result)
+	}
+
+	release, err := a.ensureNodeRunning(ctx, node)
+	if err != nil {
+		finish(err)
+		return
+	}
+	defer release()
+
+	res, err := a.speedTester.Run(ctx, loopbackListen(node.Listen), opts, func(p logger.SpeedTestProgress) {
+		p.NodeID = node.ID
+		a.emitEvent(models.EventSpeedTestProgress, p)
+	})
+	if res != nil {
+		result = res
+	}
+	if ctx.Err() == context.Canceled {
+		err = i18n.Errorf("测速已取消")
+	}
+	finish(err)
+}
+
+// ensureNodeRunning 节点未运行时按 StartNode 的流程临时启动（包括未运行的前置节点），返回测试结束后调用的 release
+// release 只停止仍由本次启动的实例运行的节点：测试期间被重新启动或被其他运行中的节点用作前置节点时保持运行
+// 引擎在本地入站可连接后才返回，等待时间由连接策略的启动超时决定
+func (a *App) ensureNodeRunning(ctx context.Context, node *models.NodeConfig) (func(), error) {
+	before := a.GetAllNodeStatuses()
+	if st, ok := before[node.ID]; ok && st.Status == models.StatusRunning {
+		return func() {}, nil
+	}
+
+	var err error
+	if a.serviceFront.Load() {
+		err = a.forwardToService(func(c *api.Client) error { return c.StartNode(node.ID) })
+	} else {
+		err = a.startNodeChain(node.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 本次启动的实例，以进程启动时间区分
+	started := make(map[string]time.Time)
+	for id, st := range a.GetAllNodeStatuses() {
+		if prev, ok := before[id]; st.Status == models.StatusRunning && (!ok || prev.Status != models.StatusRunning) {
+			started[id] = st.StartTime
+		}
+	}
+	release := func() { a.stopTemporaryNodes(node.ID, started) }
+
+	if ctx.Err() != nil {
+		release()
+		return nil, i18n.Errorf("测速已取消")
+	}
+	return release, nil
+}
+
+// stopTemporaryNodes 停止临时启动的节点（先停止测试的节点，再停止其前置节点）
+func (a *App) stopTemporaryNodes(nodeID string, started map[string]time.Time) {
+	ids := make([]string, 0, len(started))
+	for id := range started {
+		if id != nodeID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if _, ok := started[nodeID]; ok {
+		ids = append([]string{nodeID}, ids...)
+	}
+
+	a.state.Mu.RLock()
+	nodes := make([]models.NodeConfig, len(a.state.Config.Nodes))
+	copy(nodes, a.state.Config.Nodes)
+	a.state.Mu.RUnlock()
+
+	stopped := make(map[string]bool)
+	for _, id := range ids {
+		statuses := a.GetAllNodeStatuses()
+		st, ok := statuses[id]
+		if !ok || st.Status != models.StatusRunning || !st.StartTime.Equal(started[id]) {
+			continue // 已停止或已被重新启动
+		}
+		inUse := false
+		for _, n := range nodes {
+			if n.ChainNodeID == id && !stopped[n.ID] && statuses[n.ID].Status == models.StatusRunning {
+				inUse = true
+				break
+			}
+		}
+		if inUse {
+			continue
+		}
+		if a.serviceFront.Load() {
+			a.forwardToService(func(c *api.Client) error { return c.StopNode(id) })
+		} else {
+			a.engineManager.StopNode(id)
+		}
+		stopped[id] = true
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 流量统计
+// =============================================================================
+
+// statsInterval 流量速率采样及 stats:update 推送间隔
+const statsInterval = time.Second
+
+// startStatsLoop 周期采样流量并向前端推送有变化的节点统计
+func (a *App) startStatsLoop() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if changed := a.statsManager.Sample(); len(changed) > 0 {
+					a.emitEvent(models.EventStatsUpdate, changed)
+				}
+			}
+		}
+	}()
+}
+
+// GetTrafficStats 获取节点流量统计
+func (a *App) GetTrafficStats(nodeID string) models.TrafficStats {
+	return a.statsManager.Get(nodeID)
+}
+
+// GetAllTrafficStats 获取所有节点的流量统计
+func (a *App) GetAllTrafficStats() []models.TrafficStats {
+	return a.statsManager.GetAll()
+}
+
+// ResetTrafficStats 清零节点流量统计（节点ID为空时清零全部）
+func (a *App) ResetTrafficStats(nodeID string) {
+	a.statsManager.Reset(nodeID)
+}
+
+// =============================================================================
+// 活动连接
+// =============================================================================
+
+// GetConnections 获取节点的活动连接（节点ID为空时返回所有节点），includeClosed 附带最近结束的连接
+func (a *App) GetConnections(nodeID string, includeClosed bool) []models.ConnectionInfo {
+	return a.engineManager.GetConnections(nodeID, includeClosed)
+}
+
+// CloseConnection 强制关闭节点上的一条连接
+func (a *App) CloseConnection(nodeID string, connID uint64) error {
+	if err := a.engineManager.CloseConnection(nodeID, connID); err != nil {
+		return err
+	}
+	a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已关闭连接 #%d (节点 %s)", connID, nodeID))
+	return nil
+}
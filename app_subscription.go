@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// 订阅（保存订阅地址，刷新时报告节点变化）
+// =============================================================================
+
+// subscriptionNotifyLimit 通知中列出的变化条数
+const subscriptionNotifyLimit = 5
+
+// SubscriptionRefreshPayload 订阅刷新结果 (subscription:refreshed)
+type SubscriptionRefreshPayload struct {
+	SubscriptionID string                     `json:"subscription_id"`
+	Name           string                     `json:"name"`
+	Refresh        models.SubscriptionRefresh `json:"refresh"`
+}
+
+// GetSubscriptions 获取全部订阅及其刷新记录
+func (a *App) GetSubscriptions() []models.Subscription {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	subs := make([]models.Subscription, len(a.state.Config.Subscriptions))
+	copy(subs, a.state.Config.Subscriptions)
+	return subs
+}
+
+// AddSubscription 保存订阅并立即刷新一次（导入其中的节点）
+func (a *App) AddSubscription(name, rawURL string) (*models.Subscription, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	rawURL = strings.TrimSpace(rawURL)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = rawURL
+	}
+
+	a.state.Mu.Lock()
+	for _, s := range a.state.Config.Subscriptions {
+		if s.URL == rawURL {
+			a.state.Mu.Unlock()
+			return nil, i18n.Errorf("该订阅已存在: %s", s.Name)
+		}
+	}
+	a.state.Mu.Unlock()
+
+	// 先下载确认地址可用，再保存订阅
+	parsed, err := a.fetchImport(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	sub := models.Subscription{ID: models.GenerateUUID(), Name: name, URL: rawURL}
+	a.state.Mu.Lock()
+	a.state.Config.Subscriptions = append(a.state.Config.Subscriptions, sub)
+	a.state.Mu.Unlock()
+
+	if _, err := a.applySubscription(sub.ID, parsed); err != nil {
+		return nil, err
+	}
+	for _, s := range a.GetSubscriptions() {
+		if s.ID == sub.ID {
+			return &s, nil
+		}
+	}
+	return &sub, nil
+}
+
+// DeleteSubscription 删除订阅；deleteNodes 为 true 时同时删除该订阅导入的（未运行的）节点，否则保留为普通节点
+func (a *App) DeleteSubscription(id string, deleteNodes bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	defer a.state.Mu.Unlock()
+
+	subs := a.state.Config.Subscriptions
+	index := -1
+	for i := range subs {
+		if subs[i].ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return i18n.Errorf("订阅不存在")
+	}
+
+	if deleteNodes {
+		for _, n := range a.state.Config.Nodes {
+			if es, ok := a.state.EngineStatuses[n.ID]; ok && n.SubscriptionID == id && es.Status == models.StatusRunning {
+				return i18n.Errorf("请先停止节点再删除")
+			}
+		}
+		a.backupBeforeChange(config.BackupReasonDelete)
+	}
+
+	nodes := make([]models.NodeConfig, 0, len(a.state.Config.Nodes))
+	for _, n := range a.state.Config.Nodes {
+		if n.SubscriptionID == id {
+			if deleteNodes {
+				a.forgetNode(n.ID)
+				continue
+			}
+			n.SubscriptionID = ""
+		}
+		nodes = append(nodes, n)
+	}
+	a.state.Config.Nodes = nodes
+	a.state.Config.Subscriptions = append(subs[:index], subs[index+1:]...)
+
+	go a.saveConfig()
+	a.emitEvent(models.EventConfigChanged, nil)
+	return nil
+}
+
+// RefreshSubscription 重新下载订阅，更新其节点并返回本次刷新的变化
+func (a *App) RefreshSubscription(id string) (*models.SubscriptionRefresh, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	sub := a.findSubscription(id)
+	if sub == nil {
+		return nil, i18n.Errorf("订阅不存在")
+	}
+
+	parsed, err := a.fetchImport(sub.URL)
+	if err != nil {
+		a.recordSubscriptionRefresh(id, models.SubscriptionRefresh{Time: time.Now().Unix(), Error: err.Error()})
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("刷新订阅 %s 失败: %v", sub.Name, err))
+		return nil, err
+	}
+	return a.applySubscription(id, parsed)
+}
+
+// applySubscription 将解析出的订阅内容合并到节点列表，记录变化并通知
+func (a *App) applySubscription(id string, parsed *config.ParsedImport) (*models.SubscriptionRefresh, error) {
+	refresh := models.SubscriptionRefresh{Time: time.Now().Unix(), Format: parsed.Format}
+
+	a.state.Mu.Lock()
+	var name string
+	for _, s := range a.state.Config.Subscriptions {
+		if s.ID == id {
+			name = s.Name
+		}
+	}
+	if name == "" {
+		a.state.Mu.Unlock()
+		return nil, i18n.Errorf("订阅不存在")
+	}
+
+	merged, _ := config.MergeSubscription(a.state.Config.Nodes, id, parsed.Nodes, a.state.Config.NodeLimit())
+	removed := removedNodes(a.state.Config.Nodes, merged)
+	a.state.Mu.Unlock()
+
+	// 订阅中已没有的节点先备份、停止再删除
+	if len(removed) > 0 {
+		a.backupBeforeChange(config.BackupReasonDelete)
+	}
+	statuses := a.engineManager.GetAllStatuses()
+	for _, nodeID := range removed {
+		if es, ok := statuses[nodeID]; ok && es.Status == models.StatusRunning {
+			a.StopNode(nodeID)
+		}
+	}
+
+	// 停止节点期间配置可能已变化，重新合并
+	a.state.Mu.Lock()
+	merged, refresh.Diff = config.MergeSubscription(a.state.Config.Nodes, id, parsed.Nodes, a.state.Config.NodeLimit())
+	limit := a.state.Config.NodeLimit()
+	for _, nodeID := range removedNodes(a.state.Config.Nodes, merged) {
+		a.forgetNode(nodeID)
+	}
+	a.state.Config.Nodes = merged
+	a.state.Mu.Unlock()
+
+	a.recordSubscriptionRefresh(id, refresh)
+	a.logImportLimit(refresh.Diff.Skipped, limit)
+	if refresh.Diff.Empty() {
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("订阅 %s 已刷新，节点没有变化", name))
+	} else {
+		summary := subscriptionSummary(name, refresh.Diff)
+		a.logManager.LogSystem(logger.LevelInfo, summary)
+		a.notify(notify.EventSubscription, summary)
+		a.emitEvent(models.EventConfigChanged, nil)
+	}
+	a.emitEvent(models.EventSubscription, SubscriptionRefreshPayload{SubscriptionID: id, Name: name, Refresh: refresh})
+	return &refresh, nil
+}
+
+// recordSubscriptionRefresh 保存刷新记录（只保留最近 MaxSubscriptionHistory 条）
+func (a *App) recordSubscriptionRefresh(id string, refresh models.SubscriptionRefresh) {
+	a.state.Mu.Lock()
+	for i := range a.state.Config.Subscriptions {
+		s := &a.state.Config.Subscriptions[i]
+		if s.ID != id {
+			continue
+		}
+		s.LastRefresh = refresh.Time
+		s.History = append([]models.SubscriptionRefresh{refresh}, s.History...)
+		if len(s.History) > models.MaxSubscriptionHistory {
+			s.History = s.History[:models.MaxSubscriptionHistory]
+		}
+	}
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+}
+
+func (a *App) findSubscription(id string) *models.Subscription {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	for _, s := range a.state.Config.Subscriptions {
+		if s.ID == id {
+			return &s
+		}
+	}
+	return nil
+}
+
+// forgetNode 清理已删除节点的运行状态、定时任务、生成的配置与测速记录（调用方持有 a.state.Mu）
+func (a *App) forgetNode(id string) {
+	delete(a.state.EngineStatuses, id)
+	a.removeNodeSchedulesLocked(id)
+	go a.configGenerator.CleanupConfigs(id)
+	go a.serverMemory.Forget(id)
+	go a.latencyHistory.Clear(id)
+	go a.pingReportStore.Clear(id)
+}
+
+// removedNodes before 中不在 after 里的节点ID
+func removedNodes(before, after []models.NodeConfig) []string {
+	kept := make(map[string]bool, len(after))
+	for _, n := range after {
+		kept[n.ID] = true
+	}
+	var removed []string
+	for _, n := range before {
+		if !kept[n.ID] {
+			removed = append(removed, n.ID)
+		}
+	}
+	return removed
+}
+
+// subscriptionSummary 变化摘要，如 "订阅 X 已更新：新增 1、删除 0、修改 2 个节点；香港 1: a.com:443 → b.com:443"
+func subscriptionSummary(name string, d models.SubscriptionDiff) string {
+	var details []string
+	for _, c := range d.Modified {
+		if c.OldServer != "" {
+			details = append(details, fmt.Sprintf("%s: %s → %s", c.Name, c.OldServer, c.Server))
+		} else {
+			details = append(details, fmt.Sprintf("%s: %s 已变化", c.Name, strings.Join(c.Fields, ", ")))
+		}
+	}
+	for _, c := range d.Added {
+		details = append(details, "+ "+c.Name)
+	}
+	for _, c := range d.Removed {
+		details = append(details, "- "+c.Name)
+	}
+	if len(details) > subscriptionNotifyLimit {
+		details = append(details[:subscriptionNotifyLimit], "…")
+	}
+
+	summary := fmt.Sprintf("订阅 %s 已更新：新增 %d、删除 %d、修改 %d 个节点", name, len(d.Added), len(d.Removed), len(d.Modified))
+	if len(details) > 0 {
+		summary += "；" + strings.Join(details, "；")
+	}
+	return summary
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/cloudsync"
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+)
+
+// =============================================================================
+// WebDAV 同步（多台设备共享节点与规则组）
+// =============================================================================
+
+const (
+	syncStartDelay = time.Minute      // 启动后首次自动同步前的等待
+	syncTimeout    = 60 * time.Second // 单次同步的超时
+)
+
+// SyncStatus 同步状态
+type SyncStatus struct {
+	Configured   bool  `json:"configured"` // 已填写地址与同步密码
+	AutoSync     bool  `json:"auto_sync"`
+	Revision     int64 `json:"revision"`      // 上次同步时的远端版本号
+	LocalChanged bool  `json:"local_changed"` // 上次同步后本地有修改
+	LastSync     int64 `json:"last_sync"`     // 上次同步时间（Unix 秒）
+}
+
+// SyncResult 一次同步的结果 (sync:complete)
+type SyncResult struct {
+	Action        string `json:"action"`         // none / push / pull / conflict
+	Revision      int64  `json:"revision"`       // 同步后的远端版本号
+	RemoteDevice  string `json:"remote_device"`  // 远端文件的上传设备
+	RemoteUpdated int64  `json:"remote_updated"` // 远端文件的上传时间（Unix 秒）
+	Nodes         int    `json:"nodes"`          // 同步后的节点数
+}
+
+// GetSyncSettings 获取同步设置
+func (a *App) GetSyncSettings() models.SyncSettings {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.Sync
+}
+
+// SetSyncSettings 保存同步设置，修改地址后下次同步视为首次同步
+func (a *App) SetSyncSettings(settings models.SyncSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	settings.URL = strings.TrimSpace(settings.URL)
+	settings.Username = strings.TrimSpace(settings.Username)
+	if settings.URL != "" {
+		if _, err := (&cloudsync.Client{URL: settings.URL}).FileURL(); err != nil {
+			return i18n.Errorf("同步地址无效: %s", settings.URL)
+		}
+		if settings.Passphrase == "" {
+			return i18n.Errorf("请设置同步密码")
+		}
+	}
+
+	a.state.Mu.Lock()
+	old := a.state.Config.Sync
+	if settings.URL == old.URL {
+		settings.Revision, settings.SyncedHash, settings.LastSync = old.Revision, old.SyncedHash, old.LastSync
+	} else {
+		settings.Revision, settings.SyncedHash, settings.LastSync = 0, "", 0
+	}
+	a.state.Config.Sync = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	return nil
+}
+
+// GetSyncStatus 获取同步状态
+func (a *App) GetSyncStatus() SyncStatus {
+	payload := a.syncPayload()
+	a.state.Mu.RLock()
+	s := a.state.Config.Sync
+	a.state.Mu.RUnlock()
+	return SyncStatus{
+		Configured:   s.URL != "" && s.Passphrase != "",
+		AutoSync:     s.AutoSync,
+		Revision:     s.Revision,
+		LocalChanged: syncLocalChanged(s, payload),
+		LastSync:     s.LastSync,
+	}
+}
+
+// SyncNow 立即同步：按版本号决定上传或下载，双方都有修改时返回 conflict 而不做改动（只读模式下不下载）
+func (a *App) SyncNow() (*SyncResult, error) {
+	return a.runSync("")
+}
+
+// ResolveSyncConflict 解决冲突：action 为 push 时以本地覆盖远端，为 pull 时以远端覆盖本地
+func (a *App) ResolveSyncConflict(action string) (*SyncResult, error) {
+	if action != cloudsync.ActionPush && action != cloudsync.ActionPull {
+		return nil, i18n.Errorf("无效的同步操作: %s", action)
+	}
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	return a.runSync(action)
+}
+
+// runSync 执行一次同步，force 非空时跳过版本比较强制按该方向同步
+func (a *App) runSync(force string) (*SyncResult, error) {
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	a.state.Mu.RLock()
+	settings := a.state.Config.Sync
+	a.state.Mu.RUnlock()
+	if settings.URL == "" {
+		return nil, i18n.Errorf("未配置同步地址")
+	}
+	if settings.Passphrase == "" {
+		return nil, i18n.Errorf("请设置同步密码")
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, syncTimeout)
+	defer cancel()
+	client := &cloudsync.Client{
+		URL:      settings.URL,
+		Username: settings.Username,
+		Password: settings.Password,
+		HTTP:     a.egressClient(syncTimeout),
+	}
+
+	doc, etag, err := client.Get(ctx)
+	if err != nil {
+		return nil, i18n.Errorf("同步失败: %w", err)
+	}
+	payload := a.syncPayload()
+	result := &SyncResult{}
+	if doc != nil {
+		result.Revision = doc.Revision
+		result.RemoteDevice = doc.Device
+		result.RemoteUpdated = doc.UpdatedAt.Unix()
+	}
+
+	result.Action = force
+	if force == "" {
+		result.Action = cloudsync.Decide(settings.Revision, result.Revision, syncLocalChanged(settings, payload))
+	}
+
+	switch result.Action {
+	case cloudsync.ActionPush:
+		if doc != nil && etag == "" {
+			// 服务器不返回 ETag，只能按版本号检测冲突
+			etag = "*"
+		}
+		revision := result.Revision + 1
+		newDoc, err := cloudsync.Seal(payload, revision, syncDeviceName(), settings.Passphrase)
+		if err != nil {
+			return nil, i18n.Errorf("同步失败: %w", err)
+		}
+		if err := client.Put(ctx, newDoc, etag); err != nil {
+			if !errors.Is(err, cloudsync.ErrConflict) {
+				return nil, i18n.Errorf("同步失败: %w", err)
+			}
+			// 下载后远端又被其他设备修改
+			result.Action = cloudsync.ActionConflict
+			break
+		}
+		result.Revision = revision
+		a.markSynced(settings.URL, revision, payload.Hash())
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已上传同步文件（版本 %d，%d 个节点）", revision, len(payload.Nodes)))
+
+	case cloudsync.ActionPull:
+		if doc == nil {
+			return nil, i18n.Errorf("远端没有同步文件")
+		}
+		if err := a.checkWritable(); err != nil {
+			return nil, err
+		}
+		remote, err := doc.Open(settings.Passphrase)
+		if err != nil {
+			return nil, i18n.Errorf("同步失败: %w", err)
+		}
+		if err := a.applySyncPayload(remote); err != nil {
+			return nil, err
+		}
+		a.markSynced(settings.URL, doc.Revision, remote.Hash())
+		payload = remote
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已从 %s 下载同步文件（版本 %d，%d 个节点）", doc.Device, doc.Revision, len(remote.Nodes)))
+
+	case cloudsync.ActionNone:
+		a.markSynced(settings.URL, result.Revision, payload.Hash())
+	}
+
+	if result.Action == cloudsync.ActionConflict {
+		a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("同步冲突：本机与 %s 在上次同步后都修改了配置，请选择保留哪一方", result.RemoteDevice))
+	}
+	result.Nodes = len(payload.Nodes)
+	a.emitEvent(models.EventSyncComplete, result)
+	return result, nil
+}
+
+// syncPayload 当前的节点与规则组
+func (a *App) syncPayload() *cloudsync.Payload {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return &cloudsync.Payload{
+		Nodes:      append([]models.NodeConfig(nil), a.state.Config.Nodes...),
+		RuleGroups: append([]models.RuleGroup(nil), a.state.Config.RuleGroups...),
+	}
+}
+
+// syncLocalChanged 上次同步后本地是否有修改（从未同步且没有节点的新设备视为未修改，直接下载）
+func syncLocalChanged(s models.SyncSettings, p *cloudsync.Payload) bool {
+	if s.SyncedHash == "" && len(p.Nodes) == 0 {
+		return false
+	}
+	return p.Hash() != s.SyncedHash
+}
+
+// applySyncPayload 以下载的内容替换本地节点与规则组（替换前备份，已删除的节点先停止再清理）
+// 远端节点数超过本机上限时不做修改
+func (a *App) applySyncPayload(p *cloudsync.Payload) error {
+	a.state.Mu.RLock()
+	limit := a.state.Config.NodeLimit()
+	a.state.Mu.RUnlock()
+	if len(p.Nodes) > limit {
+		return i18n.Errorf("同步文件中有 %d 个节点，超过数量上限 %d（可在设置中提高上限）", len(p.Nodes), limit)
+	}
+
+	a.backupBeforeChange(config.BackupReasonSync)
+
+	keep := make(map[string]bool, len(p.Nodes))
+	for _, n := range p.Nodes {
+		keep[n.ID] = true
+	}
+	for id, st := range a.engineManager.GetAllStatuses() {
+		if !keep[id] && st.Status == models.StatusRunning {
+			a.StopNode(id)
+		}
+	}
+
+	// 其他设备（或旧版本）上传的节点可能带有冲突的IPv6开关
+	for i := range p.Nodes {
+		models.NormalizeIPv6Config(&p.Nodes[i])
+	}
+
+	a.state.Mu.Lock()
+	for _, id := range removedNodes(a.state.Config.Nodes, p.Nodes) {
+		a.forgetNode(id)
+	}
+	a.state.Config.Nodes = p.Nodes
+	a.state.Config.RuleGroups = p.RuleGroups
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	a.emitEvent(models.EventConfigChanged, nil)
+	return nil
+}
+
+// markSynced 记录同步后的版本号与摘要（同步期间修改了地址时不记录）
+func (a *App) markSynced(url string, revision int64, hash string) {
+	a.state.Mu.Lock()
+	if a.state.Config.Sync.URL != url {
+		a.state.Mu.Unlock()
+		return
+	}
+	a.state.Config.Sync.Revision = revision
+	a.state.Config.Sync.SyncedHash = hash
+	a.state.Config.Sync.LastSync = time.Now().Unix()
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+}
+
+// startSyncLoop 启动自动同步循环（启动一分钟后及之后每隔 SyncInterval 同步一次）
+func (a *App) startSyncLoop(ctx context.Context) {
+	go func() {
+		wait := syncStartDelay
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			wait = models.SyncInterval
+
+			a.state.Mu.RLock()
+			s := a.state.Config.Sync
+			locked := a.state.Config.Kiosk.Enabled
+			a.state.Mu.RUnlock()
+			if !s.AutoSync || s.URL == "" || s.Passphrase == "" || locked {
+				continue // 只读模式下暂停自动同步
+			}
+			result, err := a.runSync("")
+			if err != nil {
+				a.logManager.LogSystem(logger.LevelWarn, fmt.Sprintf("自动同步失败: %v", err))
+				continue
+			}
+			if result.Action == cloudsync.ActionConflict {
+				a.notify(notify.EventSystem, "同步冲突，请在设置中选择保留本机或远端的配置")
+			}
+		}
+	}()
+}
+
+// syncDeviceName 上传时记录的设备名
+func syncDeviceName() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/syschange"
+)
+
+// =============================================================================
+// 系统修改（DNS / TUN 路由）与预览模式
+// =============================================================================
+
+// SetSystemDNS 设置网卡的系统 DNS（预览模式下只返回将执行的命令）
+func (a *App) SetSystemDNS(interfaceName string, ipv4DNS, ipv6DNS []string) (*syschange.Plan, error) {
+	plan, err := a.dnsManager.PlanSystemDNS(interfaceName, ipv4DNS, ipv6DNS)
+	if err != nil {
+		return nil, err
+	}
+	return a.applySystemChange(plan, func() error {
+		undo, err := a.dnsManager.PlanResetSystemDNS(interfaceName)
+		if err != nil {
+			return err
+		}
+		a.journalRecord(syschange.KindDNS, journalKeyDNS(interfaceName), plan.Title, undo, nil)
+		if err := plan.Run(); err != nil {
+			return err
+		}
+		a.rememberSystemChange(journalKeyDNS(interfaceName), plan)
+		return nil
+	})
+}
+
+// ResetSystemDNS 将网卡 DNS 恢复为自动获取（预览模式下只返回将执行的命令）
+func (a *App) ResetSystemDNS(interfaceName string) (*syschange.Plan, error) {
+	plan, err := a.dnsManager.PlanResetSystemDNS(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	return a.applySystemChange(plan, func() error {
+		if err := a.dnsManager.ResetSystemDNS(interfaceName); err != nil {
+			return err
+		}
+		a.journalResolve(journalKeyDNS(interfaceName))
+		a.forgetSystemChange(journalKeyDNS(interfaceName))
+		return nil
+	})
+}
+
+// SetupTUNRoutes 将默认路由指向 TUN 网关，excludeIPs 保持直连（预览模式下只返回将执行的命令）
+func (a *App) SetupTUNRoutes(tunGateway string, excludeIPs []string) (*syschange.Plan, error) {
+	plan, err := a.tunManager.PlanDefaultRoute(tunGateway, excludeIPs)
+	if err != nil {
+		return nil, err
+	}
+	return a.applySystemChange(plan, func() error {
+		gateway, err := a.tunManager.GetDefaultGateway()
+		if err != nil {
+			return err
+		}
+		a.journalRecord(syschange.KindRoute, journalKeyRoute, plan.Title, a.tunManager.PlanUndoDefaultRoute(gateway, excludeIPs), nil)
+		if err := plan.Run(); err != nil {
+			return err
+		}
+		a.rememberSystemChange(journalKeyRoute, plan)
+		return nil
+	})
+}
+
+// RestoreTUNRoutes 恢复原默认路由（预览模式下只返回将执行的命令）
+func (a *App) RestoreTUNRoutes(originalGateway string) (*syschange.Plan, error) {
+	plan := a.tunManager.PlanRestoreRoute(originalGateway)
+	return a.applySystemChange(plan, func() error {
+		if err := plan.Run(); err != nil {
+			return err
+		}
+		a.journalResolve(journalKeyRoute)
+		a.forgetSystemChange(journalKeyRoute)
+		return nil
+	})
+}
+
+// SetPreviewSystemChanges 开启或关闭预览模式（开启后修改 DNS、路由、防火墙时只返回将执行的命令）
+func (a *App) SetPreviewSystemChanges(enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	a.state.Mu.Lock()
+	a.state.Config.PreviewSystemChanges = enabled
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	a.emitEvent(models.EventSettingsChanged, nil)
+	return nil
+}
+
+// previewSystemChanges 是否开启预览模式
+func (a *App) previewSystemChanges() bool {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.PreviewSystemChanges
+}
+
+// applySystemChange 预览模式下标记计划为未执行并返回，否则执行
+func (a *App) applySystemChange(plan *syschange.Plan, run func() error) (*syschange.Plan, error) {
+	if a.previewSystemChanges() {
+		plan.DryRun = true
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("预览模式: %s（%d 条命令，未执行）", plan.Title, len(plan.Commands)))
+		return plan, nil
+	}
+
+	if err := run(); err != nil {
+		a.logManager.LogSystem(logger.LevelError, fmt.Sprintf("%s: %v", plan.Title, err))
+		return nil, err
+	}
+	a.logManager.LogSystem(logger.LevelInfo, plan.Title)
+	return plan, nil
+}
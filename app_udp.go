@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// UDP 转发检测
+// =============================================================================
+
+// udpProbeTimeout 单次 UDP 转发测试的超时
+const udpProbeTimeout = 5 * time.Second
+
+// GetUDPStats 获取节点的 UDP 会话统计及最近一次测试结果
+func (a *App) GetUDPStats(nodeID string) models.UDPStats {
+	stats := a.engineManager.GetUDPStats(nodeID)
+	a.udpProbeMu.Lock()
+	stats.Probe = a.udpProbes[nodeID]
+	a.udpProbeMu.Unlock()
+	return stats
+}
+
+// TestUDP 经节点的本地 SOCKS5 入站发送 UDP 请求，验证节点能否转发 UDP（节点未运行时临时启动）
+func (a *App) TestUDP(nodeID string) (*models.UDPProbeResult, error) {
+	node := a.state.GetNode(nodeID)
+	if node == nil {
+		return nil, i18n.Errorf("节点不存在")
+	}
+
+	release, err := a.ensureNodeRunning(a.ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result := engine.ProbeUDP(loopbackListen(node.Listen), engine.UDPProbeTarget, udpProbeTimeout)
+
+	a.udpProbeMu.Lock()
+	if a.udpProbes == nil {
+		a.udpProbes = make(map[string]*models.UDPProbeResult)
+	}
+	a.udpProbes[node.ID] = result
+	a.udpProbeMu.Unlock()
+
+	if result.OK {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelInfo, logger.CategoryPing,
+			fmt.Sprintf("UDP 转发正常 (%s, %d ms)", result.Target, result.RTTMs))
+	} else {
+		a.logManager.LogNode(node.ID, node.Name, logger.LevelWarn, logger.CategoryPing,
+			fmt.Sprintf("UDP 转发测试失败 (%s): %s", result.Stage, result.Error))
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/notify"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 界面数据目录（webview_data）维护
+// =============================================================================
+
+// webviewCheckInterval 检查界面数据目录大小的间隔
+const webviewCheckInterval = 24 * time.Hour
+
+// WebviewDataInfo 界面数据目录状态
+type WebviewDataInfo struct {
+	system.WebviewDataUsage
+	Settings   models.WebviewDataSettings `json:"settings"`
+	LimitBytes int64                      `json:"limit_bytes"`
+	NextPath   string                     `json:"next_path,omitempty"` // 修改了存放位置，重启后使用的目录
+}
+
+// prepareWebviewData 按配置确定界面数据目录，并在界面打开前完成目录迁移和待清理的缓存
+func prepareWebviewData(dataDir string) *system.WebviewPrepareResult {
+	inUserDir := false
+	manager := config.NewManager(dataDir)
+	if path, err := manager.ResolveConfigPath(""); err == nil {
+		if cfg, err := manager.ReadConfigFile(path); err == nil {
+			inUserDir = cfg.WebviewData.InUserDir
+		}
+	}
+	return system.PrepareWebviewData(dataDir, inUserDir)
+}
+
+// webviewDir 当前使用的界面数据目录
+func (a *App) webviewDir() string {
+	if a.webview == nil {
+		return system.WebviewDataDir(a.state.DataDir, false)
+	}
+	return a.webview.Dir
+}
+
+// GetWebviewDataInfo 获取界面数据目录的位置与占用
+func (a *App) GetWebviewDataInfo() WebviewDataInfo {
+	a.state.Mu.RLock()
+	settings := a.state.Config.WebviewData
+	a.state.Mu.RUnlock()
+
+	info := WebviewDataInfo{
+		WebviewDataUsage: system.GetWebviewDataUsage(a.webviewDir()),
+		Settings:         settings,
+		LimitBytes:       settings.SizeLimit(),
+	}
+	if next := system.WebviewDataDir(a.state.DataDir, settings.InUserDir); next != info.Path {
+		info.NextPath = next
+	}
+	return info
+}
+
+// SetWebviewDataSettings 保存界面数据目录设置，存放位置在重启后迁移
+func (a *App) SetWebviewDataSettings(settings models.WebviewDataSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if settings.MaxSizeMB < 0 {
+		return i18n.Errorf("提醒大小不能为负数")
+	}
+
+	a.state.Mu.Lock()
+	moved := a.state.Config.WebviewData.InUserDir != settings.InUserDir
+	a.state.Config.WebviewData = settings
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+
+	if moved {
+		a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("界面数据目录将在重启后迁移到 %s",
+			system.WebviewDataDir(a.state.DataDir, settings.InUserDir)))
+	}
+	return nil
+}
+
+// ClearWebviewCache 清理界面缓存（保留界面设置等本地存储），缓存被界面占用，清理在下次启动时进行
+func (a *App) ClearWebviewCache() error {
+	if err := system.ScheduleWebviewClean(a.webviewDir()); err != nil {
+		return i18n.Errorf("标记清理界面缓存失败: %v", err)
+	}
+	a.logManager.LogSystem(logger.LevelInfo, "界面缓存将在下次启动时清理")
+	return nil
+}
+
+// startWebviewWatchdog 记录启动时的整理结果，之后定期检查目录大小
+func (a *App) startWebviewWatchdog() {
+	if r := a.webview; r != nil {
+		if r.MovedFrom != "" {
+			a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("界面数据目录已从 %s 迁移到 %s", r.MovedFrom, r.Dir))
+		}
+		if r.FreedBytes > 0 {
+			a.logManager.LogSystem(logger.LevelInfo, fmt.Sprintf("已清理界面缓存，释放 %.1f MB", float64(r.FreedBytes)/(1<<20)))
+		}
+		if r.Err != nil {
+			a.logManager.LogSystem(logger.LevelWarn, r.Err.Error())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelMu.Lock()
+	a.cancelFuncs = append(a.cancelFuncs, cancel)
+	a.cancelMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(webviewCheckInterval)
+		defer ticker.Stop()
+		for {
+			a.checkWebviewData()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// checkWebviewData 目录超过提醒大小时提醒，开启自动清理时标记下次启动清理缓存
+func (a *App) checkWebviewData() {
+	info := a.GetWebviewDataInfo()
+	if info.TotalBytes <= info.LimitBytes {
+		return
+	}
+
+	msg := fmt.Sprintf("界面数据目录已达 %.1f MB（缓存 %.1f MB）", float64(info.TotalBytes)/(1<<20), float64(info.CacheBytes)/(1<<20))
+	if info.Settings.AutoClean && info.CacheBytes > 0 && !info.CleanPending {
+		if err := system.ScheduleWebviewClean(info.Path); err == nil {
+			info.CleanPending = true
+			msg += "，将在下次启动时清理缓存"
+		}
+	}
+	a.logManager.LogSystem(logger.LevelWarn, msg)
+	a.notify(notify.EventSystem, msg)
+	a.emitEvent(models.EventWebviewData, info)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 自定义 Xray 配置模板
+// =============================================================================
+
+// GetXrayTemplate 获取全局 Xray 配置模板（为空表示不使用模板）
+func (a *App) GetXrayTemplate() string {
+	a.state.Mu.RLock()
+	defer a.state.Mu.RUnlock()
+	return a.state.Config.XrayTemplate
+}
+
+// SetXrayTemplate 保存全局 Xray 配置模板，节点重新启动后生效
+func (a *App) SetXrayTemplate(template string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if _, err := dns.ParseXrayTemplate(template); err != nil {
+		return err
+	}
+	if strings.TrimSpace(template) == "" {
+		template = ""
+	}
+
+	a.state.Mu.Lock()
+	a.state.Config.XrayTemplate = template
+	a.state.Mu.Unlock()
+	go a.saveConfig()
+	return nil
+}
+
+// xrayTemplateNode 生成配置时使用的模板：节点未设置时使用全局模板
+func (a *App) xrayTemplateNode(node *models.NodeConfig) *models.NodeConfig {
+	if strings.TrimSpace(node.XrayTemplate) != "" {
+		return node
+	}
+	a.state.Mu.RLock()
+	global := a.state.Config.XrayTemplate
+	a.state.Mu.RUnlock()
+	if global == "" {
+		return node
+	}
+
+	templated := *node
+	templated.XrayTemplate = global
+	return &templated
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"xlink-wails/internal/api"
+	"xlink-wails/internal/command"
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/system"
+)
+
+// =============================================================================
+// 无界面命令行 (--validate)
+// =============================================================================
+
+// 校验命令退出码
+const (
+	exitValid      = 0 // 配置有效（可能有警告）
+	exitInvalid    = 1 // 配置存在错误
+	exitLoadFailed = 2 // 配置无法读取或解析
+)
+
+// parseValidateArg 解析 --validate [config.json] 参数
+// 未提供路径时校验用户数据目录下的默认配置
+func parseValidateArg(args []string) (string, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], "-")
+		if arg == args[i] {
+			continue
+		}
+		if arg == "validate" {
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				return args[i+1], true
+			}
+			return "", true
+		}
+		if strings.HasPrefix(arg, "validate=") {
+			return strings.Trim(strings.TrimPrefix(arg, "validate="), `"`), true
+		}
+	}
+	return "", false
+}
+
+// runValidate 校验配置并将 JSON 报告写到标准输出，返回进程退出码
+func runValidate(dataDir, path string) int {
+	system.AttachParentConsole()
+
+	manager := config.NewManager(dataDir)
+	report := &config.ValidationReport{Path: path, Issues: []config.ValidationIssue{}}
+
+	resolved, err := manager.ResolveConfigPath(path)
+	if err == nil {
+		report.Path = resolved
+		var cfg *models.AppConfig
+		if cfg, err = manager.ReadConfigFile(resolved); err == nil {
+			result := config.ValidateConfig(cfg)
+			result.Path = resolved
+			report = result
+		}
+	}
+
+	code := exitValid
+	if err != nil {
+		report.Errors = 1
+		report.Issues = append(report.Issues, config.ValidationIssue{
+			Level:   config.IssueError,
+			Field:   "file",
+			Message: err.Error(),
+		})
+		code = exitLoadFailed
+	} else if !report.Valid {
+		code = exitInvalid
+	}
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+	return code
+}
+
+// =============================================================================
+// 无界面命令行 (-status)
+// =============================================================================
+
+// 状态查询退出码
+const (
+	exitStatusOK     = 0 // 已输出状态
+	exitStatusFailed = 1 // 程序未运行、控制接口不可用或节点不存在
+)
+
+// parseStatusArg 解析 -status[=<节点>] 参数，返回要查询的节点（为空时查询全部）
+// 与其他控制命令同时使用时返回错误
+func parseStatusArg(args []string) (nodeRef string, ok bool, err error) {
+	cmds := command.ParseArgs(args, command.SourceCLI)
+	for _, cmd := range cmds {
+		if cmd.Name == command.CmdStatus {
+			nodeRef, ok = cmd.NodeRef, true
+		}
+	}
+	if ok && len(cmds) > 1 {
+		return "", true, fmt.Errorf("-status 不能与其他控制命令同时使用")
+	}
+	return nodeRef, ok, nil
+}
+
+// runStatus 经本地控制接口查询正在运行的程序（或后台服务）中的节点状态，
+// 将 JSON 结果写到标准输出，出错时写到标准错误，返回进程退出码
+func runStatus(dataDir, nodeRef string, argErr error) int {
+	system.AttachParentConsole()
+
+	fail := func(err error) int {
+		fmt.Fprintln(os.Stderr, err)
+		return exitStatusFailed
+	}
+	if argErr != nil {
+		return fail(argErr)
+	}
+
+	manager := config.NewManager(dataDir)
+	manager.SetReadOnly(true)
+	cfg, err := manager.Load()
+	if err != nil {
+		return fail(fmt.Errorf("读取配置失败: %w", err))
+	}
+	// 后台服务始终开启控制接口，令牌由界面在启动服务前写入配置文件
+	if !cfg.APIEnabled && !system.QueryService().Running() {
+		return fail(fmt.Errorf("本地控制接口未开启，无法查询运行中的程序"))
+	}
+
+	statuses, err := api.NewClient(cfg.APIListen, cfg.APIToken).Status()
+	if err != nil {
+		return fail(fmt.Errorf("程序未运行或控制接口无响应: %w", err))
+	}
+
+	var out interface{} = statuses
+	if nodeRef != "" {
+		var node *models.NodeConfig
+		for i := range cfg.Nodes {
+			if cfg.Nodes[i].ID == nodeRef || (node == nil && cfg.Nodes[i].Name == nodeRef) {
+				node = &cfg.Nodes[i]
+			}
+		}
+		if node == nil {
+			return fail(fmt.Errorf("节点不存在: %s", nodeRef))
+		}
+		status := models.StatusStopped
+		if st, ok := statuses[node.ID]; ok {
+			status = st.Status
+		}
+		out = map[string]string{"node_id": node.ID, "status": status}
+	}
+
+	data, _ := json.MarshalIndent(out, "", "  ")
+	fmt.Fprintln(os.Stdout, string(data))
+	return exitStatusOK
+}
@@ -0,0 +1,86 @@
+// 数值格式化：后端返回原始数值和单位提示（Metric），按界面语言统一格式化
+import { computed } from 'vue'
+import { useAppStore } from '@/stores/app'
+import type { Metric } from '@/types'
+
+const BYTE_UNITS = ['B', 'KB', 'MB', 'GB', 'TB']
+
+function isChinese(locale: string): boolean {
+  return locale.toLowerCase().startsWith('zh')
+}
+
+function formatNumber(value: number, locale: string, digits: number): string {
+  return new Intl.NumberFormat(locale, { maximumFractionDigits: digits }).format(value)
+}
+
+// 字节数，按 1024 换算为 KB/MB/GB
+export function formatBytes(bytes: number, locale = 'zh-CN'): string {
+  let value = bytes
+  let unit = 0
+  while (Math.abs(value) >= 1024 && unit < BYTE_UNITS.length - 1) {
+    value /= 1024
+    unit++
+  }
+  return `${formatNumber(value, locale, unit === 0 ? 0 : 1)} ${BYTE_UNITS[unit]}`
+}
+
+// 速率（字节/秒）
+export function formatSpeed(bytesPerSec: number, locale = 'zh-CN'): string {
+  return `${formatBytes(bytesPerSec, locale)}/s`
+}
+
+// 时长（秒），中文为 "1小时2分"，其他语言为 "1h 2m"
+export function formatDuration(seconds: number, locale = 'zh-CN'): string {
+  const zh = isChinese(locale)
+  const units: [number, string, string][] = [
+    [86400, '天', 'd'],
+    [3600, '小时', 'h'],
+    [60, '分', 'm'],
+    [1, '秒', 's'],
+  ]
+  const parts: string[] = []
+  let rest = Math.max(0, Math.floor(seconds))
+  for (const [size, zhUnit, enUnit] of units) {
+    if (rest >= size || (size === 1 && parts.length === 0)) {
+      const n = Math.floor(rest / size)
+      rest -= n * size
+      parts.push(`${formatNumber(n, locale, 0)}${zh ? zhUnit : enUnit}`)
+    }
+    if (parts.length === 2) break
+  }
+  return parts.join(zh ? '' : ' ')
+}
+
+// 毫秒
+export function formatMillis(ms: number, locale = 'zh-CN'): string {
+  return `${formatNumber(ms, locale, 0)} ms`
+}
+
+// 按单位提示格式化 Metric
+export function formatMetric(metric: Metric, locale = 'zh-CN'): string {
+  switch (metric.unit) {
+    case 'bytes':
+      return formatBytes(metric.value, locale)
+    case 'bytes/s':
+      return formatSpeed(metric.value, locale)
+    case 'ms':
+      return formatMillis(metric.value, locale)
+    case 's':
+      return formatDuration(metric.value, locale)
+    default:
+      return formatNumber(metric.value, locale, 0)
+  }
+}
+
+// 使用当前界面语言的格式化函数
+export function useFormat() {
+  const appStore = useAppStore()
+  const locale = computed(() => appStore.language)
+  return {
+    bytes: (n: number) => formatBytes(n, locale.value),
+    speed: (n: number) => formatSpeed(n, locale.value),
+    duration: (s: number) => formatDuration(s, locale.value),
+    millis: (ms: number) => formatMillis(ms, locale.value),
+    metric: (m: Metric) => formatMetric(m, locale.value),
+  }
+}
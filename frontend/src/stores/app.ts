@@ -1,5 +1,6 @@
 import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
+import type { AppNotification, KioskStatus } from '@/types'
 
 export const useAppStore = defineStore('app', () => {
   // 状态
@@ -7,6 +8,10 @@ export const useAppStore = defineStore('app', () => {
   const language = ref('zh-CN')
   const isLoading = ref(false)
   const toasts = ref<{ id: number; type: string; message: string }[]>([])
+  const ipv6Status = ref<{ ipv6_connectivity: boolean; ipv6_addresses: string[] } | null>(null)
+  const notifications = ref<AppNotification[]>([]) // 通知中心（最新的在前）
+  const unreadNotifications = ref(0)
+  const kiosk = ref<KioskStatus>({ enabled: false, has_password: false }) // 只读模式
   
   let toastId = 0
 
@@ -48,6 +53,49 @@ export const useAppStore = defineStore('app', () => {
     isLoading.value = loading
   }
 
+  function setIPv6Status(status: any) {
+    const wasAvailable = ipv6Status.value?.ipv6_connectivity
+    ipv6Status.value = status
+    if (wasAvailable && !status.ipv6_connectivity) {
+      showToast('warning', 'IPv6 连接已断开，节点已临时切换为仅IPv4', 5000)
+    }
+  }
+
+  async function fetchNotifications() {
+    try {
+      notifications.value = await (window as any).go.main.App.GetNotifications()
+    } catch (e) {
+      console.error('Failed to fetch notifications:', e)
+    }
+  }
+
+  function addNotification(n: AppNotification) {
+    notifications.value.unshift(n)
+    unreadNotifications.value++
+  }
+
+  function markNotificationsRead() {
+    unreadNotifications.value = 0
+  }
+
+  async function clearNotifications() {
+    try {
+      await (window as any).go.main.App.ClearNotifications()
+      notifications.value = []
+      unreadNotifications.value = 0
+    } catch (e) {
+      console.error('Failed to clear notifications:', e)
+    }
+  }
+
+  async function fetchKioskStatus() {
+    try {
+      kiosk.value = await (window as any).go.main.App.GetKioskStatus()
+    } catch (e) {
+      console.error('Failed to fetch kiosk status:', e)
+    }
+  }
+
   // 初始化主题
   applyTheme()
 
@@ -63,9 +111,19 @@ export const useAppStore = defineStore('app', () => {
     language,
     isLoading,
     toasts,
+    ipv6Status,
+    notifications,
+    unreadNotifications,
+    kiosk,
     isDark,
     setTheme,
     showToast,
-    setLoading
+    setLoading,
+    setIPv6Status,
+    fetchNotifications,
+    addNotification,
+    markNotificationsRead,
+    clearNotifications,
+    fetchKioskStatus
   }
 })
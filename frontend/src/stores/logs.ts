@@ -1,27 +1,36 @@
 import { defineStore } from 'pinia'
-import { ref, computed } from 'vue'
-import type { LogEntry } from '@/types'
+import { ref, computed, watch } from 'vue'
+import type { LogBatch, LogCategory, LogEntry, LogFilter } from '@/types'
 
 declare const window: {
   go: {
     main: {
       App: {
-        GetLogs(limit: number): Promise<LogEntry[]>
+        QueryLogs(filter: LogFilter): Promise<LogEntry[]>
+        GetLogCategories(): Promise<LogCategory[]>
+        SubscribeLogs(filter: LogFilter): Promise<string>
+        UpdateLogSubscription(id: string, filter: LogFilter): Promise<void>
+        UnsubscribeLogs(id: string): Promise<void>
         ClearLogs(): Promise<void>
         ExportLogs(format: string): Promise<string>
+        GetDebugLog(): Promise<boolean>
+        SetDebugLog(enabled: boolean): Promise<void>
       }
     }
   }
-  runtime: {
-    EventsOn(event: string, callback: (data: any) => void): () => void
-  }
 }
 
+// 过滤条件变化后等待输入停止再同步到后端
+const FILTER_DEBOUNCE_MS = 300
+
 export const useLogsStore = defineStore('logs', () => {
   // 状态
   const logs = ref<LogEntry[]>([])
   const maxLogs = ref(1000)
   const autoScroll = ref(true)
+  const dropped = ref(0) // 推送积压过多被后端丢弃的条目数
+  const debugMode = ref(false) // 调试模式：后端不折叠重复日志
+  const allCategories = ref<LogCategory[]>([])
   const filter = ref({
     level: '' as string,
     category: '' as string,
@@ -29,56 +38,105 @@ export const useLogsStore = defineStore('logs', () => {
     search: ''
   })
 
+  let subscriptionId = ''
+  let filterTimer: ReturnType<typeof setTimeout> | null = null
+
   // 计算属性
-  const filteredLogs = computed(() => {
-    return logs.value.filter(log => {
-      if (filter.value.level && log.level !== filter.value.level) {
-        return false
-      }
-      if (filter.value.category && log.category !== filter.value.category) {
-        return false
-      }
-      if (filter.value.nodeId && log.node_id !== filter.value.nodeId) {
-        return false
-      }
-      if (filter.value.search) {
-        const search = filter.value.search.toLowerCase()
-        return log.message.toLowerCase().includes(search) ||
-               log.category.toLowerCase().includes(search) ||
-               log.node_name.toLowerCase().includes(search)
-      }
-      return true
-    })
-  })
+  // 过滤在后端完成，这里的日志已经是匹配结果
+  const filteredLogs = computed(() => logs.value)
 
+  // 全部类别 (标识 -> 显示名称)，后端未返回时退回到已出现的类别
   const categories = computed(() => {
-    const cats = new Set<string>()
-    logs.value.forEach(log => cats.add(log.category))
-    return Array.from(cats)
+    if (allCategories.value.length > 0) return allCategories.value
+    const cats = new Map<string, string>()
+    logs.value.forEach(log => cats.set(log.category, log.category_name || log.category))
+    return Array.from(cats, ([id, name]) => ({ id, name }))
   })
 
+  // 转换为后端的过滤条件
+  function backendFilter(limit?: number): LogFilter {
+    const f: LogFilter = {}
+    if (filter.value.nodeId) f.node_id = filter.value.nodeId
+    if (filter.value.level) f.levels = [filter.value.level]
+    if (filter.value.category) f.categories = [filter.value.category]
+    if (filter.value.search) f.search = filter.value.search
+    if (limit) f.limit = limit
+    return f
+  }
+
   // 方法
-  function addLog(log: LogEntry) {
-    logs.value.push(log)
-    
+  function addLogs(entries: LogEntry[]) {
+    logs.value.push(...entries)
+
     // 限制日志数量
     if (logs.value.length > maxLogs.value) {
       logs.value = logs.value.slice(-maxLogs.value)
     }
   }
 
+  // 处理后端批量推送（忽略其他订阅的批次）
+  function applyBatch(batch: LogBatch) {
+    if (batch.subscription_id !== subscriptionId) return
+    if (batch.dropped) dropped.value += batch.dropped
+    addLogs(batch.entries)
+  }
+
   async function fetchLogs(limit = 500) {
     try {
-      logs.value = await window.go.main.App.GetLogs(limit)
+      logs.value = await window.go.main.App.QueryLogs(backendFilter(limit))
+      dropped.value = 0
     } catch (e) {
       console.error('Failed to fetch logs:', e)
     }
   }
 
+  async function fetchCategories() {
+    try {
+      allCategories.value = await window.go.main.App.GetLogCategories()
+    } catch (e) {
+      console.error('Failed to fetch log categories:', e)
+    }
+  }
+
+  // 订阅实时日志（重复调用时替换旧订阅）
+  async function subscribe() {
+    try {
+      if (subscriptionId) await window.go.main.App.UnsubscribeLogs(subscriptionId)
+      subscriptionId = await window.go.main.App.SubscribeLogs(backendFilter())
+    } catch (e) {
+      console.error('Failed to subscribe logs:', e)
+    }
+  }
+
+  async function unsubscribe() {
+    if (!subscriptionId) return
+    const id = subscriptionId
+    subscriptionId = ''
+    try {
+      await window.go.main.App.UnsubscribeLogs(id)
+    } catch (e) {
+      console.error('Failed to unsubscribe logs:', e)
+    }
+  }
+
+  // 过滤条件变化：更新订阅并按新条件重新加载历史日志
+  async function applyFilter() {
+    try {
+      if (subscriptionId) {
+        await window.go.main.App.UpdateLogSubscription(subscriptionId, backendFilter())
+      }
+    } catch (e) {
+      // 订阅已失效（如后端重启），重新订阅
+      await subscribe()
+    }
+    await fetchLogs()
+  }
+
   async function clearLogs() {
     try {
       await window.go.main.App.ClearLogs()
       logs.value = []
+      dropped.value = 0
     } catch (e) {
       console.error('Failed to clear logs:', e)
     }
@@ -93,6 +151,19 @@ export const useLogsStore = defineStore('logs', () => {
     }
   }
 
+  async function fetchDebugMode() {
+    try {
+      debugMode.value = await window.go.main.App.GetDebugLog()
+    } catch (e) {
+      console.error('Failed to fetch debug mode:', e)
+    }
+  }
+
+  async function setDebugMode(enabled: boolean) {
+    await window.go.main.App.SetDebugLog(enabled)
+    debugMode.value = enabled
+  }
+
   function setFilter(key: keyof typeof filter.value, value: string) {
     filter.value[key] = value
   }
@@ -106,28 +177,31 @@ export const useLogsStore = defineStore('logs', () => {
     }
   }
 
-  // 初始化事件监听
-  function initEventListener() {
-    if (typeof window.runtime?.EventsOn === 'function') {
-      window.runtime.EventsOn('log:append', (data: LogEntry) => {
-        addLog(data)
-      })
-    }
-  }
+  watch(filter, () => {
+    if (filterTimer) clearTimeout(filterTimer)
+    filterTimer = setTimeout(applyFilter, FILTER_DEBOUNCE_MS)
+  }, { deep: true })
 
   return {
     logs,
     maxLogs,
     autoScroll,
+    dropped,
+    debugMode,
     filter,
     filteredLogs,
     categories,
-    addLog,
+    addLogs,
+    applyBatch,
     fetchLogs,
+    fetchCategories,
+    subscribe,
+    unsubscribe,
     clearLogs,
     exportLogs,
+    fetchDebugMode,
+    setDebugMode,
     setFilter,
-    clearFilter,
-    initEventListener
+    clearFilter
   }
 })
@@ -1,6 +1,6 @@
 import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
-import type { NodeConfig, EngineStatus } from '@/types'
+import type { NodeConfig, NodeQuery, NodePage, LiveStatus, EngineStatus, TrafficStats, AutoSelectState, RuleGroup, Subscription, SubscriptionRefresh, URLImportResult, QRImportResult } from '@/types'
 
 // Wails 绑定声明
 declare const window: any
@@ -9,9 +9,26 @@ export const useNodesStore = defineStore('nodes', () => {
   const nodes = ref<NodeConfig[]>([])
   const currentNodeId = ref<string | null>(null)
   const statuses = ref<Record<string, EngineStatus>>({})
+  const traffic = ref<Record<string, TrafficStats>>({})
+  const autoSelect = ref<AutoSelectState | null>(null)
+  const ruleGroups = ref<RuleGroup[]>([])
+  const subscriptions = ref<Subscription[]>([])
   const isLoading = ref(false)
   const error = ref<string | null>(null)
 
+  // 节点列表筛选（由后端执行），filteredIds 为 null 时显示全部节点
+  const nodeQuery = ref<NodeQuery>({ search: '', status: '', rule_group_id: '', group: '', tag: '', sort_by: '', desc: false, offset: 0, limit: 0 })
+  const filteredIds = ref<string[] | null>(null)
+
+  // 活动节点实时状态（live:status）
+  const liveStatus = ref<LiveStatus | null>(null)
+
+  const visibleNodes = computed(() => {
+    if (filteredIds.value === null) return nodes.value
+    const byId = new Map(nodes.value.map(n => [n.id, n]))
+    return filteredIds.value.map(id => byId.get(id)).filter((n): n is NodeConfig => !!n)
+  })
+
   const currentNode = computed(() => {
     if (!currentNodeId.value) return null
     return nodes.value.find(n => n.id === currentNodeId.value) || null
@@ -29,8 +46,9 @@ export const useNodesStore = defineStore('nodes', () => {
   async function fetchNodes() {
     isLoading.value = true
     try {
-      nodes.value = await window.go.main.App.GetNodes()
-      await fetchStatuses()
+      const page: NodePage = await window.go.main.App.GetNodes({})
+      nodes.value = page.nodes
+      await Promise.all([fetchStatuses(), refreshNodeQuery()])
       if (!currentNodeId.value && nodes.value.length > 0) {
         currentNodeId.value = nodes.value[0].id
       }
@@ -71,6 +89,8 @@ export const useNodesStore = defineStore('nodes', () => {
         // 这样做最安全，不会触发不必要的 Vue 响应式重绘。
         Object.assign(nodes.value[index], node)
     }
+    // 名称、标签等变化后重新筛选
+    await refreshNodeQuery()
   }
 
   async function deleteNode(id: string) {
@@ -106,6 +126,19 @@ export const useNodesStore = defineStore('nodes', () => {
     await fetchStatuses()
   }
 
+  // 按当前筛选条件（分组、标签等）批量启动 / 停止，返回操作的节点数
+  async function startFilteredNodes(): Promise<number> {
+    const count: number = await window.go.main.App.StartNodesByQuery(nodeQuery.value)
+    await fetchStatuses()
+    return count
+  }
+
+  async function stopFilteredNodes(): Promise<number> {
+    const count: number = await window.go.main.App.StopNodesByQuery(nodeQuery.value)
+    await fetchStatuses()
+    return count
+  }
+
   async function pingTest(id: string) {
     await window.go.main.App.PingTest(id)
   }
@@ -116,6 +149,32 @@ export const useNodesStore = defineStore('nodes', () => {
     } else {
       statuses.value[id] = { node_id: id, status, start_time: '', pid: 0 }
     }
+    if (nodeQuery.value.status || nodeQuery.value.sort_by === 'status') refreshNodeQuery()
+  }
+
+  async function fetchLiveStatus() {
+    try {
+      liveStatus.value = await window.go.main.App.GetLiveStatus()
+    } catch (e) {}
+  }
+
+  async function applyNodeQuery(query: Partial<NodeQuery>) {
+    nodeQuery.value = { ...nodeQuery.value, ...query }
+    await refreshNodeQuery()
+  }
+
+  async function refreshNodeQuery() {
+    const q = nodeQuery.value
+    if (!q.search && !q.status && !q.rule_group_id && !q.group && !q.tag && !q.sort_by) {
+      filteredIds.value = null
+      return
+    }
+    try {
+      const page: NodePage = await window.go.main.App.GetNodes(q)
+      filteredIds.value = page.nodes.map(n => n.id)
+    } catch (e) {
+      console.error(e)
+    }
   }
 
   function getNodeStatus(id: string): string {
@@ -126,12 +185,59 @@ export const useNodesStore = defineStore('nodes', () => {
     await window.go.main.App.ExportToClipboard(id)
   }
 
+  // 导出为 Clash Meta / sing-box 配置文件，返回保存路径（取消时为空）
+  async function exportNodeConfig(id: string, format: 'clash' | 'sing-box'): Promise<string> {
+    return await window.go.main.App.SaveNodeConfigExport(id, format)
+  }
+
   async function importNodes() {
     const count = await window.go.main.App.ImportFromClipboard()
     if (count > 0) await fetchNodes()
     return count
   }
+
+  // 订阅地址导入：preview 为 true 时只解析不写入
+  async function importFromURL(url: string, preview = false): Promise<URLImportResult> {
+    if (preview) return await window.go.main.App.PreviewImportFromURL(url)
+    const result = await window.go.main.App.ImportFromURL(url)
+    await fetchNodes()
+    return result
+  }
   
+  async function fetchSubscriptions() {
+    subscriptions.value = await window.go.main.App.GetSubscriptions()
+  }
+
+  // 保存订阅并导入其中的节点
+  async function addSubscription(name: string, url: string): Promise<Subscription> {
+    const sub = await window.go.main.App.AddSubscription(name, url)
+    await Promise.all([fetchSubscriptions(), fetchNodes()])
+    return sub
+  }
+
+  // 刷新订阅，返回节点变化
+  async function refreshSubscription(id: string): Promise<SubscriptionRefresh> {
+    try {
+      const refresh = await window.go.main.App.RefreshSubscription(id)
+      await fetchNodes()
+      return refresh
+    } finally {
+      await fetchSubscriptions()
+    }
+  }
+
+  async function deleteSubscription(id: string, deleteNodes: boolean) {
+    await window.go.main.App.DeleteSubscription(id, deleteNodes)
+    await Promise.all([fetchSubscriptions(), fetchNodes()])
+  }
+
+  // 选择图片识别二维码导入，取消选择时返回 null
+  async function importFromQRImage(): Promise<QRImportResult | null> {
+    const result = await window.go.main.App.ImportFromQRImage()
+    if (result) await fetchNodes()
+    return result
+  }
+
   async function addRule(nodeId: string, rule: any) {
     await window.go.main.App.AddRule(nodeId, rule);
     await fetchNodes(); 
@@ -147,12 +253,99 @@ export const useNodesStore = defineStore('nodes', () => {
     await fetchNodes();
   }
 
+  // 增量事件处理 (node:added / node:updated / node:deleted / rule:*)
+  function applyNodeEvent(payload: { node_id: string; node?: NodeConfig; fields?: string[] }) {
+    if (!payload.node) return
+    const index = nodes.value.findIndex(n => n.id === payload.node_id)
+    if (index === -1) {
+      nodes.value.push(payload.node)
+    } else {
+      Object.assign(nodes.value[index], payload.node)
+    }
+  }
+
+  function removeNodeLocal(id: string) {
+    nodes.value = nodes.value.filter(n => n.id !== id)
+    if (currentNodeId.value === id) currentNodeId.value = null
+  }
+
+  function applyRuleEvent(payload: { node_id: string; rule_id: string; rule?: any }, deleted = false) {
+    const node = nodes.value.find(n => n.id === payload.node_id)
+    if (!node) return
+    const index = node.rules.findIndex(r => r.id === payload.rule_id)
+    if (deleted) {
+      if (index !== -1) node.rules.splice(index, 1)
+    } else if (payload.rule) {
+      if (index === -1) node.rules.push(payload.rule)
+      else node.rules[index] = payload.rule
+    }
+  }
+
+  // 全局规则组 (rulegroup:*)
+  async function fetchRuleGroups() {
+    ruleGroups.value = await window.go.main.App.GetRuleGroups()
+  }
+
+  async function saveRuleGroup(group: RuleGroup) {
+    if (group.id) await window.go.main.App.UpdateRuleGroup(group)
+    else await window.go.main.App.AddRuleGroup(group)
+    await fetchRuleGroups()
+  }
+
+  async function deleteRuleGroup(id: string) {
+    await window.go.main.App.DeleteRuleGroup(id)
+    await Promise.all([fetchRuleGroups(), fetchNodes()])
+  }
+
+  async function setNodeRuleGroup(nodeId: string, groupId: string, attached: boolean) {
+    if (attached) await window.go.main.App.AttachRuleGroup(nodeId, groupId)
+    else await window.go.main.App.DetachRuleGroup(nodeId, groupId)
+  }
+
+  // 流量统计 (stats:update)
+  async function fetchTraffic() {
+    try {
+      const list: TrafficStats[] = await window.go.main.App.GetAllTrafficStats()
+      traffic.value = Object.fromEntries(list.map(s => [s.node_id, s]))
+    } catch (e) {}
+  }
+
+  function applyTrafficUpdate(list: TrafficStats[]) {
+    for (const s of list) traffic.value[s.node_id] = s
+  }
+
+  async function resetTraffic(id = '') {
+    await window.go.main.App.ResetTrafficStats(id)
+    if (id) delete traffic.value[id]
+    else traffic.value = {}
+  }
+
+  async function fetchAutoSelect() {
+    autoSelect.value = await window.go.main.App.GetAutoSelectState()
+  }
+
+  async function setAutoSelect(enabled: boolean) {
+    await window.go.main.App.EnableAutoSelect(enabled)
+    await fetchAutoSelect()
+  }
+
+  async function setAutoSelectOptions(interval: number, threshold: number) {
+    await window.go.main.App.SetAutoSelect(interval, threshold)
+    await fetchAutoSelect()
+  }
+
   return {
-    nodes, currentNodeId, statuses, isLoading, error,
+    nodes, currentNodeId, statuses, traffic, autoSelect, ruleGroups, subscriptions, isLoading, error,
+    nodeQuery, visibleNodes, applyNodeQuery, liveStatus, fetchLiveStatus,
     currentNode, runningNodes, hasRunningNodes,
     fetchNodes, fetchStatuses, selectNode, addNode, updateNode,
     deleteNode, duplicateNode, startNode, stopNode, startAllNodes,
-    stopAllNodes, pingTest, updateNodeStatus, getNodeStatus,
-    exportNode, importNodes, addRule, updateRule, deleteRule
+    stopAllNodes, startFilteredNodes, stopFilteredNodes, pingTest, updateNodeStatus, getNodeStatus,
+    exportNode, exportNodeConfig, importNodes, importFromURL, importFromQRImage, addRule, updateRule, deleteRule,
+    applyNodeEvent, removeNodeLocal, applyRuleEvent,
+    fetchTraffic, applyTrafficUpdate, resetTraffic,
+    fetchAutoSelect, setAutoSelect, setAutoSelectOptions,
+    fetchRuleGroups, saveRuleGroup, deleteRuleGroup, setNodeRuleGroup,
+    fetchSubscriptions, addSubscription, refreshSubscription, deleteSubscription
   }
 })
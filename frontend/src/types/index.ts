@@ -2,6 +2,20 @@
 // 节点相关类型
 // ============================================
 
+// 运行中的程序（按应用分流选择程序）
+export interface RunningApp {
+  name: string
+  path?: string
+}
+
+// 局域网共享设置
+export interface LANShareSettings {
+  enabled: boolean
+  username: string
+  password: string
+  allowed_clients?: string[] // IP 或 CIDR，留空时允许私有地址
+}
+
 export interface RoutingRule {
   id: string
   type: string // "", "domain:", "regexp:", "geosite:", "geoip:"
@@ -9,9 +23,18 @@ export interface RoutingRule {
   target: string
 }
 
+export interface RuleGroup {
+  id: string
+  name: string
+  rules: RoutingRule[]
+}
+
 export interface NodeConfig {
   id: string
   name: string
+  tags?: string[]
+  group?: string // 分组，可按分组批量启动 / 停止
+  subscription_id?: string // 导入该节点的订阅
   listen: string
   server: string
   ip: string
@@ -19,14 +42,62 @@ export interface NodeConfig {
   secret_key: string
   fallback_ip: string
   socks5: string
+  chain_node_id?: string // 前置节点：出站经该节点的本地 SOCKS5 入站（与 socks5 互斥）
+  inbound_mode?: number // 0=SOCKS5 1=SOCKS5+HTTP 2=混合端口
+  http_listen?: string
+  lan_share: LANShareSettings // 局域网共享（需智能分流）
   routing_mode: number
   strategy_mode: number
+  front_engine?: string // 智能分流前端: '' / 'xray' = Xray，'sing-box' = sing-box
+  fixed_internal_port?: number // 智能分流时 Xlink 核心的固定内部端口，0 从内部端口范围中分配
+  app_routing_mode?: number // 0=关闭 1=仅列表中的程序走代理 2=列表中的程序直连（需智能分流）
+  app_routing_apps?: string[] // 程序名或完整路径
   dns_mode: number
   enable_sniffing: boolean
+  tun_mtu?: number // 0 或不填使用默认值 9000
   rules: RoutingRule[]
+  rule_group_ids?: string[]
+  auto_restart?: boolean
+  global_keep_alive?: boolean
+  idle_timeout?: number // 秒，0 使用内核默认值
+  keep_alive_interval?: number // 秒，0 使用内核默认值
+  upload_limit?: number // KB/s，0 表示不限
+  download_limit?: number // KB/s，0 表示不限
+  connection_policy?: ConnectionPolicy // 为 0 的字段使用全局设置
+  xray_template?: string // 自定义 Xray 配置模板（JSON），为空时使用全局模板
   status?: string
 }
 
+export interface NodeQuery {
+  search: string
+  status: string
+  rule_group_id: string // 引用的规则组
+  group: string // 节点分组
+  tag: string
+  sort_by: string // "", "name", "status", "listen"
+  desc: boolean
+  offset: number
+  limit: number // 0 表示不分页
+}
+
+// 分组或标签下的节点数
+export interface NodeGroupCount {
+  name: string
+  nodes: number
+  running: number
+}
+
+export interface NodeGroupList {
+  groups: NodeGroupCount[]
+  tags: NodeGroupCount[]
+}
+
+export interface NodePage {
+  nodes: NodeConfig[]
+  total: number // 符合条件的节点数（分页前）
+  all: number
+}
+
 // ============================================
 // 应用配置
 // ============================================
@@ -39,6 +110,29 @@ export interface AppConfig {
   language: string
   global_dns_mode: number
   tun_interface_name: string
+  egress_mode?: number
+  egress_node_id?: string
+  kill_switch_enabled?: boolean
+  kill_switch_active?: boolean
+  preview_system_changes?: boolean
+  speed_test_download_url?: string
+  speed_test_upload_url?: string
+  ping_concurrency?: number
+  ping_timeout?: number
+  ping_mode?: '' | 'core' | 'tcp' | 'icmp'
+  server_health_check?: boolean
+  server_health_interval?: number
+  internal_port_range?: { min: number; max: number } // 都为 0 时由系统分配
+  proxy_bypass?: string[] // 不经系统代理的地址，为空时使用默认列表
+  system_proxy_mode?: number // 0 仅 SOCKS，1 分协议，2 PAC 自动代理
+}
+
+export interface EgressStatus {
+  mode: number
+  node_id: string
+  node_name: string
+  proxy: string
+  fallback: boolean
 }
 
 // ============================================
@@ -54,6 +148,195 @@ export interface EngineStatus {
   error_message?: string
 }
 
+// ============================================
+// 流量统计
+// ============================================
+
+// 活动节点实时状态 (live:status，每 5 秒)
+export interface LiveStatus {
+  node_id: string // 没有运行中的节点时为空
+  node_name: string
+  up_speed: number // 字节/秒
+  down_speed: number
+  latency: number // 毫秒，-1 表示失败，0 表示尚未探测
+  probed_at: number
+  exit_ip: string
+  exit_country: string
+  country_code: string
+  exit_asn: number // 离线 ASN 数据，未知时为 0
+  exit_as_org: string
+  uptime: number // 秒
+}
+
+export interface TrafficStats {
+  node_id: string
+  upload: number
+  download: number
+  up_speed: number
+  down_speed: number
+  connections: number
+  last_target: string
+  start_time: string
+  last_update: string
+  // 智能分流模式下 Xray 统计接口按标签的累计计数
+  inbounds?: Record<string, ByteCounter>
+  outbounds?: Record<string, ByteCounter> // proxy_out 经代理，direct / direct-ipv6 直连
+}
+
+export interface ByteCounter {
+  up: number
+  down: number
+}
+
+export interface ConnectionInfo {
+  id: number
+  node_id: string
+  target: string
+  server: string
+  real_server: string
+  local_port?: number // 隧道连接的本地端口，未知时省略
+  rule: string
+  strategy: string
+  latency_ms: number // 隧道建立延迟，-1 表示未知
+  start_time: string
+  duration_ms: number
+  upload: number
+  download: number
+  closed: boolean
+}
+
+export interface AutoSelectState {
+  enabled: boolean
+  testing: boolean
+  active_node_id: string
+  best_node_id: string
+  latencies: Record<string, number>
+  interval: number
+  threshold: number
+  last_check: string
+  last_switch: string
+  last_reason: string
+}
+
+// ============================================
+// 配套组件
+// ============================================
+
+export interface ComponentStatus {
+  name: string
+  description: string
+  required: boolean
+  present: boolean
+  path?: string
+  version?: string
+  sha256?: string
+  size: number
+  last_updated: string
+  action?: '' | 'download' | 'update'
+  hint?: string
+}
+
+export interface FirewallProgramStatus {
+  program: string
+  path: string
+  present: boolean
+  rule_name: string
+  allowed: boolean
+  blocked: boolean
+}
+
+export interface FirewallStatus {
+  supported: boolean
+  is_admin: boolean
+  ok: boolean
+  programs: FirewallProgramStatus[]
+  error?: string
+}
+
+export interface FirewallChangeResult {
+  plan: SystemChangePlan
+  status: FirewallStatus
+}
+
+export interface KillSwitchStatus {
+  enabled: boolean
+  engaged: boolean
+  supported: boolean
+  is_admin: boolean
+  node_ids: string[]
+}
+
+// 修改系统设置的命令（预览模式下 dry_run 为 true，未执行）
+export interface SystemCommand {
+  description: string
+  program: string
+  args: string[]
+  line: string
+  ignore_error: boolean
+  native?: string // 优先调用的系统 API 实现，失败时才执行 line
+  native_args?: string[]
+}
+
+export interface SystemChangePlan {
+  title: string
+  dry_run: boolean
+  requires_admin: boolean
+  commands: SystemCommand[]
+}
+
+export interface ScheduleEntry {
+  id: string
+  name: string
+  enabled: boolean
+  spec: string // "HH:MM" 或五段式 cron（分 时 日 月 周）
+  action: 'start' | 'stop' | 'switch' | 'stop-all'
+  node_id: string
+}
+
+export interface ScheduleInfo extends ScheduleEntry {
+  next_run: number
+}
+
+export interface GeoDataProgress {
+  file: string
+  mirror: string
+  downloaded: number
+  total: number
+}
+
+export interface GeoDataStatus {
+  updating: boolean
+  last_update: number
+  missing: string[]
+  update_days: number
+  mirrors: string[] // 为空时使用内置镜像
+}
+
+export interface GeoDataResult {
+  file: string
+  success: boolean
+  mirror?: string
+  sha256?: string
+  size: number
+  error?: string
+}
+
+export interface SkippedGeoRule {
+  source: 'builtin' | 'user'
+  name: string
+  rule_id?: string
+  file: string
+  substituted: boolean
+}
+
+export interface GeoDataMissingInfo {
+  node_id: string
+  node_name: string
+  missing_files: string[]
+  skipped_rules: SkippedGeoRule[]
+  fallback: boolean
+}
+
 // ============================================
 // 日志
 // ============================================
@@ -63,8 +346,40 @@ export interface LogEntry {
   node_id: string
   node_name: string
   level: 'debug' | 'info' | 'warn' | 'error'
-  category: string
+  category: string // 类别标识: system / engine / tunnel / rule / lb / stats / ping / xray / sing-box / dns
+  category_name?: string // 按界面语言本地化的显示名称
   message: string
+  metrics?: Record<string, Metric> // 日志中的数值（隧道延迟 latency，连接流量 up / down / duration）
+}
+
+// 数值的单位提示，界面按语言格式化
+export type MetricUnit = 'bytes' | 'bytes/s' | 'ms' | 's' | 'count'
+
+// 带单位提示的原始数值
+export interface Metric {
+  value: number
+  unit: MetricUnit
+}
+
+export interface LogCategory {
+  id: string
+  name: string
+}
+
+export interface LogFilter {
+  node_id?: string
+  levels?: string[]
+  categories?: string[]
+  search?: string
+  start_time?: string
+  end_time?: string
+  limit?: number
+}
+
+export interface LogBatch {
+  subscription_id: string
+  entries: LogEntry[]
+  dropped?: number
 }
 
 // ============================================
@@ -78,6 +393,7 @@ export interface PingResult {
 }
 
 export interface PingReport {
+  id: string
   node_id: string
   node_name: string
   start_time: string
@@ -90,6 +406,95 @@ export interface PingReport {
   min_latency: number
   max_latency: number
   results: PingResult[]
+  mode: 'core' | 'tcp' | 'icmp'
+}
+
+// 按测速结果重排服务器池的结果
+export interface PingOrderingResult {
+  report_id: string
+  servers: string[]
+  dropped: string[] | null
+  changed: boolean
+}
+
+// 热重载的结果
+export interface ReloadResult {
+  node_id: string
+  method: 'none' | 'rules' | 'front' | 'restart'
+  reason?: string // 未能以更轻的方式应用的原因
+}
+
+// 服务器池中一个服务器的健康状态
+export interface ServerHealthStatus {
+  server: string
+  up: boolean
+  latency_ms: number // 最近一次探测失败时为 -1
+  last_error?: string
+  checked_at: string
+  down_since?: string
+}
+
+// 延迟历史中的一次测速
+export interface LatencyPoint {
+  node_id: string
+  time: number // Unix 秒
+  avg: number // 毫秒，全部失败时为 -1
+  min: number
+  max: number
+  success: number
+  total: number
+}
+
+export interface SpeedTestProgress {
+  node_id: string
+  phase: 'download' | 'upload'
+  bytes: number
+  total: number
+  mbps: number
+}
+
+export interface SpeedTestResult {
+  node_id: string
+  node_name: string
+  start_time: string
+  download_mbps: number
+  download_bytes: number
+  upload_mbps: number
+  upload_bytes: number
+  error?: string
+}
+
+// UDP 转发测试结果
+export interface UDPProbeResult {
+  ok: boolean
+  stage: string // associate / relay
+  target: string
+  relay_addr?: string
+  rtt_ms: number
+  error?: string
+  checked_at: number
+}
+
+// TUN MTU 探测结果
+export interface MTUProbeResult {
+  server: string
+  ip: string
+  path_mtu: number
+  mtu: number // 建议的 TUN MTU
+  overhead: number
+  probes: number
+  checked_at: number
+}
+
+// 节点 UDP 会话统计
+export interface UDPStats {
+  node_id: string
+  sessions: number
+  failures: number
+  last_session: number
+  last_failure: number
+  last_error?: string
+  probe?: UDPProbeResult
 }
 
 // ============================================
@@ -109,14 +514,65 @@ export interface DNSLeakResult {
   local_dns: string[]
   detected_dns: DNSServerInfo[]
   conclusion: string
+  geo_sources?: string[] // 用于归属分析的离线数据文件
+  proxy_dns?: DNSProbeResult // 经代理的 UDP DNS 探测
+}
+
+// 经节点的泄露测试（与直连基线对比）
+export interface NodeLeakReport {
+  node_id: string
+  node_name: string
+  tested_at: string
+  direct?: DNSLeakResult // 不经代理的基线
+  direct_probe?: DNSProbeResult
+  via_node?: DNSLeakResult
+  shared_dns?: string[] // 经节点和直连时都检测到的 DNS 服务器
+  probe_bypassed: boolean
+  leaked: boolean
+  conclusion: string
+}
+
+// 经 SOCKS5 UDP 关联发送的 DNS 探测
+export interface DNSProbeResult {
+  server: string
+  name: string
+  source_ip?: string // 解析服务器看到的查询来源
+  source: IPAttribution
+  rtt_ms?: number
+  error?: string
+}
+
+export interface IPAttribution {
+  ip: string
+  country_code?: string
+  country?: string
+  asn?: number
+  as_org?: string
+}
+
+// IPv6 泄露测试结果
+export interface IPv6LeakResult {
+  leaked: boolean
+  tested_at: string
+  direct_ip: string // 代理之外以 IPv6 访问时的出口，空表示本机无法直接访问 IPv6 网络
+  direct_local: boolean
+  proxy_ip: string // 经代理访问仅 IPv6 站点时的出口
+  direct: IPAttribution
+  errors?: string[]
+  conclusion: string
 }
 
 export interface DNSServerInfo {
   ip: string
   country: string
+  country_code?: string // 离线数据给出的 ISO 国家代码
   city: string
   isp: string
+  asn?: number
+  as_org?: string
   is_china: boolean
+  provider?: string
+  owner: 'isp' | 'dns_provider' | 'unknown'
 }
 
 // ============================================
@@ -152,5 +608,409 @@ export const NodeStatus = {
   STOPPED: 'stopped',
   STARTING: 'starting',
   RUNNING: 'running',
-  ERROR: 'error'
+  ERROR: 'error',
+  RESTARTING: 'restarting'
 } as const
+
+// ============================================
+// 通知
+// ============================================
+
+export type NotificationEvent = 'node' | 'node_status' | 'killswitch' | 'leak' | 'network' | 'geodata' | 'schedule' | 'component' | 'subscription' | 'system'
+export type NotificationChannel = 'toast' | 'tray' | 'webhook' | 'telegram' | 'center'
+
+export interface AppNotification {
+  id: number
+  event: NotificationEvent
+  title: string
+  message: string
+  time: string
+  node?: string // 相关节点名称
+  status?: string // 节点事件的状态
+}
+
+export interface NotificationSettings {
+  routes: Record<string, NotificationChannel[]> | null
+  default_channels: NotificationChannel[] | null
+  webhook_url: string
+  telegram_token: string
+  telegram_chat_id: string
+  webhooks: WebhookSettings[] | null
+}
+
+// 自定义 Webhook，模板为 Go text/template：{{.Title}} {{.Message}} {{.Event}} {{.Node}} {{.Status}}，{{json .Message}} 输出 JSON 字符串
+export interface WebhookSettings {
+  name: string
+  url: string
+  template: string // 为空时发送默认 JSON
+  content_type: string // 为空时为 application/json
+  events: NotificationEvent[] | null // 为空时发送路由到 Webhook 渠道的全部事件
+}
+
+export interface WebhookPreset {
+  name: string
+  url: string // 尖括号中的部分需替换
+  template: string
+}
+
+export interface NotificationOptions {
+  events: NotificationEvent[]
+  channels: { name: NotificationChannel; configured: boolean }[]
+  webhook_presets: WebhookPreset[]
+}
+
+// ============================================
+// 内核更新
+// ============================================
+
+export interface CoreUpdateSettings {
+  feeds: string[] | null
+  auto_update: boolean
+}
+
+export interface CoreRelease {
+  name: string
+  version: string
+  url: string
+  sha256: string
+  size?: number
+  feed?: string
+}
+
+export interface CoreUpdateInfo {
+  name: string
+  current_version: string
+  current_sha256: string
+  present: boolean
+  latest?: CoreRelease
+  available: boolean
+}
+
+export interface CoreUpdateProgress {
+  name?: string
+  stage: 'downloading' | 'installing' | 'restarting'
+  nodes?: string[]
+}
+
+export interface CoreUpdateResult {
+  name: string
+  version: string
+  success: boolean
+  error?: string
+}
+
+export interface ResumeReport {
+  slept: string
+  checked: number
+  restarted: string[] | null
+  reapplied: number
+  errors?: string[]
+}
+
+// ============================================
+// 导入
+// ============================================
+
+export interface ImportIssue {
+  name: string
+  scheme: string
+  unsupported?: string[]
+  error?: string
+}
+
+export interface NodeChange {
+  name: string
+  server: string
+  old_server?: string // 服务器有变化时为原服务器
+  fields?: string[] // server / ip / token / secret_key / fallback_ip
+}
+
+export interface SubscriptionDiff {
+  added: NodeChange[] | null
+  removed: NodeChange[] | null
+  modified: NodeChange[] | null
+  skipped?: number // 超出节点数量上限未添加的新节点数
+}
+
+export interface SubscriptionRefresh {
+  time: number
+  format: string
+  error?: string
+  diff: SubscriptionDiff
+}
+
+export interface Subscription {
+  id: string
+  name: string
+  url: string
+  last_refresh: number
+  history: SubscriptionRefresh[] | null // 最新的在前
+}
+
+export interface URLImportResult {
+  format: 'links' | 'base64' | 'clash' | 'sing-box'
+  nodes: NodeConfig[]
+  count: number
+  skipped?: number // 超出节点数量上限未导入的数量
+  limit?: number
+  dry_run: boolean
+  issues?: ImportIssue[]
+}
+
+export interface QRImportResult {
+  codes: number // 识别出的二维码数量
+  nodes: NodeConfig[]
+  count: number
+  skipped?: number // 超出节点数量上限未导入的数量
+  limit?: number
+  issues?: ImportIssue[]
+}
+
+// ============================================
+// 自动重启
+// ============================================
+
+export interface RestartPolicy {
+  max_retries: number
+  initial_delay: number // 秒，之后每次翻倍
+  max_delay: number // 秒
+}
+
+// ============================================
+// 启动 / 停止超时与连接重试
+// ============================================
+
+export interface ConnectionPolicy {
+  start_timeout: number // 秒，本地入站超时仍无法连接视为启动失败
+  stop_timeout: number // 秒
+  dial_retries: number // 限速转发连接内核失败后的重试次数
+  retry_interval: number // 毫秒，之后每次翻倍
+  max_retry_interval: number // 毫秒
+  handshake_timeout: number // 秒，智能分流模式下 Xray 入站握手超时，0 使用内核默认值
+}
+
+// ============================================
+// 钩子命令
+// ============================================
+
+export interface HookSettings {
+  post_start: string
+  post_stop: string
+  timeout: number
+}
+
+export interface HookResult {
+  event: string
+  command: string
+  output: string
+  exit_code: number
+  duration: number
+  timed_out: boolean
+  error?: string
+}
+
+// ============================================
+// 自动备份
+// ============================================
+
+export interface BackupSettings {
+  disable_daily: boolean
+  keep_daily: number // 0 使用默认值 (7)
+  keep_snapshots: number // 0 使用默认值 (20)
+}
+
+// 系统时间校验结果
+export interface ClockCheckResult {
+  source: string // ntp://服务器 或 HTTP 地址
+  offset_ms: number // 本机时间减标准时间
+  rtt_ms: number
+  skewed: boolean // 偏差超过阈值
+  threshold: number // 秒
+  checked_at: number
+  error?: string
+}
+
+// 存储模式：程序目录（便携）或当前用户的目录
+export type StorageMode = 'portable' | 'user'
+
+// 程序目录与用户数据目录
+export interface DataLocation {
+  exe_dir: string
+  data_dir: string // 配置、日志、备份所在目录
+  portable: boolean // 程序目录中有 portable 文件时数据保存在程序目录
+  next_dir?: string // 已切换存储模式，重启后使用的目录
+}
+
+// 界面数据目录（webview_data）设置
+export interface WebviewDataSettings {
+  in_user_dir: boolean // 存放在用户数据目录，重启后生效
+  max_size_mb: number // 0 使用默认值 (300)
+  auto_clean: boolean // 超过大小时下次启动自动清理缓存
+}
+
+export interface WebviewDataInfo {
+  path: string
+  total_bytes: number
+  cache_bytes: number
+  clean_pending: boolean // 已标记在下次启动时清理缓存
+  settings: WebviewDataSettings
+  limit_bytes: number
+  next_path?: string // 重启后使用的目录
+}
+
+// 节点与规则数量上限，0 使用默认值（50 个节点、每个节点 300 条规则）
+export interface LimitSettings {
+  max_nodes?: number
+  max_rules?: number
+}
+
+// 生成的内核配置文件保留策略
+export interface GeneratedFilesSettings {
+  keep_last: boolean // 退出时保留每个节点最近一次生成的配置
+}
+
+// 程序生成的内核配置文件
+export interface GeneratedFile {
+  node_id: string
+  name: string // 位于数据目录
+  generated_at: string
+  size?: number
+}
+
+// 后台服务状态
+export type ServiceState = 'not_installed' | 'stopped' | 'starting' | 'stopping' | 'running' | 'paused'
+
+export interface ServiceInfo {
+  supported: boolean // 仅 Windows 支持
+  installed: boolean
+  state: ServiceState
+  frontend: boolean // 界面正作为服务的控制前端（节点由服务运行）
+  data_dir: string
+}
+
+// Prometheus 指标接口
+export interface MetricsSettings {
+  enabled: boolean
+  listen: string // 默认 127.0.0.1:9091
+}
+
+export interface MetricsStatus {
+  enabled: boolean
+  running: boolean
+  listen: string
+  url: string // 运行中时的抓取地址
+}
+
+// 内核崩溃报告
+export interface CrashReport {
+  id: string
+  node_id: string
+  node_name: string
+  source: string // "xlink" | "xray" | "sing-box"
+  time: string
+  exit_code: number // -1 表示被终止或未知
+  error: string
+  uptime_seconds: number
+  restart_attempt: number
+  output: string[] | null // 最后的输出（已隐去密钥）
+  configs: Record<string, string> | null // 文件名 → 脱敏后的配置
+}
+
+export interface BackupInfo {
+  name: string
+  reason: string // "", "delete", "preset", "restore", "manual", "daily", "sync"
+  time: number // Unix 秒
+  size: number
+}
+
+// ============================================
+// 只读模式
+// ============================================
+
+export interface KioskStatus {
+  enabled: boolean
+  has_password: boolean // 退出只读模式需要密码
+}
+
+// ============================================
+// WebDAV 同步
+// ============================================
+
+export interface SyncSettings {
+  url: string // WebDAV 目录（或 .json 文件）地址
+  username: string
+  password: string
+  passphrase: string // 同步密码（各设备需一致）
+  auto_sync: boolean
+  // 以下由同步流程维护
+  revision: number
+  synced_hash: string
+  last_sync: number
+}
+
+export interface SyncStatus {
+  configured: boolean
+  auto_sync: boolean
+  revision: number
+  local_changed: boolean // 上次同步后本地有修改
+  last_sync: number
+}
+
+export interface SyncResult {
+  action: 'none' | 'push' | 'pull' | 'conflict'
+  revision: number
+  remote_device: string
+  remote_updated: number
+  nodes: number
+}
+
+// ============================================
+// 本机 DNS 服务
+// ============================================
+
+export interface LocalDNSSettings {
+  enabled: boolean
+  upstream: string[]
+}
+
+export interface DNSServerPreset {
+  name: string
+  servers: string[]
+}
+
+export interface DNSSettings {
+  fake_ip_filter: string[]
+  hosts: Record<string, string>
+  presets: DNSServerPreset[]
+}
+
+export interface LocalDNSStatus {
+  enabled: boolean
+  running: boolean
+  addrs: string[]
+  upstream: string[]
+  stats: {
+    queries: number
+    fake_ip: number
+    cached: number
+    forwarded: number
+    failed: number
+  }
+}
+
+export interface DNSCacheStats {
+  entries: number
+  max_entries: number
+  hits: number
+  misses: number
+  hit_ratio: number // 0-1
+  evictions: number
+}
+
+export interface DNSCacheEntry {
+  domain: string
+  type: string
+  ttl: number // 剩余秒数
+  hits: number
+  size: number
+}
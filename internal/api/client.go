@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 客户端
+// =============================================================================
+
+// clientTimeout 单次请求超时（启动节点需要等待内核就绪）
+const clientTimeout = 30 * time.Second
+
+// Client 控制接口客户端（界面进程通过它控制后台服务）
+type Client struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// NewClient 创建客户端，listen 为服务端监听地址
+func NewClient(listen, token string) *Client {
+	if listen == "" {
+		listen = DefaultListen
+	}
+	return &Client{
+		base:   "http://" + listen + "/api/v1/",
+		token:  token,
+		client: &http.Client{Timeout: clientTimeout},
+	}
+}
+
+// Status 所有节点运行状态
+func (c *Client) Status() (map[string]models.EngineStatus, error) {
+	var statuses map[string]models.EngineStatus
+	err := c.do(http.MethodGet, "status", &statuses)
+	return statuses, err
+}
+
+// StartNode 启动节点
+func (c *Client) StartNode(ref string) error {
+	return c.do(http.MethodPost, "nodes/"+url.PathEscape(ref)+"/start", nil)
+}
+
+// StopNode 停止节点
+func (c *Client) StopNode(ref string) error {
+	return c.do(http.MethodPost, "nodes/"+url.PathEscape(ref)+"/stop", nil)
+}
+
+// StopAll 停止所有节点
+func (c *Client) StopAll() error {
+	return c.do(http.MethodPost, "stop-all", nil)
+}
+
+// Reload 通知服务端重新读取配置文件
+func (c *Client) Reload() error {
+	return c.do(http.MethodPost, "reload", nil)
+}
+
+// do 发送请求，服务端返回错误时取出错误信息
+func (c *Client) do(method, path string, out interface{}) error {
+	req, err := http.NewRequest(method, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接控制接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error != "" {
+			return errors.New(e.Error)
+		}
+		return fmt.Errorf("控制接口返回 %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,386 @@
+// Package api 提供本地 REST 控制接口，供脚本和其他工具在没有界面的情况下控制客户端
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/command"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 常量
+// =============================================================================
+
+const (
+	DefaultListen = "127.0.0.1:9090"
+
+	// PingTimeout 单次 Ping 测试的最长等待时间
+	PingTimeout = 60 * time.Second
+	// 日志默认/最大返回条数
+	defaultLogLimit = 200
+	maxLogLimit     = 5000
+
+	// 日志推送：每个连接最多缓存的批次数、空闲时的保活间隔
+	logStreamQueue     = 64
+	logStreamKeepAlive = 30 * time.Second
+)
+
+// =============================================================================
+// 后端接口
+// =============================================================================
+
+// Backend 控制接口依赖的应用能力（由 App 实现）
+type Backend interface {
+	GetNodes() []models.NodeConfig
+	GetAllNodeStatuses() map[string]models.EngineStatus
+	GetLogs(limit int) []models.LogEntry
+	GetLogsByNode(nodeID string, limit int) []models.LogEntry
+	SubscribeLogs(filter models.LogFilter, deliver func(batch logger.LogBatch)) string
+	UnsubscribeLogs(id string)
+	GetAllTrafficStats() []models.TrafficStats
+	PingNode(nodeRef string) (*logger.PingReport, error)
+	DispatchCommand(cmd command.Command) (interface{}, error)
+	ReloadConfig() error
+}
+
+// =============================================================================
+// 服务器
+// =============================================================================
+
+// Server 本地 HTTP 控制服务器
+type Server struct {
+	mu       sync.Mutex
+	backend  Backend
+	listen   string
+	token    string
+	server   *http.Server
+	listener net.Listener
+	cancel   context.CancelFunc // 停止时取消全部请求的上下文（结束日志流等长连接）
+}
+
+// NewServer 创建控制服务器
+func NewServer(backend Backend) *Server {
+	return &Server{backend: backend}
+}
+
+// GenerateToken 生成随机访问令牌
+func GenerateToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Start 在指定地址启动服务（已运行时先停止）
+func (s *Server) Start(listen, token string) error {
+	if token == "" {
+		return fmt.Errorf("访问令牌不能为空")
+	}
+	if listen == "" {
+		listen = DefaultListen
+	}
+
+	s.Stop()
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("控制接口监听失败: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", s.handle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.mu.Lock()
+	s.listen = listen
+	s.token = token
+	s.listener = ln
+	s.cancel = cancel
+	s.server = &http.Server{
+		Handler:           s.auth(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	srv := s.server
+	s.mu.Unlock()
+
+	go srv.Serve(ln)
+	return nil
+}
+
+// Stop 停止服务：先取消请求上下文让日志流等长连接结束，超时仍未结束的连接直接关闭
+func (s *Server) Stop() {
+	s.mu.Lock()
+	srv, cancelRequests := s.server, s.cancel
+	s.server = nil
+	s.listener = nil
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if srv != nil {
+		cancelRequests()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if srv.Shutdown(ctx) != nil {
+			srv.Close()
+		}
+	}
+}
+
+// IsRunning 是否正在运行
+func (s *Server) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server != nil
+}
+
+// Addr 实际监听地址
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// auth 校验 Authorization: Bearer <token>
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		token := s.token
+		s.mu.Unlock()
+
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="xlink"`)
+			writeError(w, http.StatusUnauthorized, errors.New("未授权"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// =============================================================================
+// 路由
+// =============================================================================
+
+// handle 路由表:
+//
+//	GET  /api/v1/nodes                 节点列表（含运行状态）
+//	GET  /api/v1/status                所有节点运行状态
+//	POST /api/v1/nodes/{ref}/start     启动节点（ref 为节点ID或名称）
+//	POST /api/v1/nodes/{ref}/stop      停止节点
+//	POST /api/v1/nodes/{ref}/switch    仅运行此节点
+//	POST /api/v1/nodes/{ref}/ping      延迟测试（等待完成后返回报告）
+//	POST /api/v1/stop-all              停止所有节点
+//	POST /api/v1/reload                重新读取配置文件（界面修改配置后通知后台服务）
+//	GET  /api/v1/logs?node=&limit=     日志
+//	GET  /api/v1/logs/stream?node=&level=&category=&search=
+//	                                   日志实时推送（Server-Sent Events，每个事件为一批日志）
+//	GET  /api/v1/stats                 流量统计
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/"), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "nodes" && r.Method == http.MethodGet:
+		s.handleNodes(w)
+	case path == "status" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.backend.GetAllNodeStatuses())
+	case path == "stop-all" && r.Method == http.MethodPost:
+		s.dispatch(w, command.CmdStopAll, "")
+	case path == "reload" && r.Method == http.MethodPost:
+		if err := s.backend.ReloadConfig(); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	case path == "logs" && r.Method == http.MethodGet:
+		s.handleLogs(w, r)
+	case path == "logs/stream" && r.Method == http.MethodGet:
+		s.handleLogStream(w, r)
+	case path == "stats" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.backend.GetAllTrafficStats())
+	case len(parts) == 3 && parts[0] == "nodes" && r.Method == http.MethodPost:
+		s.handleNodeAction(w, parts[1], parts[2])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("未知接口: %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// nodeView 节点列表项（不返回 token 等敏感字段）
+type nodeView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Listen      string `json:"listen"`
+	RoutingMode int    `json:"routing_mode"`
+	Status      string `json:"status"`
+}
+
+func (s *Server) handleNodes(w http.ResponseWriter) {
+	statuses := s.backend.GetAllNodeStatuses()
+	nodes := s.backend.GetNodes()
+
+	views := make([]nodeView, 0, len(nodes))
+	for _, n := range nodes {
+		status := models.StatusStopped
+		if st, ok := statuses[n.ID]; ok {
+			status = st.Status
+		}
+		views = append(views, nodeView{
+			ID:          n.ID,
+			Name:        n.Name,
+			Listen:      n.Listen,
+			RoutingMode: n.RoutingMode,
+			Status:      status,
+		})
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) handleNodeAction(w http.ResponseWriter, ref, action string) {
+	switch action {
+	case "start":
+		s.dispatch(w, command.CmdStart, ref)
+	case "stop":
+		s.dispatch(w, command.CmdStop, ref)
+	case "switch":
+		s.dispatch(w, command.CmdSwitch, ref)
+	case "ping":
+		report, err := s.backend.PingNode(ref)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("未知操作: %s", action))
+	}
+}
+
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLogLimit
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > maxLogLimit {
+		limit = maxLogLimit
+	}
+
+	if nodeID := r.URL.Query().Get("node"); nodeID != "" {
+		writeJSON(w, http.StatusOK, s.backend.GetLogsByNode(nodeID, limit))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.backend.GetLogs(limit))
+}
+
+// handleLogStream 以 SSE 推送匹配过滤条件的新日志，level / category 可用逗号分隔多个值
+func (s *Server) handleLogStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("不支持流式响应"))
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.LogFilter{
+		NodeID:     q.Get("node"),
+		Levels:     splitQueryList(q.Get("level")),
+		Categories: splitQueryList(q.Get("category")),
+		Search:     q.Get("search"),
+	}
+
+	batches := make(chan logger.LogBatch, logStreamQueue)
+	id := s.backend.SubscribeLogs(filter, func(batch logger.LogBatch) {
+		select {
+		case batches <- batch:
+		default: // 客户端读取过慢，丢弃该批
+		}
+	})
+	defer s.backend.UnsubscribeLogs(id)
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case batch := <-batches:
+			data, err := json.Marshal(batch)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: logs\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// splitQueryList 拆分逗号分隔的查询参数
+func splitQueryList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (s *Server) dispatch(w http.ResponseWriter, name command.Name, ref string) {
+	result, err := s.backend.DispatchCommand(command.Command{
+		Name:    name,
+		NodeRef: ref,
+		Source:  command.SourceAPI,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if result == nil {
+		result = map[string]bool{"ok": true}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// =============================================================================
+// 响应
+// =============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
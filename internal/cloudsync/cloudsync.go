@@ -0,0 +1,122 @@
+// Package cloudsync 通过 WebDAV 在多台设备间同步节点与规则组
+package cloudsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"xlink-wails/internal/config"
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 同步文件
+// =============================================================================
+
+const (
+	// FileName 同步目录下的文件名
+	FileName = "xlink-sync.json"
+
+	documentFormat  = "xlink-sync"
+	documentVersion = 1
+)
+
+// Payload 同步的内容（不含代理、系统设置等本机相关的配置）
+type Payload struct {
+	Nodes      []models.NodeConfig `json:"nodes"`
+	RuleGroups []models.RuleGroup  `json:"rule_groups"`
+}
+
+// Hash 内容摘要，用于判断上次同步后本地是否有修改
+func (p *Payload) Hash() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Document 远端保存的同步文件，内容以同步密码加密
+type Document struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Revision  int64     `json:"revision"` // 每次上传加一
+	Device    string    `json:"device"`   // 上传的设备名
+	UpdatedAt time.Time `json:"updated_at"`
+	config.SealedData
+}
+
+// Seal 以同步密码加密内容，生成指定版本号的同步文件
+func Seal(p *Payload, revision int64, device, passphrase string) (*Document, error) {
+	plaintext, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := config.SealWithPassword(plaintext, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{
+		Format:     documentFormat,
+		Version:    documentVersion,
+		Revision:   revision,
+		Device:     device,
+		UpdatedAt:  time.Now(),
+		SealedData: *sealed,
+	}, nil
+}
+
+// Open 以同步密码解密同步文件
+func (d *Document) Open(passphrase string) (*Payload, error) {
+	if d.Format != documentFormat {
+		return nil, i18n.Errorf("不是有效的同步文件")
+	}
+	if d.Version > documentVersion {
+		return nil, i18n.Errorf("同步文件版本 %d 过新，请升级客户端", d.Version)
+	}
+	plaintext, err := config.OpenWithPassword(&d.SealedData, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var p Payload
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, i18n.Errorf("解析同步文件失败: %w", err)
+	}
+	return &p, nil
+}
+
+// =============================================================================
+// 同步方向
+// =============================================================================
+
+// 同步动作
+const (
+	ActionNone     = "none"     // 已是最新
+	ActionPush     = "push"     // 上传本地修改
+	ActionPull     = "pull"     // 下载远端修改
+	ActionConflict = "conflict" // 本地与远端在上次同步后都有修改
+)
+
+// Decide 按版本号决定同步方向
+// synced 为上次同步时的远端版本号，remote 为当前远端版本号（远端没有文件时为 0），
+// localChanged 表示上次同步后本地是否有修改
+func Decide(synced, remote int64, localChanged bool) string {
+	remoteChanged := remote != synced
+	switch {
+	case remoteChanged && localChanged:
+		if remote == 0 {
+			// 远端文件已被删除，以本地为准
+			return ActionPush
+		}
+		return ActionConflict
+	case remoteChanged:
+		if remote == 0 {
+			return ActionPush
+		}
+		return ActionPull
+	case localChanged:
+		return ActionPush
+	}
+	return ActionNone
+}
@@ -0,0 +1,134 @@
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"xlink-wails/internal/i18n"
+)
+
+// =============================================================================
+// WebDAV 客户端
+// =============================================================================
+
+// maxDocumentSize 同步文件大小上限
+const maxDocumentSize = 16 << 20
+
+// ErrConflict 上传时远端文件已被其他设备修改
+var ErrConflict = errors.New("远端同步文件已被其他设备修改")
+
+// Client 读写 WebDAV 上的同步文件
+type Client struct {
+	URL      string // WebDAV 目录（以 .json 结尾时视为文件地址）
+	Username string
+	Password string
+	HTTP     *http.Client
+}
+
+// FileURL 同步文件的完整地址
+func (c *Client) FileURL() (string, error) {
+	u, err := url.Parse(strings.TrimSpace(c.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", i18n.Errorf("WebDAV 地址无效: %s", c.URL)
+	}
+	if !strings.HasSuffix(strings.ToLower(u.Path), ".json") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + FileName
+	}
+	return u.String(), nil
+}
+
+// Get 下载同步文件，远端没有文件时返回 nil；etag 用于上传时检测并发修改
+func (c *Client) Get(ctx context.Context) (doc *Document, etag string, err error) {
+	resp, err := c.do(ctx, http.MethodGet, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", statusError(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, "", i18n.Errorf("同步文件超过大小上限")
+	}
+	doc = &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, "", i18n.Errorf("不是有效的同步文件")
+	}
+	return doc, resp.Header.Get("ETag"), nil
+}
+
+// Put 上传同步文件
+// etag 为下载时的 ETag，远端文件已变化时返回 ErrConflict；为空时要求远端没有文件
+// （服务器不返回 ETag 时调用方传入 "*"，仅按版本号检测冲突）
+func (c *Client) Put(ctx context.Context, doc *Document, etag string) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": {"application/json"}}
+	if etag == "" {
+		header.Set("If-None-Match", "*")
+	} else if etag != "*" {
+		header.Set("If-Match", etag)
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, data, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrConflict
+	}
+	return statusError(resp)
+}
+
+func (c *Client) do(ctx context.Context, method string, body []byte, header http.Header) (*http.Response, error) {
+	fileURL, err := c.FileURL()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fileURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// statusError 将非预期的 HTTP 状态转换为错误
+func statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return i18n.Errorf("WebDAV 认证失败 (HTTP %d)", resp.StatusCode)
+	}
+	return i18n.Errorf("WebDAV 请求失败: HTTP %d", resp.StatusCode)
+}
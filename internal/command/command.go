@@ -0,0 +1,157 @@
+// Package command 提供统一的节点控制命令总线
+// 托盘菜单、命令行参数、第二实例唤醒以及 HTTP API 都通过同一个总线下发命令，
+// 避免在各个入口重复实现启动/停止逻辑
+package command
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// 命令定义
+// =============================================================================
+
+// Name 命令名称
+type Name string
+
+const (
+	CmdStart   Name = "start"    // 启动指定节点
+	CmdStop    Name = "stop"     // 停止指定节点
+	CmdSwitch  Name = "switch"   // 停止其他节点，仅运行指定节点
+	CmdStatus  Name = "status"   // 查询节点状态（NodeRef 为空时返回全部）
+	CmdStopAll Name = "stop-all" // 停止所有节点
+)
+
+// 命令来源
+const (
+	SourceTray     = "tray"
+	SourceCLI      = "cli"
+	SourceInstance = "instance" // 第二实例启动时转发的参数
+	SourceAPI      = "api"
+	SourceSchedule = "schedule" // 定时任务
+)
+
+// Command 一条控制命令
+type Command struct {
+	Name    Name   `json:"name"`
+	NodeRef string `json:"node_ref,omitempty"` // 节点ID或节点名称
+	Source  string `json:"source,omitempty"`
+}
+
+// Handler 命令处理函数
+type Handler func(cmd Command) (interface{}, error)
+
+// =============================================================================
+// 命令总线
+// =============================================================================
+
+// Bus 命令总线
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Name]Handler
+
+	// 每次分发后的回调（用于日志记录）
+	onDispatch func(cmd Command, err error)
+}
+
+// NewBus 创建命令总线
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[Name]Handler),
+	}
+}
+
+// Register 注册命令处理函数
+func (b *Bus) Register(name Name, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = h
+}
+
+// SetDispatchCallback 设置分发回调
+func (b *Bus) SetDispatchCallback(cb func(cmd Command, err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onDispatch = cb
+}
+
+// Dispatch 分发命令
+func (b *Bus) Dispatch(cmd Command) (interface{}, error) {
+	b.mu.RLock()
+	h, ok := b.handlers[cmd.Name]
+	cb := b.onDispatch
+	b.mu.RUnlock()
+
+	var result interface{}
+	var err error
+	if !ok {
+		err = fmt.Errorf("未知命令: %s", cmd.Name)
+	} else {
+		if needsNode(cmd.Name) && cmd.NodeRef == "" {
+			err = fmt.Errorf("命令 %s 需要指定节点", cmd.Name)
+		} else {
+			result, err = h(cmd)
+		}
+	}
+
+	if cb != nil {
+		cb(cmd, err)
+	}
+	return result, err
+}
+
+// needsNode 判断命令是否必须指定节点
+func needsNode(name Name) bool {
+	switch name {
+	case CmdStart, CmdStop, CmdSwitch:
+		return true
+	}
+	return false
+}
+
+// =============================================================================
+// 命令行解析
+// =============================================================================
+
+// ParseArgs 从命令行参数中解析控制命令
+// 支持的格式: -start=<节点>、--stop <节点>、-switch=<节点>、-status、-stop-all
+// 无法识别的参数（如 -autostart）会被忽略
+func ParseArgs(args []string, source string) []Command {
+	var cmds []Command
+
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], "-")
+		if arg == args[i] {
+			continue // 不是开关参数
+		}
+
+		key, value := arg, ""
+		hasValue := false
+		if idx := strings.Index(arg, "="); idx != -1 {
+			key, value = arg[:idx], arg[idx+1:]
+			hasValue = true
+		}
+
+		name := Name(strings.ToLower(key))
+		switch name {
+		case CmdStart, CmdStop, CmdSwitch:
+			if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				value = args[i+1]
+				i++
+			}
+		case CmdStatus, CmdStopAll:
+		default:
+			continue
+		}
+
+		cmds = append(cmds, Command{
+			Name:    name,
+			NodeRef: strings.Trim(value, `"`),
+			Source:  source,
+		})
+	}
+
+	return cmds
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 加密备份导出/导入
+// =============================================================================
+
+// 导出文件可以放在网盘同步目录中：内容使用与配置文件相同的 AES-GCM 加密。
+// 设置了密码时密钥由密码经 PBKDF2-SHA256 派生，可在其他电脑导入；
+// 未设置密码时使用本机配置密钥，只能在密钥相同（XLINK_CONFIG_KEY）的环境中导入。
+
+const (
+	backupFormat     = "xlink-backup"
+	backupVersion    = 1
+	backupKDFPBKDF2  = "pbkdf2-sha256"
+	backupIterations = 200000
+	backupSaltSize   = 16
+)
+
+// backupEnvelope 导出文件格式
+type backupEnvelope struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	KDF        string    `json:"kdf,omitempty"` // 为空表示使用本机配置密钥
+	Iterations int       `json:"iterations,omitempty"`
+	Salt       string    `json:"salt,omitempty"`
+	Data       string    `json:"data"` // Base64(nonce + 密文)
+}
+
+// ExportBackup 将当前配置加密导出到文件
+func (m *Manager) ExportBackup(path, password string) error {
+	m.mu.RLock()
+	config := m.config
+	m.mu.RUnlock()
+
+	if config == nil {
+		return fmt.Errorf("配置为空")
+	}
+
+	plaintext, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	env := backupEnvelope{
+		Format:    backupFormat,
+		Version:   backupVersion,
+		CreatedAt: time.Now(),
+	}
+
+	key := m.encKey
+	if password != "" {
+		salt := make([]byte, backupSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return err
+		}
+		env.KDF = backupKDFPBKDF2
+		env.Iterations = backupIterations
+		env.Salt = base64.StdEncoding.EncodeToString(salt)
+		key = pbkdf2.Key([]byte(password), salt, backupIterations, 32, sha256.New)
+	}
+
+	ciphertext, err := sealAESGCM(key, plaintext)
+	if err != nil {
+		return fmt.Errorf("加密备份失败: %w", err)
+	}
+	env.Data = base64.StdEncoding.EncodeToString(ciphertext)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("写入备份文件失败: %w", err)
+	}
+	return nil
+}
+
+// ImportBackup 从导出的加密备份恢复配置（当前配置会先自动备份）
+func (m *Manager) ImportBackup(path, password string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %w", err)
+	}
+
+	var env backupEnvelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Format != backupFormat {
+		return fmt.Errorf("不是有效的备份文件")
+	}
+	if env.Version > backupVersion {
+		return fmt.Errorf("备份文件版本 %d 过新，请升级客户端", env.Version)
+	}
+
+	key := m.encKey
+	switch env.KDF {
+	case "":
+	case backupKDFPBKDF2:
+		if password == "" {
+			return fmt.Errorf("该备份已设置密码")
+		}
+		salt, err := base64.StdEncoding.DecodeString(env.Salt)
+		if err != nil || env.Iterations <= 0 {
+			return fmt.Errorf("备份文件已损坏")
+		}
+		key = pbkdf2.Key([]byte(password), salt, env.Iterations, 32, sha256.New)
+	default:
+		return fmt.Errorf("不支持的密钥派生方式: %s", env.KDF)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return fmt.Errorf("备份文件已损坏")
+	}
+	plaintext, err := openAESGCM(key, ciphertext)
+	if err != nil {
+		if env.KDF == "" {
+			return fmt.Errorf("解密失败：备份来自使用不同配置密钥的环境")
+		}
+		return fmt.Errorf("密码错误或备份文件已损坏")
+	}
+
+	var config models.AppConfig
+	if err := json.Unmarshal(plaintext, &config); err != nil {
+		return fmt.Errorf("解析配置失败: %w", err)
+	}
+	m.validateAndFix(&config)
+
+	// 导入前备份当前配置
+	m.CreateBackup(BackupReasonRestore)
+
+	m.mu.Lock()
+	m.config = &config
+	m.mu.Unlock()
+
+	return m.Save()
+}
@@ -43,6 +43,7 @@ type Manager struct {
 	config   *models.AppConfig
 	filePath string
 	encKey   []byte
+	readOnly bool // 只读取配置文件，不写入（后台服务的配置由界面进程维护）
 }
 
 // NewManager 创建配置管理器
@@ -65,6 +66,13 @@ func NewManager(exeDir string) *Manager {
 	return m
 }
 
+// SetReadOnly 设置为只读：之后 Save、备份等写入配置目录的操作不再执行
+func (m *Manager) SetReadOnly(readOnly bool) {
+	m.mu.Lock()
+	m.readOnly = readOnly
+	m.mu.Unlock()
+}
+
 // =============================================================================
 // 加载配置
 // =============================================================================
@@ -74,6 +82,10 @@ func (m *Manager) Load() (*models.AppConfig, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if !m.readOnly {
+		m.encryptPlaintextBackups()
+	}
+
 	// 尝试按优先级加载配置
 	// 1. 加密配置文件
 	// 2. 明文JSON配置文件
@@ -250,8 +262,12 @@ func (m *Manager) loadLegacy(path string) (*models.AppConfig, error) {
 func (m *Manager) Save() error {
 	m.mu.RLock()
 	config := m.config
+	readOnly := m.readOnly
 	m.mu.RUnlock()
 
+	if readOnly {
+		return fmt.Errorf("配置文件为只读，由界面进程维护")
+	}
 	if config == nil {
 		return fmt.Errorf("配置为空")
 	}
@@ -339,8 +355,11 @@ func (m *Manager) AddNode(node models.NodeConfig) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if len(m.config.Nodes) >= models.MaxNodes {
-		return fmt.Errorf("节点数量已达上限")
+	if len(m.config.Nodes) >= m.config.NodeLimit() {
+		return fmt.Errorf("节点数量已达上限 (%d)", m.config.NodeLimit())
+	}
+	if err := models.ValidateIPv6Config(&node); err != nil {
+		return err
 	}
 
 	m.config.Nodes = append(m.config.Nodes, node)
@@ -368,7 +387,17 @@ func (m *Manager) DeleteNode(id string) error {
 
 // encrypt 使用AES-GCM加密
 func (m *Manager) encrypt(plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(m.encKey)
+	return sealAESGCM(m.encKey, plaintext)
+}
+
+// decrypt 使用AES-GCM解密
+func (m *Manager) decrypt(ciphertext []byte) ([]byte, error) {
+	return openAESGCM(m.encKey, ciphertext)
+}
+
+// sealAESGCM 使用指定密钥 AES-GCM 加密，输出 nonce+密文
+func sealAESGCM(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -387,9 +416,9 @@ func (m *Manager) encrypt(plaintext []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
-// decrypt 使用AES-GCM解密
-func (m *Manager) decrypt(ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(m.encKey)
+// openAESGCM 使用指定密钥解密 sealAESGCM 的输出
+func openAESGCM(key, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -417,11 +446,48 @@ func (m *Manager) decrypt(ciphertext []byte) ([]byte, error) {
 // 备份管理
 // =============================================================================
 
-// createBackup 创建配置备份
+// 备份原因，写入备份文件名（config_backup_<时间>[_<原因>].enc）
+const (
+	BackupReasonSave    = ""        // 每次保存前
+	BackupReasonDelete  = "delete"  // 删除节点前
+	BackupReasonPreset  = "preset"  // 应用规则预设前
+	BackupReasonRestore = "restore" // 恢复 / 导入配置前
+	BackupReasonSync    = "sync"    // 下载同步内容前
+	BackupReasonManual  = "manual"  // 手动创建
+	BackupReasonDaily   = "daily"   // 每日定时备份
+)
+
+const (
+	backupPrefix     = "config_backup_"
+	backupTimeLayout = "20060102_150405"
+)
+
+// BackupInfo 备份文件信息
+type BackupInfo struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"` // BackupReason*，保存前的备份为空
+	Time   int64  `json:"time"`   // Unix 秒
+	Size   int64  `json:"size"`
+}
+
+// createBackup 保存前备份当前配置文件
 func (m *Manager) createBackup() {
+	_, _ = m.CreateBackup(BackupReasonSave)
+}
+
+// CreateBackup 将当前配置文件备份到备份目录，返回备份文件名
+// 每种原因的备份分别按保留策略清理，保存前的备份不会挤掉变更前 / 每日备份
+func (m *Manager) CreateBackup(reason string) (string, error) {
+	m.mu.RLock()
+	readOnly := m.readOnly
+	m.mu.RUnlock()
+	if readOnly {
+		return "", fmt.Errorf("配置文件为只读，由界面进程维护")
+	}
+
 	backupDir := filepath.Join(m.exeDir, ConfigBackupDir)
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
 	}
 
 	// 检查源文件是否存在
@@ -429,48 +495,108 @@ func (m *Manager) createBackup() {
 	if !fileExists(srcPath) {
 		srcPath = filepath.Join(m.exeDir, ConfigFileName)
 		if !fileExists(srcPath) {
-			return
+			return "", fmt.Errorf("配置文件不存在")
 		}
 	}
 
 	// 创建备份文件名
-	timestamp := time.Now().Format("20060102_150405")
-	backupPath := filepath.Join(backupDir, fmt.Sprintf("config_backup_%s.enc", timestamp))
+	name := backupPrefix + time.Now().Format(backupTimeLayout)
+	if reason != BackupReasonSave {
+		name += "_" + reason
+	}
+	name += ".enc"
+	backupPath := filepath.Join(backupDir, name)
+	// 同一秒内多次备份时保留第一份（即最早的修改前状态）
+	if fileExists(backupPath) {
+		return name, nil
+	}
 
-	// 复制文件
+	// 复制文件（明文配置先加密，备份中不保留明文密钥）
 	data, err := os.ReadFile(srcPath)
 	if err != nil {
-		return
+		return "", fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	if isPlaintextConfig(data) {
+		if data, err = m.encryptForStorage(data); err != nil {
+			return "", fmt.Errorf("加密配置失败: %w", err)
+		}
+	}
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("写入备份失败: %w", err)
 	}
-	_ = os.WriteFile(backupPath, data, 0600)
 
 	// 清理旧备份
 	m.cleanOldBackups(backupDir)
+	return name, nil
 }
 
-// cleanOldBackups 清理旧备份
+// LastBackupTime 指定原因最近一次备份的时间，没有时为零值
+func (m *Manager) LastBackupTime(reason string) time.Time {
+	var last time.Time
+	for _, b := range m.ListBackupInfos() {
+		if b.Reason == reason && b.Time > last.Unix() {
+			last = time.Unix(b.Time, 0)
+		}
+	}
+	return last
+}
+
+// cleanOldBackups 清理旧备份：保存前的备份保留 MaxBackups 份，
+// 每日备份与变更前 / 手动备份按配置的保留策略分别清理
 func (m *Manager) cleanOldBackups(backupDir string) {
 	entries, err := os.ReadDir(backupDir)
 	if err != nil {
 		return
 	}
 
-	var backups []os.DirEntry
+	m.mu.RLock()
+	var policy models.BackupSettings
+	if m.config != nil {
+		policy = m.config.Backup
+	}
+	m.mu.RUnlock()
+
+	// 按类别分组（ReadDir 按文件名排序，文件名包含时间戳）
+	groups := make(map[string][]string)
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasPrefix(e.Name(), "config_backup_") {
-			backups = append(backups, e)
+		if e.IsDir() {
+			continue
+		}
+		if _, reason, ok := parseBackupName(e.Name()); ok {
+			kind := reason
+			if reason != BackupReasonSave && reason != BackupReasonDaily {
+				kind = BackupReasonManual
+			}
+			groups[kind] = append(groups[kind], e.Name())
 		}
 	}
 
-	// 保留最新的N个备份
-	if len(backups) <= MaxBackups {
-		return
+	limits := map[string]int{
+		BackupReasonSave:   MaxBackups,
+		BackupReasonDaily:  policy.DailyLimit(),
+		BackupReasonManual: policy.SnapshotLimit(),
 	}
+	for kind, names := range groups {
+		for i := 0; i < len(names)-limits[kind]; i++ {
+			os.Remove(filepath.Join(backupDir, names[i]))
+		}
+	}
+}
 
-	// 按时间排序（文件名包含时间戳）
-	for i := 0; i < len(backups)-MaxBackups; i++ {
-		os.Remove(filepath.Join(backupDir, backups[i].Name()))
+// parseBackupName 从备份文件名解析时间与原因（兼容旧版不带原因的文件名）
+func parseBackupName(name string) (time.Time, string, bool) {
+	if !strings.HasPrefix(name, backupPrefix) {
+		return time.Time{}, "", false
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), filepath.Ext(name))
+	if len(rest) < len(backupTimeLayout) {
+		return time.Time{}, "", false
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, rest[:len(backupTimeLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, "", false
 	}
+	return t, strings.TrimPrefix(rest[len(backupTimeLayout):], "_"), true
 }
 
 // RestoreBackup 从备份恢复
@@ -485,24 +611,16 @@ func (m *Manager) RestoreBackup(backupName string) error {
 		return err
 	}
 
-	// 解密备份
-	ciphertext, err := base64.StdEncoding.DecodeString(string(data))
+	config, err := m.decodeStored(data)
 	if err != nil {
-		return err
+		return fmt.Errorf("读取备份失败: %w", err)
 	}
 
-	plaintext, err := m.decrypt(ciphertext)
-	if err != nil {
-		return err
-	}
-
-	var config models.AppConfig
-	if err := json.Unmarshal(plaintext, &config); err != nil {
-		return err
-	}
+	// 恢复前备份当前配置
+	m.CreateBackup(BackupReasonRestore)
 
 	m.mu.Lock()
-	m.config = &config
+	m.config = config
 	m.mu.Unlock()
 
 	return m.Save()
@@ -518,7 +636,7 @@ func (m *Manager) ListBackups() []string {
 
 	var backups []string
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasPrefix(e.Name(), "config_backup_") {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) {
 			backups = append(backups, e.Name())
 		}
 	}
@@ -526,6 +644,76 @@ func (m *Manager) ListBackups() []string {
 	return backups
 }
 
+// ListBackupInfos 列出所有备份及其时间、原因（最新的在前）
+func (m *Manager) ListBackupInfos() []BackupInfo {
+	backupDir := filepath.Join(m.exeDir, ConfigBackupDir)
+	names := m.ListBackups()
+	infos := make([]BackupInfo, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		t, reason, ok := parseBackupName(names[i])
+		if !ok {
+			continue
+		}
+		info := BackupInfo{Name: names[i], Reason: reason, Time: t.Unix()}
+		if fi, err := os.Stat(filepath.Join(backupDir, names[i])); err == nil {
+			info.Size = fi.Size()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// encryptPlaintextBackups 加密旧版本留下的明文备份
+func (m *Manager) encryptPlaintextBackups() {
+	backupDir := filepath.Join(m.exeDir, ConfigBackupDir)
+	for _, name := range m.ListBackups() {
+		path := filepath.Join(backupDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil || !isPlaintextConfig(data) {
+			continue
+		}
+		if enc, err := m.encryptForStorage(data); err == nil {
+			_ = os.WriteFile(path, enc, 0600)
+		}
+	}
+}
+
+// encryptForStorage 按配置文件格式加密（AES-GCM + Base64）
+func (m *Manager) encryptForStorage(plaintext []byte) ([]byte, error) {
+	ciphertext, err := m.encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(ciphertext)), nil
+}
+
+// decodeStored 解析配置文件格式的数据（兼容旧版明文备份）
+func (m *Manager) decodeStored(data []byte) (*models.AppConfig, error) {
+	plaintext := data
+	if !isPlaintextConfig(data) {
+		ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("Base64解码失败: %w", err)
+		}
+		if plaintext, err = m.decrypt(ciphertext); err != nil {
+			return nil, fmt.Errorf("解密失败: %w", err)
+		}
+	}
+
+	var config models.AppConfig
+	if err := json.Unmarshal(plaintext, &config); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	m.validateAndFix(&config)
+	return &config, nil
+}
+
+// isPlaintextConfig 数据是否为明文 JSON 配置
+func isPlaintextConfig(data []byte) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return strings.HasPrefix(trimmed, "{")
+}
+
 // =============================================================================
 // 辅助函数
 // =============================================================================
@@ -536,12 +724,13 @@ func (m *Manager) createDefaultConfig() *models.AppConfig {
 		Nodes: []models.NodeConfig{
 			models.NewDefaultNode("默认节点"),
 		},
-		AutoStart:        false,
-		MinimizeToTray:   true,
-		Theme:            "system",
-		Language:         "zh-CN",
-		GlobalDNSMode:    models.DNSModeFakeIP,
-		TUNInterfaceName: "XlinkTUN",
+		AutoStart:         false,
+		MinimizeToTray:    true,
+		Theme:             "system",
+		Language:          "zh-CN",
+		GlobalDNSMode:     models.DNSModeFakeIP,
+		TUNInterfaceName:  "XlinkTUN",
+		GeoDataUpdateDays: 7,
 	}
 }
 
@@ -554,6 +743,30 @@ func (m *Manager) validateAndFix(config *models.AppConfig) {
 		}
 	}
 
+	// 全局 IPv6 开关冲突时以禁用为准
+	if models.ValidateGlobalIPv6Settings(config) != nil {
+		config.GlobalEnableIPv6 = false
+		config.GlobalPreferIPv6 = false
+	}
+
+	// 规则组：补齐ID
+	groupIDs := make(map[string]bool)
+	for i := range config.RuleGroups {
+		group := &config.RuleGroups[i]
+		if group.ID == "" {
+			group.ID = models.GenerateUUID()
+		}
+		if group.Name == "" {
+			group.Name = fmt.Sprintf("规则组 %d", i+1)
+		}
+		for j := range group.Rules {
+			if group.Rules[j].ID == "" {
+				group.Rules[j].ID = models.GenerateUUID()
+			}
+		}
+		groupIDs[group.ID] = true
+	}
+
 	// 验证每个节点
 	for i := range config.Nodes {
 		node := &config.Nodes[i]
@@ -580,6 +793,44 @@ func (m *Manager) validateAndFix(config *models.AppConfig) {
 		if len(node.Rules) == 0 && node.RulesStr != "" {
 			node.Rules = parseRulesString(node.RulesStr)
 		}
+
+		// 移除已不存在的规则组引用
+		refs := node.RuleGroupIDs[:0]
+		for _, id := range node.RuleGroupIDs {
+			if groupIDs[id] {
+				refs = append(refs, id)
+			}
+		}
+		node.RuleGroupIDs = refs
+
+		// IPv6 开关：未单独设置的节点跟随全局，冲突的开关以禁用为准
+		models.ApplyGlobalIPv6Settings(node, config)
+		models.ReconcileIPv6Config(node)
+	}
+
+	// 定时任务：补齐ID
+	for i := range config.Schedules {
+		if config.Schedules[i].ID == "" {
+			config.Schedules[i].ID = models.GenerateUUID()
+		}
+	}
+
+	// 出口策略：指定的节点已不存在时改为经当前节点
+	if config.EgressMode < models.EgressDirect || config.EgressMode > models.EgressNode {
+		config.EgressMode = models.EgressDirect
+	}
+	if config.EgressMode == models.EgressNode {
+		found := false
+		for i := range config.Nodes {
+			if config.Nodes[i].ID == config.EgressNodeID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			config.EgressMode = models.EgressActiveNode
+			config.EgressNodeID = ""
+		}
 	}
 
 	// 验证主题
@@ -680,42 +931,95 @@ func (m *Manager) ExportNode(nodeID string) (string, error) {
 		return "", fmt.Errorf("节点不存在: %s", nodeID)
 	}
 
-	return buildXlinkURI(node), nil
+	// 分享链接中没有规则组的概念，导出展开后的规则
+	exported := *node
+	exported.Rules = models.ResolveNodeRules(node, m.config.RuleGroups)
+	return buildXlinkURI(&exported), nil
 }
 
-// ImportNodes 从xlink://链接导入节点
+// ImportNodes 从xlink://链接或第三方分享链接导入节点，返回实际添加的节点
 func (m *Manager) ImportNodes(text string) ([]models.NodeConfig, error) {
-	lines := strings.Split(text, "\n")
-	var imported []models.NodeConfig
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if !strings.HasPrefix(line, "xlink://") {
-			continue
-		}
-
-		node, err := parseXlinkURI(line)
-		if err != nil {
-			continue
-		}
+	imported, _, err := m.ImportNodesWithReport(text)
+	return imported, err
+}
 
-		imported = append(imported, *node)
+// ImportNodesWithReport 导入节点，返回实际添加的节点，以及每个第三方链接被忽略的字段和超出上限跳过的数量
+// 支持 xlink:// 以及 vmess:// vless:// trojan:// ss://
+func (m *Manager) ImportNodesWithReport(text string) ([]models.NodeConfig, *ImportResult, error) {
+	imported, issues := parseNodeLinks(text)
+	result := &ImportResult{Issues: issues}
+	if len(imported) == 0 {
+		return nil, result, fmt.Errorf("未找到有效的节点链接")
 	}
 
-	if len(imported) == 0 {
-		return nil, fmt.Errorf("未找到有效的xlink://链接")
+	result.Count = m.AddNodes(imported)
+	if result.Count < len(imported) {
+		result.Skipped = len(imported) - result.Count
+		result.Limit = m.NodeLimit()
+	}
+	if result.Count == 0 {
+		return nil, result, fmt.Errorf("节点数量已达上限 (%d)", result.Limit)
 	}
+	return imported[:result.Count], result, nil
+}
 
-	// 添加到配置
+// NodeLimit 当前配置的节点数量上限
+func (m *Manager) NodeLimit() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.NodeLimit()
+}
+
+// AddNodes 将导入的节点添加到配置（超出节点数量上限的部分忽略），返回实际添加的数量
+func (m *Manager) AddNodes(nodes []models.NodeConfig) int {
 	m.mu.Lock()
-	for _, node := range imported {
-		if len(m.config.Nodes) < models.MaxNodes {
-			m.config.Nodes = append(m.config.Nodes, node)
+	defer m.mu.Unlock()
+
+	added := 0
+	limit := m.config.NodeLimit()
+	for _, node := range nodes {
+		if len(m.config.Nodes) >= limit {
+			break
 		}
+		models.NormalizeIPv6Config(&node)
+		m.config.Nodes = append(m.config.Nodes, node)
+		added++
 	}
-	m.mu.Unlock()
+	return added
+}
 
-	return imported, nil
+// parseNodeLinks 逐行解析 xlink:// 和第三方分享链接
+func parseNodeLinks(text string) ([]models.NodeConfig, []ImportIssue) {
+	var imported []models.NodeConfig
+	var issues []ImportIssue
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "xlink://"):
+			node, err := parseXlinkURI(line)
+			if err != nil {
+				continue
+			}
+			imported = append(imported, *node)
+
+		case isShareLink(line):
+			node, issue, err := parseShareLink(line)
+			if err != nil {
+				issues = append(issues, ImportIssue{
+					Scheme: strings.SplitN(line, "://", 2)[0],
+					Error:  err.Error(),
+				})
+				continue
+			}
+			imported = append(imported, *node)
+			if len(issue.Unsupported) > 0 {
+				issues = append(issues, *issue)
+			}
+		}
+	}
+	return imported, issues
 }
 
 // buildXlinkURI 构建xlink://链接
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点去重
+// =============================================================================
+
+// NodeFingerprint 计算节点的有效端点指纹
+// 服务器池按集合比较（忽略顺序、分隔符和大小写），Token 为空时以 SecretKey 代替
+func NodeFingerprint(node *models.NodeConfig) string {
+	set := make(map[string]bool)
+	var list []string
+	for _, s := range models.SplitServers(node.Server) {
+		s = strings.ToLower(s)
+		if !set[s] {
+			set[s] = true
+			list = append(list, s)
+		}
+	}
+	sort.Strings(list)
+
+	token := node.Token
+	if token == "" {
+		token = node.SecretKey
+	}
+
+	return strings.Join([]string{
+		strings.Join(list, ";"),
+		strings.TrimSpace(node.IP),
+		token,
+		strings.TrimSpace(node.FallbackIP),
+		strings.TrimSpace(node.Socks5),
+		node.ChainNodeID,
+	}, "|")
+}
+
+// FindDuplicates 查找重复节点
+// isPreferred 用于挑选保留节点（如运行中的节点），为 nil 时保留最早出现的节点
+func FindDuplicates(nodes []models.NodeConfig, isPreferred func(id string) bool) []models.DuplicateGroup {
+	groups := make(map[string][]int)
+	var order []string
+
+	for i := range nodes {
+		key := NodeFingerprint(&nodes[i])
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	var result []models.DuplicateGroup
+	for _, key := range order {
+		idx := groups[key]
+		if len(idx) < 2 {
+			continue
+		}
+
+		survivor := idx[0]
+		if isPreferred != nil {
+			for _, i := range idx {
+				if isPreferred(nodes[i].ID) {
+					survivor = i
+					break
+				}
+			}
+		}
+
+		group := models.DuplicateGroup{
+			Key:        key,
+			SurvivorID: nodes[survivor].ID,
+		}
+		for _, i := range idx {
+			group.Names = append(group.Names, nodes[i].Name)
+			if i != survivor {
+				group.DuplicateIDs = append(group.DuplicateIDs, nodes[i].ID)
+			}
+		}
+		result = append(result, group)
+	}
+
+	return result
+}
+
+// MergeRules 将重复节点中保留节点没有的规则和规则组引用追加到保留节点（规则数量不超过 maxRules）
+func MergeRules(survivor *models.NodeConfig, duplicates []models.NodeConfig, maxRules int) int {
+	existing := make(map[string]bool)
+	for _, r := range survivor.Rules {
+		existing[r.Type+r.Match+","+r.Target] = true
+	}
+
+	added := 0
+	for _, dup := range duplicates {
+		for _, r := range dup.Rules {
+			key := r.Type + r.Match + "," + r.Target
+			if existing[key] || len(survivor.Rules) >= maxRules {
+				continue
+			}
+			existing[key] = true
+			r.ID = models.GenerateUUID()
+			survivor.Rules = append(survivor.Rules, r)
+			added++
+		}
+		for _, id := range dup.RuleGroupIDs {
+			if !containsGroupID(survivor.RuleGroupIDs, id) {
+				survivor.RuleGroupIDs = append(survivor.RuleGroupIDs, id)
+			}
+		}
+	}
+	return added
+}
+
+func containsGroupID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
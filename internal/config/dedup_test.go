@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"xlink-wails/internal/models"
+)
+
+func TestNodeFingerprint(t *testing.T) {
+	base := models.NodeConfig{Server: "a.example.com:443;b.example.com:443", Token: "t"}
+	tests := []struct {
+		name string
+		node models.NodeConfig
+		same bool
+	}{
+		{"reordered and upper-case pool", models.NodeConfig{Server: "B.example.com:443, a.example.com:443", Token: "t"}, true},
+		{"duplicated server", models.NodeConfig{Server: "a.example.com:443;b.example.com:443;a.example.com:443", Token: "t"}, true},
+		{"secret key instead of token", models.NodeConfig{Server: base.Server, SecretKey: "t"}, true},
+		{"different token", models.NodeConfig{Server: base.Server, Token: "u"}, false},
+		{"different fixed IP", models.NodeConfig{Server: base.Server, Token: "t", IP: "1.1.1.1"}, false},
+		{"different chain", models.NodeConfig{Server: base.Server, Token: "t", ChainNodeID: "c"}, false},
+		{"subset of pool", models.NodeConfig{Server: "a.example.com:443", Token: "t"}, false},
+	}
+	want := NodeFingerprint(&base)
+	for _, tt := range tests {
+		if got := NodeFingerprint(&tt.node) == want; got != tt.same {
+			t.Errorf("%s: same fingerprint = %v, want %v", tt.name, got, tt.same)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	nodes := []models.NodeConfig{
+		{ID: "1", Name: "A", Server: "a.com:443", Token: "t"},
+		{ID: "2", Name: "B", Server: "b.com:443", Token: "t"},
+		{ID: "3", Name: "A2", Server: "A.com:443", Token: "t"},
+		{ID: "4", Name: "A3", Server: "a.com:443", SecretKey: "t"},
+		{ID: "5", Name: "B2", Server: "b.com:443", Token: "t"},
+	}
+	tests := []struct {
+		name      string
+		preferred string
+		want      [][]string // 每组：保留节点 ID + 重复节点 ID
+	}{
+		{"earliest survives", "", [][]string{{"1", "3", "4"}, {"2", "5"}}},
+		{"preferred survives", "4", [][]string{{"4", "1", "3"}, {"2", "5"}}},
+	}
+	for _, tt := range tests {
+		var isPreferred func(string) bool
+		if tt.preferred != "" {
+			isPreferred = func(id string) bool { return id == tt.preferred }
+		}
+		var got [][]string
+		for _, g := range FindDuplicates(nodes, isPreferred) {
+			got = append(got, append([]string{g.SurvivorID}, g.DuplicateIDs...))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: groups = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := FindDuplicates(nodes[:2], nil); len(got) != 0 {
+		t.Errorf("unique nodes: groups = %v, want none", got)
+	}
+}
+
+func TestMergeRules(t *testing.T) {
+	rule := func(typ, match, target string) models.RoutingRule {
+		return models.RoutingRule{Type: typ, Match: match, Target: target}
+	}
+	tests := []struct {
+		name       string
+		survivor   []models.RoutingRule
+		duplicates [][]models.RoutingRule
+		maxRules   int
+		wantAdded  int
+		wantRules  int
+	}{
+		{"adds missing rules", []models.RoutingRule{rule("domain", "a.com", "direct")},
+			[][]models.RoutingRule{{rule("domain", "a.com", "direct"), rule("domain", "b.com", "proxy")}}, 10, 1, 2},
+		{"dedups across duplicates", nil,
+			[][]models.RoutingRule{{rule("ip", "1.1.1.1", "block")}, {rule("ip", "1.1.1.1", "block")}}, 10, 1, 1},
+		{"respects limit", []models.RoutingRule{rule("domain", "a.com", "direct")},
+			[][]models.RoutingRule{{rule("domain", "b.com", "proxy"), rule("domain", "c.com", "proxy")}}, 2, 1, 2},
+	}
+	for _, tt := range tests {
+		survivor := models.NodeConfig{Rules: tt.survivor, RuleGroupIDs: []string{"g1"}}
+		var dups []models.NodeConfig
+		for _, rules := range tt.duplicates {
+			dups = append(dups, models.NodeConfig{Rules: rules, RuleGroupIDs: []string{"g1", "g2"}})
+		}
+		added := MergeRules(&survivor, dups, tt.maxRules)
+		if added != tt.wantAdded || len(survivor.Rules) != tt.wantRules {
+			t.Errorf("%s: added %d, rules %d; want %d, %d", tt.name, added, len(survivor.Rules), tt.wantAdded, tt.wantRules)
+		}
+		if !reflect.DeepEqual(survivor.RuleGroupIDs, []string{"g1", "g2"}) {
+			t.Errorf("%s: RuleGroupIDs = %v", tt.name, survivor.RuleGroupIDs)
+		}
+	}
+}
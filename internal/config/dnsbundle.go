@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// DNS 配置包（只含 DNS 设置，不含节点与凭据，可在多台电脑间共享）
+// =============================================================================
+
+const (
+	DNSBundleType    = "xlink-dns"
+	DNSBundleVersion = 1
+)
+
+// DNSBundle DNS 配置包
+type DNSBundle struct {
+	Type      string             `json:"type"`    // 固定为 DNSBundleType
+	Version   int                `json:"version"` // DNSBundleVersion
+	CreatedAt time.Time          `json:"created_at"`
+	DNSMode   int                `json:"dns_mode"` // 全局 DNS 模式
+	Upstream  []string           `json:"upstream"` // 本机 DNS 服务的上游
+	DNS       models.DNSSettings `json:"dns"`      // Fake-IP 过滤、hosts、上游预设
+}
+
+// NewDNSBundle 从当前配置生成 DNS 配置包
+func NewDNSBundle(cfg *models.AppConfig) *DNSBundle {
+	return &DNSBundle{
+		Type:      DNSBundleType,
+		Version:   DNSBundleVersion,
+		CreatedAt: time.Now(),
+		DNSMode:   cfg.GlobalDNSMode,
+		Upstream:  append([]string(nil), cfg.LocalDNS.Upstream...),
+		DNS:       cfg.DNS,
+	}
+}
+
+// ParseDNSBundle 解析 DNS 配置包，只检查格式与版本，内容由调用方校验
+func ParseDNSBundle(data []byte) (*DNSBundle, error) {
+	var b DNSBundle
+	if err := json.Unmarshal(data, &b); err != nil || b.Type != DNSBundleType {
+		return nil, i18n.Errorf("不是有效的 DNS 配置包")
+	}
+	if b.Version > DNSBundleVersion {
+		return nil, i18n.Errorf("DNS 配置包版本过新 (%d)，请先升级程序", b.Version)
+	}
+	if b.DNSMode < models.DNSModeStandard || b.DNSMode > models.DNSModeTUN {
+		return nil, i18n.Errorf("无效的 DNS 模式: %d", b.DNSMode)
+	}
+	return &b, nil
+}
+
+// Apply 将配置包写入配置（本机 DNS 服务的开关不变）
+func (b *DNSBundle) Apply(cfg *models.AppConfig) {
+	cfg.GlobalDNSMode = b.DNSMode
+	cfg.LocalDNS.Upstream = b.Upstream
+	cfg.DNS = b.DNS
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// =============================================================================
+// 本地密码（只读模式解锁）与密码加密
+// =============================================================================
+
+const (
+	passwordIterations = 200000
+	passwordSaltSize   = 16
+)
+
+// HashPassword 以随机盐派生密码哈希（PBKDF2-HMAC-SHA256），返回 Base64 编码的哈希与盐
+func HashPassword(password string) (hash, salt string, err error) {
+	s := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(s); err != nil {
+		return "", "", err
+	}
+	key := pbkdf2.Key([]byte(password), s, passwordIterations, 32, sha256.New)
+	return base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(s), nil
+}
+
+// VerifyPassword 校验密码与 HashPassword 生成的哈希是否一致
+func VerifyPassword(password, hash, salt string) bool {
+	want, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil {
+		return false
+	}
+	s, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return false
+	}
+	got := pbkdf2.Key([]byte(password), s, passwordIterations, len(want), sha256.New)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// SealedData 以密码加密的数据（PBKDF2-HMAC-SHA256 派生密钥，AES-256-GCM 加密）
+type SealedData struct {
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Data       string `json:"data"` // Base64(nonce + 密文)
+}
+
+// SealWithPassword 以密码加密数据（每次使用新的随机盐）
+func SealWithPassword(plaintext []byte, password string) (*SealedData, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	ciphertext, err := sealAESGCM(pbkdf2.Key([]byte(password), salt, passwordIterations, 32, sha256.New), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &SealedData{
+		Iterations: passwordIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Data:       base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// OpenWithPassword 解密 SealWithPassword 加密的数据
+func OpenWithPassword(s *SealedData, password string) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(s.Salt)
+	if err != nil || s.Iterations <= 0 || s.Iterations > 10*passwordIterations {
+		return nil, fmt.Errorf("数据已损坏")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(s.Data)
+	if err != nil {
+		return nil, fmt.Errorf("数据已损坏")
+	}
+	plaintext, err := openAESGCM(pbkdf2.Key([]byte(password), salt, s.Iterations, 32, sha256.New), ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("密码错误或数据已损坏")
+	}
+	return plaintext, nil
+}
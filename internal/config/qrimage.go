@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // 注册 GIF 解码
+	_ "image/jpeg" // 注册 JPEG 解码
+	_ "image/png"  // 注册 PNG 解码
+	"io"
+	"os"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	multiqr "github.com/makiuchi-d/gozxing/multi/qrcode"
+	"github.com/makiuchi-d/gozxing/qrcode"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 从二维码图片导入
+// =============================================================================
+
+const (
+	// QRImageMaxSize 二维码图片文件大小上限
+	QRImageMaxSize = 20 << 20
+	// qrImageMaxPixels 图片像素数上限，避免解码超大图片占用过多内存
+	qrImageMaxPixels = 50 << 20
+)
+
+// QRImport 从二维码图片导入的结果
+type QRImport struct {
+	Codes   int                 `json:"codes"` // 识别出的二维码数量
+	Nodes   []models.NodeConfig `json:"nodes"` // 实际导入的节点
+	Count   int                 `json:"count"`
+	Skipped int                 `json:"skipped,omitempty"` // 超出节点数量上限未导入的数量
+	Limit   int                 `json:"limit,omitempty"`
+	Issues  []ImportIssue       `json:"issues,omitempty"`
+}
+
+// DecodeQRImage 识别图片（PNG / JPEG / GIF）中的全部二维码，返回各二维码的文本
+func DecodeQRImage(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, QRImageMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取图片失败: %w", err)
+	}
+	if len(data) > QRImageMaxSize {
+		return nil, fmt.Errorf("图片超过大小上限")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别图片格式，仅支持 PNG / JPEG / GIF")
+	}
+	if cfg.Width*cfg.Height > qrImageMaxPixels {
+		return nil, fmt.Errorf("图片尺寸过大")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别图片格式，仅支持 PNG / JPEG / GIF")
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil, fmt.Errorf("图片中未找到二维码")
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+	results, err := multiqr.NewQRCodeMultiReader().DecodeMultiple(bitmap, hints)
+	if err != nil || len(results) == 0 {
+		// 多码识别失败时按单个二维码再试一次（对模糊或倾斜的截图更宽松）
+		result, err := qrcode.NewQRCodeReader().Decode(bitmap, hints)
+		if err != nil {
+			return nil, fmt.Errorf("图片中未找到二维码")
+		}
+		results = []*gozxing.Result{result}
+	}
+
+	texts := make([]string, 0, len(results))
+	seen := make(map[string]bool)
+	for _, r := range results {
+		text := strings.TrimSpace(r.GetText())
+		if text != "" && !seen[text] {
+			seen[text] = true
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("图片中未找到二维码")
+	}
+	return texts, nil
+}
+
+// ImportQRImage 识别图片中的二维码（分享链接或 Base64 订阅内容）并导入其中的节点
+func (m *Manager) ImportQRImage(path string) (*QRImport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取图片失败: %w", err)
+	}
+	defer file.Close()
+
+	texts, err := DecodeQRImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QRImport{Codes: len(texts)}
+	var nodes []models.NodeConfig
+	for _, text := range texts {
+		parsed, err := ParseImport([]byte(text))
+		if parsed != nil {
+			nodes = append(nodes, parsed.Nodes...)
+			result.Issues = append(result.Issues, parsed.Issues...)
+		}
+		if err != nil && (parsed == nil || len(parsed.Issues) == 0) {
+			result.Issues = append(result.Issues, ImportIssue{Name: qrTextPreview(text), Scheme: "qr", Error: err.Error()})
+		}
+	}
+	if len(nodes) == 0 {
+		return result, fmt.Errorf("二维码中没有可导入的节点")
+	}
+
+	result.Count = m.AddNodes(nodes)
+	result.Nodes = nodes[:result.Count]
+	if result.Count < len(nodes) {
+		result.Skipped = len(nodes) - result.Count
+		result.Limit = m.NodeLimit()
+	}
+	if result.Count == 0 {
+		return result, fmt.Errorf("节点数量已达上限 (%d)", result.Limit)
+	}
+	return result, nil
+}
+
+// qrTextPreview 二维码文本的前 32 个字符，用于提示哪个二维码无法导入
+func qrTextPreview(text string) string {
+	if r := []rune(text); len(r) > 32 {
+		return string(r[:32]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 第三方分享链接导入 (vmess / vless / trojan / ss)
+// =============================================================================
+
+// ImportIssue 导入时无法映射到 NodeConfig 的字段
+type ImportIssue struct {
+	Name        string   `json:"name"`
+	Scheme      string   `json:"scheme"`
+	Unsupported []string `json:"unsupported,omitempty"` // 被忽略的字段 (key=value)
+	Error       string   `json:"error,omitempty"`       // 解析失败原因
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Count   int           `json:"count"`
+	Skipped int           `json:"skipped,omitempty"` // 超出节点数量上限未导入的数量
+	Limit   int           `json:"limit,omitempty"`   // 有节点被跳过时的节点数量上限
+	Issues  []ImportIssue `json:"issues,omitempty"`
+}
+
+// shareLinkSchemes 支持的分享链接前缀
+var shareLinkSchemes = []string{"vmess://", "vless://", "trojan://", "ss://"}
+
+// isShareLink 判断是否为支持的第三方分享链接
+func isShareLink(line string) bool {
+	for _, p := range shareLinkSchemes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseShareLink 解析第三方分享链接
+// 服务器地址映射到 Server，UUID/密码映射到 Token，其余传输层参数无法对应时记录在 ImportIssue 中
+func parseShareLink(link string) (*models.NodeConfig, *ImportIssue, error) {
+	switch {
+	case strings.HasPrefix(link, "vmess://"):
+		return parseVmessLink(link)
+	case strings.HasPrefix(link, "vless://"), strings.HasPrefix(link, "trojan://"):
+		return parseURLStyleLink(link)
+	case strings.HasPrefix(link, "ss://"):
+		return parseSSLink(link)
+	}
+	return nil, nil, fmt.Errorf("不支持的链接格式")
+}
+
+// parseVmessLink 解析 vmess://base64(json)
+func parseVmessLink(link string) (*models.NodeConfig, *ImportIssue, error) {
+	data, err := decodeBase64Loose(strings.TrimPrefix(link, "vmess://"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("vmess 链接解码失败: %w", err)
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, nil, fmt.Errorf("vmess 链接解析失败: %w", err)
+	}
+
+	str := func(key string) string {
+		switch val := v[key].(type) {
+		case string:
+			return val
+		case float64:
+			return fmt.Sprintf("%d", int(val))
+		}
+		return ""
+	}
+
+	host, port, id := str("add"), str("port"), str("id")
+	if host == "" || port == "" || id == "" {
+		return nil, nil, fmt.Errorf("vmess 链接缺少地址、端口或ID")
+	}
+
+	node := models.NewDefaultNode(str("ps"))
+	node.Server = net.JoinHostPort(host, port)
+	node.Token = id
+
+	issue := &ImportIssue{Name: node.Name, Scheme: "vmess"}
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch k {
+		case "v", "ps", "add", "port", "id":
+			continue
+		}
+		if val := str(k); val != "" && val != "0" && val != "none" && val != "auto" {
+			issue.Unsupported = append(issue.Unsupported, k+"="+val)
+		}
+	}
+
+	finishImportedNode(&node, issue)
+	return &node, issue, nil
+}
+
+// parseURLStyleLink 解析 vless://uuid@host:port?...#name 和 trojan://password@host:port?...#name
+func parseURLStyleLink(link string) (*models.NodeConfig, *ImportIssue, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s 链接解析失败: %w", strings.SplitN(link, ":", 2)[0], err)
+	}
+	if u.User == nil || u.Hostname() == "" || u.Port() == "" {
+		return nil, nil, fmt.Errorf("%s 链接缺少凭据、地址或端口", u.Scheme)
+	}
+
+	node := models.NewDefaultNode(u.Fragment)
+	node.Server = net.JoinHostPort(u.Hostname(), u.Port())
+	node.Token = u.User.Username()
+
+	issue := &ImportIssue{Name: node.Name, Scheme: u.Scheme}
+	issue.Unsupported = unsupportedQuery(u.Query(), "encryption")
+
+	finishImportedNode(&node, issue)
+	return &node, issue, nil
+}
+
+// parseSSLink 解析 Shadowsocks 链接
+// SIP002: ss://base64(method:password)@host:port#name
+// 旧格式: ss://base64(method:password@host:port)#name
+func parseSSLink(link string) (*models.NodeConfig, *ImportIssue, error) {
+	body := strings.TrimPrefix(link, "ss://")
+
+	name := ""
+	if idx := strings.LastIndex(body, "#"); idx != -1 {
+		name, _ = url.PathUnescape(body[idx+1:])
+		body = body[:idx]
+	}
+
+	var query url.Values
+	if idx := strings.Index(body, "?"); idx != -1 {
+		query, _ = url.ParseQuery(body[idx+1:])
+		body = strings.TrimSuffix(body[:idx], "/")
+	}
+
+	var userInfo, hostPort string
+	if idx := strings.LastIndex(body, "@"); idx != -1 {
+		userInfo, hostPort = body[:idx], body[idx+1:]
+		if decoded, err := decodeBase64Loose(userInfo); err == nil {
+			userInfo = string(decoded)
+		} else {
+			userInfo, _ = url.PathUnescape(userInfo)
+		}
+	} else {
+		decoded, err := decodeBase64Loose(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ss 链接解码失败: %w", err)
+		}
+		plain := string(decoded)
+		idx := strings.LastIndex(plain, "@")
+		if idx == -1 {
+			return nil, nil, fmt.Errorf("ss 链接格式错误")
+		}
+		userInfo, hostPort = plain[:idx], plain[idx+1:]
+	}
+
+	parts := strings.SplitN(userInfo, ":", 2)
+	if len(parts) != 2 || hostPort == "" {
+		return nil, nil, fmt.Errorf("ss 链接缺少加密方式、密码或地址")
+	}
+
+	node := models.NewDefaultNode(name)
+	node.Server = hostPort
+	node.Token = parts[1]
+
+	issue := &ImportIssue{Name: node.Name, Scheme: "ss"}
+	issue.Unsupported = append(issue.Unsupported, "method="+parts[0])
+	issue.Unsupported = append(issue.Unsupported, unsupportedQuery(query)...)
+
+	finishImportedNode(&node, issue)
+	return &node, issue, nil
+}
+
+// unsupportedQuery 列出无法映射的查询参数
+func unsupportedQuery(query url.Values, ignore ...string) []string {
+	skip := make(map[string]bool)
+	for _, k := range ignore {
+		skip[k] = true
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var result []string
+	for _, k := range keys {
+		if skip[k] {
+			continue
+		}
+		if val := query.Get(k); val != "" && val != "none" {
+			result = append(result, k+"="+val)
+		}
+	}
+	return result
+}
+
+// finishImportedNode 补全导入节点的公共字段
+func finishImportedNode(node *models.NodeConfig, issue *ImportIssue) {
+	if node.Name == "" {
+		node.Name = node.Server
+		issue.Name = node.Name
+	}
+	node.SecretKey = ""
+	node.ID = models.GenerateUUID()
+}
+
+// decodeBase64Loose 兼容标准/URL安全、有无填充的 Base64
+func decodeBase64Loose(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimRight(s, "=")
+	if data, err := base64.RawStdEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestParseShareLink(t *testing.T) {
+	vmess := "vmess://" + base64.StdEncoding.EncodeToString([]byte(
+		`{"v":"2","ps":"香港 01","add":"hk.example.com","port":443,"id":"uuid-1","aid":"0","net":"ws","tls":"tls","scy":"auto"}`))
+	ssLegacy := "ss://" + base64.RawURLEncoding.EncodeToString([]byte("aes-256-gcm:pass@ss.example.com:8388")) + "#Legacy"
+	ssSIP002 := "ss://" + base64.RawURLEncoding.EncodeToString([]byte("chacha20-ietf-poly1305:pw")) +
+		"@[2001:db8::1]:8388/?plugin=obfs-local#%E6%97%A5%E6%9C%AC"
+
+	tests := []struct {
+		name        string
+		link        string
+		server      string
+		token       string
+		nodeName    string
+		scheme      string
+		unsupported []string
+	}{
+		{
+			name: "vmess", link: vmess,
+			server: "hk.example.com:443", token: "uuid-1", nodeName: "香港 01", scheme: "vmess",
+			unsupported: []string{"net=ws", "tls=tls"},
+		},
+		{
+			name: "vless", link: "vless://uuid-2@v.example.com:8443?encryption=none&security=reality&type=tcp#VL",
+			server: "v.example.com:8443", token: "uuid-2", nodeName: "VL", scheme: "vless",
+			unsupported: []string{"security=reality", "type=tcp"},
+		},
+		{
+			name: "trojan without name", link: "trojan://secret@t.example.com:443",
+			server: "t.example.com:443", token: "secret", nodeName: "t.example.com:443", scheme: "trojan",
+		},
+		{
+			name: "ss legacy", link: ssLegacy,
+			server: "ss.example.com:8388", token: "pass", nodeName: "Legacy", scheme: "ss",
+			unsupported: []string{"method=aes-256-gcm"},
+		},
+		{
+			name: "ss SIP002", link: ssSIP002,
+			server: "[2001:db8::1]:8388", token: "pw", nodeName: "日本", scheme: "ss",
+			unsupported: []string{"method=chacha20-ietf-poly1305", "plugin=obfs-local"},
+		},
+	}
+	for _, tt := range tests {
+		node, issue, err := parseShareLink(tt.link)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if node.Server != tt.server || node.Token != tt.token || node.Name != tt.nodeName {
+			t.Errorf("%s: node = {Server:%q Token:%q Name:%q}, want {%q %q %q}",
+				tt.name, node.Server, node.Token, node.Name, tt.server, tt.token, tt.nodeName)
+		}
+		if node.ID == "" || node.SecretKey != "" {
+			t.Errorf("%s: ID = %q, SecretKey = %q", tt.name, node.ID, node.SecretKey)
+		}
+		if issue.Scheme != tt.scheme || issue.Name != tt.nodeName || !reflect.DeepEqual(issue.Unsupported, tt.unsupported) {
+			t.Errorf("%s: issue = %+v, want scheme %q unsupported %v", tt.name, issue, tt.scheme, tt.unsupported)
+		}
+	}
+}
+
+func TestParseShareLinkErrors(t *testing.T) {
+	tests := []string{
+		"vmess://not-base64!",
+		"vmess://" + base64.StdEncoding.EncodeToString([]byte(`{"add":"a.com","port":443}`)),
+		"vless://v.example.com:443",
+		"trojan://secret@t.example.com",
+		"ss://" + base64.RawURLEncoding.EncodeToString([]byte("no-at-sign")),
+		"ss://" + base64.RawURLEncoding.EncodeToString([]byte("methodonly")) + "@s.example.com:8388",
+		"http://example.com",
+	}
+	for _, link := range tests {
+		if _, _, err := parseShareLink(link); err == nil {
+			t.Errorf("parseShareLink(%q): expected error", link)
+		}
+	}
+}
+
+func TestDecodeBase64Loose(t *testing.T) {
+	want := "a?b>c"
+	for _, s := range []string{
+		base64.StdEncoding.EncodeToString([]byte(want)),
+		base64.RawStdEncoding.EncodeToString([]byte(want)),
+		base64.URLEncoding.EncodeToString([]byte(want)),
+		" " + base64.RawURLEncoding.EncodeToString([]byte(want)) + "\n",
+	} {
+		got, err := decodeBase64Loose(s)
+		if err != nil || string(got) != want {
+			t.Errorf("decodeBase64Loose(%q) = %q, %v", s, got, err)
+		}
+	}
+}
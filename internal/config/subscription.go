@@ -0,0 +1,465 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 订阅内容识别与导入 (Base64 订阅 / Clash YAML / sing-box JSON / 链接列表)
+// =============================================================================
+
+// 订阅内容格式
+const (
+	ImportFormatLinks   = "links"    // 每行一个分享链接
+	ImportFormatBase64  = "base64"   // Base64 编码的链接列表（常见订阅格式）
+	ImportFormatClash   = "clash"    // Clash YAML 的 proxies
+	ImportFormatSingBox = "sing-box" // sing-box JSON 的 outbounds
+)
+
+// ParsedImport 订阅内容的解析结果（尚未写入配置）
+type ParsedImport struct {
+	Format string              `json:"format"`
+	Nodes  []models.NodeConfig `json:"nodes"`
+	Issues []ImportIssue       `json:"issues,omitempty"`
+}
+
+// ParseImport 识别订阅内容的格式并解析出节点，不修改配置
+func ParseImport(data []byte) (*ParsedImport, error) {
+	text := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	if text == "" {
+		return nil, fmt.Errorf("内容为空")
+	}
+
+	result := &ParsedImport{Format: DetectImportFormat(text)}
+	switch result.Format {
+	case ImportFormatSingBox:
+		nodes, issues, err := parseSingBoxOutbounds(text)
+		if err != nil {
+			return nil, err
+		}
+		result.Nodes, result.Issues = nodes, issues
+	case ImportFormatClash:
+		result.Nodes, result.Issues = parseClashConfig(text)
+	case ImportFormatLinks:
+		result.Nodes, result.Issues = parseNodeLinks(text)
+	case ImportFormatBase64:
+		decoded, _ := decodeBase64Loose(stripWhitespace(text))
+		result.Nodes, result.Issues = parseNodeLinks(string(decoded))
+	default:
+		return nil, fmt.Errorf("无法识别的订阅格式")
+	}
+
+	if len(result.Nodes) == 0 {
+		return result, fmt.Errorf("未找到有效的节点")
+	}
+	return result, nil
+}
+
+// DetectImportFormat 判断订阅内容的格式，无法识别时返回空字符串
+func DetectImportFormat(text string) string {
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, "{") {
+		var probe struct {
+			Outbounds json.RawMessage `json:"outbounds"`
+		}
+		if json.Unmarshal([]byte(text), &probe) == nil && len(probe.Outbounds) > 0 {
+			return ImportFormatSingBox
+		}
+		return ""
+	}
+
+	if containsLinks(text) {
+		return ImportFormatLinks
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimRight(line, " \r"), "proxies:") {
+			return ImportFormatClash
+		}
+	}
+
+	if decoded, err := decodeBase64Loose(stripWhitespace(text)); err == nil && containsLinks(string(decoded)) {
+		return ImportFormatBase64
+	}
+	return ""
+}
+
+// containsLinks 是否包含 xlink:// 或支持的第三方分享链接
+func containsLinks(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "xlink://") || isShareLink(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// =============================================================================
+// 通用映射
+// =============================================================================
+
+// proxyFields 第三方配置中一个代理的字段
+type proxyFields struct {
+	scheme string
+	name   string
+	host   string
+	port   string
+	token  string
+	extra  map[string]string // 无法映射的字段
+}
+
+// importedNode 将代理字段映射为节点，与分享链接导入的规则一致
+func importedNode(p proxyFields) (*models.NodeConfig, *ImportIssue, error) {
+	if p.host == "" || p.port == "" || p.token == "" {
+		return nil, nil, fmt.Errorf("%s 缺少地址、端口或凭据", p.scheme)
+	}
+
+	node := models.NewDefaultNode(p.name)
+	node.Server = net.JoinHostPort(p.host, p.port)
+	node.Token = p.token
+
+	issue := &ImportIssue{Name: node.Name, Scheme: p.scheme}
+	keys := make([]string, 0, len(p.extra))
+	for k := range p.extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if val := p.extra[k]; val != "" && val != "none" && val != "false" {
+			issue.Unsupported = append(issue.Unsupported, k+"="+val)
+		}
+	}
+
+	finishImportedNode(&node, issue)
+	return &node, issue, nil
+}
+
+// collectNode 解析单个代理并汇总结果
+func collectNode(p proxyFields, nodes *[]models.NodeConfig, issues *[]ImportIssue) {
+	node, issue, err := importedNode(p)
+	if err != nil {
+		*issues = append(*issues, ImportIssue{Name: p.name, Scheme: p.scheme, Error: err.Error()})
+		return
+	}
+	*nodes = append(*nodes, *node)
+	if len(issue.Unsupported) > 0 {
+		*issues = append(*issues, *issue)
+	}
+}
+
+// =============================================================================
+// sing-box
+// =============================================================================
+
+// singBoxSkipTypes 不是代理服务器的出站类型
+var singBoxSkipTypes = map[string]bool{
+	"direct": true, "block": true, "dns": true, "selector": true, "urltest": true,
+}
+
+// parseSingBoxOutbounds 解析 sing-box 配置的 outbounds
+func parseSingBoxOutbounds(text string) ([]models.NodeConfig, []ImportIssue, error) {
+	var cfg struct {
+		Outbounds []map[string]interface{} `json:"outbounds"`
+	}
+	if err := json.Unmarshal([]byte(text), &cfg); err != nil {
+		return nil, nil, fmt.Errorf("sing-box 配置解析失败: %w", err)
+	}
+
+	var nodes []models.NodeConfig
+	var issues []ImportIssue
+	for _, ob := range cfg.Outbounds {
+		typ := jsonString(ob["type"])
+		if typ == "" || singBoxSkipTypes[typ] {
+			continue
+		}
+
+		p := proxyFields{
+			scheme: typ,
+			name:   jsonString(ob["tag"]),
+			host:   jsonString(ob["server"]),
+			port:   jsonString(ob["server_port"]),
+			extra:  make(map[string]string),
+		}
+		for k, v := range ob {
+			switch k {
+			case "type", "tag", "server", "server_port":
+			case "uuid", "password":
+				if p.token == "" {
+					p.token = jsonString(v)
+				}
+			default:
+				p.extra[k] = jsonString(v)
+			}
+		}
+		collectNode(p, &nodes, &issues)
+	}
+	return nodes, issues, nil
+}
+
+// jsonString 将 JSON 值转为字符串，对象和数组只保留紧凑形式
+func jsonString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// =============================================================================
+// Clash
+// =============================================================================
+
+// parseClashConfig 解析 Clash 配置的 proxies
+func parseClashConfig(text string) ([]models.NodeConfig, []ImportIssue) {
+	var nodes []models.NodeConfig
+	var issues []ImportIssue
+	for _, fields := range parseClashProxies(text) {
+		p := proxyFields{
+			scheme: fields["type"],
+			name:   fields["name"],
+			host:   fields["server"],
+			port:   fields["port"],
+			extra:  make(map[string]string),
+		}
+		for k, v := range fields {
+			switch k {
+			case "type", "name", "server", "port":
+			case "uuid", "password":
+				if p.token == "" {
+					p.token = v
+				}
+			default:
+				p.extra[k] = v
+			}
+		}
+		collectNode(p, &nodes, &issues)
+	}
+	return nodes, issues
+}
+
+// parseClashProxies 从 Clash YAML 中提取 proxies 列表
+// 只解析需要的子集：顶层 proxies 下的流式 ({k: v, ...}) 或块式 (- k: v) 映射，
+// 嵌套字段（如 ws-opts）不展开，只作为无法映射的字段列出
+func parseClashProxies(text string) []map[string]string {
+	var proxies []map[string]string
+	var current map[string]string
+	inSection := false
+	keyIndent := -1
+
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if indent == 0 && !strings.HasPrefix(trimmed, "-") {
+			inSection = trimmed == "proxies:"
+			current = nil
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			current = make(map[string]string)
+			proxies = append(proxies, current)
+			item := strings.TrimLeft(line[indent+1:], " ")
+			keyIndent = len(line) - len(item)
+			if item == "" {
+				keyIndent = indent + 2
+			}
+			if strings.HasPrefix(item, "{") {
+				parseYAMLFlowMap(item, current)
+				current = nil
+			} else if item != "" {
+				parseYAMLPair(item, current)
+			}
+			continue
+		}
+
+		// 只取代理的直接字段，更深的缩进属于嵌套字段
+		if current != nil && indent == keyIndent {
+			parseYAMLPair(trimmed, current)
+		}
+	}
+	return proxies
+}
+
+// parseYAMLPair 解析 key: value；值在下一级缩进中的嵌套字段记为 {...}
+func parseYAMLPair(s string, into map[string]string) {
+	key, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	if value = yamlScalar(value); value == "" {
+		value = "{...}"
+	}
+	into[key] = value
+}
+
+// parseYAMLFlowMap 解析单行的 {k: v, k2: v2}
+func parseYAMLFlowMap(s string, into map[string]string) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "{")
+	if idx := strings.LastIndex(s, "}"); idx != -1 {
+		s = s[:idx]
+	}
+	for _, part := range splitYAMLFlow(s) {
+		parseYAMLPair(part, into)
+	}
+}
+
+// splitYAMLFlow 按逗号拆分流式映射，忽略引号和嵌套括号中的逗号
+func splitYAMLFlow(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// yamlScalar 去掉引号和行尾注释
+func yamlScalar(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.LastIndexByte(s, s[0]); end > 0 {
+			return s[1:end]
+		}
+	}
+	if idx := strings.Index(s, " #"); idx != -1 {
+		s = strings.TrimSpace(s[:idx])
+	}
+	return s
+}
+
+// =============================================================================
+// 订阅刷新
+// =============================================================================
+
+// MergeSubscription 以订阅的最新内容更新属于 subID 的节点，返回新的节点列表与变化
+// 按名称匹配（同名节点按出现顺序一一对应）：匹配到的节点只更新服务器与凭据，保留本地的监听、规则等设置；
+// 订阅中已没有的节点被删除，新增的节点追加到末尾（超出节点数量上限 maxNodes 的部分忽略，计入 Skipped）
+func MergeSubscription(nodes []models.NodeConfig, subID string, fresh []models.NodeConfig, maxNodes int) ([]models.NodeConfig, models.SubscriptionDiff) {
+	var diff models.SubscriptionDiff
+
+	pending := make(map[string][]int) // 名称 → 尚未匹配的新节点下标
+	for i, n := range fresh {
+		pending[n.Name] = append(pending[n.Name], i)
+	}
+	matched := make([]bool, len(fresh))
+
+	merged := make([]models.NodeConfig, 0, len(nodes)+len(fresh))
+	for _, node := range nodes {
+		if node.SubscriptionID != subID {
+			merged = append(merged, node)
+			continue
+		}
+		queue := pending[node.Name]
+		if len(queue) == 0 {
+			diff.Removed = append(diff.Removed, models.NodeChange{Name: node.Name, Server: node.Server})
+			continue
+		}
+		i := queue[0]
+		pending[node.Name] = queue[1:]
+		matched[i] = true
+
+		if fields := endpointChanges(&node, &fresh[i]); len(fields) > 0 {
+			change := models.NodeChange{Name: node.Name, Server: fresh[i].Server, Fields: fields}
+			if node.Server != fresh[i].Server {
+				change.OldServer = node.Server
+			}
+			diff.Modified = append(diff.Modified, change)
+			applyEndpoint(&node, &fresh[i])
+		}
+		merged = append(merged, node)
+	}
+
+	for i, n := range fresh {
+		if matched[i] {
+			continue
+		}
+		if len(merged) >= maxNodes {
+			diff.Skipped++
+			continue
+		}
+		n.SubscriptionID = subID
+		models.NormalizeIPv6Config(&n)
+		merged = append(merged, n)
+		diff.Added = append(diff.Added, models.NodeChange{Name: n.Name, Server: n.Server})
+	}
+	return merged, diff
+}
+
+// endpointChanges 订阅提供的连接字段中有变化的字段名
+func endpointChanges(old, fresh *models.NodeConfig) []string {
+	var fields []string
+	if old.Server != fresh.Server {
+		fields = append(fields, "server")
+	}
+	if old.IP != fresh.IP {
+		fields = append(fields, "ip")
+	}
+	if old.Token != fresh.Token {
+		fields = append(fields, "token")
+	}
+	if old.SecretKey != fresh.SecretKey {
+		fields = append(fields, "secret_key")
+	}
+	if old.FallbackIP != fresh.FallbackIP {
+		fields = append(fields, "fallback_ip")
+	}
+	return fields
+}
+
+// applyEndpoint 以订阅内容覆盖节点的连接字段
+func applyEndpoint(node, fresh *models.NodeConfig) {
+	node.Server = fresh.Server
+	node.IP = fresh.IP
+	node.Token = fresh.Token
+	node.SecretKey = fresh.SecretKey
+	node.FallbackIP = fresh.FallbackIP
+}
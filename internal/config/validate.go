@@ -0,0 +1,380 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"xlink-wails/internal/command"
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/scheduler"
+)
+
+// =============================================================================
+// 配置校验 (供 --validate 命令行使用)
+// =============================================================================
+
+// 校验问题级别
+const (
+	IssueError   = "error"   // 配置无法正常运行
+	IssueWarning = "warning" // 加载时会被自动修正或可能不符合预期
+)
+
+// ValidationIssue 单条校验问题
+type ValidationIssue struct {
+	Level    string `json:"level"`
+	NodeID   string `json:"node_id,omitempty"`
+	NodeName string `json:"node_name,omitempty"`
+	RuleID   string `json:"rule_id,omitempty"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+}
+
+// ValidationReport 配置校验报告
+type ValidationReport struct {
+	Path     string            `json:"path"`
+	Valid    bool              `json:"valid"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Nodes    int               `json:"nodes"`
+	Issues   []ValidationIssue `json:"issues"`
+}
+
+// ResolveConfigPath 返回要校验的配置文件路径，未指定时按加载优先级查找默认配置
+func (m *Manager) ResolveConfigPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	for _, name := range []string{ConfigFileNameEnc, ConfigFileName} {
+		p := filepath.Join(m.exeDir, name)
+		if fileExists(p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("未找到默认配置文件")
+}
+
+// ReadConfigFile 读取配置文件但不做自动修正，也不替换当前配置
+// 同时支持明文JSON和加密配置
+func (m *Manager) ReadConfigFile(path string) (*models.AppConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	plaintext := data
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "{") {
+		ciphertext, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("Base64解码失败: %w", err)
+		}
+		if plaintext, err = m.decrypt(ciphertext); err != nil {
+			return nil, fmt.Errorf("解密失败: %w", err)
+		}
+	}
+
+	var config models.AppConfig
+	if err := json.Unmarshal(plaintext, &config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	return &config, nil
+}
+
+// ValidateConfig 对配置执行全部校验（节点、规则、IPv6 开关、端口冲突）
+func ValidateConfig(config *models.AppConfig) *ValidationReport {
+	report := &ValidationReport{Nodes: len(config.Nodes), Issues: []ValidationIssue{}}
+
+	add := func(level string, node *models.NodeConfig, ruleID, field, msg string) {
+		issue := ValidationIssue{Level: level, RuleID: ruleID, Field: field, Message: msg}
+		if node != nil {
+			issue.NodeID, issue.NodeName = node.ID, node.Name
+		}
+		report.Issues = append(report.Issues, issue)
+		if level == IssueError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if len(config.Nodes) == 0 {
+		add(IssueWarning, nil, "", "nodes", "没有节点，加载时将创建默认节点")
+	}
+	if err := models.ValidateLimits(config.Limits); err != nil {
+		add(IssueError, nil, "", "limits", err.Error())
+	}
+	if err := models.ValidatePortRange(config.InternalPortRange); err != nil {
+		add(IssueError, nil, "", "internal_port_range", err.Error())
+	}
+	if err := models.ValidateProxyBypass(config.ProxyBypass); err != nil {
+		add(IssueError, nil, "", "proxy_bypass", err.Error())
+	}
+	if len(config.Nodes) > config.NodeLimit() {
+		add(IssueError, nil, "", "nodes", fmt.Sprintf("节点数量 %d 超过上限 %d", len(config.Nodes), config.NodeLimit()))
+	}
+	maxRules := config.RuleLimit()
+
+	ids := make(map[string]bool)
+	for i := range config.Nodes {
+		node := &config.Nodes[i]
+
+		if node.ID == "" {
+			add(IssueWarning, node, "", "id", "缺少节点ID，加载时将自动生成")
+		} else if ids[node.ID] {
+			add(IssueError, node, "", "id", "节点ID重复")
+		}
+		ids[node.ID] = true
+
+		validateNode(node, add)
+		validateRules(node, node.Rules, maxRules, add)
+		validateRuleGroupRefs(node, config.RuleGroups, maxRules, add)
+	}
+
+	validateRuleGroups(config.RuleGroups, maxRules, add)
+	validateSchedules(config.Schedules, ids, add)
+
+	validatePortConflicts(config.Nodes, add)
+
+	report.Valid = report.Errors == 0
+	return report
+}
+
+type issueFunc func(level string, node *models.NodeConfig, ruleID, field, msg string)
+
+// validateNode 校验节点基本字段与 IPv6 开关
+func validateNode(node *models.NodeConfig, add issueFunc) {
+	if node.Name == "" {
+		add(IssueWarning, node, "", "name", "节点名称为空")
+	} else if len(node.Name) > models.MaxNameLen {
+		add(IssueError, node, "", "name", fmt.Sprintf("节点名称超过 %d 字节", models.MaxNameLen))
+	}
+
+	if strings.TrimSpace(node.Server) == "" {
+		add(IssueError, node, "", "server", "服务器地址不能为空")
+	} else if len(node.Server) > models.MaxURLLen {
+		add(IssueError, node, "", "server", fmt.Sprintf("服务器地址超过 %d 字节", models.MaxURLLen))
+	}
+
+	if node.Listen == "" {
+		add(IssueWarning, node, "", "listen", "监听地址为空，加载时将使用 127.0.0.1:10808")
+	} else if _, err := parseListenPort(node.Listen); err != nil {
+		add(IssueError, node, "", "listen", err.Error())
+	}
+
+	if node.InboundMode < models.InboundSocks || node.InboundMode > models.InboundMixed {
+		add(IssueError, node, "", "inbound_mode", fmt.Sprintf("未知的入站协议: %d", node.InboundMode))
+	} else if node.InboundMode == models.InboundSocksHTTP {
+		if _, err := parseListenPort(node.HTTPListen); err != nil {
+			add(IssueError, node, "", "http_listen", err.Error())
+		}
+	}
+
+	if node.RoutingMode != models.RoutingModeGlobal && node.RoutingMode != models.RoutingModeSmart {
+		add(IssueError, node, "", "routing_mode", fmt.Sprintf("未知的路由模式: %d", node.RoutingMode))
+	}
+	if node.StrategyMode < models.StrategyRandom || node.StrategyMode > models.StrategyHash {
+		add(IssueError, node, "", "strategy_mode", fmt.Sprintf("未知的负载策略: %d", node.StrategyMode))
+	}
+	if node.DNSMode < models.DNSModeStandard || node.DNSMode > models.DNSModeTUN {
+		add(IssueError, node, "", "dns_mode", fmt.Sprintf("未知的DNS模式: %d", node.DNSMode))
+	}
+
+	// 在副本上校验，避免自动修正改动原配置
+	check := *node
+	if err := models.ValidateIPv6Config(&check); err != nil {
+		add(IssueError, node, "", "ipv6", err.Error())
+	} else if node.IPv6Only && !node.EnableIPv6 {
+		add(IssueWarning, node, "", "ipv6", "IPv6Only 需要 EnableIPv6，加载时将自动启用")
+	}
+	if node.AutoIPStrategy && (node.DisableIPv6 || node.IPv6Only || node.PreferIPv6) {
+		add(IssueWarning, node, "", "auto_ip_strategy", "已启用自动IP策略，手动设置的 IPv6 开关将被忽略")
+	}
+	if err := models.ValidateKeepAlive(node); err != nil {
+		add(IssueError, node, "", "keep_alive", err.Error())
+	}
+	if err := models.ValidateBandwidthLimit(node); err != nil {
+		add(IssueError, node, "", "bandwidth_limit", err.Error())
+	}
+	if err := models.ValidateTUNMTU(node); err != nil {
+		add(IssueError, node, "", "tun_mtu", err.Error())
+	}
+	if err := models.ValidateFixedInternalPort(node); err != nil {
+		add(IssueError, node, "", "fixed_internal_port", err.Error())
+	}
+	if err := models.ValidateAppRouting(node); err != nil {
+		add(IssueError, node, "", "app_routing", err.Error())
+	}
+	if err := models.ValidateFrontEngine(node); err != nil {
+		add(IssueError, node, "", "front_engine", err.Error())
+	}
+	if err := models.ValidateLANShare(node); err != nil {
+		add(IssueError, node, "", "lan_share", err.Error())
+	}
+}
+
+// validateSchedules 校验定时任务的时间表达式和目标节点
+func validateSchedules(schedules []models.ScheduleEntry, nodeIDs map[string]bool, add issueFunc) {
+	for _, e := range schedules {
+		name := e.Name
+		if name == "" {
+			name = e.ID
+		}
+		if _, err := scheduler.Parse(e.Spec); err != nil {
+			add(IssueError, nil, "", "schedules.spec", fmt.Sprintf("定时任务 %s: %v", name, err))
+		}
+		switch command.Name(e.Action) {
+		case command.CmdStopAll:
+		case command.CmdStart, command.CmdStop, command.CmdSwitch:
+			if !nodeIDs[e.NodeID] {
+				add(IssueWarning, nil, "", "schedules.node_id", fmt.Sprintf("定时任务 %s 的目标节点不存在，执行时将失败", name))
+			}
+		default:
+			add(IssueError, nil, "", "schedules.action", fmt.Sprintf("定时任务 %s 的动作无效: %s", name, e.Action))
+		}
+	}
+}
+
+// validateRuleGroups 校验全局规则组（规则问题不关联节点，按规则ID定位）
+func validateRuleGroups(groups []models.RuleGroup, maxRules int, add issueFunc) {
+	ids := make(map[string]bool)
+	for _, g := range groups {
+		if g.ID != "" && ids[g.ID] {
+			add(IssueError, nil, "", "rule_groups.id", fmt.Sprintf("规则组ID重复: %s", g.Name))
+		}
+		ids[g.ID] = true
+		validateRules(nil, g.Rules, maxRules, add)
+	}
+}
+
+// validateRuleGroupRefs 校验节点引用的规则组是否存在，以及展开后的规则数量
+func validateRuleGroupRefs(node *models.NodeConfig, groups []models.RuleGroup, maxRules int, add issueFunc) {
+	for _, id := range node.RuleGroupIDs {
+		if models.FindRuleGroup(groups, id) == nil {
+			add(IssueWarning, node, "", "rule_group_ids", fmt.Sprintf("引用的规则组 %s 不存在，加载时将移除", id))
+		}
+	}
+	if len(node.RuleGroupIDs) > 0 && len(node.Rules) <= maxRules {
+		if n := len(models.ResolveNodeRules(node, groups)); n > maxRules {
+			add(IssueError, node, "", "rule_group_ids", fmt.Sprintf("展开规则组后规则数量 %d 超过上限 %d", n, maxRules))
+		}
+	}
+}
+
+// validateRules 校验分流规则（node 为空时表示规则组中的规则）
+func validateRules(node *models.NodeConfig, rules []models.RoutingRule, maxRules int, add issueFunc) {
+	if len(rules) > maxRules {
+		add(IssueError, node, "", "rules", fmt.Sprintf("规则数量 %d 超过上限 %d", len(rules), maxRules))
+	}
+
+	ruleIDs := make(map[string]bool)
+	for _, r := range rules {
+		if r.ID != "" && ruleIDs[r.ID] {
+			add(IssueError, node, r.ID, "rules.id", "规则ID重复")
+		}
+		ruleIDs[r.ID] = true
+
+		match := strings.TrimSpace(r.Match)
+		if match == "" {
+			add(IssueError, node, r.ID, "rules.match", "规则匹配内容为空")
+		}
+		if strings.TrimSpace(r.Target) == "" {
+			add(IssueError, node, r.ID, "rules.target", "规则目标为空")
+		}
+
+		switch strings.ToLower(r.Type) {
+		case "", "domain:", "domain", "regexp:", "regexp", "geosite:", "geosite", "geoip:", "geoip":
+		case "ip:", "ip":
+			if match != "" && net.ParseIP(match) == nil {
+				if _, _, err := net.ParseCIDR(match); err != nil {
+					add(IssueError, node, r.ID, "rules.match", fmt.Sprintf("无效的IP地址: %s", match))
+				}
+			}
+		case "ip-cidr:", "ip-cidr", "cidr":
+			if _, _, err := net.ParseCIDR(match); match != "" && err != nil {
+				add(IssueError, node, r.ID, "rules.match", fmt.Sprintf("无效的CIDR: %s", match))
+			}
+		default:
+			add(IssueWarning, node, r.ID, "rules.type", fmt.Sprintf("未知的规则类型 %q，将按关键字匹配", r.Type))
+		}
+	}
+}
+
+// validatePortConflicts 检查节点之间的监听端口冲突
+func validatePortConflicts(nodes []models.NodeConfig, add issueFunc) {
+	type listener struct {
+		host string
+		node *models.NodeConfig
+	}
+	byPort := make(map[int][]listener)
+
+	for i := range nodes {
+		node := &nodes[i]
+
+		listens := map[string]string{"listen": node.Listen}
+		if node.InboundMode == models.InboundSocksHTTP {
+			listens["http_listen"] = node.HTTPListen
+		}
+		if node.RoutingMode == models.RoutingModeSmart && node.FixedInternalPort > 0 {
+			listens["fixed_internal_port"] = net.JoinHostPort("127.0.0.1", strconv.Itoa(node.FixedInternalPort))
+		}
+		for _, field := range []string{"listen", "http_listen", "fixed_internal_port"} {
+			addr, ok := listens[field]
+			if !ok {
+				continue
+			}
+			port, err := parseListenPort(addr)
+			if err != nil {
+				continue
+			}
+			if node.LANShare.Enabled && field != "fixed_internal_port" {
+				addr = models.LANShareListen(addr)
+			}
+			host, _, _ := net.SplitHostPort(addr)
+
+			for _, other := range byPort[port] {
+				if hostsOverlap(host, other.host) {
+					add(IssueError, node, "", field,
+						fmt.Sprintf("监听端口 %d 与节点 [%s] 冲突", port, other.node.Name))
+					break
+				}
+			}
+			byPort[port] = append(byPort[port], listener{host: host, node: node})
+		}
+	}
+}
+
+// parseListenPort 解析监听地址中的端口
+func parseListenPort(listen string) (int, error) {
+	_, portStr, err := net.SplitHostPort(listen)
+	if err != nil {
+		return 0, fmt.Errorf("监听地址格式错误，应为 host:port: %s", listen)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("监听端口无效: %s", portStr)
+	}
+	return port, nil
+}
+
+// hostsOverlap 判断两个监听地址是否会占用同一端口（通配地址与任意地址冲突）
+func hostsOverlap(a, b string) bool {
+	wildcard := func(h string) bool {
+		return h == "" || h == "0.0.0.0" || h == "::"
+	}
+	if wildcard(a) || wildcard(b) {
+		return true
+	}
+	if a == "localhost" {
+		a = "127.0.0.1"
+	}
+	if b == "localhost" {
+		b = "127.0.0.1"
+	}
+	return a == b
+}
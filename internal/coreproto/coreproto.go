@@ -0,0 +1,260 @@
+// Package coreproto 解析 xlink 内核的输出格式，供引擎、日志和统计共用
+package coreproto
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 输出格式
+// =============================================================================
+
+// 内核输出的格式:
+//   延迟测试     server | Delay: 42ms                            或  server | Error: timeout
+//   规则命中     Rule Hit -> target | SNI: server (Rule: keyword)
+//   负载均衡     LB -> target | SNI: server | Algo: rr
+//   隧道建立     Tunnel -> server (...) >>> real (...) Latency: 35ms
+//   连接结束     [Stats] target | Up: 1.2 KB | Down: 3.4 MB | Time: 5s
+// 日志行前可能带有时间戳以及 [CLI] / [Core] 前缀。
+// 解析结果保留原始数值（字节、毫秒），显示文本由日志或界面按语言格式化。
+
+// 日志标记
+const (
+	MarkerRuleHit = "Rule Hit ->"
+	MarkerLB      = "LB ->"
+	MarkerTunnel  = "Tunnel ->"
+	MarkerStats   = "[Stats]"
+)
+
+// Kind 日志行的类型
+type Kind int
+
+const (
+	KindOther   Kind = iota // 其他内核日志
+	KindRuleHit             // 规则命中
+	KindLB                  // 负载均衡选路
+	KindTunnel              // 隧道建立
+	KindStats               // 连接结束统计
+)
+
+// Marker 类型对应的日志标记，KindOther 返回空字符串
+func (k Kind) Marker() string {
+	switch k {
+	case KindRuleHit:
+		return MarkerRuleHit
+	case KindLB:
+		return MarkerLB
+	case KindTunnel:
+		return MarkerTunnel
+	case KindStats:
+		return MarkerStats
+	}
+	return ""
+}
+
+// Classify 判断日志行的类型
+func Classify(line string) Kind {
+	switch {
+	case strings.Contains(line, MarkerRuleHit):
+		return KindRuleHit
+	case strings.Contains(line, MarkerLB):
+		return KindLB
+	case strings.Contains(line, MarkerTunnel):
+		return KindTunnel
+	case strings.Contains(line, MarkerStats):
+		return KindStats
+	}
+	return KindOther
+}
+
+// FromMarker 去掉日志标记之前的时间戳和前缀，不含标记时原样返回
+func FromMarker(line string) string {
+	if marker := Classify(line).Marker(); marker != "" {
+		return line[strings.Index(line, marker):]
+	}
+	return line
+}
+
+// StripPrefix 去掉 [CLI] / [Core] 前缀
+func StripPrefix(line string) string {
+	line = strings.TrimPrefix(line, "[CLI] ")
+	return strings.TrimPrefix(line, "[Core] ")
+}
+
+// StripTimestamp 去掉行首的时间戳（2006/01/02 15:04:05，可带小数秒），没有时原样返回
+func StripTimestamp(line string) string {
+	const layout = "2006/01/02 15:04:05"
+	if len(line) < len(layout) {
+		return line
+	}
+	for i := 0; i < len(layout); i++ {
+		c := line[i]
+		if isDigit(layout[i]) != isDigit(c) || (!isDigit(c) && c != layout[i]) {
+			return line
+		}
+	}
+	rest := line[len(layout):]
+	if strings.HasPrefix(rest, ".") {
+		rest = strings.TrimLeft(rest[1:], "0123456789")
+	}
+	return strings.TrimLeft(rest, " ")
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// afterMarker 标记之后的内容
+func afterMarker(line, marker string) (string, bool) {
+	idx := strings.Index(line, marker)
+	if idx == -1 {
+		return "", false
+	}
+	return line[idx+len(marker):], true
+}
+
+// =============================================================================
+// 延迟测试
+// =============================================================================
+
+// ParsePing 解析延迟测试结果行，失败结果的 Latency 为 -1
+func ParsePing(line string) (models.PingResult, bool) {
+	server, info, ok := strings.Cut(strings.TrimSpace(line), "|")
+	if !ok {
+		return models.PingResult{}, false
+	}
+	result := models.PingResult{Server: strings.TrimSpace(server), Latency: -1}
+	if result.Server == "" {
+		return models.PingResult{}, false
+	}
+
+	info = strings.TrimSpace(info)
+	switch {
+	case strings.HasPrefix(info, "Delay:"):
+		delay := strings.TrimSpace(strings.TrimPrefix(info, "Delay:"))
+		latency, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(delay, "ms")))
+		if err != nil {
+			return models.PingResult{}, false
+		}
+		result.Latency = latency
+	case strings.HasPrefix(info, "Error:"):
+		result.Error = strings.TrimSpace(strings.TrimPrefix(info, "Error:"))
+	default:
+		return models.PingResult{}, false
+	}
+	return result, true
+}
+
+// =============================================================================
+// 连接日志
+// =============================================================================
+
+// Route 规则命中或负载均衡选路
+type Route struct {
+	Target   string // 访问的目标
+	Server   string // 选中的服务器 (SNI)
+	Rule     string // 命中的规则关键词（规则命中）
+	Strategy string // 负载策略 random / rr / hash（负载均衡）
+}
+
+// ParseRoute 解析 "Rule Hit ->" 或 "LB ->" 日志
+func ParseRoute(line string) (Route, bool) {
+	rest, ok := afterMarker(line, MarkerRuleHit)
+	if !ok {
+		if rest, ok = afterMarker(line, MarkerLB); !ok {
+			return Route{}, false
+		}
+	}
+
+	parts := strings.Split(rest, "|")
+	route := Route{Target: strings.TrimSpace(parts[0])}
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		switch {
+		case strings.HasPrefix(p, "SNI:"):
+			server := p[4:]
+			if idx := strings.Index(server, "(Rule:"); idx != -1 {
+				route.Rule = strings.TrimSuffix(strings.TrimSpace(server[idx+6:]), ")")
+				server = server[:idx]
+			}
+			route.Server = strings.TrimSpace(server)
+		case strings.HasPrefix(p, "Algo:"):
+			route.Strategy = strings.TrimSpace(p[5:])
+		}
+	}
+	return route, route.Target != ""
+}
+
+// Tunnel 隧道建立
+type Tunnel struct {
+	Server      string // 连接的服务器
+	Real        string // 实际出口
+	Latency     int64  // 建立耗时（毫秒），日志中没有时为 -1
+	LatencyText string // 内核输出的原文，如 "35ms"
+}
+
+// ParseTunnel 解析 "Tunnel ->" 日志；没有 ">>>" 时只返回服务器
+func ParseTunnel(line string) (Tunnel, bool) {
+	rest, ok := afterMarker(line, MarkerTunnel)
+	if !ok {
+		return Tunnel{}, false
+	}
+
+	t := Tunnel{Latency: -1}
+	if idx := strings.Index(rest, "Latency:"); idx != -1 {
+		t.LatencyText = strings.TrimSpace(rest[idx+8:])
+		if d, ok := parseDuration(t.LatencyText); ok {
+			t.Latency = d.Milliseconds()
+		}
+		rest = rest[:idx]
+	}
+	server, real, _ := strings.Cut(rest, ">>>")
+	t.Server = strings.TrimSpace(strings.Split(server, "(")[0])
+	t.Real = strings.TrimSpace(strings.Split(real, "(")[0])
+	return t, t.Server != ""
+}
+
+// Stats 连接结束统计
+type Stats struct {
+	Target       string
+	Up           int64         // 字节
+	Down         int64         // 字节
+	Duration     time.Duration // 连接时长
+	UpText       string        // 内核输出的原文，如 "1.2 KB"
+	DownText     string
+	DurationText string // 如 "5s"
+}
+
+// ParseStats 解析 "[Stats]" 日志
+func ParseStats(line string) (Stats, bool) {
+	rest, ok := afterMarker(line, MarkerStats)
+	if !ok {
+		return Stats{}, false
+	}
+
+	parts := strings.Split(rest, "|")
+	s := Stats{Target: strings.TrimSpace(parts[0])}
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		switch {
+		case strings.HasPrefix(p, "Up:"):
+			s.UpText = strings.TrimSpace(p[3:])
+			s.Up = models.ParseByteSize(s.UpText)
+		case strings.HasPrefix(p, "Down:"):
+			s.DownText = strings.TrimSpace(p[5:])
+			s.Down = models.ParseByteSize(s.DownText)
+		case strings.HasPrefix(p, "Time:"):
+			s.DurationText = strings.TrimSpace(p[5:])
+			s.Duration, _ = parseDuration(s.DurationText)
+		}
+	}
+	return s, true
+}
+
+// parseDuration 解析 "35ms"、"7 ms"、"5s" 等时长
+func parseDuration(s string) (time.Duration, bool) {
+	d, err := time.ParseDuration(strings.ReplaceAll(s, " ", ""))
+	return d, err == nil
+}
@@ -0,0 +1,155 @@
+package coreproto
+
+import (
+	"testing"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		line string
+		want Kind
+	}{
+		{"2024/01/02 15:04:05 [Core] Rule Hit -> a.com | SNI: s1", KindRuleHit},
+		{"LB -> a.com | SNI: s1 | Algo: rr", KindLB},
+		{"Tunnel -> s1 (1.1.1.1) >>> real (2.2.2.2) Latency: 35ms", KindTunnel},
+		{"[Stats] a.com | Up: 1 KB | Down: 2 KB | Time: 5s", KindStats},
+		{"listening on 127.0.0.1:10808", KindOther},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.line); got != tt.want {
+			t.Errorf("Classify(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestStripTimestampAndPrefix(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"2024/01/02 15:04:05 [Core] started", "[Core] started"},
+		{"2024/01/02 15:04:05.123456 [CLI] started", "[CLI] started"},
+		{"2024-01-02 15:04:05 started", "2024-01-02 15:04:05 started"},
+		{"short", "short"},
+	}
+	for _, tt := range tests {
+		if got := StripTimestamp(tt.line); got != tt.want {
+			t.Errorf("StripTimestamp(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	if got := StripPrefix("[CLI] [Core] started"); got != "started" {
+		t.Errorf("StripPrefix = %q, want %q", got, "started")
+	}
+	if got := FromMarker("2024/01/02 15:04:05 [Core] LB -> a.com"); got != "LB -> a.com" {
+		t.Errorf("FromMarker = %q, want %q", got, "LB -> a.com")
+	}
+}
+
+func TestParsePing(t *testing.T) {
+	tests := []struct {
+		line string
+		want models.PingResult
+		ok   bool
+	}{
+		{"s1.example.com:443 | Delay: 42ms", models.PingResult{Server: "s1.example.com:443", Latency: 42}, true},
+		{"  s1 | Delay: 7 ms ", models.PingResult{Server: "s1", Latency: 7}, true},
+		{"s1 | Error: timeout", models.PingResult{Server: "s1", Latency: -1, Error: "timeout"}, true},
+		{"s1 | Delay: fast", models.PingResult{}, false},
+		{"s1 | Status: ok", models.PingResult{}, false},
+		{" | Delay: 42ms", models.PingResult{}, false},
+		{"no separator", models.PingResult{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParsePing(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParsePing(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	tests := []struct {
+		line string
+		want Route
+		ok   bool
+	}{
+		{
+			"Rule Hit -> www.google.com:443 | SNI: s1.example.com (Rule: google)",
+			Route{Target: "www.google.com:443", Server: "s1.example.com", Rule: "google"},
+			true,
+		},
+		{
+			"2024/01/02 15:04:05 LB -> a.com:80 | SNI: s2 | Algo: rr",
+			Route{Target: "a.com:80", Server: "s2", Strategy: "rr"},
+			true,
+		},
+		{"Rule Hit -> ", Route{}, false},
+		{"Tunnel -> s1", Route{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRoute(tt.line)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseRoute(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseTunnel(t *testing.T) {
+	tests := []struct {
+		line string
+		want Tunnel
+		ok   bool
+	}{
+		{
+			"Tunnel -> s1.example.com (1.1.1.1) >>> real.example.com (2.2.2.2) Latency: 35ms",
+			Tunnel{Server: "s1.example.com", Real: "real.example.com", Latency: 35, LatencyText: "35ms"},
+			true,
+		},
+		{
+			"Tunnel -> s1 Latency: 1.5 s",
+			Tunnel{Server: "s1", Latency: 1500, LatencyText: "1.5 s"},
+			true,
+		},
+		{"Tunnel -> s1 Latency: soon", Tunnel{Server: "s1", Latency: -1, LatencyText: "soon"}, true},
+		{"Tunnel -> ", Tunnel{Latency: -1}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTunnel(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseTunnel(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseStats(t *testing.T) {
+	tests := []struct {
+		line string
+		want Stats
+		ok   bool
+	}{
+		{
+			"[Stats] a.com:443 | Up: 1.5 KB | Down: 2 MB | Time: 5s",
+			Stats{
+				Target: "a.com:443", Up: 1536, Down: 2 << 20, Duration: 5 * time.Second,
+				UpText: "1.5 KB", DownText: "2 MB", DurationText: "5s",
+			},
+			true,
+		},
+		{
+			"[Core] [Stats] b.com | Down: 10 B",
+			Stats{Target: "b.com", Down: 10, DownText: "10 B"},
+			true,
+		},
+		{"Stats a.com | Up: 1 KB", Stats{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseStats(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseStats(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
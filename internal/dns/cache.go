@@ -0,0 +1,392 @@
+package dns
+
+import (
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// DNS 缓存（本机 DNS 服务转发的应答）
+// =============================================================================
+
+const (
+	// DefaultDNSCacheSize 缓存条目上限，超出时淘汰最久未使用的条目
+	DefaultDNSCacheSize = 4096
+
+	// dnsCacheMaxTTL 缓存时间上限，避免上游返回过长的 TTL
+	dnsCacheMaxTTL = time.Hour
+	// dnsCacheNegativeTTL 没有记录的应答（NXDOMAIN / 无数据）且没有 SOA 时的缓存时间
+	dnsCacheNegativeTTL = 30 * time.Second
+
+	dnsTypeOPT = 41
+)
+
+// DNSCacheStats 缓存统计
+type DNSCacheStats struct {
+	Entries    int     `json:"entries"`
+	MaxEntries int     `json:"max_entries"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	HitRatio   float64 `json:"hit_ratio"` // 0-1，没有查询时为 0
+	Evictions  uint64  `json:"evictions"` // 因超出上限被淘汰的条目
+}
+
+// DNSCacheEntry 缓存条目（导出给前端查看）
+type DNSCacheEntry struct {
+	Domain string `json:"domain"`
+	Type   string `json:"type"`
+	TTL    int    `json:"ttl"` // 剩余秒数
+	Hits   uint64 `json:"hits"`
+	Size   int    `json:"size"` // 应答报文字节数
+}
+
+// dnsCacheKey 域名 + 查询类型 + 类
+type dnsCacheKey struct {
+	name  string
+	qtype uint16
+	class uint16
+}
+
+type dnsCacheItem struct {
+	key     dnsCacheKey
+	msg     []byte
+	stored  time.Time
+	expires time.Time
+	hits    uint64
+}
+
+// dnsCache TTL 感知的 LRU 缓存
+type dnsCache struct {
+	mu        sync.Mutex
+	max       int
+	items     map[dnsCacheKey]*list.Element
+	lru       *list.List // 队首为最近使用
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newDNSCache(max int) *dnsCache {
+	return &dnsCache{
+		max:   max,
+		items: make(map[dnsCacheKey]*list.Element),
+		lru:   list.New(),
+	}
+}
+
+// get 返回 ID 已替换、TTL 已扣除经过时间的应答副本
+func (c *dnsCache) get(key dnsCacheKey, id []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil
+	}
+	item := el.Value.(*dnsCacheItem)
+	now := time.Now()
+	if !now.Before(item.expires) {
+		c.removeLocked(el)
+		c.misses++
+		return nil
+	}
+
+	c.hits++
+	item.hits++
+	c.lru.MoveToFront(el)
+
+	msg := append([]byte(nil), item.msg...)
+	copy(msg[:2], id)
+	rewriteTTLs(msg, uint32(now.Sub(item.stored)/time.Second))
+	return msg
+}
+
+// put 按应答中最小的 TTL 缓存；失败、截断或 TTL 为 0 的应答不缓存
+func (c *dnsCache) put(key dnsCacheKey, msg []byte) {
+	ttl, ok := cacheableTTL(msg)
+	if !ok || ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	item := &dnsCacheItem{
+		key:     key,
+		msg:     append([]byte(nil), msg...),
+		stored:  now,
+		expires: now.Add(ttl),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		item.hits = el.Value.(*dnsCacheItem).hits
+		el.Value = item
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(item)
+	for c.lru.Len() > c.max {
+		c.removeLocked(c.lru.Back())
+		c.evictions++
+	}
+}
+
+func (c *dnsCache) removeLocked(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.items, el.Value.(*dnsCacheItem).key)
+}
+
+// flush 删除指定域名（含子域名）的条目，domains 为空时清空全部，返回删除的数量
+func (c *dnsCache) flush(domains []string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(domains) == 0 {
+		n := c.lru.Len()
+		c.items = make(map[dnsCacheKey]*list.Element)
+		c.lru.Init()
+		return n
+	}
+
+	removed := 0
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		name := el.Value.(*dnsCacheItem).key.name
+		for _, d := range domains {
+			if matchDomainPattern(name, "+."+d) {
+				c.removeLocked(el)
+				removed++
+				break
+			}
+		}
+		el = next
+	}
+	return removed
+}
+
+func (c *dnsCache) stats() DNSCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := DNSCacheStats{
+		Entries:    c.lru.Len(),
+		MaxEntries: c.max,
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+	}
+	if total := c.hits + c.misses; total > 0 {
+		s.HitRatio = float64(c.hits) / float64(total)
+	}
+	return s
+}
+
+// dump 未过期的条目，按域名排序
+func (c *dnsCache) dump() []DNSCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]DNSCacheEntry, 0, c.lru.Len())
+	for el := c.lru.Front(); el != nil; el = el.Next() {
+		item := el.Value.(*dnsCacheItem)
+		if !now.Before(item.expires) {
+			continue
+		}
+		entries = append(entries, DNSCacheEntry{
+			Domain: item.key.name,
+			Type:   dnsTypeName(item.key.qtype),
+			TTL:    int(item.expires.Sub(now).Seconds()),
+			Hits:   item.hits,
+			Size:   len(item.msg),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Domain != entries[j].Domain {
+			return entries[i].Domain < entries[j].Domain
+		}
+		return entries[i].Type < entries[j].Type
+	})
+	return entries
+}
+
+// =============================================================================
+// Manager 接口
+// =============================================================================
+
+// GetDNSCacheStats 获取 DNS 缓存统计
+func (m *Manager) GetDNSCacheStats() DNSCacheStats {
+	return m.cache.stats()
+}
+
+// DumpDNSCache 列出当前缓存的域名
+func (m *Manager) DumpDNSCache() []DNSCacheEntry {
+	return m.cache.dump()
+}
+
+// FlushDNSCache 删除指定域名及其子域名的缓存，不指定时清空全部，返回删除的条目数
+func (m *Manager) FlushDNSCache(domains ...string) int {
+	normalized := make([]string, 0, len(domains))
+	for _, d := range domains {
+		if d = strings.ToLower(strings.Trim(strings.TrimSpace(d), ".")); d != "" {
+			normalized = append(normalized, d)
+		}
+	}
+	if len(domains) > 0 && len(normalized) == 0 {
+		return 0
+	}
+	return m.cache.flush(normalized)
+}
+
+// =============================================================================
+// 报文 TTL 处理
+// =============================================================================
+
+// cacheableTTL 计算应答的缓存时间：所有记录（OPT 除外）中最小的 TTL
+func cacheableTTL(msg []byte) (time.Duration, bool) {
+	if len(msg) < dnsHeaderSize {
+		return 0, false
+	}
+	flags := binary.BigEndian.Uint16(msg[2:])
+	if flags&0x0200 != 0 { // TC
+		return 0, false
+	}
+	if rcode := flags & 0xF; rcode != 0 && rcode != 3 { // 只缓存成功和 NXDOMAIN
+		return 0, false
+	}
+
+	minTTL := uint32(0)
+	found := false
+	err := walkRecords(msg, func(rtype uint16, ttlOff int) {
+		if rtype == dnsTypeOPT {
+			return
+		}
+		if ttl := binary.BigEndian.Uint32(msg[ttlOff:]); !found || ttl < minTTL {
+			minTTL = ttl
+		}
+		found = true
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	if !found {
+		return dnsCacheNegativeTTL, true
+	}
+	ttl := time.Duration(minTTL) * time.Second
+	if ttl > dnsCacheMaxTTL {
+		ttl = dnsCacheMaxTTL
+	}
+	return ttl, true
+}
+
+// rewriteTTLs 将所有记录（OPT 除外）的 TTL 减去 elapsed 秒
+func rewriteTTLs(msg []byte, elapsed uint32) {
+	walkRecords(msg, func(rtype uint16, ttlOff int) {
+		if rtype == dnsTypeOPT {
+			return
+		}
+		ttl := binary.BigEndian.Uint32(msg[ttlOff:])
+		if ttl > elapsed {
+			ttl -= elapsed
+		} else {
+			ttl = 0
+		}
+		binary.BigEndian.PutUint32(msg[ttlOff:], ttl)
+	})
+}
+
+// walkRecords 遍历应答、授权和附加部分的所有记录，fn 收到记录类型和 TTL 字段的偏移
+func walkRecords(msg []byte, fn func(rtype uint16, ttlOff int)) error {
+	if len(msg) < dnsHeaderSize {
+		return fmt.Errorf("报文过短")
+	}
+	off := dnsHeaderSize
+	for i := 0; i < int(binary.BigEndian.Uint16(msg[4:])); i++ {
+		var err error
+		if off, err = skipName(msg, off); err != nil {
+			return err
+		}
+		off += 4
+	}
+
+	records := int(binary.BigEndian.Uint16(msg[6:])) +
+		int(binary.BigEndian.Uint16(msg[8:])) +
+		int(binary.BigEndian.Uint16(msg[10:]))
+	for i := 0; i < records; i++ {
+		var err error
+		if off, err = skipName(msg, off); err != nil {
+			return err
+		}
+		if off+10 > len(msg) {
+			return fmt.Errorf("记录不完整")
+		}
+		rtype := binary.BigEndian.Uint16(msg[off:])
+		rdlen := int(binary.BigEndian.Uint16(msg[off+8:]))
+		fn(rtype, off+4)
+		off += 10 + rdlen
+		if off > len(msg) {
+			return fmt.Errorf("记录不完整")
+		}
+	}
+	return nil
+}
+
+// skipName 跳过报文中的域名（支持压缩指针），返回之后的偏移
+func skipName(msg []byte, off int) (int, error) {
+	for {
+		if off >= len(msg) {
+			return 0, fmt.Errorf("域名不完整")
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			return off + 1, nil
+		case n&0xC0 == 0xC0:
+			if off+2 > len(msg) {
+				return 0, fmt.Errorf("域名不完整")
+			}
+			return off + 2, nil
+		case n&0xC0 != 0:
+			return 0, fmt.Errorf("域名格式无效")
+		}
+		off += 1 + n
+	}
+}
+
+// dnsTypeName 查询类型的名称
+func dnsTypeName(qtype uint16) string {
+	switch qtype {
+	case dnsTypeA:
+		return "A"
+	case 2:
+		return "NS"
+	case 5:
+		return "CNAME"
+	case 6:
+		return "SOA"
+	case dnsTypePTR:
+		return "PTR"
+	case 15:
+		return "MX"
+	case 16:
+		return "TXT"
+	case dnsTypeAAAA:
+		return "AAAA"
+	case 33:
+		return "SRV"
+	case dnsTypeSVCB:
+		return "SVCB"
+	case dnsTypeHTTPS:
+		return "HTTPS"
+	}
+	return fmt.Sprintf("TYPE%d", qtype)
+}
@@ -0,0 +1,50 @@
+package dns
+
+import (
+	"net"
+	"strings"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 自定义 DNS 规则（额外的 Fake-IP 过滤与 hosts 覆盖）
+// =============================================================================
+
+// SetCustomRules 设置用户的 Fake-IP 过滤与 hosts 覆盖，本机 DNS 服务立即生效，内核配置在节点下次启动时生效
+func (m *Manager) SetCustomRules(settings models.DNSSettings) {
+	hosts := make(map[string]string, len(settings.Hosts))
+	for domain, ip := range settings.Hosts {
+		hosts[strings.ToLower(strings.TrimSuffix(domain, "."))] = ip
+	}
+	filter := append([]string(nil), settings.FakeIPFilter...)
+
+	m.mu.Lock()
+	m.hosts = hosts
+	m.fakeIPFilter = filter
+	m.mu.Unlock()
+}
+
+// lookupHost 查找 hosts 覆盖
+func (m *Manager) lookupHost(name string) (net.IP, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ip := net.ParseIP(m.hosts[name])
+	return ip, ip != nil
+}
+
+// customFakeIPFilter 用户追加的 Fake-IP 过滤规则
+func (m *Manager) customFakeIPFilter() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.fakeIPFilter
+}
+
+// addCustomHosts 将 hosts 覆盖写入内核 DNS 配置（纯域名在 Xray 中为完整匹配）
+func (m *Manager) addCustomHosts(hosts map[string]interface{}) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for domain, ip := range m.hosts {
+		hosts[domain] = ip
+	}
+}
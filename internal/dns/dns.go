@@ -3,18 +3,17 @@
 package dns
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 
 	"xlink-wails/internal/models"
+	"xlink-wails/internal/syschange"
 )
 
 // =============================================================================
@@ -110,6 +109,16 @@ type Manager struct {
 	originalDNSv4 []string
 	originalDNSv6 []string
 
+	// 缺少规则数据时使用内置列表代替
+	geoFallback bool
+
+	// 用户的 hosts 覆盖（域名 → IP）与额外的 Fake-IP 过滤规则
+	hosts        map[string]string
+	fakeIPFilter []string
+
+	// 本机 DNS 服务转发应答的缓存
+	cache *dnsCache
+
 	// 日志回调
 	logCallback func(level, message string)
 }
@@ -120,12 +129,14 @@ func NewManager(exeDir string) *Manager {
 		exeDir:          exeDir,
 		tunName:         DefaultTUNName,
 		ipVersion:       IPVersionDual,
+		geoFallback:     true,
 		fakeIPMap:       make(map[string]string),
 		reverseFakeIP:   make(map[string]string),
 		fakeIPv6Map:     make(map[string]string),
 		reverseFakeIPv6: make(map[string]string),
 		nextFakeIP:      ipv4ToUint32(net.ParseIP(FakeIPPoolStart)),
 		nextFakeIPv6:    ipv6ToBigInt(net.ParseIP(FakeIPv6PoolStart)),
+		cache:           newDNSCache(DefaultDNSCacheSize),
 	}
 }
 
@@ -275,6 +286,7 @@ func (m *Manager) GenerateXrayDNSConfig(cfg *DNSConfig, hasGeosite, hasGeoip boo
 	if cfg.BlockAds && hasGeosite {
 		dnsConfig.Hosts["geosite:category-ads-all"] = m.getBlockAddress(cfg)
 	}
+	m.addCustomHosts(dnsConfig.Hosts)
 
 	return dnsConfig
 }
@@ -622,8 +634,21 @@ type XrayFullConfig struct {
 	Inbounds  []map[string]interface{} `json:"inbounds"`
 	Outbounds []map[string]interface{} `json:"outbounds"`
 	Routing   map[string]interface{}   `json:"routing"`
+	Policy    map[string]interface{}   `json:"policy,omitempty"`
+	Stats     *struct{}                `json:"stats,omitempty"` // 开启统计时为空对象
+	Metrics   map[string]interface{}   `json:"metrics,omitempty"`
+	API       map[string]interface{}   `json:"api,omitempty"`
+
+	// Template 用户提供的配置模板（JSON），写入时生成的配置合并到其中
+	Template string `json:"-"`
 }
 
+// XrayMetricsTag Xray 统计接口（metrics）的标签
+const XrayMetricsTag = "metrics"
+
+// XrayAPITag Xray API 的标签（只开启 RoutingService，热重载时替换路由规则）
+const XrayAPITag = "api"
+
 // GenerateFullXrayConfig 生成完整的Xray配置
 func (m *Manager) GenerateFullXrayConfig(
 	node *models.NodeConfig,
@@ -631,27 +656,7 @@ func (m *Manager) GenerateFullXrayConfig(
 	hasGeosite, hasGeoip bool,
 ) (*XrayFullConfig, error) {
 
-	dnsCfg := &DNSConfig{
-		Mode:           node.DNSMode,
-		EnableFakeIP:   node.DNSMode == models.DNSModeFakeIP,
-		EnableSniffing: node.EnableSniffing,
-		EnableTUN:      node.DNSMode == models.DNSModeTUN,
-		HijackDNS:      true,
-		BlockAds:       true,
-		EnableIPv6:     node.EnableIPv6,
-		PreferIPv6:     node.PreferIPv6,
-		DisableIPv6:    node.DisableIPv6,
-		IPv6Only:       node.IPv6Only,
-	}
-
-	// 设置IP版本
-	if node.DisableIPv6 {
-		dnsCfg.IPVersion = IPVersionIPv4
-	} else if node.IPv6Only {
-		dnsCfg.IPVersion = IPVersionIPv6
-	} else if node.EnableIPv6 {
-		dnsCfg.IPVersion = IPVersionDual
-	}
+	dnsCfg := m.nodeDNSConfig(node)
 
 	// 解析监听地址
 	listenHost, listenPort := m.parseListenAddr(node.Listen)
@@ -660,6 +665,7 @@ func (m *Manager) GenerateFullXrayConfig(
 		Log: map[string]interface{}{
 			"loglevel": "warning",
 		},
+		Template: node.XrayTemplate,
 	}
 
 	// DNS配置
@@ -671,9 +677,16 @@ func (m *Manager) GenerateFullXrayConfig(
 	}
 
 	// 入站配置
+	// 混合入站无需额外配置：Xray 的 socks 入站会自动识别 HTTP 代理请求
 	inbound := m.generateInboundConfig(dnsCfg, listenHost, listenPort)
 
 	config.Inbounds = []map[string]interface{}{inbound}
+	if node.InboundMode == models.InboundSocksHTTP && node.HTTPListen != "" {
+		httpHost, httpPort := m.parseListenAddr(node.HTTPListen)
+		config.Inbounds = append(config.Inbounds, m.generateHTTPInboundConfig(dnsCfg, httpHost, httpPort))
+	}
+	// 局域网共享：本地入站保持无需认证，局域网地址上的入站要求账号
+	config.Inbounds = append(config.Inbounds, m.generateLANShareInbounds(node, dnsCfg)...)
 
 	// 出站配置
 	config.Outbounds = m.generateOutboundConfig(dnsCfg, xlinkPort)
@@ -681,9 +694,72 @@ func (m *Manager) GenerateFullXrayConfig(
 	// 路由配置
 	config.Routing = m.generateRoutingConfig(node, dnsCfg, hasGeosite, hasGeoip)
 
+	// 连接策略：入站握手超时（未设置时使用 Xray 默认值）
+	policy := map[string]interface{}{}
+	if handshake := node.ConnectionPolicy.HandshakeTimeout; handshake > 0 {
+		policy["levels"] = map[string]interface{}{
+			"0": map[string]interface{}{"handshake": handshake},
+		}
+	}
+
+	// 流量统计：开启入站 / 出站计数，由 metrics 在回环地址上以 HTTP 提供（/debug/vars）
+	if node.StatsPort > 0 {
+		config.Stats = &struct{}{}
+		config.Metrics = map[string]interface{}{
+			"tag":    XrayMetricsTag,
+			"listen": fmt.Sprintf("127.0.0.1:%d", node.StatsPort),
+		}
+		policy["system"] = map[string]interface{}{
+			"statsInboundUplink":    true,
+			"statsInboundDownlink":  true,
+			"statsOutboundUplink":   true,
+			"statsOutboundDownlink": true,
+		}
+	}
+	if len(policy) > 0 {
+		config.Policy = policy
+	}
+
+	// 路由规则热重载：xray api adrules 经此接口替换运行中的规则
+	if node.APIPort > 0 {
+		config.API = map[string]interface{}{
+			"tag":      XrayAPITag,
+			"listen":   fmt.Sprintf("127.0.0.1:%d", node.APIPort),
+			"services": []string{"RoutingService"},
+		}
+	}
+
 	return config, nil
 }
 
+// nodeDNSConfig 根据节点配置构建DNS配置
+func (m *Manager) nodeDNSConfig(node *models.NodeConfig) *DNSConfig {
+	dnsCfg := &DNSConfig{
+		Mode:           node.DNSMode,
+		EnableFakeIP:   node.DNSMode == models.DNSModeFakeIP,
+		EnableSniffing: node.EnableSniffing,
+		EnableTUN:      node.DNSMode == models.DNSModeTUN,
+		TUNMTU:         node.TUNMTU,
+		HijackDNS:      true,
+		BlockAds:       true,
+		EnableIPv6:     node.EnableIPv6,
+		PreferIPv6:     node.PreferIPv6,
+		DisableIPv6:    node.DisableIPv6,
+		IPv6Only:       node.IPv6Only,
+	}
+
+	// 设置IP版本
+	if node.DisableIPv6 {
+		dnsCfg.IPVersion = IPVersionIPv4
+	} else if node.IPv6Only {
+		dnsCfg.IPVersion = IPVersionIPv6
+	} else if node.EnableIPv6 {
+		dnsCfg.IPVersion = IPVersionDual
+	}
+
+	return dnsCfg
+}
+
 // generateInboundConfig 生成入站配置
 func (m *Manager) generateInboundConfig(cfg *DNSConfig, listenHost string, listenPort int) map[string]interface{} {
 	// 处理监听地址
@@ -719,6 +795,24 @@ func (m *Manager) generateInboundConfig(cfg *DNSConfig, listenHost string, liste
 	return inbound
 }
 
+// generateHTTPInboundConfig 生成 HTTP 入站配置
+func (m *Manager) generateHTTPInboundConfig(cfg *DNSConfig, listenHost string, listenPort int) map[string]interface{} {
+	inbound := map[string]interface{}{
+		"tag":      "http-in",
+		"listen":   listenHost,
+		"port":     listenPort,
+		"protocol": "http",
+		"settings": map[string]interface{}{
+			"allowTransparent": false,
+		},
+	}
+
+	if sniffing := m.GenerateSniffingConfig(cfg); sniffing != nil {
+		inbound["sniffing"] = sniffing
+	}
+	return inbound
+}
+
 // generateOutboundConfig 生成出站配置
 func (m *Manager) generateOutboundConfig(cfg *DNSConfig, xlinkPort int) []map[string]interface{} {
 	// 确定domainStrategy
@@ -805,30 +899,137 @@ func (m *Manager) generateRoutingConfig(
 	routing := map[string]interface{}{
 		"domainStrategy": domainStrategy,
 		"domainMatcher":  "hybrid",
-		"rules":          []map[string]interface{}{},
 	}
 
-	rules := []map[string]interface{}{}
+	chain := m.buildRuleChain(node, dnsCfg, hasGeosite, hasGeoip)
+	rules := make([]map[string]interface{}, 0, len(chain))
+	for _, entry := range chain {
+		rules = append(rules, entry.Rule)
+	}
+	routing["rules"] = rules
+
+	return routing
+}
+
+// =============================================================================
+// 规则链
+// =============================================================================
+
+// 规则来源
+const (
+	RuleSourceBuiltin = "builtin" // 内置规则
+	RuleSourceUser    = "user"    // 节点自定义规则（含预设展开后的规则）
+	RuleSourceCore    = "core"    // 全局模式下由 Xlink 内核执行的规则
+)
+
+// RuleChainEntry 规则链中的一条规则（按匹配顺序）
+type RuleChainEntry struct {
+	Index       int                    `json:"index"`
+	Source      string                 `json:"source"`
+	Name        string                 `json:"name"`
+	OutboundTag string                 `json:"outbound_tag"`
+	RuleID      string                 `json:"rule_id,omitempty"` // 用户规则ID
+	Rule        map[string]interface{} `json:"rule"`
+}
+
+// GetEffectiveRuleChain 返回节点实际生效的有序规则链
+// 智能分流模式下与写入 Xray 配置的 routing.rules 完全一致；
+// 全局模式下不启动 Xray，规则由 Xlink 内核按顺序匹配，未命中全部走代理
+func (m *Manager) GetEffectiveRuleChain(node *models.NodeConfig, hasGeosite, hasGeoip bool) []RuleChainEntry {
+	dnsCfg := m.nodeDNSConfig(node)
+
+	if node.RoutingMode == models.RoutingModeSmart {
+		return m.buildRuleChain(node, dnsCfg, hasGeosite, hasGeoip)
+	}
+
+	// 规则原样写入 Xlink 配置，geo 规则不经 Xray，缺少规则数据时也不会被跳过
+	var chain []RuleChainEntry
+	for _, r := range node.Rules {
+		rule := m.convertUserRule(r, dnsCfg)
+		if rule == nil {
+			continue
+		}
+		chain = append(chain, RuleChainEntry{
+			Index:       len(chain),
+			Source:      RuleSourceCore,
+			Name:        r.Type + r.Match,
+			OutboundTag: rule["outboundTag"].(string),
+			RuleID:      r.ID,
+			Rule:        rule,
+		})
+	}
+	chain = append(chain, RuleChainEntry{
+		Index:       len(chain),
+		Source:      RuleSourceBuiltin,
+		Name:        "默认走代理",
+		OutboundTag: "proxy_out",
+		Rule:        map[string]interface{}{"type": "field", "outboundTag": "proxy_out", "port": "0-65535"},
+	})
+	return chain
+}
+
+// buildRuleChain 构建智能分流模式下的完整规则链
+func (m *Manager) buildRuleChain(
+	node *models.NodeConfig,
+	dnsCfg *DNSConfig,
+	hasGeosite, hasGeoip bool,
+) []RuleChainEntry {
+	var chain []RuleChainEntry
+	fallback := m.geoFallbackEnabled()
+
+	add := func(source, name, ruleID string, rule map[string]interface{}) {
+		tag, _ := rule["outboundTag"].(string)
+		chain = append(chain, RuleChainEntry{
+			Index:       len(chain),
+			Source:      source,
+			Name:        name,
+			OutboundTag: tag,
+			RuleID:      ruleID,
+			Rule:        rule,
+		})
+	}
+
+	// 局域网共享：拦截不在允许列表中的客户端
+	socksTags, lanTags := lanShareInboundTags(node)
+	if len(lanTags) > 0 {
+		if blocked := lanShareBlockedSources(&node.LANShare); len(blocked) > 0 {
+			add(RuleSourceBuiltin, "拦截未允许的局域网客户端", "", map[string]interface{}{
+				"type":        "field",
+				"inboundTag":  lanTags,
+				"source":      blocked,
+				"outboundTag": "block",
+			})
+		}
+	}
 
 	// DNS请求路由到dns-out
-	rules = append(rules, map[string]interface{}{
+	add(RuleSourceBuiltin, "DNS请求", "", map[string]interface{}{
 		"type":        "field",
-		"inboundTag":  []string{"socks-in"},
+		"inboundTag":  append([]string{"socks-in"}, socksTags...),
 		"port":        53,
 		"outboundTag": "dns-out",
 	})
 
+	// 按应用分流：列表中的程序直连
+	if node.AppRoutingMode == models.AppRoutingExclude {
+		add(RuleSourceUser, "按应用直连", "", map[string]interface{}{
+			"type":        "field",
+			"outboundTag": "direct",
+			"process":     node.AppRoutingApps,
+		})
+	}
+
 	// 用户自定义规则
 	for _, r := range node.Rules {
 		rule := m.convertUserRule(r, dnsCfg)
 		if rule != nil {
-			rules = append(rules, rule)
+			add(RuleSourceUser, r.Type+r.Match, r.ID, rule)
 		}
 	}
 
 	// 广告拦截
 	if dnsCfg.BlockAds && hasGeosite {
-		rules = append(rules, map[string]interface{}{
+		add(RuleSourceBuiltin, "广告拦截", "", map[string]interface{}{
 			"type":        "field",
 			"outboundTag": "block",
 			"domain":      []string{"geosite:category-ads-all"},
@@ -836,7 +1037,7 @@ func (m *Manager) generateRoutingConfig(
 	}
 
 	// 拦截BT流量
-	rules = append(rules, map[string]interface{}{
+	add(RuleSourceBuiltin, "拦截BT", "", map[string]interface{}{
 		"type":        "field",
 		"outboundTag": "block",
 		"protocol":    []string{"bittorrent"},
@@ -844,16 +1045,22 @@ func (m *Manager) generateRoutingConfig(
 
 	// 私有IP直连 (IPv4)
 	if hasGeoip {
-		rules = append(rules, map[string]interface{}{
+		add(RuleSourceBuiltin, "私有IP直连", "", map[string]interface{}{
 			"type":        "field",
 			"outboundTag": "direct",
 			"ip":          []string{"geoip:private"},
 		})
+	} else if fallback {
+		add(RuleSourceBuiltin, "私有IP直连（内置列表）", "", map[string]interface{}{
+			"type":        "field",
+			"outboundTag": "direct",
+			"ip":          builtinPrivateIPs,
+		})
 	}
 
 	// 私有IPv6直连
 	if dnsCfg.EnableIPv6 && !dnsCfg.DisableIPv6 {
-		rules = append(rules, map[string]interface{}{
+		add(RuleSourceBuiltin, "私有IPv6直连", "", map[string]interface{}{
 			"type":        "field",
 			"outboundTag": "direct",
 			"ip": []string{
@@ -867,32 +1074,62 @@ func (m *Manager) generateRoutingConfig(
 
 	// 中国IP直连
 	if hasGeoip {
-		rules = append(rules, map[string]interface{}{
+		add(RuleSourceBuiltin, "中国IP直连", "", map[string]interface{}{
 			"type":        "field",
 			"outboundTag": "direct",
 			"ip":          []string{"geoip:cn"},
 		})
+	} else if fallback {
+		add(RuleSourceBuiltin, "国内公共DNS直连（内置列表）", "", map[string]interface{}{
+			"type":        "field",
+			"outboundTag": "direct",
+			"ip":          builtinCNDNSIPs,
+		})
 	}
 
 	// 中国域名直连
 	if hasGeosite {
-		rules = append(rules, map[string]interface{}{
+		add(RuleSourceBuiltin, "中国域名直连", "", map[string]interface{}{
 			"type":        "field",
 			"outboundTag": "direct",
 			"domain":      []string{"geosite:cn", "geosite:geolocation-cn"},
 		})
+	} else if fallback {
+		add(RuleSourceBuiltin, "中国域名直连（内置列表）", "", map[string]interface{}{
+			"type":        "field",
+			"outboundTag": "direct",
+			"domain":      builtinCNDomains,
+		})
 	}
 
 	// 默认走代理
-	rules = append(rules, map[string]interface{}{
+	add(RuleSourceBuiltin, "默认走代理", "", map[string]interface{}{
 		"type":        "field",
 		"outboundTag": "proxy_out",
 		"port":        "0-65535",
 	})
 
-	routing["rules"] = rules
+	if node.AppRoutingMode == models.AppRoutingInclude {
+		chain = includeAppsOnly(chain, node.AppRoutingApps)
+	}
+	return chain
+}
 
-	return routing
+// includeAppsOnly 仅列表中的程序走代理：走代理的规则追加进程条件（与原条件同时满足），其余连接最后直连
+// 直连与拦截规则对所有程序生效
+func includeAppsOnly(chain []RuleChainEntry, apps []string) []RuleChainEntry {
+	for i := range chain {
+		if chain[i].OutboundTag == "proxy_out" {
+			chain[i].Rule["process"] = apps
+		}
+	}
+	return append(chain, RuleChainEntry{
+		Index:       len(chain),
+		Source:      RuleSourceUser,
+		Name:        "其余程序直连",
+		OutboundTag: "direct",
+		Rule:        map[string]interface{}{"type": "field", "outboundTag": "direct", "port": "0-65535"},
+	})
 }
 
 // convertUserRule 转换用户规则
@@ -1104,6 +1341,13 @@ func (m *Manager) GetFakeIPStats() map[string]interface{} {
 	}
 }
 
+// FakeIPCounts 已分配的 IPv4 / IPv6 Fake-IP 数量
+func (m *Manager) FakeIPCounts() (ipv4, ipv6 int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.fakeIPMap), len(m.fakeIPv6Map)
+}
+
 // =============================================================================
 // 系统DNS操作（Windows - 支持IPv6）
 // =============================================================================
@@ -1156,145 +1400,104 @@ func (m *Manager) GetSystemDNS() ([]SystemDNSInfo, error) {
 	return results, nil
 }
 
-// getInterfaceDNS 获取指定接口的DNS
+// getInterfaceDNS 获取指定接口的DNS（经 IP Helper API 读取，不依赖系统语言）
 func (m *Manager) getInterfaceDNS(interfaceName string, ipv6 bool) ([]string, error) {
-	var cmd *exec.Cmd
-	if ipv6 {
-		cmd = exec.Command("netsh", "interface", "ipv6", "show", "dns", fmt.Sprintf("name=%s", interfaceName))
-	} else {
-		cmd = exec.Command("netsh", "interface", "ip", "show", "dns", fmt.Sprintf("name=%s", interfaceName))
-	}
+	return interfaceDNS(interfaceName, ipv6)
+}
 
-	output, err := cmd.Output()
+// SetSystemDNS 设置系统DNS（需要管理员权限）
+func (m *Manager) SetSystemDNS(interfaceName string, ipv4DNS, ipv6DNS []string) error {
+	plan, err := m.PlanSystemDNS(interfaceName, ipv4DNS, ipv6DNS)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	return m.parseDNSOutput(string(output), ipv6), nil
-}
-
-// parseDNSOutput 解析DNS输出
-func (m *Manager) parseDNSOutput(output string, ipv6 bool) []string {
-	var dns []string
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// 尝试解析IP地址
-		// 支持格式: "DNS Servers: 8.8.8.8" 或直接IP
-		parts := strings.Split(line, ":")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			ip := net.ParseIP(part)
-			if ip != nil {
-				isV6 := ip.To4() == nil
-				if ipv6 == isV6 {
-					dns = append(dns, ip.String())
-				}
-			}
-		}
+	if err := plan.Run(); err != nil {
+		return fmt.Errorf("设置DNS失败: %w", err)
 	}
-
-	return dns
+	return nil
 }
 
-// SetSystemDNS 设置系统DNS（需要管理员权限）
-func (m *Manager) SetSystemDNS(interfaceName string, ipv4DNS, ipv6DNS []string) error {
+// PlanSystemDNS 生成设置系统DNS的命令（不执行）
+func (m *Manager) PlanSystemDNS(interfaceName string, ipv4DNS, ipv6DNS []string) (*syschange.Plan, error) {
 	if runtime.GOOS != "windows" {
-		return fmt.Errorf("仅支持Windows")
-	}
-
-	var errs []string
-
-	// 设置IPv4 DNS
-	if len(ipv4DNS) > 0 {
-		if err := m.setInterfaceDNS(interfaceName, ipv4DNS, false); err != nil {
-			errs = append(errs, fmt.Sprintf("IPv4: %v", err))
-		}
+		return nil, fmt.Errorf("仅支持Windows")
 	}
 
-	// 设置IPv6 DNS
-	if len(ipv6DNS) > 0 {
-		if err := m.setInterfaceDNS(interfaceName, ipv6DNS, true); err != nil {
-			errs = append(errs, fmt.Sprintf("IPv6: %v", err))
-		}
+	plan := syschange.NewPlan(fmt.Sprintf("设置 %s 的 DNS", interfaceName), true)
+	if err := addInterfaceDNS(plan, interfaceName, ipv4DNS, false); err != nil {
+		return nil, err
 	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("设置DNS失败: %s", strings.Join(errs, "; "))
+	if err := addInterfaceDNS(plan, interfaceName, ipv6DNS, true); err != nil {
+		return nil, err
 	}
-
-	return nil
+	return plan, nil
 }
 
-// setInterfaceDNS 设置指定接口的DNS
-func (m *Manager) setInterfaceDNS(interfaceName string, dns []string, ipv6 bool) error {
+// nativeSetDNS 设置网卡 DNS 的内置实现（SetInterfaceDnsSettings），
+// 参数为网卡名称、ipv4 / ipv6 和逗号分隔的服务器，服务器为空时恢复自动获取
+const nativeSetDNS = "dns.set_interface_dns"
+
+// addInterfaceDNS 追加设置指定接口DNS的命令（优先调用系统 API，不可用时执行 PowerShell）
+func addInterfaceDNS(plan *syschange.Plan, interfaceName string, dns []string, ipv6 bool) error {
 	if len(dns) == 0 {
 		return nil
 	}
 
-	var protocol string
+	protocol, family := "ipv4", "IPv4"
 	if ipv6 {
-		protocol = "ipv6"
-	} else {
-		protocol = "ip"
+		protocol, family = "ipv6", "IPv6"
+	}
+	quoted := make([]string, 0, len(dns))
+	for _, addr := range dns {
+		ip := net.ParseIP(addr)
+		if ip == nil || (ip.To4() == nil) != ipv6 {
+			return fmt.Errorf("%s DNS 地址无效: %s", family, addr)
+		}
+		quoted = append(quoted, psQuote(ip.String()))
 	}
 
-	// 设置主DNS
-	cmd := exec.Command("netsh", "interface", protocol, "set", "dns",
-		fmt.Sprintf("name=%s", interfaceName),
-		"source=static",
-		fmt.Sprintf("addr=%s", dns[0]),
-		"validate=no",
+	plan.AddNative(fmt.Sprintf("设置%s DNS %s", family, strings.Join(dns, ", ")),
+		nativeSetDNS, []string{interfaceName, protocol, strings.Join(dns, ",")},
+		"powershell", powerShellArgs(fmt.Sprintf("Set-DnsClientServerAddress -InterfaceAlias %s -ServerAddresses %s",
+			psQuote(interfaceName), strings.Join(quoted, ",")))...,
 	)
+	return nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("设置主DNS失败: %v", err)
-	}
-
-	// 添加备用DNS
-	for i := 1; i < len(dns); i++ {
-		cmd = exec.Command("netsh", "interface", protocol, "add", "dns",
-			fmt.Sprintf("name=%s", interfaceName),
-			fmt.Sprintf("addr=%s", dns[i]),
-			fmt.Sprintf("index=%d", i+1),
-			"validate=no",
-		)
-		cmd.Run() // 忽略错误
-	}
+// powerShellArgs 执行一条 PowerShell 命令的参数
+func powerShellArgs(script string) []string {
+	return []string{"-NoProfile", "-NonInteractive", "-Command", script}
+}
 
-	return nil
+// psQuote PowerShell 单引号字符串
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
 
 // ResetSystemDNS 重置系统DNS为自动获取
 func (m *Manager) ResetSystemDNS(interfaceName string) error {
-	if runtime.GOOS != "windows" {
-		return fmt.Errorf("仅支持Windows")
+	plan, err := m.PlanResetSystemDNS(interfaceName)
+	if err != nil {
+		return err
 	}
-
-	// 重置IPv4 DNS
-	cmd := exec.Command("netsh", "interface", "ip", "set", "dns",
-		fmt.Sprintf("name=%s", interfaceName),
-		"source=dhcp",
-	)
-	if err := cmd.Run(); err != nil {
-		m.log("warn", fmt.Sprintf("重置IPv4 DNS失败: %v", err))
+	for _, err := range plan.RunAll() {
+		m.log("warn", err.Error())
 	}
+	return nil
+}
 
-	// 重置IPv6 DNS
-	cmd = exec.Command("netsh", "interface", "ipv6", "set", "dns",
-		fmt.Sprintf("name=%s", interfaceName),
-		"source=dhcp",
-	)
-	if err := cmd.Run(); err != nil {
-		m.log("warn", fmt.Sprintf("重置IPv6 DNS失败: %v", err))
+// PlanResetSystemDNS 生成重置系统DNS的命令（不执行）
+func (m *Manager) PlanResetSystemDNS(interfaceName string) (*syschange.Plan, error) {
+	if runtime.GOOS != "windows" {
+		return nil, fmt.Errorf("仅支持Windows")
 	}
 
-	return nil
+	// Set-DnsClientServerAddress -ResetServerAddresses 同时重置两种地址，两条命令的命令行相同
+	reset := powerShellArgs(fmt.Sprintf("Set-DnsClientServerAddress -InterfaceAlias %s -ResetServerAddresses", psQuote(interfaceName)))
+	plan := syschange.NewPlan(fmt.Sprintf("重置 %s 的 DNS", interfaceName), true)
+	plan.AddNative("重置IPv4 DNS", nativeSetDNS, []string{interfaceName, "ipv4", ""}, "powershell", reset...)
+	plan.AddNative("重置IPv6 DNS", nativeSetDNS, []string{interfaceName, "ipv6", ""}, "powershell", reset...)
+	return plan, nil
 }
 
 // =============================================================================
@@ -1376,7 +1579,7 @@ type IPv6SupportInfo struct {
 
 // WriteXrayConfig 写入Xray配置文件
 func (m *Manager) WriteXrayConfig(config *XrayFullConfig, path string) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := config.Render()
 	if err != nil {
 		return fmt.Errorf("序列化配置失败: %w", err)
 	}
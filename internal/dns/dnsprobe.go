@@ -0,0 +1,176 @@
+package dns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"xlink-wails/internal/socks5"
+)
+
+// =============================================================================
+// 经代理的 UDP DNS 探测
+// =============================================================================
+
+// 经节点的 SOCKS5 入站建立 UDP 关联，向公共解析服务器查询一个返回“查询来源地址”的 TXT 记录
+// （Google 的 o-o.myaddr.l.google.com），解析服务器看到的来源即 DNS 查询实际的出口：
+// 来源是节点的出口说明 UDP DNS 经过了节点，来源位于境内则说明查询绕过了节点。
+
+const (
+	// DefaultDNSProbeServer UDP DNS 探测的解析服务器
+	DefaultDNSProbeServer = "8.8.8.8:53"
+	// DefaultDNSProbeName 返回查询来源地址的 TXT 域名
+	DefaultDNSProbeName = "o-o.myaddr.l.google.com"
+	// dnsProbeTimeout 探测的总超时
+	dnsProbeTimeout = 5 * time.Second
+	// dnsTypeTXT TXT 记录类型
+	dnsTypeTXT = 16
+)
+
+// DNSProbeResult UDP DNS 探测结果
+type DNSProbeResult struct {
+	Server   string        `json:"server"`
+	Name     string        `json:"name"`
+	SourceIP string        `json:"source_ip,omitempty"` // 解析服务器看到的查询来源
+	Source   IPAttribution `json:"source"`              // 来源的归属（有离线数据时）
+	RTTMs    int64         `json:"rtt_ms,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// SetDNSProbe 设置 UDP DNS 探测的解析服务器和查询域名（为空时使用默认值）
+func (t *LeakTester) SetDNSProbe(server, name string) {
+	t.probeServer, t.probeName = server, name
+}
+
+// probeConn 发送探测的 UDP 连接（经代理的关联或直连）
+type probeConn interface {
+	WriteTo(p []byte, target string) (int, error)
+	Read(p []byte) (int, error)
+	SetDeadline(t time.Time) error
+	Close() error
+}
+
+// directProbeConn 直连的 UDP 连接
+type directProbeConn struct {
+	*net.UDPConn
+}
+
+func (c directProbeConn) WriteTo(p []byte, _ string) (int, error) {
+	return c.Write(p)
+}
+
+// ProbeDNS 经代理的 UDP 关联发送 DNS 探测，proxy 为 nil 时直接发送（作为对比的基线）
+func (t *LeakTester) ProbeDNS(proxy *socks5.Dialer) *DNSProbeResult {
+	result := &DNSProbeResult{Server: t.probeServer, Name: t.probeName}
+	if result.Server == "" {
+		result.Server = DefaultDNSProbeServer
+	}
+	if result.Name == "" {
+		result.Name = DefaultDNSProbeName
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsProbeTimeout)
+	defer cancel()
+	var conn probeConn
+	var err error
+	if proxy != nil {
+		conn, err = proxy.ListenUDP(ctx)
+	} else {
+		var d net.Dialer
+		var c net.Conn
+		if c, err = d.DialContext(ctx, "udp", result.Server); err == nil {
+			conn = directProbeConn{c.(*net.UDPConn)}
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dnsProbeTimeout))
+
+	query := buildTXTQuery(result.Name)
+	start := time.Now()
+	if _, err := conn.WriteTo(query, result.Server); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			result.Error = fmt.Sprintf("未收到 DNS 应答，代理可能不支持 UDP 转发: %v", err)
+			return result
+		}
+		// 忽略 ID 不匹配的报文
+		if n < dnsHeaderSize || buf[0] != query[0] || buf[1] != query[1] {
+			continue
+		}
+		result.RTTMs = time.Since(start).Milliseconds()
+		ip, err := txtSourceIP(buf[:n])
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.SourceIP = ip
+		break
+	}
+
+	result.Source = IPAttribution{IP: result.SourceIP}
+	if t.geoDB != nil {
+		if attrs, _ := t.geoDB.Lookup([]string{result.SourceIP}); attrs != nil {
+			result.Source = attrs[result.SourceIP]
+		}
+	}
+	return result
+}
+
+// buildTXTQuery 生成 TXT 查询报文（随机 ID，期望递归）
+func buildTXTQuery(name string) []byte {
+	msg := make([]byte, dnsHeaderSize)
+	rand.Read(msg[:2])
+	binary.BigEndian.PutUint16(msg[2:], 0x0100) // RD
+	binary.BigEndian.PutUint16(msg[4:], 1)
+	msg = append(msg, encodeName(name)...)
+	msg = binary.BigEndian.AppendUint16(msg, dnsTypeTXT)
+	return binary.BigEndian.AppendUint16(msg, dnsClassIN)
+}
+
+// txtSourceIP 从应答的 TXT 记录中取出第一个 IP 地址
+func txtSourceIP(msg []byte) (string, error) {
+	if rcode := binary.BigEndian.Uint16(msg[2:]) & 0xF; rcode != 0 {
+		return "", fmt.Errorf("DNS 应答错误码 %d", rcode)
+	}
+	var found string
+	err := walkRecords(msg, func(rtype uint16, ttlOff int) {
+		if rtype != dnsTypeTXT || found != "" {
+			return
+		}
+		rdlen := int(binary.BigEndian.Uint16(msg[ttlOff+4:]))
+		if ttlOff+6+rdlen > len(msg) {
+			return
+		}
+		rdata := msg[ttlOff+6 : ttlOff+6+rdlen]
+		for len(rdata) > 0 {
+			l := int(rdata[0])
+			if 1+l > len(rdata) {
+				return
+			}
+			if s := string(rdata[1 : 1+l]); net.ParseIP(s) != nil {
+				found = s
+				return
+			}
+			rdata = rdata[1+l:]
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("DNS 应答中没有来源地址")
+	}
+	return found, nil
+}
@@ -0,0 +1,259 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// geosite.dat / geoip.dat 下载与更新
+// =============================================================================
+
+// GeoDataFiles 需要维护的规则数据文件
+var GeoDataFiles = []string{"geosite.dat", "geoip.dat"}
+
+// DefaultGeoDataMirrors 默认下载镜像（按顺序尝试，文件名追加在末尾，校验文件为 <文件名>.sha256sum）
+var DefaultGeoDataMirrors = []string{
+	"https://github.com/Loyalsoldier/v2ray-rules-dat/releases/latest/download/",
+	"https://cdn.jsdelivr.net/gh/Loyalsoldier/v2ray-rules-dat@release/",
+	"https://fastly.jsdelivr.net/gh/Loyalsoldier/v2ray-rules-dat@release/",
+}
+
+const (
+	// geoDataMaxSize 单个文件的大小上限，防止镜像异常时写满磁盘
+	geoDataMaxSize = 128 << 20
+	// geoDataProgressInterval 进度回调的最小间隔
+	geoDataProgressInterval = 200 * time.Millisecond
+)
+
+// GeoDataProgress 下载进度
+type GeoDataProgress struct {
+	File       string `json:"file"`
+	Mirror     string `json:"mirror"`
+	Downloaded int64  `json:"downloaded"`
+	Total      int64  `json:"total"` // 未知时为 -1
+}
+
+// GeoDataResult 单个文件的更新结果
+type GeoDataResult struct {
+	File    string `json:"file"`
+	Success bool   `json:"success"`
+	Mirror  string `json:"mirror,omitempty"`
+	SHA256  string `json:"sha256,omitempty"`
+	Size    int64  `json:"size"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GeoDataManager 规则数据下载管理器
+type GeoDataManager struct {
+	exeDir     string
+	mu         sync.Mutex
+	updating   bool
+	onProgress func(GeoDataProgress)
+}
+
+// NewGeoDataManager 创建规则数据管理器
+func NewGeoDataManager(exeDir string) *GeoDataManager {
+	return &GeoDataManager{exeDir: exeDir}
+}
+
+// SetProgressCallback 设置下载进度回调
+func (g *GeoDataManager) SetProgressCallback(cb func(GeoDataProgress)) {
+	g.onProgress = cb
+}
+
+// Missing 返回缺失的规则数据文件
+func (g *GeoDataManager) Missing() []string {
+	var missing []string
+	for _, name := range GeoDataFiles {
+		if fi, err := os.Stat(filepath.Join(g.exeDir, name)); err != nil || fi.Size() == 0 {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// OldestUpdate 返回规则数据中最旧的修改时间（有文件缺失时返回零值）
+func (g *GeoDataManager) OldestUpdate() time.Time {
+	var oldest time.Time
+	for _, name := range GeoDataFiles {
+		fi, err := os.Stat(filepath.Join(g.exeDir, name))
+		if err != nil {
+			return time.Time{}
+		}
+		if oldest.IsZero() || fi.ModTime().Before(oldest) {
+			oldest = fi.ModTime()
+		}
+	}
+	return oldest
+}
+
+// IsUpdating 是否正在更新
+func (g *GeoDataManager) IsUpdating() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.updating
+}
+
+// Update 依次从镜像下载文件并校验 SHA256，校验通过后替换原文件
+// files 为空时更新全部规则数据；mirrors 为空时使用默认镜像
+func (g *GeoDataManager) Update(ctx context.Context, client *http.Client, mirrors []string, files ...string) ([]GeoDataResult, error) {
+	g.mu.Lock()
+	if g.updating {
+		g.mu.Unlock()
+		return nil, fmt.Errorf("规则数据正在更新中")
+	}
+	g.updating = true
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.updating = false
+		g.mu.Unlock()
+	}()
+
+	if len(files) == 0 {
+		files = GeoDataFiles
+	}
+	if len(mirrors) == 0 {
+		mirrors = DefaultGeoDataMirrors
+	}
+
+	results := make([]GeoDataResult, 0, len(files))
+	for _, name := range files {
+		result := GeoDataResult{File: name}
+
+		var errs []string
+		for _, mirror := range mirrors {
+			if ctx.Err() != nil {
+				errs = append(errs, ctx.Err().Error())
+				break
+			}
+			sum, size, err := g.download(ctx, client, mirror, name)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", mirror, err))
+				continue
+			}
+			result.Success, result.Mirror, result.SHA256, result.Size = true, mirror, sum, size
+			break
+		}
+		if !result.Success {
+			result.Error = strings.Join(errs, "; ")
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// download 从单个镜像下载文件到临时文件，校验后替换
+func (g *GeoDataManager) download(ctx context.Context, client *http.Client, mirror, name string) (string, int64, error) {
+	base := strings.TrimSuffix(mirror, "/") + "/" + name
+
+	expected, err := fetchChecksum(ctx, client, base+".sha256sum")
+	if err != nil {
+		return "", 0, fmt.Errorf("获取校验值失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	target := filepath.Join(g.exeDir, name)
+	tmp, err := os.CreateTemp(g.exeDir, name+".*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	total := resp.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	h := sha256.New()
+	counter := &progressWriter{file: name, mirror: mirror, total: total, cb: g.onProgress}
+	size, err := io.Copy(io.MultiWriter(tmp, h, counter), io.LimitReader(resp.Body, geoDataMaxSize+1))
+	tmp.Close()
+	if err != nil {
+		return "", 0, fmt.Errorf("下载失败: %w", err)
+	}
+	if size > geoDataMaxSize {
+		return "", 0, fmt.Errorf("文件超过大小上限")
+	}
+	counter.report(true)
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(sum, expected) {
+		return "", 0, fmt.Errorf("SHA256 校验失败")
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", 0, fmt.Errorf("替换文件失败: %w", err)
+	}
+	return sum, size, nil
+}
+
+// fetchChecksum 读取 sha256sum 文件（格式: "<hash>  <文件名>"）
+func fetchChecksum(ctx context.Context, client *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 4096))
+	if scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 && len(fields[0]) == sha256.Size*2 {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("校验文件格式错误")
+}
+
+// progressWriter 统计写入字节数并限频回调进度
+type progressWriter struct {
+	file, mirror string
+	total        int64
+	written      int64
+	last         time.Time
+	cb           func(GeoDataProgress)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	w.report(false)
+	return len(p), nil
+}
+
+func (w *progressWriter) report(force bool) {
+	if w.cb == nil || (!force && time.Since(w.last) < geoDataProgressInterval) {
+		return
+	}
+	w.last = time.Now()
+	w.cb(GeoDataProgress{File: w.file, Mirror: w.mirror, Downloaded: w.written, Total: w.total})
+}
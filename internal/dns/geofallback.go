@@ -0,0 +1,140 @@
+package dns
+
+import (
+	"strings"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 规则数据缺失时的降级处理
+// =============================================================================
+
+// 缺少 geosite.dat / geoip.dat 时，依赖它们的规则无法加载（Xray 会直接启动失败），
+// 因此生成配置时跳过这些规则，并向用户报告跳过了哪些规则。
+// 开启内置列表时，用最小的私有地址段 / 中国域名列表代替，保证智能分流仍大致可用。
+
+// builtinPrivateIPs 私有及保留 IPv4 地址段（代替 geoip:private）
+var builtinPrivateIPs = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+}
+
+// builtinCNDNSIPs 国内公共 DNS 服务器地址。缺少 geoip.dat 时代替 geoip:cn 的直连规则，
+// 但只保证这几个 DNS 服务器直连，并不是中国 IP 地址列表（其余国内 IP 按默认规则走代理）
+var builtinCNDNSIPs = []string{
+	"223.5.5.5/32",
+	"223.6.6.6/32",
+	"119.29.29.29/32",
+	"114.114.114.114/32",
+	"114.114.115.115/32",
+	"180.76.76.76/32",
+}
+
+// builtinCNDomains 常用国内域名（代替 geosite:cn）
+var builtinCNDomains = []string{
+	"domain:cn",
+	"domain:baidu.com",
+	"domain:bdstatic.com",
+	"domain:qq.com",
+	"domain:gtimg.com",
+	"domain:weixin.com",
+	"domain:taobao.com",
+	"domain:tmall.com",
+	"domain:alipay.com",
+	"domain:alicdn.com",
+	"domain:aliyun.com",
+	"domain:jd.com",
+	"domain:163.com",
+	"domain:126.net",
+	"domain:bilibili.com",
+	"domain:hdslb.com",
+	"domain:weibo.com",
+	"domain:sina.com",
+	"domain:zhihu.com",
+	"domain:douyin.com",
+	"domain:bytedance.com",
+	"domain:meituan.com",
+	"domain:xiaomi.com",
+	"domain:huawei.com",
+	"domain:csdn.net",
+	"domain:mi.com",
+}
+
+// SkippedGeoRule 因缺少规则数据被跳过的规则
+type SkippedGeoRule struct {
+	Source      string `json:"source"` // RuleSourceBuiltin / RuleSourceUser
+	Name        string `json:"name"`
+	RuleID      string `json:"rule_id,omitempty"`
+	File        string `json:"file"`        // 缺少的文件
+	Substituted bool   `json:"substituted"` // 已用内置列表代替
+}
+
+// SetGeoFallback 设置缺少规则数据时是否使用内置列表代替
+func (m *Manager) SetGeoFallback(enabled bool) {
+	m.mu.Lock()
+	m.geoFallback = enabled
+	m.mu.Unlock()
+}
+
+func (m *Manager) geoFallbackEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.geoFallback
+}
+
+// SkippedGeoRules 列出智能分流模式下因缺少规则数据而跳过的规则
+func (m *Manager) SkippedGeoRules(node *models.NodeConfig, hasGeosite, hasGeoip bool) []SkippedGeoRule {
+	if node.RoutingMode != models.RoutingModeSmart || (hasGeosite && hasGeoip) {
+		return nil
+	}
+
+	dnsCfg := m.nodeDNSConfig(node)
+	fallback := m.geoFallbackEnabled()
+	var skipped []SkippedGeoRule
+
+	for _, r := range node.Rules {
+		if file := userRuleGeoFile(r); file != "" && !geoFileAvailable(file, hasGeosite, hasGeoip) {
+			skipped = append(skipped, SkippedGeoRule{Source: RuleSourceUser, Name: r.Type + r.Match, RuleID: r.ID, File: file})
+		}
+	}
+
+	if dnsCfg.BlockAds && !hasGeosite {
+		skipped = append(skipped, SkippedGeoRule{Source: RuleSourceBuiltin, Name: "广告拦截", File: "geosite.dat"})
+	}
+	if !hasGeoip {
+		skipped = append(skipped,
+			SkippedGeoRule{Source: RuleSourceBuiltin, Name: "私有IP直连", File: "geoip.dat", Substituted: fallback},
+			SkippedGeoRule{Source: RuleSourceBuiltin, Name: "中国IP直连", File: "geoip.dat", Substituted: fallback},
+		)
+	}
+	if !hasGeosite {
+		skipped = append(skipped, SkippedGeoRule{Source: RuleSourceBuiltin, Name: "中国域名直连", File: "geosite.dat", Substituted: fallback})
+	}
+	return skipped
+}
+
+// userRuleGeoFile 用户规则依赖的规则数据文件，不依赖时返回空
+func userRuleGeoFile(r models.RoutingRule) string {
+	switch strings.ToLower(r.Type) {
+	case "geosite:", "geosite":
+		return "geosite.dat"
+	case "geoip:", "geoip":
+		return "geoip.dat"
+	}
+	return ""
+}
+
+func geoFileAvailable(file string, hasGeosite, hasGeoip bool) bool {
+	if file == "geosite.dat" {
+		return hasGeosite
+	}
+	return hasGeoip
+}
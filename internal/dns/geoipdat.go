@@ -0,0 +1,187 @@
+package dns
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// =============================================================================
+// geoip.dat 离线查询
+// =============================================================================
+
+// geoip.dat 为 v2ray 的 protobuf 格式:
+//   GeoIPList { repeated GeoIP entry = 1; }
+//   GeoIP     { string country_code = 1; repeated CIDR cidr = 2; }
+//   CIDR      { bytes ip = 1; uint32 prefix = 2; }
+// 这里只做一次顺序扫描，不构建完整的数据结构，也不需要引入 protobuf 依赖。
+
+// LookupGeoIPDat 在 geoip.dat 中查询一组IP所属的标签（国家代码或 google/cloudflare 等服务标签，小写）
+func LookupGeoIPDat(path string, ips []string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type query struct {
+		raw string
+		ip  net.IP
+	}
+	var queries []query
+	for _, s := range ips {
+		if ip := net.ParseIP(s); ip != nil {
+			queries = append(queries, query{raw: s, ip: ip})
+		}
+	}
+
+	result := make(map[string][]string)
+	if len(queries) == 0 {
+		return result, nil
+	}
+
+	err = walkProto(data, func(field int, entry []byte) error {
+		if field != 1 {
+			return nil
+		}
+
+		var code string
+		var cidrs [][]byte
+		if err := walkProto(entry, func(f int, v []byte) error {
+			switch f {
+			case 1:
+				code = strings.ToLower(string(v))
+			case 2:
+				cidrs = append(cidrs, v)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, c := range cidrs {
+			ipNet, err := parseGeoIPCIDR(c)
+			if err != nil {
+				return err
+			}
+			for _, q := range queries {
+				if ipNet.Contains(q.ip) && !containsString(result[q.raw], code) {
+					result[q.raw] = append(result[q.raw], code)
+				}
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// parseGeoIPCIDR 解析 CIDR 消息
+func parseGeoIPCIDR(msg []byte) (*net.IPNet, error) {
+	var ip []byte
+	var prefix uint64
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, fmt.Errorf("geoip.dat 格式错误")
+		}
+		msg = msg[n:]
+
+		switch key {
+		case 1<<3 | 2: // ip
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < l {
+				return nil, fmt.Errorf("geoip.dat 格式错误")
+			}
+			ip = msg[n : n+int(l)]
+			msg = msg[n+int(l):]
+		case 2 << 3: // prefix (varint)
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return nil, fmt.Errorf("geoip.dat 格式错误")
+			}
+			prefix = v
+			msg = msg[n:]
+		default:
+			rest, err := skipProtoField(msg, key&7)
+			if err != nil {
+				return nil, err
+			}
+			msg = rest
+		}
+	}
+
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return nil, fmt.Errorf("geoip.dat 中的IP长度无效: %d", len(ip))
+	}
+	return &net.IPNet{IP: net.IP(ip), Mask: net.CIDRMask(int(prefix), len(ip)*8)}, nil
+}
+
+// walkProto 遍历消息中的长度前缀字段（wire type 2），其他类型的字段跳过
+func walkProto(msg []byte, fn func(field int, value []byte) error) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return fmt.Errorf("geoip.dat 格式错误")
+		}
+		msg = msg[n:]
+
+		if key&7 != 2 {
+			rest, err := skipProtoField(msg, key&7)
+			if err != nil {
+				return err
+			}
+			msg = rest
+			continue
+		}
+
+		l, n := binary.Uvarint(msg)
+		if n <= 0 || uint64(len(msg)-n) < l {
+			return fmt.Errorf("geoip.dat 格式错误")
+		}
+		if err := fn(int(key>>3), msg[n:n+int(l)]); err != nil {
+			return err
+		}
+		msg = msg[n+int(l):]
+	}
+	return nil
+}
+
+// skipProtoField 跳过一个非长度前缀字段
+func skipProtoField(msg []byte, wireType uint64) ([]byte, error) {
+	switch wireType {
+	case 0: // varint
+		_, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, fmt.Errorf("geoip.dat 格式错误")
+		}
+		return msg[n:], nil
+	case 1: // 64 位
+		if len(msg) < 8 {
+			return nil, fmt.Errorf("geoip.dat 格式错误")
+		}
+		return msg[8:], nil
+	case 2:
+		l, n := binary.Uvarint(msg)
+		if n <= 0 || uint64(len(msg)-n) < l {
+			return nil, fmt.Errorf("geoip.dat 格式错误")
+		}
+		return msg[n+int(l):], nil
+	case 5: // 32 位
+		if len(msg) < 4 {
+			return nil, fmt.Errorf("geoip.dat 格式错误")
+		}
+		return msg[4:], nil
+	default:
+		return nil, fmt.Errorf("geoip.dat 格式错误: 未知字段类型 %d", wireType)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
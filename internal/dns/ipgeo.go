@@ -0,0 +1,217 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// IP 归属离线查询
+// =============================================================================
+
+// 泄露分析需要知道 DNS 服务器和出口 IP 属于哪个国家、哪个自治系统（ASN）。
+// 查询只使用程序目录中的离线数据，不发起网络请求（查询本身不会绕过代理，也不会暴露被查询的 IP）：
+//   - 国家：优先使用国家 MMDB（GeoLite2-Country / Country.mmdb 等），没有时使用 geoip.dat；
+//   - ASN：使用 GeoLite2-ASN.mmdb（可选，没有时不提供 ASN）。
+// MMDB 按文件修改时间缓存在内存中，替换文件后下次查询自动重新载入。
+
+// CountryMMDBFiles 国家数据库的候选文件名（按顺序使用第一个存在的）
+var CountryMMDBFiles = []string{"GeoLite2-Country.mmdb", "Country.mmdb", "GeoLite2-City.mmdb"}
+
+// ASNMMDBFiles ASN 数据库的候选文件名
+var ASNMMDBFiles = []string{"GeoLite2-ASN.mmdb"}
+
+// IPAttribution IP 的归属信息
+type IPAttribution struct {
+	IP          string   `json:"ip"`
+	CountryCode string   `json:"country_code,omitempty"` // ISO 3166-1 两位代码（大写）
+	Country     string   `json:"country,omitempty"`      // 国家名称（数据库提供时）
+	ASN         uint32   `json:"asn,omitempty"`
+	ASOrg       string   `json:"as_org,omitempty"`
+	Tags        []string `json:"-"` // geoip.dat 中的服务标签（google、cloudflare 等）
+}
+
+// IsChina 是否为中国大陆 IP
+func (a IPAttribution) IsChina() bool {
+	return a.CountryCode == "CN"
+}
+
+// IPGeoDB 离线 IP 归属数据库
+type IPGeoDB struct {
+	dir string
+	mu  sync.Mutex
+	dbs map[string]*cachedMMDB
+}
+
+type cachedMMDB struct {
+	modTime time.Time
+	size    int64
+	reader  *MMDBReader
+}
+
+// NewIPGeoDB 创建 IP 归属数据库，数据文件位于 dir
+func NewIPGeoDB(dir string) *IPGeoDB {
+	return &IPGeoDB{dir: dir, dbs: make(map[string]*cachedMMDB)}
+}
+
+// Sources 当前可用的数据文件
+func (g *IPGeoDB) Sources() []string {
+	var sources []string
+	if name := g.firstExisting(CountryMMDBFiles); name != "" {
+		sources = append(sources, name)
+	} else if g.firstExisting([]string{"geoip.dat"}) != "" {
+		sources = append(sources, "geoip.dat")
+	}
+	if name := g.firstExisting(ASNMMDBFiles); name != "" {
+		sources = append(sources, name)
+	}
+	return sources
+}
+
+// HasCountryData 是否有可用的国家数据
+func (g *IPGeoDB) HasCountryData() bool {
+	return g.firstExisting(CountryMMDBFiles) != "" || g.firstExisting([]string{"geoip.dat"}) != ""
+}
+
+// Lookup 查询一组 IP 的归属；无效的 IP 不出现在结果中
+// 某个数据文件读取失败时仍返回其余数据的查询结果和该错误
+func (g *IPGeoDB) Lookup(ips []string) (map[string]IPAttribution, error) {
+	result := make(map[string]IPAttribution, len(ips))
+	parsed := make(map[string]net.IP, len(ips))
+	for _, s := range ips {
+		if ip := net.ParseIP(s); ip != nil {
+			parsed[s] = ip
+			result[s] = IPAttribution{IP: s}
+		}
+	}
+	if len(parsed) == 0 {
+		return result, nil
+	}
+
+	var errs []string
+	if name := g.firstExisting(CountryMMDBFiles); name != "" {
+		if err := g.lookupMMDB(name, parsed, result, applyCountryRecord); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	// geoip.dat 补充服务标签，没有国家 MMDB 时也提供国家代码
+	if tags, err := LookupGeoIPDat(filepath.Join(g.dir, "geoip.dat"), ips); err != nil {
+		if !os.IsNotExist(err) {
+			errs = append(errs, fmt.Sprintf("geoip.dat: %v", err))
+		}
+	} else {
+		for ip, list := range tags {
+			attr, ok := result[ip]
+			if !ok {
+				continue
+			}
+			for _, tag := range list {
+				if len(tag) == 2 {
+					if attr.CountryCode == "" {
+						attr.CountryCode = strings.ToUpper(tag)
+					}
+				} else {
+					attr.Tags = append(attr.Tags, tag)
+				}
+			}
+			result[ip] = attr
+		}
+	}
+	if name := g.firstExisting(ASNMMDBFiles); name != "" {
+		if err := g.lookupMMDB(name, parsed, result, applyASNRecord); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// lookupMMDB 在指定数据库中查询并把记录写入结果
+func (g *IPGeoDB) lookupMMDB(name string, ips map[string]net.IP, result map[string]IPAttribution, apply func(*IPAttribution, map[string]interface{})) error {
+	reader, err := g.open(name)
+	if err != nil {
+		return err
+	}
+	for s, ip := range ips {
+		record, err := reader.Lookup(ip)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			continue
+		}
+		attr := result[s]
+		apply(&attr, record)
+		result[s] = attr
+	}
+	return nil
+}
+
+// open 载入数据库（文件未变化时使用缓存）
+func (g *IPGeoDB) open(name string) (*MMDBReader, error) {
+	path := filepath.Join(g.dir, name)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.dbs[name]; ok && c.modTime.Equal(fi.ModTime()) && c.size == fi.Size() {
+		return c.reader, nil
+	}
+	reader, err := OpenMMDB(path)
+	if err != nil {
+		delete(g.dbs, name)
+		return nil, err
+	}
+	g.dbs[name] = &cachedMMDB{modTime: fi.ModTime(), size: fi.Size(), reader: reader}
+	return reader, nil
+}
+
+// firstExisting 候选文件中第一个存在的（不存在时为空）
+func (g *IPGeoDB) firstExisting(names []string) string {
+	for _, name := range names {
+		if fi, err := os.Stat(filepath.Join(g.dir, name)); err == nil && fi.Size() > 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// applyCountryRecord 国家数据库记录：优先取 country，没有时取注册国家
+func applyCountryRecord(attr *IPAttribution, record map[string]interface{}) {
+	for _, key := range []string{"country", "registered_country"} {
+		c, ok := record[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		code, _ := c["iso_code"].(string)
+		if code == "" {
+			continue
+		}
+		attr.CountryCode = strings.ToUpper(code)
+		if names, ok := c["names"].(map[string]interface{}); ok {
+			if name, _ := names["zh-CN"].(string); name != "" {
+				attr.Country = name
+			} else if name, _ := names["en"].(string); name != "" {
+				attr.Country = name
+			}
+		}
+		return
+	}
+}
+
+// applyASNRecord ASN 数据库记录
+func applyASNRecord(attr *IPAttribution, record map[string]interface{}) {
+	attr.ASN = uint32(mmdbUint(record["autonomous_system_number"]))
+	attr.ASOrg, _ = record["autonomous_system_organization"].(string)
+}
@@ -0,0 +1,171 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 自动 IPv4/IPv6 策略 (Happy Eyeballs 偏好)
+// =============================================================================
+
+// 自动策略的决策结果
+const (
+	IPDecisionPreferIPv4 = "prefer_ipv4"
+	IPDecisionPreferIPv6 = "prefer_ipv6"
+	IPDecisionIPv4Only   = "ipv4_only" // 服务器 IPv6 不可达
+	IPDecisionIPv6Only   = "ipv6_only" // 服务器 IPv4 不可达
+	IPDecisionUnknown    = "unknown"   // 两者都不可达，保持节点原有设置
+)
+
+const (
+	// ipProbeTimeout 单次 TCP 建连超时
+	ipProbeTimeout = 3 * time.Second
+	// ipProbeMaxServers 每次最多测量的服务器数
+	ipProbeMaxServers = 5
+	// IPStrategySwitchMargin 重新评估时，两种协议延迟差小于此值则不切换（防止来回抖动）
+	IPStrategySwitchMargin = 20
+)
+
+// IPFamilyProbe 服务器 IPv4/IPv6 延迟测量结果
+type IPFamilyProbe struct {
+	IPv4Latency int       `json:"ipv4_latency"` // 毫秒，-1 表示不可达
+	IPv6Latency int       `json:"ipv6_latency"` // 毫秒，-1 表示不可达
+	Decision    string    `json:"decision"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// ProbeIPFamilies 分别通过 IPv4 和 IPv6 连接节点服务器，取各自最低延迟并给出决策
+func ProbeIPFamilies(ctx context.Context, server string) *IPFamilyProbe {
+	servers := models.SplitServers(server)
+	if len(servers) > ipProbeMaxServers {
+		servers = servers[:ipProbeMaxServers]
+	}
+
+	probe := &IPFamilyProbe{IPv4Latency: -1, IPv6Latency: -1, CheckedAt: time.Now()}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	record := func(latency *int, ms int) {
+		mu.Lock()
+		if *latency < 0 || ms < *latency {
+			*latency = ms
+		}
+		mu.Unlock()
+	}
+
+	for _, s := range servers {
+		host, port := splitServerHostPort(s)
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			continue
+		}
+
+		var v4, v6 net.IP
+		for _, ip := range ips {
+			if ip.IP.To4() != nil {
+				if v4 == nil {
+					v4 = ip.IP
+				}
+			} else if v6 == nil {
+				v6 = ip.IP
+			}
+		}
+
+		if v4 != nil {
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				if ms := dialLatency(ctx, "tcp4", addr); ms >= 0 {
+					record(&probe.IPv4Latency, ms)
+				}
+			}(net.JoinHostPort(v4.String(), port))
+		}
+		if v6 != nil {
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				if ms := dialLatency(ctx, "tcp6", addr); ms >= 0 {
+					record(&probe.IPv6Latency, ms)
+				}
+			}(net.JoinHostPort(v6.String(), port))
+		}
+	}
+	wg.Wait()
+
+	probe.Decision = decideIPFamily(probe.IPv4Latency, probe.IPv6Latency)
+	return probe
+}
+
+// Apply 将决策写入节点的 IPv6 开关（应传入副本），未知时保持原样
+func (p *IPFamilyProbe) Apply(node *models.NodeConfig) {
+	switch p.Decision {
+	case IPDecisionPreferIPv4:
+		node.EnableIPv6, node.PreferIPv6, node.DisableIPv6, node.IPv6Only = true, false, false, false
+	case IPDecisionPreferIPv6:
+		node.EnableIPv6, node.PreferIPv6, node.DisableIPv6, node.IPv6Only = true, true, false, false
+	case IPDecisionIPv4Only:
+		node.EnableIPv6, node.PreferIPv6, node.DisableIPv6, node.IPv6Only = false, false, true, false
+	case IPDecisionIPv6Only:
+		node.EnableIPv6, node.PreferIPv6, node.DisableIPv6, node.IPv6Only = true, true, false, true
+	}
+}
+
+// ShouldSwitch 判断新的测量结果是否值得切换（决策相同或延迟差在阈值内时不切换）
+func (p *IPFamilyProbe) ShouldSwitch(previous *IPFamilyProbe) bool {
+	if previous == nil || p.Decision == IPDecisionUnknown {
+		return false
+	}
+	if p.Decision == previous.Decision {
+		return false
+	}
+	// 仅是优先级互换时，要求差距超过阈值
+	if (p.Decision == IPDecisionPreferIPv4 || p.Decision == IPDecisionPreferIPv6) &&
+		(previous.Decision == IPDecisionPreferIPv4 || previous.Decision == IPDecisionPreferIPv6) {
+		diff := p.IPv4Latency - p.IPv6Latency
+		if diff < 0 {
+			diff = -diff
+		}
+		return diff >= IPStrategySwitchMargin
+	}
+	return true
+}
+
+func decideIPFamily(v4, v6 int) string {
+	switch {
+	case v4 < 0 && v6 < 0:
+		return IPDecisionUnknown
+	case v6 < 0:
+		return IPDecisionIPv4Only
+	case v4 < 0:
+		return IPDecisionIPv6Only
+	case v6 <= v4:
+		return IPDecisionPreferIPv6
+	default:
+		return IPDecisionPreferIPv4
+	}
+}
+
+// splitServerHostPort 解析服务器地址，未写端口时默认 443
+func splitServerHostPort(server string) (string, string) {
+	if host, port, err := net.SplitHostPort(server); err == nil {
+		return host, port
+	}
+	return strings.Trim(server, "[]"), "443"
+}
+
+func dialLatency(ctx context.Context, network, addr string) int {
+	dialer := &net.Dialer{Timeout: ipProbeTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return -1
+	}
+	conn.Close()
+	return int(time.Since(start).Milliseconds())
+}
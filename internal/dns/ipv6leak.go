@@ -0,0 +1,159 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// IPv6 泄露测试
+// =============================================================================
+
+// 双栈网络中常见的泄露方式：IPv4 流量经代理（或 TUN），IPv6 流量却经本机网卡直接发出。
+// 测试分别在代理之外（强制 tcp6，与不走代理的程序相同）和经代理访问仅有 IPv6 地址的检测站点，
+// 比较两次看到的出口：代理之外能以 IPv6 访问外网、且出口与经代理时不同，说明 IPv6 流量绕过了隧道。
+
+// DefaultIPv6Endpoints 仅支持 IPv6 的出口查询地址（返回纯文本 IP，按顺序尝试）
+var DefaultIPv6Endpoints = []string{
+	"https://api6.ipify.org",
+	"https://ipv6.icanhazip.com",
+	"https://v6.ident.me",
+}
+
+// ipv6TestTimeout 单次请求的超时
+const ipv6TestTimeout = 8 * time.Second
+
+// IPv6LeakResult IPv6 泄露测试结果
+type IPv6LeakResult struct {
+	Leaked      bool          `json:"leaked"`
+	TestedAt    time.Time     `json:"tested_at"`
+	DirectIP    string        `json:"direct_ip"`    // 代理之外以 IPv6 访问时的出口，空表示本机无法直接访问 IPv6 网络
+	DirectLocal bool          `json:"direct_local"` // 直连出口是本机网卡上的地址（未经任何隧道或 NAT）
+	ProxyIP     string        `json:"proxy_ip"`     // 经代理访问仅 IPv6 站点时的出口，空表示代理无法访问 IPv6 站点
+	Direct      IPAttribution `json:"direct"`       // 直连出口的归属（有离线数据时）
+	Errors      []string      `json:"errors,omitempty"`
+	Conclusion  string        `json:"conclusion"`
+}
+
+// SetIPv6Endpoints 设置 IPv6 出口查询地址（为空时使用默认地址）
+func (t *LeakTester) SetIPv6Endpoints(endpoints []string) {
+	t.ipv6Endpoints = endpoints
+}
+
+// RunIPv6Test 执行 IPv6 泄露测试，proxied 为经代理的 HTTP 客户端
+func (t *LeakTester) RunIPv6Test(proxied *http.Client) *IPv6LeakResult {
+	result := &IPv6LeakResult{TestedAt: time.Now()}
+	endpoints := t.ipv6Endpoints
+	if len(endpoints) == 0 {
+		endpoints = DefaultIPv6Endpoints
+	}
+
+	direct := directIPv6Client()
+	defer direct.CloseIdleConnections()
+	var err error
+	if result.DirectIP, err = queryPlainIP(direct, endpoints); err != nil {
+		result.Errors = append(result.Errors, "直连: "+err.Error())
+	}
+	if result.ProxyIP, err = queryPlainIP(proxied, endpoints); err != nil {
+		result.Errors = append(result.Errors, "代理: "+err.Error())
+	}
+
+	if result.DirectIP != "" {
+		result.DirectLocal = isLocalAddress(result.DirectIP)
+		if t.geoDB != nil {
+			attrs, _ := t.geoDB.Lookup([]string{result.DirectIP})
+			result.Direct = attrs[result.DirectIP]
+		}
+	}
+
+	result.Leaked = result.DirectIP != "" && !sameIP(result.DirectIP, result.ProxyIP)
+	result.Conclusion = ipv6Conclusion(result)
+	return result
+}
+
+// directIPv6Client 不经代理、只使用 IPv6 连接的客户端
+func directIPv6Client() *http.Client {
+	dialer := &net.Dialer{Timeout: ipv6TestTimeout}
+	transport := &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp6", addr)
+		},
+		TLSHandshakeTimeout: ipv6TestTimeout,
+	}
+	return &http.Client{Transport: transport, Timeout: ipv6TestTimeout}
+}
+
+// queryPlainIP 依次请求查询地址，返回第一个有效的 IPv6 地址
+func queryPlainIP(client *http.Client, endpoints []string) (string, error) {
+	var errs []string
+	for _, endpoint := range endpoints {
+		ip, err := fetchPlainIP(client, endpoint)
+		if err == nil {
+			return ip, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return "", fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+func fetchPlainIP(client *http.Client, endpoint string) (string, error) {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: HTTP %d", endpoint, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSpace(string(body))
+	if ip := net.ParseIP(s); ip == nil || ip.To4() != nil {
+		return "", fmt.Errorf("%s: 返回的不是 IPv6 地址", endpoint)
+	}
+	return s, nil
+}
+
+// isLocalAddress IP 是否配置在本机网卡上
+func isLocalAddress(s string) bool {
+	ip := net.ParseIP(s)
+	addrs, err := net.InterfaceAddrs()
+	if ip == nil || err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameIP 两个地址是否相同（忽略书写差异）
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	return ipA != nil && ipB != nil && ipA.Equal(ipB)
+}
+
+// ipv6Conclusion 生成结论
+func ipv6Conclusion(r *IPv6LeakResult) string {
+	switch {
+	case r.Leaked && r.ProxyIP == "":
+		return fmt.Sprintf("⚠️ IPv6 泄露! 代理无法访问 IPv6 站点，而本机可直接经 IPv6 访问外网（出口 %s），不走代理的 IPv6 流量会暴露真实地址；请关闭系统 IPv6 或使用接管 IPv6 的 TUN 模式", r.DirectIP)
+	case r.Leaked:
+		return fmt.Sprintf("⚠️ IPv6 泄露! 代理之外的 IPv6 流量经本机网卡直接发出（出口 %s，经代理为 %s）；请关闭系统 IPv6 或使用接管 IPv6 的 TUN 模式", r.DirectIP, r.ProxyIP)
+	case r.DirectIP != "":
+		return "✓ IPv6 未泄露，IPv6 流量经代理发出（出口 " + r.ProxyIP + "）"
+	case r.ProxyIP != "":
+		return "✓ IPv6 未泄露，本机无法直接访问 IPv6 网络，IPv6 站点经代理访问（出口 " + r.ProxyIP + "）"
+	}
+	return "✓ IPv6 未泄露，本机和代理均无法访问 IPv6 网络"
+}
@@ -0,0 +1,106 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// IPv6 状态监控
+// =============================================================================
+
+const (
+	// ipv6AddrPollInterval 网卡地址轮询间隔（仅比对地址，开销很小）
+	ipv6AddrPollInterval = 5 * time.Second
+	// ipv6RecheckInterval 地址未变化时，完整连通性检测的间隔
+	ipv6RecheckInterval = 2 * time.Minute
+)
+
+// IPv6Watcher 在网络变化时重新检测 IPv6 连通性
+type IPv6Watcher struct {
+	manager  *Manager
+	onChange func(old, current *IPv6SupportInfo)
+}
+
+// NewIPv6Watcher 创建 IPv6 监控器，onChange 在检测结果变化时调用（首次检测也会调用，old 为 nil）
+func NewIPv6Watcher(manager *Manager, onChange func(old, current *IPv6SupportInfo)) *IPv6Watcher {
+	return &IPv6Watcher{
+		manager:  manager,
+		onChange: onChange,
+	}
+}
+
+// Run 运行监控循环，直到 ctx 取消
+func (w *IPv6Watcher) Run(ctx context.Context) {
+	fingerprint := ipv6AddrFingerprint()
+	last := w.check(nil)
+	lastCheck := time.Now()
+
+	ticker := time.NewTicker(ipv6AddrPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := ipv6AddrFingerprint()
+		if current == fingerprint && time.Since(lastCheck) < ipv6RecheckInterval {
+			continue
+		}
+
+		fingerprint = current
+		last = w.check(last)
+		lastCheck = time.Now()
+	}
+}
+
+// check 完整检测一次，结果变化时回调
+func (w *IPv6Watcher) check(last *IPv6SupportInfo) *IPv6SupportInfo {
+	info := w.manager.CheckIPv6Support()
+	if (last == nil || ipv6InfoChanged(last, info)) && w.onChange != nil {
+		w.onChange(last, info)
+	}
+	return info
+}
+
+// ipv6InfoChanged 比较两次检测结果
+func ipv6InfoChanged(a, b *IPv6SupportInfo) bool {
+	if a.HasIPv6Interface != b.HasIPv6Interface ||
+		a.HasIPv6Address != b.HasIPv6Address ||
+		a.HasIPv6Gateway != b.HasIPv6Gateway ||
+		a.IPv6Connectivity != b.IPv6Connectivity {
+		return true
+	}
+	return strings.Join(a.IPv6Addresses, ",") != strings.Join(b.IPv6Addresses, ",")
+}
+
+// ipv6AddrFingerprint 当前所有已启用网卡的地址指纹，用于发现网络切换
+func ipv6AddrFingerprint() string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+
+	var addrs []string
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifAddrs {
+			addrs = append(addrs, iface.Name+"/"+addr.String())
+		}
+	}
+
+	sort.Strings(addrs)
+	return strings.Join(addrs, ",")
+}
@@ -0,0 +1,156 @@
+package dns
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 局域网共享（局域网入站、认证与客户端白名单）
+// =============================================================================
+
+// generateLANShareInbounds 生成局域网地址上需要认证的入站（与对应的本地入站使用相同的协议和嗅探设置）
+func (m *Manager) generateLANShareInbounds(node *models.NodeConfig, cfg *DNSConfig) []map[string]interface{} {
+	if !node.LANShare.Enabled {
+		return nil
+	}
+	var inbounds []map[string]interface{}
+	for i, in := range node.LANInbounds {
+		host, port := m.parseListenAddr(in.Listen)
+		var inbound map[string]interface{}
+		if in.HTTP {
+			inbound = m.generateHTTPInboundConfig(cfg, host, port)
+		} else {
+			inbound = m.generateInboundConfig(cfg, host, port)
+		}
+		inbound["tag"] = lanShareInboundTag(in, i)
+		applyLANShareAuth(inbound, &node.LANShare)
+		inbounds = append(inbounds, inbound)
+	}
+	return inbounds
+}
+
+// lanShareInboundTags 局域网共享入站的标签：socks 为其中的 SOCKS5 入站，all 为全部
+func lanShareInboundTags(node *models.NodeConfig) (socks, all []string) {
+	if !node.LANShare.Enabled {
+		return nil, nil
+	}
+	for i, in := range node.LANInbounds {
+		tag := lanShareInboundTag(in, i)
+		if !in.HTTP {
+			socks = append(socks, tag)
+		}
+		all = append(all, tag)
+	}
+	return socks, all
+}
+
+// lanShareInboundTag 第 i 个局域网共享入站的标签
+func lanShareInboundTag(in models.LANShareInbound, i int) string {
+	if in.HTTP {
+		return fmt.Sprintf("http-lan-%d", i)
+	}
+	return fmt.Sprintf("socks-lan-%d", i)
+}
+
+// applyLANShareAuth 为入站添加用户名密码认证
+func applyLANShareAuth(inbound map[string]interface{}, share *models.LANShareSettings) {
+	settings := inbound["settings"].(map[string]interface{})
+	settings["accounts"] = []map[string]interface{}{
+		{"user": share.Username, "pass": share.Password},
+	}
+	if inbound["protocol"] == "socks" {
+		settings["auth"] = "password"
+		// UDP 返回地址由 Xray 按客户端连接的本机地址确定，不能固定为回环地址
+		delete(settings, "ip")
+	}
+}
+
+// lanShareBlockedSources 不在允许列表中的客户端地址段（Xray 路由只能按来源匹配后拦截，需取补集）
+func lanShareBlockedSources(share *models.LANShareSettings) []string {
+	clients, err := models.LANShareClients(share)
+	if err != nil {
+		return nil
+	}
+
+	var v4, v6 []netip.Prefix
+	for _, p := range clients {
+		if p.Addr().Is4() {
+			v4 = append(v4, p)
+		} else {
+			v6 = append(v6, p)
+		}
+	}
+
+	var blocked []string
+	for _, p := range complementPrefixes(v4, netip.IPv4Unspecified()) {
+		blocked = append(blocked, p.String())
+	}
+	for _, p := range complementPrefixes(v6, netip.IPv6Unspecified()) {
+		blocked = append(blocked, p.String())
+	}
+	return blocked
+}
+
+// complementPrefixes 计算同一地址族中未被 allowed 覆盖的地址段，zero 为该地址族的全零地址
+func complementPrefixes(allowed []netip.Prefix, zero netip.Addr) []netip.Prefix {
+	sort.Slice(allowed, func(i, j int) bool {
+		return allowed[i].Addr().Less(allowed[j].Addr())
+	})
+
+	var out []netip.Prefix
+	cursor := zero
+	for _, p := range allowed {
+		start, end := p.Addr(), lastAddr(p)
+		if cursor.Less(start) {
+			out = append(out, rangePrefixes(cursor, start.Prev())...)
+		}
+		if !end.Less(cursor) {
+			cursor = end.Next()
+			if !cursor.IsValid() {
+				return out // 已覆盖到地址空间末尾
+			}
+		}
+	}
+	return append(out, rangePrefixes(cursor, lastAddr(netip.PrefixFrom(zero, 0)))...)
+}
+
+// rangePrefixes 将 [lo, hi] 地址区间拆分为最少的 CIDR 地址段
+func rangePrefixes(lo, hi netip.Addr) []netip.Prefix {
+	var out []netip.Prefix
+	for lo.IsValid() && !hi.Less(lo) {
+		bits := lo.BitLen()
+		// 找出以 lo 开头且不超过 hi 的最大地址段
+		for b := 0; b <= lo.BitLen(); b++ {
+			p := netip.PrefixFrom(lo, b)
+			if p.Masked().Addr() == lo && !hi.Less(lastAddr(p)) {
+				bits = b
+				break
+			}
+		}
+		p := netip.PrefixFrom(lo, bits)
+		out = append(out, p)
+		lo = lastAddr(p).Next()
+	}
+	return out
+}
+
+// lastAddr 地址段的最后一个地址
+func lastAddr(p netip.Prefix) netip.Addr {
+	p = p.Masked()
+	b := p.Addr().As16()
+	offset := 0
+	if p.Addr().Is4() {
+		offset = 12
+	}
+	for i := offset*8 + p.Bits(); i < 128; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	if p.Addr().Is4() {
+		return netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]})
+	}
+	return netip.AddrFrom16(b)
+}
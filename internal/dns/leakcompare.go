@@ -0,0 +1,110 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/socks5"
+)
+
+// =============================================================================
+// 经节点的泄露测试（与直连基线对比）
+// =============================================================================
+
+// RunTest 只测试当前的出口，看不出结果是否真的来自节点。这里先不经代理测试一次作为基线，
+// 再经节点的本地入站测试，对比两次检测到的 DNS 服务器和 UDP DNS 探测的来源：
+// 经节点时仍出现与直连相同的 DNS 服务器或探测来源，说明这部分 DNS 查询没有经过节点。
+
+// NodeLeakReport 经节点的泄露测试报告
+type NodeLeakReport struct {
+	NodeID        string          `json:"node_id"`
+	NodeName      string          `json:"node_name"`
+	TestedAt      time.Time       `json:"tested_at"`
+	Direct        *LeakTestResult `json:"direct"`       // 不经代理的基线
+	DirectProbe   *DNSProbeResult `json:"direct_probe"` // 不经代理的 UDP DNS 探测
+	ViaNode       *LeakTestResult `json:"via_node"`     // 经节点的测试（含经 UDP 关联的探测）
+	SharedDNS     []string        `json:"shared_dns,omitempty"`
+	ProbeBypassed bool            `json:"probe_bypassed"` // 经节点的探测来源与直连相同
+	Leaked        bool            `json:"leaked"`
+	Conclusion    string          `json:"conclusion"`
+}
+
+// CompareViaProxy 先直连、再经 proxy 执行泄露测试并对比
+func (t *LeakTester) CompareViaProxy(proxy *socks5.Dialer) (*NodeLeakReport, error) {
+	report := &NodeLeakReport{TestedAt: time.Now()}
+	var err error
+	if report.Direct, err = t.RunDirectTest(); err != nil {
+		return nil, err
+	}
+	report.DirectProbe = t.ProbeDNS(nil)
+	if report.ViaNode, err = t.RunTestVia(proxy); err != nil {
+		return nil, err
+	}
+	CompareLeakResults(report)
+	return report, nil
+}
+
+// CompareLeakResults 根据基线和经节点的结果填写对比结论
+func CompareLeakResults(r *NodeLeakReport) {
+	direct := make(map[string]bool, len(r.Direct.DetectedDNS))
+	for _, d := range r.Direct.DetectedDNS {
+		direct[d.IP] = true
+	}
+	r.SharedDNS = nil
+	for _, d := range r.ViaNode.DetectedDNS {
+		if direct[d.IP] {
+			r.SharedDNS = append(r.SharedDNS, d.IP)
+		}
+	}
+	via := r.ViaNode.ProxyDNS
+	r.ProbeBypassed = via != nil && r.DirectProbe != nil &&
+		via.SourceIP != "" && sameIP(via.SourceIP, r.DirectProbe.SourceIP)
+
+	r.Leaked = r.ViaNode.Leaked || len(r.SharedDNS) > 0 || r.ProbeBypassed
+	r.Conclusion = nodeLeakConclusion(r)
+}
+
+// nodeLeakConclusion 生成对比结论
+func nodeLeakConclusion(r *NodeLeakReport) string {
+	via := r.ViaNode
+	if len(via.DetectedDNS) == 0 && (via.ProxyDNS == nil || via.ProxyDNS.SourceIP == "") {
+		return "⚠️ 经节点的检测全部失败，无法判断是否泄露（请确认节点可用）"
+	}
+
+	if r.Leaked {
+		var reasons []string
+		if len(r.SharedDNS) > 0 {
+			reasons = append(reasons, "经节点时仍检测到与直连相同的DNS服务器: "+strings.Join(r.SharedDNS, ", "))
+		}
+		if r.ProbeBypassed {
+			reasons = append(reasons, fmt.Sprintf("经节点发出的 UDP DNS 查询与直连时来源相同 (%s)", via.ProxyDNS.SourceIP))
+		}
+		if via.Leaked {
+			reasons = append(reasons, strings.TrimPrefix(via.Conclusion, "⚠️ "))
+		}
+		return "⚠️ 经节点测试存在DNS泄露: " + strings.Join(reasons, "; ")
+	}
+
+	before, after := leakSummary(r.Direct, r.DirectProbe), leakSummary(via, via.ProxyDNS)
+	return fmt.Sprintf("✓ 经节点的DNS请求未泄露：直连时 %s，经节点时 %s", before, after)
+}
+
+// leakSummary 一次测试检测到的 DNS 服务器和探测来源的简要描述
+func leakSummary(result *LeakTestResult, probe *DNSProbeResult) string {
+	var parts []string
+	for _, d := range result.DetectedDNS {
+		s := d.IP
+		if d.Country != "" {
+			s += " (" + d.Country + ")"
+		}
+		parts = append(parts, s)
+	}
+	if probe != nil && probe.SourceIP != "" {
+		parts = append(parts, "UDP 探测来源 "+probe.SourceIP)
+	}
+	if len(parts) == 0 {
+		return "未检测到"
+	}
+	return strings.Join(parts, "、")
+}
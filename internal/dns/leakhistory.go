@@ -0,0 +1,85 @@
+package dns
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// =============================================================================
+// 泄露测试历史
+// =============================================================================
+
+// maxLeakHistory 保留的历史记录条数
+const maxLeakHistory = 200
+
+// LeakHistoryEntry 单次泄露测试记录
+type LeakHistoryEntry struct {
+	LeakTestResult
+	NodeIDs   []string `json:"node_ids"`  // 测试时运行中的节点
+	Scheduled bool     `json:"scheduled"` // 是否为定时测试
+}
+
+// LeakHistory 泄露测试历史（持久化到 JSON 文件）
+type LeakHistory struct {
+	mu      sync.Mutex
+	path    string
+	entries []LeakHistoryEntry
+}
+
+// NewLeakHistory 创建历史记录并加载已有文件
+func NewLeakHistory(path string) *LeakHistory {
+	h := &LeakHistory{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &h.entries)
+	}
+	return h
+}
+
+// Add 追加一条记录，返回上一条记录（没有时为 nil）
+func (h *LeakHistory) Add(entry LeakHistoryEntry) *LeakHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var previous *LeakHistoryEntry
+	if n := len(h.entries); n > 0 {
+		p := h.entries[n-1]
+		previous = &p
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > maxLeakHistory {
+		h.entries = h.entries[len(h.entries)-maxLeakHistory:]
+	}
+	h.saveLocked()
+	return previous
+}
+
+// List 返回历史记录（最新的在前）
+func (h *LeakHistory) List() []LeakHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make([]LeakHistoryEntry, 0, len(h.entries))
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		result = append(result, h.entries[i])
+	}
+	return result
+}
+
+// Clear 清空历史
+func (h *LeakHistory) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = nil
+	os.Remove(h.path)
+}
+
+func (h *LeakHistory) saveLocked() {
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return
+	}
+	os.WriteFile(h.path, data, 0644)
+}
@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/socks5"
 )
 
 // =============================================================================
@@ -17,27 +20,83 @@ import (
 
 // LeakTestResult DNS泄露测试结果
 type LeakTestResult struct {
-	Leaked       bool              `json:"leaked"`
-	TestedAt     time.Time         `json:"tested_at"`
-	LocalDNS     []string          `json:"local_dns"`
-	DetectedDNS  []DNSServerInfo   `json:"detected_dns"`
-	TestServers  []string          `json:"test_servers"`
-	Errors       []string          `json:"errors,omitempty"`
-	Conclusion   string            `json:"conclusion"`
+	Leaked      bool            `json:"leaked"`
+	TestedAt    time.Time       `json:"tested_at"`
+	LocalDNS    []string        `json:"local_dns"`
+	DetectedDNS []DNSServerInfo `json:"detected_dns"`
+	TestServers []string        `json:"test_servers"`
+	Errors      []string        `json:"errors,omitempty"`
+	Conclusion  string          `json:"conclusion"`
+	GeoSources  []string        `json:"geo_sources,omitempty"` // 用于归属分析的离线数据文件
+	ProxyDNS    *DNSProbeResult `json:"proxy_dns,omitempty"`   // 经代理的 UDP DNS 探测（设置了代理时）
 }
 
 // DNSServerInfo DNS服务器信息
 type DNSServerInfo struct {
-	IP       string `json:"ip"`
-	Country  string `json:"country"`
-	City     string `json:"city"`
-	ISP      string `json:"isp"`
-	IsChina  bool   `json:"is_china"`
+	IP          string `json:"ip"`
+	Country     string `json:"country"`
+	CountryCode string `json:"country_code,omitempty"` // 离线数据给出的 ISO 国家代码
+	City        string `json:"city"`
+	ISP         string `json:"isp"`
+	ASN         uint32 `json:"asn,omitempty"`
+	ASOrg       string `json:"as_org,omitempty"`
+	IsChina     bool   `json:"is_china"`
+	Provider    string `json:"provider,omitempty"` // 公共DNS服务商（如 Google、Cloudflare）
+	Owner       string `json:"owner"`              // 归属分类 (DNSOwner*)
+}
+
+// DNS服务器归属分类
+const (
+	DNSOwnerISP      = "isp"          // 本地运营商DNS（泄露）
+	DNSOwnerProvider = "dns_provider" // 公共DNS服务商
+	DNSOwnerUnknown  = "unknown"
+)
+
+// publicDNSProviders 常见公共DNS服务商的解析出口网段
+var publicDNSProviders = []struct {
+	name  string
+	cidrs []string
+}{
+	{"Google", []string{"8.8.8.0/24", "8.8.4.0/24", "74.125.0.0/16", "172.217.0.0/16", "172.253.0.0/16", "2001:4860::/32"}},
+	{"Cloudflare", []string{"1.1.1.0/24", "1.0.0.0/24", "162.158.0.0/15", "172.64.0.0/13", "2606:4700::/32", "2a06:98c0::/29"}},
+	{"Quad9", []string{"9.9.9.0/24", "149.112.112.0/24", "2620:fe::/48"}},
+	{"OpenDNS", []string{"208.67.216.0/21", "2620:119::/32"}},
+	{"AliDNS", []string{"223.5.5.0/24", "223.6.6.0/24", "2400:3200::/32"}},
+	{"DNSPod", []string{"119.29.29.0/24", "119.28.28.0/24", "182.254.116.0/24", "2402:4e00::/32"}},
+	{"114DNS", []string{"114.114.114.0/24", "114.114.115.0/24"}},
+	{"BaiduDNS", []string{"180.76.76.0/24"}},
+}
+
+// geoipProviderTags geoip.dat 中代表服务商的标签
+var geoipProviderTags = map[string]string{
+	"google":     "Google",
+	"cloudflare": "Cloudflare",
+}
+
+// LeakTestAPI 泄露检测服务
+type LeakTestAPI struct {
+	Name string
+	URL  string
+}
+
+// DefaultLeakTestAPIs 默认使用的泄露检测服务
+var DefaultLeakTestAPIs = []LeakTestAPI{
+	{"ipleak.net", "https://ipleak.net/json/"},
+	{"browserleaks", "https://browserleaks.com/dns"},
+	{"dnsleaktest", "https://www.dnsleaktest.com/results.html"},
 }
 
 // LeakTester DNS泄露测试器
 type LeakTester struct {
 	httpClient *http.Client
+	clientFunc func(timeout time.Duration) *http.Client // 按出口策略创建客户端，为空时直连
+	geoDB      *IPGeoDB                                 // 离线 IP 归属数据，用于补全DNS服务器和出口的归属
+	proxy      *socks5.Dialer                           // 设置后检测请求经此 SOCKS5 代理（节点的本地入站）发出
+
+	testAPIs      []LeakTestAPI // 泄露检测服务，为空时使用默认服务
+	ipv6Endpoints []string      // IPv6 泄露测试的出口查询地址，为空时使用默认地址
+	probeServer   string        // UDP DNS 探测的解析服务器，为空时使用默认值
+	probeName     string        // UDP DNS 探测查询的域名
 }
 
 // NewLeakTester 创建泄露测试器
@@ -49,28 +108,55 @@ func NewLeakTester() *LeakTester {
 	}
 }
 
-// SetProxy 设置代理
-func (t *LeakTester) SetProxy(proxyAddr string) {
-	if proxyAddr == "" {
-		return
-	}
+// SetClientFunc 设置HTTP客户端来源，每次测试时调用以获取当前出口
+func (t *LeakTester) SetClientFunc(fn func(timeout time.Duration) *http.Client) {
+	t.clientFunc = fn
+}
 
-	// 配置SOCKS5代理
-	transport := &http.Transport{
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			dialer := &net.Dialer{
-				Timeout: 5 * time.Second,
-			}
-			// 这里简化处理，实际应使用SOCKS5
-			return dialer.DialContext(ctx, network, addr)
-		},
+// SetProxy 设置检测经过的 SOCKS5 代理（为 nil 时按 SetClientFunc 的出口），在开始测试前设置
+// 设置后 HTTP 检测经代理的 CONNECT 发出，并额外经 UDP 关联发送 DNS 探测
+func (t *LeakTester) SetProxy(proxy *socks5.Dialer) {
+	t.proxy = proxy
+}
+
+// client 获取本次测试使用的HTTP客户端
+func (t *LeakTester) client() *http.Client {
+	if t.proxy != nil {
+		return t.proxy.HTTPClient(10 * time.Second)
 	}
+	if t.clientFunc != nil {
+		return t.clientFunc(10 * time.Second)
+	}
+	return t.httpClient
+}
 
-	t.httpClient.Transport = transport
+// SetGeoDB 设置离线 IP 归属数据（没有数据文件时只使用检测服务返回的信息）
+func (t *LeakTester) SetGeoDB(db *IPGeoDB) {
+	t.geoDB = db
 }
 
-// RunTest 执行DNS泄露测试
+// SetTestAPIs 设置泄露检测服务（为空时使用默认服务）
+func (t *LeakTester) SetTestAPIs(apis []LeakTestAPI) {
+	t.testAPIs = apis
+}
+
+// RunTest 执行DNS泄露测试（经 SetProxy / SetClientFunc 设置的出口）
 func (t *LeakTester) RunTest() (*LeakTestResult, error) {
+	return t.runTest(t.client(), t.proxy)
+}
+
+// RunTestVia 经指定的 SOCKS5 代理执行DNS泄露测试（含经 UDP 关联的 DNS 探测）
+func (t *LeakTester) RunTestVia(proxy *socks5.Dialer) (*LeakTestResult, error) {
+	return t.runTest(proxy.HTTPClient(10*time.Second), proxy)
+}
+
+// RunDirectTest 不经任何代理执行DNS泄露测试，作为对比的基线
+func (t *LeakTester) RunDirectTest() (*LeakTestResult, error) {
+	return t.runTest(&http.Client{Transport: &http.Transport{Proxy: nil}, Timeout: 10 * time.Second}, nil)
+}
+
+// runTest 使用 client 访问检测服务；proxy 不为空时额外经其 UDP 关联发送 DNS 探测
+func (t *LeakTester) runTest(client *http.Client, proxy *socks5.Dialer) (*LeakTestResult, error) {
 	result := &LeakTestResult{
 		TestedAt:    time.Now(),
 		TestServers: []string{},
@@ -83,22 +169,17 @@ func (t *LeakTester) RunTest() (*LeakTestResult, error) {
 
 	// 测试多个泄露检测服务
 	detectedDNS := make(map[string]DNSServerInfo)
-
-	testAPIs := []struct {
-		name string
-		url  string
-	}{
-		{"ipleak.net", "https://ipleak.net/json/"},
-		{"browserleaks", "https://browserleaks.com/dns"},
-		{"dnsleaktest", "https://www.dnsleaktest.com/results.html"},
+	testAPIs := t.testAPIs
+	if len(testAPIs) == 0 {
+		testAPIs = DefaultLeakTestAPIs
 	}
 
 	for _, api := range testAPIs {
-		result.TestServers = append(result.TestServers, api.name)
+		result.TestServers = append(result.TestServers, api.Name)
 
-		info, err := t.queryLeakAPI(api.url)
+		info, err := t.queryLeakAPI(client, api.URL)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", api.name, err))
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", api.Name, err))
 			continue
 		}
 
@@ -111,6 +192,10 @@ func (t *LeakTester) RunTest() (*LeakTestResult, error) {
 	for _, info := range detectedDNS {
 		result.DetectedDNS = append(result.DetectedDNS, info)
 	}
+	t.enrichDNSServers(result)
+	if proxy != nil {
+		result.ProxyDNS = t.ProbeDNS(proxy)
+	}
 
 	// 判断是否泄露
 	result.Leaked = t.analyzeLeakage(result)
@@ -120,7 +205,7 @@ func (t *LeakTester) RunTest() (*LeakTestResult, error) {
 }
 
 // queryLeakAPI 查询泄露检测API
-func (t *LeakTester) queryLeakAPI(url string) (DNSServerInfo, error) {
+func (t *LeakTester) queryLeakAPI(client *http.Client, url string) (DNSServerInfo, error) {
 	var info DNSServerInfo
 
 	req, err := http.NewRequest("GET", url, nil)
@@ -131,7 +216,7 @@ func (t *LeakTester) queryLeakAPI(url string) (DNSServerInfo, error) {
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := t.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return info, err
 	}
@@ -187,6 +272,85 @@ func (t *LeakTester) getLocalDNS() ([]string, error) {
 	return nil, nil
 }
 
+// enrichDNSServers 使用离线数据补全DNS服务器的国家和归属（不发起任何网络请求，不会绕过代理）
+func (t *LeakTester) enrichDNSServers(result *LeakTestResult) {
+	var attrs map[string]IPAttribution
+	if t.geoDB != nil && len(result.DetectedDNS) > 0 {
+		ips := make([]string, 0, len(result.DetectedDNS))
+		for _, d := range result.DetectedDNS {
+			ips = append(ips, d.IP)
+		}
+		var err error
+		if attrs, err = t.geoDB.Lookup(ips); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		result.GeoSources = t.geoDB.Sources()
+	}
+
+	for i := range result.DetectedDNS {
+		d := &result.DetectedDNS[i]
+		d.Provider = lookupDNSProvider(d.IP)
+
+		// 有离线数据时以离线数据的国家为准，否则按检测服务返回的国家名称判断
+		attr := attrs[d.IP]
+		for _, tag := range attr.Tags {
+			if name, ok := geoipProviderTags[tag]; ok && d.Provider == "" {
+				d.Provider = name
+			}
+		}
+		if attr.CountryCode != "" {
+			d.CountryCode = attr.CountryCode
+			d.IsChina = attr.IsChina()
+			if attr.Country != "" {
+				d.Country = attr.Country
+			} else if d.Country == "" {
+				d.Country = attr.CountryCode
+			}
+		} else {
+			d.IsChina = t.isChineseServer(*d)
+		}
+		d.ASN, d.ASOrg = attr.ASN, attr.ASOrg
+		if d.ISP == "" {
+			d.ISP = attr.ASOrg
+		}
+
+		d.Owner = classifyDNSOwner(*d, result.LocalDNS)
+	}
+}
+
+// lookupDNSProvider 判断IP是否属于已知的公共DNS服务商
+func lookupDNSProvider(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	for _, p := range publicDNSProviders {
+		for _, cidr := range p.cidrs {
+			if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(parsed) {
+				return p.name
+			}
+		}
+	}
+	return ""
+}
+
+// classifyDNSOwner 判断DNS服务器归属：
+// 公共DNS服务商 -> dns_provider；系统配置的DNS或境内的非公共DNS -> isp（通常是运营商分配的DNS）
+func classifyDNSOwner(d DNSServerInfo, localDNS []string) string {
+	for _, l := range localDNS {
+		if l == d.IP {
+			return DNSOwnerISP
+		}
+	}
+	if d.Provider != "" {
+		return DNSOwnerProvider
+	}
+	if d.IsChina {
+		return DNSOwnerISP
+	}
+	return DNSOwnerUnknown
+}
+
 // isChineseServer 判断是否是中国服务器
 func (t *LeakTester) isChineseServer(info DNSServerInfo) bool {
 	country := strings.ToLower(info.Country)
@@ -204,6 +368,11 @@ func (t *LeakTester) analyzeLeakage(result *LeakTestResult) bool {
 		}
 	}
 
+	// 经代理发出的 DNS 查询从境内到达解析服务器，说明 UDP 流量没有经过节点
+	if result.ProxyDNS != nil && result.ProxyDNS.Source.IsChina() {
+		return true
+	}
+
 	// 如果检测到本地DNS在检测结果中
 	for _, localDNS := range result.LocalDNS {
 		for _, detected := range result.DetectedDNS {
@@ -221,10 +390,21 @@ func (t *LeakTester) generateConclusion(result *LeakTestResult) string {
 	if result.Leaked {
 		var reasons []string
 		for _, dns := range result.DetectedDNS {
-			if dns.IsChina {
+			if !dns.IsChina {
+				continue
+			}
+			switch dns.Owner {
+			case DNSOwnerISP:
+				reasons = append(reasons, fmt.Sprintf("请求经由本地运营商DNS解析: %s (%s)", dns.IP, dns.ISP))
+			case DNSOwnerProvider:
+				reasons = append(reasons, fmt.Sprintf("请求经由境内公共DNS解析: %s (%s)", dns.IP, dns.Provider))
+			default:
 				reasons = append(reasons, fmt.Sprintf("检测到中国DNS: %s (%s)", dns.IP, dns.ISP))
 			}
 		}
+		if p := result.ProxyDNS; p != nil && p.Source.IsChina() {
+			reasons = append(reasons, fmt.Sprintf("经代理发出的 DNS 查询从境内地址 %s 到达 %s", p.SourceIP, p.Server))
+		}
 
 		if len(reasons) > 0 {
 			return "⚠️ DNS泄露! " + strings.Join(reasons, "; ")
@@ -236,6 +416,15 @@ func (t *LeakTester) generateConclusion(result *LeakTestResult) string {
 		return "✓ 未检测到DNS服务器（可能测试失败）"
 	}
 
+	var providers []string
+	for _, dns := range result.DetectedDNS {
+		if dns.Provider != "" && !containsString(providers, dns.Provider) {
+			providers = append(providers, dns.Provider)
+		}
+	}
+	if len(providers) > 0 {
+		return "✓ DNS未泄露，请求由 " + strings.Join(providers, "、") + " 经代理解析"
+	}
 	return "✓ DNS未泄露，所有请求通过代理解析"
 }
 
@@ -243,107 +432,42 @@ func (t *LeakTester) generateConclusion(result *LeakTestResult) string {
 // 快速泄露检测
 // =============================================================================
 
-// QuickLeakCheck 快速泄露检测
-func (t *LeakTester) QuickLeakCheck(proxyAddr string) (bool, string, error) {
-	// 创建使用代理的客户端
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	if proxyAddr != "" {
-		// 配置代理（简化）
-		_ = proxyAddr
+// QuickLeakCheck 快速泄露检测：经 proxy 查询出口 IP 并用离线数据判断归属（出口位于境内即视为泄露）
+// proxy 为 nil 时直连查询
+func (t *LeakTester) QuickLeakCheck(proxy *socks5.Dialer) (IPAttribution, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	if proxy != nil {
+		client = proxy.HTTPClient(5 * time.Second)
 	}
 
 	// 请求IP检测API
 	resp, err := client.Get("https://api.ip.sb/ip")
 	if err != nil {
-		return false, "", err
+		return IPAttribution{}, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return false, "", err
+		return IPAttribution{}, err
 	}
 
 	ip := strings.TrimSpace(string(body))
-
-	// 检查是否为中国IP（简化判断）
-	isChina := t.isChineseIP(ip)
-
-	return isChina, ip, nil
+	return t.Attribute(ip)
 }
 
-// isChineseIP 判断是否为中国IP
-func (t *LeakTester) isChineseIP(ip string) bool {
-	// 简化判断，实际应查询IP库
-	// 中国IP段示例
-	chinaRanges := []string{
-		"1.0.0.0/8",
-		"14.0.0.0/8",
-		"27.0.0.0/8",
-		"36.0.0.0/8",
-		"39.0.0.0/8",
-		"42.0.0.0/8",
-		"49.0.0.0/8",
-		"58.0.0.0/8",
-		"59.0.0.0/8",
-		"60.0.0.0/8",
-		"61.0.0.0/8",
-		"101.0.0.0/8",
-		"103.0.0.0/8",
-		"106.0.0.0/8",
-		"110.0.0.0/8",
-		"111.0.0.0/8",
-		"112.0.0.0/8",
-		"113.0.0.0/8",
-		"114.0.0.0/8",
-		"115.0.0.0/8",
-		"116.0.0.0/8",
-		"117.0.0.0/8",
-		"118.0.0.0/8",
-		"119.0.0.0/8",
-		"120.0.0.0/8",
-		"121.0.0.0/8",
-		"122.0.0.0/8",
-		"123.0.0.0/8",
-		"124.0.0.0/8",
-		"125.0.0.0/8",
-		"126.0.0.0/8",
-		"139.0.0.0/8",
-		"140.0.0.0/8",
-		"171.0.0.0/8",
-		"175.0.0.0/8",
-		"180.0.0.0/8",
-		"182.0.0.0/8",
-		"183.0.0.0/8",
-		"202.0.0.0/8",
-		"203.0.0.0/8",
-		"210.0.0.0/8",
-		"211.0.0.0/8",
-		"218.0.0.0/8",
-		"219.0.0.0/8",
-		"220.0.0.0/8",
-		"221.0.0.0/8",
-		"222.0.0.0/8",
-		"223.0.0.0/8",
-	}
-
-	parsedIP := net.ParseIP(ip)
-	if parsedIP == nil {
-		return false
-	}
-
-	for _, cidr := range chinaRanges {
-		_, network, err := net.ParseCIDR(cidr)
-		if err != nil {
-			continue
-		}
-		if network.Contains(parsedIP) {
-			return true
-		}
+// Attribute 用离线数据查询单个 IP 的归属，没有国家数据时返回错误（无法判断是否位于境内）
+func (t *LeakTester) Attribute(ip string) (IPAttribution, error) {
+	if net.ParseIP(ip) == nil {
+		return IPAttribution{}, i18n.Errorf("无效的 IP 地址: %s", ip)
 	}
-
-	return false
+	if t.geoDB == nil || !t.geoDB.HasCountryData() {
+		return IPAttribution{IP: ip}, i18n.Errorf("缺少 IP 归属数据（geoip.dat 或国家 MMDB），无法判断出口位置")
+	}
+	attrs, err := t.geoDB.Lookup([]string{ip})
+	attr := attrs[ip]
+	if err != nil && attr.CountryCode == "" {
+		return attr, err
+	}
+	return attr, nil
 }
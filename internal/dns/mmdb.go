@@ -0,0 +1,338 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+// =============================================================================
+// MaxMind DB（.mmdb）离线查询
+// =============================================================================
+
+// MMDB 文件由三部分组成：二叉搜索树（按 IP 的每一位向左 / 右走）、数据区、末尾的元数据。
+// 这里只实现查询所需的部分（读取元数据、遍历搜索树、解码数据），不需要引入 maxminddb 依赖。
+// 格式说明: https://maxmind.github.io/MaxMind-DB/
+
+// mmdbMetadataMarker 元数据起始标记
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+const (
+	// mmdbDataSeparator 搜索树与数据区之间的 16 字节分隔
+	mmdbDataSeparator = 16
+	// mmdbMaxDepth 嵌套解码的最大深度，防止损坏的文件导致无限递归
+	mmdbMaxDepth = 32
+)
+
+// MMDBReader 已载入内存的 MMDB 文件
+type MMDBReader struct {
+	buf          []byte
+	data         []byte // 数据区
+	nodeCount    uint
+	recordSize   uint
+	ipVersion    uint
+	ipv4Start    uint // IPv6 数据库中 ::/96 对应的节点（IPv4 地址从这里开始查）
+	DatabaseType string
+}
+
+// OpenMMDB 读取 MMDB 文件
+func OpenMMDB(path string) (*MMDBReader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewMMDBReader(buf)
+}
+
+// NewMMDBReader 解析内存中的 MMDB 数据
+func NewMMDBReader(buf []byte) (*MMDBReader, error) {
+	i := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if i < 0 {
+		return nil, fmt.Errorf("mmdb 格式错误: 缺少元数据")
+	}
+	metaStart := i + len(mmdbMetadataMarker)
+	meta, _, err := (&mmdbDecoder{data: buf[metaStart:]}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("mmdb 元数据解析失败: %w", err)
+	}
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mmdb 格式错误: 元数据不是对象")
+	}
+
+	r := &MMDBReader{buf: buf}
+	r.nodeCount = uint(mmdbUint(m["node_count"]))
+	r.recordSize = uint(mmdbUint(m["record_size"]))
+	r.ipVersion = uint(mmdbUint(m["ip_version"]))
+	r.DatabaseType, _ = m["database_type"].(string)
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("mmdb 格式错误: 不支持的记录长度 %d", r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("mmdb 格式错误: 不支持的 IP 版本 %d", r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+mmdbDataSeparator > uint(i) {
+		return nil, fmt.Errorf("mmdb 格式错误: 搜索树超出文件长度")
+	}
+	r.data = buf[treeSize+mmdbDataSeparator : i]
+
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for j := 0; j < 96 && node < r.nodeCount; j++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// Lookup 查询 IP 对应的数据，未收录时返回 nil
+func (r *MMDBReader) Lookup(ip net.IP) (map[string]interface{}, error) {
+	node, bits := uint(0), 128
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 32
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-uint(i&7))) & 1
+		node = r.readNode(node, bit)
+	}
+	if node <= r.nodeCount {
+		return nil, nil
+	}
+
+	offset := node - r.nodeCount - mmdbDataSeparator
+	value, _, err := (&mmdbDecoder{data: r.data}).decode(offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := value.(map[string]interface{})
+	return m, nil
+}
+
+// readNode 读取节点的左（bit=0）或右（bit=1）记录
+func (r *MMDBReader) readNode(node, bit uint) uint {
+	b := r.buf
+	switch r.recordSize {
+	case 24:
+		off := node*6 + bit*3
+		return uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+	case 28:
+		off := node * 7
+		if bit == 0 {
+			return uint(b[off+3]&0xF0)<<20 | uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+		}
+		return uint(b[off+3]&0x0F)<<24 | uint(b[off+4])<<16 | uint(b[off+5])<<8 | uint(b[off+6])
+	default:
+		off := node*8 + bit*4
+		return uint(binary.BigEndian.Uint32(b[off:]))
+	}
+}
+
+// mmdbDecoder 数据区解码器，指针以数据区起点为基准
+type mmdbDecoder struct {
+	data []byte
+}
+
+// MMDB 数据类型
+const (
+	mmdbExtended = 0
+	mmdbPointer  = 1
+	mmdbString   = 2
+	mmdbDouble   = 3
+	mmdbBytes    = 4
+	mmdbUint16   = 5
+	mmdbUint32   = 6
+	mmdbMap      = 7
+	mmdbInt32    = 8
+	mmdbUint64   = 9
+	mmdbUint128  = 10
+	mmdbArray    = 11
+	mmdbBool     = 14
+	mmdbFloat    = 15
+)
+
+var errMMDBTruncated = fmt.Errorf("mmdb 格式错误: 数据被截断")
+
+// decode 解码 offset 处的值，返回值和下一个值的位置
+func (d *mmdbDecoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > mmdbMaxDepth {
+		return nil, 0, fmt.Errorf("mmdb 格式错误: 嵌套过深")
+	}
+	typ, size, offset, err := d.readControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == mmdbPointer {
+		target, next, err := d.readPointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(target, depth+1)
+		return value, next, err
+	}
+
+	switch typ {
+	case mmdbMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("mmdb 格式错误: 对象的键不是字符串")
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k] = value
+			offset = next
+		}
+		return m, offset, nil
+	case mmdbArray:
+		list := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			list = append(list, value)
+			offset = next
+		}
+		return list, offset, nil
+	case mmdbBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.data)) {
+		return nil, 0, errMMDBTruncated
+	}
+	raw := d.data[offset : offset+size]
+	next := offset + size
+	switch typ {
+	case mmdbString:
+		return string(raw), next, nil
+	case mmdbBytes, mmdbUint128:
+		return append([]byte(nil), raw...), next, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("mmdb 格式错误: double 长度 %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), next, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("mmdb 格式错误: float 长度 %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw))), next, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("mmdb 格式错误: 整数长度 %d", size)
+		}
+		var v uint64
+		for _, b := range raw {
+			v = v<<8 | uint64(b)
+		}
+		return v, next, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("mmdb 格式错误: 整数长度 %d", size)
+		}
+		var v uint32
+		for _, b := range raw {
+			v = v<<8 | uint32(b)
+		}
+		return int64(int32(v)), next, nil
+	}
+	return nil, 0, fmt.Errorf("mmdb 格式错误: 未知数据类型 %d", typ)
+}
+
+// readControl 读取控制字节，返回类型、长度（指针为长度位）和数据起点
+func (d *mmdbDecoder) readControl(offset uint) (typ, size, next uint, err error) {
+	if offset >= uint(len(d.data)) {
+		return 0, 0, 0, errMMDBTruncated
+	}
+	ctrl := d.data[offset]
+	offset++
+	typ = uint(ctrl >> 5)
+	if typ == mmdbPointer {
+		return typ, uint(ctrl & 0x1F), offset, nil
+	}
+	if typ == mmdbExtended {
+		if offset >= uint(len(d.data)) {
+			return 0, 0, 0, errMMDBTruncated
+		}
+		typ = 7 + uint(d.data[offset])
+		offset++
+	}
+
+	size = uint(ctrl & 0x1F)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.data)) {
+			return 0, 0, 0, errMMDBTruncated
+		}
+		var v uint
+		for _, b := range d.data[offset : offset+n] {
+			v = v<<8 | uint(b)
+		}
+		offset += n
+		switch n {
+		case 1:
+			size = 29 + v
+		case 2:
+			size = 285 + v
+		default:
+			size = 65821 + v
+		}
+	}
+	return typ, size, offset, nil
+}
+
+// readPointer 解析指针，bits 为控制字节的低 5 位
+func (d *mmdbDecoder) readPointer(bits, offset uint) (target, next uint, err error) {
+	n := (bits>>3)&0x3 + 1
+	if offset+n > uint(len(d.data)) {
+		return 0, 0, errMMDBTruncated
+	}
+	var v uint
+	if n < 4 {
+		v = bits & 0x7
+	}
+	for _, b := range d.data[offset : offset+n] {
+		v = v<<8 | uint(b)
+	}
+	switch n {
+	case 2:
+		v += 2048
+	case 3:
+		v += 526336
+	}
+	return v, offset + n, nil
+}
+
+// mmdbUint 取出整数值（类型不符时为 0）
+func mmdbUint(v interface{}) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int64:
+		if n > 0 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
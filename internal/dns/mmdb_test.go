@@ -0,0 +1,139 @@
+package dns
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// mmdbStr 编码 MMDB 字符串（长度小于 29）
+func mmdbStr(s string) []byte {
+	return append([]byte{mmdbString<<5 | byte(len(s))}, s...)
+}
+
+// mmdbU16 编码 MMDB uint16
+func mmdbU16(v uint16) []byte {
+	return []byte{mmdbUint16<<5 | 2, byte(v >> 8), byte(v)}
+}
+
+// mmdbMapOf 编码 MMDB 对象，kv 为依次排列的已编码键和值
+func mmdbMapOf(kv ...[]byte) []byte {
+	out := []byte{mmdbMap<<5 | byte(len(kv)/2)}
+	for _, b := range kv {
+		out = append(out, b...)
+	}
+	return out
+}
+
+// buildTestMMDB 构造只有一个搜索树节点的 IPv4 数据库（24 位记录）：
+// 首位为 0 的地址（0.0.0.0/1）指向 {"country": {"iso_code": "CN"}}，其余地址未收录。
+// 数据区开头是字符串 "CN"，对象中的 iso_code 以指针引用它。recordSize 只写入元数据，用于构造损坏的文件。
+func buildTestMMDB(recordSize uint16) []byte {
+	data := mmdbStr("CN")
+	recordOffset := len(data)
+	data = append(data, mmdbMapOf(
+		mmdbStr("country"), mmdbMapOf(mmdbStr("iso_code"), []byte{mmdbPointer << 5, 0}),
+	)...)
+
+	const nodeCount = 1
+	left := nodeCount + mmdbDataSeparator + recordOffset
+	tree := []byte{byte(left >> 16), byte(left >> 8), byte(left), 0, 0, nodeCount}
+
+	var buf bytes.Buffer
+	buf.Write(tree)
+	buf.Write(make([]byte, mmdbDataSeparator))
+	buf.Write(data)
+	buf.Write(mmdbMetadataMarker)
+	buf.Write(mmdbMapOf(
+		mmdbStr("node_count"), mmdbU16(nodeCount),
+		mmdbStr("record_size"), mmdbU16(recordSize),
+		mmdbStr("ip_version"), mmdbU16(4),
+		mmdbStr("database_type"), mmdbStr("Test-Country"),
+	))
+	return buf.Bytes()
+}
+
+func TestMMDBLookup(t *testing.T) {
+	r, err := NewMMDBReader(buildTestMMDB(24))
+	if err != nil {
+		t.Fatalf("NewMMDBReader: %v", err)
+	}
+	if r.DatabaseType != "Test-Country" {
+		t.Errorf("DatabaseType = %q", r.DatabaseType)
+	}
+
+	cn := map[string]interface{}{"country": map[string]interface{}{"iso_code": "CN"}}
+	tests := []struct {
+		ip   string
+		want map[string]interface{}
+	}{
+		{"1.2.3.4", cn},
+		{"127.255.255.255", cn},
+		{"128.0.0.1", nil},
+		{"2001:db8::1", nil}, // IPv4 数据库不收录 IPv6 地址
+	}
+	for _, tt := range tests {
+		got, err := r.Lookup(net.ParseIP(tt.ip))
+		if err != nil {
+			t.Errorf("Lookup(%s): %v", tt.ip, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestNewMMDBReaderErrors(t *testing.T) {
+	valid := buildTestMMDB(24)
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"no metadata", []byte("not a database")},
+		{"bad record size", buildTestMMDB(20)},
+		{"truncated tree", valid[bytes.Index(valid, mmdbMetadataMarker)-4:]},
+		{"truncated metadata", valid[:len(valid)-3]},
+	}
+	for _, tt := range tests {
+		if _, err := NewMMDBReader(tt.buf); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestMMDBDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    interface{}
+		wantErr bool
+	}{
+		{"string", mmdbStr("hello"), "hello", false},
+		{"uint16", mmdbU16(443), uint64(443), false},
+		{"uint32 short", []byte{mmdbUint32<<5 | 1, 0x7F}, uint64(127), false},
+		{"int32 negative", []byte{4, mmdbInt32 - 7, 0xFF, 0xFF, 0xFF, 0xFE}, int64(-2), false},
+		{"uint64", []byte{2, mmdbUint64 - 7, 0x01, 0x00}, uint64(256), false},
+		{"bool true", []byte{1, mmdbBool - 7}, true, false},
+		{"bool false", []byte{0, mmdbBool - 7}, false, false},
+		{"double", []byte{mmdbDouble<<5 | 8, 0x3F, 0xF8, 0, 0, 0, 0, 0, 0}, 1.5, false},
+		{"array", append([]byte{2, mmdbArray - 7}, append(mmdbStr("a"), mmdbStr("b")...)...), []interface{}{"a", "b"}, false},
+		{"long string", append([]byte{mmdbString<<5 | 29, 1}, bytes.Repeat([]byte("x"), 30)...), string(bytes.Repeat([]byte("x"), 30)), false},
+		{"truncated string", []byte{mmdbString<<5 | 5, 'a'}, nil, true},
+		{"bad double size", []byte{mmdbDouble<<5 | 4, 0, 0, 0, 0}, nil, true},
+		{"map with non-string key", mmdbMapOf(mmdbU16(1), mmdbStr("v")), nil, true},
+		{"self pointer", []byte{mmdbPointer << 5, 0}, nil, true},
+		{"empty", nil, nil, true},
+	}
+	for _, tt := range tests {
+		got, _, err := (&mmdbDecoder{data: tt.data}).decode(0, 0)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
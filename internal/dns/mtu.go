@@ -0,0 +1,115 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// TUN MTU 自动探测
+// =============================================================================
+
+// 默认的 9000 远超大多数链路（PPPoE 为 1492，部分移动网络和隧道更小），
+// 超出路径 MTU 的 UDP 包被中途丢弃且不会报错，表现为 QUIC / 游戏流量时断时续。
+// 这里向节点当前使用的服务器发送禁止分片的 ICMP 包，二分查找能通过的最大包长，
+// 再扣除代理协议封装的开销，得到 TUN 网卡应使用的 MTU。
+
+const (
+	// MTUMaxProbe 探测上限（以太网 MTU）
+	MTUMaxProbe = 1500
+	// MTUTunnelOverhead 代理协议封装（TLS 记录、WebSocket / ECH 帧）预留的字节数
+	MTUTunnelOverhead = 80
+	// minTUNMTU TUN 网卡同时承载 IPv6，MTU 不能低于 IPv6 最小值
+	minTUNMTU = 1280
+	// mtuProbeTimeout 单个探测包的超时
+	mtuProbeTimeout = 2 * time.Second
+)
+
+// MTUProbeResult MTU 探测结果
+type MTUProbeResult struct {
+	Server    string `json:"server"`   // 探测的服务器
+	IP        string `json:"ip"`       // 服务器解析得到的地址
+	PathMTU   int    `json:"path_mtu"` // 到服务器的路径 MTU
+	MTU       int    `json:"mtu"`      // 建议的 TUN MTU
+	Overhead  int    `json:"overhead"` // 扣除的封装开销
+	Probes    int    `json:"probes"`   // 发送的探测包数
+	CheckedAt int64  `json:"checked_at"`
+}
+
+// ProbeMTU 探测到服务器的路径 MTU 并给出 TUN MTU 建议（服务器需响应 ICMP）
+func (t *TUNManager) ProbeMTU(ctx context.Context, server string) (*MTUProbeResult, error) {
+	host, _ := splitServerHostPort(server)
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(ips) == 0 {
+		return nil, fmt.Errorf("解析服务器 %s 失败: %v", host, err)
+	}
+	ip := ips[0].IP
+	for _, addr := range ips {
+		if addr.IP.To4() != nil {
+			ip = addr.IP
+			break
+		}
+	}
+
+	// ping 的包长为 ICMP 数据长度，需加上 IP 头和 ICMP 头
+	header, lo := 28, models.MinTUNMTU
+	if ip.To4() == nil {
+		header, lo = 48, minTUNMTU
+	}
+
+	result := &MTUProbeResult{Server: server, IP: ip.String(), Overhead: MTUTunnelOverhead}
+	fits := func(mtu int) (bool, error) {
+		// 单个包丢失不代表超长，失败时重试一次
+		for i := 0; i < 2; i++ {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			result.Probes++
+			ok, err := t.PingDF(ip, mtu-header, mtuProbeTimeout)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	ok, err := fits(lo)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("服务器 %s 不响应 ICMP，无法探测 MTU", ip)
+	}
+
+	// lo 能通过、hi 不能通过
+	hi := MTUMaxProbe
+	if ok, err := fits(MTUMaxProbe); err != nil {
+		return nil, err
+	} else if ok {
+		lo = MTUMaxProbe
+	}
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		ok, err := fits(mid)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	result.PathMTU = lo
+	result.MTU = lo - MTUTunnelOverhead
+	if result.MTU < minTUNMTU {
+		result.MTU = minTUNMTU
+	}
+	result.CheckedAt = time.Now().Unix()
+	return result, nil
+}
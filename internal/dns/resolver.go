@@ -0,0 +1,597 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// 本机 DNS 服务（127.0.0.1:53 / [::1]:53）
+// =============================================================================
+//
+// 代理域名的 A/AAAA 查询直接从 Fake-IP 映射表应答，不向任何 DNS 服务器发出请求；
+// Fake-IP 过滤列表中的域名（局域网、时间同步、网络检测等）、单标签主机名和其他
+// 查询类型转发给上游（应答按 TTL 缓存），使所有程序（不只是经过 SOCKS 入站的流量）都不会泄露 DNS。
+
+const (
+	// LocalResolverPort 本机 DNS 服务端口
+	LocalResolverPort = 53
+
+	// fakeIPTTL Fake-IP 应答的 TTL（秒），保持很短以便切换节点或清空映射后立即生效
+	fakeIPTTL = 1
+	// resolverUpstreamTimeout 单个上游的查询超时
+	resolverUpstreamTimeout = 5 * time.Second
+	// resolverTCPIdleTimeout TCP 连接空闲超时
+	resolverTCPIdleTimeout = 10 * time.Second
+)
+
+// localResolverHosts 监听地址，IPv6 回环不可用时只监听 IPv4
+var localResolverHosts = []string{"127.0.0.1", "::1"}
+
+// DefaultResolverUpstream 未配置上游时使用的地址
+var DefaultResolverUpstream = []string{DNSAliDNS, DNSTencent}
+
+// DNS 报文常量
+const (
+	dnsTypeA     = 1
+	dnsTypePTR   = 12
+	dnsTypeAAAA  = 28
+	dnsTypeSVCB  = 64
+	dnsTypeHTTPS = 65
+	dnsClassIN   = 1
+
+	dnsRcodeFormErr  = 1
+	dnsRcodeServFail = 2
+
+	dnsHeaderSize = 12
+)
+
+// LocalResolverStats 本机 DNS 服务统计
+type LocalResolverStats struct {
+	Queries   uint64 `json:"queries"`   // 收到的查询
+	FakeIP    uint64 `json:"fake_ip"`   // 从 Fake-IP 映射应答
+	Cached    uint64 `json:"cached"`    // 从缓存应答
+	Forwarded uint64 `json:"forwarded"` // 转发给上游
+	Failed    uint64 `json:"failed"`    // 上游全部失败或报文无效
+}
+
+// LocalResolver 本机 DNS 服务
+type LocalResolver struct {
+	mu        sync.Mutex
+	manager   *Manager
+	filter    []string // 不使用 Fake-IP 的域名
+	upstream  []string
+	packets   []net.PacketConn
+	listeners []net.Listener
+
+	queries   uint64
+	fakeIP    uint64
+	cached    uint64
+	forwarded uint64
+	failed    uint64
+}
+
+// NewLocalResolver 创建本机 DNS 服务，Fake-IP 映射与 manager 共享
+func NewLocalResolver(manager *Manager) *LocalResolver {
+	return &LocalResolver{manager: manager, filter: DefaultDNSConfig().FakeIPFilter}
+}
+
+// NormalizeUpstream 将 "IP" 或 "IP:端口" 规范为 host:port（上游必须是 IP，避免解析上游时回环到本服务）
+func NormalizeUpstream(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	host, port := addr, strconv.Itoa(LocalResolverPort)
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		host, port = h, p
+	}
+	host = strings.Trim(host, "[]")
+
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("上游 DNS 必须是 IP 地址: %s", addr)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return "", fmt.Errorf("上游 DNS 端口无效: %s", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Start 在 127.0.0.1:53 和 [::1]:53 上启动 UDP/TCP 服务（已运行时先停止）
+func (r *LocalResolver) Start(upstream []string) error {
+	if len(upstream) == 0 {
+		upstream = DefaultResolverUpstream
+	}
+	servers := make([]string, 0, len(upstream))
+	for _, addr := range upstream {
+		server, err := NormalizeUpstream(addr)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, server)
+	}
+
+	r.Stop()
+
+	var packets []net.PacketConn
+	var listeners []net.Listener
+	for i, host := range localResolverHosts {
+		addr := net.JoinHostPort(host, strconv.Itoa(LocalResolverPort))
+		pc, err := net.ListenPacket("udp", addr)
+		if err == nil {
+			var ln net.Listener
+			if ln, err = net.Listen("tcp", addr); err == nil {
+				packets = append(packets, pc)
+				listeners = append(listeners, ln)
+				continue
+			}
+			pc.Close()
+		}
+
+		if i == 0 {
+			closeAll(packets, listeners)
+			return fmt.Errorf("本机 DNS 服务监听 %s 失败: %w", addr, err)
+		}
+		r.manager.log("warn", fmt.Sprintf("本机 DNS 服务未能监听 %s: %v", addr, err))
+	}
+
+	r.mu.Lock()
+	r.upstream = servers
+	r.packets = packets
+	r.listeners = listeners
+	r.mu.Unlock()
+
+	for _, pc := range packets {
+		go r.serveUDP(pc)
+	}
+	for _, ln := range listeners {
+		go r.serveTCP(ln)
+	}
+	return nil
+}
+
+// Stop 停止服务
+func (r *LocalResolver) Stop() {
+	r.mu.Lock()
+	packets, listeners := r.packets, r.listeners
+	r.packets, r.listeners = nil, nil
+	r.mu.Unlock()
+
+	closeAll(packets, listeners)
+}
+
+func closeAll(packets []net.PacketConn, listeners []net.Listener) {
+	for _, pc := range packets {
+		pc.Close()
+	}
+	for _, ln := range listeners {
+		ln.Close()
+	}
+}
+
+// IsRunning 是否正在运行
+func (r *LocalResolver) IsRunning() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.packets) > 0
+}
+
+// Addrs 实际监听的地址
+func (r *LocalResolver) Addrs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addrs := make([]string, 0, len(r.packets))
+	for _, pc := range r.packets {
+		addrs = append(addrs, pc.LocalAddr().String())
+	}
+	return addrs
+}
+
+// Stats 获取统计
+func (r *LocalResolver) Stats() LocalResolverStats {
+	return LocalResolverStats{
+		Queries:   atomic.LoadUint64(&r.queries),
+		FakeIP:    atomic.LoadUint64(&r.fakeIP),
+		Cached:    atomic.LoadUint64(&r.cached),
+		Forwarded: atomic.LoadUint64(&r.forwarded),
+		Failed:    atomic.LoadUint64(&r.failed),
+	}
+}
+
+// =============================================================================
+// 监听
+// =============================================================================
+
+func (r *LocalResolver) serveUDP(pc net.PacketConn) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		query := append([]byte(nil), buf[:n]...)
+		go func() {
+			if resp := r.handle(query, "udp"); resp != nil {
+				pc.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+func (r *LocalResolver) serveTCP(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go r.serveTCPConn(conn)
+	}
+}
+
+// serveTCPConn 处理一个 TCP 连接上的多个查询（2 字节长度前缀）
+func (r *LocalResolver) serveTCPConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		conn.SetDeadline(time.Now().Add(resolverTCPIdleTimeout))
+		query, err := readTCPMessage(conn)
+		if err != nil {
+			return
+		}
+		resp := r.handle(query, "tcp")
+		if resp == nil {
+			return
+		}
+		if err := writeTCPMessage(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+func readTCPMessage(conn io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(conn io.Writer, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := conn.Write(buf)
+	return err
+}
+
+// =============================================================================
+// 查询处理
+// =============================================================================
+
+// dnsQuestion 查询中的问题
+type dnsQuestion struct {
+	name  string // 小写，不含末尾的点
+	qtype uint16
+	class uint16
+	end   int // 问题部分在报文中的结束位置
+}
+
+// handle 处理一个查询报文，返回应答；报文无法识别时返回 nil
+func (r *LocalResolver) handle(query []byte, network string) []byte {
+	atomic.AddUint64(&r.queries, 1)
+
+	q, err := parseQuestion(query)
+	if err != nil {
+		atomic.AddUint64(&r.failed, 1)
+		if len(query) < dnsHeaderSize {
+			return nil
+		}
+		return errorResponse(query, dnsHeaderSize, dnsRcodeFormErr)
+	}
+
+	if q.class == dnsClassIN {
+		if resp, ok := r.answerLocal(query, q); ok {
+			atomic.AddUint64(&r.fakeIP, 1)
+			return resp
+		}
+	}
+
+	key := dnsCacheKey{name: q.name, qtype: q.qtype, class: q.class}
+	if resp := r.manager.cache.get(key, query[:2]); resp != nil {
+		atomic.AddUint64(&r.cached, 1)
+		return resp
+	}
+
+	resp, err := r.forward(query, network)
+	if err != nil {
+		atomic.AddUint64(&r.failed, 1)
+		r.manager.log("warn", fmt.Sprintf("本机 DNS 转发 %s 失败: %v", q.name, err))
+		return errorResponse(query, q.end, dnsRcodeServFail)
+	}
+	atomic.AddUint64(&r.forwarded, 1)
+	r.manager.cache.put(key, resp)
+	return resp
+}
+
+// answerLocal 代理域名的 A/AAAA 从 Fake-IP 映射应答，Fake-IP 的反向查询返回对应域名
+func (r *LocalResolver) answerLocal(query []byte, q *dnsQuestion) ([]byte, bool) {
+	if q.qtype == dnsTypePTR {
+		ip := ptrToIP(q.name)
+		if ip == "" || !r.manager.IsFakeIP(ip) {
+			return nil, false
+		}
+		domain, ok := r.manager.LookupFakeIP(ip)
+		if !ok {
+			return nil, false
+		}
+		return answerResponse(query, q, encodeName(domain)), true
+	}
+
+	// hosts 覆盖：类型不符（如 IPv4 地址的 AAAA 查询）时返回空应答
+	if ip, ok := r.manager.lookupHost(q.name); ok {
+		switch q.qtype {
+		case dnsTypeA:
+			return answerResponse(query, q, ip.To4()), true
+		case dnsTypeAAAA:
+			if ip.To4() != nil {
+				return answerResponse(query, q, nil), true
+			}
+			return answerResponse(query, q, ip.To16()), true
+		case dnsTypeSVCB, dnsTypeHTTPS:
+			return answerResponse(query, q, nil), true
+		}
+		return nil, false
+	}
+
+	if !r.proxied(q.name) {
+		return nil, false
+	}
+
+	ipVersion := r.manager.GetIPVersion()
+	switch q.qtype {
+	case dnsTypeA:
+		if ipVersion == IPVersionIPv6 {
+			return answerResponse(query, q, nil), true
+		}
+		return answerResponse(query, q, net.ParseIP(r.manager.AllocateFakeIP(q.name)).To4()), true
+	case dnsTypeAAAA:
+		if ipVersion == IPVersionIPv4 {
+			return answerResponse(query, q, nil), true
+		}
+		return answerResponse(query, q, net.ParseIP(r.manager.AllocateFakeIPv6(q.name)).To16()), true
+	case dnsTypeSVCB, dnsTypeHTTPS:
+		// 不返回 IP 提示，使浏览器回退到 A/AAAA 查询并连接 Fake-IP
+		return answerResponse(query, q, nil), true
+	}
+	return nil, false
+}
+
+// proxied 域名是否使用 Fake-IP（单标签主机名和过滤列表中的域名直接解析）
+func (r *LocalResolver) proxied(name string) bool {
+	if !strings.Contains(name, ".") || strings.HasSuffix(name, ".arpa") {
+		return false
+	}
+	for _, pattern := range r.filter {
+		if matchDomainPattern(name, pattern) {
+			return false
+		}
+	}
+	for _, pattern := range r.manager.customFakeIPFilter() {
+		if matchDomainPattern(name, pattern) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchDomainPattern 匹配 Fake-IP 过滤规则："+.x" 匹配 x 及其子域名，"*.x" 匹配 x 的子域名，其余为完整域名
+func matchDomainPattern(name, pattern string) bool {
+	switch {
+	case strings.HasPrefix(pattern, "+."):
+		suffix := pattern[2:]
+		return name == suffix || strings.HasSuffix(name, "."+suffix)
+	case strings.HasPrefix(pattern, "*."):
+		return strings.HasSuffix(name, pattern[1:])
+	}
+	return name == pattern
+}
+
+// forward 按顺序向上游查询，TCP 收到的查询也通过 TCP 转发
+func (r *LocalResolver) forward(query []byte, network string) ([]byte, error) {
+	r.mu.Lock()
+	upstream := r.upstream
+	r.mu.Unlock()
+
+	var lastErr error
+	for _, server := range upstream {
+		resp, err := exchange(query, network, server)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("未配置上游 DNS")
+	}
+	return nil, lastErr
+}
+
+// exchange 向单个上游发送查询
+func exchange(query []byte, network, server string) ([]byte, error) {
+	conn, err := net.DialTimeout(network, server, resolverUpstreamTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(resolverUpstreamTimeout))
+
+	if network == "tcp" {
+		if err := writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// 忽略 ID 不匹配的报文
+		if n >= dnsHeaderSize && buf[0] == query[0] && buf[1] == query[1] {
+			return append([]byte(nil), buf[:n]...), nil
+		}
+	}
+}
+
+// =============================================================================
+// 报文编解码
+// =============================================================================
+
+// parseQuestion 解析只含一个问题的标准查询
+func parseQuestion(msg []byte) (*dnsQuestion, error) {
+	if len(msg) < dnsHeaderSize {
+		return nil, fmt.Errorf("报文过短")
+	}
+	flags := binary.BigEndian.Uint16(msg[2:])
+	if flags&0x8000 != 0 || (flags>>11)&0xF != 0 {
+		return nil, fmt.Errorf("不是标准查询")
+	}
+	if binary.BigEndian.Uint16(msg[4:]) != 1 {
+		return nil, fmt.Errorf("问题数量不是 1")
+	}
+
+	var labels []string
+	off := dnsHeaderSize
+	for {
+		if off >= len(msg) {
+			return nil, fmt.Errorf("域名不完整")
+		}
+		n := int(msg[off])
+		off++
+		if n == 0 {
+			break
+		}
+		if n&0xC0 != 0 || off+n > len(msg) {
+			return nil, fmt.Errorf("域名格式无效")
+		}
+		labels = append(labels, strings.ToLower(string(msg[off:off+n])))
+		off += n
+	}
+	if off+4 > len(msg) {
+		return nil, fmt.Errorf("问题不完整")
+	}
+
+	return &dnsQuestion{
+		name:  strings.Join(labels, "."),
+		qtype: binary.BigEndian.Uint16(msg[off:]),
+		class: binary.BigEndian.Uint16(msg[off+2:]),
+		end:   off + 4,
+	}, nil
+}
+
+// responseHeader 基于查询生成应答头和问题部分（丢弃附加记录）
+func responseHeader(query []byte, questionEnd int, rcode uint16, answers uint16) []byte {
+	resp := make([]byte, questionEnd, questionEnd+64)
+	copy(resp, query[:questionEnd])
+
+	flags := binary.BigEndian.Uint16(query[2:])
+	flags = 0x8000 | flags&0x0100 | 0x0080 | rcode // QR, 保留 RD, RA
+	binary.BigEndian.PutUint16(resp[2:], flags)
+	if questionEnd > dnsHeaderSize {
+		binary.BigEndian.PutUint16(resp[4:], 1)
+	} else {
+		binary.BigEndian.PutUint16(resp[4:], 0)
+	}
+	binary.BigEndian.PutUint16(resp[6:], answers)
+	binary.BigEndian.PutUint16(resp[8:], 0)
+	binary.BigEndian.PutUint16(resp[10:], 0)
+	return resp
+}
+
+// errorResponse 生成错误应答
+func errorResponse(query []byte, questionEnd int, rcode uint16) []byte {
+	return responseHeader(query, questionEnd, rcode, 0)
+}
+
+// answerResponse 生成包含一条记录的应答，rdata 为空时返回无记录的成功应答
+func answerResponse(query []byte, q *dnsQuestion, rdata []byte) []byte {
+	if len(rdata) == 0 {
+		return responseHeader(query, q.end, 0, 0)
+	}
+
+	resp := responseHeader(query, q.end, 0, 1)
+	rr := make([]byte, 12, 12+len(rdata))
+	binary.BigEndian.PutUint16(rr[0:], 0xC000|dnsHeaderSize) // 指向问题中的域名
+	binary.BigEndian.PutUint16(rr[2:], q.qtype)
+	binary.BigEndian.PutUint16(rr[4:], dnsClassIN)
+	binary.BigEndian.PutUint32(rr[6:], fakeIPTTL)
+	binary.BigEndian.PutUint16(rr[10:], uint16(len(rdata)))
+	return append(append(resp, rr...), rdata...)
+}
+
+// encodeName 将域名编码为 DNS 报文格式
+func encodeName(name string) []byte {
+	var buf []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 {
+			continue
+		}
+		buf = append(buf, byte(len(label)))
+		buf = append(buf, label...)
+	}
+	return append(buf, 0)
+}
+
+// ptrToIP 将反向查询域名转换为 IP，不是反向查询时返回空字符串
+func ptrToIP(name string) string {
+	if rest := strings.TrimSuffix(name, ".in-addr.arpa"); rest != name {
+		parts := strings.Split(rest, ".")
+		if len(parts) != 4 {
+			return ""
+		}
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		if ip := net.ParseIP(strings.Join(parts, ".")); ip != nil {
+			return ip.String()
+		}
+		return ""
+	}
+
+	if rest := strings.TrimSuffix(name, ".ip6.arpa"); rest != name {
+		nibbles := strings.Split(rest, ".")
+		if len(nibbles) != 32 {
+			return ""
+		}
+		var sb strings.Builder
+		for i := len(nibbles) - 1; i >= 0; i-- {
+			sb.WriteString(nibbles[i])
+			if i%4 == 0 && i > 0 {
+				sb.WriteByte(':')
+			}
+		}
+		if ip := net.ParseIP(sb.String()); ip != nil {
+			return ip.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package dns
+
+import "fmt"
+
+// interfaceDNS 非Windows平台不支持读取网卡DNS
+func interfaceDNS(interfaceName string, ipv6 bool) ([]string, error) {
+	return nil, fmt.Errorf("仅支持Windows")
+}
@@ -0,0 +1,137 @@
+//go:build windows
+// +build windows
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"xlink-wails/internal/syschange"
+)
+
+// =============================================================================
+// 网卡 DNS（IP Helper API）
+// =============================================================================
+
+// netsh 的输出随系统语言变化，解析在非英文系统上会出错，设置时也需要逐条启动进程。
+// 读取改用 GetAdaptersAddresses，设置优先调用 SetInterfaceDnsSettings（Windows 10 2004 起提供），
+// 旧系统或调用失败时由计划中的 PowerShell 命令完成。
+
+var (
+	modiphlpapi                 = windows.NewLazySystemDLL("iphlpapi.dll")
+	procSetInterfaceDnsSettings = modiphlpapi.NewProc("SetInterfaceDnsSettings")
+)
+
+const (
+	dnsInterfaceSettingsVersion1 = 1
+	dnsSettingIPv6               = 0x0001
+	dnsSettingNameServer         = 0x0002
+
+	gaaFlagSkipAnycast   = 0x0002
+	gaaFlagSkipMulticast = 0x0004
+)
+
+// dnsInterfaceSettings 对应 DNS_INTERFACE_SETTINGS
+type dnsInterfaceSettings struct {
+	Version             uint32
+	Flags               uint64
+	Domain              *uint16
+	NameServer          *uint16
+	SearchList          *uint16
+	RegistrationEnabled uint32
+	RegisterAdapterName uint32
+	EnableLLMNR         uint32
+	QueryAdapterName    uint32
+	ProfileNameServer   *uint16
+}
+
+func init() {
+	syschange.RegisterNative(nativeSetDNS, setInterfaceDNSNative)
+}
+
+// setInterfaceDNSNative 参数：网卡名称、ipv4 / ipv6、逗号分隔的服务器（为空时恢复自动获取）
+func setInterfaceDNSNative(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("参数无效")
+	}
+	if err := procSetInterfaceDnsSettings.Find(); err != nil {
+		return fmt.Errorf("系统不支持 SetInterfaceDnsSettings")
+	}
+	adapter, err := findAdapter(args[0])
+	if err != nil {
+		return err
+	}
+	guid, err := windows.GUIDFromString(windows.BytePtrToString(adapter.AdapterName))
+	if err != nil {
+		return err
+	}
+	servers, err := windows.UTF16PtrFromString(args[2])
+	if err != nil {
+		return err
+	}
+
+	settings := dnsInterfaceSettings{
+		Version:    dnsInterfaceSettingsVersion1,
+		Flags:      dnsSettingNameServer,
+		NameServer: servers,
+	}
+	if args[1] == "ipv6" {
+		settings.Flags |= dnsSettingIPv6
+	}
+	r, _, _ := procSetInterfaceDnsSettings.Call(
+		uintptr(unsafe.Pointer(&guid)),
+		uintptr(unsafe.Pointer(&settings)),
+	)
+	if r != 0 {
+		return windows.Errno(r)
+	}
+	return nil
+}
+
+// interfaceDNS 读取网卡当前使用的 DNS 服务器
+func interfaceDNS(interfaceName string, ipv6 bool) ([]string, error) {
+	adapter, err := findAdapter(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	var servers []string
+	for s := adapter.FirstDnsServerAddress; s != nil; s = s.Next {
+		ip := s.Address.IP()
+		if ip == nil || (ip.To4() == nil) != ipv6 {
+			continue
+		}
+		// 未配置 IPv6 DNS 时系统会列出站点本地的占位地址
+		if ipv6 && ip.Equal(net.ParseIP("fec0:0:0:ffff::1")) {
+			continue
+		}
+		servers = append(servers, ip.String())
+	}
+	return servers, nil
+}
+
+// findAdapter 按名称（网络连接中显示的名称）查找网卡
+func findAdapter(interfaceName string) (*windows.IpAdapterAddresses, error) {
+	size := uint32(15000)
+	for {
+		buf := make([]byte, size)
+		first := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0]))
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, gaaFlagSkipAnycast|gaaFlagSkipMulticast, 0, first, &size)
+		if err == windows.ERROR_BUFFER_OVERFLOW {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取网卡列表失败: %w", err)
+		}
+		for a := first; a != nil; a = a.Next {
+			if strings.EqualFold(windows.UTF16PtrToString(a.FriendlyName), interfaceName) {
+				return a, nil
+			}
+		}
+		return nil, fmt.Errorf("找不到网卡: %s", interfaceName)
+	}
+}
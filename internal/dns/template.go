@@ -0,0 +1,105 @@
+package dns
+
+import (
+	"encoding/json"
+	"strings"
+
+	"xlink-wails/internal/i18n"
+)
+
+// =============================================================================
+// 自定义 Xray 配置模板
+// =============================================================================
+
+// 高级用户可提供一份 Xray 配置作为模板（全局或按节点），生成的配置渲染到模板中：
+//   - 对象逐层合并，程序生成的字段覆盖模板中的同名字段（log、dns、fakedns 等由程序维护）；
+//   - inbounds / outbounds 中程序生成的排在前面（第一个出站为默认出站），模板中标签不重复的追加在后；
+//   - routing.rules 中模板的规则排在程序生成的规则之前，便于为 api 等额外入站指定出站；
+//   - 其他数组以程序生成的为准。
+// 这样 policy、stats、api、observatory 等程序未提供的选项无需修改程序即可加入。
+
+// ParseXrayTemplate 解析模板，必须是 JSON 对象（空白模板返回 nil）
+func ParseXrayTemplate(template string) (map[string]interface{}, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, nil
+	}
+	var tpl map[string]interface{}
+	if err := json.Unmarshal([]byte(template), &tpl); err != nil {
+		return nil, i18n.Errorf("Xray 配置模板不是有效的 JSON 对象: %w", err)
+	}
+	if tpl == nil {
+		return nil, i18n.Errorf("Xray 配置模板必须是 JSON 对象")
+	}
+	return tpl, nil
+}
+
+// Render 把生成的配置渲染为 JSON，设置了模板时合并到模板中
+func (c *XrayFullConfig) Render() ([]byte, error) {
+	managed, err := json.MarshalIndent(c, "", "  ")
+	if err != nil || strings.TrimSpace(c.Template) == "" {
+		return managed, err
+	}
+
+	tpl, err := ParseXrayTemplate(c.Template)
+	if err != nil {
+		return nil, err
+	}
+	var generated map[string]interface{}
+	if err := json.Unmarshal(managed, &generated); err != nil {
+		return nil, err
+	}
+
+	merged := mergeXrayObject(tpl, generated)
+	if routing, ok := merged["routing"].(map[string]interface{}); ok {
+		tplRouting, _ := tpl["routing"].(map[string]interface{})
+		tplRules, _ := tplRouting["rules"].([]interface{})
+		rules, _ := routing["rules"].([]interface{})
+		routing["rules"] = append(append([]interface{}{}, tplRules...), rules...)
+	}
+	for _, key := range []string{"inbounds", "outbounds"} {
+		tplList, _ := tpl[key].([]interface{})
+		list, _ := generated[key].([]interface{})
+		merged[key] = appendByTag(list, tplList)
+	}
+	return json.MarshalIndent(merged, "", "  ")
+}
+
+// mergeXrayObject 逐层合并对象，generated 中的字段优先
+func mergeXrayObject(tpl, generated map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(tpl)+len(generated))
+	for k, v := range tpl {
+		merged[k] = v
+	}
+	for k, v := range generated {
+		sub, ok1 := v.(map[string]interface{})
+		base, ok2 := merged[k].(map[string]interface{})
+		if ok1 && ok2 {
+			merged[k] = mergeXrayObject(base, sub)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// appendByTag 在生成的列表后追加模板中标签不重复的项
+func appendByTag(generated, extra []interface{}) []interface{} {
+	result := append([]interface{}{}, generated...)
+	tags := make(map[string]bool, len(generated))
+	for _, item := range generated {
+		if m, ok := item.(map[string]interface{}); ok {
+			if tag, _ := m["tag"].(string); tag != "" {
+				tags[tag] = true
+			}
+		}
+	}
+	for _, item := range extra {
+		if m, ok := item.(map[string]interface{}); ok {
+			if tag, _ := m["tag"].(string); tag != "" && tags[tag] {
+				continue
+			}
+		}
+		result = append(result, item)
+	}
+	return result
+}
@@ -5,6 +5,10 @@ package dns
 
 import (
 	"fmt"
+	"net"
+	"time"
+
+	"xlink-wails/internal/syschange"
 )
 
 // TUNManager 非Windows平台TUN管理器
@@ -36,6 +40,11 @@ func (t *TUNManager) SetupTUN(tunIP, gateway string, mtu int) error {
 	return fmt.Errorf("TUN模式在当前平台暂不支持")
 }
 
+// PlanTUNSetup 配置TUN的命令
+func (t *TUNManager) PlanTUNSetup(tunIP, gateway string, mtu int) *syschange.Plan {
+	return syschange.NewPlan("TUN模式在当前平台暂不支持", true)
+}
+
 // AddRoute 添加路由
 func (t *TUNManager) AddRoute(destination, mask, gateway string) error {
 	return fmt.Errorf("暂不支持")
@@ -51,6 +60,11 @@ func (t *TUNManager) SetupDefaultRoute(tunGateway string, excludeIPs []string) e
 	return fmt.Errorf("暂不支持")
 }
 
+// PlanDefaultRoute 设置默认路由的命令
+func (t *TUNManager) PlanDefaultRoute(tunGateway string, excludeIPs []string) (*syschange.Plan, error) {
+	return nil, fmt.Errorf("暂不支持")
+}
+
 // GetDefaultGateway 获取默认网关
 func (t *TUNManager) GetDefaultGateway() (string, error) {
 	return "", fmt.Errorf("暂不支持")
@@ -61,6 +75,16 @@ func (t *TUNManager) RestoreRoute(originalGateway string) error {
 	return fmt.Errorf("暂不支持")
 }
 
+// PlanRestoreRoute 恢复路由的命令
+func (t *TUNManager) PlanRestoreRoute(originalGateway string) *syschange.Plan {
+	return syschange.NewPlan("暂不支持", true)
+}
+
+// PlanUndoDefaultRoute 撤销默认路由的命令
+func (t *TUNManager) PlanUndoDefaultRoute(originalGateway string, excludeIPs []string) *syschange.Plan {
+	return syschange.NewPlan("暂不支持", true)
+}
+
 // SetDNSForInterface 设置DNS
 func (t *TUNManager) SetDNSForInterface(dns []string) error {
 	return fmt.Errorf("暂不支持")
@@ -70,3 +94,8 @@ func (t *TUNManager) SetDNSForInterface(dns []string) error {
 func (t *TUNManager) FlushDNSCache() error {
 	return fmt.Errorf("暂不支持")
 }
+
+// PingDF 发送禁止分片的 ICMP 包
+func (t *TUNManager) PingDF(ip net.IP, size int, timeout time.Duration) (bool, error) {
+	return false, fmt.Errorf("暂不支持")
+}
@@ -5,9 +5,15 @@ package dns
 
 import (
 	"fmt"
+	"net"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
+
+	"xlink-wails/internal/syschange"
 )
 
 // =============================================================================
@@ -61,36 +67,39 @@ func (t *TUNManager) SetupTUN(tunIP, gateway string, mtu int) error {
 		return fmt.Errorf("需要管理员权限")
 	}
 
+	if err := t.PlanTUNSetup(tunIP, gateway, mtu).Run(); err != nil {
+		return fmt.Errorf("配置TUN失败: %v", err)
+	}
+
+	t.isUp = true
+	return nil
+}
+
+// PlanTUNSetup 生成配置TUN网卡的命令（不执行）
+func (t *TUNManager) PlanTUNSetup(tunIP, gateway string, mtu int) *syschange.Plan {
+	plan := syschange.NewPlan(fmt.Sprintf("配置 TUN 网卡 %s", t.tunName), true)
+
 	// 配置IP地址
-	cmd := exec.Command("netsh", "interface", "ip", "set", "address",
+	plan.Add("配置TUN IP", "netsh", "interface", "ip", "set", "address",
 		fmt.Sprintf("name=%s", t.tunName),
 		"source=static",
 		fmt.Sprintf("addr=%s", tunIP),
 		"mask=255.255.0.0",
 		fmt.Sprintf("gateway=%s", gateway),
 	)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("配置TUN IP失败: %v", err)
-	}
 
-	// 设置MTU
-	cmd = exec.Command("netsh", "interface", "ipv4", "set", "subinterface",
+	// 设置MTU（失败时忽略）
+	plan.AddOptional("设置TUN MTU", "netsh", "interface", "ipv4", "set", "subinterface",
 		t.tunName,
 		fmt.Sprintf("mtu=%d", mtu),
 		"store=persistent",
 	)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	cmd.Run() // 忽略错误
-
-	t.isUp = true
-	return nil
+	return plan
 }
 
 // AddRoute 添加路由
 func (t *TUNManager) AddRoute(destination, mask, gateway string) error {
-	cmd := exec.Command("route", "add", destination, "mask", mask, gateway, "metric", "1")
+	cmd := exec.Command("route", routeAddArgs(destination, mask, gateway)...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 	return cmd.Run()
 }
@@ -104,22 +113,34 @@ func (t *TUNManager) DeleteRoute(destination, mask string) error {
 
 // SetupDefaultRoute 设置默认路由走TUN
 func (t *TUNManager) SetupDefaultRoute(tunGateway string, excludeIPs []string) error {
+	plan, err := t.PlanDefaultRoute(tunGateway, excludeIPs)
+	if err != nil {
+		return err
+	}
+	return plan.Run()
+}
+
+// PlanDefaultRoute 生成默认路由走TUN的命令（只读取当前网关，不修改路由）
+func (t *TUNManager) PlanDefaultRoute(tunGateway string, excludeIPs []string) (*syschange.Plan, error) {
 	// 先获取原始默认网关
 	originalGateway, err := t.GetDefaultGateway()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	plan := syschange.NewPlan("设置默认路由经过 TUN", true)
+
 	// 为排除的IP添加直连路由
 	for _, ip := range excludeIPs {
-		t.AddRoute(ip, "255.255.255.255", originalGateway)
+		plan.AddOptional(fmt.Sprintf("添加 %s 直连路由", ip), "route", routeAddArgs(ip, "255.255.255.255", originalGateway)...)
 	}
 
 	// 删除原始默认路由
-	t.DeleteRoute("0.0.0.0", "0.0.0.0")
+	plan.AddOptional("删除原默认路由", "route", "delete", "0.0.0.0", "mask", "0.0.0.0")
 
 	// 添加新的默认路由
-	return t.AddRoute("0.0.0.0", "0.0.0.0", tunGateway)
+	plan.Add("添加TUN默认路由", "route", routeAddArgs("0.0.0.0", "0.0.0.0", tunGateway)...)
+	return plan, nil
 }
 
 // GetDefaultGateway 获取默认网关
@@ -147,42 +168,54 @@ func (t *TUNManager) GetDefaultGateway() (string, error) {
 
 // RestoreRoute 恢复原始路由
 func (t *TUNManager) RestoreRoute(originalGateway string) error {
+	return t.PlanRestoreRoute(originalGateway).Run()
+}
+
+// PlanRestoreRoute 生成恢复原始路由的命令（不执行）
+func (t *TUNManager) PlanRestoreRoute(originalGateway string) *syschange.Plan {
+	plan := syschange.NewPlan("恢复原默认路由", true)
+
 	// 删除TUN路由
-	t.DeleteRoute("0.0.0.0", "0.0.0.0")
+	plan.AddOptional("删除TUN默认路由", "route", "delete", "0.0.0.0", "mask", "0.0.0.0")
 
 	// 恢复原始默认路由
-	return t.AddRoute("0.0.0.0", "0.0.0.0", originalGateway)
+	plan.Add("恢复原默认路由", "route", routeAddArgs("0.0.0.0", "0.0.0.0", originalGateway)...)
+	return plan
+}
+
+// PlanUndoDefaultRoute 生成撤销 PlanDefaultRoute 的命令：恢复原默认路由并删除排除 IP 的直连路由
+func (t *TUNManager) PlanUndoDefaultRoute(originalGateway string, excludeIPs []string) *syschange.Plan {
+	plan := t.PlanRestoreRoute(originalGateway)
+	for _, ip := range excludeIPs {
+		plan.AddOptional(fmt.Sprintf("删除 %s 直连路由", ip), "route", "delete", ip, "mask", "255.255.255.255")
+	}
+	return plan
+}
+
+// routeAddArgs route add 的参数
+func routeAddArgs(destination, mask, gateway string) []string {
+	return []string{"add", destination, "mask", mask, gateway, "metric", "1"}
 }
 
 // SetDNSForInterface 为TUN接口设置DNS
 func (t *TUNManager) SetDNSForInterface(dns []string) error {
-	if len(dns) == 0 {
-		return nil
+	var ipv4DNS, ipv6DNS []string
+	for _, addr := range dns {
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+			ipv6DNS = append(ipv6DNS, addr)
+		} else {
+			ipv4DNS = append(ipv4DNS, addr)
+		}
 	}
 
-	cmd := exec.Command("netsh", "interface", "ip", "set", "dns",
-		fmt.Sprintf("name=%s", t.tunName),
-		"source=static",
-		fmt.Sprintf("addr=%s", dns[0]),
-	)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-
-	if err := cmd.Run(); err != nil {
+	plan := syschange.NewPlan(fmt.Sprintf("设置 %s 的 DNS", t.tunName), true)
+	if err := addInterfaceDNS(plan, t.tunName, ipv4DNS, false); err != nil {
 		return err
 	}
-
-	// 添加备用DNS
-	for i := 1; i < len(dns); i++ {
-		cmd = exec.Command("netsh", "interface", "ip", "add", "dns",
-			fmt.Sprintf("name=%s", t.tunName),
-			fmt.Sprintf("addr=%s", dns[i]),
-			fmt.Sprintf("index=%d", i+1),
-		)
-		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-		cmd.Run()
+	if err := addInterfaceDNS(plan, t.tunName, ipv6DNS, true); err != nil {
+		return err
 	}
-
-	return nil
+	return plan.Run()
 }
 
 // FlushDNSCache 刷新DNS缓存
@@ -192,6 +225,30 @@ func (t *TUNManager) FlushDNSCache() error {
 	return cmd.Run()
 }
 
+// pingReplyPattern ping 回复行中的往返时间（"时间=12ms"、"time<1ms"），超长包与不可达提示中没有
+var pingReplyPattern = regexp.MustCompile(`(?i)[=<]\s*\d+\s*ms`)
+
+// PingDF 发送一个指定数据长度、禁止分片的 ICMP 包，返回是否收到回复
+func (t *TUNManager) PingDF(ip net.IP, size int, timeout time.Duration) (bool, error) {
+	args := []string{"-n", "1", "-w", strconv.Itoa(int(timeout.Milliseconds())), "-l", strconv.Itoa(size)}
+	if ip.To4() != nil {
+		args = append(args, "-4", "-f")
+	} else {
+		// IPv6 路由器从不分片，无需 -f
+		args = append(args, "-6")
+	}
+	cmd := exec.Command("ping", append(args, ip.String())...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("执行 ping 失败: %v", err)
+	}
+	return pingReplyPattern.Match(output), nil
+}
+
 // fileExists 检查文件是否存在
 func fileExists(path string) bool {
 	_, err := syscall.GetFileAttributes(syscall.StringToUTF16Ptr(path))
@@ -0,0 +1,367 @@
+//go:build e2e
+// +build e2e
+
+// Package e2e 使用模拟内核（mockcore）对引擎、日志解析、流量统计和进程监控做端到端测试，
+// 无需真实内核和服务器，在开发机上即可运行:
+//
+//	go run -tags e2e ./internal/e2e/runner
+package e2e
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/engine"
+	"xlink-wails/internal/generator"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 模拟内核
+// =============================================================================
+
+// MockCorePackage 模拟内核的包路径
+const MockCorePackage = "xlink-wails/internal/e2e/mockcore"
+
+// BehaviorFile 模拟内核读取的行为控制文件（位于运行目录）
+const BehaviorFile = "mockcore.json"
+
+// Behavior 模拟内核的异常行为，字段与 mockcore 一致
+type Behavior struct {
+	StartDelayMs int      `json:"start_delay_ms"`
+	FailStart    bool     `json:"fail_start"`
+	CrashAfterMs int      `json:"crash_after_ms"`
+	ExitCode     int      `json:"exit_code"`
+	PingDelayMs  int      `json:"ping_delay_ms"`
+	PingSleepMs  int      `json:"ping_sleep_ms"`
+	LatencyMs    int      `json:"latency_ms"`
+	ExtraLines   []string `json:"extra_lines"`
+	ConfigError  string   `json:"config_error"`
+	NoRulesAPI   bool     `json:"no_rules_api"`
+}
+
+// BuildMockCore 编译模拟内核到 dir，返回可执行文件路径
+func BuildMockCore(dir string) (string, error) {
+	path := filepath.Join(dir, "mockcore")
+	cmd := exec.Command("go", "build", "-tags", "mockcore", "-o", path, MockCorePackage)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("编译模拟内核失败: %v\n%s", err, out)
+	}
+	return path, nil
+}
+
+// =============================================================================
+// 测试环境
+// =============================================================================
+
+// Harness 一个独立的运行目录，按 App 的方式组装引擎、日志和流量统计
+type Harness struct {
+	Dir    string
+	Engine *engine.Manager
+	Logs   *logger.Manager
+	Stats  *logger.StatsManager
+
+	gen  *generator.Generator
+	dns  *dns.Manager
+	echo net.Listener
+
+	mu       sync.Mutex
+	statuses map[string][]string // 节点状态变化历史
+	changed  chan struct{}
+}
+
+// New 在 dir 下创建测试环境，mockCore 为 BuildMockCore 返回的路径
+func New(dir, mockCore string) (*Harness, error) {
+	for _, name := range []string{engine.XlinkBinaryName, engine.XrayBinaryName, engine.SingBoxBinaryName} {
+		if err := copyFile(mockCore, filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+	}
+
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	go serveEcho(echo)
+
+	h := &Harness{
+		Dir:      dir,
+		Engine:   engine.NewManager(dir),
+		Logs:     logger.NewManager(dir),
+		Stats:    logger.NewStatsManager(),
+		gen:      generator.NewGenerator(dir),
+		dns:      dns.NewManager(dir),
+		echo:     echo,
+		statuses: make(map[string][]string),
+		changed:  make(chan struct{}),
+	}
+
+	h.Engine.SetLogCallback(func(nodeID, nodeName, level, category, message string) {
+		if category == logger.CategoryStats {
+			if h.Engine.CoreStatsEnabled(nodeID) {
+				h.Stats.RecordConnection(nodeID, message)
+			} else {
+				h.Stats.Record(nodeID, message)
+			}
+		}
+		h.Logs.LogNode(nodeID, nodeName, level, category, message)
+	})
+	h.Engine.SetTrafficCallback(h.Stats.RecordCounters)
+	h.Engine.SetStatusCallback(func(nodeID, status string, err error) {
+		h.mu.Lock()
+		h.statuses[nodeID] = append(h.statuses[nodeID], status)
+		close(h.changed)
+		h.changed = make(chan struct{})
+		h.mu.Unlock()
+		if err != nil {
+			h.Logs.LogNode(nodeID, nodeID, logger.LevelError, logger.CategorySystem, err.Error())
+		}
+	})
+	return h, nil
+}
+
+// Close 停止全部节点并释放资源
+func (h *Harness) Close() {
+	h.Engine.StopAll()
+	h.Logs.Stop()
+	h.echo.Close()
+}
+
+// SetBehavior 设置之后启动的内核的行为
+func (h *Harness) SetBehavior(b Behavior) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(h.Dir, BehaviorFile), data, 0644)
+}
+
+// EchoAddr 回显服务地址（作为代理目标）
+func (h *Harness) EchoAddr() string {
+	return h.echo.Addr().String()
+}
+
+// NewNode 创建监听在空闲端口的全局代理节点
+func (h *Harness) NewNode(name string) *models.NodeConfig {
+	node := models.NewDefaultNode(name)
+	node.Listen = "127.0.0.1:" + strconv.Itoa(h.Engine.FindFreePort())
+	node.Server = "mock.example.com:443"
+	node.Token = "mock-token"
+	node.RoutingMode = models.RoutingModeGlobal
+	return &node
+}
+
+// StartNode 生成核心配置并启动节点（设置了带宽限制时与 App 一样经限速转发；
+// 智能分流时与 App 一样生成开启统计接口的 Xray 或 sing-box 配置）
+func (h *Harness) StartNode(node *models.NodeConfig) error {
+	configPath, err := h.generate(node, &models.NodeConfig{})
+	if err != nil {
+		return err
+	}
+	return h.Engine.StartNode(node, configPath)
+}
+
+// ReloadNode 与 App 一样沿用运行中实例的内部端口重新生成配置，并热重载到运行中的节点
+func (h *Harness) ReloadNode(node *models.NodeConfig) (*engine.ReloadResult, error) {
+	before, err := h.Engine.SnapshotConfig(node.ID)
+	if err != nil {
+		return nil, err
+	}
+	configPath, err := h.generate(node, before.Node())
+	if err != nil {
+		return nil, err
+	}
+	return h.Engine.ReloadNode(node, configPath, before)
+}
+
+// generate 生成节点配置，running 中不为 0 的内部端口沿用
+func (h *Harness) generate(node, running *models.NodeConfig) (string, error) {
+	allocate := func(reuse int) int {
+		if reuse > 0 {
+			return reuse
+		}
+		return h.Engine.FindFreePort()
+	}
+	listen := node.Listen
+	node.BandwidthRelays = nil
+	node.StatsPort = 0
+	node.APIPort = 0
+	if node.RoutingMode == models.RoutingModeSmart {
+		node.InternalPort = allocate(running.InternalPort)
+		node.StatsPort = allocate(running.StatsPort)
+		if !models.IsSingBoxFront(node) {
+			node.APIPort = allocate(running.APIPort)
+		}
+		listen = "127.0.0.1:" + strconv.Itoa(node.InternalPort)
+	} else if models.HasBandwidthLimit(node) {
+		listen = running.BandwidthRelays[node.Listen]
+		if listen == "" {
+			listen = "127.0.0.1:" + strconv.Itoa(h.Engine.FindFreePort())
+		}
+		node.BandwidthRelays = map[string]string{node.Listen: listen}
+	}
+	configPath, err := h.gen.GenerateXlinkConfig(node, listen)
+	if err != nil {
+		return "", err
+	}
+	if models.IsSingBoxFront(node) {
+		if _, _, err := h.gen.GenerateSingBoxConfig(node, node.InternalPort); err != nil {
+			return "", err
+		}
+	} else if node.RoutingMode == models.RoutingModeSmart {
+		cfg, err := h.dns.GenerateFullXrayConfig(node, node.InternalPort, false, false)
+		if err != nil {
+			return "", err
+		}
+		xrayPath := filepath.Join(h.Dir, fmt.Sprintf(generator.XrayConfigTemplate, node.ID))
+		if err := h.dns.WriteXrayConfig(cfg, xrayPath); err != nil {
+			return "", err
+		}
+	}
+	return configPath, nil
+}
+
+// WaitStatus 等待节点进入指定状态（包括曾经进入过）
+func (h *Harness) WaitStatus(nodeID, status string, timeout time.Duration) error {
+	return h.WaitStatusCount(nodeID, status, 1, timeout)
+}
+
+// WaitStatusCount 等待节点累计 n 次进入指定状态
+func (h *Harness) WaitStatusCount(nodeID, status string, n int, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		h.mu.Lock()
+		history := h.statuses[nodeID]
+		changed := h.changed
+		h.mu.Unlock()
+
+		count := 0
+		for _, s := range history {
+			if s == status {
+				count++
+			}
+		}
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return fmt.Errorf("等待节点 %s 第 %d 次进入 %s 超时，状态历史: %v", nodeID, n, status, history)
+		}
+	}
+}
+
+// WaitListening 等待节点入站可连接
+func (h *Harness) WaitListening(node *models.NodeConfig, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if conn, err := net.DialTimeout("tcp", node.Listen, 200*time.Millisecond); err == nil {
+			conn.Close()
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("节点入站 %s 无法连接", node.Listen)
+}
+
+// WaitLog 等待节点出现满足条件的日志
+func (h *Harness) WaitLog(nodeID string, match func(e models.LogEntry) bool, timeout time.Duration) (models.LogEntry, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, e := range h.Logs.GetLogsByNode(nodeID, logger.BufferSize) {
+			if match(e) {
+				return e, nil
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return models.LogEntry{}, fmt.Errorf("等待节点 %s 的日志超时", nodeID)
+}
+
+// Fetch 通过节点的 SOCKS5 入站向回显服务发送 payload，返回回显内容
+func (h *Harness) Fetch(node *models.NodeConfig, payload []byte) ([]byte, error) {
+	conn, err := net.DialTimeout("tcp", node.Listen, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if err := socksConnect(conn, h.EchoAddr()); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(payload); err != nil {
+		return nil, err
+	}
+	conn.(*net.TCPConn).CloseWrite()
+	return io.ReadAll(conn)
+}
+
+// =============================================================================
+// 工具函数
+// =============================================================================
+
+// socksConnect 无认证 SOCKS5 CONNECT（仅 IPv4 目标）
+func socksConnect(conn net.Conn, target string) error {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host).To4()
+	port, _ := strconv.Atoi(portStr)
+	if ip == nil {
+		return fmt.Errorf("仅支持 IPv4 目标: %s", target)
+	}
+
+	if _, err := conn.Write([]byte{5, 1, 0}); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil || reply[1] != 0 {
+		return fmt.Errorf("SOCKS5 握手失败")
+	}
+
+	req := append([]byte{5, 1, 0, 1}, ip...)
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	resp := make([]byte, 10)
+	if _, err := io.ReadFull(conn, resp); err != nil || resp[1] != 0 {
+		return fmt.Errorf("SOCKS5 连接失败")
+	}
+	return nil
+}
+
+func serveEcho(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		}()
+	}
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0755)
+}
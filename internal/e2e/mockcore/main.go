@@ -0,0 +1,665 @@
+//go:build mockcore
+// +build mockcore
+
+// mockcore 模拟 xlink-cli-binary / xray / sing-box 的测试内核
+// 读取与真实内核相同的配置文件，在入站地址上提供可用的 SOCKS5（含 UDP ASSOCIATE）/ HTTP 代理（直连目标），
+// 并按真实内核的格式输出 Rule Hit / LB / Tunnel / [Stats] 日志，供 e2e 测试驱动引擎、日志解析和流量统计。
+// 作为 xray 运行且配置了 metrics.listen 时，与真实 Xray 一样在 /debug/vars 提供按入站 / 出站标签的计数；
+// 以 sing-box 为文件名运行且配置了 Clash API 时，在 /connections 提供累计计数。
+// 作为 xray 运行且配置了 api.listen 时接受 xray api adrules 替换路由规则（以 HTTP 代替真实的 gRPC），
+// 替换后输出规则数量，供 e2e 测试确认规则经 API 更新而进程没有重启。
+//
+// 构建: go build -tags mockcore -o xlink-cli-binary.exe ./internal/e2e/mockcore
+//
+// 运行目录下的 mockcore.json 用于控制异常行为（启动失败、运行中崩溃等），见 behavior。
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BehaviorFile 行为控制文件名（位于工作目录）
+const BehaviorFile = "mockcore.json"
+
+// behavior 异常行为控制，与 e2e.Behavior 字段一致
+type behavior struct {
+	StartDelayMs int      `json:"start_delay_ms"` // 监听前等待
+	FailStart    bool     `json:"fail_start"`     // 输出错误后立即退出
+	CrashAfterMs int      `json:"crash_after_ms"` // 运行指定时间后异常退出
+	ExitCode     int      `json:"exit_code"`      // 异常退出码（默认 2）
+	PingDelayMs  int      `json:"ping_delay_ms"`  // --ping 报告的延迟（默认 42）
+	PingSleepMs  int      `json:"ping_sleep_ms"`  // --ping 输出结果前等待（模拟慢速测速）
+	LatencyMs    int      `json:"latency_ms"`     // Tunnel 日志中的延迟（默认 35）
+	ExtraLines   []string `json:"extra_lines"`    // 启动后额外输出的日志行
+	ConfigError  string   `json:"config_error"`   // xray run -test / sing-box check 报告的配置错误
+	NoRulesAPI   bool     `json:"no_rules_api"`   // 模拟不支持 xray api adrules 的旧版 Xray
+}
+
+// inbound 同时兼容 xlink（listen 为 host:port）、xray（listen + port）和 sing-box（listen + listen_port）配置
+type inbound struct {
+	Tag        string `json:"tag"`
+	Listen     string `json:"listen"`
+	Port       int    `json:"port"`
+	ListenPort int    `json:"listen_port"`
+	Protocol   string `json:"protocol"`
+	Type       string `json:"type"`
+}
+
+type coreConfig struct {
+	Metrics struct {
+		Listen string `json:"listen"`
+	} `json:"metrics"`
+	API struct {
+		Listen string `json:"listen"`
+	} `json:"api"`
+	Experimental struct {
+		ClashAPI struct {
+			ExternalController string `json:"external_controller"`
+		} `json:"clash_api"`
+	} `json:"experimental"`
+	Inbounds  []inbound `json:"inbounds"`
+	Outbounds []struct {
+		Settings struct {
+			Server   string `json:"server"`
+			Strategy string `json:"strategy"`
+			Rules    string `json:"rules"`
+		} `json:"settings"`
+	} `json:"outbounds"`
+}
+
+var (
+	out   = bufio.NewWriter(os.Stdout)
+	outMu sync.Mutex
+)
+
+func logf(format string, args ...interface{}) {
+	outMu.Lock()
+	defer outMu.Unlock()
+	fmt.Fprintf(out, time.Now().Format("2006/01/02 15:04:05")+" "+format+"\n", args...)
+	out.Flush()
+}
+
+func main() {
+	b := loadBehavior()
+
+	// xray 形式: run -c config.json；sing-box 形式: run / check -c config.json（按程序文件名区分）
+	args := os.Args[1:]
+	isSingBox := strings.HasPrefix(strings.ToLower(filepath.Base(os.Args[0])), "sing-box")
+	if !isSingBox && len(args) >= 2 && args[0] == "api" && args[1] == "adrules" {
+		runAdRules(args[2:], b)
+		return
+	}
+	isXray := !isSingBox && len(args) > 0 && args[0] == "run"
+	check := isSingBox && len(args) > 0 && args[0] == "check"
+	if isXray || (isSingBox && len(args) > 0 && (args[0] == "run" || check)) {
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("mockcore", flag.ExitOnError)
+	configPath := fs.String("c", "", "配置文件")
+	ping := fs.Bool("ping", false, "测速")
+	test := fs.Bool("test", false, "只检查配置")
+	server := fs.String("server", "", "服务器列表")
+	fs.String("key", "", "令牌")
+	fs.String("ip", "", "服务器IP")
+	fs.Parse(args)
+
+	if *ping {
+		runPing(*server, b)
+		return
+	}
+	if *test {
+		runTest(*configPath, b)
+		return
+	}
+	if check {
+		runCheck(*configPath, b)
+		return
+	}
+
+	if b.StartDelayMs > 0 {
+		time.Sleep(time.Duration(b.StartDelayMs) * time.Millisecond)
+	}
+	if b.FailStart {
+		logf("[Core] error: mock start failure")
+		os.Exit(exitCode(b))
+	}
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		logf("[Core] error: read config: %v", err)
+		os.Exit(1)
+	}
+	var cfg coreConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		logf("[Core] error: parse config: %v", err)
+		os.Exit(1)
+	}
+
+	p := &proxy{b: b, xray: isXray, singBox: isSingBox, server: "mock.example.com:443", strategy: "random", counters: newCounters()}
+	if len(cfg.Outbounds) > 0 {
+		s := cfg.Outbounds[0].Settings
+		if servers := strings.Split(s.Server, ";"); servers[0] != "" {
+			p.server = strings.TrimSpace(servers[0])
+		}
+		if s.Strategy != "" {
+			p.strategy = s.Strategy
+		}
+		p.rules = s.Rules != ""
+	}
+
+	for _, in := range cfg.Inbounds {
+		if in.Type == "tun" {
+			continue
+		}
+		addr := in.Listen
+		if in.Port > 0 {
+			addr = net.JoinHostPort(in.Listen, strconv.Itoa(in.Port))
+		} else if in.ListenPort > 0 {
+			addr = net.JoinHostPort(in.Listen, strconv.Itoa(in.ListenPort))
+			in.Protocol = in.Type
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			logf("[Core] error: listen %s: %v", addr, err)
+			os.Exit(1)
+		}
+		if isXray {
+			logf("[Info] proxy/%s: listening TCP on %s", in.Protocol, addr)
+		} else if isSingBox {
+			logf("INFO inbound/%s[%s]: tcp server started at %s", in.Protocol, in.Tag, addr)
+		} else {
+			logf("[Core] %s inbound listening on %s", in.Protocol, addr)
+		}
+		go p.serve(ln, in.Tag, in.Protocol)
+	}
+	if isXray && cfg.Metrics.Listen != "" {
+		ln, err := net.Listen("tcp", cfg.Metrics.Listen)
+		if err != nil {
+			logf("[Core] error: listen %s: %v", cfg.Metrics.Listen, err)
+			os.Exit(1)
+		}
+		go http.Serve(ln, p.counters)
+	}
+	if isXray && cfg.API.Listen != "" {
+		ln, err := net.Listen("tcp", cfg.API.Listen)
+		if err != nil {
+			logf("[Core] error: listen %s: %v", cfg.API.Listen, err)
+			os.Exit(1)
+		}
+		go http.Serve(ln, http.HandlerFunc(serveRulesAPI))
+	}
+	if controller := cfg.Experimental.ClashAPI.ExternalController; isSingBox && controller != "" {
+		ln, err := net.Listen("tcp", controller)
+		if err != nil {
+			logf("FATAL start service: listen %s: %v", controller, err)
+			os.Exit(1)
+		}
+		go http.Serve(ln, p.counters)
+	}
+
+	for _, line := range b.ExtraLines {
+		logf("%s", line)
+	}
+
+	if b.CrashAfterMs > 0 {
+		time.Sleep(time.Duration(b.CrashAfterMs) * time.Millisecond)
+		logf("[Core] error: mock crash")
+		os.Exit(exitCode(b))
+	}
+	select {}
+}
+
+func loadBehavior() behavior {
+	var b behavior
+	if data, err := os.ReadFile(BehaviorFile); err == nil {
+		json.Unmarshal(data, &b)
+	}
+	return b
+}
+
+func exitCode(b behavior) int {
+	if b.ExitCode != 0 {
+		return b.ExitCode
+	}
+	return 2
+}
+
+// runTest 按 xray run -test 的方式检查配置：通过时输出 Configuration OK.，否则输出错误并以 23 退出
+func runTest(configPath string, b behavior) {
+	fmt.Println("Xray 1.8.24 (mockcore)")
+	var cfg coreConfig
+	data, err := os.ReadFile(configPath)
+	if err == nil {
+		err = json.Unmarshal(data, &cfg)
+	}
+	if err == nil && b.ConfigError != "" {
+		err = fmt.Errorf("%s", b.ConfigError)
+	}
+	if err != nil {
+		fmt.Printf("Failed to start: main: failed to load config files: [%s] > %v\n", configPath, err)
+		os.Exit(23)
+	}
+	fmt.Println("Configuration OK.")
+}
+
+// runCheck 按 sing-box check 的方式检查配置：通过时无输出，否则输出 FATAL 错误并以 1 退出
+func runCheck(configPath string, b behavior) {
+	var cfg coreConfig
+	data, err := os.ReadFile(configPath)
+	if err == nil {
+		err = json.Unmarshal(data, &cfg)
+	}
+	if err == nil && b.ConfigError != "" {
+		err = fmt.Errorf("%s", b.ConfigError)
+	}
+	if err != nil {
+		fmt.Printf("FATAL[0000] decode config at %s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+}
+
+// runAdRules 按 xray api adrules --server=addr rules.json 的方式把规则文件提交给运行中的 mock xray
+func runAdRules(args []string, b behavior) {
+	if b.NoRulesAPI {
+		fmt.Println("xray: unknown command \"adrules\"")
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("adrules", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:8080", "API 地址")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Println("failed to add rules: no config file")
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("failed to read %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+	resp, err := http.Post("http://"+*server+"/adrules", "application/json", bytes.NewReader(data))
+	if err != nil {
+		fmt.Printf("failed to dial %s: %v\n", *server, err)
+		os.Exit(1)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Printf("failed to add rules: %s\n", strings.TrimSpace(string(body)))
+		os.Exit(1)
+	}
+}
+
+// serveRulesAPI 替换路由规则并输出规则数量
+func serveRulesAPI(w http.ResponseWriter, r *http.Request) {
+	var cfg struct {
+		Routing struct {
+			Rules []json.RawMessage `json:"rules"`
+		} `json:"routing"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logf("[Info] app/router: routing rules replaced via API (%d rules)", len(cfg.Routing.Rules))
+}
+
+// runPing 按真实内核格式输出: server | Delay: 42ms
+func runPing(servers string, b behavior) {
+	delay := b.PingDelayMs
+	if delay == 0 {
+		delay = 42
+	}
+	time.Sleep(time.Duration(b.PingSleepMs) * time.Millisecond)
+	for _, s := range strings.Split(servers, ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			fmt.Printf("%s | Delay: %dms\n", s, delay)
+		}
+	}
+}
+
+// =============================================================================
+// 代理
+// =============================================================================
+
+type proxy struct {
+	b        behavior
+	xray     bool
+	singBox  bool
+	server   string
+	strategy string
+	rules    bool
+	counters *counters
+}
+
+func (p *proxy) serve(ln net.Listener, tag, protocol string) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(conn, tag, protocol)
+	}
+}
+
+func (p *proxy) handle(conn net.Conn, tag, protocol string) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	var target string
+	var udp bool
+	var err error
+	if protocol == "http" {
+		target, err = httpHandshake(br, conn)
+	} else if head, perr := br.Peek(1); perr == nil && head[0] != 5 && protocol == "mixed" {
+		target, err = httpHandshake(br, conn)
+	} else {
+		target, udp, err = socksHandshake(br, conn)
+	}
+	if err != nil {
+		return
+	}
+	if udp {
+		p.udpAssociate(conn, br, tag)
+		return
+	}
+
+	remote, err := net.DialTimeout("tcp", target, 10*time.Second)
+	if err != nil {
+		logf("[Core] error: dial %s: %v", target, err)
+		return
+	}
+	defer remote.Close()
+
+	if p.singBox {
+		logf("INFO [%d 0ms] inbound/%s[%s]: inbound connection to %s", atomic.AddInt64(&connID, 1), protocol, tag, target)
+	} else if !p.xray {
+		p.logOpen(target, remote.RemoteAddr().String())
+	}
+	start := time.Now()
+
+	var up, down int64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		n, _ := io.Copy(remote, br)
+		atomic.AddInt64(&up, n)
+		if tc, ok := remote.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+	n, _ := io.Copy(conn, remote)
+	atomic.AddInt64(&down, n)
+	if tc, ok := conn.(*net.TCPConn); ok {
+		tc.CloseWrite()
+	}
+	wg.Wait()
+
+	if p.singBox {
+		p.counters.add("inbound", tag, atomic.LoadInt64(&up), atomic.LoadInt64(&down))
+		return
+	}
+	if p.xray {
+		p.counters.add("inbound", tag, atomic.LoadInt64(&up), atomic.LoadInt64(&down))
+		p.counters.add("outbound", "proxy_out", atomic.LoadInt64(&up), atomic.LoadInt64(&down))
+		logf("[Info] proxy: %s accepted tcp:%s [%s -> proxy_out]", conn.RemoteAddr(), target, tag)
+		return
+	}
+	logf("[Stats] %s | Up: %s | Down: %s | Time: %ds", target,
+		formatBytes(atomic.LoadInt64(&up)), formatBytes(atomic.LoadInt64(&down)), int(time.Since(start).Seconds()))
+}
+
+// connID sing-box 日志中的连接编号
+var connID int64
+
+// counters Xray 统计计数（/debug/vars 中的 stats 部分），sing-box 的 /connections 为入站计数之和
+type counters struct {
+	mu    sync.Mutex
+	stats map[string]map[string]map[string]int64 // inbound/outbound → 标签 → uplink/downlink
+}
+
+func newCounters() *counters {
+	return &counters{stats: map[string]map[string]map[string]int64{
+		"inbound":  {},
+		"outbound": {},
+	}}
+}
+
+func (c *counters) add(kind, tag string, up, down int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats[kind][tag] == nil {
+		c.stats[kind][tag] = map[string]int64{}
+	}
+	c.stats[kind][tag]["uplink"] += up
+	c.stats[kind][tag]["downlink"] += down
+}
+
+func (c *counters) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch r.URL.Path {
+	case "/debug/vars":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"stats": c.stats})
+	case "/connections":
+		var up, down int64
+		for _, tag := range c.stats["inbound"] {
+			up += tag["uplink"]
+			down += tag["downlink"]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"uploadTotal":   up,
+			"downloadTotal": down,
+			"connections":   []interface{}{},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// logOpen 输出选路与隧道日志
+func (p *proxy) logOpen(target, real string) {
+	if p.rules {
+		logf("[CLI] Rule Hit -> %s | SNI: %s (Rule: mock)", target, p.server)
+	} else {
+		logf("[CLI] LB -> %s | SNI: %s | Algo: %s", target, p.server, p.strategy)
+	}
+	latency := p.b.LatencyMs
+	if latency == 0 {
+		latency = 35
+	}
+	logf("[Core] Tunnel -> %s (ech) >>> %s (direct) Latency: %dms", p.server, real, latency)
+}
+
+// socksHandshake 无认证 SOCKS5 CONNECT / UDP ASSOCIATE，udp 为 true 时由调用方回复
+func socksHandshake(br *bufio.Reader, conn net.Conn) (string, bool, error) {
+	target, cmd, err := readSocksRequest(br, conn)
+	if err != nil {
+		return "", false, err
+	}
+	if cmd == 3 {
+		return target, true, nil
+	}
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	return target, false, nil
+}
+
+// udpAssociate 在回环地址上转发 UDP 数据包（直连目标），控制连接关闭时结束
+func (p *proxy) udpAssociate(conn net.Conn, br *bufio.Reader, tag string) {
+	relay, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer relay.Close()
+	out, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer out.Close()
+
+	addr := relay.LocalAddr().(*net.UDPAddr)
+	reply := append([]byte{5, 0, 0, 1}, addr.IP.To4()...)
+	conn.Write(binary.BigEndian.AppendUint16(reply, uint16(addr.Port)))
+
+	var mu sync.Mutex
+	var client net.Addr
+	// 目标的应答加上来源地址后发回客户端
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, from, err := out.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			to := client
+			mu.Unlock()
+			src := from.(*net.UDPAddr)
+			header := append([]byte{0, 0, 0, 1}, src.IP.To4()...)
+			header = binary.BigEndian.AppendUint16(header, uint16(src.Port))
+			if to != nil {
+				relay.WriteTo(append(header, buf[:n]...), to)
+			}
+		}
+	}()
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, from, err := relay.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			client = from
+			mu.Unlock()
+			target, payload, err := parseUDPPacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			dst, err := net.ResolveUDPAddr("udp", target)
+			if err != nil {
+				continue
+			}
+			if p.xray {
+				logf("[Info] proxy: %s accepted udp:%s [%s -> proxy_out]", from, target, tag)
+			}
+			out.WriteTo(payload, dst)
+		}
+	}()
+
+	// 控制连接关闭即结束关联
+	io.Copy(io.Discard, br)
+}
+
+// parseUDPPacket 解析 SOCKS5 UDP 数据包头
+func parseUDPPacket(packet []byte) (string, []byte, error) {
+	if len(packet) < 4 || packet[2] != 0 {
+		return "", nil, fmt.Errorf("invalid udp packet")
+	}
+	br := bufio.NewReader(bytes.NewReader(packet[4:]))
+	host, err := readSocksAddr(br, packet[3])
+	if err != nil {
+		return "", nil, err
+	}
+	rest, _ := io.ReadAll(br)
+	return host, rest, nil
+}
+
+// readSocksRequest 完成方法协商并读取请求，返回目标地址和命令
+func readSocksRequest(br *bufio.Reader, conn net.Conn) (string, byte, error) {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(br, head); err != nil || head[0] != 5 {
+		return "", 0, fmt.Errorf("not socks5")
+	}
+	if _, err := io.ReadFull(br, make([]byte, head[1])); err != nil {
+		return "", 0, err
+	}
+	conn.Write([]byte{5, 0})
+
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(br, req); err != nil || (req[1] != 1 && req[1] != 3) {
+		return "", 0, fmt.Errorf("unsupported command")
+	}
+	target, err := readSocksAddr(br, req[3])
+	return target, req[1], err
+}
+
+// readSocksAddr 读取地址类型之后的地址和端口
+func readSocksAddr(br *bufio.Reader, atyp byte) (string, error) {
+	var host string
+	switch atyp {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(br, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case 3:
+		l, err := br.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		name := make([]byte, l)
+		if _, err := io.ReadFull(br, name); err != nil {
+			return "", err
+		}
+		host = string(name)
+	case 4:
+		ip := make([]byte, 16)
+		if _, err := io.ReadFull(br, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	default:
+		return "", fmt.Errorf("unsupported address type")
+	}
+	portBuf := make([]byte, 2)
+	if _, err := io.ReadFull(br, portBuf); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBuf)))), nil
+}
+
+// httpHandshake 仅支持 CONNECT
+func httpHandshake(br *bufio.Reader, conn net.Conn) (string, error) {
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return "", err
+	}
+	if req.Method != http.MethodConnect {
+		conn.Write([]byte("HTTP/1.1 405 Method Not Allowed\r\n\r\n"))
+		return "", fmt.Errorf("unsupported method")
+	}
+	conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	return req.Host, nil
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,23 @@
+//go:build e2e
+// +build e2e
+
+// runner 运行全部端到端测试场景
+//
+//	go run -tags e2e ./internal/e2e/runner [-run 名称片段]
+package main
+
+import (
+	"flag"
+	"os"
+
+	"xlink-wails/internal/e2e"
+)
+
+func main() {
+	filter := flag.String("run", "", "只运行名称包含该片段的场景")
+	flag.Parse()
+
+	if failed := e2e.RunAll(os.Stdout, *filter); failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点链路（经前置节点的本地 SOCKS5 入站转发）
+// =============================================================================
+
+// StartChain 按链路顺序启动 nodeID：未运行的前置节点先启动，nodeID 最后启动（已运行时重启）
+// prepare 为每个节点生成配置，返回要启动的节点与配置文件路径
+func (m *Manager) StartChain(nodes []models.NodeConfig, nodeID string, prepare func(id string) (*models.NodeConfig, string, error)) error {
+	path, err := models.ChainPath(nodes, nodeID)
+	if err != nil {
+		return err
+	}
+
+	for i, id := range path {
+		last := i == len(path)-1
+		if !last && m.GetStatus(id) == models.StatusRunning {
+			continue
+		}
+		node, configPath, err := prepare(id)
+		if err != nil {
+			if !last {
+				return i18n.Errorf("启动前置节点失败: %w", err)
+			}
+			return err
+		}
+		if err := m.StartNode(node, configPath); err != nil {
+			if !last {
+				return i18n.Errorf("启动前置节点 %s 失败: %w", node.Name, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// StartOrder 按链路依赖排序节点（前置节点在前），用于批量启动；链路无效的节点放在 invalid 中
+func StartOrder(nodes []models.NodeConfig) (order []string, invalid map[string]error) {
+	added := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		path, err := models.ChainPath(nodes, n.ID)
+		if err != nil {
+			if invalid == nil {
+				invalid = make(map[string]error)
+			}
+			invalid[n.ID] = err
+			continue
+		}
+		for _, id := range path {
+			if !added[id] {
+				added[id] = true
+				order = append(order, id)
+			}
+		}
+	}
+	return order, invalid
+}
+
+// checkChainUpstream 启动前确认前置节点正在运行
+func (m *Manager) checkChainUpstream(node *models.NodeConfig) error {
+	if node.ChainNodeID == "" {
+		return nil
+	}
+	if m.GetStatus(node.ChainNodeID) != models.StatusRunning {
+		return i18n.Errorf("前置节点未运行，请先启动前置节点")
+	}
+	return nil
+}
+
+// stopChainDependents 停止经 nodeID 转发的下游节点（递归，visited 防止配置被改成循环时无限递归）
+func (m *Manager) stopChainDependents(nodeID string, visited map[string]bool) {
+	visited[nodeID] = true
+
+	m.mu.RLock()
+	var dependents []*EngineInstance
+	for id, inst := range m.instances {
+		if inst.node.ChainNodeID == nodeID && !visited[id] {
+			dependents = append(dependents, inst)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, inst := range dependents {
+		m.stopChainDependents(inst.NodeID, visited)
+		if inst.LogCallback != nil {
+			inst.LogCallback(logger.LevelWarn, logger.CategorySystem, "前置节点已停止，同时停止本节点")
+		}
+		m.cancelRestart(inst.NodeID)
+		m.mu.Lock()
+		m.stopInstanceLocked(inst.NodeID)
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,279 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/coreproto"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 活动连接表
+// =============================================================================
+
+// 内核日志中一条连接的生命周期依次为规则命中或负载均衡选路、隧道建立、连接结束统计
+// （格式见 coreproto），这里按日志顺序把它们关联成连接表。
+
+const (
+	// maxOpenConnections 单节点最多跟踪的未结束连接数（防止内核漏打 [Stats] 时无限增长）
+	maxOpenConnections = 1024
+	// maxClosedConnections 单节点保留的已结束连接数
+	maxClosedConnections = 100
+)
+
+// connTable 单个节点的连接表
+type connTable struct {
+	mu     sync.Mutex
+	nodeID string
+	nextID uint64
+	open   []*models.ConnectionInfo
+	closed []*models.ConnectionInfo
+
+	// localPorts 查询内核到远端地址的已建立连接的本地端口（不支持的平台为 nil）
+	localPorts func(remote string) []int
+}
+
+func newConnTable(nodeID string) *connTable {
+	return &connTable{nodeID: nodeID}
+}
+
+// observe 根据一行内核日志更新连接表
+func (t *connTable) observe(line string) {
+	switch coreproto.Classify(line) {
+	case coreproto.KindRuleHit, coreproto.KindLB:
+		if route, ok := coreproto.ParseRoute(line); ok {
+			t.onRouted(route)
+		}
+	case coreproto.KindTunnel:
+		if tunnel, ok := coreproto.ParseTunnel(line); ok && tunnel.Real != "" {
+			t.onTunnel(tunnel)
+		}
+	case coreproto.KindStats:
+		if stats, ok := coreproto.ParseStats(line); ok {
+			t.onStats(stats)
+		}
+	}
+}
+
+// onRouted 规则命中/负载均衡选路，新建一条连接
+func (t *connTable) onRouted(route coreproto.Route) {
+	conn := &models.ConnectionInfo{
+		NodeID:    t.nodeID,
+		Target:    route.Target,
+		Server:    route.Server,
+		Rule:      route.Rule,
+		Strategy:  route.Strategy,
+		LatencyMs: -1,
+		StartTime: time.Now(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	conn.ID = t.nextID
+	t.open = append(t.open, conn)
+	if len(t.open) > maxOpenConnections {
+		t.open = t.open[len(t.open)-maxOpenConnections:]
+	}
+}
+
+// onTunnel 隧道建立，补充到最早一条同服务器且尚未建立隧道的连接
+func (t *connTable) onTunnel(tunnel coreproto.Tunnel) {
+	t.mu.Lock()
+	var tunneled *models.ConnectionInfo
+	for _, conn := range t.open {
+		if conn.RealServer == "" && (conn.Server == tunnel.Server || conn.Server == "") {
+			conn.RealServer = tunnel.Real
+			conn.LatencyMs = tunnel.Latency
+			tunneled = conn
+			break
+		}
+	}
+	t.mu.Unlock()
+
+	if tunneled != nil && t.localPorts != nil {
+		t.assignLocalPort(tunneled, t.localPorts(tunnel.Real))
+	}
+}
+
+// assignLocalPort 从内核到同一远端地址的连接中找出这条隧道的本地端口
+// 已分配给其他连接的端口除外；系统按顺序分配临时端口，剩余多个时取最大的（最新建立的）
+func (t *connTable) assignLocalPort(conn *models.ConnectionInfo, ports []int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	taken := make(map[int]bool)
+	for _, other := range t.open {
+		if other != conn && other.RealServer == conn.RealServer && other.LocalPort > 0 {
+			taken[other.LocalPort] = true
+		}
+	}
+	for _, port := range ports {
+		if !taken[port] && port > conn.LocalPort {
+			conn.LocalPort = port
+		}
+	}
+}
+
+// onStats 连接结束，移到已结束列表
+func (t *connTable) onStats(stats coreproto.Stats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, conn := range t.open {
+		if conn.Target != stats.Target {
+			continue
+		}
+		conn.Upload = stats.Up
+		conn.Download = stats.Down
+		conn.Closed = true
+		conn.DurationMs = time.Since(conn.StartTime).Milliseconds()
+
+		t.open = append(t.open[:i], t.open[i+1:]...)
+		t.closed = append(t.closed, conn)
+		if len(t.closed) > maxClosedConnections {
+			t.closed = t.closed[len(t.closed)-maxClosedConnections:]
+		}
+		return
+	}
+}
+
+// snapshot 返回连接表副本（未结束的在前，按开始时间倒序）
+func (t *connTable) snapshot(includeClosed bool) []models.ConnectionInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	result := make([]models.ConnectionInfo, 0, len(t.open))
+	for _, conn := range t.open {
+		c := *conn
+		c.DurationMs = now.Sub(c.StartTime).Milliseconds()
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartTime.After(result[j].StartTime) })
+
+	if includeClosed {
+		for i := len(t.closed) - 1; i >= 0; i-- {
+			result = append(result, *t.closed[i])
+		}
+	}
+	return result
+}
+
+// remove 将已被强制关闭的连接移到已结束列表
+func (t *connTable) remove(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, conn := range t.open {
+		if conn.ID != id {
+			continue
+		}
+		conn.Closed = true
+		conn.DurationMs = time.Since(conn.StartTime).Milliseconds()
+
+		t.open = append(t.open[:i], t.open[i+1:]...)
+		t.closed = append(t.closed, conn)
+		if len(t.closed) > maxClosedConnections {
+			t.closed = t.closed[len(t.closed)-maxClosedConnections:]
+		}
+		return
+	}
+}
+
+// find 查找未结束的连接
+func (t *connTable) find(id uint64) *models.ConnectionInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, conn := range t.open {
+		if conn.ID == id {
+			c := *conn
+			return &c
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// 管理器接口
+// =============================================================================
+
+// GetConnections 获取节点的连接列表（节点ID为空时返回所有运行中节点）
+// 未结束的在前，同类按开始时间倒序
+func (m *Manager) GetConnections(nodeID string, includeClosed bool) []models.ConnectionInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []models.ConnectionInfo
+	for id, inst := range m.instances {
+		if nodeID != "" && id != nodeID {
+			continue
+		}
+		if inst.Connections != nil {
+			result = append(result, inst.Connections.snapshot(includeClosed)...)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Closed != b.Closed {
+			return !a.Closed
+		}
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.After(b.StartTime)
+		}
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		return a.ID > b.ID
+	})
+	return result
+}
+
+// CloseConnection 强制关闭内核与服务器之间的一条隧道连接
+// 内核没有控制接口，这里通过系统 TCP 表按隧道实际地址断开连接（需要管理员权限）
+func (m *Manager) CloseConnection(nodeID string, connID uint64) error {
+	m.mu.RLock()
+	inst, exists := m.instances[nodeID]
+	m.mu.RUnlock()
+	if !exists || inst.Connections == nil {
+		return fmt.Errorf("节点未运行: %s", nodeID)
+	}
+
+	conn := inst.Connections.find(connID)
+	if conn == nil {
+		return fmt.Errorf("连接不存在或已结束: %d", connID)
+	}
+	if _, _, err := net.SplitHostPort(conn.RealServer); err != nil {
+		return fmt.Errorf("内核未提供该连接的隧道地址，无法关闭")
+	}
+	if conn.LocalPort == 0 {
+		return fmt.Errorf("未能确定该连接的本地端口，无法关闭")
+	}
+
+	pid := inst.xlinkPID()
+	if pid == 0 {
+		return fmt.Errorf("节点未运行: %s", nodeID)
+	}
+
+	if err := closeTCPConnection(pid, conn.LocalPort, conn.RealServer); err != nil {
+		return err
+	}
+	inst.Connections.remove(connID)
+	return nil
+}
+
+// xlinkPID 内核进程的 PID，未运行时为 0
+func (inst *EngineInstance) xlinkPID() int {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	if inst.XlinkProcess == nil {
+		return 0
+	}
+	return inst.XlinkProcess.Pid
+}
@@ -6,7 +6,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +13,8 @@ import (
 	"sync"
 	"time"
 
+	"xlink-wails/internal/coreproto"
+	"xlink-wails/internal/logger"
 	"xlink-wails/internal/models"
 )
 
@@ -22,14 +23,9 @@ import (
 // =============================================================================
 
 const (
-	XlinkBinaryName = "xlink-cli-binary.exe"
-	XrayBinaryName  = "xray.exe"
-
-	// 进程启动超时
-	StartTimeout = 10 * time.Second
-
-	// 进程停止超时
-	StopTimeout = 2 * time.Second
+	XlinkBinaryName   = "xlink-cli-binary.exe"
+	XrayBinaryName    = "xray.exe"
+	SingBoxBinaryName = "sing-box.exe"
 )
 
 // =============================================================================
@@ -44,6 +40,8 @@ type ProcessInfo struct {
 	StdoutPipe io.ReadCloser
 	StderrPipe io.ReadCloser
 	Cancel     context.CancelFunc
+	Done       chan struct{} // waitProcess 回收进程后关闭
+	stopWait   time.Duration // 终止后等待进程退出的时间（连接策略的停止超时）
 }
 
 // EngineInstance 单个引擎实例
@@ -57,12 +55,36 @@ type EngineInstance struct {
 	// Xlink 核心进程
 	XlinkProcess *ProcessInfo
 
-	// Xray 前端进程（智能分流模式）
-	XrayProcess *ProcessInfo
+	// 前端进程（智能分流模式，Xray 或 sing-box）
+	FrontProcess *ProcessInfo
+	front        *frontEngine // 前端内核，非智能分流模式为 nil
 
 	// 内部端口（智能分流时Xlink监听的端口）
 	InternalPort int
 
+	// 限速转发（节点设置了带宽限制时）
+	relay *bandwidthRelay
+
+	// 活动连接表（由内核日志关联）
+	Connections *connTable
+
+	// UDP 会话统计（由前端内核日志统计）
+	UDP *udpStats
+
+	// 最后的输出（异常退出时写入崩溃报告）
+	output *outputTail
+
+	// 启动 / 停止超时与连接重试（全局策略叠加节点设置）
+	policy models.ConnectionPolicy
+
+	// 前端内核统计接口地址（为空时流量计数来自 Xlink 核心的 [Stats] 日志）
+	statsAddr string
+
+	// 自动重启所需的启动参数
+	node           models.NodeConfig
+	configPath     string
+	restartAttempt int // 本实例是第几次连续自动重启启动的（0 表示手动启动）
+
 	// 日志回调
 	LogCallback func(level, category, message string)
 
@@ -85,13 +107,36 @@ type Manager struct {
 
 	// 全局状态回调
 	globalStatusCallback func(nodeID, status string, err error)
+
+	// 内核异常退出回调（崩溃报告）
+	crashCallback func(report CrashReport)
+
+	// 内核统计计数回调
+	trafficCallback func(nodeID string, traffic models.CoreTraffic)
+
+	// 全局连接策略（节点可单独覆盖）
+	connPolicy models.ConnectionPolicy
+
+	// 自动重启
+	restartPolicy models.RestartPolicy
+	restartGen    map[string]uint64 // 手动启动/停止时递增，使等待中的自动重启失效
+	restarts      map[string]int    // 各节点自动重启的累计次数（运行指标）
+
+	// 内部端口分配
+	portMu    sync.Mutex
+	portRange models.PortRange
+	portNext  int // 下次从范围内第几个端口开始查找，避免连续分配到刚释放的同一端口
 }
 
 // NewManager 创建引擎管理器
 func NewManager(exeDir string) *Manager {
 	return &Manager{
-		exeDir:    exeDir,
-		instances: make(map[string]*EngineInstance),
+		exeDir:        exeDir,
+		instances:     make(map[string]*EngineInstance),
+		connPolicy:    models.ConnectionPolicy{}.Normalize(),
+		restartPolicy: models.RestartPolicy{}.Normalize(),
+		restartGen:    make(map[string]uint64),
+		restarts:      make(map[string]int),
 	}
 }
 
@@ -111,6 +156,17 @@ func (m *Manager) SetStatusCallback(cb func(nodeID, status string, err error)) {
 
 // StartNode 启动节点引擎
 func (m *Manager) StartNode(node *models.NodeConfig, configPath string) error {
+	m.cancelRestart(node.ID)
+	return m.startNode(node, configPath, 0)
+}
+
+// startNode 启动节点引擎，attempt 为连续自动重启的次数
+func (m *Manager) startNode(node *models.NodeConfig, configPath string, attempt int) error {
+	if err := m.checkChainUpstream(node); err != nil {
+		return err
+	}
+	policy := m.nodePolicy(node)
+
 	m.mu.Lock()
 
 	// 检查是否已运行
@@ -128,9 +184,17 @@ func (m *Manager) StartNode(node *models.NodeConfig, configPath string) error {
 
 	// 创建新实例
 	instance := &EngineInstance{
-		NodeID:   node.ID,
-		NodeName: node.Name,
-		Status:   models.StatusStarting,
+		NodeID:         node.ID,
+		NodeName:       node.Name,
+		Status:         models.StatusStarting,
+		Connections:    newConnTable(node.ID),
+		UDP:            &udpStats{},
+		output:         &outputTail{},
+		policy:         policy,
+		front:          frontEngineFor(node),
+		node:           *node,
+		configPath:     configPath,
+		restartAttempt: attempt,
 		LogCallback: func(level, category, message string) {
 			if m.globalLogCallback != nil {
 				m.globalLogCallback(node.ID, node.Name, level, category, message)
@@ -143,22 +207,35 @@ func (m *Manager) StartNode(node *models.NodeConfig, configPath string) error {
 		},
 	}
 
+	if instance.front != nil && node.StatsPort > 0 {
+		instance.statsAddr = fmt.Sprintf("127.0.0.1:%d", node.StatsPort)
+	}
+	// 记录每条隧道的本地端口，强制关闭时按端口断开指定的那条连接
+	instance.Connections.localPorts = func(remote string) []int {
+		return tcpLocalPorts(instance.xlinkPID(), remote)
+	}
+
 	m.instances[node.ID] = instance
 	m.mu.Unlock()
 
 	// 通知状态变更
 	instance.StatusCallback(models.StatusStarting, nil)
 
+	// 监听端口被占用时直接报告占用的进程，不启动内核
+	if err := m.checkListenPorts(instance, node); err != nil {
+		m.cleanupInstance(instance, err)
+		return err
+	}
+
 	// 启动Xlink核心
 	if err := m.startXlinkProcess(instance, node, configPath); err != nil {
 		m.cleanupInstance(instance, err)
 		return err
 	}
 
-	// 如果是智能分流模式，启动Xray
-	if node.RoutingMode == models.RoutingModeSmart {
-		xrayConfigPath := strings.Replace(configPath, "config_core_", "config_xray_", 1)
-		if err := m.startXrayProcess(instance, xrayConfigPath); err != nil {
+	// 如果是智能分流模式，启动前端内核
+	if instance.front != nil {
+		if err := m.startFrontProcess(instance, instance.front.configPath(configPath)); err != nil {
 			// 停止已启动的Xlink
 			m.stopXlinkProcess(instance)
 			m.cleanupInstance(instance, err)
@@ -166,12 +243,35 @@ func (m *Manager) StartNode(node *models.NodeConfig, configPath string) error {
 		}
 	}
 
+	// 等待前端进程开始监听，进程提前退出或超时视为启动失败
+	if err := m.waitReady(instance, node); err != nil {
+		if instance.stopped() {
+			return err
+		}
+		m.stopFrontProcess(instance)
+		m.stopXlinkProcess(instance)
+		m.cleanupInstance(instance, err)
+		return err
+	}
+
+	// 带宽限制：对外监听地址由限速转发占用
+	if err := m.startRelay(instance, node); err != nil {
+		m.stopFrontProcess(instance)
+		m.stopXlinkProcess(instance)
+		m.cleanupInstance(instance, err)
+		return err
+	}
+
 	// 更新状态为运行中
 	instance.mu.Lock()
 	instance.Status = models.StatusRunning
 	instance.mu.Unlock()
 	instance.StatusCallback(models.StatusRunning, nil)
 
+	if instance.statsAddr != "" {
+		go m.pollFrontStats(instance)
+	}
+
 	// ⚠️【修复】删除了 healthCheckLoop 调用
 	// Go 的 waitProcess (cmd.Wait) 机制已经足够稳定，
 	// 额外的轮询检查在 Windows 上会导致误判并杀死正常进程。
@@ -210,94 +310,102 @@ func (m *Manager) startXlinkProcess(inst *EngineInstance, node *models.NodeConfi
 
 	m.hideWindow(cmd)
 
-	stdout, err := cmd.StdoutPipe()
+	pipes, err := newOutputPipes(cmd)
 	if err != nil {
 		cancel()
-		return fmt.Errorf("创建stdout管道失败: %w", err)
+		return fmt.Errorf("创建输出管道失败: %w", err)
 	}
 
-	stderr, err := cmd.StderrPipe()
+	err = cmd.Start()
+	pipes.started()
 	if err != nil {
 		cancel()
-		return fmt.Errorf("创建stderr管道失败: %w", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		cancel()
+		pipes.close()
 		return fmt.Errorf("启动Xlink进程失败: %w", err)
 	}
+	if err := containProcess(cmd.Process); err != nil {
+		inst.LogCallback(logger.LevelWarn, logger.CategorySystem, fmt.Sprintf("界面异常退出时将无法自动结束Xlink核心: %v", err))
+	}
 
+	done := make(chan struct{})
 	inst.mu.Lock()
 	inst.XlinkProcess = &ProcessInfo{
 		Cmd:        cmd,
 		Pid:        cmd.Process.Pid,
 		StartTime:  time.Now(),
-		StdoutPipe: stdout,
-		StderrPipe: stderr,
+		StdoutPipe: pipes.stdout,
+		StderrPipe: pipes.stderr,
 		Cancel:     cancel,
+		Done:       done,
+		stopWait:   time.Duration(inst.policy.StopTimeout) * time.Second,
 	}
 	inst.mu.Unlock()
 
-	go m.readProcessOutput(inst, "xlink", stdout)
-	go m.readProcessOutput(inst, "xlink", stderr)
-	go m.waitProcess(inst, "xlink", cmd)
+	m.readOutput(inst, "xlink", pipes)
+	go m.waitProcess(inst, "xlink", cmd, done, pipes)
 
-	inst.LogCallback("info", "系统", fmt.Sprintf("Xlink核心已启动 (PID: %d)", cmd.Process.Pid))
+	inst.LogCallback(logger.LevelInfo, logger.CategorySystem, fmt.Sprintf("Xlink核心已启动 (PID: %d)", cmd.Process.Pid))
 
 	return nil
 }
 
-// startXrayProcess 启动Xray进程
-func (m *Manager) startXrayProcess(inst *EngineInstance, configPath string) error {
-	xrayPath := filepath.Join(m.exeDir, XrayBinaryName)
+// startFrontProcess 启动前端进程（Xray 或 sing-box）
+func (m *Manager) startFrontProcess(inst *EngineInstance, configPath string) error {
+	front := inst.front
+	frontPath := filepath.Join(m.exeDir, front.binary)
 
-	if _, err := os.Stat(xrayPath); os.IsNotExist(err) {
-		return fmt.Errorf("Xray文件不存在: %s", XrayBinaryName)
+	if _, err := os.Stat(frontPath); os.IsNotExist(err) {
+		return fmt.Errorf("%s文件不存在: %s", front.name, front.binary)
 	}
 
 	absConfigPath, _ := filepath.Abs(configPath)
-	args := []string{"run", "-c", absConfigPath}
+	if err := m.testFrontConfig(inst, frontPath, absConfigPath); err != nil {
+		return err
+	}
+	args := append(append([]string(nil), front.runArgs...), absConfigPath)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	cmd := exec.CommandContext(ctx, xrayPath, args...)
+	cmd := exec.CommandContext(ctx, frontPath, args...)
 	cmd.Dir = m.exeDir
 
 	m.hideWindow(cmd)
 
-	stdout, err := cmd.StdoutPipe()
+	pipes, err := newOutputPipes(cmd)
 	if err != nil {
 		cancel()
-		return fmt.Errorf("创建Xray stdout管道失败: %w", err)
+		return fmt.Errorf("创建%s 输出管道失败: %w", front.name, err)
 	}
 
-	stderr, err := cmd.StderrPipe()
+	err = cmd.Start()
+	pipes.started()
 	if err != nil {
 		cancel()
-		return fmt.Errorf("创建Xray stderr管道失败: %w", err)
+		pipes.close()
+		return fmt.Errorf("启动%s进程失败: %w", front.name, err)
 	}
-
-	if err := cmd.Start(); err != nil {
-		cancel()
-		return fmt.Errorf("启动Xray进程失败: %w", err)
+	if err := containProcess(cmd.Process); err != nil {
+		inst.LogCallback(logger.LevelWarn, logger.CategorySystem, fmt.Sprintf("界面异常退出时将无法自动结束%s: %v", front.name, err))
 	}
 
+	done := make(chan struct{})
 	inst.mu.Lock()
-	inst.XrayProcess = &ProcessInfo{
+	inst.FrontProcess = &ProcessInfo{
 		Cmd:        cmd,
 		Pid:        cmd.Process.Pid,
 		StartTime:  time.Now(),
-		StdoutPipe: stdout,
-		StderrPipe: stderr,
+		StdoutPipe: pipes.stdout,
+		StderrPipe: pipes.stderr,
 		Cancel:     cancel,
+		Done:       done,
+		stopWait:   time.Duration(inst.policy.StopTimeout) * time.Second,
 	}
 	inst.mu.Unlock()
 
-	go m.readProcessOutput(inst, "xray", stdout)
-	go m.readProcessOutput(inst, "xray", stderr)
-	go m.waitProcess(inst, "xray", cmd)
+	m.readOutput(inst, front.source, pipes)
+	go m.waitProcess(inst, front.source, cmd, done, pipes)
 
-	inst.LogCallback("info", "系统", fmt.Sprintf("Xray前端已启动 (PID: %d)", cmd.Process.Pid))
+	inst.LogCallback(logger.LevelInfo, logger.CategorySystem, fmt.Sprintf("%s前端已启动 (PID: %d)", front.name, cmd.Process.Pid))
 
 	return nil
 }
@@ -306,8 +414,11 @@ func (m *Manager) startXrayProcess(inst *EngineInstance, configPath string) erro
 // 停止引擎
 // =============================================================================
 
-// StopNode 停止节点引擎
+// StopNode 停止节点引擎（经该节点转发的下游节点先停止）
 func (m *Manager) StopNode(nodeID string) error {
+	m.stopChainDependents(nodeID, make(map[string]bool))
+	m.cancelRestart(nodeID)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -326,10 +437,10 @@ func (m *Manager) stopInstanceLocked(nodeID string) error {
 	// 先标记状态，防止 UI 闪烁
 	inst.Status = models.StatusStopped
 	
-	// 停止 Xray
-	if inst.XrayProcess != nil {
-		m.terminateProcess(inst.XrayProcess)
-		inst.XrayProcess = nil
+	// 停止前端进程
+	if inst.FrontProcess != nil {
+		m.terminateProcess(inst.FrontProcess)
+		inst.FrontProcess = nil
 	}
 
 	// 停止 Xlink
@@ -337,6 +448,10 @@ func (m *Manager) stopInstanceLocked(nodeID string) error {
 		m.terminateProcess(inst.XlinkProcess)
 		inst.XlinkProcess = nil
 	}
+
+	// 停止限速转发
+	inst.relay.Close()
+	inst.relay = nil
 	
 	inst.mu.Unlock()
 
@@ -346,7 +461,7 @@ func (m *Manager) stopInstanceLocked(nodeID string) error {
 	}
 
 	if inst.LogCallback != nil {
-		go inst.LogCallback("info", "系统", "节点已停止")
+		go inst.LogCallback(logger.LevelInfo, logger.CategorySystem, "节点已停止")
 	}
 
 	// 从 map 中移除
@@ -361,6 +476,7 @@ func (m *Manager) StopAll() {
 	defer m.mu.Unlock()
 
 	for nodeID := range m.instances {
+		m.restartGen[nodeID]++
 		m.stopInstanceLocked(nodeID)
 	}
 }
@@ -386,14 +502,83 @@ func (m *Manager) terminateProcess(proc *ProcessInfo) {
 		proc.Cmd.Process.Kill()
 	}
 	
-	// 4. 释放资源
-	proc.Cmd.Wait()
+	// 4. 等待 waitProcess 回收进程（cmd.Wait 只能由一处调用，并发调用会永久阻塞）
+	if proc.Done != nil {
+		select {
+		case <-proc.Done:
+		case <-time.After(proc.stopWait):
+		}
+	}
 }
 
 // =============================================================================
 // 日志读取
 // =============================================================================
 
+// outputPipes 进程的 stdout / stderr 管道
+// 不使用 cmd.StdoutPipe：它在 Wait 返回时关闭读取端，进程退出前最后写出的内容（如崩溃信息）可能来不及读取；
+// 独立管道在进程退出后仍可读完，读到 EOF 为止
+type outputPipes struct {
+	stdout, stderr   *os.File // 读取端
+	stdoutW, stderrW *os.File // 写入端，进程启动后父进程关闭
+	wg               sync.WaitGroup
+}
+
+// outputDrainTimeout 进程退出后等待读完剩余输出的时间
+const outputDrainTimeout = 2 * time.Second
+
+func newOutputPipes(cmd *exec.Cmd) (*outputPipes, error) {
+	p := &outputPipes{}
+	var err error
+	if p.stdout, p.stdoutW, err = os.Pipe(); err != nil {
+		return nil, err
+	}
+	if p.stderr, p.stderrW, err = os.Pipe(); err != nil {
+		p.stdout.Close()
+		p.stdoutW.Close()
+		return nil, err
+	}
+	cmd.Stdout = p.stdoutW
+	cmd.Stderr = p.stderrW
+	return p, nil
+}
+
+// started 进程已启动（或启动失败），关闭父进程持有的写入端
+func (p *outputPipes) started() {
+	p.stdoutW.Close()
+	p.stderrW.Close()
+}
+
+// close 关闭读取端
+func (p *outputPipes) close() {
+	p.stdout.Close()
+	p.stderr.Close()
+}
+
+// drain 等待输出读完（超时返回，如子进程仍持有管道）
+func (p *outputPipes) drain(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// readOutput 开始读取进程的 stdout 和 stderr
+func (m *Manager) readOutput(inst *EngineInstance, source string, pipes *outputPipes) {
+	pipes.wg.Add(2)
+	for _, r := range []io.Reader{pipes.stdout, pipes.stderr} {
+		go func(r io.Reader) {
+			defer pipes.wg.Done()
+			m.readProcessOutput(inst, source, r)
+		}(r)
+	}
+}
+
 // readProcessOutput 读取进程输出
 func (m *Manager) readProcessOutput(inst *EngineInstance, source string, reader io.Reader) {
 	scanner := bufio.NewScanner(reader)
@@ -411,89 +596,77 @@ func (m *Manager) readProcessOutput(inst *EngineInstance, source string, reader
 
 // parseAndForwardLog 解析并转发日志
 func (m *Manager) parseAndForwardLog(inst *EngineInstance, source, line string) {
+	inst.output.add(source, line)
+	if inst.Connections != nil && !isFrontSource(source) {
+		inst.Connections.observe(line)
+	}
+	if inst.UDP != nil && isFrontSource(source) {
+		inst.UDP.observe(line)
+	}
+
 	if inst.LogCallback == nil {
 		return
 	}
 
-	level := "info"
-	category := "内核"
+	level := logger.LevelInfo
+	category := logger.CategoryEngine
 	message := line
 
 	// 简单解析日志级别
 	lowerLine := strings.ToLower(line)
 	if strings.Contains(lowerLine, "error") || strings.Contains(lowerLine, "[err]") {
-		level = "error"
+		level = logger.LevelError
 	} else if strings.Contains(lowerLine, "warn") || strings.Contains(lowerLine, "[warn]") {
-		level = "warn"
-	}
-
-	// 简单分类
-	if strings.Contains(line, "Tunnel ->") {
-		category = "隧道"
-		message = m.parseTunnelLog(line)
-	} else if strings.Contains(line, "Rule Hit") {
-		category = "规则"
-		message = m.parseRuleHitLog(line)
-	} else if strings.Contains(line, "LB ->") {
-		category = "负载"
-		message = m.parseLBLog(line)
-	} else if strings.Contains(line, "[Stats]") {
-		category = "统计"
-		message = m.parseStatsLog(line)
-	} else if source == "xray" {
-		category = "Xray"
+		level = logger.LevelWarn
+	}
+
+	// 按内核输出格式分类，消息从日志标记开始
+	switch coreproto.Classify(line) {
+	case coreproto.KindTunnel:
+		category = logger.CategoryTunnel
+	case coreproto.KindRuleHit:
+		category = logger.CategoryRule
+	case coreproto.KindLB:
+		category = logger.CategoryLB
+	case coreproto.KindStats:
+		category = logger.CategoryStats
+	default:
+		if inst.front != nil && source == inst.front.source {
+			category = inst.front.category
+		}
 	}
 
-	message = strings.TrimPrefix(message, "[CLI] ")
-	message = strings.TrimPrefix(message, "[Core] ")
+	message = coreproto.StripPrefix(coreproto.FromMarker(message))
 
 	inst.LogCallback(level, category, message)
 }
 
-// parseTunnelLog 解析隧道日志
-func (m *Manager) parseTunnelLog(line string) string {
-	if idx := strings.Index(line, "Tunnel ->"); idx != -1 {
-		return line[idx:]
-	}
-	return line
-}
-
-func (m *Manager) parseRuleHitLog(line string) string {
-	if idx := strings.Index(line, "Rule Hit"); idx != -1 {
-		return line[idx:]
-	}
-	return line
-}
-
-func (m *Manager) parseLBLog(line string) string {
-	if idx := strings.Index(line, "LB ->"); idx != -1 {
-		return line[idx:]
-	}
-	return line
-}
-
-func (m *Manager) parseStatsLog(line string) string {
-	if idx := strings.Index(line, "[Stats]"); idx != -1 {
-		return line[idx:]
-	}
-	return line
-}
-
 // =============================================================================
 // 进程监控 (被动等待)
 // =============================================================================
 
 // waitProcess 等待进程退出
 // 这是最标准的进程守护方式，当进程因任何原因退出时，Wait 会返回
-func (m *Manager) waitProcess(inst *EngineInstance, source string, cmd *exec.Cmd) {
+// done 在进程回收后立即关闭（此时 terminateProcess 可能正持有 inst.mu 等待）
+func (m *Manager) waitProcess(inst *EngineInstance, source string, cmd *exec.Cmd, done chan struct{}, pipes *outputPipes) {
 	err := cmd.Wait()
+	close(done)
 
 	inst.mu.Lock()
 	status := inst.Status
+	var startTime time.Time
+	current := false
+	for _, proc := range []*ProcessInfo{inst.XlinkProcess, inst.FrontProcess} {
+		if proc != nil && proc.Cmd == cmd {
+			startTime = proc.StartTime
+			current = true
+		}
+	}
 	inst.mu.Unlock()
 
 	// 如果状态是 Running，说明是异常退出（不是用户点的停止）
-	if status == models.StatusRunning {
+	// 热重载时被替换的前端进程已不是实例当前的进程，不算异常退出
+	if status == models.StatusRunning && current {
 		errMsg := fmt.Sprintf("%s 进程意外退出", source)
 		if err != nil {
 			errMsg += fmt.Sprintf(": %v", err)
@@ -501,15 +674,24 @@ func (m *Manager) waitProcess(inst *EngineInstance, source string, cmd *exec.Cmd
 
 		inst.mu.Lock()
 		inst.Status = models.StatusError
+		inst.relay.Close() // 释放对外监听地址，便于重新启动
+		inst.relay = nil
 		inst.mu.Unlock()
 
+		// 先读完退出前的输出，再写入崩溃报告
+		pipes.drain(outputDrainTimeout)
 		if inst.LogCallback != nil {
-			inst.LogCallback("error", "系统", errMsg)
+			inst.LogCallback(logger.LevelError, logger.CategorySystem, errMsg)
 		}
+		m.reportCrash(inst, source, cmd, err, startTime)
 		if inst.StatusCallback != nil {
 			inst.StatusCallback(models.StatusError, fmt.Errorf(errMsg))
 		}
+		m.scheduleRestart(inst, fmt.Errorf(errMsg))
 	}
+
+	pipes.drain(outputDrainTimeout)
+	pipes.close()
 }
 
 // ⚠️【修复】已移除 healthCheckLoop 函数
@@ -553,24 +735,19 @@ func (m *Manager) PingTest(node *models.NodeConfig, callback func(result models.
 	
 	if err := cmd.Start(); err != nil { return err }
 
+	// Wait 会关闭管道，必须先读完输出
+	scanned := make(chan struct{})
 	go func() {
+		defer close(scanned)
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.Contains(line, "Delay:") {
-				parts := strings.Split(line, "|")
-				if len(parts) >= 2 {
-					server := strings.TrimSpace(parts[0])
-					delayStr := strings.TrimPrefix(strings.TrimSpace(parts[1]), "Delay:")
-					delayStr = strings.TrimSuffix(strings.TrimSpace(delayStr), "ms")
-					var delay int
-					fmt.Sscanf(delayStr, "%d", &delay)
-					callback(models.PingResult{Server: server, Latency: delay})
-				}
+			if result, ok := coreproto.ParsePing(scanner.Text()); ok {
+				callback(result)
 			}
 		}
 	}()
 
+	<-scanned
 	err = cmd.Wait()
 	if cmd.Process != nil {
 		m.killProcessTree(cmd.Process.Pid)
@@ -613,15 +790,6 @@ func (m *Manager) GetAllStatuses() map[string]models.EngineStatus {
 	return statuses
 }
 
-func (m *Manager) FindFreePort() int {
-	ln, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		return 0
-	}
-	defer ln.Close()
-	return ln.Addr().(*net.TCPAddr).Port
-}
-
 func (m *Manager) GetExeDir() string {
 	return m.exeDir
 }
@@ -635,4 +803,14 @@ func (m *Manager) stopXlinkProcess(inst *EngineInstance) {
 	}
 }
 
+// stopFrontProcess 停止前端进程（未启动时忽略）
+func (m *Manager) stopFrontProcess(inst *EngineInstance) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.FrontProcess != nil {
+		m.terminateProcess(inst.FrontProcess)
+		inst.FrontProcess = nil
+	}
+}
+
 
@@ -4,7 +4,14 @@
 package engine
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
@@ -21,3 +28,82 @@ func (m *Manager) killProcessTree(pid int) error {
 	// 发送SIGKILL到进程组
 	return syscall.Kill(-pid, syscall.SIGKILL)
 }
+
+// containProcess 非Windows平台内核已在独立的进程组中，由 killProcessTree 结束
+func containProcess(p *os.Process) error {
+	return nil
+}
+
+// findOrphanProcesses 通过 /proc 列出由 dir 中的 names 启动、已被 init 收养（父进程已退出）的进程
+// 没有 /proc 的平台返回空列表
+func findOrphanProcesses(dir string, names []string) ([]OrphanProcess, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, nil
+	}
+
+	var orphans []OrphanProcess
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		path, err := os.Readlink(filepath.Join("/proc", e.Name(), "exe"))
+		if err != nil || filepath.Dir(path) != dir {
+			continue
+		}
+		name := filepath.Base(path)
+		matched := false
+		for _, n := range names {
+			matched = matched || name == n
+		}
+		if !matched {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join("/proc", e.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// pid (comm) state ppid ...，comm 中可能含空格和括号
+		fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		if _, err := os.Stat(filepath.Join("/proc", strconv.Itoa(ppid))); ppid > 1 && err == nil {
+			continue
+		}
+		orphans = append(orphans, OrphanProcess{PID: pid, Name: name, Path: path})
+	}
+	return orphans, nil
+}
+
+// closeTCPConnection 非Windows平台不支持按连接断开
+func closeTCPConnection(pid, localPort int, remote string) error {
+	return fmt.Errorf("仅支持Windows")
+}
+
+// tcpLocalPorts 非Windows平台不读取系统 TCP 表
+func tcpLocalPorts(pid int, remote string) []int {
+	return nil
+}
+
+// listenerOwner 通过 lsof 查找在端口上监听的进程，lsof 不可用或找不到时 pid 为 0
+func listenerOwner(port int) (int, string) {
+	output, err := exec.Command("lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output()
+	if err != nil {
+		return 0, ""
+	}
+	pid, name := 0, ""
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "p") && pid == 0:
+			pid, _ = strconv.Atoi(line[1:])
+		case strings.HasPrefix(line, "c") && name == "":
+			name = line[1:]
+		}
+	}
+	return pid, name
+}
@@ -5,8 +5,17 @@ package engine
 
 import (
 	"fmt"   // <--- 必须加上这一行
+	"net"
 	"os/exec"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
 // hideWindow 隐藏Windows控制台窗口
@@ -24,3 +33,302 @@ func (m *Manager) killProcessTree(pid int) error {
 	kill.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 	return kill.Run()
 }
+
+// =============================================================================
+// 作业对象
+// =============================================================================
+
+// 界面被任务管理器结束或崩溃时来不及停止内核，遗留的 xlink / xray 进程继续占用端口，
+// 再次启动时节点无法监听。内核启动后放入设置了 KILL_ON_JOB_CLOSE 的作业对象，
+// 作业句柄只由本进程持有，本进程以任何方式退出时系统关闭句柄并结束作业中的全部内核。
+
+var (
+	jobOnce   sync.Once
+	jobHandle windows.Handle
+	jobErr    error
+)
+
+// processJob 创建（仅一次）本进程退出时结束全部内核的作业对象
+func processJob() (windows.Handle, error) {
+	jobOnce.Do(func() {
+		job, err := windows.CreateJobObject(nil, nil)
+		if err != nil {
+			jobErr = fmt.Errorf("创建作业对象失败: %w", err)
+			return
+		}
+		info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+			BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+				LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+			},
+		}
+		if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+			uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+			windows.CloseHandle(job)
+			jobErr = fmt.Errorf("设置作业对象失败: %w", err)
+			return
+		}
+		jobHandle = job
+	})
+	return jobHandle, jobErr
+}
+
+// containProcess 将内核进程放入作业对象，本进程退出时随之结束
+func containProcess(p *os.Process) error {
+	job, err := processJob()
+	if err != nil {
+		return err
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(p.Pid))
+	if err != nil {
+		return fmt.Errorf("打开进程失败: %w", err)
+	}
+	defer windows.CloseHandle(h)
+	if err := windows.AssignProcessToJobObject(job, h); err != nil {
+		return fmt.Errorf("加入作业对象失败: %w", err)
+	}
+	return nil
+}
+
+var (
+	modiphlpapi             = syscall.NewLazyDLL("iphlpapi.dll")
+	procGetExtendedTcpTable = modiphlpapi.NewProc("GetExtendedTcpTable")
+	procSetTcpEntry         = modiphlpapi.NewProc("SetTcpEntry")
+)
+
+const (
+	afINET                   = 2
+	afINET6                  = 23
+	tcpTableOwnerPIDListener = 3
+	tcpTableOwnerPIDAll      = 5
+	mibTCPStateEstablished   = 5
+	mibTCPStateDeleteTCB     = 12
+
+	// SetTcpEntry 在非管理员权限下返回的错误码
+	errorAccessDenied  = 5
+	errorMRMidNotFound = 317
+)
+
+// mibTCPRowOwnerPID 对应 MIB_TCPROW_OWNER_PID
+type mibTCPRowOwnerPID struct {
+	State      uint32
+	LocalAddr  uint32
+	LocalPort  uint32
+	RemoteAddr uint32
+	RemotePort uint32
+	OwningPID  uint32
+}
+
+// mibTCP6RowOwnerPID 对应 MIB_TCP6ROW_OWNER_PID
+type mibTCP6RowOwnerPID struct {
+	LocalAddr     [16]byte
+	LocalScopeID  uint32
+	LocalPort     uint32
+	RemoteAddr    [16]byte
+	RemoteScopeID uint32
+	RemotePort    uint32
+	State         uint32
+	OwningPID     uint32
+}
+
+// tcpTable 读取系统 TCP 表，返回行数和紧随其后的行数据
+func tcpTable(family, class uintptr) (uint32, []byte, error) {
+	var size uint32
+	procGetExtendedTcpTable.Call(0, uintptr(unsafe.Pointer(&size)), 0, family, class, 0)
+	if size == 0 {
+		return 0, nil, fmt.Errorf("获取TCP连接表失败")
+	}
+	buf := make([]byte, size)
+	r, _, _ := procGetExtendedTcpTable.Call(
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)), 0, family, class, 0,
+	)
+	if r != 0 {
+		return 0, nil, fmt.Errorf("获取TCP连接表失败: %d", r)
+	}
+	return *(*uint32)(unsafe.Pointer(&buf[0])), buf[4:], nil
+}
+
+// listenerOwner 查找在端口上监听的进程（IPv4 与 IPv6），找不到时 pid 为 0
+func listenerOwner(port int) (int, string) {
+	wantPort := uint32(port>>8&0xff) | uint32(port&0xff)<<8
+
+	pid := 0
+	if count, rows, err := tcpTable(afINET, tcpTableOwnerPIDListener); err == nil {
+		rowSize := unsafe.Sizeof(mibTCPRowOwnerPID{})
+		for i := uint32(0); i < count && pid == 0; i++ {
+			row := (*mibTCPRowOwnerPID)(unsafe.Pointer(&rows[uintptr(i)*rowSize]))
+			if row.LocalPort&0xffff == wantPort {
+				pid = int(row.OwningPID)
+			}
+		}
+	}
+	if pid == 0 {
+		if count, rows, err := tcpTable(afINET6, tcpTableOwnerPIDListener); err == nil {
+			rowSize := unsafe.Sizeof(mibTCP6RowOwnerPID{})
+			for i := uint32(0); i < count && pid == 0; i++ {
+				row := (*mibTCP6RowOwnerPID)(unsafe.Pointer(&rows[uintptr(i)*rowSize]))
+				if row.LocalPort&0xffff == wantPort {
+					pid = int(row.OwningPID)
+				}
+			}
+		}
+	}
+	if pid == 0 {
+		return 0, ""
+	}
+	return pid, processName(uint32(pid))
+}
+
+// processName 从进程快照中查找进程名（无需打开进程，系统进程同样可用）
+func processName(pid uint32) string {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if entry.ProcessID == pid {
+			return windows.UTF16ToString(entry.ExeFile[:])
+		}
+	}
+	return ""
+}
+
+// findOrphanProcesses 列出由 dir 中的 names 启动、父进程已经退出的进程
+// Windows 不会为孤儿进程更换父进程，父进程 PID 不存在或已被更晚创建的进程复用时视为已退出
+func findOrphanProcesses(dir string, names []string) ([]OrphanProcess, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("读取进程列表失败: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var orphans []OrphanProcess
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		name := windows.UTF16ToString(entry.ExeFile[:])
+		if !matchName(name, names) {
+			continue
+		}
+		path, created, ok := processImage(entry.ProcessID)
+		if !ok || !strings.EqualFold(filepath.Dir(path), dir) {
+			continue
+		}
+		if _, parentCreated, ok := processImage(entry.ParentProcessID); ok && parentCreated <= created {
+			continue
+		}
+		orphans = append(orphans, OrphanProcess{PID: int(entry.ProcessID), Name: name, Path: path})
+	}
+	return orphans, nil
+}
+
+// processImage 进程的文件路径和创建时间，进程不存在或无权访问时 ok 为 false
+func processImage(pid uint32) (string, int64, bool) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", 0, false
+	}
+	defer windows.CloseHandle(h)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if windows.QueryFullProcessImageName(h, 0, &buf[0], &size) != nil {
+		return "", 0, false
+	}
+	var created, exited, kernel, user windows.Filetime
+	if windows.GetProcessTimes(h, &created, &exited, &kernel, &user) != nil {
+		return "", 0, false
+	}
+	return windows.UTF16ToString(buf[:size]), created.Nanoseconds(), true
+}
+
+func matchName(name string, names []string) bool {
+	for _, n := range names {
+		if strings.EqualFold(name, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// establishedTCPRows 指定进程到远端地址的已建立 TCP 连接（仅 IPv4）
+func establishedTCPRows(pid int, remote string) ([]mibTCPRowOwnerPID, error) {
+	host, portStr, err := net.SplitHostPort(remote)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return nil, fmt.Errorf("仅支持关闭IPv4连接: %s", remote)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("端口无效: %s", portStr)
+	}
+
+	// 表中地址与端口均为网络字节序
+	wantAddr := *(*uint32)(unsafe.Pointer(&ip[0]))
+	wantPort := uint32(port>>8&0xff) | uint32(port&0xff)<<8
+
+	count, rows, err := tcpTable(afINET, tcpTableOwnerPIDAll)
+	if err != nil {
+		return nil, err
+	}
+	var matched []mibTCPRowOwnerPID
+	rowSize := unsafe.Sizeof(mibTCPRowOwnerPID{})
+	for i := uint32(0); i < count; i++ {
+		row := (*mibTCPRowOwnerPID)(unsafe.Pointer(&rows[uintptr(i)*rowSize]))
+		if int(row.OwningPID) == pid && row.State == mibTCPStateEstablished &&
+			row.RemoteAddr == wantAddr && row.RemotePort&0xffff == wantPort {
+			matched = append(matched, *row)
+		}
+	}
+	return matched, nil
+}
+
+// rowLocalPort 行中网络字节序的本地端口
+func rowLocalPort(row *mibTCPRowOwnerPID) int {
+	return int(row.LocalPort>>8&0xff | row.LocalPort&0xff<<8)
+}
+
+// tcpLocalPorts 指定进程到远端地址的已建立 TCP 连接的本地端口
+func tcpLocalPorts(pid int, remote string) []int {
+	rows, err := establishedTCPRows(pid, remote)
+	if err != nil {
+		return nil
+	}
+	ports := make([]int, 0, len(rows))
+	for i := range rows {
+		ports = append(ports, rowLocalPort(&rows[i]))
+	}
+	return ports
+}
+
+// closeTCPConnection 断开指定进程从本地端口到远端地址的 TCP 连接（仅 IPv4）
+func closeTCPConnection(pid, localPort int, remote string) error {
+	rows, err := establishedTCPRows(pid, remote)
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		if rowLocalPort(&rows[i]) != localPort {
+			continue
+		}
+
+		// SetTcpEntry 接收 MIB_TCPROW，即去掉 OwningPID 的前五个字段
+		entry := rows[i]
+		entry.State = mibTCPStateDeleteTCB
+		if r, _, _ := procSetTcpEntry.Call(uintptr(unsafe.Pointer(&entry))); r != 0 {
+			if r == errorMRMidNotFound || r == errorAccessDenied {
+				return fmt.Errorf("关闭连接需要管理员权限")
+			}
+			return fmt.Errorf("关闭连接失败: %d", r)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("未找到本地端口 %d 到 %s 的连接", localPort, remote)
+}
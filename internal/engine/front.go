@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"strings"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 智能分流前端内核
+// =============================================================================
+
+// 智能分流模式下由前端内核（Xray 或 sing-box，按节点选择）提供本地入站并分流，
+// 需要代理的连接交给 Xlink 核心。两者的启动参数、配置检查命令和日志格式不同，其余流程一致。
+
+// frontEngine 前端内核的启动方式
+type frontEngine struct {
+	source       string // 进程标识（日志来源、崩溃报告）
+	name         string // 显示名称
+	binary       string // 程序文件名
+	configPrefix string // 配置文件名前缀（替换核心配置的 config_core_）
+	category     string // 日志类别
+	runArgs      []string
+	checkArgs    []string // 配置检查命令（配置路径追加在后）
+}
+
+var (
+	frontXray = &frontEngine{
+		source:       "xray",
+		name:         "Xray",
+		binary:       XrayBinaryName,
+		configPrefix: "config_xray_",
+		category:     logger.CategoryXray,
+		runArgs:      []string{"run", "-c"},
+		checkArgs:    []string{"run", "-test", "-c"},
+	}
+	frontSingBox = &frontEngine{
+		source:       "sing-box",
+		name:         "sing-box",
+		binary:       SingBoxBinaryName,
+		configPrefix: "config_singbox_",
+		category:     logger.CategorySingBox,
+		runArgs:      []string{"run", "-c"},
+		checkArgs:    []string{"check", "-c"},
+	}
+)
+
+// frontEngineFor 节点使用的前端内核，非智能分流模式返回 nil
+func frontEngineFor(node *models.NodeConfig) *frontEngine {
+	if node.RoutingMode != models.RoutingModeSmart {
+		return nil
+	}
+	if node.FrontEngine == models.FrontEngineSingBox {
+		return frontSingBox
+	}
+	return frontXray
+}
+
+// configPath 前端配置路径（与核心配置位于同一目录）
+func (f *frontEngine) configPath(corePath string) string {
+	return strings.Replace(corePath, "config_core_", f.configPrefix, 1)
+}
+
+// isFrontSource 日志来源是否为前端内核
+func isFrontSource(source string) bool {
+	return source == frontXray.source || source == frontSingBox.source
+}
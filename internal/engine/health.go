@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 服务器池健康状态
+// =============================================================================
+
+// 内核按 hash / rr / random 策略在服务器池中选择服务器，不会跳过已经失效的服务器，
+// 池中有服务器宕机时部分连接会静默失败。后台定期探测运行中节点池内的每个服务器，
+// 连续多次失败的标记为不可用，生成配置时从池中暂时移除；恢复后再放回。
+// 状态只保存在内存中，节点停止后清除。
+
+const (
+	// DefaultHealthInterval 默认探测间隔
+	DefaultHealthInterval = time.Minute
+	// MinHealthInterval 最短探测间隔
+	MinHealthInterval = 10 * time.Second
+	// HealthFailThreshold 连续失败多少次标记为不可用
+	HealthFailThreshold = 2
+	// HealthRecoverThreshold 不可用的服务器连续成功多少次恢复（避免反复切换导致频繁重启）
+	HealthRecoverThreshold = 2
+)
+
+// ServerHealthStatus 服务器的健康状态
+type ServerHealthStatus struct {
+	Server    string    `json:"server"`
+	Up        bool      `json:"up"`
+	LatencyMs int       `json:"latency_ms"` // 最近一次探测的连接时间，失败时为 -1
+	LastError string    `json:"last_error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	DownSince time.Time `json:"down_since,omitempty"` // 标记为不可用的时间
+
+	failures  int // 连续失败次数
+	successes int // 不可用期间的连续成功次数
+}
+
+// ServerHealthChange 服务器状态变化
+type ServerHealthChange struct {
+	NodeID string `json:"node_id"`
+	Server string `json:"server"`
+	Up     bool   `json:"up"`
+	Error  string `json:"error,omitempty"` // 不可用时最近一次探测的错误
+}
+
+// ServerHealth 各节点服务器池的健康状态
+type ServerHealth struct {
+	mu    sync.Mutex
+	nodes map[string][]*ServerHealthStatus // 节点 → 按池中顺序的状态
+}
+
+// NewServerHealth 创建健康状态记录
+func NewServerHealth() *ServerHealth {
+	return &ServerHealth{nodes: make(map[string][]*ServerHealthStatus)}
+}
+
+// Update 记录节点一轮探测的结果（按池中顺序），返回状态发生变化的服务器
+// 结果中没有的服务器（已从池中删除）不再记录
+func (h *ServerHealth) Update(nodeID string, results []models.PingResult) []ServerHealthChange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := make(map[string]*ServerHealthStatus, len(h.nodes[nodeID]))
+	for _, s := range h.nodes[nodeID] {
+		prev[s.Server] = s
+	}
+
+	now := time.Now()
+	var changes []ServerHealthChange
+	statuses := make([]*ServerHealthStatus, 0, len(results))
+	for _, r := range results {
+		s := prev[r.Server]
+		if s == nil {
+			s = &ServerHealthStatus{Server: r.Server, Up: true}
+		}
+		s.CheckedAt = now
+		s.LatencyMs = r.Latency
+
+		if r.Latency >= 0 {
+			s.LastError = ""
+			s.failures = 0
+			if !s.Up {
+				s.successes++
+				if s.successes >= HealthRecoverThreshold {
+					s.Up, s.successes, s.DownSince = true, 0, time.Time{}
+					changes = append(changes, ServerHealthChange{NodeID: nodeID, Server: s.Server, Up: true})
+				}
+			}
+		} else {
+			s.LastError = r.Error
+			s.successes = 0
+			s.failures++
+			if s.Up && s.failures >= HealthFailThreshold {
+				s.Up, s.DownSince = false, now
+				changes = append(changes, ServerHealthChange{NodeID: nodeID, Server: s.Server, Error: r.Error})
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	h.nodes[nodeID] = statuses
+	return changes
+}
+
+// Status 节点服务器的健康状态（按池中顺序）
+func (h *ServerHealth) Status(nodeID string) []ServerHealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make([]ServerHealthStatus, 0, len(h.nodes[nodeID]))
+	for _, s := range h.nodes[nodeID] {
+		result = append(result, *s)
+	}
+	return result
+}
+
+// Forget 清除节点的状态（节点停止时调用）
+func (h *ServerHealth) Forget(nodeID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.nodes, nodeID)
+}
+
+// NodeIDs 有健康状态记录的节点
+func (h *ServerHealth) NodeIDs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ids := make([]string, 0, len(h.nodes))
+	for id := range h.nodes {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// HealthyPool 从服务器池中移除不可用的服务器，返回 ";" 分隔的列表
+// 全部不可用时返回完整的池（保留让内核自行重试的机会，而不是生成空的服务器列表）
+func (h *ServerHealth) HealthyPool(nodeID, pool string) string {
+	h.mu.Lock()
+	down := make(map[string]bool)
+	for _, s := range h.nodes[nodeID] {
+		if !s.Up {
+			down[s.Server] = true
+		}
+	}
+	h.mu.Unlock()
+
+	entries := models.SplitServers(pool)
+	healthy := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !down[e] {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = entries
+	}
+	return strings.Join(healthy, ";")
+}
@@ -0,0 +1,319 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点带宽限制
+// =============================================================================
+
+// 内核没有限速功能，设置了带宽限制的节点由前端进程（Xlink 或智能分流时的 Xray）监听内部地址，
+// 对外的监听地址改由本地转发占用，转发时按令牌桶限速。同一节点的全部连接共享上传和下载两个令牌桶。
+// 转发只处理 TCP，SOCKS5 UDP 关联的数据包直接发往内核，不受限制。
+
+const (
+	// relayBufferSize 单次转发的最大字节数
+	relayBufferSize = 32 << 10
+	// relayDialTimeout 单次连接内部地址的超时
+	relayDialTimeout = 5 * time.Second
+)
+
+// tokenBucket 令牌桶，令牌数可以暂时为负（预支），之后的请求按欠额等待
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // 字节/秒
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 按 KB/s 创建令牌桶，允许突发一秒的流量；kbps 为 0 时不限速
+func newTokenBucket(kbps int) *tokenBucket {
+	b := &tokenBucket{}
+	b.setRate(kbps)
+	return b
+}
+
+// setRate 修改速率，正在转发的连接随即按新速率限速（清除之前的欠额）；kbps 为 0 时不限速
+func (b *tokenBucket) setRate(kbps int) {
+	if kbps < 0 {
+		kbps = 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rate = float64(kbps) * 1024
+	b.burst = b.rate
+	b.tokens = b.rate
+	b.last = time.Now()
+}
+
+// reserve 取出 n 个令牌，返回需要等待的时间
+func (b *tokenBucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// chunk 单次转发的字节数，限速较低时减小以免一次预支过多
+func (b *tokenBucket) chunk() int {
+	if b == nil {
+		return relayBufferSize
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.burst == 0 || int(b.burst) >= relayBufferSize {
+		return relayBufferSize
+	}
+	if n := int(b.burst / 4); n > 512 {
+		return n
+	}
+	return 512
+}
+
+// bandwidthRelay 节点的限速转发
+type bandwidthRelay struct {
+	up   *tokenBucket // 客户端 → 内核
+	down *tokenBucket // 内核 → 客户端
+
+	// allow 客户端地址过滤（局域网共享时内核只能看到转发的回环地址，由转发检查客户端白名单）
+	allow func(net.IP) bool
+
+	// policy 连接内部地址失败时的重试次数与间隔
+	policy models.ConnectionPolicy
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// startBandwidthRelay 按 routes（对外监听地址 → 内部地址）开始转发，任一地址监听失败时全部关闭
+func startBandwidthRelay(routes map[string]string, uploadKB, downloadKB int, allow func(net.IP) bool, policy models.ConnectionPolicy) (*bandwidthRelay, error) {
+	r := &bandwidthRelay{
+		up:     newTokenBucket(uploadKB),
+		down:   newTokenBucket(downloadKB),
+		allow:  allow,
+		policy: policy,
+		conns:  make(map[net.Conn]struct{}),
+	}
+	for listen, backend := range routes {
+		ln, err := net.Listen("tcp", listen)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("限速转发监听 %s 失败: %w", listen, err)
+		}
+		r.listeners = append(r.listeners, ln)
+		go r.serve(ln, backend)
+	}
+	return r, nil
+}
+
+func (r *bandwidthRelay) serve(ln net.Listener, backend string) {
+	for {
+		client, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(client, backend)
+	}
+}
+
+func (r *bandwidthRelay) handle(client net.Conn, backend string) {
+	if r.allow != nil {
+		if addr, ok := client.RemoteAddr().(*net.TCPAddr); !ok || !r.allow(addr.IP) {
+			client.Close()
+			return
+		}
+	}
+	upstream, err := r.dialBackend(backend)
+	if err != nil {
+		client.Close()
+		return
+	}
+	if !r.track(client, upstream) {
+		client.Close()
+		upstream.Close()
+		return
+	}
+	defer r.untrack(client, upstream)
+
+	done := make(chan struct{})
+	go func() {
+		limitedCopy(upstream, client, r.up)
+		closeWrite(upstream)
+		close(done)
+	}()
+	limitedCopy(client, upstream, r.down)
+	closeWrite(client)
+	<-done
+}
+
+// dialBackend 连接内部地址，失败时按连接策略的次数与间隔重试（内核重启期间短暂不可连接）
+func (r *bandwidthRelay) dialBackend(addr string) (net.Conn, error) {
+	for attempt := 1; ; attempt++ {
+		conn, err := net.DialTimeout("tcp", addr, relayDialTimeout)
+		if err == nil || attempt > r.policy.DialRetries {
+			return conn, err
+		}
+		time.Sleep(r.policy.RetryDelay(attempt))
+	}
+}
+
+// track 登记连接以便关闭转发时一并断开，转发已关闭时返回 false
+func (r *bandwidthRelay) track(conns ...net.Conn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	for _, c := range conns {
+		r.conns[c] = struct{}{}
+	}
+	return true
+}
+
+func (r *bandwidthRelay) untrack(conns ...net.Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+		delete(r.conns, c)
+	}
+}
+
+// setLimits 修改上传和下载限速（KB/s，0 表示不限）
+func (r *bandwidthRelay) setLimits(uploadKB, downloadKB int) {
+	r.up.setRate(uploadKB)
+	r.down.setRate(downloadKB)
+}
+
+// Close 停止监听并断开全部连接（可重复调用）
+func (r *bandwidthRelay) Close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	for _, ln := range r.listeners {
+		ln.Close()
+	}
+	for c := range r.conns {
+		c.Close()
+	}
+}
+
+// limitedCopy 按令牌桶限速复制，bucket 为 nil 时不限速
+func limitedCopy(dst io.Writer, src io.Reader, bucket *tokenBucket) {
+	buf := make([]byte, bucket.chunk())
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if bucket != nil {
+				if wait := bucket.reserve(n); wait > 0 {
+					time.Sleep(wait)
+				}
+			}
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// closeWrite 一个方向结束后半关闭连接，让对端收到 EOF
+func closeWrite(c net.Conn) {
+	if tc, ok := c.(*net.TCPConn); ok {
+		tc.CloseWrite()
+		return
+	}
+	c.Close()
+}
+
+// startRelay 节点设置了带宽限制时开始限速转发
+func (m *Manager) startRelay(inst *EngineInstance, node *models.NodeConfig) error {
+	if len(node.BandwidthRelays) == 0 {
+		return nil
+	}
+	var allow func(net.IP) bool
+	if node.LANShare.Enabled {
+		clients, err := models.LANShareClients(&node.LANShare)
+		if err != nil {
+			return err
+		}
+		allow = func(ip net.IP) bool { return models.LANShareAllows(clients, ip) }
+	}
+	relay, err := startBandwidthRelay(node.BandwidthRelays, node.UploadLimit, node.DownloadLimit, allow, inst.policy)
+	if err != nil {
+		return err
+	}
+
+	inst.mu.Lock()
+	inst.relay = relay
+	inst.mu.Unlock()
+
+	inst.LogCallback(logger.LevelInfo, logger.CategorySystem,
+		fmt.Sprintf("带宽限制已生效 (上传 %s / 下载 %s)", formatLimit(node.UploadLimit), formatLimit(node.DownloadLimit)))
+	return nil
+}
+
+// SetBandwidthLimit 修改运行中节点的带宽限制（KB/s，0 表示不限），立即对现有连接生效
+// 节点未运行或启动时没有设置限制（未经限速转发）时返回 false，需重新启动节点才能生效
+func (m *Manager) SetBandwidthLimit(nodeID string, uploadKB, downloadKB int) bool {
+	m.mu.RLock()
+	inst, ok := m.instances[nodeID]
+	m.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	inst.mu.Lock()
+	relay := inst.relay
+	inst.mu.Unlock()
+	if relay == nil {
+		return false
+	}
+	relay.setLimits(uploadKB, downloadKB)
+	inst.LogCallback(logger.LevelInfo, logger.CategorySystem,
+		fmt.Sprintf("带宽限制已更新 (上传 %s / 下载 %s)", formatLimit(uploadKB), formatLimit(downloadKB)))
+	return true
+}
+
+// formatLimit 限速的显示文本
+func formatLimit(kbps int) string {
+	if kbps <= 0 {
+		return "不限"
+	}
+	return fmt.Sprintf("%d KB/s", kbps)
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// =============================================================================
+// 上次运行遗留的内核进程
+// =============================================================================
+
+// 界面崩溃或被强制结束后（作业对象不可用，或由旧版本启动），内核进程可能继续运行并占用端口，
+// 再次启动节点时只能看到端口被占用。启动时查找由程序目录中的内核文件启动、且父进程已经退出的进程并结束。
+// 只匹配程序目录下的内核文件，其他位置运行的 xray / sing-box 以及本程序其他实例启动的内核不受影响。
+
+// coreBinaries 程序启动的内核文件
+var coreBinaries = []string{XlinkBinaryName, XrayBinaryName, SingBoxBinaryName}
+
+// OrphanProcess 上次运行遗留的内核进程
+type OrphanProcess struct {
+	PID  int    `json:"pid"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// CleanupOrphans 结束上次运行遗留的内核进程，返回已结束的进程
+func (m *Manager) CleanupOrphans() ([]OrphanProcess, error) {
+	dir, err := filepath.Abs(m.exeDir)
+	if err != nil {
+		return nil, err
+	}
+	orphans, err := findOrphanProcesses(dir, coreBinaries)
+	if err != nil {
+		return nil, err
+	}
+
+	own := make(map[int]bool)
+	m.mu.RLock()
+	for _, inst := range m.instances {
+		inst.mu.RLock()
+		for _, p := range []*ProcessInfo{inst.XlinkProcess, inst.FrontProcess} {
+			if p != nil {
+				own[p.Pid] = true
+			}
+		}
+		inst.mu.RUnlock()
+	}
+	m.mu.RUnlock()
+
+	var killed []OrphanProcess
+	var firstErr error
+	for _, o := range orphans {
+		if own[o.PID] {
+			continue
+		}
+		proc, err := os.FindProcess(o.PID)
+		if err == nil {
+			err = proc.Kill()
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("结束 %s (PID %d) 失败: %w", o.Name, o.PID, err)
+			}
+			continue
+		}
+		killed = append(killed, o)
+	}
+	return killed, firstErr
+}
+
+// RemoveStaleTempFiles 删除上次运行中断时遗留的临时文件，返回删除的文件数
+func (m *Manager) RemoveStaleTempFiles() int {
+	matches, _ := filepath.Glob(filepath.Join(m.exeDir, xrayRulesTempPattern))
+	removed := 0
+	for _, path := range matches {
+		if os.Remove(path) == nil {
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 启动前检查端口占用
+// =============================================================================
+
+// 监听端口被占用时内核启动后立即退出，节点只显示含义不明的退出码。启动进程前先逐个尝试监听
+// 节点要使用的地址，无法监听时查出占用端口的进程（Windows: GetExtendedTcpTable，其他平台: lsof），
+// 对外监听地址同时给出附近可用的端口供用户改用。
+
+// suggestPortRange 在被占用端口之后多少个端口内寻找可用端口
+const suggestPortRange = 100
+
+// PortConflict 无法监听的地址
+type PortConflict struct {
+	Addr      string `json:"addr"`
+	Internal  bool   `json:"internal"`             // 自动分配的内部端口，重新启动节点时会换用其他端口
+	OwnerNode string `json:"owner_node,omitempty"` // 占用端口的节点名称
+	PID       int    `json:"pid,omitempty"`        // 占用端口的进程
+	Process   string `json:"process,omitempty"`
+	Error     string `json:"error"`               // 监听失败的原因
+	Suggested int    `json:"suggested,omitempty"` // 建议改用的端口（仅对外监听地址）
+}
+
+// Err 转换为返回给用户的错误
+func (c *PortConflict) Err() error {
+	switch {
+	case c.Internal && c.OwnerNode != "":
+		return i18n.Errorf("内部端口 %s 已被节点 %s 占用，请重新启动节点", c.Addr, c.OwnerNode)
+	case c.Internal && c.PID > 0:
+		return i18n.Errorf("内部端口 %s 已被进程 %s (PID %d) 占用，请重新启动节点", c.Addr, c.Process, c.PID)
+	case c.Internal:
+		return i18n.Errorf("内部端口 %s 无法使用（%s），请重新启动节点", c.Addr, c.Error)
+	case c.OwnerNode != "":
+		return i18n.Errorf("监听地址 %s 已被节点 %s 占用，可改用端口 %d", c.Addr, c.OwnerNode, c.Suggested)
+	case c.PID > 0:
+		return i18n.Errorf("监听地址 %s 已被进程 %s (PID %d) 占用，可改用端口 %d", c.Addr, c.Process, c.PID, c.Suggested)
+	}
+	return i18n.Errorf("监听地址 %s 无法使用（%s），可改用端口 %d", c.Addr, c.Error, c.Suggested)
+}
+
+// SetPortRange 设置内部端口范围（对之后分配的端口生效），Min 与 Max 都为 0 时由系统分配
+func (m *Manager) SetPortRange(r models.PortRange) {
+	m.portMu.Lock()
+	defer m.portMu.Unlock()
+	m.portRange = r
+	m.portNext = 0
+}
+
+// FindFreePort 分配一个 127.0.0.1 上可用的内部端口；设置了内部端口范围时依次轮换查找，范围内没有可用端口时返回 0
+func (m *Manager) FindFreePort() int {
+	m.portMu.Lock()
+	defer m.portMu.Unlock()
+
+	r := m.portRange
+	if r.Min <= 0 || r.Max < r.Min {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return 0
+		}
+		defer ln.Close()
+		return ln.Addr().(*net.TCPAddr).Port
+	}
+
+	size := r.Max - r.Min + 1
+	for i := 0; i < size; i++ {
+		offset := (m.portNext + i) % size
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(r.Min+offset)))
+		if err != nil {
+			continue
+		}
+		ln.Close()
+		m.portNext = (offset + 1) % size
+		return r.Min + offset
+	}
+	return 0
+}
+
+// CheckPorts 检查节点要监听的地址是否可用，返回无法监听的地址（不含 nodeID 自身正在运行的实例占用的端口）
+func (m *Manager) CheckPorts(node *models.NodeConfig) []PortConflict {
+	var conflicts []PortConflict
+	for _, p := range nodeListenAddrs(node) {
+		ln, err := net.Listen("tcp", p.addr)
+		if err == nil {
+			ln.Close()
+			continue
+		}
+
+		c := PortConflict{Addr: p.addr, Internal: p.internal, Error: err.Error()}
+		_, port, _ := splitPort(p.addr)
+		if name, ok := m.portOwnerNode(port, node.ID); ok {
+			c.OwnerNode = name
+		} else if pid, process := listenerOwner(port); pid > 0 {
+			c.PID, c.Process = pid, process
+		}
+		if !p.internal {
+			c.Suggested = m.suggestPort(p.addr)
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts
+}
+
+// checkListenPorts 启动进程前检查端口，有冲突时记录全部冲突并返回第一个
+func (m *Manager) checkListenPorts(inst *EngineInstance, node *models.NodeConfig) error {
+	conflicts := m.CheckPorts(node)
+	if len(conflicts) == 0 {
+		return nil
+	}
+	for i := range conflicts {
+		inst.LogCallback(logger.LevelError, logger.CategorySystem, conflicts[i].Err().Error())
+	}
+	return conflicts[0].Err()
+}
+
+type listenAddr struct {
+	addr     string
+	internal bool
+}
+
+// nodeListenAddrs 节点启动后监听的全部 TCP 地址
+func nodeListenAddrs(node *models.NodeConfig) []listenAddr {
+	var addrs []listenAddr
+	seen := make(map[string]bool)
+	add := func(addr string, internal bool) {
+		if addr == "" || seen[addr] {
+			return
+		}
+		seen[addr] = true
+		addrs = append(addrs, listenAddr{addr, internal})
+	}
+
+	for _, listen := range []string{node.Listen, node.HTTPListen} {
+		if listen == "" {
+			continue
+		}
+		add(listen, false)
+	}
+	for _, in := range node.LANInbounds {
+		add(in.Listen, false)
+	}
+	for _, internal := range node.BandwidthRelays {
+		add(internal, true)
+	}
+	if node.RoutingMode == models.RoutingModeSmart {
+		// 用户设置的固定内部端口按对外监听地址处理（给出可改用的端口）
+		for _, port := range []int{node.InternalPort, node.StatsPort, node.APIPort} {
+			if port > 0 {
+				add(net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), port != node.FixedInternalPort)
+			}
+		}
+	}
+	return addrs
+}
+
+// portOwnerNode 查找配置中使用该端口的其他运行中节点
+func (m *Manager) portOwnerNode(port int, excludeID string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for id, inst := range m.instances {
+		if id == excludeID {
+			continue
+		}
+		inst.mu.RLock()
+		node := inst.node
+		inst.mu.RUnlock()
+		for _, p := range nodeListenAddrs(&node) {
+			if _, pp, err := splitPort(p.addr); err == nil && pp == port {
+				return node.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// suggestPort 在同一主机上寻找被占用端口之后的第一个可用端口，找不到时由系统分配
+func (m *Manager) suggestPort(addr string) int {
+	host, port, err := splitPort(addr)
+	if err != nil {
+		return m.FindFreePort()
+	}
+	for p := port + 1; p <= port+suggestPortRange && p <= 65535; p++ {
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(p)))
+		if err != nil {
+			continue
+		}
+		ln.Close()
+		if _, used := m.portOwnerNode(p, ""); !used {
+			return p
+		}
+	}
+	return m.FindFreePort()
+}
+
+func splitPort(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("端口无效: %s", portStr)
+	}
+	return host, port, nil
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+)
+
+// =============================================================================
+// 启动前检查前端配置
+// =============================================================================
+
+// 启动前端内核前先检查生成的配置（Xray: `xray run -test -c <配置>`，sing-box: `sing-box check -c <配置>`）：
+// 解析或语义错误在启动前直接报告给用户，而不是进程启动后立即退出、节点以含义不明的退出码进入错误状态。
+
+// frontTestTimeout 配置检查的最长时间（超时不阻止启动）
+const frontTestTimeout = 10 * time.Second
+
+// testFrontConfig 检查前端配置，配置有误时返回内核给出的错误信息
+// 检查本身无法执行（超时、无法启动进程）时只记录警告，仍继续启动
+func (m *Manager) testFrontConfig(inst *EngineInstance, frontPath, configPath string) error {
+	front := inst.front
+	ctx, cancel := context.WithTimeout(context.Background(), frontTestTimeout)
+	defer cancel()
+
+	args := append(append([]string(nil), front.checkArgs...), configPath)
+	cmd := exec.CommandContext(ctx, frontPath, args...)
+	cmd.Dir = m.exeDir
+	m.hideWindow(cmd)
+
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if ctx.Err() != nil || !errors.As(err, &exitErr) {
+		inst.LogCallback(logger.LevelWarn, logger.CategorySystem, fmt.Sprintf("%s 配置检查未能完成，跳过: %v", front.name, err))
+		return nil
+	}
+
+	msg := frontTestError(string(output))
+	if msg == "" {
+		err = i18n.Errorf("%s 配置检查未通过，退出码 %d", front.name, exitErr.ExitCode())
+	} else {
+		err = i18n.Errorf("%s 配置检查未通过: %s", front.name, msg)
+	}
+	inst.LogCallback(logger.LevelError, logger.CategorySystem, err.Error())
+	return err
+}
+
+// frontTestError 从检查输出中取出错误说明：优先取 Xray 的 "Failed to start" 所在行，
+// 否则取最后一行非空输出（去掉 sing-box 的 "FATAL[0000]" 前缀，没有输出时为空）
+func frontTestError(output string) string {
+	last := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.Contains(line, "Failed to start") {
+			return strings.TrimSpace(strings.TrimPrefix(line[strings.Index(line, "Failed to start"):], "Failed to start:"))
+		}
+		if strings.HasPrefix(line, "FATAL[") {
+			if i := strings.Index(line, "]"); i > 0 {
+				line = strings.TrimSpace(line[i+1:])
+			}
+		}
+		last = line
+	}
+	return last
+}
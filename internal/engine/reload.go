@@ -0,0 +1,286 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 热重载
+// =============================================================================
+
+// 修改规则后重新启动节点会断开所有连接。热重载先按运行中实例的内部端口重新生成配置，
+// 再与实例启动时的配置对比，按变化的范围选择影响最小的方式：
+//   - 配置没有变化：不做任何操作；
+//   - 只有 Xray 的路由规则变化：经 Xray API 替换运行中的规则（xray api adrules，不带 -append 时整体替换），连接不中断；
+//   - 只有前端内核的配置变化（DNS、入站、sing-box 规则等）：只重启前端内核，Xlink 核心和已建立的隧道保留；
+//   - Xlink 核心配置、限速转发或前端内核类型变化：完全重启。
+// Xray 版本过旧不支持 adrules 时改为重启前端内核。
+
+// 热重载方式
+const (
+	ReloadNone    = "none"    // 配置没有变化
+	ReloadRules   = "rules"   // 经 Xray API 替换路由规则
+	ReloadFront   = "front"   // 只重启前端内核
+	ReloadRestart = "restart" // 完全重启
+)
+
+// xrayAPITimeout 调用 xray api 的超时
+const xrayAPITimeout = 10 * time.Second
+
+// xrayRulesTempPattern 提交给 xray api adrules 的临时规则文件
+const xrayRulesTempPattern = "xray_rules_*.json"
+
+// ReloadResult 热重载的结果
+type ReloadResult struct {
+	NodeID string `json:"node_id"`
+	Method string `json:"method"`
+	Reason string `json:"reason,omitempty"` // 未能以更轻的方式应用的原因
+}
+
+// ConfigSnapshot 运行中实例启动时使用的节点设置与生成的配置
+type ConfigSnapshot struct {
+	node  models.NodeConfig
+	xlink []byte
+	front []byte
+}
+
+// Node 实例启动时的节点设置（含内部端口，重新生成配置时沿用）
+func (s *ConfigSnapshot) Node() *models.NodeConfig {
+	node := s.node
+	return &node
+}
+
+// SnapshotConfig 读取运行中节点当前使用的配置，节点未运行时返回错误
+func (m *Manager) SnapshotConfig(nodeID string) (*ConfigSnapshot, error) {
+	inst := m.runningInstance(nodeID)
+	if inst == nil {
+		return nil, i18n.Errorf("节点未运行")
+	}
+
+	inst.mu.RLock()
+	snap := &ConfigSnapshot{node: inst.node}
+	configPath, front := inst.configPath, inst.front
+	inst.mu.RUnlock()
+
+	var err error
+	if snap.xlink, err = os.ReadFile(configPath); err != nil {
+		return nil, fmt.Errorf("读取运行中的配置失败: %w", err)
+	}
+	if front != nil {
+		snap.front, _ = os.ReadFile(front.configPath(configPath))
+	}
+	return snap, nil
+}
+
+// ReloadNode 将重新生成的配置应用到运行中的节点，before 为生成前的 SnapshotConfig
+func (m *Manager) ReloadNode(node *models.NodeConfig, configPath string, before *ConfigSnapshot) (*ReloadResult, error) {
+	inst := m.runningInstance(node.ID)
+	if inst == nil {
+		return nil, i18n.Errorf("节点未运行")
+	}
+
+	xlink, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取生成的配置失败: %w", err)
+	}
+	front := frontEngineFor(node)
+	var frontCfg []byte
+	if front != nil {
+		frontCfg, _ = os.ReadFile(front.configPath(configPath))
+	}
+
+	restart := func(reason string) (*ReloadResult, error) {
+		inst.LogCallback(logger.LevelInfo, logger.CategorySystem, fmt.Sprintf("%s，重新启动节点", reason))
+		if err := m.StartNode(node, configPath); err != nil {
+			return nil, err
+		}
+		return &ReloadResult{NodeID: node.ID, Method: ReloadRestart, Reason: reason}, nil
+	}
+
+	switch {
+	case front != inst.front:
+		return restart("前端内核类型变化")
+	case !xlinkConfigEqual(node, before.xlink, xlink):
+		return restart("Xlink 核心配置变化")
+	case !reflect.DeepEqual(node.BandwidthRelays, before.node.BandwidthRelays):
+		return restart("限速转发变化")
+	case bytes.Equal(frontCfg, before.front):
+		m.updateInstanceConfig(inst, node, configPath)
+		return &ReloadResult{NodeID: node.ID, Method: ReloadNone}, nil
+	}
+
+	reason := "前端内核配置变化"
+	if front == frontXray && node.APIPort > 0 && routingOnlyChanged(before.front, frontCfg) {
+		err := m.reloadXrayRules(node.APIPort, frontCfg)
+		if err == nil {
+			m.updateInstanceConfig(inst, node, configPath)
+			inst.LogCallback(logger.LevelInfo, logger.CategorySystem, "已通过 Xray API 更新路由规则，现有连接未中断")
+			return &ReloadResult{NodeID: node.ID, Method: ReloadRules}, nil
+		}
+		reason = fmt.Sprintf("经 Xray API 更新路由规则失败 (%v)", err)
+		inst.LogCallback(logger.LevelWarn, logger.CategorySystem, reason+"，改为重启 Xray")
+	}
+
+	if err := m.restartFront(inst, node, configPath); err != nil {
+		return nil, err
+	}
+	return &ReloadResult{NodeID: node.ID, Method: ReloadFront, Reason: reason}, nil
+}
+
+// runningInstance 运行中的实例，未运行时返回 nil
+func (m *Manager) runningInstance(nodeID string) *EngineInstance {
+	m.mu.RLock()
+	inst, ok := m.instances[nodeID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	if inst.Status != models.StatusRunning {
+		return nil
+	}
+	return inst
+}
+
+// updateInstanceConfig 记录实例当前使用的节点设置与配置
+func (m *Manager) updateInstanceConfig(inst *EngineInstance, node *models.NodeConfig, configPath string) {
+	inst.mu.Lock()
+	inst.node = *node
+	inst.configPath = configPath
+	inst.mu.Unlock()
+}
+
+// restartFront 只重启前端内核，Xlink 核心与限速转发保持运行；失败时停止整个节点
+func (m *Manager) restartFront(inst *EngineInstance, node *models.NodeConfig, configPath string) error {
+	m.stopFrontProcess(inst)
+	err := m.startFrontProcess(inst, inst.front.configPath(configPath))
+	if err == nil {
+		err = m.waitReady(inst, node)
+	}
+	if err != nil {
+		m.stopFrontProcess(inst)
+		m.stopXlinkProcess(inst)
+		inst.mu.Lock()
+		inst.relay.Close()
+		inst.relay = nil
+		inst.mu.Unlock()
+		m.cleanupInstance(inst, err)
+		return err
+	}
+
+	m.updateInstanceConfig(inst, node, configPath)
+	if inst.statsAddr != "" {
+		go m.pollFrontStats(inst)
+	}
+	inst.LogCallback(logger.LevelInfo, logger.CategorySystem, fmt.Sprintf("已重启 %s 以应用配置变化，Xlink 核心保持运行", inst.front.name))
+	return nil
+}
+
+// xlinkConfigEqual 两份 Xlink 核心配置对运行中的核心是否等效
+// 智能分流模式下直连、拦截规则由 Xray 在转发到核心之前处理，核心配置中这部分规则的变化不需要重启核心
+func xlinkConfigEqual(node *models.NodeConfig, before, after []byte) bool {
+	if bytes.Equal(before, after) {
+		return true
+	}
+	if node.RoutingMode != models.RoutingModeSmart {
+		return false
+	}
+	var a, b map[string]interface{}
+	if json.Unmarshal(before, &a) != nil || json.Unmarshal(after, &b) != nil {
+		return false
+	}
+	for _, cfg := range []map[string]interface{}{a, b} {
+		outbounds, _ := cfg["outbounds"].([]interface{})
+		for _, o := range outbounds {
+			outbound, _ := o.(map[string]interface{})
+			settings, _ := outbound["settings"].(map[string]interface{})
+			if rules, ok := settings["rules"].(string); ok {
+				settings["rules"] = proxyRules(rules)
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// proxyRules 去掉核心规则中目标为直连或拦截的行（与 Xray 规则的出站判断一致）
+func proxyRules(rules string) string {
+	var kept []string
+	for _, line := range strings.Split(rules, `\r\n`) {
+		target := strings.ToLower(line[strings.LastIndex(line, ",")+1:])
+		if !strings.Contains(target, "direct") && !strings.Contains(target, "block") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, `\r\n`)
+}
+
+// routingOnlyChanged 两份 Xray 配置是否只有路由规则和负载均衡器不同
+func routingOnlyChanged(before, after []byte) bool {
+	var a, b map[string]interface{}
+	if json.Unmarshal(before, &a) != nil || json.Unmarshal(after, &b) != nil {
+		return false
+	}
+	for _, cfg := range []map[string]interface{}{a, b} {
+		if routing, ok := cfg["routing"].(map[string]interface{}); ok {
+			delete(routing, "rules")
+			delete(routing, "balancers")
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// reloadXrayRules 以 xray api adrules 整体替换运行中 Xray 的路由规则
+func (m *Manager) reloadXrayRules(apiPort int, frontCfg []byte) error {
+	var cfg struct {
+		Routing struct {
+			Rules     json.RawMessage `json:"rules,omitempty"`
+			Balancers json.RawMessage `json:"balancers,omitempty"`
+		} `json:"routing"`
+	}
+	if err := json.Unmarshal(frontCfg, &cfg); err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]interface{}{"routing": cfg.Routing})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(m.exeDir, xrayRulesTempPattern)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), xrayAPITimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, filepath.Join(m.exeDir, XrayBinaryName),
+		"api", "adrules", fmt.Sprintf("--server=127.0.0.1:%d", apiPort), f.Name())
+	cmd.Dir = m.exeDir
+	m.hideWindow(cmd)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
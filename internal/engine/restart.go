@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"xlink-wails/internal/logger"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 异常退出后自动重启
+// =============================================================================
+
+// restartStableAfter 进程稳定运行超过该时间后再退出，重启次数重新计数
+const restartStableAfter = 60 * time.Second
+
+// SetRestartPolicy 设置自动重启策略（对之后的异常退出生效）
+func (m *Manager) SetRestartPolicy(policy models.RestartPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restartPolicy = policy.Normalize()
+}
+
+// cancelRestart 手动启动或停止节点时取消等待中的自动重启
+func (m *Manager) cancelRestart(nodeID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restartGen[nodeID]++
+}
+
+// scheduleRestart 节点开启了自动重启时，按策略退避后重新启动
+// 状态依次通过回调报告: error → restarting → starting → running / error
+func (m *Manager) scheduleRestart(inst *EngineInstance, reason error) {
+	if !inst.node.AutoRestart {
+		return
+	}
+
+	m.mu.RLock()
+	policy := m.restartPolicy
+	gen := m.restartGen[inst.NodeID]
+	m.mu.RUnlock()
+
+	attempt := inst.restartAttempt
+	inst.mu.RLock()
+	if inst.XlinkProcess != nil && time.Since(inst.XlinkProcess.StartTime) >= restartStableAfter {
+		attempt = 0
+	}
+	inst.mu.RUnlock()
+
+	go m.restartLoop(inst, policy, gen, attempt, reason)
+}
+
+func (m *Manager) restartLoop(inst *EngineInstance, policy models.RestartPolicy, gen uint64, attempt int, reason error) {
+	node := inst.node
+	for {
+		attempt++
+		if attempt > policy.MaxRetries {
+			inst.LogCallback(logger.LevelError, logger.CategorySystem, fmt.Sprintf("已连续自动重启 %d 次，不再重试", policy.MaxRetries))
+			return
+		}
+
+		delay := policy.Delay(attempt)
+		if !m.markRestarting(node.ID, gen) {
+			return
+		}
+		inst.LogCallback(logger.LevelWarn, logger.CategorySystem,
+			fmt.Sprintf("%v，%s 后第 %d/%d 次自动重启", reason, delay, attempt, policy.MaxRetries))
+		inst.StatusCallback(models.StatusRestarting, nil)
+
+		time.Sleep(delay)
+		if !m.takeForRestart(node.ID, gen) {
+			return
+		}
+		m.countRestart(node.ID)
+
+		err := m.startNode(&node, inst.configPath, attempt)
+		if err == nil {
+			inst.LogCallback(logger.LevelInfo, logger.CategorySystem, "自动重启成功")
+			return
+		}
+		reason = err
+	}
+}
+
+// countRestart 累计自动重启次数
+func (m *Manager) countRestart(nodeID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restarts[nodeID]++
+}
+
+// RestartCounts 各节点自本次启动以来自动重启的次数
+func (m *Manager) RestartCounts() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	counts := make(map[string]int, len(m.restarts))
+	for id, n := range m.restarts {
+		counts[id] = n
+	}
+	return counts
+}
+
+// markRestarting 自动重启仍然有效时将实例标记为等待重启
+func (m *Manager) markRestarting(nodeID string, gen uint64) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.restartGen[nodeID] != gen {
+		return false
+	}
+	if inst, ok := m.instances[nodeID]; ok {
+		inst.mu.Lock()
+		inst.Status = models.StatusRestarting
+		inst.mu.Unlock()
+	}
+	return true
+}
+
+// takeForRestart 结束异常实例残留的进程（如智能分流时仍在运行的另一个进程）并移除实例，
+// 不发送停止通知；期间用户手动启动或停止过节点时返回 false
+func (m *Manager) takeForRestart(nodeID string, gen uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.restartGen[nodeID] != gen {
+		return false
+	}
+
+	if inst, ok := m.instances[nodeID]; ok {
+		inst.mu.Lock()
+		if inst.FrontProcess != nil {
+			m.terminateProcess(inst.FrontProcess)
+			inst.FrontProcess = nil
+		}
+		if inst.XlinkProcess != nil {
+			m.terminateProcess(inst.XlinkProcess)
+			inst.XlinkProcess = nil
+		}
+		inst.relay.Close()
+		inst.relay = nil
+		inst.mu.Unlock()
+		delete(m.instances, nodeID)
+	}
+	return true
+}
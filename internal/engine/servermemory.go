@@ -0,0 +1,234 @@
+package engine
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/coreproto"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 节点 "最近可用服务器" 记忆
+// =============================================================================
+
+// 记录每个节点的服务器池中最近成功的服务器（隧道建立或测速成功），
+// 生成配置时把它们排到服务器列表最前面，重启后可以更快收敛，而不必重新探测整个池。
+
+// maxRememberedServers 每个节点记住的服务器数量
+const maxRememberedServers = 5
+
+// serverMemoryFlushDelay 记录变化后延迟写盘的时间（隧道日志频繁，合并多次变化）
+const serverMemoryFlushDelay = 30 * time.Second
+
+// ServerMemory 最近可用服务器记录（持久化到 JSON 文件）
+type ServerMemory struct {
+	mu      sync.Mutex
+	path    string
+	servers map[string][]string // key: NodeID，最近成功的在前
+	dirty   bool                // 有尚未写盘的变化
+	timer   *time.Timer         // 延迟写盘，未安排时为 nil
+}
+
+// NewServerMemory 创建记录并加载已有文件
+func NewServerMemory(path string) *ServerMemory {
+	s := &ServerMemory{path: path, servers: make(map[string][]string)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &s.servers)
+		if s.servers == nil {
+			s.servers = make(map[string][]string)
+		}
+	}
+	return s
+}
+
+// Touch 记录一次成功连接，把服务器移到最前面
+func (s *ServerMemory) Touch(nodeID, server string) {
+	server = strings.TrimSpace(server)
+	if nodeID == "" || server == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.servers[nodeID]
+	if len(list) > 0 && list[0] == server {
+		return // 顺序未变，避免频繁写盘
+	}
+
+	updated := []string{server}
+	for _, v := range list {
+		if v != server && len(updated) < maxRememberedServers {
+			updated = append(updated, v)
+		}
+	}
+	s.servers[nodeID] = updated
+	s.markDirtyLocked()
+}
+
+// SetRanking 用测速结果（按延迟从低到高）替换节点的记录
+func (s *ServerMemory) SetRanking(nodeID string, servers []string) {
+	if nodeID == "" || len(servers) == 0 {
+		return
+	}
+	if len(servers) > maxRememberedServers {
+		servers = servers[:maxRememberedServers]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.servers[nodeID] = append([]string(nil), servers...)
+	s.markDirtyLocked()
+}
+
+// Preferred 获取节点最近可用的服务器（最近成功的在前）
+func (s *ServerMemory) Preferred(nodeID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.servers[nodeID]...)
+}
+
+// Forget 删除节点的记录（节点删除或服务器池变更时调用）
+func (s *ServerMemory) Forget(nodeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.servers[nodeID]; !ok {
+		return
+	}
+	delete(s.servers, nodeID)
+	s.markDirtyLocked()
+}
+
+// Flush 立即写入尚未保存的变化（退出时调用）
+func (s *ServerMemory) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.dirty {
+		s.saveLocked()
+	}
+}
+
+// markDirtyLocked 标记有变化并安排延迟写盘
+func (s *ServerMemory) markDirtyLocked() {
+	s.dirty = true
+	if s.timer == nil {
+		s.timer = time.AfterFunc(serverMemoryFlushDelay, s.Flush)
+	}
+}
+
+func (s *ServerMemory) saveLocked() {
+	s.dirty = false
+	data, err := json.MarshalIndent(s.servers, "", "  ")
+	if err != nil {
+		return
+	}
+	os.WriteFile(s.path, data, 0644)
+}
+
+// ReorderServers 按记忆顺序把服务器排到池的最前面，其余保持原顺序，返回 ";" 分隔的列表
+// 记忆中的服务器可以只有主机名（不含端口）
+func ReorderServers(pool string, preferred []string) string {
+	if len(preferred) == 0 || pool == "" {
+		return pool
+	}
+
+	entries := models.SplitServers(pool)
+	used := make([]bool, len(entries))
+	front := make([]string, 0, len(entries))
+
+	for _, p := range preferred {
+		for i, e := range entries {
+			if !used[i] && serverMatches(e, p) {
+				used[i] = true
+				front = append(front, e)
+				break
+			}
+		}
+	}
+	if len(front) == 0 {
+		return pool
+	}
+
+	for i, e := range entries {
+		if !used[i] {
+			front = append(front, e)
+		}
+	}
+	return strings.Join(front, ";")
+}
+
+// RankServers 按测速结果重排服务器池，返回 ";" 分隔的列表和被删除的服务器：
+// 测速成功的按延迟从低到高排在最前，测速中没有的（测速后新加入的）保持原顺序排在其后，
+// 测速失败的排在最后，dropFailed 时删除
+func RankServers(pool string, results []models.PingResult, dropFailed bool) (string, []string) {
+	type ranked struct {
+		entry   string
+		latency int
+	}
+	var ok, untested, failed []ranked
+	for _, e := range models.SplitServers(pool) {
+		r, found := matchPingResult(e, results)
+		switch {
+		case !found:
+			untested = append(untested, ranked{entry: e})
+		case r.Latency >= 0:
+			ok = append(ok, ranked{entry: e, latency: r.Latency})
+		default:
+			failed = append(failed, ranked{entry: e})
+		}
+	}
+	sort.SliceStable(ok, func(i, j int) bool { return ok[i].latency < ok[j].latency })
+
+	var servers, dropped []string
+	for _, r := range append(ok, untested...) {
+		servers = append(servers, r.entry)
+	}
+	for _, r := range failed {
+		if dropFailed {
+			dropped = append(dropped, r.entry)
+		} else {
+			servers = append(servers, r.entry)
+		}
+	}
+	return strings.Join(servers, ";"), dropped
+}
+
+// matchPingResult 池中条目对应的测速结果（测速结果或条目可能不带端口）
+func matchPingResult(entry string, results []models.PingResult) (models.PingResult, bool) {
+	for _, r := range results {
+		if serverMatches(entry, r.Server) || serverMatches(r.Server, entry) {
+			return r, true
+		}
+	}
+	return models.PingResult{}, false
+}
+
+// TunnelServer 从 "Tunnel -> server (...) >>> real (...)" 日志中提取服务器
+func TunnelServer(line string) string {
+	tunnel, _ := coreproto.ParseTunnel(line)
+	return tunnel.Server
+}
+
+// serverMatches 池中条目与记录的服务器是否相同（记录可能不带端口）
+func serverMatches(entry, server string) bool {
+	if entry == server {
+		return true
+	}
+	if host, _, err := net.SplitHostPort(entry); err == nil {
+		return host == strings.Trim(server, "[]")
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// sing-box 统计接口
+// =============================================================================
+
+// sing-box 前端开启 Clash API（experimental.clash_api），/connections 返回全部连接的累计上下行字节数。
+// Clash API 不按入站 / 出站标签累计（只列出仍在进行的连接），计数记在 SingBoxStatsTag 标签下。
+
+const (
+	// SingBoxStatsPath Clash API 提供连接统计的路径
+	SingBoxStatsPath = "/connections"
+	// SingBoxStatsTag sing-box 累计流量记入的入站标签
+	SingBoxStatsTag = "sing-box"
+)
+
+// singBoxConnections /connections 中的累计计数
+type singBoxConnections struct {
+	DownloadTotal int64 `json:"downloadTotal"`
+	UploadTotal   int64 `json:"uploadTotal"`
+}
+
+// QuerySingBoxStats 读取 sing-box Clash API 的累计计数
+func QuerySingBoxStats(addr string) (models.CoreTraffic, error) {
+	client := &http.Client{Timeout: xrayStatsTimeout}
+	resp, err := client.Get("http://" + addr + SingBoxStatsPath)
+	if err != nil {
+		return models.CoreTraffic{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return models.CoreTraffic{}, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var conns singBoxConnections
+	if err := json.NewDecoder(resp.Body).Decode(&conns); err != nil {
+		return models.CoreTraffic{}, err
+	}
+	return models.CoreTraffic{
+		Inbounds: map[string]models.ByteCounter{
+			SingBoxStatsTag: {Up: conns.UploadTotal, Down: conns.DownloadTotal},
+		},
+	}, nil
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"net"
+	"time"
+
+	"xlink-wails/internal/i18n"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 启动 / 停止超时与连接重试
+// =============================================================================
+
+// readyDialTimeout 启动检查时单次连接本地入站的超时
+const readyDialTimeout = time.Second
+
+// SetConnectionPolicy 设置全局连接策略（对之后启动的节点生效）
+func (m *Manager) SetConnectionPolicy(policy models.ConnectionPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connPolicy = policy.Normalize()
+}
+
+// nodePolicy 节点生效的连接策略：全局策略叠加节点中设置的字段
+func (m *Manager) nodePolicy(node *models.NodeConfig) models.ConnectionPolicy {
+	m.mu.RLock()
+	global := m.connPolicy
+	m.mu.RUnlock()
+	return global.Override(node.ConnectionPolicy).Normalize()
+}
+
+// waitReady 等待前端进程（限速时为内部地址）的本地入站可连接，按重试间隔逐次翻倍探测
+// 进程提前退出、节点被停止或超过启动超时时返回错误
+func (m *Manager) waitReady(inst *EngineInstance, node *models.NodeConfig) error {
+	addr := node.Listen
+	if internal, ok := node.BandwidthRelays[node.Listen]; ok {
+		addr = internal
+	}
+	addr = loopbackAddr(addr)
+
+	deadline := time.Now().Add(time.Duration(inst.policy.StartTimeout) * time.Second)
+	for attempt := 1; ; attempt++ {
+		if conn, err := net.DialTimeout("tcp", addr, readyDialTimeout); err == nil {
+			conn.Close()
+			return nil
+		}
+		if inst.stopped() {
+			return i18n.Errorf("节点已停止")
+		}
+		if source := inst.exitedProcess(); source != "" {
+			return i18n.Errorf("%s 进程启动后退出", source)
+		}
+		wait := inst.policy.RetryDelay(attempt)
+		if time.Now().Add(wait).After(deadline) {
+			return i18n.Errorf("节点启动超时，本地入站 %s 无法连接", addr)
+		}
+		time.Sleep(wait)
+	}
+}
+
+// stopped 启动过程中节点是否已被停止
+func (inst *EngineInstance) stopped() bool {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	return inst.Status == models.StatusStopped
+}
+
+// exitedProcess 返回已退出的进程名（xlink / xray / sing-box），都在运行时返回空
+func (inst *EngineInstance) exitedProcess() string {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	procs := map[string]*ProcessInfo{"xlink": inst.XlinkProcess}
+	if inst.front != nil {
+		procs[inst.front.source] = inst.FrontProcess
+	}
+	for source, proc := range procs {
+		if proc == nil || proc.Done == nil {
+			continue
+		}
+		select {
+		case <-proc.Done:
+			return source
+		default:
+		}
+	}
+	return ""
+}
+
+// loopbackAddr 把监听所有地址的监听地址转换为可连接的回环地址
+func loopbackAddr(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if ip != nil && ip.To4() == nil {
+			host = "::1"
+		} else {
+			host = "127.0.0.1"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/models"
+	"xlink-wails/internal/socks5"
+)
+
+// =============================================================================
+// UDP 转发统计与测试
+// =============================================================================
+
+// 很多服务端部署不支持 UDP，QUIC 会回退到 TCP 而不易察觉，游戏和语音则直接失败。
+// 智能分流模式下 Xray 的访问日志为每个 UDP 会话输出一行 "accepted udp:目标 [socks-in -> ...]"
+// （sing-box 为 "inbound packet connection ..."），
+// 转发失败时错误日志带有 udp 字样，据此统计会话数与失败数；TestUDP 则主动经节点发送 NTP 请求验证转发。
+
+// UDPProbeTarget 默认的 UDP 测试目标（NTP，不使用 53 端口以免被 DNS 劫持规则拦截）
+const UDPProbeTarget = "time.cloudflare.com:123"
+
+// udpStats 单个节点的 UDP 会话统计
+type udpStats struct {
+	mu          sync.Mutex
+	sessions    uint64
+	failures    uint64
+	lastSession time.Time
+	lastFailure time.Time
+	lastError   string
+}
+
+// observe 根据一行前端内核日志更新统计
+func (s *udpStats) observe(line string) {
+	lower := strings.ToLower(line)
+	switch {
+	case strings.Contains(lower, " accepted udp:"), strings.Contains(lower, "inbound packet connection"):
+		s.mu.Lock()
+		s.sessions++
+		s.lastSession = time.Now()
+		s.mu.Unlock()
+	case strings.Contains(lower, "udp") && (strings.Contains(lower, "failed") || strings.Contains(lower, "error")):
+		s.mu.Lock()
+		s.failures++
+		s.lastFailure = time.Now()
+		s.lastError = line
+		s.mu.Unlock()
+	}
+}
+
+// snapshot 统计快照
+func (s *udpStats) snapshot(nodeID string) models.UDPStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := models.UDPStats{
+		NodeID:    nodeID,
+		Sessions:  s.sessions,
+		Failures:  s.failures,
+		LastError: s.lastError,
+	}
+	if !s.lastSession.IsZero() {
+		stats.LastSession = s.lastSession.Unix()
+	}
+	if !s.lastFailure.IsZero() {
+		stats.LastFailure = s.lastFailure.Unix()
+	}
+	return stats
+}
+
+// GetUDPStats 获取节点的 UDP 会话统计（节点未运行时为空统计）
+func (m *Manager) GetUDPStats(nodeID string) models.UDPStats {
+	m.mu.RLock()
+	inst, exists := m.instances[nodeID]
+	m.mu.RUnlock()
+	if !exists || inst.UDP == nil {
+		return models.UDPStats{NodeID: nodeID}
+	}
+	return inst.UDP.snapshot(nodeID)
+}
+
+// ProbeUDP 经 SOCKS5 代理的 UDP 关联向 target 发送 NTP 请求，验证代理能否转发 UDP
+func ProbeUDP(proxyAddr, target string, timeout time.Duration) *models.UDPProbeResult {
+	result := &models.UDPProbeResult{Target: target, CheckedAt: time.Now().Unix()}
+	fail := func(stage string, err error) *models.UDPProbeResult {
+		result.Stage = stage
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	proxy := &socks5.Dialer{Addr: proxyAddr, Timeout: timeout}
+	conn, err := proxy.ListenUDP(ctx)
+	if err != nil {
+		return fail("associate", err)
+	}
+	defer conn.Close()
+	result.RelayAddr = conn.RelayAddr().String()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	ntp := make([]byte, 48)
+	ntp[0] = 0x23 // LI=0, VN=4, Mode=3
+
+	start := time.Now()
+	if _, err := conn.WriteTo(ntp, target); err != nil {
+		return fail("relay", err)
+	}
+	buf := make([]byte, 1500)
+	n, err := conn.Read(buf)
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			err = fmt.Errorf("未收到 UDP 响应，代理或服务端可能不支持 UDP 转发: %w", err)
+		}
+		return fail("relay", err)
+	}
+	if n < 48 || buf[0]&0x07 != 4 {
+		return fail("relay", fmt.Errorf("UDP 响应无效"))
+	}
+
+	result.OK = true
+	result.RTTMs = time.Since(start).Milliseconds()
+	return result
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// Xray 统计接口
+// =============================================================================
+
+// 智能分流模式下 Xray 开启 stats 与 metrics，在回环地址上以 expvar（/debug/vars）提供
+// 按入站 / 出站标签累计的字节数；sing-box 前端则由 Clash API 提供（见 QuerySingBoxStats）。
+// 引擎定期读取并交给回调，比解析 Xlink 核心的 [Stats] 日志更准确：直连流量不经过 Xlink 核心，
+// 且未结束的连接也实时计入。
+
+const (
+	// XrayStatsPath Xray metrics 提供计数的路径
+	XrayStatsPath = "/debug/vars"
+	// xrayStatsInterval 读取统计接口的间隔
+	xrayStatsInterval = time.Second
+	// xrayStatsTimeout 单次读取的超时
+	xrayStatsTimeout = 2 * time.Second
+)
+
+// xrayVars /debug/vars 中的统计部分
+type xrayVars struct {
+	Stats struct {
+		Inbound  map[string]xrayCounter `json:"inbound"`
+		Outbound map[string]xrayCounter `json:"outbound"`
+	} `json:"stats"`
+}
+
+type xrayCounter struct {
+	Uplink   int64 `json:"uplink"`
+	Downlink int64 `json:"downlink"`
+}
+
+// SetTrafficCallback 设置读取到内核统计计数时的回调
+func (m *Manager) SetTrafficCallback(cb func(nodeID string, traffic models.CoreTraffic)) {
+	m.trafficCallback = cb
+}
+
+// CoreStatsEnabled 节点当前是否由内核统计接口提供流量计数（此时 [Stats] 日志只用于连接数）
+func (m *Manager) CoreStatsEnabled(nodeID string) bool {
+	m.mu.RLock()
+	inst, ok := m.instances[nodeID]
+	m.mu.RUnlock()
+	return ok && inst.statsAddr != ""
+}
+
+// QueryXrayStats 读取 Xray 统计接口的累计计数
+func QueryXrayStats(addr string) (models.CoreTraffic, error) {
+	client := &http.Client{Timeout: xrayStatsTimeout}
+	resp, err := client.Get("http://" + addr + XrayStatsPath)
+	if err != nil {
+		return models.CoreTraffic{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return models.CoreTraffic{}, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var vars xrayVars
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return models.CoreTraffic{}, err
+	}
+	traffic := models.CoreTraffic{
+		Inbounds:  make(map[string]models.ByteCounter, len(vars.Stats.Inbound)),
+		Outbounds: make(map[string]models.ByteCounter, len(vars.Stats.Outbound)),
+	}
+	for tag, c := range vars.Stats.Inbound {
+		traffic.Inbounds[tag] = models.ByteCounter{Up: c.Uplink, Down: c.Downlink}
+	}
+	for tag, c := range vars.Stats.Outbound {
+		traffic.Outbounds[tag] = models.ByteCounter{Up: c.Uplink, Down: c.Downlink}
+	}
+	return traffic, nil
+}
+
+// pollFrontStats 定期读取节点前端内核的统计接口，直到前端进程退出
+func (m *Manager) pollFrontStats(inst *EngineInstance) {
+	inst.mu.RLock()
+	proc := inst.FrontProcess
+	inst.mu.RUnlock()
+	if proc == nil || m.trafficCallback == nil {
+		return
+	}
+	query := QueryXrayStats
+	if inst.front == frontSingBox {
+		query = QuerySingBoxStats
+	}
+
+	ticker := time.NewTicker(xrayStatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-proc.Done:
+			return
+		case <-ticker.C:
+		}
+		traffic, err := query(inst.statsAddr)
+		if err != nil {
+			continue
+		}
+		traffic.Since = proc.StartTime
+		m.trafficCallback(inst.NodeID, traffic)
+	}
+}
@@ -0,0 +1,384 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 导出为 Clash Meta / sing-box 配置
+// =============================================================================
+
+// Clash 与 sing-box 不支持 xlink 协议，导出的代理指向本机 Xlink 客户端的 SOCKS5 入站，
+// 规则部分可直接复用；在路由器等其他设备上使用时，将代理地址改为运行客户端的电脑的局域网地址
+// （节点需监听 0.0.0.0），或换成其他可用的代理。
+// 规则顺序与内核一致：节点规则（含引用的规则组）→ 私有地址直连 → 中国 IP / 域名直连 → 其余走代理。
+
+// 导出格式
+const (
+	ExportFormatClash   = "clash"    // Clash Meta (mihomo) YAML
+	ExportFormatSingBox = "sing-box" // sing-box JSON（1.11 及以上）
+)
+
+const (
+	// exportProxyGroup Clash 配置中的代理分组
+	exportProxyGroup = "PROXY"
+	// exportMixedPort 导出配置的本地混合入站端口
+	exportMixedPort = 7890
+	// sing-box 规则集下载地址
+	singBoxGeositeURL = "https://raw.githubusercontent.com/SagerNet/sing-geosite/rule-set/geosite-%s.srs"
+	singBoxGeoIPURL   = "https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-%s.srs"
+)
+
+// ExportResult 导出结果
+type ExportResult struct {
+	Format  string   `json:"format"`
+	Content string   `json:"content"`
+	Skipped []string `json:"skipped,omitempty"` // 无法转换而被跳过的规则
+}
+
+// 转换后的规则类型
+const (
+	matchKeyword = "keyword"
+	matchSuffix  = "suffix"
+	matchRegex   = "regex"
+	matchGeosite = "geosite"
+	matchGeoIP   = "geoip"
+	matchCIDR    = "cidr"
+)
+
+// 规则目标
+const (
+	targetProxy  = "proxy"
+	targetDirect = "direct"
+	targetBlock  = "block"
+)
+
+// exportRule 与格式无关的规则
+type exportRule struct {
+	kind   string
+	value  string
+	target string
+}
+
+// builtinExportRules 内核内置的直连规则
+var builtinExportRules = []exportRule{
+	{kind: matchGeoIP, value: "private", target: targetDirect},
+	{kind: matchGeoIP, value: "cn", target: targetDirect},
+	{kind: matchGeosite, value: "cn", target: targetDirect},
+}
+
+// ExportNodeConfig 将节点及其规则导出为 Clash Meta YAML 或 sing-box JSON
+// node.Rules 需已包含引用的规则组规则
+func ExportNodeConfig(node *models.NodeConfig, format string) (*ExportResult, error) {
+	host, port, err := exportProxyAddr(node.Listen)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExportResult{Format: format}
+	rules := make([]exportRule, 0, len(node.Rules)+len(builtinExportRules))
+	for _, r := range node.Rules {
+		rule, err := convertExportRule(r)
+		if err == nil && format == ExportFormatClash && rule.kind != matchCIDR && strings.Contains(rule.value, ",") {
+			// Clash 规则以逗号分隔字段
+			err = fmt.Errorf("匹配内容包含逗号")
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s%s: %v", r.Type, r.Match, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	rules = append(rules, builtinExportRules...)
+
+	name := exportProxyName(node.Name)
+	switch format {
+	case ExportFormatClash:
+		result.Content = buildClashConfig(name, host, port, rules)
+	case ExportFormatSingBox:
+		result.Content, err = buildSingBoxConfig(name, host, port, rules)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	return result, nil
+}
+
+// exportProxyName 代理名称，避免为空或与内置的出站同名
+func exportProxyName(name string) string {
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(name) {
+	case "", targetDirect, "reject", strings.ToLower(exportProxyGroup):
+		return "xlink"
+	}
+	return name
+}
+
+// exportProxyAddr 节点的本地 SOCKS5 入站地址，通配地址换成回环地址
+func exportProxyAddr(listen string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", 0, fmt.Errorf("监听地址格式错误: %s", listen)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("监听地址格式错误: %s", listen)
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return host, port, nil
+}
+
+// convertExportRule 按内核的规则语义转换（无前缀为关键词，domain: 匹配域名及其子域名）
+func convertExportRule(r models.RoutingRule) (exportRule, error) {
+	rule := exportRule{value: strings.TrimSpace(r.Match), target: targetProxy}
+	if rule.value == "" {
+		return rule, fmt.Errorf("匹配内容为空")
+	}
+
+	target := strings.ToLower(r.Target)
+	switch {
+	case strings.Contains(target, "direct"):
+		rule.target = targetDirect
+	case strings.Contains(target, "block"):
+		rule.target = targetBlock
+	}
+
+	switch strings.TrimSuffix(strings.ToLower(r.Type), ":") {
+	case "domain":
+		rule.kind = matchSuffix
+	case "regexp":
+		rule.kind = matchRegex
+	case "geosite":
+		rule.kind = matchGeosite
+		rule.value = strings.ToLower(rule.value)
+	case "geoip":
+		rule.kind = matchGeoIP
+		rule.value = strings.ToLower(rule.value)
+	case "ip", "ip-cidr", "cidr":
+		cidr, err := normalizeCIDR(rule.value)
+		if err != nil {
+			return rule, err
+		}
+		rule.kind, rule.value = matchCIDR, cidr
+	default:
+		rule.kind = matchKeyword
+	}
+	return rule, nil
+}
+
+// normalizeCIDR 单个 IP 补全为 /32 或 /128
+func normalizeCIDR(s string) (string, error) {
+	if _, ipnet, err := net.ParseCIDR(s); err == nil {
+		return ipnet.String(), nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", fmt.Errorf("IP 地址无效")
+	}
+	if ip.To4() != nil {
+		return ip.String() + "/32", nil
+	}
+	return ip.String() + "/128", nil
+}
+
+// =============================================================================
+// Clash Meta
+// =============================================================================
+
+// buildClashConfig 生成 Clash Meta 配置
+func buildClashConfig(name, host string, port int, rules []exportRule) string {
+	var b strings.Builder
+	b.WriteString("# Xlink 客户端导出的 Clash Meta (mihomo) 配置\n")
+	b.WriteString("# Clash 不支持 xlink 协议，代理指向本机 Xlink 客户端的 SOCKS5 入站；\n")
+	b.WriteString("# 在其他设备上使用时请将 server 改为运行客户端的电脑的局域网地址（节点需监听 0.0.0.0）\n")
+	fmt.Fprintf(&b, "mixed-port: %d\n", exportMixedPort)
+	b.WriteString("mode: rule\n")
+	b.WriteString("log-level: info\n\n")
+
+	b.WriteString("proxies:\n")
+	fmt.Fprintf(&b, "  - name: %s\n", yamlQuote(name))
+	b.WriteString("    type: socks5\n")
+	fmt.Fprintf(&b, "    server: %s\n", yamlQuote(host))
+	fmt.Fprintf(&b, "    port: %d\n", port)
+	b.WriteString("    udp: true\n\n")
+
+	b.WriteString("proxy-groups:\n")
+	fmt.Fprintf(&b, "  - name: %s\n", exportProxyGroup)
+	b.WriteString("    type: select\n")
+	b.WriteString("    proxies:\n")
+	fmt.Fprintf(&b, "      - %s\n", yamlQuote(name))
+	b.WriteString("      - DIRECT\n\n")
+
+	b.WriteString("rules:\n")
+	for _, r := range rules {
+		fmt.Fprintf(&b, "  - %s\n", yamlQuote(clashRule(r)))
+	}
+	fmt.Fprintf(&b, "  - MATCH,%s\n", exportProxyGroup)
+	return b.String()
+}
+
+// clashRule 单条 Clash 规则
+func clashRule(r exportRule) string {
+	target := exportProxyGroup
+	switch r.target {
+	case targetDirect:
+		target = "DIRECT"
+	case targetBlock:
+		target = "REJECT"
+	}
+
+	switch r.kind {
+	case matchSuffix:
+		return "DOMAIN-SUFFIX," + r.value + "," + target
+	case matchRegex:
+		return "DOMAIN-REGEX," + r.value + "," + target
+	case matchGeosite:
+		return "GEOSITE," + r.value + "," + target
+	case matchGeoIP:
+		return "GEOIP," + r.value + "," + target
+	case matchCIDR:
+		if strings.Contains(r.value, ":") {
+			return "IP-CIDR6," + r.value + "," + target
+		}
+		return "IP-CIDR," + r.value + "," + target
+	}
+	return "DOMAIN-KEYWORD," + r.value + "," + target
+}
+
+// yamlQuote 无法作为 YAML 普通标量时加双引号（双引号转义与 Go 字符串字面量兼容）
+func yamlQuote(s string) string {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.ContainsAny(s, "\"\\\n\t") || strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.HasSuffix(s, ":") {
+		return strconv.Quote(s)
+	}
+	// 会被解析为布尔值、空值或数字的字符串
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// =============================================================================
+// sing-box
+// =============================================================================
+
+// singBoxConfig sing-box 配置中导出用到的部分
+type singBoxConfig struct {
+	Log       map[string]interface{}   `json:"log"`
+	Inbounds  []map[string]interface{} `json:"inbounds"`
+	Outbounds []map[string]interface{} `json:"outbounds"`
+	Route     singBoxRoute             `json:"route"`
+}
+
+type singBoxRoute struct {
+	Rules               []map[string]interface{} `json:"rules"`
+	RuleSet             []map[string]interface{} `json:"rule_set,omitempty"`
+	Final               string                   `json:"final"`
+	AutoDetectInterface bool                     `json:"auto_detect_interface"`
+}
+
+// buildSingBoxConfig 生成 sing-box 配置，geosite / geoip 规则改用远程规则集
+func buildSingBoxConfig(name, host string, port int, rules []exportRule) (string, error) {
+	cfg := singBoxConfig{
+		Log: map[string]interface{}{"level": "info"},
+		Inbounds: []map[string]interface{}{{
+			"type":        "mixed",
+			"tag":         "mixed-in",
+			"listen":      "127.0.0.1",
+			"listen_port": exportMixedPort,
+		}},
+		Outbounds: []map[string]interface{}{
+			{"type": "socks", "tag": name, "server": host, "server_port": port, "version": "5"},
+			{"type": "direct", "tag": targetDirect},
+		},
+		Route: singBoxRoute{
+			// 先嗅探出域名，域名规则才能匹配经 IP 访问的连接
+			Rules:               []map[string]interface{}{{"action": "sniff"}},
+			Final:               name,
+			AutoDetectInterface: true,
+		},
+	}
+
+	converted, ruleSets := singBoxRules(rules, name)
+	cfg.Route.Rules = append(cfg.Route.Rules, converted...)
+	cfg.Route.RuleSet = ruleSets
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// singBoxRules 转换为 sing-box 路由规则，geosite / geoip 规则改用远程规则集（同时返回用到的规则集）
+func singBoxRules(rules []exportRule, proxyTag string) ([]map[string]interface{}, []map[string]interface{}) {
+	var converted, ruleSets []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		rule := map[string]interface{}{}
+		switch r.kind {
+		case matchSuffix:
+			rule["domain_suffix"] = []string{r.value}
+		case matchRegex:
+			rule["domain_regex"] = []string{r.value}
+		case matchCIDR:
+			rule["ip_cidr"] = []string{r.value}
+		case matchKeyword:
+			rule["domain_keyword"] = []string{r.value}
+		case matchGeoIP:
+			if r.value == "private" {
+				rule["ip_is_private"] = true
+				break
+			}
+			tag := "geoip-" + r.value
+			rule["rule_set"] = []string{tag}
+			if !seen[tag] {
+				seen[tag] = true
+				ruleSets = append(ruleSets, singBoxRuleSet(tag, fmt.Sprintf(singBoxGeoIPURL, r.value)))
+			}
+		case matchGeosite:
+			tag := "geosite-" + r.value
+			rule["rule_set"] = []string{tag}
+			if !seen[tag] {
+				seen[tag] = true
+				ruleSets = append(ruleSets, singBoxRuleSet(tag, fmt.Sprintf(singBoxGeositeURL, r.value)))
+			}
+		}
+
+		switch r.target {
+		case targetBlock:
+			rule["action"] = "reject"
+		case targetDirect:
+			rule["outbound"] = targetDirect
+		default:
+			rule["outbound"] = proxyTag
+		}
+		converted = append(converted, rule)
+	}
+	return converted, ruleSets
+}
+
+// singBoxRuleSet 远程二进制规则集（经代理下载）
+func singBoxRuleSet(tag, url string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":   "remote",
+		"tag":    tag,
+		"format": "binary",
+		"url":    url,
+	}
+}
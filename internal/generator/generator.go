@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"xlink-wails/internal/models"
 )
@@ -16,8 +17,9 @@ import (
 // =============================================================================
 
 const (
-	XlinkConfigTemplate = "config_core_%s.json"
-	XrayConfigTemplate  = "config_xray_%s.json"
+	XlinkConfigTemplate   = "config_core_%s.json"
+	XrayConfigTemplate    = "config_xray_%s.json"
+	SingBoxConfigTemplate = "config_singbox_%s.json"
 )
 
 // =============================================================================
@@ -68,6 +70,9 @@ var DNSModeDescriptions = map[int]string{
 
 type Generator struct {
 	exeDir string
+
+	mu    sync.Mutex
+	files map[string]GeneratedFile // 登记的生成文件，key: 文件名（首次使用时加载）
 }
 
 func NewGenerator(exeDir string) *Generator {
@@ -96,13 +101,15 @@ type XlinkOutbound struct {
 }
 
 type XlinkProxySettings struct {
-	Server          string `json:"server"`
-	ServerIP        string `json:"server_ip,omitempty"`
-	Token           string `json:"token"`
-	Strategy        string `json:"strategy"`
-	Rules           string `json:"rules,omitempty"`
-	GlobalKeepAlive bool   `json:"global_keep_alive"`
-	S5              string `json:"s5,omitempty"`
+	Server            string `json:"server"`
+	ServerIP          string `json:"server_ip,omitempty"`
+	Token             string `json:"token"`
+	Strategy          string `json:"strategy"`
+	Rules             string `json:"rules,omitempty"`
+	GlobalKeepAlive   bool   `json:"global_keep_alive"`
+	IdleTimeout       int    `json:"idle_timeout,omitempty"`        // 秒，0 使用内核默认值
+	KeepAliveInterval int    `json:"keep_alive_interval,omitempty"` // 秒，0 使用内核默认值
+	S5                string `json:"s5,omitempty"`
 }
 
 // =============================================================================
@@ -128,25 +135,21 @@ func (g *Generator) GenerateXlinkConfig(node *models.NodeConfig, listenAddr stri
 	rules := serializeRules(node.Rules)
 
 	config := XlinkConfig{
-		Inbounds: []XlinkInbound{
-			{
-				Tag:      "socks-in",
-				Listen:   listenAddr,
-				Protocol: "socks",
-			},
-		},
+		Inbounds: xlinkInbounds(node, listenAddr),
 		Outbounds: []XlinkOutbound{
 			{
 				Tag:      "proxy",
 				Protocol: "ech-proxy",
 				Settings: XlinkProxySettings{
-					Server:          servers,
-					ServerIP:        node.IP,
-					Token:           tokenStr, // 修复后的 Token
-					Strategy:        strategy,
-					Rules:           rules,
-					GlobalKeepAlive: false,
-					S5:              node.Socks5,
+					Server:            servers,
+					ServerIP:          node.IP,
+					Token:             tokenStr, // 修复后的 Token
+					Strategy:          strategy,
+					Rules:             rules,
+					GlobalKeepAlive:   node.GlobalKeepAlive,
+					IdleTimeout:       node.IdleTimeout,
+					KeepAliveInterval: node.KeepAliveInterval,
+					S5:                node.Socks5,
 				},
 			},
 		},
@@ -160,10 +163,30 @@ func (g *Generator) GenerateXlinkConfig(node *models.NodeConfig, listenAddr stri
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return "", fmt.Errorf("写入配置文件失败: %w", err)
 	}
+	g.Track(node.ID, configPath)
 
 	return configPath, nil
 }
 
+// xlinkInbounds 生成核心入站
+// 智能分流模式下核心只监听内部端口，HTTP 入站由 Xray 前端提供
+func xlinkInbounds(node *models.NodeConfig, listenAddr string) []XlinkInbound {
+	socks := XlinkInbound{Tag: "socks-in", Listen: listenAddr, Protocol: "socks"}
+	if node.RoutingMode == models.RoutingModeSmart {
+		return []XlinkInbound{socks}
+	}
+
+	switch node.InboundMode {
+	case models.InboundMixed:
+		socks.Protocol = "mixed"
+	case models.InboundSocksHTTP:
+		if node.HTTPListen != "" {
+			return []XlinkInbound{socks, {Tag: "http-in", Listen: node.HTTPListen, Protocol: "http"}}
+		}
+	}
+	return []XlinkInbound{socks}
+}
+
 // =============================================================================
 // 辅助方法
 // =============================================================================
@@ -185,27 +208,48 @@ func (g *Generator) ValidateNodeConfig(node *models.NodeConfig) error {
 	if !strings.Contains(node.Listen, ":") {
 		return fmt.Errorf("监听地址格式错误，应为 host:port")
 	}
-	return nil
+	if node.InboundMode == models.InboundSocksHTTP {
+		if !strings.Contains(node.HTTPListen, ":") {
+			return fmt.Errorf("HTTP 监听地址格式错误，应为 host:port")
+		}
+		if node.HTTPListen == node.Listen {
+			return fmt.Errorf("HTTP 监听地址不能与 SOCKS 监听地址相同")
+		}
+	}
+	if err := models.ValidateKeepAlive(node); err != nil {
+		return err
+	}
+	if err := models.ValidateBandwidthLimit(node); err != nil {
+		return err
+	}
+	if err := models.ValidateTUNMTU(node); err != nil {
+		return err
+	}
+	if err := models.ValidateFixedInternalPort(node); err != nil {
+		return err
+	}
+	if err := models.ValidateAppRouting(node); err != nil {
+		return err
+	}
+	if err := models.ValidateFrontEngine(node); err != nil {
+		return err
+	}
+	return models.ValidateLANShare(node)
 }
 
+// CleanupConfigs 删除节点生成的配置（含未登记的旧版本生成文件，文件名带节点 ID，不会与用户文件重名）
 func (g *Generator) CleanupConfigs(nodeID string) error {
-	xlinkPath := filepath.Join(g.exeDir, fmt.Sprintf(XlinkConfigTemplate, nodeID))
-	xrayPath := filepath.Join(g.exeDir, fmt.Sprintf(XrayConfigTemplate, nodeID))
-	os.Remove(xlinkPath)
-	os.Remove(xrayPath)
-	return nil
+	os.Remove(filepath.Join(g.exeDir, fmt.Sprintf(XlinkConfigTemplate, nodeID)))
+	os.Remove(filepath.Join(g.exeDir, fmt.Sprintf(XrayConfigTemplate, nodeID)))
+	os.Remove(filepath.Join(g.exeDir, fmt.Sprintf(SingBoxConfigTemplate, nodeID)))
+	_, err := g.RemoveGenerated(func(id string) bool { return id != nodeID })
+	return err
 }
 
+// CleanupAllConfigs 删除所有登记的生成文件
 func (g *Generator) CleanupAllConfigs() error {
-	pattern := filepath.Join(g.exeDir, "config_*.json")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		return err
-	}
-	for _, f := range files {
-		os.Remove(f)
-	}
-	return nil
+	_, err := g.RemoveGenerated(nil)
+	return err
 }
 
 // =============================================================================
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// =============================================================================
+// 生成文件登记
+// =============================================================================
+
+// 生成的内核配置与用户文件位于同一目录，清理时只删除登记过的文件，
+// 不再按 config_*.json 通配删除，避免误删用户放在该目录中的同名模式文件。
+
+// ManifestFileName 生成文件登记表
+const ManifestFileName = "generated_files.json"
+
+// GeneratedFile 登记的生成文件
+type GeneratedFile struct {
+	NodeID      string    `json:"node_id"`
+	Name        string    `json:"name"` // 文件名（位于数据目录）
+	GeneratedAt time.Time `json:"generated_at"`
+	Size        int64     `json:"size,omitempty"` // 列出时填写
+}
+
+// Track 登记节点生成的文件（同名文件覆盖旧记录）
+func (g *Generator) Track(nodeID, path string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.loadManifestLocked()
+	name := filepath.Base(path)
+	g.files[name] = GeneratedFile{NodeID: nodeID, Name: name, GeneratedAt: time.Now()}
+	g.saveManifestLocked()
+}
+
+// GeneratedFiles 列出仍存在的登记文件（按节点、文件名排序）
+func (g *Generator) GeneratedFiles() []GeneratedFile {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.loadManifestLocked()
+	result := make([]GeneratedFile, 0, len(g.files))
+	for _, f := range g.files {
+		info, err := os.Stat(filepath.Join(g.exeDir, f.Name))
+		if err != nil {
+			continue
+		}
+		f.Size = info.Size()
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NodeID != result[j].NodeID {
+			return result[i].NodeID < result[j].NodeID
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// RemoveGenerated 删除登记的文件，keep 返回 true 的节点保留（keep 为空时全部删除），返回删除的文件数
+func (g *Generator) RemoveGenerated(keep func(nodeID string) bool) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.loadManifestLocked()
+	removed := 0
+	var firstErr error
+	for name, f := range g.files {
+		if keep != nil && keep(f.NodeID) {
+			continue
+		}
+		err := os.Remove(filepath.Join(g.exeDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("删除 %s 失败: %w", name, err)
+			}
+			continue
+		}
+		if err == nil {
+			removed++
+		}
+		delete(g.files, name)
+	}
+	g.saveManifestLocked()
+	return removed, firstErr
+}
+
+// loadManifestLocked 首次使用时读取登记表
+func (g *Generator) loadManifestLocked() {
+	if g.files != nil {
+		return
+	}
+	g.files = make(map[string]GeneratedFile)
+	data, err := os.ReadFile(filepath.Join(g.exeDir, ManifestFileName))
+	if err != nil {
+		return
+	}
+	var list []GeneratedFile
+	if json.Unmarshal(data, &list) != nil {
+		return
+	}
+	for _, f := range list {
+		// 只接受数据目录中的文件名，登记表被改动时也不会删除其他位置的文件
+		if name := filepath.Base(f.Name); name == f.Name && name != ManifestFileName {
+			g.files[name] = f
+		}
+	}
+}
+
+// saveManifestLocked 写入登记表，没有登记文件时删除登记表
+func (g *Generator) saveManifestLocked() {
+	path := filepath.Join(g.exeDir, ManifestFileName)
+	if len(g.files) == 0 {
+		os.Remove(path)
+		return
+	}
+	list := make([]GeneratedFile, 0, len(g.files))
+	for _, f := range g.files {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	if data, err := json.MarshalIndent(list, "", "  "); err == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
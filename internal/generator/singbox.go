@@ -0,0 +1,328 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"xlink-wails/internal/dns"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// sing-box 智能分流前端
+// =============================================================================
+
+// 节点选择 sing-box 作为智能分流前端时，由 sing-box 提供本地入站（TUN 模式下另建 TUN 网卡）、
+// 负责 DNS 与分流，需要代理的连接交给 Xlink 核心的内部 SOCKS5 入站。
+// 规则顺序与 Xray 前端一致：局域网白名单 → 按应用直连 → 节点规则 → 广告 / BT 拦截 → 私有地址、中国 IP / 域名直连 → 其余走代理；
+// geosite / geoip 规则使用远程规则集（经代理下载，缓存在数据目录），不依赖 geosite.dat / geoip.dat。
+// 要求 sing-box 1.11 及以上。
+
+const (
+	// SingBoxProxyTag 代理出站（Xlink 核心）的标签
+	SingBoxProxyTag = "proxy"
+	// singBoxCacheTemplate 规则集与 Fake-IP 缓存文件
+	singBoxCacheTemplate = "cache_singbox_%s.db"
+	// singBoxTUNIPv4 / singBoxTUNIPv6 TUN 网卡地址（不能与 Fake-IP 地址池重叠）
+	singBoxTUNIPv4 = "172.19.0.1/30"
+	singBoxTUNIPv6 = "fdfe:dcba:9876::1/126"
+	// xlinkProcessName Xlink 核心的进程名（与 engine.XlinkBinaryName 一致），TUN 模式下直连以免回环
+	xlinkProcessName = "xlink-cli-binary.exe"
+	// singBoxAdsRuleSet 广告拦截使用的 geosite 分类
+	singBoxAdsRuleSet = "category-ads-all"
+)
+
+// singBoxFrontConfig sing-box 前端配置
+type singBoxFrontConfig struct {
+	Log          map[string]interface{}   `json:"log"`
+	DNS          map[string]interface{}   `json:"dns"`
+	Inbounds     []map[string]interface{} `json:"inbounds"`
+	Outbounds    []map[string]interface{} `json:"outbounds"`
+	Route        singBoxRoute             `json:"route"`
+	Experimental map[string]interface{}   `json:"experimental"`
+}
+
+// GenerateSingBoxConfig 生成节点的 sing-box 前端配置，xlinkPort 为 Xlink 核心的内部端口
+// 返回配置路径和无法转换而跳过的规则
+func (g *Generator) GenerateSingBoxConfig(node *models.NodeConfig, xlinkPort int) (string, []string, error) {
+	configPath := filepath.Join(g.exeDir, fmt.Sprintf(SingBoxConfigTemplate, node.ID))
+	cachePath := filepath.Join(g.exeDir, fmt.Sprintf(singBoxCacheTemplate, node.ID))
+
+	inbounds, err := singBoxInbounds(node)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rules := make([]exportRule, 0, len(node.Rules)+len(builtinExportRules)+1)
+	var skipped []string
+	for _, r := range node.Rules {
+		rule, err := convertExportRule(r)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s%s: %v", r.Type, r.Match, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	rules = append(rules, exportRule{kind: matchGeosite, value: singBoxAdsRuleSet, target: targetBlock})
+	rules = append(rules, builtinExportRules...)
+
+	cfg := singBoxFrontConfig{
+		Log:      map[string]interface{}{"level": "info", "timestamp": true},
+		DNS:      singBoxDNS(node),
+		Inbounds: inbounds,
+		Outbounds: []map[string]interface{}{
+			{"type": "socks", "tag": SingBoxProxyTag, "server": "127.0.0.1", "server_port": xlinkPort, "version": "5"},
+			{"type": "direct", "tag": targetDirect},
+		},
+		Route: singBoxRoute{
+			Final:               SingBoxProxyTag,
+			AutoDetectInterface: true,
+		},
+		Experimental: map[string]interface{}{
+			"cache_file": map[string]interface{}{"enabled": true, "path": cachePath, "store_fakeip": true},
+		},
+	}
+	if node.StatsPort > 0 {
+		cfg.Experimental["clash_api"] = map[string]interface{}{
+			"external_controller": fmt.Sprintf("127.0.0.1:%d", node.StatsPort),
+		}
+	}
+
+	// 先嗅探出域名，域名规则才能匹配经 IP 访问的连接；DNS 请求交给内置 DNS 处理
+	route := []map[string]interface{}{
+		{"action": "sniff"},
+		{"protocol": "dns", "action": "hijack-dns"},
+	}
+	if lanTags := singBoxLANInboundTags(node); len(lanTags) > 0 {
+		clients, err := models.LANShareClients(&node.LANShare)
+		if err != nil {
+			return "", nil, err
+		}
+		cidrs := make([]string, 0, len(clients))
+		for _, p := range clients {
+			cidrs = append(cidrs, p.String())
+		}
+		// 拦截不在允许列表中的客户端（只针对局域网入站，TUN 流量的来源是本机网卡地址）
+		route = append(route, map[string]interface{}{
+			"type": "logical",
+			"mode": "and",
+			"rules": []map[string]interface{}{
+				{"inbound": lanTags},
+				{"source_ip_cidr": cidrs, "invert": true},
+			},
+			"action": "reject",
+		})
+	}
+	if node.DNSMode == models.DNSModeTUN {
+		route = append(route, map[string]interface{}{"process_name": []string{xlinkProcessName}, "outbound": targetDirect})
+	}
+	if node.AppRoutingMode == models.AppRoutingExclude {
+		rule := singBoxAppMatcher(node.AppRoutingApps)
+		rule["outbound"] = targetDirect
+		route = append(route, rule)
+	}
+
+	converted, ruleSets := singBoxRules(rules, SingBoxProxyTag)
+	for _, r := range converted {
+		// BT 拦截排在广告拦截之后、直连规则之前（与 Xray 前端的顺序一致）
+		if sets, _ := r["rule_set"].([]string); len(sets) == 1 && sets[0] == "geosite-"+singBoxAdsRuleSet {
+			route = append(route, r, map[string]interface{}{"protocol": []string{"bittorrent"}, "action": "reject"})
+			continue
+		}
+		route = append(route, r)
+	}
+
+	// 仅列表中的程序走代理：走代理的规则追加进程条件，其余连接最后直连
+	if node.AppRoutingMode == models.AppRoutingInclude {
+		for i, r := range route {
+			if r["outbound"] != SingBoxProxyTag {
+				continue
+			}
+			delete(r, "outbound")
+			route[i] = map[string]interface{}{
+				"type":     "logical",
+				"mode":     "and",
+				"rules":    []map[string]interface{}{r, singBoxAppMatcher(node.AppRoutingApps)},
+				"outbound": SingBoxProxyTag,
+			}
+		}
+		last := singBoxAppMatcher(node.AppRoutingApps)
+		last["outbound"] = SingBoxProxyTag
+		route = append(route, last)
+		cfg.Route.Final = targetDirect
+	}
+	cfg.Route.Rules = route
+	cfg.Route.RuleSet = ruleSets
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return "", nil, fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	g.Track(node.ID, configPath)
+	g.Track(node.ID, cachePath)
+
+	return configPath, skipped, nil
+}
+
+// singBoxLANInboundTags 局域网共享入站的标签（与 node.LANInbounds 一一对应）
+func singBoxLANInboundTags(node *models.NodeConfig) []string {
+	if !node.LANShare.Enabled {
+		return nil
+	}
+	tags := make([]string, len(node.LANInbounds))
+	for i, lan := range node.LANInbounds {
+		if lan.HTTP {
+			tags[i] = fmt.Sprintf("http-lan-%d", i)
+		} else {
+			tags[i] = fmt.Sprintf("mixed-lan-%d", i)
+		}
+	}
+	return tags
+}
+
+// singBoxInbounds 本地入站：mixed（SOCKS5 + HTTP）、可选的 HTTP 入站、局域网共享入站和 TUN 入站
+func singBoxInbounds(node *models.NodeConfig) ([]map[string]interface{}, error) {
+	listen := func(tag, typ, addr string, auth bool) (map[string]interface{}, error) {
+		host, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("监听地址格式错误: %s", addr)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("监听地址格式错误: %s", addr)
+		}
+		if host == "" {
+			host = "0.0.0.0"
+		}
+		in := map[string]interface{}{"type": typ, "tag": tag, "listen": host, "listen_port": port}
+		if auth {
+			in["users"] = []map[string]interface{}{
+				{"username": node.LANShare.Username, "password": node.LANShare.Password},
+			}
+		}
+		return in, nil
+	}
+
+	mixed, err := listen("mixed-in", "mixed", node.Listen, false)
+	if err != nil {
+		return nil, err
+	}
+	inbounds := []map[string]interface{}{mixed}
+	if node.InboundMode == models.InboundSocksHTTP && node.HTTPListen != "" {
+		http, err := listen("http-in", "http", node.HTTPListen, false)
+		if err != nil {
+			return nil, err
+		}
+		inbounds = append(inbounds, http)
+	}
+	// 局域网共享：本地入站保持无需认证，局域网地址上的入站要求账号
+	if node.LANShare.Enabled {
+		tags := singBoxLANInboundTags(node)
+		for i, lan := range node.LANInbounds {
+			typ := "mixed"
+			if lan.HTTP {
+				typ = "http"
+			}
+			in, err := listen(tags[i], typ, lan.Listen, true)
+			if err != nil {
+				return nil, err
+			}
+			inbounds = append(inbounds, in)
+		}
+	}
+
+	if node.DNSMode == models.DNSModeTUN {
+		mtu := node.TUNMTU
+		if mtu <= 0 {
+			mtu = dns.DefaultTUNMTU
+		}
+		address := []string{singBoxTUNIPv4}
+		if node.EnableIPv6 && !node.DisableIPv6 {
+			address = append(address, singBoxTUNIPv6)
+		}
+		inbounds = append(inbounds, map[string]interface{}{
+			"type":           "tun",
+			"tag":            "tun-in",
+			"interface_name": dns.DefaultTUNName,
+			"address":        address,
+			"mtu":            mtu,
+			"auto_route":     true,
+			"strict_route":   true,
+			"stack":          "mixed",
+		})
+	}
+	return inbounds, nil
+}
+
+// singBoxDNS DNS 配置：中国域名与本地域名经直连解析，其余经代理解析；Fake-IP 与 TUN 模式下 A / AAAA 查询返回 Fake-IP
+func singBoxDNS(node *models.NodeConfig) map[string]interface{} {
+	servers := []map[string]interface{}{
+		{"tag": "remote", "address": dns.DNSCloudflareDoH, "detour": SingBoxProxyTag},
+		{"tag": "local", "address": dns.DNSAliDNS, "detour": targetDirect},
+	}
+	rules := []map[string]interface{}{
+		{"domain_suffix": []string{".lan", ".local", ".localhost", ".localdomain", ".home.arpa"}, "server": "local"},
+		{"rule_set": []string{"geosite-cn"}, "server": "local"},
+	}
+	cfg := map[string]interface{}{
+		"servers":  servers,
+		"final":    "remote",
+		"strategy": singBoxDNSStrategy(node),
+	}
+
+	if node.DNSMode == models.DNSModeFakeIP || node.DNSMode == models.DNSModeTUN {
+		cfg["servers"] = append(servers, map[string]interface{}{"tag": "fakeip", "address": "fakeip"})
+		rules = append(rules, map[string]interface{}{"query_type": []string{"A", "AAAA"}, "server": "fakeip"})
+		fakeip := map[string]interface{}{"enabled": true, "inet4_range": dns.FakeIPPoolCIDR}
+		if node.EnableIPv6 && !node.DisableIPv6 {
+			fakeip["inet6_range"] = dns.FakeIPv6PoolCIDR
+		}
+		cfg["fakeip"] = fakeip
+	}
+	cfg["rules"] = rules
+	return cfg
+}
+
+// singBoxDNSStrategy 按节点的 IPv6 设置选择解析策略
+func singBoxDNSStrategy(node *models.NodeConfig) string {
+	switch {
+	case node.DisableIPv6 || !node.EnableIPv6:
+		return "ipv4_only"
+	case node.IPv6Only:
+		return "ipv6_only"
+	case node.PreferIPv6:
+		return "prefer_ipv6"
+	}
+	return "prefer_ipv4"
+}
+
+// singBoxAppMatcher 按程序匹配的规则条件：程序名匹配 process_name，完整路径匹配 process_path
+func singBoxAppMatcher(apps []string) map[string]interface{} {
+	var names, paths []string
+	for _, app := range apps {
+		if strings.ContainsAny(app, `\/`) {
+			paths = append(paths, app)
+		} else {
+			names = append(names, app)
+		}
+	}
+	switch {
+	case len(paths) == 0:
+		return map[string]interface{}{"process_name": names}
+	case len(names) == 0:
+		return map[string]interface{}{"process_path": paths}
+	}
+	return map[string]interface{}{
+		"type":  "logical",
+		"mode":  "or",
+		"rules": []map[string]interface{}{{"process_name": names}, {"process_path": paths}},
+	}
+}
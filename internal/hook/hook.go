@@ -0,0 +1,160 @@
+// Package hook 在节点启动 / 停止后执行用户配置的命令
+package hook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// 钩子命令
+// =============================================================================
+
+// 触发钩子的事件
+const (
+	EventPostStart = "post_start"
+	EventPostStop  = "post_stop"
+)
+
+// maxOutput 记录的输出上限，超出部分丢弃
+const maxOutput = 64 << 10
+
+// Context 传给命令的节点信息，以 XLINK_ 开头的环境变量提供
+type Context struct {
+	Event    string // post_start / post_stop
+	NodeID   string
+	NodeName string
+	Listen   string // 本地监听地址
+	Status   string // 节点状态 (models.Status*)
+	Error    string // 异常退出的原因
+}
+
+// Env 命令的环境变量（在当前进程的环境变量之后追加）
+func (c Context) Env() []string {
+	port := ""
+	if i := strings.LastIndex(c.Listen, ":"); i != -1 {
+		port = c.Listen[i+1:]
+	}
+	return append(os.Environ(),
+		"XLINK_EVENT="+c.Event,
+		"XLINK_NODE_ID="+c.NodeID,
+		"XLINK_NODE_NAME="+c.NodeName,
+		"XLINK_NODE_LISTEN="+c.Listen,
+		"XLINK_NODE_PORT="+port,
+		"XLINK_NODE_STATUS="+c.Status,
+		"XLINK_NODE_ERROR="+c.Error,
+	)
+}
+
+// Result 命令的执行结果
+type Result struct {
+	Event    string `json:"event"`
+	Command  string `json:"command"`
+	Output   string `json:"output"`    // 标准输出与标准错误合并后的内容
+	ExitCode int    `json:"exit_code"` // 未能启动或超时时为 -1
+	Duration int64  `json:"duration"`  // 毫秒
+	TimedOut bool   `json:"timed_out"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Lines 输出的非空行
+func (r *Result) Lines() []string {
+	var lines []string
+	for _, line := range strings.Split(r.Output, "\n") {
+		if line = strings.TrimRight(line, "\r "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Run 通过系统 shell 执行命令，超时后终止命令及其子进程
+func Run(command string, hc Context, timeout time.Duration) *Result {
+	result := &Result{Event: hc.Event, Command: command, ExitCode: -1}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(command)
+	cmd.Env = hc.Env()
+	out := &limitedBuffer{limit: maxOutput}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// 命令在后台启动的程序继续持有输出管道时，命令退出后最多再等待一秒
+	cmd.WaitDelay = time.Second
+	prepareCommand(cmd)
+
+	if err := cmd.Start(); err != nil {
+		result.Error = fmt.Sprintf("启动命令失败: %v", err)
+		return result
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		killTree(cmd)
+		err = <-done
+		result.TimedOut = true
+	}
+
+	result.Duration = time.Since(start).Milliseconds()
+	result.Output = out.String()
+	switch {
+	case result.TimedOut:
+		result.Error = fmt.Sprintf("命令超时 (%s)，已终止", timeout)
+	case err != nil && !errors.Is(err, exec.ErrWaitDelay):
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+			result.Error = "退出码 " + strconv.Itoa(result.ExitCode)
+		} else {
+			result.Error = err.Error()
+		}
+	default:
+		result.ExitCode = 0
+	}
+	return result
+}
+
+// limitedBuffer 只保留前 limit 字节的输出（命令的两个输出流并发写入）
+type limitedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.truncated {
+		return b.buf.String() + "\n(输出过长，已截断)"
+	}
+	return b.buf.String()
+}
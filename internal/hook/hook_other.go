@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package hook
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand 由 sh -c 执行命令
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("/bin/sh", "-c", command)
+}
+
+// prepareCommand 单独建立进程组，超时时可一并终止子进程
+func prepareCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killTree 终止命令所在的进程组
+func killTree(cmd *exec.Cmd) {
+	if syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) != nil {
+		cmd.Process.Kill()
+	}
+}
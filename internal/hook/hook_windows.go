@@ -0,0 +1,32 @@
+//go:build windows
+// +build windows
+
+package hook
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// shellCommand 由 cmd /C 执行命令（原样传递命令行，保留用户书写的引号）
+func shellCommand(command string) *exec.Cmd {
+	cmd := exec.Command("cmd")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "cmd /S /C \"" + command + "\""}
+	return cmd
+}
+
+// prepareCommand 隐藏控制台窗口
+func prepareCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr.HideWindow = true
+	cmd.SysProcAttr.CreationFlags = 0x08000000 // CREATE_NO_WINDOW
+}
+
+// killTree 终止命令及其启动的子进程
+func killTree(cmd *exec.Cmd) {
+	kill := exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(cmd.Process.Pid))
+	kill.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if kill.Run() != nil {
+		cmd.Process.Kill()
+	}
+}
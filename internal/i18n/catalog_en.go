@@ -0,0 +1,289 @@
+package i18n
+
+// enUS 英文消息目录
+var enUS = map[string]string{
+	// ---- 日志类别 ----
+	"系统":       "System",
+	"内核":       "Core",
+	"隧道":       "Tunnel",
+	"规则":       "Rule",
+	"负载":       "Balance",
+	"统计":       "Stats",
+	"测速":       "Ping",
+	"Xray":     "Xray",
+	"sing-box": "sing-box",
+	"DNS":      "DNS",
+
+	// ---- 节点 ----
+	"节点不存在":                 "Node not found",
+	"节点不存在: %s":             "Node not found: %s",
+	"节点数量已达上限":              "Node limit reached",
+	"节点数量已达上限 (%d)":         "Node limit reached (%d)",
+	"请先停止节点再删除":             "Stop the node before deleting it",
+	"节点 %s 与保留节点不一致，请重新检测":  "Node %s differs from the node being kept, please check again",
+	"节点 %s 正在运行，请先停止":       "Node %s is running, stop it first",
+	"生成配置失败: %w":            "Failed to generate config: %w",
+	"没有节点":                  "No nodes",
+	"没有运行中的节点":              "No running nodes",
+	"没有可用的节点":               "No available nodes",
+	"没有符合条件的节点":             "No nodes match the filter",
+	"请指定分组、标签或其他筛选条件":       "Specify a group, tag or another filter",
+	"节点启动超时，本地入站 %s 无法连接":   "Node startup timed out, local inbound %s is unreachable",
+	"测速已取消":                 "Speed test cancelled",
+	"测速地址无效: %s":            "Invalid speed test URL: %s",
+	"监听地址格式错误: %s":          "Invalid listen address: %s",
+	"空闲超时和保活间隔不能为负数":        "Idle timeout and keep-alive interval cannot be negative",
+	"保活间隔必须小于空闲超时":          "Keep-alive interval must be shorter than the idle timeout",
+	"带宽限制不能为负数":             "Bandwidth limits cannot be negative",
+	"带宽限制不能超过 10 GB/s":      "Bandwidth limits cannot exceed 10 GB/s",
+	"无效的出口策略: %d":           "Invalid egress mode: %d",
+	"核心文件不存在":               "Core binary not found",
+	"监听地址不能为空":              "Listen address is required",
+	"服务器地址不能为空":             "Server address is required",
+	"监听地址格式错误，应为 host:port": "Invalid listen address, expected host:port",
+
+	// ---- 规则 ----
+	"规则不存在":                     "Rule not found",
+	"规则组不存在":                    "Rule group not found",
+	"规则组名称不能为空":                 "Rule group name is required",
+	"规则组名称超过 %d 字节":             "Rule group name exceeds %d bytes",
+	"规则数量 %d 超过上限 %d":           "Rule count %d exceeds the limit of %d",
+	"添加后规则数量 %d 超过上限 %d":        "Rule count after adding (%d) exceeds the limit of %d",
+	"修改后节点 %s 的规则数量 %d 超过上限 %d": "Rule count of node %s after the change (%d) exceeds the limit of %d",
+	"规则匹配内容和目标不能为空":             "Rule match and target are required",
+	"预设不存在":                     "Preset not found",
+	"规则数据正在更新中":                 "Rule data is being updated",
+
+	// ---- 系统 ----
+	"设置 PAC 失败: %w": "Failed to set PAC: %w",
+	"自启未初始化":        "Auto-start is not initialized",
+	"断线保护需要修改防火墙策略，请以管理员身份运行": "Kill switch modifies firewall policy, please run as administrator",
+	"控制接口启动失败，请检查监听地址是否被占用":   "Failed to start the control API, check whether the listen address is in use",
+	"测试间隔不能为负数":               "Test interval cannot be negative",
+	"重启策略参数不能为负数":             "Restart policy values cannot be negative",
+	"定时任务不存在":                 "Scheduled task not found",
+	"不支持的定时动作: %s":            "Unsupported scheduled action: %s",
+	"日志订阅不存在: %s":             "Log subscription not found: %s",
+	"仅 Windows 支持防火墙规则管理":     "Firewall rules are only supported on Windows",
+	"仅 Windows 支持系统托盘":        "System tray is only supported on Windows",
+	"已取消管理员授权":                "Administrator authorization was cancelled",
+	"需要管理员权限":                 "Administrator privileges required",
+	"关闭连接需要管理员权限":             "Closing connections requires administrator privileges",
+	"仅支持Windows":              "Only supported on Windows",
+	"TUN模式在当前平台暂不支持":          "TUN mode is not supported on this platform",
+	"未找到默认网关":                 "Default gateway not found",
+	"配置为空":                    "Configuration is empty",
+	"不是有效的备份文件":               "Not a valid backup file",
+	"该备份已设置密码":                "This backup is password protected",
+	"备份文件已损坏":                 "Backup file is corrupted",
+	"密码错误或备份文件已损坏":            "Wrong password or corrupted backup file",
+	"解密失败：备份来自使用不同配置密钥的环境":    "Decryption failed: the backup was made with a different config key",
+	"创建备份目录失败: %w":            "Failed to create backup directory: %w",
+	"配置文件不存在":                 "Configuration file not found",
+	"读取配置文件失败: %w":            "Failed to read configuration file: %w",
+	"加密配置失败: %w":              "Failed to encrypt configuration: %w",
+	"写入备份失败: %w":              "Failed to write backup: %w",
+	"不支持的链接格式":                "Unsupported link format",
+	"未找到有效的节点链接":              "No valid node links found",
+	"无效的URI格式":                "Invalid URI format",
+
+	// ---- 订阅导入 ----
+	"订阅地址无效: %s":      "Invalid subscription URL: %s",
+	"下载订阅失败: %w":      "Failed to download subscription: %w",
+	"下载订阅失败: HTTP %d": "Failed to download subscription: HTTP %d",
+	"订阅内容超过大小上限":      "Subscription content exceeds the size limit",
+	"解析订阅失败: %w":      "Failed to parse subscription: %w",
+	"内容为空":            "Content is empty",
+	"无法识别的订阅格式":       "Unrecognized subscription format",
+	"未找到有效的节点":        "No valid nodes found",
+	"该订阅已存在: %s":      "Subscription already exists: %s",
+	"订阅不存在":           "Subscription not found",
+
+	// ---- 二维码导入 ----
+	"图片超过大小上限":                      "Image exceeds the size limit",
+	"图片尺寸过大":                        "Image dimensions are too large",
+	"无法识别图片格式，仅支持 PNG / JPEG / GIF": "Unrecognized image format, only PNG / JPEG / GIF are supported",
+	"图片中未找到二维码":                     "No QR code found in the image",
+	"二维码中没有可导入的节点":                  "The QR codes contain no importable nodes",
+
+	// ---- 自动备份 ----
+	"备份保留份数应在 0-%d 之间": "Backup retention must be between 0 and %d",
+	"创建备份失败: %w":       "Failed to create backup: %w",
+
+	// ---- 只读模式 ----
+	"只读模式下不能修改配置": "Configuration cannot be changed in read-only mode",
+	"密码错误":        "Wrong password",
+
+	// ---- 配置导出 ----
+	"不支持的导出格式: %s": "Unsupported export format: %s",
+	"写入文件失败: %w":   "Failed to write file: %w",
+
+	// ---- 本机 DNS 服务 ----
+	"上游 DNS 无效: %w": "Invalid upstream DNS: %w",
+	"本机 DNS 服务启动失败，请检查 53 端口是否被占用: %w": "Failed to start the local DNS service, check whether port 53 is in use: %w",
+
+	// ---- DNS 配置包 ----
+	"读取文件失败: %w":              "Failed to read file: %w",
+	"不是有效的 DNS 配置包":           "Not a valid DNS settings bundle",
+	"DNS 配置包版本过新 (%d)，请先升级程序": "The DNS settings bundle is from a newer version (%d), please upgrade first",
+	"无效的 DNS 模式: %d":          "Invalid DNS mode: %d",
+	"hosts 中 %s 的地址无效: %s":    "Invalid address for %s in hosts: %s",
+	"DNS 预设名称不能为空":            "DNS preset name cannot be empty",
+	"DNS 预设 %s 没有服务器":         "DNS preset %s has no servers",
+
+	// ---- 内核更新 ----
+	"更新源地址无效: %s": "Invalid update feed URL: %s",
+	"未配置内核更新源":    "No core update feed configured",
+	"内核正在更新中":     "Core update already in progress",
+
+	// ---- 通知 ----
+	"未知的通知事件: %s":                     "Unknown notification event: %s",
+	"未知的通知渠道: %s":                     "Unknown notification channel: %s",
+	"通知渠道 %s 未配置":                     "Notification channel %s is not configured",
+	"Webhook 地址无效: %s":                "Invalid webhook URL: %s",
+	"Webhook %s 的模板有误: %w":            "Template of webhook %s is invalid: %w",
+	"Telegram 需要同时填写 Bot Token 和会话ID": "Telegram requires both a bot token and a chat ID",
+	"托盘图标未显示":                         "Tray icon is not shown",
+	"这是一条测试通知":                        "This is a test notification",
+
+	// ---- 钩子命令 ----
+	"钩子超时应在 0-%d 秒之间": "Hook timeout must be between 0 and %d seconds",
+	"未设置该事件的钩子命令":     "No hook command is set for this event",
+
+	// ---- TUN MTU ----
+	"TUN MTU 应在 %d 到 %d 之间": "TUN MTU must be between %d and %d",
+	"节点未配置服务器":              "The node has no server configured",
+
+	// ---- 界面数据目录 ----
+	"提醒大小不能为负数":      "The warning size cannot be negative",
+	"标记清理界面缓存失败: %v": "Failed to schedule UI cache cleanup: %v",
+
+	// ---- 局域网共享 ----
+	"局域网共享需要智能分流模式":            "LAN sharing requires smart routing mode",
+	"局域网共享需要设置用户名和密码":          "LAN sharing requires a username and password",
+	"允许的客户端无效: %s":             "Invalid allowed client: %s",
+	"开启局域网共享时本地监听地址需为回环地址: %s": "The local listen address must be a loopback address when LAN sharing is enabled: %s",
+
+	// ---- 数量上限 ----
+	"节点数量上限应在 1 到 %d 之间":         "Node limit must be between 1 and %d",
+	"规则数量上限应在 1 到 %d 之间":         "Rule limit must be between 1 and %d",
+	"当前已有 %d 个节点，上限不能低于该数量":      "There are already %d nodes, the limit cannot be lower",
+	"节点 %s 已有 %d 条规则，上限不能低于该数量":  "Node %s already has %d rules, the limit cannot be lower",
+	"规则组 %s 已有 %d 条规则，上限不能低于该数量": "Rule group %s already has %d rules, the limit cannot be lower",
+
+	// ---- 后台服务 ----
+	"无法获取程序路径: %w":     "Cannot determine program path: %w",
+	"后台服务已启动，但控制接口无响应": "The background service started but its control API is not responding",
+	"后台服务无响应: %w":      "Background service not responding: %w",
+
+	// ---- 存储模式 ----
+	"未知的存储模式: %s": "Unknown storage mode: %s",
+	"后台服务使用安装时的数据目录，请先卸载后台服务再切换存储模式": "The background service uses the data directory chosen at install time; uninstall it before switching storage mode",
+	"切换存储模式失败: %w": "Failed to switch storage mode: %w",
+
+	// ---- 指标接口 ----
+	"指标接口启动失败，请检查监听地址是否被占用: %w": "Failed to start the metrics endpoint, check whether the listen address is in use: %w",
+
+	// ---- 按应用分流 ----
+	"未知的按应用分流模式: %d": "Unknown per-app routing mode: %d",
+	"按应用分流需要智能分流模式":  "Per-app routing requires smart routing mode",
+	"请填写按应用分流的程序":    "Please enter the programs for per-app routing",
+
+	// ---- 节点链路 ----
+	"前置节点不存在: %s":           "Upstream node not found: %s",
+	"节点链路存在循环: %s":          "Node chain contains a cycle: %s",
+	"节点链路过长（最多 %d 级）":       "Node chain is too long (at most %d hops)",
+	"不能以节点自身作为前置节点":         "A node cannot use itself as its upstream",
+	"前置节点与上游 SOCKS5 不能同时设置": "Upstream node and upstream SOCKS5 cannot both be set",
+	"前置节点未运行，请先启动前置节点":      "The upstream node is not running, start it first",
+	"启动前置节点失败: %w":          "Failed to start upstream node: %w",
+	"启动前置节点 %s 失败: %w":      "Failed to start upstream node %s: %w",
+	"节点 %s 以该节点为前置节点，请先修改":  "Node %s uses this node as its upstream, change it first",
+
+	// ---- WebDAV 同步 ----
+	"同步地址无效: %s":  "Invalid sync URL: %s",
+	"请设置同步密码":     "Please set a sync passphrase",
+	"未配置同步地址":     "Sync URL is not configured",
+	"无效的同步操作: %s": "Invalid sync action: %s",
+	"同步失败: %w":    "Sync failed: %w",
+	"远端没有同步文件":    "There is no sync file on the server",
+	"同步文件中有 %d 个节点，超过数量上限 %d（可在设置中提高上限）": "The sync file has %d nodes, more than the limit of %d (the limit can be raised in settings)",
+	"WebDAV 地址无效: %s":       "Invalid WebDAV URL: %s",
+	"WebDAV 认证失败 (HTTP %d)": "WebDAV authentication failed (HTTP %d)",
+	"WebDAV 请求失败: HTTP %d":  "WebDAV request failed: HTTP %d",
+	"同步文件超过大小上限":            "Sync file exceeds the size limit",
+	"不是有效的同步文件":             "Not a valid sync file",
+	"同步文件版本 %d 过新，请升级客户端":   "Sync file version %d is too new, please upgrade the client",
+	"解析同步文件失败: %w":          "Failed to parse sync file: %w",
+	"远端同步文件已被其他设备修改":        "The sync file was modified by another device",
+	"数据已损坏":                 "Data is corrupted",
+	"密码错误或数据已损坏":            "Wrong passphrase or corrupted data",
+
+	// ---- 连接策略 ----
+	"连接策略参数不能为负数": "Connection policy values cannot be negative",
+	"启动超时不能超过 %d 秒，停止超时不能超过 %d 秒，握手超时不能超过 %d 秒": "Start timeout cannot exceed %d s, stop timeout cannot exceed %d s, handshake timeout cannot exceed %d s",
+	"重试次数不能超过 %d，重试间隔不能超过 %d 毫秒":                "Retries cannot exceed %d and retry interval cannot exceed %d ms",
+	"节点已停止":      "The node was stopped",
+	"%s 进程启动后退出": "%s process exited during startup",
+
+	// ---- 前端配置检查 ----
+	"%s 配置检查未通过: %s":    "%s config check failed: %s",
+	"%s 配置检查未通过，退出码 %d": "%s config check failed with exit code %d",
+
+	// ---- Xray 配置模板 ----
+	"Xray 配置模板不是有效的 JSON 对象: %w": "The Xray config template is not a valid JSON object: %w",
+	"Xray 配置模板必须是 JSON 对象":       "The Xray config template must be a JSON object",
+
+	// ---- sing-box 前端 ----
+	"未知的前端内核: %s": "Unknown front-end engine: %s",
+
+	// ---- IP 归属 ----
+	"无效的 IP 地址: %s": "Invalid IP address: %s",
+	"缺少 IP 归属数据（geoip.dat 或国家 MMDB），无法判断出口位置": "No IP location data (geoip.dat or a country MMDB) is available to locate the exit",
+
+	// ---- 测速 ----
+	"并发数必须在 0-%d 之间":        "Concurrency must be between 0 and %d",
+	"超时不能为负数":               "Timeout cannot be negative",
+	"未知的测速方式: %s":           "Unknown latency test mode: %s",
+	"测速报告不存在: %s":           "Latency test report not found: %s",
+	"节点还没有测速结果，请先测速":        "The node has no latency test results yet; run a test first",
+	"测速结果中没有可用的服务器，未修改服务器池": "No server passed the latency test; the server pool was left unchanged",
+
+	// ---- 服务器健康探测 ----
+	"探测间隔不能少于 %d 秒": "The probe interval must be at least %d seconds",
+
+	// ---- 端口占用 ----
+	"内部端口 %s 已被节点 %s 占用，请重新启动节点":           "Internal port %s is in use by node %s; start the node again",
+	"内部端口 %s 已被进程 %s (PID %d) 占用，请重新启动节点":  "Internal port %s is in use by process %s (PID %d); start the node again",
+	"内部端口 %s 无法使用（%s），请重新启动节点":             "Internal port %s is unavailable (%s); start the node again",
+	"监听地址 %s 已被节点 %s 占用，可改用端口 %d":          "Listen address %s is in use by node %s; try port %d instead",
+	"监听地址 %s 已被进程 %s (PID %d) 占用，可改用端口 %d": "Listen address %s is in use by process %s (PID %d); try port %d instead",
+	"监听地址 %s 无法使用（%s），可改用端口 %d":            "Listen address %s is unavailable (%s); try port %d instead",
+
+	// ---- 内部端口范围 ----
+	"内部端口范围应在 1024-65535 之间，且起始端口不大于结束端口": "The internal port range must be within 1024-65535 with the start port not greater than the end port",
+	"内部端口范围至少需要包含 %d 个端口":                 "The internal port range must contain at least %d ports",
+	"固定内部端口应在 1-65535 之间":                 "The fixed internal port must be between 1 and 65535",
+	"固定内部端口不能与监听端口相同: %d":                 "The fixed internal port cannot be the same as a listen port: %d",
+	"内部端口范围中没有可用的端口":                      "No port is available in the internal port range",
+
+	// ---- 热重载 ----
+	"节点未运行": "The node is not running",
+
+	// ---- 不代理列表 ----
+	"不代理列表最多 %d 条":    "The proxy bypass list can contain at most %d entries",
+	"不代理列表中的条目无效: %s": "Invalid proxy bypass entry: %s",
+
+	// ---- PAC 系统代理模式 ----
+	"无效的系统代理模式: %d": "Invalid system proxy mode: %d",
+
+	// ---- 自动选择 ----
+	"间隔和阈值不能为负数": "Interval and threshold cannot be negative",
+
+	// ---- 界面语言 ----
+	"不支持的语言: %s": "Unsupported language: %s",
+
+	// ---- 规则数据更新 ----
+	"更新间隔不能为负数":    "The update interval cannot be negative",
+	"下载镜像地址无效: %s": "Invalid download mirror: %s",
+}
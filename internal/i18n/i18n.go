@@ -0,0 +1,110 @@
+// Package i18n 后端消息本地化：以中文原文作为消息 ID，按界面语言查表翻译
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// 语言与消息目录
+// =============================================================================
+
+// DefaultLanguage 源语言（消息 ID 即该语言的原文），未指定或不支持的语言也使用它
+const DefaultLanguage = "zh-CN"
+
+// catalogs 各语言的消息目录：中文原文 -> 译文（格式化占位符需与原文一致）
+var catalogs = map[string]map[string]string{
+	"en-US": enUS,
+}
+
+// Languages 支持的全部语言
+func Languages() []string {
+	return []string{DefaultLanguage, "en-US"}
+}
+
+// Supported 是否为支持的语言
+func Supported(lang string) bool {
+	return lang == DefaultLanguage || catalogs[lang] != nil
+}
+
+// lookup 获取消息在指定语言下的格式串，缺少译文时返回原文
+func lookup(lang, msgID string) string {
+	if msg, ok := catalogs[lang][msgID]; ok {
+		return msg
+	}
+	return msgID
+}
+
+// T 翻译消息并按参数格式化（无参数时不做格式化）
+func T(lang, msgID string, args ...interface{}) string {
+	format := lookup(lang, msgID)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// =============================================================================
+// 可翻译错误
+// =============================================================================
+
+// Error 保留格式串与参数的错误，返回给界面时再按语言渲染
+// Error() 始终返回源语言文本，日志等内部用途不受界面语言影响
+type Error struct {
+	format string
+	args   []interface{}
+}
+
+// Errorf 创建可翻译错误，format 即消息 ID；支持 %w 包装
+func Errorf(format string, args ...interface{}) error {
+	return &Error{format: format, args: args}
+}
+
+// Error 源语言文本
+func (e *Error) Error() string {
+	return e.render(DefaultLanguage)
+}
+
+// Unwrap 返回 %w 包装的错误
+func (e *Error) Unwrap() error {
+	if !strings.Contains(e.format, "%w") {
+		return nil
+	}
+	for _, arg := range e.args {
+		if err, ok := arg.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// render 按指定语言渲染，错误类参数同样翻译
+func (e *Error) render(lang string) string {
+	format := strings.ReplaceAll(lookup(lang, e.format), "%w", "%v")
+	if len(e.args) == 0 {
+		return format
+	}
+
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		if err, ok := arg.(error); ok {
+			args[i] = Translate(err, lang)
+		} else {
+			args[i] = arg
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Translate 将错误翻译为指定语言的文本
+// 可翻译错误按格式串翻译；普通错误按完整文本查表，查不到时原样返回
+func Translate(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	if le, ok := err.(*Error); ok {
+		return le.render(lang)
+	}
+	return lookup(lang, err.Error())
+}
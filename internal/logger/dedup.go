@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/coreproto"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 重复日志折叠
+// =============================================================================
+
+// 内核陷入错误循环（如连接被拒绝后反复重试）时会在短时间内输出成千上万行相同的日志。
+// 同一节点、级别、类别下连续出现相同的消息（忽略行首时间戳）时只记录第一条，之后的重复只计数；
+// 出现不同的消息或距第一次重复超过 DedupWindow 时补记一条 "上一条消息重复了 N 次"，
+// 缓冲区、日志文件和订阅推送看到的都是折叠后的结果。
+// 调试模式下不折叠，原样记录内核输出。
+
+// DedupWindow 持续重复时补记重复次数的最长间隔
+const DedupWindow = 5 * time.Second
+
+// dedupKey 重复判断的范围
+type dedupKey struct {
+	nodeID   string
+	level    string
+	category string
+}
+
+// dedupState 某个范围内最近记录的消息及其之后被折叠的次数
+type dedupState struct {
+	last     models.LogEntry
+	text     string // 去掉时间戳后的消息，用于比较
+	repeated int
+	since    time.Time // 第一次被折叠的时间
+}
+
+// dedupTracker 重复日志状态
+type dedupTracker struct {
+	mu     sync.Mutex
+	debug  bool
+	states map[dedupKey]*dedupState
+}
+
+// SetDebug 开启或关闭调试模式；开启时不再折叠重复日志，并补记已折叠的次数
+func (m *Manager) SetDebug(enabled bool) {
+	m.dedup.mu.Lock()
+	defer m.dedup.mu.Unlock()
+
+	if enabled && !m.dedup.debug {
+		m.flushRepeatsLocked(true)
+		m.dedup.states = nil
+	}
+	m.dedup.debug = enabled
+}
+
+// IsDebug 是否处于调试模式
+func (m *Manager) IsDebug() bool {
+	m.dedup.mu.Lock()
+	defer m.dedup.mu.Unlock()
+	return m.dedup.debug
+}
+
+// record 折叠重复后追加日志条目
+func (m *Manager) record(entry models.LogEntry) {
+	m.dedup.mu.Lock()
+	defer m.dedup.mu.Unlock()
+
+	if m.dedup.debug {
+		m.appendEntry(entry)
+		return
+	}
+
+	key := dedupKey{nodeID: entry.NodeID, level: entry.Level, category: entry.Category}
+	text := coreproto.StripTimestamp(entry.Message)
+	st, ok := m.dedup.states[key]
+	if ok && st.text == text {
+		if st.repeated == 0 {
+			st.since = entry.Timestamp
+		}
+		st.repeated++
+		if entry.Timestamp.Sub(st.since) >= DedupWindow {
+			m.appendRepeat(st)
+		}
+		return
+	}
+
+	if ok && st.repeated > 0 {
+		m.appendRepeat(st)
+	}
+	if m.dedup.states == nil {
+		m.dedup.states = make(map[dedupKey]*dedupState)
+	}
+	m.dedup.states[key] = &dedupState{last: entry, text: text}
+	m.appendEntry(entry)
+}
+
+// flushRepeats 由刷新循环调用，补记重复已超过 DedupWindow 的次数
+func (m *Manager) flushRepeats() {
+	m.dedup.mu.Lock()
+	defer m.dedup.mu.Unlock()
+	m.flushRepeatsLocked(false)
+}
+
+// flushRepeatsLocked all 为 true 时补记全部未记录的重复次数
+func (m *Manager) flushRepeatsLocked(all bool) {
+	now := time.Now()
+	for _, st := range m.dedup.states {
+		if st.repeated > 0 && (all || now.Sub(st.since) >= DedupWindow) {
+			m.appendRepeat(st)
+		}
+	}
+}
+
+// resetRepeats 清空日志时丢弃重复状态，之后的消息重新开始计数
+func (m *Manager) resetRepeats() {
+	m.dedup.mu.Lock()
+	defer m.dedup.mu.Unlock()
+	m.dedup.states = nil
+}
+
+// appendRepeat 追加重复次数条目并重新计数
+func (m *Manager) appendRepeat(st *dedupState) {
+	entry := st.last
+	entry.Timestamp = time.Now()
+	entry.Message = fmt.Sprintf("上一条消息重复了 %d 次", st.repeated)
+	st.repeated = 0
+	m.appendEntry(entry)
+}
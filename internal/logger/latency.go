@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// 延迟历史
+// =============================================================================
+
+// 每次测速完成后按节点追加一条汇总记录（JSON Lines，每行一条），供界面绘制延迟趋势。
+// 追加写入不重写整个文件；超过保留期限或条数的记录在累计足够多后统一清理重写。
+
+const (
+	// LatencyHistoryFileName 延迟历史文件（位于数据目录）
+	LatencyHistoryFileName = "latency_history.jsonl"
+	// LatencyHistoryRetention 延迟记录保留时长
+	LatencyHistoryRetention = 30 * 24 * time.Hour
+	// LatencyHistoryMaxPoints 每个节点最多保留的记录数
+	LatencyHistoryMaxPoints = 2000
+)
+
+// latencyCompactEvery 追加多少条后清理一次过期记录
+const latencyCompactEvery = 500
+
+// LatencyPoint 一次测速的汇总结果
+type LatencyPoint struct {
+	NodeID  string `json:"node_id"`
+	Time    int64  `json:"time"` // 测速完成时间（Unix 秒）
+	Avg     int    `json:"avg"`  // 平均延迟（毫秒），全部失败时为 -1
+	Min     int    `json:"min"`
+	Max     int    `json:"max"`
+	Success int    `json:"success"` // 成功的服务器数
+	Total   int    `json:"total"`
+}
+
+// LatencyHistory 按节点保存的延迟历史
+type LatencyHistory struct {
+	mu       sync.Mutex
+	path     string
+	points   map[string][]LatencyPoint // 节点 → 记录（按时间先后）
+	appended int                       // 上次清理后追加的条数
+}
+
+// NewLatencyHistory 创建延迟历史并加载已有文件（无法解析的行跳过）
+func NewLatencyHistory(path string) *LatencyHistory {
+	h := &LatencyHistory{path: path, points: make(map[string][]LatencyPoint)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var p LatencyPoint
+		if json.Unmarshal(scanner.Bytes(), &p) != nil || p.NodeID == "" {
+			continue
+		}
+		h.points[p.NodeID] = append(h.points[p.NodeID], p)
+	}
+	if h.pruneLocked(time.Now()) {
+		h.rewriteLocked()
+	}
+	return h
+}
+
+// Add 记录一次测速报告
+func (h *LatencyHistory) Add(report PingReport) {
+	if report.NodeID == "" || report.TotalCount == 0 {
+		return
+	}
+	end := report.EndTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+	p := LatencyPoint{
+		NodeID:  report.NodeID,
+		Time:    end.Unix(),
+		Avg:     -1,
+		Min:     -1,
+		Max:     -1,
+		Success: report.SuccessCount,
+		Total:   report.TotalCount,
+	}
+	if report.SuccessCount > 0 {
+		p.Avg, p.Min, p.Max = report.AvgLatency, report.MinLatency, report.MaxLatency
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.points[p.NodeID] = append(h.points[p.NodeID], p)
+	h.appended++
+	if h.appended >= latencyCompactEvery {
+		h.pruneLocked(time.Now())
+		h.rewriteLocked()
+		return
+	}
+	h.appendLocked(p)
+}
+
+// Query 返回节点自 since 起的记录（按时间先后，since 为零值时返回全部保留的记录）
+func (h *LatencyHistory) Query(nodeID string, since time.Time) []LatencyPoint {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make([]LatencyPoint, 0)
+	for _, p := range h.points[nodeID] {
+		if since.IsZero() || p.Time >= since.Unix() {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Clear 删除节点的记录，nodeID 为空时全部删除
+func (h *LatencyHistory) Clear(nodeID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if nodeID == "" {
+		h.points = make(map[string][]LatencyPoint)
+		h.appended = 0
+		os.Remove(h.path)
+		return
+	}
+	if _, ok := h.points[nodeID]; !ok {
+		return
+	}
+	delete(h.points, nodeID)
+	h.rewriteLocked()
+}
+
+// pruneLocked 删除过期和超出条数的记录，返回是否有删除
+func (h *LatencyHistory) pruneLocked(now time.Time) bool {
+	cutoff := now.Add(-LatencyHistoryRetention).Unix()
+	pruned := false
+	for id, list := range h.points {
+		start := 0
+		for start < len(list) && list[start].Time < cutoff {
+			start++
+		}
+		if len(list)-start > LatencyHistoryMaxPoints {
+			start = len(list) - LatencyHistoryMaxPoints
+		}
+		if start == 0 {
+			continue
+		}
+		pruned = true
+		if start == len(list) {
+			delete(h.points, id)
+			continue
+		}
+		h.points[id] = append([]LatencyPoint(nil), list[start:]...)
+	}
+	return pruned
+}
+
+// appendLocked 向文件追加一条记录
+func (h *LatencyHistory) appendLocked(p LatencyPoint) {
+	line, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
+
+// rewriteLocked 按内存中的记录重写文件（先写临时文件再替换）
+func (h *LatencyHistory) rewriteLocked() {
+	h.appended = 0
+	var buf bytes.Buffer
+	for _, list := range h.points {
+		for _, p := range list {
+			line, err := json.Marshal(p)
+			if err != nil {
+				continue
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	}
+	if buf.Len() == 0 {
+		os.Remove(h.path)
+		return
+	}
+	tmp := h.path + ".tmp"
+	if os.WriteFile(tmp, buf.Bytes(), 0644) != nil {
+		os.Remove(tmp)
+		return
+	}
+	os.Rename(tmp, h.path)
+}
@@ -12,6 +12,8 @@ import (
 	"sync"
 	"time"
 
+	"xlink-wails/internal/coreproto"
+	"xlink-wails/internal/i18n"
 	"xlink-wails/internal/models"
 )
 
@@ -44,19 +46,71 @@ const (
 	LevelError = "error"
 )
 
-// 日志类别
+// 日志类别（稳定的标识符，显示名称通过 CategoryName 按语言获取）
 const (
-	CategorySystem  = "系统"
-	CategoryEngine  = "内核"
-	CategoryTunnel  = "隧道"
-	CategoryRule    = "规则"
-	CategoryLB      = "负载"
-	CategoryStats   = "统计"
-	CategoryPing    = "测速"
-	CategoryXray    = "Xray"
-	CategoryDNS     = "DNS"
+	CategorySystem  = "system"
+	CategoryEngine  = "engine"
+	CategoryTunnel  = "tunnel"
+	CategoryRule    = "rule"
+	CategoryLB      = "lb"
+	CategoryStats   = "stats"
+	CategoryPing    = "ping"
+	CategoryXray    = "xray"
+	CategorySingBox = "sing-box"
+	CategoryDNS     = "dns"
 )
 
+// DefaultLanguage 未指定或不支持的语言使用的显示语言
+const DefaultLanguage = i18n.DefaultLanguage
+
+// categoryNames 类别的源语言显示名称，其他语言通过 i18n 消息目录翻译
+var categoryNames = map[string]string{
+	CategorySystem:  "系统",
+	CategoryEngine:  "内核",
+	CategoryTunnel:  "隧道",
+	CategoryRule:    "规则",
+	CategoryLB:      "负载",
+	CategoryStats:   "统计",
+	CategoryPing:    "测速",
+	CategoryXray:    "Xray",
+	CategorySingBox: "sing-box",
+	CategoryDNS:     "DNS",
+}
+
+// LogCategory 日志类别及其显示名称
+type LogCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Categories 返回全部类别（按固定顺序）及指定语言的显示名称
+func Categories(lang string) []LogCategory {
+	ids := []string{CategorySystem, CategoryEngine, CategoryTunnel, CategoryRule, CategoryLB,
+		CategoryStats, CategoryPing, CategoryXray, CategorySingBox, CategoryDNS}
+
+	result := make([]LogCategory, len(ids))
+	for i, id := range ids {
+		result[i] = LogCategory{ID: id, Name: CategoryName(id, lang)}
+	}
+	return result
+}
+
+// CategoryName 获取类别在指定语言下的显示名称，未知类别原样返回
+func CategoryName(category, lang string) string {
+	if name, ok := categoryNames[category]; ok {
+		return i18n.T(lang, name)
+	}
+	return category
+}
+
+// Localize 为日志条目填充指定语言的类别显示名称（原地修改并返回）
+func Localize(entries []models.LogEntry, lang string) []models.LogEntry {
+	for i := range entries {
+		entries[i].CategoryName = CategoryName(entries[i].Category, lang)
+	}
+	return entries
+}
+
 // =============================================================================
 // 日志管理器
 // =============================================================================
@@ -74,8 +128,11 @@ type Manager struct {
 	logFile     *os.File
 	logFilePath string
 
-	// 回调函数
-	onNewLog func(entry models.LogEntry)
+	// 日志订阅
+	hub streamHub
+
+	// 重复日志折叠
+	dedup dedupTracker
 
 	// 控制
 	flushTicker *time.Ticker
@@ -92,6 +149,11 @@ type LogParser interface {
 	Parse(line string) (level, category, message string)
 }
 
+// MetricsParser 可从日志行中提取原始数值的解析器（可选），数值随日志条目返回给界面
+type MetricsParser interface {
+	Metrics(line string) map[string]models.Metric
+}
+
 // NewManager 创建日志管理器
 func NewManager(exeDir string) *Manager {
 	m := &Manager{
@@ -172,7 +234,7 @@ func (m *Manager) Log(nodeID, nodeName, level, category, message string) {
 		Message:   message,
 	}
 
-	m.appendEntry(entry)
+	m.record(entry)
 }
 
 // LogSystem 记录系统日志
@@ -198,10 +260,8 @@ func (m *Manager) appendEntry(entry models.LogEntry) {
 	// 写入文件
 	m.writeToFile(entry)
 
-	// 回调通知
-	if m.onNewLog != nil {
-		m.onNewLog(entry)
-	}
+	// 放入订阅队列，由刷新循环批量推送
+	m.publish(entry)
 }
 
 // writeToFile 写入日志文件
@@ -354,10 +414,12 @@ func (m *Manager) GetLogsByLevel(level string, limit int) []models.LogEntry {
 // Clear 清空日志缓冲区
 func (m *Manager) Clear() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	m.buffer = make([]models.LogEntry, BufferSize)
 	m.bufferPos = 0
+	m.mu.Unlock()
+
+	m.resetRepeats()
 }
 
 // =============================================================================
@@ -378,8 +440,29 @@ func (m *Manager) ParseAndLog(nodeID, nodeName, rawLog string) {
 		}
 
 		level, category, message := m.parseLine(line)
-		m.LogNode(nodeID, nodeName, level, category, message)
+		m.record(models.LogEntry{
+			Timestamp: time.Now(),
+			NodeID:    nodeID,
+			NodeName:  nodeName,
+			Level:     level,
+			Category:  category,
+			Message:   message,
+			Metrics:   m.parseMetrics(line),
+		})
+	}
+}
+
+// parseMetrics 由匹配该行的解析器提取原始数值
+func (m *Manager) parseMetrics(line string) map[string]models.Metric {
+	for _, parser := range m.parsers {
+		if parser.CanParse(line) {
+			if mp, ok := parser.(MetricsParser); ok {
+				return mp.Metrics(line)
+			}
+			return nil
+		}
 	}
+	return nil
 }
 
 // parseLine 解析单行日志
@@ -407,8 +490,7 @@ func (m *Manager) parseLine(line string) (level, category, message string) {
 	}
 
 	// 移除常见前缀
-	message = strings.TrimPrefix(message, "[CLI] ")
-	message = strings.TrimPrefix(message, "[Core] ")
+	message = coreproto.StripPrefix(message)
 
 	return
 }
@@ -432,33 +514,15 @@ func defaultParsers() []LogParser {
 type TunnelParser struct{}
 
 func (p *TunnelParser) CanParse(line string) bool {
-	return strings.Contains(line, "Tunnel ->")
+	return coreproto.Classify(line) == coreproto.KindTunnel
 }
 
 func (p *TunnelParser) Parse(line string) (level, category, message string) {
 	level = LevelInfo
 	category = CategoryTunnel
 
-	var sni, real, latency string
-
-	// 提取延迟
-	if idx := strings.Index(line, "Latency:"); idx != -1 {
-		latency = strings.TrimSpace(line[idx+8:])
-	}
-
-	// 提取SNI和真实服务器
-	if idx := strings.Index(line, "Tunnel ->"); idx != -1 {
-		rest := line[idx+9:]
-		parts := strings.Split(rest, ">>>")
-		if len(parts) >= 2 {
-			sni = strings.TrimSpace(strings.Split(parts[0], "(")[0])
-			realPart := strings.TrimSpace(parts[1])
-			real = strings.TrimSpace(strings.Split(realPart, "(")[0])
-		}
-	}
-
-	if sni != "" && real != "" {
-		message = fmt.Sprintf("隧道建立: %s ==> %s [延迟: %s]", sni, real, latency)
+	if t, ok := coreproto.ParseTunnel(line); ok && t.Real != "" {
+		message = fmt.Sprintf("隧道建立: %s ==> %s [延迟: %s]", t.Server, t.Real, t.LatencyText)
 	} else {
 		message = line
 	}
@@ -466,38 +530,28 @@ func (p *TunnelParser) Parse(line string) (level, category, message string) {
 	return
 }
 
+// Metrics 隧道建立延迟（毫秒）
+func (p *TunnelParser) Metrics(line string) map[string]models.Metric {
+	t, ok := coreproto.ParseTunnel(line)
+	if !ok || t.Latency < 0 {
+		return nil
+	}
+	return map[string]models.Metric{"latency": models.NewMetric(t.Latency, models.UnitMilliseconds)}
+}
+
 // RuleHitParser 规则命中解析器
 type RuleHitParser struct{}
 
 func (p *RuleHitParser) CanParse(line string) bool {
-	return strings.Contains(line, "Rule Hit")
+	return coreproto.Classify(line) == coreproto.KindRuleHit
 }
 
 func (p *RuleHitParser) Parse(line string) (level, category, message string) {
 	level = LevelInfo
 	category = CategoryRule
 
-	var target, node, rule string
-
-	if idx := strings.Index(line, "Rule Hit ->"); idx != -1 {
-		rest := line[idx+11:]
-		parts := strings.Split(rest, "|")
-		if len(parts) >= 2 {
-			target = strings.TrimSpace(parts[0])
-
-			sniPart := parts[1]
-			if sniIdx := strings.Index(sniPart, "SNI:"); sniIdx != -1 {
-				nodeRest := sniPart[sniIdx+4:]
-				node = strings.TrimSpace(strings.Split(nodeRest, "(")[0])
-			}
-			if ruleIdx := strings.Index(sniPart, "(Rule:"); ruleIdx != -1 {
-				rule = strings.TrimSuffix(strings.TrimSpace(sniPart[ruleIdx+6:]), ")")
-			}
-		}
-	}
-
-	if target != "" && node != "" {
-		message = fmt.Sprintf("命中: %-25s -> %s (关键词: %s)", target, node, rule)
+	if r, ok := coreproto.ParseRoute(line); ok && r.Server != "" {
+		message = fmt.Sprintf("命中: %-25s -> %s (关键词: %s)", r.Target, r.Server, r.Rule)
 	} else {
 		message = line
 	}
@@ -509,33 +563,21 @@ func (p *RuleHitParser) Parse(line string) (level, category, message string) {
 type LoadBalanceParser struct{}
 
 func (p *LoadBalanceParser) CanParse(line string) bool {
-	return strings.Contains(line, "LB ->")
+	return coreproto.Classify(line) == coreproto.KindLB
 }
 
 func (p *LoadBalanceParser) Parse(line string) (level, category, message string) {
 	level = LevelInfo
 	category = CategoryLB
 
-	var target, node, algo string
-
-	if idx := strings.Index(line, "LB ->"); idx != -1 {
-		rest := line[idx+5:]
-		parts := strings.Split(rest, "|")
-
-		if len(parts) >= 1 {
-			target = strings.TrimSpace(parts[0])
-		}
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if strings.HasPrefix(p, "SNI:") {
-				node = strings.TrimSpace(p[4:])
-			} else if strings.HasPrefix(p, "Algo:") {
-				algo = strings.TrimSpace(p[5:])
-			}
-		}
+	r, ok := coreproto.ParseRoute(line)
+	if !ok || r.Server == "" {
+		message = line
+		return
 	}
 
 	// 翻译策略名称
+	algo := r.Strategy
 	switch algo {
 	case "random":
 		algo = "随机"
@@ -545,12 +587,7 @@ func (p *LoadBalanceParser) Parse(line string) (level, category, message string)
 		algo = "哈希"
 	}
 
-	if target != "" && node != "" {
-		message = fmt.Sprintf("访问: %-25s -> %s (策略: %s)", target, node, algo)
-	} else {
-		message = line
-	}
-
+	message = fmt.Sprintf("访问: %-25s -> %s (策略: %s)", r.Target, r.Server, algo)
 	return
 }
 
@@ -558,36 +595,15 @@ func (p *LoadBalanceParser) Parse(line string) (level, category, message string)
 type StatsParser struct{}
 
 func (p *StatsParser) CanParse(line string) bool {
-	return strings.Contains(line, "[Stats]")
+	return coreproto.Classify(line) == coreproto.KindStats
 }
 
 func (p *StatsParser) Parse(line string) (level, category, message string) {
 	level = LevelInfo
 	category = CategoryStats
 
-	var target, up, down, duration string
-
-	if idx := strings.Index(line, "[Stats]"); idx != -1 {
-		rest := line[idx+7:]
-		parts := strings.Split(rest, "|")
-
-		if len(parts) >= 1 {
-			target = strings.TrimSpace(parts[0])
-		}
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if strings.HasPrefix(p, "Up:") {
-				up = strings.TrimSpace(p[3:])
-			} else if strings.HasPrefix(p, "Down:") {
-				down = strings.TrimSpace(p[5:])
-			} else if strings.HasPrefix(p, "Time:") {
-				duration = strings.TrimSpace(p[5:])
-			}
-		}
-	}
-
-	if target != "" {
-		message = fmt.Sprintf("结束: %s (上行:%s / 下行:%s) 时长:%s", target, up, down, duration)
+	if s, ok := coreproto.ParseStats(line); ok && s.Target != "" {
+		message = fmt.Sprintf("结束: %s (上行:%s / 下行:%s) 时长:%s", s.Target, s.UpText, s.DownText, s.DurationText)
 	} else {
 		message = line
 	}
@@ -595,6 +611,19 @@ func (p *StatsParser) Parse(line string) (level, category, message string) {
 	return
 }
 
+// Metrics 连接的上下行字节数与时长（秒）
+func (p *StatsParser) Metrics(line string) map[string]models.Metric {
+	s, ok := coreproto.ParseStats(line)
+	if !ok || s.Target == "" {
+		return nil
+	}
+	return map[string]models.Metric{
+		"up":       models.NewMetric(s.Up, models.UnitBytes),
+		"down":     models.NewMetric(s.Down, models.UnitBytes),
+		"duration": models.NewMetric(int64(s.Duration/time.Second), models.UnitSeconds),
+	}
+}
+
 // PingParser Ping测试解析器
 type PingParser struct{}
 
@@ -669,14 +698,9 @@ func (p *XrayParser) Parse(line string) (level, category, message string) {
 }
 
 // =============================================================================
-// 回调与控制
+// 刷新与控制
 // =============================================================================
 
-// SetCallback 设置新日志回调
-func (m *Manager) SetCallback(cb func(entry models.LogEntry)) {
-	m.onNewLog = cb
-}
-
 // flushLoop 刷新循环
 func (m *Manager) flushLoop() {
 	m.flushTicker = time.NewTicker(FlushInterval)
@@ -688,6 +712,8 @@ func (m *Manager) flushLoop() {
 			if m.logFile != nil {
 				m.logFile.Sync()
 			}
+			m.flushRepeats()
+			m.flushSubscriptions()
 		case <-m.stopChan:
 			return
 		}
@@ -696,6 +722,11 @@ func (m *Manager) flushLoop() {
 
 // Stop 停止日志管理器
 func (m *Manager) Stop() {
+	// 补记尚未记录的重复次数
+	m.dedup.mu.Lock()
+	m.flushRepeatsLocked(true)
+	m.dedup.mu.Unlock()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -717,9 +748,9 @@ func (m *Manager) Stop() {
 // 日志导出
 // =============================================================================
 
-// ExportToFile 导出日志到文件
-func (m *Manager) ExportToFile(path string, format string) error {
-	logs := m.GetLogs(BufferSize)
+// ExportToFile 导出日志到文件（类别使用指定语言的显示名称）
+func (m *Manager) ExportToFile(path string, format string, lang string) error {
+	logs := Localize(m.GetLogs(BufferSize), lang)
 
 	file, err := os.Create(path)
 	if err != nil {
@@ -743,7 +774,7 @@ func (m *Manager) ExportToFile(path string, format string) error {
 				log.Timestamp.Format("2006-01-02 15:04:05"),
 				log.NodeName,
 				log.Level,
-				log.Category,
+				log.CategoryName,
 				strings.ReplaceAll(log.Message, ",", "，"),
 			)
 			writer.WriteString(line)
@@ -756,7 +787,7 @@ func (m *Manager) ExportToFile(path string, format string) error {
 				log.Timestamp.Format("2006-01-02 15:04:05"),
 				log.NodeName,
 				log.Level,
-				log.Category,
+				log.CategoryName,
 				log.Message,
 			)
 			writer.WriteString(line)
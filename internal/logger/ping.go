@@ -7,13 +7,12 @@ import (
 	"io"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"xlink-wails/internal/coreproto"
 	"xlink-wails/internal/models"
 )
 
@@ -29,6 +28,10 @@ type PingManager struct {
 	// 当前运行的测试
 	mu         sync.Mutex
 	activePing *PingSession
+	mode       string // 测速方式，见 PingMode*
+
+	// 每次测试完成后的回调（单节点测试、批量测试均会触发）
+	onReport func(PingReport)
 }
 
 // PingSession 单次Ping测试会话
@@ -43,18 +46,20 @@ type PingSession struct {
 
 // PingReport 测试报告
 type PingReport struct {
-	NodeID      string              `json:"node_id"`
-	NodeName    string              `json:"node_name"`
-	StartTime   time.Time           `json:"start_time"`
-	EndTime     time.Time           `json:"end_time"`
-	Duration    time.Duration       `json:"duration"`
-	TotalCount  int                 `json:"total_count"`
-	SuccessCount int                `json:"success_count"`
-	FailCount   int                 `json:"fail_count"`
-	AvgLatency  int                 `json:"avg_latency"`
-	MinLatency  int                 `json:"min_latency"`
-	MaxLatency  int                 `json:"max_latency"`
-	Results     []models.PingResult `json:"results"`
+	ID           string              `json:"id"` // <完成时间>-<节点ID>，用于读取保存的报告
+	NodeID       string              `json:"node_id"`
+	NodeName     string              `json:"node_name"`
+	StartTime    time.Time           `json:"start_time"`
+	EndTime      time.Time           `json:"end_time"`
+	Duration     time.Duration       `json:"duration"`
+	TotalCount   int                 `json:"total_count"`
+	SuccessCount int                 `json:"success_count"`
+	FailCount    int                 `json:"fail_count"`
+	AvgLatency   int                 `json:"avg_latency"`
+	MinLatency   int                 `json:"min_latency"`
+	MaxLatency   int                 `json:"max_latency"`
+	Results      []models.PingResult `json:"results"`
+	Mode         string              `json:"mode"` // 测速方式
 }
 
 // NewPingManager 创建Ping管理器
@@ -65,6 +70,25 @@ func NewPingManager(exeDir string, logger *Manager) *PingManager {
 	}
 }
 
+// SetReportCallback 设置测试完成回调（只在测试完整结束时调用，被取消或超时的测试不回调）
+func (pm *PingManager) SetReportCallback(cb func(PingReport)) {
+	pm.onReport = cb
+}
+
+// SetMode 设置测速方式（未知的值按 PingModeCore 处理），对之后开始的测试生效
+func (pm *PingManager) SetMode(mode string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.mode = NormalizePingMode(mode)
+}
+
+// Mode 当前的测速方式
+func (pm *PingManager) Mode() string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return NormalizePingMode(pm.mode)
+}
+
 // =============================================================================
 // Ping 测试执行
 // =============================================================================
@@ -121,7 +145,7 @@ func (pm *PingManager) runPing(
 	node *models.NodeConfig,
 	onResult func(models.PingResult),
 	onComplete func(PingReport),
-) {
+) error {
 	defer close(session.Done)
 	defer func() {
 		pm.mu.Lock()
@@ -131,9 +155,60 @@ func (pm *PingManager) runPing(
 		pm.mu.Unlock()
 	}()
 
+	mode := pm.Mode()
+
 	// 日志
-	pm.logger.LogNode(node.ID, node.Name, LevelInfo, CategoryPing, "开始延迟测试...")
+	if mode == PingModeCore {
+		pm.logger.LogNode(node.ID, node.Name, LevelInfo, CategoryPing, "开始延迟测试...")
+	} else {
+		pm.logger.LogNode(node.ID, node.Name, LevelInfo, CategoryPing, fmt.Sprintf("开始延迟测试（%s）...", pingModeName(mode)))
+	}
+
+	record := func(result models.PingResult) {
+		session.Results = append(session.Results, result)
+
+		// 记录日志
+		if result.Latency >= 0 {
+			pm.logger.LogNode(node.ID, node.Name, LevelInfo, CategoryPing,
+				fmt.Sprintf("%s - 延迟: %dms", result.Server, result.Latency))
+		} else {
+			pm.logger.LogNode(node.ID, node.Name, LevelWarn, CategoryPing,
+				fmt.Sprintf("%s - 失败: %s", result.Server, result.Error))
+		}
+
+		// 回调
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	if mode == PingModeCore {
+		if err := pm.corePing(ctx, node, record); err != nil {
+			return err
+		}
+	} else {
+		pm.probePing(ctx, node, mode, record)
+	}
+
+	// 生成报告
+	report := pm.generateReport(session)
+	report.Mode = mode
+
+	// 记录报告；被取消或超时的测试只有部分结果，不交给报告回调保存
+	pm.logReport(node.ID, node.Name, report)
+	if pm.onReport != nil && ctx.Err() == nil {
+		pm.onReport(report)
+	}
 
+	// 回调
+	if onComplete != nil {
+		onComplete(report)
+	}
+	return nil
+}
+
+// corePing 由 xlink 内核（--ping）完成握手测速，record 在当前 goroutine 中依次调用
+func (pm *PingManager) corePing(ctx context.Context, node *models.NodeConfig, record func(models.PingResult)) error {
 	// 构建命令
 	xlinkPath := filepath.Join(pm.exeDir, "xlink-cli-binary.exe")
 
@@ -160,18 +235,18 @@ func (pm *PingManager) runPing(
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		pm.logger.LogNode(node.ID, node.Name, LevelError, CategoryPing, fmt.Sprintf("创建管道失败: %v", err))
-		return
+		return fmt.Errorf("创建管道失败: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		pm.logger.LogNode(node.ID, node.Name, LevelError, CategoryPing, fmt.Sprintf("创建管道失败: %v", err))
-		return
+		return fmt.Errorf("创建管道失败: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
 		pm.logger.LogNode(node.ID, node.Name, LevelError, CategoryPing, fmt.Sprintf("启动测速失败: %v", err))
-		return
+		return fmt.Errorf("启动测速失败: %w", err)
 	}
 
 	// 读取输出
@@ -189,36 +264,12 @@ func (pm *PingManager) runPing(
 	}()
 
 	for result := range resultChan {
-		session.Results = append(session.Results, result)
-
-		// 记录日志
-		if result.Latency >= 0 {
-			pm.logger.LogNode(node.ID, node.Name, LevelInfo, CategoryPing,
-				fmt.Sprintf("%s - 延迟: %dms", result.Server, result.Latency))
-		} else {
-			pm.logger.LogNode(node.ID, node.Name, LevelWarn, CategoryPing,
-				fmt.Sprintf("%s - 失败: %s", result.Server, result.Error))
-		}
-
-		// 回调
-		if onResult != nil {
-			onResult(result)
-		}
+		record(result)
 	}
 
 	// 等待进程结束
 	cmd.Wait()
-
-	// 生成报告
-	report := pm.generateReport(session)
-
-	// 记录报告
-	pm.logReport(node.ID, node.Name, report)
-
-	// 回调
-	if onComplete != nil {
-		onComplete(report)
-	}
+	return nil
 }
 
 // readPingOutput 读取Ping输出
@@ -226,59 +277,9 @@ func (pm *PingManager) readPingOutput(reader io.Reader, results chan<- models.Pi
 	defer wg.Done()
 
 	scanner := bufio.NewScanner(reader)
-	// 匹配延迟结果的正则
-	delayPattern := regexp.MustCompile(`^(.+?)\s*\|\s*Delay:\s*(\d+)ms`)
-	errorPattern := regexp.MustCompile(`^(.+?)\s*\|\s*Error:\s*(.+)`)
-
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		result := models.PingResult{
-			Latency: -1,
-		}
-
-		// 尝试匹配延迟
-		if matches := delayPattern.FindStringSubmatch(line); len(matches) >= 3 {
-			result.Server = strings.TrimSpace(matches[1])
-			if latency, err := strconv.Atoi(matches[2]); err == nil {
-				result.Latency = latency
-			}
-			results <- result
-			continue
-		}
-
-		// 尝试匹配错误
-		if matches := errorPattern.FindStringSubmatch(line); len(matches) >= 3 {
-			result.Server = strings.TrimSpace(matches[1])
-			result.Error = strings.TrimSpace(matches[2])
+		if result, ok := coreproto.ParsePing(scanner.Text()); ok {
 			results <- result
-			continue
-		}
-
-		// 尝试简单格式
-		if strings.Contains(line, "|") {
-			parts := strings.SplitN(line, "|", 2)
-			if len(parts) == 2 {
-				result.Server = strings.TrimSpace(parts[0])
-				info := strings.TrimSpace(parts[1])
-
-				if strings.HasPrefix(info, "Delay:") {
-					delayStr := strings.TrimSpace(strings.TrimPrefix(info, "Delay:"))
-					delayStr = strings.TrimSuffix(delayStr, "ms")
-					if latency, err := strconv.Atoi(delayStr); err == nil {
-						result.Latency = latency
-					}
-				} else if strings.HasPrefix(info, "Error:") {
-					result.Error = strings.TrimSpace(strings.TrimPrefix(info, "Error:"))
-				}
-
-				if result.Server != "" {
-					results <- result
-				}
-			}
 		}
 	}
 }
@@ -297,6 +298,7 @@ func (pm *PingManager) generateReport(session *PingSession) PingReport {
 	}
 
 	report.Duration = report.EndTime.Sub(report.StartTime)
+	report.ID = pingReportID(report.NodeID, report.EndTime)
 
 	var totalLatency int64
 
@@ -372,50 +374,135 @@ type BatchPingResult struct {
 	Error    string      `json:"error,omitempty"`
 }
 
-// BatchPing 批量测试多个节点
+// PingAndWait 测试单个节点并等待报告（超时后取消测试）
+// 不占用界面发起的单节点测试，测试无法开始时立即返回错误
+func (pm *PingManager) PingAndWait(node *models.NodeConfig, timeout time.Duration) (*PingReport, error) {
+	return pm.pingNode(context.Background(), node, timeout)
+}
+
+// 批量测试选项的默认值与上限
+const (
+	DefaultBatchConcurrency = 4
+	MaxBatchConcurrency     = 32
+	DefaultBatchNodeTimeout = 30 * time.Second
+)
+
+// BatchPingOptions 批量测试选项
+type BatchPingOptions struct {
+	Concurrency int           // 同时测试的节点数，0 使用 DefaultBatchConcurrency
+	NodeTimeout time.Duration // 单个节点的超时，0 使用 DefaultBatchNodeTimeout
+}
+
+// normalized 补全默认值并限制并发上限
+func (o BatchPingOptions) normalized() BatchPingOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultBatchConcurrency
+	}
+	if o.Concurrency > MaxBatchConcurrency {
+		o.Concurrency = MaxBatchConcurrency
+	}
+	if o.NodeTimeout <= 0 {
+		o.NodeTimeout = DefaultBatchNodeTimeout
+	}
+	return o
+}
+
+// BatchPing 批量测试多个节点，最多 opts.Concurrency 个节点同时测试
+// 返回的结果与 nodes 顺序一致；onProgress 按完成顺序逐个调用（不会并发调用），current 为已完成的数量。
+// ctx 取消后正在进行的测试立即结束，尚未开始的节点不再测试，均以“测试已取消”返回。
 func (pm *PingManager) BatchPing(
+	ctx context.Context,
 	nodes []*models.NodeConfig,
+	opts BatchPingOptions,
 	onProgress func(current, total int, result BatchPingResult),
 ) []BatchPingResult {
-	results := make([]BatchPingResult, 0, len(nodes))
+	opts = opts.normalized()
 	total := len(nodes)
+	results := make([]BatchPingResult, total)
 
-	for i, node := range nodes {
-		result := BatchPingResult{
-			NodeID:   node.ID,
-			NodeName: node.Name,
-		}
+	workers := opts.Concurrency
+	if workers > total {
+		workers = total
+	}
 
-		// 创建等待通道
-		done := make(chan PingReport, 1)
+	var progressMu sync.Mutex
+	completed := 0
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				node := nodes[i]
+				result := BatchPingResult{
+					NodeID:   node.ID,
+					NodeName: node.Name,
+				}
 
-		err := pm.StartPing(node, nil, func(report PingReport) {
-			done <- report
-		})
+				report, err := pm.pingNode(ctx, node, opts.NodeTimeout)
+				if err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Report = report
+				}
+				results[i] = result
 
-		if err != nil {
-			result.Error = err.Error()
-		} else {
-			// 等待完成（带超时）
-			select {
-			case report := <-done:
-				result.Report = &report
-			case <-time.After(30 * time.Second):
-				result.Error = "测试超时"
-				pm.StopPing()
+				progressMu.Lock()
+				completed++
+				if onProgress != nil {
+					onProgress(completed, total, result)
+				}
+				progressMu.Unlock()
 			}
-		}
-
-		results = append(results, result)
+		}()
+	}
 
-		if onProgress != nil {
-			onProgress(i+1, total, result)
-		}
+	for i := range nodes {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
 	return results
 }
 
+// pingNode 测试单个节点直到完成、超时或 ctx 取消
+// 与 StartPing 不同，不占用（也不会取消）当前的单节点测试，可同时执行多个
+func (pm *PingManager) pingNode(ctx context.Context, node *models.NodeConfig, timeout time.Duration) (*PingReport, error) {
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("测试已取消")
+	}
+
+	nodeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	session := &PingSession{
+		NodeID:    node.ID,
+		NodeName:  node.Name,
+		StartTime: time.Now(),
+		Cancel:    cancel,
+		Results:   make([]models.PingResult, 0),
+		Done:      make(chan struct{}),
+	}
+
+	var report PingReport
+	if err := pm.runPing(nodeCtx, session, node, nil, func(r PingReport) {
+		report = r
+	}); err != nil {
+		return nil, err
+	}
+
+	// 被终止的测试只有部分结果，不作为有效报告
+	switch {
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("测试已取消")
+	case nodeCtx.Err() != nil:
+		return nil, fmt.Errorf("测试超时")
+	}
+	return &report, nil
+}
+
 // =============================================================================
 // 平台特定函数
 // =============================================================================
@@ -3,9 +3,26 @@
 
 package logger
 
-import "os/exec"
+import (
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
 
 // hideWindow 非Windows平台无需隐藏窗口
 func hideWindow(cmd *exec.Cmd) {
 	// 空实现
 }
+
+// icmpPingArgs 发送单个回显请求的 ping 参数（Linux 的 -W 以秒为单位，macOS 以毫秒为单位）
+func icmpPingArgs(ip net.IP, timeout time.Duration) []string {
+	wait := strconv.Itoa(int(timeout.Seconds()))
+	if runtime.GOOS == "darwin" {
+		wait = strconv.Itoa(int(timeout.Milliseconds()))
+	} else if wait == "0" {
+		wait = "1"
+	}
+	return []string{"-c", "1", "-W", wait, ip.String()}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+func TestGenerateReportStats(t *testing.T) {
+	ok := func(server string, ms int) models.PingResult { return models.PingResult{Server: server, Latency: ms} }
+	fail := func(server string) models.PingResult {
+		return models.PingResult{Server: server, Latency: -1, Error: "timeout"}
+	}
+
+	tests := []struct {
+		name          string
+		results       []models.PingResult
+		success, fail int
+		min, max, avg int
+		order         []string
+	}{
+		{
+			name:    "mixed",
+			results: []models.PingResult{ok("a", 120), fail("b"), ok("c", 30), ok("d", 61)},
+			success: 3, fail: 1, min: 30, max: 120, avg: 70,
+			order: []string{"c", "d", "a", "b"},
+		},
+		{
+			name:    "all failed",
+			results: []models.PingResult{fail("a"), fail("b")},
+			success: 0, fail: 2, min: -1, max: -1, avg: 0,
+			order: []string{"a", "b"},
+		},
+		{
+			name:    "zero latency counts as success",
+			results: []models.PingResult{ok("a", 0)},
+			success: 1, fail: 0, min: 0, max: 0, avg: 0,
+			order: []string{"a"},
+		},
+		{
+			name:    "empty",
+			success: 0, fail: 0, min: -1, max: -1, avg: 0,
+		},
+	}
+	for _, tt := range tests {
+		session := &PingSession{
+			NodeID:    "n1",
+			NodeName:  "Node",
+			StartTime: time.Now().Add(-time.Second),
+			Results:   append([]models.PingResult(nil), tt.results...),
+		}
+		r := (&PingManager{}).generateReport(session)
+
+		if r.TotalCount != len(tt.results) || r.SuccessCount != tt.success || r.FailCount != tt.fail {
+			t.Errorf("%s: counts = %d/%d/%d, want %d/%d/%d", tt.name,
+				r.TotalCount, r.SuccessCount, r.FailCount, len(tt.results), tt.success, tt.fail)
+		}
+		if r.MinLatency != tt.min || r.MaxLatency != tt.max || r.AvgLatency != tt.avg {
+			t.Errorf("%s: min/max/avg = %d/%d/%d, want %d/%d/%d", tt.name,
+				r.MinLatency, r.MaxLatency, r.AvgLatency, tt.min, tt.max, tt.avg)
+		}
+		var order []string
+		for _, res := range r.Results {
+			order = append(order, res.Server)
+		}
+		if !reflect.DeepEqual(order, tt.order) {
+			t.Errorf("%s: order = %v, want %v", tt.name, order, tt.order)
+		}
+		if r.ID == "" || r.Duration <= 0 {
+			t.Errorf("%s: ID = %q, Duration = %v", tt.name, r.ID, r.Duration)
+		}
+	}
+}
@@ -4,8 +4,11 @@
 package logger
 
 import (
+	"net"
 	"os/exec"
+	"strconv"
 	"syscall"
+	"time"
 )
 
 func init() {
@@ -19,3 +22,14 @@ func hideWindow(cmd *exec.Cmd) {
 		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
 	}
 }
+
+// icmpPingArgs 发送单个回显请求的 ping 参数（-w 以毫秒为单位）
+func icmpPingArgs(ip net.IP, timeout time.Duration) []string {
+	args := []string{"-n", "1", "-w", strconv.Itoa(int(timeout.Milliseconds()))}
+	if ip.To4() != nil {
+		args = append(args, "-4")
+	} else {
+		args = append(args, "-6")
+	}
+	return append(args, ip.String())
+}
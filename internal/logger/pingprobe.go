@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// TCP / ICMP 测速
+// =============================================================================
+
+// 内核的 --ping 会完成完整的代理握手，握手失败（密钥错误、ECH 配置被拦截等）时只有失败结果。
+// 此时仍可以用 TCP 连接时间或 ICMP 回显判断服务器是否可达、链路延迟如何：
+//   - TCP：连接服务器端口（节点指定了 IP 时连接该 IP），只计连接建立的时间，不含域名解析；
+//   - ICMP：调用系统 ping 命令发送一个回显请求，服务器或中间网络可能屏蔽 ICMP。
+// 两种方式都不经过代理协议，延迟通常低于内核测速的结果，不能据此判断节点是否可用。
+
+// 测速方式
+const (
+	PingModeCore = "core" // xlink 内核握手测速（默认）
+	PingModeTCP  = "tcp"  // TCP 连接时间
+	PingModeICMP = "icmp" // ICMP 回显
+)
+
+// probeTimeout 单个服务器的探测超时
+const probeTimeout = 5 * time.Second
+
+// icmpReplyPattern ping 输出中的往返时间（"时间=12ms"、"time<1ms"、"time=12.3 ms"）
+var icmpReplyPattern = regexp.MustCompile(`(?i)[=<]\s*(\d+(?:\.\d+)?)\s*ms`)
+
+// IsValidPingMode 是否为支持的测速方式（空值表示默认）
+func IsValidPingMode(mode string) bool {
+	switch mode {
+	case "", PingModeCore, PingModeTCP, PingModeICMP:
+		return true
+	}
+	return false
+}
+
+// NormalizePingMode 补全默认测速方式，未知的值按 PingModeCore 处理
+func NormalizePingMode(mode string) string {
+	if mode == PingModeTCP || mode == PingModeICMP {
+		return mode
+	}
+	return PingModeCore
+}
+
+// pingModeName 测速方式的显示名称
+func pingModeName(mode string) string {
+	switch mode {
+	case PingModeTCP:
+		return "TCP 连接"
+	case PingModeICMP:
+		return "ICMP"
+	}
+	return "内核握手"
+}
+
+// probePing 同时探测节点的全部服务器，record 在当前 goroutine 中依次调用
+func (pm *PingManager) probePing(ctx context.Context, node *models.NodeConfig, mode string, record func(models.PingResult)) {
+	servers := models.SplitServers(node.Server)
+	results := make(chan models.PingResult, len(servers))
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+			results <- ProbeServer(ctx, server, node.IP, mode)
+		}(server)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		record(result)
+	}
+}
+
+// ProbeServer 以 TCP 或 ICMP 方式探测单个服务器；fixedIP 非空时不解析服务器域名，直接探测该地址
+func ProbeServer(ctx context.Context, server, fixedIP, mode string) models.PingResult {
+	result := models.PingResult{Server: server, Latency: -1, IPVersion: "unknown"}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	host, port := splitHostPortDefault(server)
+	ip, err := resolveProbeIP(ctx, host, fixedIP)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if ip.To4() != nil {
+		result.IPVersion = "ipv4"
+	} else {
+		result.IPVersion = "ipv6"
+	}
+
+	var latency time.Duration
+	if mode == PingModeICMP {
+		latency, err = icmpEcho(ctx, ip)
+	} else {
+		latency, err = tcpConnect(ctx, net.JoinHostPort(ip.String(), port))
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Latency = int(latency.Milliseconds())
+	return result
+}
+
+// splitHostPortDefault 解析服务器地址，未写端口时默认 443
+func splitHostPortDefault(server string) (string, string) {
+	if host, port, err := net.SplitHostPort(server); err == nil {
+		return host, port
+	}
+	return strings.Trim(server, "[]"), "443"
+}
+
+// resolveProbeIP 确定探测的地址（优先 IPv4）
+func resolveProbeIP(ctx context.Context, host, fixedIP string) (net.IP, error) {
+	if fixedIP != "" {
+		host = fixedIP
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return nil, fmt.Errorf("解析失败: %v", err)
+	}
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			return addr.IP, nil
+		}
+	}
+	return addrs[0].IP, nil
+}
+
+// tcpConnect 建立 TCP 连接所用的时间
+func tcpConnect(ctx context.Context, addr string) (time.Duration, error) {
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
+
+// icmpEcho 调用系统 ping 命令发送一个回显请求，返回往返时间
+func icmpEcho(ctx context.Context, ip net.IP) (time.Duration, error) {
+	timeout := probeTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	cmd := exec.CommandContext(ctx, "ping", icmpPingArgs(ip, timeout)...)
+	hideWindow(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return 0, fmt.Errorf("无法执行 ping 命令: %v", err)
+		}
+	}
+	if ctx.Err() != nil {
+		return 0, fmt.Errorf("ICMP 超时")
+	}
+	ms, ok := parseICMPReply(string(output))
+	if !ok {
+		return 0, fmt.Errorf("ICMP 无响应（服务器或网络可能屏蔽了 ICMP）")
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+// parseICMPReply 从 ping 输出中取出第一个回复的往返时间（毫秒）
+func parseICMPReply(output string) (float64, bool) {
+	m := icmpReplyPattern.FindStringSubmatch(output)
+	if m == nil {
+		return 0, false
+	}
+	ms, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return math.Round(ms), true
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// 测速报告存储
+// =============================================================================
+
+// 延迟历史只保留汇总数据；完整的报告（每个服务器的结果）按节点保存到数据目录，
+// 便于日后对比同一节点不同时间的测速结果：ping_reports/<节点ID>/<完成时间>.json。
+
+const (
+	// PingReportDirName 测速报告目录（位于数据目录）
+	PingReportDirName = "ping_reports"
+	// PingReportKeep 每个节点最多保留的报告数，超出时删除最旧的
+	PingReportKeep = 200
+	// PingReportRetention 报告保留时长
+	PingReportRetention = 30 * 24 * time.Hour
+)
+
+// pingReportTimeLayout 报告 ID 和文件名中的完成时间（UTC），按名称排序即按时间排序
+const pingReportTimeLayout = "20060102T150405.000"
+
+// pingReportID 报告 ID: <完成时间>-<节点ID>
+func pingReportID(nodeID string, end time.Time) string {
+	return end.UTC().Format(pingReportTimeLayout) + "-" + nodeID
+}
+
+// PingReportStore 测速报告目录，每个报告一个 JSON 文件
+type PingReportStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewPingReportStore 创建报告存储（目录在首次保存时创建）
+func NewPingReportStore(dir string) *PingReportStore {
+	return &PingReportStore{dir: dir}
+}
+
+// Save 保存报告（没有测试任何服务器的报告不保存），并清理该节点过期和超出数量的报告
+func (s *PingReportStore) Save(report PingReport) error {
+	if report.TotalCount == 0 {
+		return nil
+	}
+	report.ID = pingReportID(report.NodeID, report.EndTime)
+	nodeDir, err := s.nodeDir(report.NodeID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(nodeDir, 0755); err != nil {
+		return fmt.Errorf("创建测速报告目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := report.EndTime.UTC().Format(pingReportTimeLayout) + ".json"
+	if err := os.WriteFile(filepath.Join(nodeDir, name), data, 0644); err != nil {
+		return fmt.Errorf("写入测速报告失败: %w", err)
+	}
+
+	cutoff := time.Now().Add(-PingReportRetention).UTC().Format(pingReportTimeLayout)
+	for i, name := range reportNames(nodeDir) {
+		if i >= PingReportKeep || name < cutoff {
+			os.Remove(filepath.Join(nodeDir, name))
+		}
+	}
+	return nil
+}
+
+// List 节点保存的报告（最新的在前），不含每个服务器的结果，完整内容用 Get 读取
+func (s *PingReportStore) List(nodeID string) []PingReport {
+	reports := []PingReport{}
+	nodeDir, err := s.nodeDir(nodeID)
+	if err != nil {
+		return reports
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range reportNames(nodeDir) {
+		r, err := readPingReport(filepath.Join(nodeDir, name))
+		if err != nil {
+			continue
+		}
+		r.Results = nil
+		reports = append(reports, *r)
+	}
+	return reports
+}
+
+// Get 按 ID 读取完整报告
+func (s *PingReportStore) Get(id string) (*PingReport, error) {
+	n := len(pingReportTimeLayout)
+	if len(id) <= n+1 || id[n] != '-' {
+		return nil, os.ErrNotExist
+	}
+	if _, err := time.Parse(pingReportTimeLayout, id[:n]); err != nil {
+		return nil, os.ErrNotExist
+	}
+	nodeDir, err := s.nodeDir(id[n+1:])
+	if err != nil {
+		return nil, os.ErrNotExist
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readPingReport(filepath.Join(nodeDir, id[:n]+".json"))
+}
+
+// Clear 删除节点的全部报告，nodeID 为空时删除所有节点的报告
+func (s *PingReportStore) Clear(nodeID string) error {
+	target := s.dir
+	if nodeID != "" {
+		nodeDir, err := s.nodeDir(nodeID)
+		if err != nil {
+			return err
+		}
+		target = nodeDir
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("删除测速报告失败: %w", err)
+	}
+	return nil
+}
+
+// nodeDir 节点的报告目录（节点 ID 不能包含路径分隔符）
+func (s *PingReportStore) nodeDir(nodeID string) (string, error) {
+	if nodeID == "" || nodeID == "." || nodeID == ".." || strings.ContainsAny(nodeID, `/\`) {
+		return "", fmt.Errorf("无效的节点 ID: %q", nodeID)
+	}
+	return filepath.Join(s.dir, nodeID), nil
+}
+
+// reportNames 目录中的报告文件名（最新的在前）
+func reportNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names
+}
+
+func readPingReport(path string) (*PingReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r PingReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
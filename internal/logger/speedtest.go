@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// 带宽测速
+// =============================================================================
+
+// Ping 只反映握手延迟，这里通过节点的本地 SOCKS5 入站实际下载/上传数据来测量吞吐量。
+
+const (
+	// DefaultSpeedTestDownloadURL 默认下载测试地址
+	DefaultSpeedTestDownloadURL = "https://speed.cloudflare.com/__down?bytes=50000000"
+	// DefaultSpeedTestUploadURL 默认上传测试地址
+	DefaultSpeedTestUploadURL = "https://speed.cloudflare.com/__up"
+	// DefaultSpeedTestUploadBytes 默认上传数据量
+	DefaultSpeedTestUploadBytes = 10 * 1024 * 1024
+	// DefaultSpeedTestDuration 单个方向的最长测试时间，超时后按已传输的数据计算
+	DefaultSpeedTestDuration = 15 * time.Second
+
+	speedProgressInterval = 250 * time.Millisecond
+)
+
+// 测速阶段
+const (
+	SpeedPhaseDownload = "download"
+	SpeedPhaseUpload   = "upload"
+)
+
+// SpeedTestOptions 测速参数
+type SpeedTestOptions struct {
+	DownloadURL string
+	UploadURL   string // 为空时跳过上传测试
+	UploadBytes int64
+	Duration    time.Duration
+}
+
+// SpeedTestProgress 测速进度
+type SpeedTestProgress struct {
+	NodeID string  `json:"node_id"`
+	Phase  string  `json:"phase"`
+	Bytes  int64   `json:"bytes"`
+	Total  int64   `json:"total"` // 未知时为 0
+	Mbps   float64 `json:"mbps"`  // 当前阶段的平均速率
+}
+
+// SpeedTestResult 测速结果
+type SpeedTestResult struct {
+	NodeID        string    `json:"node_id"`
+	NodeName      string    `json:"node_name"`
+	StartTime     time.Time `json:"start_time"`
+	DownloadMbps  float64   `json:"download_mbps"`
+	DownloadBytes int64     `json:"download_bytes"`
+	UploadMbps    float64   `json:"upload_mbps"`
+	UploadBytes   int64     `json:"upload_bytes"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// SpeedTester 带宽测速（同一时间只运行一个测试）
+type SpeedTester struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewSpeedTester 创建测速器
+func NewSpeedTester() *SpeedTester {
+	return &SpeedTester{}
+}
+
+// Begin 开始一次测试，返回的 context 在 Stop 时取消；已有测试在运行时返回错误
+func (st *SpeedTester) Begin(parent context.Context) (context.Context, func(), error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.cancel != nil {
+		return nil, nil, fmt.Errorf("已有测速正在进行")
+	}
+	ctx, cancel := context.WithCancel(parent)
+	st.cancel = cancel
+
+	done := func() {
+		cancel()
+		st.mu.Lock()
+		st.cancel = nil
+		st.mu.Unlock()
+	}
+	return ctx, done, nil
+}
+
+// Stop 停止正在进行的测试
+func (st *SpeedTester) Stop() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.cancel != nil {
+		st.cancel()
+	}
+}
+
+// Running 是否有测试正在进行
+func (st *SpeedTester) Running() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.cancel != nil
+}
+
+// Run 通过 SOCKS5 代理依次测试下载和上传速度
+func (st *SpeedTester) Run(ctx context.Context, socksAddr string, opts SpeedTestOptions, progress func(SpeedTestProgress)) (*SpeedTestResult, error) {
+	if opts.DownloadURL == "" {
+		opts.DownloadURL = DefaultSpeedTestDownloadURL
+	}
+	if opts.UploadBytes <= 0 {
+		opts.UploadBytes = DefaultSpeedTestUploadBytes
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultSpeedTestDuration
+	}
+
+	result := &SpeedTestResult{StartTime: time.Now()}
+
+	n, mbps, err := measureDownload(ctx, speedTestClient(socksAddr, nil), opts, progress)
+	result.DownloadBytes, result.DownloadMbps = n, mbps
+	if err != nil {
+		return result, fmt.Errorf("下载测试失败: %w", err)
+	}
+
+	if opts.UploadURL != "" {
+		n, mbps, err := measureUpload(ctx, socksAddr, opts, progress)
+		result.UploadBytes, result.UploadMbps = n, mbps
+		if err != nil {
+			return result, fmt.Errorf("上传测试失败: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// speedTestClient 经 SOCKS5 代理的 HTTP 客户端，meter 不为空时统计写入代理连接的字节数
+func speedTestClient(socksAddr string, meter *speedMeter) *http.Client {
+	transport := &http.Transport{
+		Proxy:             http.ProxyURL(&url.URL{Scheme: "socks5", Host: socksAddr}),
+		DisableKeepAlives: true,
+	}
+	if meter != nil {
+		var dialer net.Dialer
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &meteredConn{Conn: conn, meter: meter}, nil
+		}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// meteredConn 统计实际写入连接的字节数
+type meteredConn struct {
+	net.Conn
+	meter *speedMeter
+}
+
+func (c *meteredConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.meter.Write(p[:n])
+	return n, err
+}
+
+// measureDownload 下载测试文件，达到最长时间后停止并按已下载量计算
+func measureDownload(ctx context.Context, client *http.Client, opts SpeedTestOptions, progress func(SpeedTestProgress)) (int64, float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.DownloadURL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	meter := newSpeedMeter(SpeedPhaseDownload, resp.ContentLength, start, progress)
+	_, err = io.Copy(io.Discard, io.TeeReader(resp.Body, meter))
+	meter.report()
+
+	// 达到最长测试时间属于正常结束
+	if err != nil && ctx.Err() != context.DeadlineExceeded {
+		return meter.bytes, meter.mbps(), err
+	}
+	if meter.bytes == 0 {
+		return 0, 0, fmt.Errorf("没有收到数据")
+	}
+	return meter.bytes, meter.mbps(), nil
+}
+
+// measureUpload 上传指定大小的数据，达到最长时间后停止并按已发送量计算
+// 按写入代理连接的字节数统计（请求体被读入发送缓冲区时数据还没有发出）
+func measureUpload(ctx context.Context, socksAddr string, opts SpeedTestOptions, progress func(SpeedTestProgress)) (int64, float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	start := time.Now()
+	meter := newSpeedMeter(SpeedPhaseUpload, opts.UploadBytes, start, progress)
+	client := speedTestClient(socksAddr, meter)
+	body := io.LimitReader(zeroReader{}, opts.UploadBytes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.UploadURL, body)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.ContentLength = opts.UploadBytes
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := client.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	meter.report()
+
+	if err != nil && ctx.Err() != context.DeadlineExceeded {
+		return meter.bytes, meter.mbps(), err
+	}
+	if err == nil && resp.StatusCode >= 400 {
+		return meter.bytes, meter.mbps(), fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return meter.bytes, meter.mbps(), nil
+}
+
+// speedMeter 统计传输字节数并定期回调进度
+type speedMeter struct {
+	phase      string
+	total      int64
+	start      time.Time
+	bytes      int64
+	lastReport time.Time
+	progress   func(SpeedTestProgress)
+}
+
+func newSpeedMeter(phase string, total int64, start time.Time, progress func(SpeedTestProgress)) *speedMeter {
+	if total < 0 {
+		total = 0
+	}
+	return &speedMeter{phase: phase, total: total, start: start, progress: progress}
+}
+
+func (m *speedMeter) Write(p []byte) (int, error) {
+	m.bytes += int64(len(p))
+	if time.Since(m.lastReport) >= speedProgressInterval {
+		m.report()
+	}
+	return len(p), nil
+}
+
+func (m *speedMeter) report() {
+	m.lastReport = time.Now()
+	if m.progress != nil {
+		m.progress(SpeedTestProgress{Phase: m.phase, Bytes: m.bytes, Total: m.total, Mbps: m.mbps()})
+	}
+}
+
+// mbps 平均速率（兆比特每秒）
+func (m *speedMeter) mbps() float64 {
+	elapsed := time.Since(m.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(m.bytes) * 8 / elapsed / 1e6
+}
+
+// zeroReader 无限输出零字节，用于生成上传数据
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
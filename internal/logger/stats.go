@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"xlink-wails/internal/coreproto"
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 流量统计管理器
+// =============================================================================
+
+// StatsManager 解析内核的 [Stats] 日志，按节点累计上下行流量
+// 内核在每条连接结束时输出一行 [Stats] 日志（格式见 coreproto）；
+// 智能分流模式下改用 Xray 统计接口的精确计数，日志只用于统计连接数
+type StatsManager struct {
+	mu    sync.RWMutex
+	nodes map[string]*nodeTraffic
+}
+
+// nodeTraffic 节点流量及上次采样值（用于计算速率）
+type nodeTraffic struct {
+	stats        models.TrafficStats
+	lastUpload   int64
+	lastDownload int64
+	lastSample   time.Time
+	dirty        bool
+
+	// 上次收到的内核统计计数，用于计算增量（内核重启后 Since 变化，计数从零开始）
+	coreSince time.Time
+	coreLast  models.CoreTraffic
+}
+
+// NewStatsManager 创建流量统计管理器
+func NewStatsManager() *StatsManager {
+	return &StatsManager{
+		nodes: make(map[string]*nodeTraffic),
+	}
+}
+
+// Record 解析一行统计日志并累加到节点，非统计日志返回 false
+func (sm *StatsManager) Record(nodeID, line string) bool {
+	return sm.record(nodeID, line, true)
+}
+
+// RecordConnection 解析一行统计日志，只累计连接数和最近目标（流量由 RecordCounters 提供）
+func (sm *StatsManager) RecordConnection(nodeID, line string) bool {
+	return sm.record(nodeID, line, false)
+}
+
+func (sm *StatsManager) record(nodeID, line string, bytes bool) bool {
+	s, ok := coreproto.ParseStats(line)
+	if !ok {
+		return false
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	t := sm.getOrCreateLocked(nodeID)
+	if bytes {
+		t.stats.Upload += s.Up
+		t.stats.Download += s.Down
+	}
+	t.stats.Connections++
+	if s.Target != "" {
+		t.stats.LastTarget = s.Target
+	}
+	t.stats.LastUpdate = time.Now()
+	t.dirty = true
+	return true
+}
+
+// RecordCounters 按内核统计接口的累计计数更新节点流量：入站计数之和为节点的上下行流量
+func (sm *StatsManager) RecordCounters(nodeID string, traffic models.CoreTraffic) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	t := sm.getOrCreateLocked(nodeID)
+	last := t.coreLast
+	if !traffic.Since.Equal(t.coreSince) {
+		last = models.CoreTraffic{}
+		t.coreSince = traffic.Since
+	}
+	t.coreLast = traffic
+
+	changed := false
+	for tag, c := range traffic.Inbounds {
+		d := counterDelta(last.Inbounds[tag], c)
+		t.stats.Upload += d.Up
+		t.stats.Download += d.Down
+		changed = addCounter(&t.stats.Inbounds, tag, d) || changed
+	}
+	for tag, c := range traffic.Outbounds {
+		changed = addCounter(&t.stats.Outbounds, tag, counterDelta(last.Outbounds[tag], c)) || changed
+	}
+	if changed {
+		t.stats.LastUpdate = time.Now()
+		t.dirty = true
+	}
+}
+
+// counterDelta 两次累计计数之差（计数变小说明内核已重启，从零算起）
+func counterDelta(last, cur models.ByteCounter) models.ByteCounter {
+	if cur.Up < last.Up || cur.Down < last.Down {
+		return cur
+	}
+	return models.ByteCounter{Up: cur.Up - last.Up, Down: cur.Down - last.Down}
+}
+
+// addCounter 累加标签计数，返回是否有变化
+func addCounter(counters *map[string]models.ByteCounter, tag string, d models.ByteCounter) bool {
+	if d.Up == 0 && d.Down == 0 {
+		return false
+	}
+	if *counters == nil {
+		*counters = make(map[string]models.ByteCounter)
+	}
+	c := (*counters)[tag]
+	c.Up += d.Up
+	c.Down += d.Down
+	(*counters)[tag] = c
+	return true
+}
+
+// Get 获取节点流量统计
+func (sm *StatsManager) Get(nodeID string) models.TrafficStats {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if t, ok := sm.nodes[nodeID]; ok {
+		return t.snapshot()
+	}
+	return models.TrafficStats{NodeID: nodeID}
+}
+
+// GetAll 获取所有节点的流量统计
+func (sm *StatsManager) GetAll() []models.TrafficStats {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	result := make([]models.TrafficStats, 0, len(sm.nodes))
+	for _, t := range sm.nodes {
+		result = append(result, t.snapshot())
+	}
+	return result
+}
+
+// Reset 清零节点流量统计（节点ID为空时清零全部）
+func (sm *StatsManager) Reset(nodeID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if nodeID == "" {
+		sm.nodes = make(map[string]*nodeTraffic)
+		return
+	}
+	delete(sm.nodes, nodeID)
+}
+
+// Sample 计算自上次采样以来的速率，返回有变化的节点统计
+func (sm *StatsManager) Sample() []models.TrafficStats {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	now := time.Now()
+	var changed []models.TrafficStats
+
+	for _, t := range sm.nodes {
+		elapsed := now.Sub(t.lastSample).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+
+		upSpeed := int64(float64(t.stats.Upload-t.lastUpload) / elapsed)
+		downSpeed := int64(float64(t.stats.Download-t.lastDownload) / elapsed)
+
+		// 速率从非零回落到零也需要通知一次
+		if !t.dirty && upSpeed == t.stats.UpSpeed && downSpeed == t.stats.DownSpeed {
+			t.lastSample = now
+			continue
+		}
+
+		t.stats.UpSpeed = upSpeed
+		t.stats.DownSpeed = downSpeed
+		t.lastUpload = t.stats.Upload
+		t.lastDownload = t.stats.Download
+		t.lastSample = now
+		t.dirty = false
+
+		changed = append(changed, t.snapshot())
+	}
+
+	return changed
+}
+
+// snapshot 复制统计（标签计数的 map 之后仍会被修改）
+func (t *nodeTraffic) snapshot() models.TrafficStats {
+	s := t.stats
+	s.Inbounds = copyCounters(t.stats.Inbounds)
+	s.Outbounds = copyCounters(t.stats.Outbounds)
+	return s
+}
+
+func copyCounters(src map[string]models.ByteCounter) map[string]models.ByteCounter {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]models.ByteCounter, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func (sm *StatsManager) getOrCreateLocked(nodeID string) *nodeTraffic {
+	t, ok := sm.nodes[nodeID]
+	if !ok {
+		now := time.Now()
+		t = &nodeTraffic{
+			stats:      models.TrafficStats{NodeID: nodeID, StartTime: now},
+			lastSample: now,
+		}
+		sm.nodes[nodeID] = t
+	}
+	return t
+}
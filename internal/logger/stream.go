@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// 日志订阅
+// =============================================================================
+
+// 前端与 REST 客户端按 models.LogFilter 订阅日志，只推送匹配的条目；
+// 新条目先积攒在订阅的待发送队列中，由刷新循环按 FlushInterval 批量推送，
+// 避免输出频繁的节点逐条刷屏。
+
+const (
+	// StreamMaxPending 每个订阅在两次刷新之间最多积压的条目数，超出时丢弃最旧的
+	StreamMaxPending = 2000
+)
+
+// LogBatch 一次批量推送的日志
+type LogBatch struct {
+	SubscriptionID string            `json:"subscription_id"`
+	Entries        []models.LogEntry `json:"entries"`
+	Dropped        int               `json:"dropped,omitempty"` // 因积压过多被丢弃的条目数
+}
+
+// subscription 日志订阅
+type subscription struct {
+	id      string
+	filter  models.LogFilter
+	search  string // 小写的搜索关键词
+	deliver func(batch LogBatch)
+
+	mu      sync.Mutex
+	pending []models.LogEntry
+	dropped int
+}
+
+// streamHub 订阅表
+type streamHub struct {
+	mu   sync.RWMutex
+	subs map[string]*subscription
+}
+
+// Subscribe 按过滤条件订阅日志，匹配的新条目批量交给 deliver（在刷新协程中调用）
+func (m *Manager) Subscribe(filter models.LogFilter, deliver func(batch LogBatch)) string {
+	sub := &subscription{id: newSubscriptionID(), deliver: deliver}
+	sub.setFilter(filter)
+
+	m.hub.mu.Lock()
+	if m.hub.subs == nil {
+		m.hub.subs = make(map[string]*subscription)
+	}
+	m.hub.subs[sub.id] = sub
+	m.hub.mu.Unlock()
+	return sub.id
+}
+
+// UpdateSubscription 修改订阅的过滤条件（已积压的条目仍按旧条件推送）
+func (m *Manager) UpdateSubscription(id string, filter models.LogFilter) bool {
+	m.hub.mu.RLock()
+	sub, ok := m.hub.subs[id]
+	m.hub.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	sub.setFilter(filter)
+	return true
+}
+
+// Unsubscribe 取消订阅
+func (m *Manager) Unsubscribe(id string) {
+	m.hub.mu.Lock()
+	delete(m.hub.subs, id)
+	m.hub.mu.Unlock()
+}
+
+// Query 按过滤条件查询缓冲区中的日志（按时间顺序，Limit 限制返回最近的条数）
+func (m *Manager) Query(filter models.LogFilter) []models.LogEntry {
+	limit := filter.Limit
+	if limit <= 0 || limit > BufferSize {
+		limit = BufferSize
+	}
+	search := strings.ToLower(filter.Search)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := m.bufferPos
+	if count > BufferSize {
+		count = BufferSize
+	}
+
+	var result []models.LogEntry
+	for i := 0; i < count && len(result) < limit; i++ {
+		entry := m.buffer[(m.bufferPos-1-i+BufferSize)%BufferSize]
+		if matchFilter(&entry, &filter, search) {
+			result = append(result, entry)
+		}
+	}
+
+	// 反转为时间顺序
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// MatchFilter 判断日志条目是否匹配过滤条件
+func MatchFilter(entry *models.LogEntry, filter *models.LogFilter) bool {
+	return matchFilter(entry, filter, strings.ToLower(filter.Search))
+}
+
+func matchFilter(entry *models.LogEntry, filter *models.LogFilter, search string) bool {
+	if filter.NodeID != "" && entry.NodeID != filter.NodeID {
+		return false
+	}
+	if len(filter.Levels) > 0 && !containsString(filter.Levels, entry.Level) {
+		return false
+	}
+	if len(filter.Categories) > 0 && !containsString(filter.Categories, entry.Category) {
+		return false
+	}
+	if filter.StartTime != nil && entry.Timestamp.Before(*filter.StartTime) {
+		return false
+	}
+	if filter.EndTime != nil && entry.Timestamp.After(*filter.EndTime) {
+		return false
+	}
+	if search != "" {
+		return strings.Contains(strings.ToLower(entry.Message), search) ||
+			strings.Contains(strings.ToLower(entry.NodeName), search) ||
+			strings.Contains(strings.ToLower(entry.Category), search) ||
+			strings.Contains(strings.ToLower(CategoryName(entry.Category, DefaultLanguage)), search)
+	}
+	return true
+}
+
+// publish 把新条目放入匹配订阅的待发送队列
+func (m *Manager) publish(entry models.LogEntry) {
+	m.hub.mu.RLock()
+	defer m.hub.mu.RUnlock()
+
+	for _, sub := range m.hub.subs {
+		sub.mu.Lock()
+		if matchFilter(&entry, &sub.filter, sub.search) {
+			if len(sub.pending) >= StreamMaxPending {
+				sub.pending = sub.pending[1:]
+				sub.dropped++
+			}
+			sub.pending = append(sub.pending, entry)
+		}
+		sub.mu.Unlock()
+	}
+}
+
+// flushSubscriptions 批量推送各订阅积压的条目
+func (m *Manager) flushSubscriptions() {
+	m.hub.mu.RLock()
+	subs := make([]*subscription, 0, len(m.hub.subs))
+	for _, sub := range m.hub.subs {
+		subs = append(subs, sub)
+	}
+	m.hub.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.mu.Lock()
+		batch := LogBatch{SubscriptionID: sub.id, Entries: sub.pending, Dropped: sub.dropped}
+		sub.pending = nil
+		sub.dropped = 0
+		sub.mu.Unlock()
+
+		if len(batch.Entries) > 0 {
+			sub.deliver(batch)
+		}
+	}
+}
+
+func (s *subscription) setFilter(filter models.LogFilter) {
+	s.mu.Lock()
+	s.filter = filter
+	s.search = strings.ToLower(filter.Search)
+	s.mu.Unlock()
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newSubscriptionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Prometheus 文本格式
+// =============================================================================
+
+// family 一组同名指标
+type family struct {
+	w    io.Writer
+	name string
+}
+
+// newFamily 写入 HELP 和 TYPE 行
+func newFamily(w io.Writer, name, typ, help string) family {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	return family{w: w, name: name}
+}
+
+// sample 写入一个样本，labels 为成对的标签名和值
+func (f family) sample(value float64, labels ...string) {
+	var sb strings.Builder
+	sb.WriteString(f.name)
+	if len(labels) > 0 {
+		sb.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(labels[i])
+			sb.WriteString(`="`)
+			sb.WriteString(escapeLabel(labels[i+1]))
+			sb.WriteByte('"')
+		}
+		sb.WriteByte('}')
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	sb.WriteByte('\n')
+	io.WriteString(f.w, sb.String())
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Write 以 Prometheus 文本格式写出快照
+func Write(w io.Writer, s Snapshot) {
+	newFamily(w, "xlink_info", "gauge", "Xlink client version").
+		sample(1, "version", s.Version)
+
+	nodeLabels := func(n NodeSample) []string { return []string{"node", n.ID, "name", n.Name} }
+	with := func(labels []string, extra ...string) []string {
+		return append(append([]string(nil), labels...), extra...)
+	}
+
+	f := newFamily(w, "xlink_node_up", "gauge", "Whether the node is running (1) or not (0)")
+	for _, n := range s.Nodes {
+		f.sample(boolValue(n.Running), nodeLabels(n)...)
+	}
+	f = newFamily(w, "xlink_node_status", "gauge", "Current node status, the status label carries the value")
+	for _, n := range s.Nodes {
+		f.sample(1, with(nodeLabels(n), "status", n.Status)...)
+	}
+	f = newFamily(w, "xlink_node_upload_bytes_total", "counter", "Bytes sent through the node")
+	for _, n := range s.Nodes {
+		f.sample(float64(n.UploadBytes), nodeLabels(n)...)
+	}
+	f = newFamily(w, "xlink_node_download_bytes_total", "counter", "Bytes received through the node")
+	for _, n := range s.Nodes {
+		f.sample(float64(n.DownloadBytes), nodeLabels(n)...)
+	}
+	f = newFamily(w, "xlink_node_upload_speed_bytes", "gauge", "Current upload speed in bytes per second")
+	for _, n := range s.Nodes {
+		f.sample(float64(n.UpSpeed), nodeLabels(n)...)
+	}
+	f = newFamily(w, "xlink_node_download_speed_bytes", "gauge", "Current download speed in bytes per second")
+	for _, n := range s.Nodes {
+		f.sample(float64(n.DownSpeed), nodeLabels(n)...)
+	}
+	f = newFamily(w, "xlink_node_connections_total", "counter", "Connections closed on the node")
+	for _, n := range s.Nodes {
+		f.sample(float64(n.Connections), nodeLabels(n)...)
+	}
+	f = newFamily(w, "xlink_node_restarts_total", "counter", "Automatic engine restarts after abnormal exits")
+	for _, n := range s.Nodes {
+		f.sample(float64(n.Restarts), nodeLabels(n)...)
+	}
+
+	// 延迟只输出测试过的节点
+	f = newFamily(w, "xlink_node_ping_latency_seconds", "gauge", "Server latency from the last ping test")
+	for _, n := range s.Nodes {
+		if n.PingAt.IsZero() || n.PingSuccess == 0 {
+			continue
+		}
+		f.sample(float64(n.PingAvgMs)/1000, with(nodeLabels(n), "stat", "avg")...)
+		f.sample(float64(n.PingMinMs)/1000, with(nodeLabels(n), "stat", "min")...)
+		f.sample(float64(n.PingMaxMs)/1000, with(nodeLabels(n), "stat", "max")...)
+	}
+	f = newFamily(w, "xlink_node_ping_servers", "gauge", "Servers that answered or failed in the last ping test")
+	for _, n := range s.Nodes {
+		if n.PingAt.IsZero() {
+			continue
+		}
+		f.sample(float64(n.PingSuccess), with(nodeLabels(n), "result", "success")...)
+		f.sample(float64(n.PingFailed), with(nodeLabels(n), "result", "failed")...)
+	}
+	f = newFamily(w, "xlink_node_ping_timestamp_seconds", "gauge", "Unix time of the last ping test")
+	for _, n := range s.Nodes {
+		if n.PingAt.IsZero() {
+			continue
+		}
+		f.sample(float64(n.PingAt.Unix()), nodeLabels(n)...)
+	}
+
+	f = newFamily(w, "xlink_fakeip_pool_used", "gauge", "Fake-IP addresses currently mapped")
+	for _, p := range s.FakeIP {
+		f.sample(float64(p.Used), "family", p.Family)
+	}
+	f = newFamily(w, "xlink_fakeip_pool_size", "gauge", "Fake-IP pool capacity")
+	for _, p := range s.FakeIP {
+		f.sample(float64(p.Size), "family", p.Family)
+	}
+}
@@ -0,0 +1,160 @@
+// Package metrics 以 Prometheus 文本格式发布运行指标，供 Prometheus 抓取后在 Grafana 等工具中绘图
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// 常量
+// =============================================================================
+
+const (
+	DefaultListen = "127.0.0.1:9091"
+
+	// Path 指标地址
+	Path = "/metrics"
+
+	// contentType Prometheus 文本格式 0.0.4
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// =============================================================================
+// 指标快照
+// =============================================================================
+
+// NodeSample 单个节点的指标
+type NodeSample struct {
+	ID      string
+	Name    string
+	Status  string // models.Status*
+	Running bool
+
+	UploadBytes   int64 // 累计上行字节
+	DownloadBytes int64 // 累计下行字节
+	UpSpeed       int64 // 上行速率（字节/秒）
+	DownSpeed     int64 // 下行速率（字节/秒）
+	Connections   int   // 已结束的连接数
+	Restarts      int   // 异常退出后的自动重启次数
+
+	// 最近一次延迟测试（PingAt 为零时未测试过）
+	PingAt      time.Time
+	PingAvgMs   int
+	PingMinMs   int
+	PingMaxMs   int
+	PingSuccess int
+	PingFailed  int
+}
+
+// FakeIPPool Fake-IP 地址池使用情况
+type FakeIPPool struct {
+	Family string // ipv4 / ipv6
+	Used   int
+	Size   int
+}
+
+// Snapshot 一次抓取时的全部指标
+type Snapshot struct {
+	Version string
+	Nodes   []NodeSample
+	FakeIP  []FakeIPPool
+}
+
+// Source 每次抓取时调用，返回当前指标
+type Source func() Snapshot
+
+// =============================================================================
+// 服务器
+// =============================================================================
+
+// Server 指标 HTTP 服务器（只读，不需要令牌）
+type Server struct {
+	mu       sync.Mutex
+	source   Source
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer 创建指标服务器
+func NewServer(source Source) *Server {
+	return &Server{source: source}
+}
+
+// Start 在指定地址启动服务（已运行时先停止）
+func (s *Server) Start(listen string) error {
+	if listen == "" {
+		listen = DefaultListen
+	}
+
+	s.Stop()
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("指标接口监听失败: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, s.handle)
+
+	s.mu.Lock()
+	s.listener = ln
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv := s.server
+	s.mu.Unlock()
+
+	go srv.Serve(ln)
+	return nil
+}
+
+// Stop 停止服务
+func (s *Server) Stop() {
+	s.mu.Lock()
+	srv := s.server
+	s.server = nil
+	s.listener = nil
+	s.mu.Unlock()
+
+	if srv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}
+}
+
+// IsRunning 是否正在运行
+func (s *Server) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server != nil
+}
+
+// Addr 实际监听地址
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var buf bytes.Buffer
+	Write(&buf, s.source())
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
@@ -5,8 +5,16 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"xlink-wails/internal/i18n"
 )
 
 // =============================================================================
@@ -17,8 +25,10 @@ const (
 	AppVersion = "22.0.0"
 	AppTitle   = "Xlink客户端 v" + AppVersion
 
-	MaxNodes    = 50
-	MaxRules    = 300
+	// 节点与每个节点规则数量的默认上限（可在设置中调整，见 LimitSettings）
+	DefaultMaxNodes = 50
+	DefaultMaxRules = 300
+
 	MaxNameLen  = 128
 	MaxURLLen   = 8192
 	MaxRulesLen = 16384
@@ -30,6 +40,19 @@ const (
 	RoutingModeSmart  = 1 // 智能分流
 )
 
+// 智能分流前端内核（负责分流、DNS 和 TUN，代理流量交给 Xlink 核心）
+const (
+	FrontEngineXray    = "xray"     // Xray（默认）
+	FrontEngineSingBox = "sing-box" // sing-box（Windows 下 TUN 更稳定）
+)
+
+// 按应用分流模式（智能分流模式下由内核按连接所属进程匹配，TUN 模式下可接管所有程序）
+const (
+	AppRoutingOff     = 0 // 不按应用分流
+	AppRoutingInclude = 1 // 仅列表中的程序走代理，其余程序直连
+	AppRoutingExclude = 2 // 列表中的程序直连，其余程序按规则分流
+)
+
 // 负载均衡策略
 const (
 	StrategyRandom = 0 // 随机
@@ -46,10 +69,39 @@ const (
 
 // 节点运行状态
 const (
-	StatusStopped  = "stopped"
-	StatusStarting = "starting"
-	StatusRunning  = "running"
-	StatusError    = "error"
+	StatusStopped    = "stopped"
+	StatusStarting   = "starting"
+	StatusRunning    = "running"
+	StatusError      = "error"
+	StatusRestarting = "restarting" // 异常退出后等待自动重启
+)
+
+// 本地入站协议
+const (
+	InboundSocks     = 0 // 仅 SOCKS5
+	InboundSocksHTTP = 1 // SOCKS5 + 独立的 HTTP 端口 (HTTPListen)
+	InboundMixed     = 2 // 同一端口同时接受 SOCKS5 和 HTTP
+)
+
+// 系统代理写入模式
+const (
+	SystemProxyModeSocks       = 0 // 仅写入 socks= 条目
+	SystemProxyModePerProtocol = 1 // 分协议写入 http/https/ftp 与 socks 条目（兼容忽略 socks= 的旧程序）
+	SystemProxyModePAC         = 2 // 设置自动配置地址指向本地 PAC 服务，只有规则需要代理的域名经过代理
+)
+
+// 应用内部 HTTP 请求（泄露测试、规则数据下载等）的出口策略
+const (
+	EgressDirect     = 0 // 直连
+	EgressActiveNode = 1 // 经当前运行的节点
+	EgressNode       = 2 // 经指定节点（EgressNodeID）
+)
+
+// 节点列表排序
+const (
+	NodeSortName   = "name"   // 按名称
+	NodeSortStatus = "status" // 运行中的在前
+	NodeSortListen = "listen" // 按监听地址
 )
 
 // IP版本偏好
@@ -72,11 +124,520 @@ type RoutingRule struct {
 	Target string `json:"target"` // 目标节点
 }
 
+// RuleGroup 全局规则组，节点通过 ID 引用，修改后所有引用它的节点同时生效
+type RuleGroup struct {
+	ID    string        `json:"id"`   // 唯一ID (UUID)
+	Name  string        `json:"name"` // 组名
+	Rules []RoutingRule `json:"rules"`
+}
+
+// ScheduleEntry 定时任务：按时间表达式对节点执行控制命令
+type ScheduleEntry struct {
+	ID      string `json:"id"`      // 唯一ID (UUID)
+	Name    string `json:"name"`    // 任务名称
+	Enabled bool   `json:"enabled"` // 是否启用
+	Spec    string `json:"spec"`    // 时间表达式: "HH:MM" 或五段式 cron（分 时 日 月 周）
+	Action  string `json:"action"`  // 命令: start / stop / switch / stop-all
+	NodeID  string `json:"node_id"` // 目标节点（stop-all 时为空）
+}
+
+// Subscription 订阅：刷新时与上次导入的节点比对，更新该订阅的节点并记录变化
+type Subscription struct {
+	ID          string                `json:"id"`           // 唯一ID (UUID)
+	Name        string                `json:"name"`         // 订阅名称
+	URL         string                `json:"url"`          // 订阅地址
+	LastRefresh int64                 `json:"last_refresh"` // 上次刷新时间（Unix 秒）
+	History     []SubscriptionRefresh `json:"history"`      // 最近的刷新记录（最新的在前）
+}
+
+// MaxSubscriptionHistory 每个订阅保留的刷新记录数
+const MaxSubscriptionHistory = 10
+
+// SubscriptionRefresh 一次订阅刷新的记录
+type SubscriptionRefresh struct {
+	Time   int64            `json:"time"`            // Unix 秒
+	Format string           `json:"format"`          // 识别出的内容格式
+	Error  string           `json:"error,omitempty"` // 刷新失败的原因
+	Diff   SubscriptionDiff `json:"diff"`
+}
+
+// SubscriptionDiff 刷新前后订阅节点的变化
+type SubscriptionDiff struct {
+	Added    []NodeChange `json:"added"`
+	Removed  []NodeChange `json:"removed"`
+	Modified []NodeChange `json:"modified"`
+	Skipped  int          `json:"skipped,omitempty"` // 超出节点数量上限未添加的新节点数
+}
+
+// Empty 节点列表是否没有变化
+func (d SubscriptionDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// NodeChange 订阅中一个节点的变化
+type NodeChange struct {
+	Name      string   `json:"name"`
+	Server    string   `json:"server"`               // 刷新后的服务器（删除时为原服务器）
+	OldServer string   `json:"old_server,omitempty"` // 服务器有变化时为原服务器
+	Fields    []string `json:"fields,omitempty"`     // 有变化的字段 (server / ip / token / secret_key / fallback_ip)
+}
+
+// NotificationSettings 通知渠道与事件路由
+type NotificationSettings struct {
+	Routes          map[string][]string `json:"routes"`           // 事件类型 -> 渠道，未配置的事件使用默认渠道
+	DefaultChannels []string            `json:"default_channels"` // 默认渠道，为空(null)时使用系统通知 + 通知中心
+	WebhookURL      string              `json:"webhook_url"`      // Webhook 地址（POST JSON）
+	TelegramToken   string              `json:"telegram_token"`   // Telegram Bot Token
+	TelegramChatID  string              `json:"telegram_chat_id"` // Telegram 会话ID
+	Webhooks        []WebhookSettings   `json:"webhooks"`         // 自定义 Webhook（与 WebhookURL 一起组成 Webhook 渠道）
+}
+
+// WebhookSettings 自定义 Webhook：按模板生成请求体，可接入 Telegram / Discord / Slack 等机器人
+type WebhookSettings struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Template    string   `json:"template"`     // 请求体模板（Go text/template），为空时发送默认 JSON
+	ContentType string   `json:"content_type"` // 为空时为 application/json
+	Events      []string `json:"events"`       // 只发送这些事件，为空时发送路由到 Webhook 渠道的全部事件
+}
+
+// CoreUpdateSettings 内核程序自动更新
+type CoreUpdateSettings struct {
+	Feeds      []string `json:"feeds"`       // 更新源清单地址，按顺序优先
+	AutoUpdate bool     `json:"auto_update"` // 定期检查并自动安装（会短暂重启受影响的节点）
+}
+
+// RestartPolicy 核心进程异常退出后的自动重启策略（对开启 AutoRestart 的节点生效）
+// 连续重启的等待时间从 InitialDelay 开始逐次翻倍，不超过 MaxDelay；稳定运行一段时间后重新计数
+type RestartPolicy struct {
+	MaxRetries   int `json:"max_retries"`   // 连续重启次数上限，0 使用默认值
+	InitialDelay int `json:"initial_delay"` // 首次重启前等待（秒），0 使用默认值
+	MaxDelay     int `json:"max_delay"`     // 等待上限（秒），0 使用默认值
+}
+
+// 自动重启策略默认值
+const (
+	DefaultRestartMaxRetries   = 5
+	DefaultRestartInitialDelay = 2
+	DefaultRestartMaxDelay     = 60
+)
+
+// Normalize 填充未设置的字段
+func (p RestartPolicy) Normalize() RestartPolicy {
+	if p.MaxRetries <= 0 {
+		p.MaxRetries = DefaultRestartMaxRetries
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = DefaultRestartInitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRestartMaxDelay
+	}
+	if p.MaxDelay < p.InitialDelay {
+		p.MaxDelay = p.InitialDelay
+	}
+	return p
+}
+
+// Delay 第 attempt 次（从 1 开始）重启前的等待时间
+func (p RestartPolicy) Delay(attempt int) time.Duration {
+	delay := time.Duration(p.InitialDelay) * time.Second
+	max := time.Duration(p.MaxDelay) * time.Second
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// ConnectionPolicy 节点启动 / 停止超时与连接重试策略
+// AppConfig 中为全局设置，节点可单独覆盖：节点中为 0 的字段使用全局值，全局为 0 的字段使用默认值
+type ConnectionPolicy struct {
+	StartTimeout     int `json:"start_timeout"`      // 启动后等待本地入站可连接的最长时间（秒），超时视为启动失败
+	StopTimeout      int `json:"stop_timeout"`       // 停止时等待内核进程退出的时间（秒）
+	DialRetries      int `json:"dial_retries"`       // 限速转发连接内核入站失败后的重试次数
+	RetryInterval    int `json:"retry_interval"`     // 首次重试前等待（毫秒），之后逐次翻倍
+	MaxRetryInterval int `json:"max_retry_interval"` // 重试等待上限（毫秒）
+	HandshakeTimeout int `json:"handshake_timeout"`  // 入站握手超时（秒），写入智能分流的 Xray 策略，0 使用内核默认值
+}
+
+// 连接策略默认值与上限
+const (
+	DefaultStartTimeout     = 10
+	DefaultStopTimeout      = 2
+	DefaultDialRetries      = 8
+	DefaultRetryInterval    = 100
+	DefaultMaxRetryInterval = 1000
+
+	MaxStartTimeout     = 300
+	MaxStopTimeout      = 60
+	MaxDialRetries      = 100
+	MaxRetryIntervalMs  = 60000
+	MaxHandshakeTimeout = 300
+)
+
+// Override 用 node 中非 0 的字段覆盖全局策略
+func (p ConnectionPolicy) Override(node ConnectionPolicy) ConnectionPolicy {
+	pick := func(global *int, v int) {
+		if v > 0 {
+			*global = v
+		}
+	}
+	pick(&p.StartTimeout, node.StartTimeout)
+	pick(&p.StopTimeout, node.StopTimeout)
+	pick(&p.DialRetries, node.DialRetries)
+	pick(&p.RetryInterval, node.RetryInterval)
+	pick(&p.MaxRetryInterval, node.MaxRetryInterval)
+	pick(&p.HandshakeTimeout, node.HandshakeTimeout)
+	return p
+}
+
+// Normalize 填充未设置的字段（握手超时保持 0，由内核决定）
+func (p ConnectionPolicy) Normalize() ConnectionPolicy {
+	if p.StartTimeout <= 0 {
+		p.StartTimeout = DefaultStartTimeout
+	}
+	if p.StopTimeout <= 0 {
+		p.StopTimeout = DefaultStopTimeout
+	}
+	if p.DialRetries <= 0 {
+		p.DialRetries = DefaultDialRetries
+	}
+	if p.RetryInterval <= 0 {
+		p.RetryInterval = DefaultRetryInterval
+	}
+	if p.MaxRetryInterval <= 0 {
+		p.MaxRetryInterval = DefaultMaxRetryInterval
+	}
+	if p.MaxRetryInterval < p.RetryInterval {
+		p.MaxRetryInterval = p.RetryInterval
+	}
+	return p
+}
+
+// RetryDelay 第 attempt 次（从 1 开始）重试前的等待时间
+func (p ConnectionPolicy) RetryDelay(attempt int) time.Duration {
+	delay := time.Duration(p.RetryInterval) * time.Millisecond
+	max := time.Duration(p.MaxRetryInterval) * time.Millisecond
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// ValidateConnectionPolicy 验证连接策略（0 表示使用全局值或默认值）
+func ValidateConnectionPolicy(p ConnectionPolicy) error {
+	if p.StartTimeout < 0 || p.StopTimeout < 0 || p.DialRetries < 0 || p.RetryInterval < 0 ||
+		p.MaxRetryInterval < 0 || p.HandshakeTimeout < 0 {
+		return i18n.Errorf("连接策略参数不能为负数")
+	}
+	if p.StartTimeout > MaxStartTimeout || p.StopTimeout > MaxStopTimeout || p.HandshakeTimeout > MaxHandshakeTimeout {
+		return i18n.Errorf("启动超时不能超过 %d 秒，停止超时不能超过 %d 秒，握手超时不能超过 %d 秒",
+			MaxStartTimeout, MaxStopTimeout, MaxHandshakeTimeout)
+	}
+	if p.DialRetries > MaxDialRetries || p.RetryInterval > MaxRetryIntervalMs || p.MaxRetryInterval > MaxRetryIntervalMs {
+		return i18n.Errorf("重试次数不能超过 %d，重试间隔不能超过 %d 毫秒", MaxDialRetries, MaxRetryIntervalMs)
+	}
+	return nil
+}
+
+// PortRange 内部端口范围：智能分流时 Xlink 核心与前端内核之间的端口、前端内核的统计接口和限速转发的内部地址
+// 从中分配（企业防火墙常限制系统随机分配的 49152-65535），Min 与 Max 都为 0 时由系统分配
+type PortRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// MinPortRangeSize 内部端口范围至少包含的端口数（每个智能分流节点占用 2 个，限速时再加 1-2 个）
+const MinPortRangeSize = 16
+
+// ValidatePortRange 验证内部端口范围
+func ValidatePortRange(r PortRange) error {
+	if r.Min == 0 && r.Max == 0 {
+		return nil
+	}
+	if r.Min < 1024 || r.Max > 65535 || r.Min > r.Max {
+		return i18n.Errorf("内部端口范围应在 1024-65535 之间，且起始端口不大于结束端口")
+	}
+	if r.Max-r.Min+1 < MinPortRangeSize {
+		return i18n.Errorf("内部端口范围至少需要包含 %d 个端口", MinPortRangeSize)
+	}
+	return nil
+}
+
+// ValidateFixedInternalPort 验证节点的固定内部端口（0 表示从内部端口范围中分配）
+func ValidateFixedInternalPort(node *NodeConfig) error {
+	if node.FixedInternalPort == 0 {
+		return nil
+	}
+	if node.FixedInternalPort < 1 || node.FixedInternalPort > 65535 {
+		return i18n.Errorf("固定内部端口应在 1-65535 之间")
+	}
+	for _, listen := range []string{node.Listen, node.HTTPListen} {
+		if _, port, err := net.SplitHostPort(listen); err == nil && port == strconv.Itoa(node.FixedInternalPort) {
+			return i18n.Errorf("固定内部端口不能与监听端口相同: %d", node.FixedInternalPort)
+		}
+	}
+	return nil
+}
+
+// ProxyBypassLocal 不含点的本地主机名（Windows ProxyOverride 中的 <local>）
+const ProxyBypassLocal = "<local>"
+
+// MaxProxyBypassEntries 不代理列表的条目上限
+const MaxProxyBypassEntries = 200
+
+// DefaultProxyBypass 系统代理默认不代理本机、局域网网段和本地主机名
+var DefaultProxyBypass = []string{"localhost", "127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", ProxyBypassLocal}
+
+// EffectiveProxyBypass 系统代理实际使用的不代理列表
+func (c *AppConfig) EffectiveProxyBypass() []string {
+	if len(c.ProxyBypass) == 0 {
+		return append([]string(nil), DefaultProxyBypass...)
+	}
+	return append([]string(nil), c.ProxyBypass...)
+}
+
+// NormalizeProxyBypass 去除空白和重复的条目，主机名转为小写
+func NormalizeProxyBypass(list []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(list))
+	for _, e := range list {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" || seen[e] {
+			continue
+		}
+		seen[e] = true
+		result = append(result, e)
+	}
+	return result
+}
+
+// ValidateProxyBypass 验证不代理列表：主机名（可用 * 通配，如 *.corp.example）、IP、
+// IPv4 通配写法（192.168.*）、CIDR 网段或 <local>
+func ValidateProxyBypass(list []string) error {
+	if len(list) > MaxProxyBypassEntries {
+		return i18n.Errorf("不代理列表最多 %d 条", MaxProxyBypassEntries)
+	}
+	for _, e := range list {
+		if !validProxyBypassEntry(e) {
+			return i18n.Errorf("不代理列表中的条目无效: %s", e)
+		}
+	}
+	return nil
+}
+
+func validProxyBypassEntry(e string) bool {
+	switch {
+	case e == ProxyBypassLocal:
+		return true
+	case strings.Contains(e, "/"):
+		_, err := netip.ParsePrefix(e)
+		return err == nil
+	case net.ParseIP(e) != nil:
+		return true
+	case strings.Trim(e, "*.") == "":
+		return false
+	}
+	for _, r := range e {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '*' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// HookSettings 节点启动 / 停止后执行的命令（Windows 下由 cmd /C 执行，其他系统由 sh -c 执行）
+// 节点信息通过环境变量传入，输出记录到节点日志
+type HookSettings struct {
+	PostStart string `json:"post_start"` // 节点启动成功后执行
+	PostStop  string `json:"post_stop"`  // 节点停止或异常退出后执行
+	Timeout   int    `json:"timeout"`    // 超时（秒），0 使用默认值
+}
+
+// 钩子命令超时
+const (
+	DefaultHookTimeout = 30
+	MaxHookTimeout     = 600
+)
+
+// TimeoutDuration 超时时长（未设置时使用默认值）
+func (h HookSettings) TimeoutDuration() time.Duration {
+	if h.Timeout <= 0 {
+		return DefaultHookTimeout * time.Second
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// BackupSettings 自动备份的保留策略
+// 删除节点、应用预设、恢复配置前及手动创建的备份，与每日定时备份分别按份数保留
+type BackupSettings struct {
+	DisableDaily  bool `json:"disable_daily"`  // 关闭每日定时备份
+	KeepDaily     int  `json:"keep_daily"`     // 保留的每日备份份数，0 使用默认值
+	KeepSnapshots int  `json:"keep_snapshots"` // 保留的变更前 / 手动备份份数，0 使用默认值
+}
+
+// 自动备份保留份数
+const (
+	DefaultBackupKeepDaily     = 7
+	DefaultBackupKeepSnapshots = 20
+	MaxBackupKeep              = 365
+)
+
+// DailyLimit 保留的每日备份份数（未设置时使用默认值）
+func (b BackupSettings) DailyLimit() int {
+	if b.KeepDaily <= 0 {
+		return DefaultBackupKeepDaily
+	}
+	return b.KeepDaily
+}
+
+// SnapshotLimit 保留的变更前 / 手动备份份数（未设置时使用默认值）
+func (b BackupSettings) SnapshotLimit() int {
+	if b.KeepSnapshots <= 0 {
+		return DefaultBackupKeepSnapshots
+	}
+	return b.KeepSnapshots
+}
+
+// 可设置的节点 / 规则数量上限
+const (
+	LimitMaxNodes = 1000
+	LimitMaxRules = 5000
+)
+
+// LimitSettings 节点与规则数量上限，0 使用默认值
+// 节点越多，配置加载保存、批量延迟测试和节点列表越慢；规则越多，生成的内核配置越大，
+// 内核启动越慢、每个连接的路由匹配耗时越长，建议优先使用规则组与 geosite / geoip 规则
+type LimitSettings struct {
+	MaxNodes int `json:"max_nodes,omitempty"`
+	MaxRules int `json:"max_rules,omitempty"` // 每个节点展开规则组后的规则数量
+}
+
+// NodeLimit 生效的节点数量上限
+func (c *AppConfig) NodeLimit() int {
+	if c.Limits.MaxNodes > 0 {
+		return c.Limits.MaxNodes
+	}
+	return DefaultMaxNodes
+}
+
+// RuleLimit 生效的每个节点规则数量上限
+func (c *AppConfig) RuleLimit() int {
+	if c.Limits.MaxRules > 0 {
+		return c.Limits.MaxRules
+	}
+	return DefaultMaxRules
+}
+
+// ValidateLimits 验证数量上限设置
+func ValidateLimits(l LimitSettings) error {
+	if l.MaxNodes < 0 || l.MaxNodes > LimitMaxNodes {
+		return i18n.Errorf("节点数量上限应在 1 到 %d 之间", LimitMaxNodes)
+	}
+	if l.MaxRules < 0 || l.MaxRules > LimitMaxRules {
+		return i18n.Errorf("规则数量上限应在 1 到 %d 之间", LimitMaxRules)
+	}
+	return nil
+}
+
+// GeneratedFilesSettings 生成的内核配置文件的保留策略
+type GeneratedFilesSettings struct {
+	KeepLast bool `json:"keep_last"` // 退出时保留每个节点最近一次生成的配置（便于排查问题），否则退出和启动时删除
+}
+
+// WebviewDataSettings 界面数据目录（webview_data）的位置与大小监控
+type WebviewDataSettings struct {
+	InUserDir bool `json:"in_user_dir"` // 存放在用户数据目录而不是程序目录，重启后生效
+	MaxSizeMB int  `json:"max_size_mb"` // 超过该大小时提醒，0 使用默认值
+	AutoClean bool `json:"auto_clean"`  // 超过大小时在下次启动时自动清理缓存
+}
+
+// DefaultWebviewDataMaxMB 界面数据目录的默认提醒大小
+const DefaultWebviewDataMaxMB = 300
+
+// SizeLimit 提醒大小（字节，未设置时使用默认值）
+func (w WebviewDataSettings) SizeLimit() int64 {
+	if w.MaxSizeMB <= 0 {
+		return DefaultWebviewDataMaxMB << 20
+	}
+	return int64(w.MaxSizeMB) << 20
+}
+
+// KioskSettings 只读模式：禁止新建、修改、删除和导入节点及修改设置，仍可启动 / 停止节点和查看状态
+type KioskSettings struct {
+	Enabled      bool   `json:"enabled"`
+	PasswordHash string `json:"password_hash,omitempty"` // 退出只读模式的密码（PBKDF2），为空时无需密码
+	PasswordSalt string `json:"password_salt,omitempty"`
+}
+
+// SyncSettings WebDAV 同步：节点与规则组以同步密码加密后上传，其他设备下载后合并
+type SyncSettings struct {
+	URL        string `json:"url"` // WebDAV 目录（或 .json 文件）地址
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Passphrase string `json:"passphrase"` // 同步密码（各设备需一致）
+	AutoSync   bool   `json:"auto_sync"`  // 启动后及每隔 SyncInterval 自动同步
+
+	// 同步状态（由同步流程维护）
+	Revision   int64  `json:"revision"`    // 上次同步时的远端版本号
+	SyncedHash string `json:"synced_hash"` // 上次同步时节点与规则组的摘要
+	LastSync   int64  `json:"last_sync"`   // 上次同步时间（Unix 秒）
+}
+
+// SyncInterval 自动同步间隔
+const SyncInterval = 30 * time.Minute
+
+// LocalDNSSettings 本机 DNS 服务（监听 127.0.0.1:53 和 [::1]:53）
+// 代理域名从 Fake-IP 映射应答，其余查询转发给 Upstream
+type LocalDNSSettings struct {
+	Enabled  bool     `json:"enabled"`
+	Upstream []string `json:"upstream"` // 上游 DNS（IP 或 IP:端口），为空时使用内置地址
+}
+
+// DNSSettings 自定义 DNS 规则（与 DNS 模式、本机 DNS 上游一起可导出为 DNS 配置包）
+type DNSSettings struct {
+	FakeIPFilter []string          `json:"fake_ip_filter"` // 额外不使用 Fake-IP 的域名（"+.x" / "*.x" / 完整域名），追加在内置列表之后
+	Hosts        map[string]string `json:"hosts"`          // 域名 → IP，优先于 Fake-IP 与上游解析
+	Presets      []DNSServerPreset `json:"presets"`        // 自定义上游 DNS 预设
+}
+
+// DNSServerPreset 自定义上游 DNS 预设
+type DNSServerPreset struct {
+	Name    string   `json:"name"`
+	Servers []string `json:"servers"` // IP 或 IP:端口
+}
+
+// MetricsSettings Prometheus 指标接口（只读，不需要令牌）
+type MetricsSettings struct {
+	Enabled bool   `json:"enabled"`
+	Listen  string `json:"listen"` // 监听地址，默认 127.0.0.1:9091
+}
+
+// LANShareSettings 局域网共享：在局域网地址上另建要求账号认证的入站，只允许列表中的客户端使用
+// 回环地址上的入站保持不变（无需认证），供本机的系统代理、PAC、测速和内部请求使用
+type LANShareSettings struct {
+	Enabled        bool     `json:"enabled"`
+	Username       string   `json:"username"`
+	Password       string   `json:"password"`
+	AllowedClients []string `json:"allowed_clients"` // 允许的客户端 IP 或 CIDR，为空时允许私有地址（本机始终允许）
+}
+
 // NodeConfig 单个节点的完整配置
 type NodeConfig struct {
 	// 基本信息
-	ID   string `json:"id"`   // 唯一ID (UUID)
-	Name string `json:"name"` // 节点别名
+	ID    string   `json:"id"`              // 唯一ID (UUID)
+	Name  string   `json:"name"`            // 节点别名
+	Tags  []string `json:"tags,omitempty"`  // 标签（用于筛选节点）
+	Group string   `json:"group,omitempty"` // 分组（如 streaming、work），可按分组批量启动 / 停止
+
+	// 导入该节点的订阅，刷新订阅时按名称更新服务器与凭据
+	SubscriptionID string `json:"subscription_id,omitempty"`
 
 	// 连接配置
 	Listen     string `json:"listen"`      // 本地监听地址 (如 127.0.0.1:10808 或 [::1]:10808)
@@ -87,14 +648,29 @@ type NodeConfig struct {
 	FallbackIP string `json:"fallback_ip"` // 回源IP (支持IPv4/IPv6)
 	Socks5     string `json:"socks5"`      // 上游SOCKS5代理 (支持IPv6格式 [::1]:1080)
 
+	// 前置节点：出站经该节点的本地 SOCKS5 入站转发（与 Socks5 互斥，启动时前置节点先启动）
+	ChainNodeID string `json:"chain_node_id,omitempty"`
+
+	// 本地入站
+	InboundMode int    `json:"inbound_mode"` // 入站协议 (Inbound*)
+	HTTPListen  string `json:"http_listen"`  // HTTP 入站监听地址（仅 InboundSocksHTTP 使用，如 127.0.0.1:10809）
+
+	// 局域网共享（开启后在本机的局域网地址上以相同端口另建需要认证的入站）
+	LANShare LANShareSettings `json:"lan_share"`
+
 	// 路由与策略
-	RoutingMode  int `json:"routing_mode"`  // 路由模式
-	StrategyMode int `json:"strategy_mode"` // 负载策略
+	RoutingMode  int    `json:"routing_mode"`           // 路由模式
+	StrategyMode int    `json:"strategy_mode"`          // 负载策略
+	FrontEngine  string `json:"front_engine,omitempty"` // 智能分流前端内核 (FrontEngine*)，为空时使用 Xray
+
+	// 智能分流时 Xlink 核心监听的固定内部端口（防火墙只放行指定端口时使用），0 时从内部端口范围中分配
+	FixedInternalPort int `json:"fixed_internal_port,omitempty"`
 
 	// DNS 防泄露配置
-	DNSMode        int    `json:"dns_mode"`        // DNS模式
-	CustomDNS      string `json:"custom_dns"`      // 自定义DNS服务器 (支持IPv6)
-	EnableSniffing bool   `json:"enable_sniffing"` // 启用流量嗅探
+	DNSMode        int    `json:"dns_mode"`          // DNS模式
+	CustomDNS      string `json:"custom_dns"`        // 自定义DNS服务器 (支持IPv6)
+	EnableSniffing bool   `json:"enable_sniffing"`   // 启用流量嗅探
+	TUNMTU         int    `json:"tun_mtu,omitempty"` // TUN 网卡 MTU，0 使用默认值（可由 AutoTuneMTU 探测填写）
 
 	// IPv6 相关配置
 	EnableIPv6  bool `json:"enable_ipv6"`  // 启用IPv6支持（双栈）
@@ -102,12 +678,44 @@ type NodeConfig struct {
 	DisableIPv6 bool `json:"disable_ipv6"` // 禁用IPv6（仅使用IPv4）
 	IPv6Only    bool `json:"ipv6_only"`    // 仅使用IPv6（禁用IPv4）
 
+	// 自动IP策略：启动时测量服务器 IPv4/IPv6 延迟，自动决定优先级（启用后忽略上面的 IPv6 开关）
+	AutoIPStrategy bool `json:"auto_ip_strategy"`
+
 	// 分流规则
 	Rules []RoutingRule `json:"rules"`
 
+	// 引用的全局规则组（按顺序排在节点自身规则之后）
+	RuleGroupIDs []string `json:"rule_group_ids,omitempty"`
+
+	// 按应用分流
+	AppRoutingMode int      `json:"app_routing_mode"`           // AppRouting*
+	AppRoutingApps []string `json:"app_routing_apps,omitempty"` // 程序名（如 chrome.exe）或完整路径
+
+	// 核心进程异常退出后按全局重启策略自动重启
+	AutoRestart bool `json:"auto_restart"`
+
+	// 启动 / 停止超时与连接重试，为 0 的字段使用全局设置
+	ConnectionPolicy ConnectionPolicy `json:"connection_policy"`
+
+	// 连接保活（部分 NAT 网关会断开长时间空闲的隧道）
+	GlobalKeepAlive   bool `json:"global_keep_alive"`   // 没有流量时也定期发送保活包
+	IdleTimeout       int  `json:"idle_timeout"`        // 空闲连接超时（秒），0 使用内核默认值
+	KeepAliveInterval int  `json:"keep_alive_interval"` // 保活间隔（秒），0 使用内核默认值
+
+	// 带宽限制（本地入站由令牌桶转发限速，整个节点共享）
+	UploadLimit   int `json:"upload_limit"`   // 上传限速（KB/s），0 表示不限
+	DownloadLimit int `json:"download_limit"` // 下载限速（KB/s），0 表示不限
+
+	// 自定义 Xray 配置模板（JSON，智能分流时生成的配置合并到其中），为空时使用全局模板
+	XrayTemplate string `json:"xray_template,omitempty"`
+
 	// 运行时状态 (不持久化)
-	Status       string `json:"-"` // 运行状态
-	InternalPort int    `json:"-"` // 内部端口（智能分流时使用）
+	Status          string            `json:"-"` // 运行状态
+	InternalPort    int               `json:"-"` // 内部端口（智能分流时使用）
+	StatsPort       int               `json:"-"` // 前端内核统计接口端口（智能分流时使用）
+	APIPort         int               `json:"-"` // Xray API 端口（智能分流 Xray 前端，热重载时更新路由规则）
+	BandwidthRelays map[string]string `json:"-"` // 限速转发：对外监听地址 → 前端进程实际监听的内部地址
+	LANInbounds     []LANShareInbound `json:"-"` // 局域网共享：局域网地址上需要认证的入站（启动时按本机网卡生成）
 
 	// 已弃用字段兼容
 	RulesStr string `json:"rules_str,omitempty"` // 旧版规则字符串
@@ -130,10 +738,126 @@ type AppConfig struct {
 	GlobalPreferIPv6  bool `json:"global_prefer_ipv6"`  // 全局优先IPv6
 	GlobalDisableIPv6 bool `json:"global_disable_ipv6"` // 全局禁用IPv6
 
+	// 系统代理
+	SystemProxyMode int      `json:"system_proxy_mode"`      // 系统代理写入模式
+	ProxyBypass     []string `json:"proxy_bypass,omitempty"` // 不经系统代理的地址，为空时使用 DefaultProxyBypass
+
+	// 本地控制接口 (REST)
+	APIEnabled bool   `json:"api_enabled"` // 启用本地控制接口
+	APIListen  string `json:"api_listen"`  // 监听地址，默认 127.0.0.1:9090
+	APIToken   string `json:"api_token"`   // Bearer 访问令牌
+
+	// Prometheus 指标接口
+	Metrics MetricsSettings `json:"metrics"`
+
+	// 自动选择最快节点
+	AutoSelectEnabled   bool `json:"auto_select_enabled"`   // 启用自动选择
+	AutoSelectInterval  int  `json:"auto_select_interval"`  // 重新评估间隔（分钟），0 使用默认值
+	AutoSelectThreshold int  `json:"auto_select_threshold"` // 当前节点比最快节点慢多少毫秒时切换，0 使用默认值
+
+	// 批量测速
+	PingConcurrency int `json:"ping_concurrency"` // 同时测试的节点数，0 使用默认值
+	PingTimeout     int `json:"ping_timeout"`     // 单个节点的超时（秒），0 使用默认值
+
+	// 测速方式: "core"(内核握手，默认) / "tcp"(TCP 连接时间) / "icmp"(ICMP 回显)
+	PingMode string `json:"ping_mode"`
+
+	// 服务器池健康探测（不可用的服务器从生成的配置中暂时移除）
+	ServerHealthCheck    bool `json:"server_health_check"`    // 启用
+	ServerHealthInterval int  `json:"server_health_interval"` // 探测间隔（秒），0 使用默认值
+
+	// 定时泄露测试（有节点运行时按间隔执行）
+	LeakTestInterval int `json:"leak_test_interval"` // 间隔（小时），0 表示关闭
+
+	// 规则数据 (geosite.dat / geoip.dat) 更新
+	GeoDataMirrors     []string `json:"geodata_mirrors"`      // 下载镜像，为空时使用内置镜像
+	GeoDataUpdateDays  int      `json:"geodata_update_days"`  // 自动更新间隔（天），0 表示关闭
+	DisableGeoFallback bool     `json:"disable_geo_fallback"` // 缺少规则数据时不使用内置的私有/中国地址列表代替
+
+	// 全局规则组
+	RuleGroups []RuleGroup `json:"rule_groups"`
+
+	// 内部 HTTP 请求出口（所选节点未运行时自动回退：指定节点 → 当前节点 → 直连）
+	EgressMode   int    `json:"egress_mode"`
+	EgressNodeID string `json:"egress_node_id"`
+
+	// 定时任务
+	Schedules []ScheduleEntry `json:"schedules"`
+
+	// 订阅
+	Subscriptions []Subscription `json:"subscriptions"`
+
+	// 通知渠道
+	Notifications NotificationSettings `json:"notifications"`
+
+	// 内核程序更新
+	CoreUpdate CoreUpdateSettings `json:"core_update"`
+
+	// 核心进程自动重启策略
+	RestartPolicy RestartPolicy `json:"restart_policy"`
+
+	// 启动 / 停止超时与连接重试策略（节点可单独覆盖）
+	ConnectionPolicy ConnectionPolicy `json:"connection_policy"`
+
+	// 内部端口范围（节点可设置固定的内部端口）
+	InternalPortRange PortRange `json:"internal_port_range"`
+
+	// 自定义 Xray 配置模板（节点可单独设置）
+	XrayTemplate string `json:"xray_template,omitempty"`
+
+	// 节点启动 / 停止钩子
+	Hooks HookSettings `json:"hooks"`
+
+	// 自动备份
+	Backup BackupSettings `json:"backup"`
+
+	// 只读模式
+	Kiosk KioskSettings `json:"kiosk"`
+
+	// WebDAV 同步
+	Sync SyncSettings `json:"sync"`
+
+	// 本机 DNS 服务
+	LocalDNS LocalDNSSettings `json:"local_dns"`
+
+	// 自定义 DNS 规则（Fake-IP 过滤、hosts、上游预设）
+	DNS DNSSettings `json:"dns"`
+
+	// 界面数据目录维护
+	WebviewData WebviewDataSettings `json:"webview_data"`
+
+	// 生成的内核配置文件保留策略
+	GeneratedFiles GeneratedFilesSettings `json:"generated_files"`
+
+	// 节点与规则数量上限
+	Limits LimitSettings `json:"limits"`
+
+	// 调试日志：不折叠重复日志，保留内核原始输出
+	DebugLog bool `json:"debug_log"`
+
+	// 断线保护：节点异常退出后阻止非代理出站流量，直到节点恢复或关闭保护
+	KillSwitchEnabled bool `json:"kill_switch_enabled"`
+	KillSwitchActive  bool `json:"kill_switch_active"` // 保护当前是否生效（应用重启后保持）
+
+	// 预览模式：修改系统 DNS、路由、防火墙前只返回将执行的命令，不实际执行
+	PreviewSystemChanges bool `json:"preview_system_changes"`
+
+	// 带宽测速
+	SpeedTestDownloadURL string `json:"speed_test_download_url"` // 下载测试文件，为空时使用内置地址
+	SpeedTestUploadURL   string `json:"speed_test_upload_url"`   // 上传测试地址，为空时使用内置地址
+
 	// 🚀【核心新增】记录上次运行的节点 ID，实现自动恢复
 	LastRunningNodeID string `json:"last_running_node_id"`
 }
 
+// DuplicateGroup 重复节点分组（去重建议）
+type DuplicateGroup struct {
+	Key          string   `json:"key"`           // 有效端点指纹
+	SurvivorID   string   `json:"survivor_id"`   // 保留的节点
+	DuplicateIDs []string `json:"duplicate_ids"` // 将被合并删除的节点
+	Names        []string `json:"names"`         // 组内所有节点名称（展示用）
+}
+
 // =============================================================================
 // 运行时状态结构
 // =============================================================================
@@ -148,14 +872,61 @@ type EngineStatus struct {
 	ErrorMessage string    `json:"error_message,omitempty"`
 }
 
+// ConnectionInfo 节点上的一条连接（由内核日志关联而来）
+type ConnectionInfo struct {
+	ID         uint64    `json:"id"`
+	NodeID     string    `json:"node_id"`
+	Target     string    `json:"target"`               // 访问的目标地址
+	Server     string    `json:"server"`               // 选中的服务器 (SNI)
+	RealServer string    `json:"real_server"`          // 隧道实际连接的地址
+	LocalPort  int       `json:"local_port,omitempty"` // 隧道连接的本地端口（用于断开连接，未知时为 0）
+	Rule       string    `json:"rule"`                 // 命中的规则关键词（负载均衡时为空）
+	Strategy   string    `json:"strategy"`             // 负载均衡策略
+	LatencyMs  int64     `json:"latency_ms"`           // 隧道建立延迟（毫秒），未知时为 -1
+	StartTime  time.Time `json:"start_time"`
+	DurationMs int64     `json:"duration_ms"`
+	Upload     int64     `json:"upload"`   // 上行字节（连接结束后才有）
+	Download   int64     `json:"download"` // 下行字节（连接结束后才有）
+	Closed     bool      `json:"closed"`
+}
+
+// UDPStats 节点的 UDP 转发统计
+// 会话与失败数来自 Xray 的访问日志和错误日志，仅智能分流模式下可统计
+type UDPStats struct {
+	NodeID      string          `json:"node_id"`
+	Sessions    uint64          `json:"sessions"`               // UDP 会话数（QUIC、游戏、DNS 等）
+	Failures    uint64          `json:"failures"`               // UDP 转发失败次数
+	LastSession int64           `json:"last_session,omitempty"` // Unix 秒
+	LastFailure int64           `json:"last_failure,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	Probe       *UDPProbeResult `json:"probe,omitempty"` // 最近一次 UDP 转发测试
+}
+
+// UDPProbeResult UDP 转发测试结果
+type UDPProbeResult struct {
+	OK        bool   `json:"ok"`
+	Stage     string `json:"stage,omitempty"` // 失败的阶段: "associate"（连接代理、握手并建立 UDP 关联）, "relay"
+	Target    string `json:"target"`
+	RelayAddr string `json:"relay_addr,omitempty"` // 代理返回的 UDP 转发地址
+	RTTMs     int64  `json:"rtt_ms"`
+	Error     string `json:"error,omitempty"`
+	CheckedAt int64  `json:"checked_at"`
+}
+
 // LogEntry 日志条目
 type LogEntry struct {
 	Timestamp time.Time `json:"timestamp"`
 	NodeID    string    `json:"node_id"`
 	NodeName  string    `json:"node_name"`
 	Level     string    `json:"level"`    // "info", "warn", "error", "debug"
-	Category  string    `json:"category"` // "系统", "内核", "规则", "负载", "统计", "测速"
+	Category  string    `json:"category"` // 类别标识: "system", "engine", "tunnel", "rule", "lb", "stats", "ping", "xray", "sing-box", "dns"
 	Message   string    `json:"message"`
+
+	// 日志中的数值（如隧道延迟、连接流量），界面可按语言重新格式化
+	Metrics map[string]Metric `json:"metrics,omitempty"`
+
+	// 类别显示名称，由 API 层按界面语言填充
+	CategoryName string `json:"category_name,omitempty"`
 }
 
 // LogFilter 日志过滤选项
@@ -186,6 +957,59 @@ type PingStatus struct {
 	TotalCount  int    `json:"total_count"`
 }
 
+// Unit 数值的单位，作为界面格式化的提示
+// 统计类接口返回原始数值和单位，由界面按语言选择 KB/MB、秒/s 等显示方式，不在后端拼接显示文本
+type Unit string
+
+const (
+	UnitBytes        Unit = "bytes"   // 字节，界面按大小换算为 KB/MB/GB
+	UnitBytesPerSec  Unit = "bytes/s" // 字节/秒
+	UnitMilliseconds Unit = "ms"
+	UnitSeconds      Unit = "s"
+	UnitCount        Unit = "count"
+)
+
+// Metric 带单位提示的原始数值
+type Metric struct {
+	Value int64 `json:"value"`
+	Unit  Unit  `json:"unit"`
+}
+
+// NewMetric 创建带单位的数值
+func NewMetric(value int64, unit Unit) Metric {
+	return Metric{Value: value, Unit: unit}
+}
+
+// TrafficStats 单个节点的流量统计
+type TrafficStats struct {
+	NodeID      string    `json:"node_id"`
+	Upload      int64     `json:"upload"`      // 累计上行字节
+	Download    int64     `json:"download"`    // 累计下行字节
+	UpSpeed     int64     `json:"up_speed"`    // 上行速率 (字节/秒)
+	DownSpeed   int64     `json:"down_speed"`  // 下行速率 (字节/秒)
+	Connections int       `json:"connections"` // 已结束的连接数
+	LastTarget  string    `json:"last_target"` // 最近一次结束的连接目标
+	StartTime   time.Time `json:"start_time"`  // 统计开始时间
+	LastUpdate  time.Time `json:"last_update"` // 最近一次更新时间
+
+	// 智能分流模式下由 Xray 统计接口提供的精确计数（按入站 / 出站标签累计），日志统计时为空
+	Inbounds  map[string]ByteCounter `json:"inbounds,omitempty"`
+	Outbounds map[string]ByteCounter `json:"outbounds,omitempty"`
+}
+
+// ByteCounter 上下行字节计数
+type ByteCounter struct {
+	Up   int64 `json:"up"`
+	Down int64 `json:"down"`
+}
+
+// CoreTraffic 内核统计接口返回的累计计数（自进程启动起）
+type CoreTraffic struct {
+	Since     time.Time              // 内核进程启动时间，变化时说明内核已重启、计数从零开始
+	Inbounds  map[string]ByteCounter // 入站标签 → 计数（上行为客户端发出的字节）
+	Outbounds map[string]ByteCounter // 出站标签 → 计数
+}
+
 // IPv6SupportStatus IPv6支持状态
 type IPv6SupportStatus struct {
 	HasIPv6Interface bool     `json:"has_ipv6_interface"` // 是否有IPv6网卡
@@ -203,16 +1027,72 @@ type IPv6SupportStatus struct {
 type EventType string
 
 const (
-	EventLogAppend         EventType = "log:append"
+	EventLogBatch          EventType = "log:batch" // 日志订阅的批量推送
 	EventNodeStatus        EventType = "node:status"
 	EventPingResult        EventType = "ping:result"
 	EventPingComplete      EventType = "ping:complete"
 	EventPingBatchProgress EventType = "ping:batch:progress"
 	EventPingBatchComplete EventType = "ping:batch:complete"
-	EventConfigChanged     EventType = "config:changed"
+	EventConfigChanged     EventType = "config:changed" // 整体变更（导入/恢复备份），前端需全量刷新
 	EventIPv6StatusChanged EventType = "ipv6:status:changed"
+	EventStatsUpdate       EventType = "stats:update"
+	EventAutoSelectUpdate  EventType = "autoselect:update"
+	EventLeakTestComplete  EventType = "leak:test:complete"
+	EventLeakDetected      EventType = "leak:detected" // 测试结果由未泄露变为泄露
+	EventGeoDataProgress   EventType = "geodata:progress"
+	EventGeoDataComplete   EventType = "geodata:complete"
+	EventScheduleRun       EventType = "schedule:run"
+	EventSpeedTestProgress EventType = "speedtest:progress"
+	EventSpeedTestComplete EventType = "speedtest:complete"
+	EventKillSwitchChanged EventType = "killswitch:changed"
+	EventGeoDataMissing    EventType = "geodata:missing"      // 生成配置时因缺少规则数据跳过了规则
+	EventNotification      EventType = "notification:new"     // 通知中心收到新通知
+	EventCoreProgress      EventType = "core:update:progress" // 内核更新阶段变化
+	EventCoreUpdated       EventType = "core:update:complete"
+	EventSystemResumed     EventType = "system:resumed"         // 睡眠唤醒后的恢复已完成
+	EventKioskChanged      EventType = "kiosk:changed"          // 只读模式开启或关闭
+	EventLiveStatus        EventType = "live:status"            // 活动节点的速率、延迟与出口（每 5 秒）
+	EventSyncComplete      EventType = "sync:complete"          // WebDAV 同步完成（含冲突）
+	EventSubscription      EventType = "subscription:refreshed" // 订阅刷新完成（含变化报告）
+	EventWebviewData       EventType = "webview:data"           // 界面数据目录超过提醒大小
+	EventCrashReport       EventType = "crash:report"           // 内核异常退出，已保存崩溃报告
+	EventServerHealth      EventType = "server:health"          // 节点池内的服务器不可用或恢复
+	EventNodeReloaded      EventType = "node:reloaded"          // 运行中的节点已热重载配置
+
+	// 细粒度配置事件，前端可增量更新
+	EventNodeAdded        EventType = "node:added"
+	EventNodeUpdated      EventType = "node:updated"
+	EventNodeDeleted      EventType = "node:deleted"
+	EventRuleAdded        EventType = "rule:added"
+	EventRuleUpdated      EventType = "rule:updated"
+	EventRuleDeleted      EventType = "rule:deleted"
+	EventRuleGroupAdded   EventType = "rulegroup:added"
+	EventRuleGroupUpdated EventType = "rulegroup:updated"
+	EventRuleGroupDeleted EventType = "rulegroup:deleted"
+	EventSettingsChanged  EventType = "settings:changed"
 )
 
+// NodeEventPayload 节点变更事件负载
+type NodeEventPayload struct {
+	NodeID string      `json:"node_id"`
+	Node   *NodeConfig `json:"node,omitempty"`   // 变更后的节点（删除时为空）
+	Fields []string    `json:"fields,omitempty"` // 发生变化的字段（json 名称）
+}
+
+// RuleGroupEventPayload 规则组变更事件负载
+type RuleGroupEventPayload struct {
+	GroupID string     `json:"group_id"`
+	Group   *RuleGroup `json:"group,omitempty"`    // 变更后的规则组（删除时为空）
+	NodeIDs []string   `json:"node_ids,omitempty"` // 引用该规则组的节点
+}
+
+// RuleEventPayload 规则变更事件负载
+type RuleEventPayload struct {
+	NodeID string       `json:"node_id"`
+	RuleID string       `json:"rule_id"`
+	Rule   *RoutingRule `json:"rule,omitempty"` // 变更后的规则（删除时为空）
+}
+
 // Event 前后端事件结构
 type Event struct {
 	Type    EventType   `json:"type"`
@@ -230,7 +1110,8 @@ type AppState struct {
 	EngineStatuses map[string]*EngineStatus // key: NodeID
 	CurrentNodeID  string
 	ExeDir         string
-	IsAutoStart    bool              // 是否由开机自启触发
+	DataDir        string             // 配置、日志、备份等用户数据所在目录（便携模式下与 ExeDir 相同）
+	IsAutoStart    bool               // 是否由开机自启触发
 	IPv6Status     *IPv6SupportStatus // IPv6支持状态缓存
 }
 
@@ -379,6 +1260,73 @@ func randomHex(n int) string {
 	return hex.EncodeToString(b)[:n]
 }
 
+// DiffNodeFields 比较两个节点配置，返回发生变化的字段（json 名称）
+// 运行时字段（json:"-"）不参与比较
+func DiffNodeFields(oldNode, newNode *NodeConfig) []string {
+	var fields []string
+
+	ov := reflect.ValueOf(oldNode).Elem()
+	nv := reflect.ValueOf(newNode).Elem()
+	t := ov.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			fields = append(fields, tag)
+		}
+	}
+	return fields
+}
+
+// ParseByteSize 解析 "1.5 KB"、"512B"、"2MiB"、"1024" 等格式为字节数
+func ParseByteSize(s string) int64 {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0
+	}
+
+	multiplier := float64(1)
+	units := []struct {
+		suffix string
+		value  float64
+	}{
+		{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			multiplier = u.value
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return int64(value * multiplier)
+}
+
+// SplitServers 拆分服务器地址池（支持分号、逗号、中文逗号和换行分隔）
+func SplitServers(server string) []string {
+	fields := strings.FieldsFunc(server, func(r rune) bool {
+		return r == ';' || r == ',' || r == '，' || r == '\n' || r == '\r'
+	})
+	result := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 // GetStrategyString 获取策略字符串
 func GetStrategyString(mode int) string {
 	switch mode {
@@ -420,6 +1368,211 @@ func GetIPVersionString(node *NodeConfig) string {
 	return "仅IPv4"
 }
 
+// GetHTTPListen 获取节点的 HTTP 代理地址，没有 HTTP 入站时返回空
+func GetHTTPListen(node *NodeConfig) string {
+	switch node.InboundMode {
+	case InboundSocksHTTP:
+		return node.HTTPListen
+	case InboundMixed:
+		return node.Listen
+	default:
+		return ""
+	}
+}
+
+// ResolveNodeRules 展开节点引用的规则组，返回最终规则列表（节点自身规则在前，不存在的规则组忽略）
+func ResolveNodeRules(node *NodeConfig, groups []RuleGroup) []RoutingRule {
+	if len(node.RuleGroupIDs) == 0 {
+		return node.Rules
+	}
+
+	rules := make([]RoutingRule, 0, len(node.Rules))
+	rules = append(rules, node.Rules...)
+	for _, id := range node.RuleGroupIDs {
+		if g := FindRuleGroup(groups, id); g != nil {
+			rules = append(rules, g.Rules...)
+		}
+	}
+	return rules
+}
+
+// NodeQuery 节点列表的筛选、排序与分页参数，零值返回全部节点（按配置顺序）
+type NodeQuery struct {
+	Search      string `json:"search"`        // 名称、服务器或监听地址包含的文字（不区分大小写）
+	Status      string `json:"status"`        // 运行状态 (Status*)
+	RuleGroupID string `json:"rule_group_id"` // 引用的规则组
+	Group       string `json:"group"`         // 节点分组（不区分大小写）
+	Tag         string `json:"tag"`           // 标签（不区分大小写）
+	SortBy      string `json:"sort_by"`       // NodeSort*，为空时按配置顺序
+	Desc        bool   `json:"desc"`          // 倒序
+	Offset      int    `json:"offset"`
+	Limit       int    `json:"limit"` // 0 表示不分页
+}
+
+// NodePage 节点列表查询结果
+type NodePage struct {
+	Nodes []NodeConfig `json:"nodes"`
+	Total int          `json:"total"` // 符合条件的节点数（分页前）
+	All   int          `json:"all"`   // 全部节点数
+}
+
+// QueryNodes 按条件筛选、排序并分页，节点的 Status 需已填充
+func QueryNodes(nodes []NodeConfig, q NodeQuery) NodePage {
+	search := strings.ToLower(strings.TrimSpace(q.Search))
+	matched := make([]NodeConfig, 0, len(nodes))
+	for _, n := range nodes {
+		if q.Status != "" && n.Status != q.Status {
+			continue
+		}
+		if q.RuleGroupID != "" && !containsString(n.RuleGroupIDs, q.RuleGroupID, false) {
+			continue
+		}
+		if q.Group != "" && !strings.EqualFold(n.Group, q.Group) {
+			continue
+		}
+		if q.Tag != "" && !containsString(n.Tags, q.Tag, true) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(n.Name), search) &&
+			!strings.Contains(strings.ToLower(n.Server), search) && !strings.Contains(strings.ToLower(n.Listen), search) {
+			continue
+		}
+		matched = append(matched, n)
+	}
+
+	var less func(a, b *NodeConfig) bool
+	switch q.SortBy {
+	case NodeSortName:
+		less = func(a, b *NodeConfig) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case NodeSortStatus:
+		less = func(a, b *NodeConfig) bool { return statusRank(a.Status) < statusRank(b.Status) }
+	case NodeSortListen:
+		less = func(a, b *NodeConfig) bool { return a.Listen < b.Listen }
+	}
+	if less != nil {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if q.Desc {
+				return less(&matched[j], &matched[i])
+			}
+			return less(&matched[i], &matched[j])
+		})
+	} else if q.Desc {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+
+	page := NodePage{Total: len(matched), All: len(nodes)}
+	start := q.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := len(matched)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+	page.Nodes = matched[start:end]
+	return page
+}
+
+// statusRank 按状态排序时的先后（运行中的在前）
+func statusRank(status string) int {
+	switch status {
+	case StatusRunning:
+		return 0
+	case StatusStarting, StatusRestarting:
+		return 1
+	case StatusError:
+		return 2
+	}
+	return 3
+}
+
+// NodeGroupCount 分组或标签下的节点数
+type NodeGroupCount struct {
+	Name    string `json:"name"`
+	Nodes   int    `json:"nodes"`
+	Running int    `json:"running"` // 运行中的节点数
+}
+
+// NodeGroupList 全部节点分组与标签（按名称排序）
+type NodeGroupList struct {
+	Groups []NodeGroupCount `json:"groups"`
+	Tags   []NodeGroupCount `json:"tags"`
+}
+
+// ListNodeGroups 统计节点分组与标签（大小写不同的名称合并，使用最先出现的写法），节点的 Status 需已填充
+func ListNodeGroups(nodes []NodeConfig) NodeGroupList {
+	groups := make(map[string]*NodeGroupCount)
+	tags := make(map[string]*NodeGroupCount)
+	count := func(m map[string]*NodeGroupCount, name string, running bool) {
+		key := strings.ToLower(name)
+		c, ok := m[key]
+		if !ok {
+			c = &NodeGroupCount{Name: name}
+			m[key] = c
+		}
+		c.Nodes++
+		if running {
+			c.Running++
+		}
+	}
+	for _, n := range nodes {
+		running := n.Status == StatusRunning
+		if n.Group != "" {
+			count(groups, n.Group, running)
+		}
+		for _, t := range n.Tags {
+			count(tags, t, running)
+		}
+	}
+	sorted := func(m map[string]*NodeGroupCount) []NodeGroupCount {
+		list := make([]NodeGroupCount, 0, len(m))
+		for _, c := range m {
+			list = append(list, *c)
+		}
+		sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name) })
+		return list
+	}
+	return NodeGroupList{Groups: sorted(groups), Tags: sorted(tags)}
+}
+
+// NormalizeTags 去除标签首尾空白、空标签及重复标签（不区分大小写）
+func NormalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || containsString(out, t, true) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// containsString 列表是否包含 s
+func containsString(list []string, s string, foldCase bool) bool {
+	for _, v := range list {
+		if v == s || (foldCase && strings.EqualFold(v, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindRuleGroup 按ID查找规则组
+func FindRuleGroup(groups []RuleGroup, id string) *RuleGroup {
+	for i := range groups {
+		if groups[i].ID == id {
+			return &groups[i]
+		}
+	}
+	return nil
+}
+
 // GetEffectiveIPVersion 获取节点实际生效的IP版本
 func GetEffectiveIPVersion(node *NodeConfig) int {
 	if node.IPv6Only {
@@ -434,17 +1587,27 @@ func GetEffectiveIPVersion(node *NodeConfig) int {
 	return IPVersionIPv4
 }
 
-// ValidateIPv6Config 验证IPv6配置是否有效
+// IPv6ConfigError IPv6 开关互相冲突
+type IPv6ConfigError struct {
+	Fields []string // 冲突的字段（json 名称）
+	Reason string
+}
+
+func (e *IPv6ConfigError) Error() string {
+	return e.Reason
+}
+
+// ValidateIPv6Config 验证IPv6配置是否有效，冲突时返回 *IPv6ConfigError
 func ValidateIPv6Config(node *NodeConfig) error {
 	// 互斥检查
 	if node.DisableIPv6 && node.IPv6Only {
-		return fmt.Errorf("DisableIPv6 和 IPv6Only 不能同时启用")
+		return &IPv6ConfigError{Fields: []string{"disable_ipv6", "ipv6_only"}, Reason: "DisableIPv6 和 IPv6Only 不能同时启用"}
 	}
 	if node.DisableIPv6 && node.PreferIPv6 {
-		return fmt.Errorf("DisableIPv6 和 PreferIPv6 不能同时启用")
+		return &IPv6ConfigError{Fields: []string{"disable_ipv6", "prefer_ipv6"}, Reason: "DisableIPv6 和 PreferIPv6 不能同时启用"}
 	}
 	if node.DisableIPv6 && node.EnableIPv6 {
-		return fmt.Errorf("DisableIPv6 和 EnableIPv6 不能同时启用")
+		return &IPv6ConfigError{Fields: []string{"disable_ipv6", "enable_ipv6"}, Reason: "DisableIPv6 和 EnableIPv6 不能同时启用"}
 	}
 	if node.IPv6Only && !node.EnableIPv6 {
 		// 自动修正：IPv6Only 必须启用 EnableIPv6
@@ -453,6 +1616,303 @@ func ValidateIPv6Config(node *NodeConfig) error {
 	return nil
 }
 
+// NormalizeIPv6Config 自动修正冲突的IPv6开关（导入、订阅、同步等批量写入时使用，不拒绝整批节点）
+// 与 DisableIPv6 冲突时以禁用为准（IPv4 总是可用），返回是否做了修改
+func NormalizeIPv6Config(node *NodeConfig) bool {
+	before := [4]bool{node.EnableIPv6, node.PreferIPv6, node.DisableIPv6, node.IPv6Only}
+	if ValidateIPv6Config(node) != nil {
+		node.EnableIPv6, node.PreferIPv6, node.IPv6Only = false, false, false
+	}
+	return before != [4]bool{node.EnableIPv6, node.PreferIPv6, node.DisableIPv6, node.IPv6Only}
+}
+
+// ValidateKeepAlive 验证连接保活设置
+func ValidateKeepAlive(node *NodeConfig) error {
+	if node.IdleTimeout < 0 || node.KeepAliveInterval < 0 {
+		return fmt.Errorf("空闲超时和保活间隔不能为负数")
+	}
+	if node.IdleTimeout > 0 && node.KeepAliveInterval >= node.IdleTimeout {
+		return fmt.Errorf("保活间隔必须小于空闲超时")
+	}
+	return nil
+}
+
+// MaxBandwidthLimit 带宽限制上限（KB/s，即 10 GB/s）
+const MaxBandwidthLimit = 10 << 20
+
+// HasBandwidthLimit 节点是否设置了带宽限制
+func HasBandwidthLimit(node *NodeConfig) bool {
+	return node.UploadLimit > 0 || node.DownloadLimit > 0
+}
+
+// ValidateBandwidthLimit 验证带宽限制设置
+func ValidateBandwidthLimit(node *NodeConfig) error {
+	if node.UploadLimit < 0 || node.DownloadLimit < 0 {
+		return fmt.Errorf("带宽限制不能为负数")
+	}
+	if node.UploadLimit > MaxBandwidthLimit || node.DownloadLimit > MaxBandwidthLimit {
+		return fmt.Errorf("带宽限制不能超过 10 GB/s")
+	}
+	return nil
+}
+
+// TUN 网卡 MTU 的取值范围
+const (
+	MinTUNMTU = 576
+	MaxTUNMTU = 9000
+)
+
+// ValidateTUNMTU 验证 TUN 网卡 MTU
+func ValidateTUNMTU(node *NodeConfig) error {
+	if node.TUNMTU != 0 && (node.TUNMTU < MinTUNMTU || node.TUNMTU > MaxTUNMTU) {
+		return i18n.Errorf("TUN MTU 应在 %d 到 %d 之间", MinTUNMTU, MaxTUNMTU)
+	}
+	return nil
+}
+
+// ValidateAppRouting 验证按应用分流设置
+func ValidateAppRouting(node *NodeConfig) error {
+	if node.AppRoutingMode < AppRoutingOff || node.AppRoutingMode > AppRoutingExclude {
+		return i18n.Errorf("未知的按应用分流模式: %d", node.AppRoutingMode)
+	}
+	if node.AppRoutingMode == AppRoutingOff {
+		return nil
+	}
+	if node.RoutingMode != RoutingModeSmart {
+		return fmt.Errorf("按应用分流需要智能分流模式")
+	}
+	if len(node.AppRoutingApps) == 0 {
+		return fmt.Errorf("请填写按应用分流的程序")
+	}
+	return nil
+}
+
+// ValidateFrontEngine 验证智能分流前端内核
+func ValidateFrontEngine(node *NodeConfig) error {
+	switch node.FrontEngine {
+	case "", FrontEngineXray, FrontEngineSingBox:
+		return nil
+	}
+	return i18n.Errorf("未知的前端内核: %s", node.FrontEngine)
+}
+
+// IsSingBoxFront 节点是否以 sing-box 作为智能分流前端
+func IsSingBoxFront(node *NodeConfig) bool {
+	return node.RoutingMode == RoutingModeSmart && node.FrontEngine == FrontEngineSingBox
+}
+
+// NormalizeApps 去除程序名首尾的空白和引号（从资源管理器复制的路径带引号）及重复项
+func NormalizeApps(apps []string) []string {
+	var out []string
+	for _, app := range apps {
+		app = strings.Trim(strings.TrimSpace(app), `"`)
+		if app == "" || containsString(out, app, true) {
+			continue
+		}
+		out = append(out, app)
+	}
+	return out
+}
+
+// lanShareDefaultClients 未填写允许的客户端时允许的私有地址段
+var lanShareDefaultClients = []string{
+	"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16", "fc00::/7", "fe80::/10",
+}
+
+// lanShareLoopback 本机地址始终允许
+var lanShareLoopback = []string{"127.0.0.0/8", "::1/128"}
+
+// ValidateLANShare 验证局域网共享设置
+func ValidateLANShare(node *NodeConfig) error {
+	if !node.LANShare.Enabled {
+		return nil
+	}
+	if node.RoutingMode != RoutingModeSmart {
+		return fmt.Errorf("局域网共享需要智能分流模式")
+	}
+	if node.LANShare.Username == "" || node.LANShare.Password == "" {
+		return fmt.Errorf("局域网共享需要设置用户名和密码")
+	}
+	// 本地入站不需要认证，只能监听回环地址；局域网设备经局域网地址上需要认证的入站连接
+	for _, listen := range []string{node.Listen, node.HTTPListen} {
+		if listen == "" {
+			continue
+		}
+		host, _, err := net.SplitHostPort(listen)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			return fmt.Errorf("开启局域网共享时本地监听地址需为回环地址: %s", listen)
+		}
+	}
+	_, err := LANShareClients(&node.LANShare)
+	return err
+}
+
+// LANShareInbound 局域网地址上需要认证的入站
+type LANShareInbound struct {
+	Listen string // 局域网地址与端口
+	HTTP   bool   // 对应 HTTP 入站（否则对应 SOCKS5 / 混合入站）
+}
+
+// LANShareInbounds 按本机的局域网地址生成局域网共享入站：每个本地入站在同一地址族的每个地址上使用相同端口
+// （同时监听通配地址与回环地址在部分系统上会冲突，因此逐个绑定局域网地址）
+func LANShareInbounds(node *NodeConfig, addrs []netip.Addr) []LANShareInbound {
+	var inbounds []LANShareInbound
+	add := func(listen string, http bool) {
+		host, port, err := net.SplitHostPort(listen)
+		if err != nil {
+			return
+		}
+		local, err := netip.ParseAddr(host)
+		if err != nil {
+			return
+		}
+		for _, addr := range addrs {
+			if addr.Is4() == local.Unmap().Is4() {
+				inbounds = append(inbounds, LANShareInbound{Listen: net.JoinHostPort(addr.String(), port), HTTP: http})
+			}
+		}
+	}
+	add(node.Listen, false)
+	if node.InboundMode == InboundSocksHTTP && node.HTTPListen != "" {
+		add(node.HTTPListen, true)
+	}
+	return inbounds
+}
+
+// LANShareClients 允许连接的客户端地址段（含本机地址）
+func LANShareClients(s *LANShareSettings) ([]netip.Prefix, error) {
+	list := s.AllowedClients
+	if len(list) == 0 {
+		list = lanShareDefaultClients
+	}
+	prefixes := make([]netip.Prefix, 0, len(list)+len(lanShareLoopback))
+	for _, c := range append(append([]string(nil), lanShareLoopback...), list...) {
+		c = strings.TrimSpace(c)
+		if p, err := netip.ParsePrefix(c); err == nil {
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(c)
+		if err != nil {
+			return nil, i18n.Errorf("允许的客户端无效: %s", c)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// LANShareAllows 客户端 IP 是否在允许的地址段中
+func LANShareAllows(clients []netip.Prefix, ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range clients {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// LANShareListen 局域网共享时的监听地址：主机部分改为通配地址（IPv6 地址改为 ::）
+func LANShareListen(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return net.JoinHostPort("::", port)
+	}
+	return net.JoinHostPort("0.0.0.0", port)
+}
+
+// MaxChainLength 节点链路的最大级数（含节点自身）
+const MaxChainLength = 5
+
+// ChainPath 沿前置节点返回启动 nodeID 所需的节点ID（最上游在前，nodeID 在最后）
+// 前置节点不存在、链路存在循环或过长时返回错误
+func ChainPath(nodes []NodeConfig, nodeID string) ([]string, error) {
+	byID := make(map[string]*NodeConfig, len(nodes))
+	for i := range nodes {
+		byID[nodes[i].ID] = &nodes[i]
+	}
+
+	var path []string
+	seen := make(map[string]bool)
+	for id := nodeID; id != ""; {
+		node := byID[id]
+		if node == nil {
+			if id == nodeID {
+				return nil, i18n.Errorf("节点不存在: %s", id)
+			}
+			return nil, i18n.Errorf("前置节点不存在: %s", id)
+		}
+		if seen[id] {
+			return nil, i18n.Errorf("节点链路存在循环: %s", node.Name)
+		}
+		seen[id] = true
+		path = append([]string{id}, path...)
+		id = node.ChainNodeID
+	}
+	if len(path) > MaxChainLength {
+		return nil, i18n.Errorf("节点链路过长（最多 %d 级）", MaxChainLength)
+	}
+	return path, nil
+}
+
+// ValidateChain 验证节点的前置节点设置（nodes 为当前全部节点，node 为修改后的节点）
+func ValidateChain(nodes []NodeConfig, node *NodeConfig) error {
+	if node.ChainNodeID == "" {
+		return nil
+	}
+	if node.ChainNodeID == node.ID {
+		return i18n.Errorf("不能以节点自身作为前置节点")
+	}
+	if strings.TrimSpace(node.Socks5) != "" {
+		return i18n.Errorf("前置节点与上游 SOCKS5 不能同时设置")
+	}
+
+	updated := make([]NodeConfig, len(nodes))
+	copy(updated, nodes)
+	for i := range updated {
+		if updated[i].ID == node.ID {
+			updated[i] = *node
+		}
+	}
+	_, err := ChainPath(updated, node.ID)
+	return err
+}
+
+// ReconcileIPv6Config 修复冲突的IPv6开关（加载旧配置时使用），以 DisableIPv6 为准，返回是否有修改
+func ReconcileIPv6Config(node *NodeConfig) bool {
+	if ValidateIPv6Config(node) == nil {
+		return false
+	}
+	node.EnableIPv6 = false
+	node.PreferIPv6 = false
+	node.IPv6Only = false
+	return true
+}
+
+// HasGlobalIPv6Settings 全局IPv6设置是否被配置过（全部为 false 视为未设置）
+func HasGlobalIPv6Settings(config *AppConfig) bool {
+	return config.GlobalEnableIPv6 || config.GlobalPreferIPv6 || config.GlobalDisableIPv6
+}
+
+// ValidateGlobalIPv6Settings 验证全局IPv6设置
+func ValidateGlobalIPv6Settings(config *AppConfig) error {
+	if config.GlobalDisableIPv6 && (config.GlobalEnableIPv6 || config.GlobalPreferIPv6) {
+		return &IPv6ConfigError{
+			Fields: []string{"global_disable_ipv6", "global_enable_ipv6", "global_prefer_ipv6"},
+			Reason: "全局禁用IPv6 时不能同时启用或优先IPv6",
+		}
+	}
+	return nil
+}
+
 // ApplyGlobalIPv6Settings 应用全局IPv6设置到节点
 func ApplyGlobalIPv6Settings(node *NodeConfig, config *AppConfig) {
 	// 如果节点没有明确设置，使用全局设置
@@ -0,0 +1,259 @@
+// Package notify 将应用通知按事件类型分发到多个渠道（系统通知、托盘气泡、Webhook、Telegram、应用内通知中心）
+package notify
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"xlink-wails/internal/i18n"
+)
+
+// =============================================================================
+// 常量
+// =============================================================================
+
+// Event 通知事件类型
+type Event string
+
+const (
+	EventNode         Event = "node"         // 节点恢复运行、自动切换
+	EventNodeStatus   Event = "node_status"  // 节点启动、停止、出错
+	EventKillSwitch   Event = "killswitch"   // 断线保护启用
+	EventLeak         Event = "leak"         // 检测到 DNS 泄露
+	EventNetwork      Event = "network"      // 网络环境变化（如 IPv6 断开）
+	EventGeoData      Event = "geodata"      // 规则数据更新
+	EventSchedule     Event = "schedule"     // 定时任务执行失败
+	EventComponent    Event = "component"    // 配套组件缺失
+	EventSubscription Event = "subscription" // 订阅刷新后节点有变化
+	EventSystem       Event = "system"       // 其他系统消息
+)
+
+// 渠道名称
+const (
+	ChannelToast    = "toast"    // 系统通知
+	ChannelTray     = "tray"     // 托盘气泡
+	ChannelWebhook  = "webhook"  // HTTP Webhook
+	ChannelTelegram = "telegram" // Telegram Bot
+	ChannelCenter   = "center"   // 应用内通知中心
+)
+
+// SendTimeout 单个渠道发送的超时时间
+const SendTimeout = 15 * time.Second
+
+// Events 全部事件类型（按固定顺序）
+func Events() []Event {
+	return []Event{EventNode, EventNodeStatus, EventKillSwitch, EventLeak, EventNetwork, EventGeoData,
+		EventSchedule, EventComponent, EventSubscription, EventSystem}
+}
+
+// Channels 全部渠道（按固定顺序）
+func Channels() []string {
+	return []string{ChannelToast, ChannelTray, ChannelWebhook, ChannelTelegram, ChannelCenter}
+}
+
+// DefaultChannels 未配置路由时使用的渠道
+func DefaultChannels() []string {
+	return []string{ChannelToast, ChannelCenter}
+}
+
+// eventDefaults 未配置路由时不使用默认渠道的事件（节点启动、停止较频繁，默认不弹出系统通知）
+var eventDefaults = map[Event][]string{
+	EventNodeStatus: {ChannelWebhook, ChannelCenter},
+}
+
+// ValidChannel 是否为已知渠道
+func ValidChannel(name string) bool {
+	for _, c := range Channels() {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidEvent 是否为已知事件类型
+func ValidEvent(event Event) bool {
+	for _, e := range Events() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// 通知与渠道
+// =============================================================================
+
+// Notification 一条通知
+type Notification struct {
+	ID      uint64    `json:"id"`
+	Event   Event     `json:"event"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	Node    string    `json:"node,omitempty"`   // 相关节点名称
+	Status  string    `json:"status,omitempty"` // 节点状态（节点事件）
+}
+
+// Sink 通知渠道
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// FuncSink 由函数实现的渠道（用于包装系统通知、托盘气泡等平台能力）
+type FuncSink struct {
+	name string
+	send func(n Notification) error
+}
+
+// NewFuncSink 创建函数渠道
+func NewFuncSink(name string, send func(n Notification) error) *FuncSink {
+	return &FuncSink{name: name, send: send}
+}
+
+// Name 渠道名称
+func (s *FuncSink) Name() string { return s.name }
+
+// Send 发送通知
+func (s *FuncSink) Send(ctx context.Context, n Notification) error { return s.send(n) }
+
+// =============================================================================
+// 分发
+// =============================================================================
+
+// Router 按事件类型把通知分发到配置的渠道
+type Router struct {
+	mu       sync.RWMutex
+	sinks    map[string]Sink
+	routes   map[Event][]string
+	defaults []string
+	onError  func(channel string, n Notification, err error)
+
+	nextID uint64
+}
+
+// NewRouter 创建分发器
+func NewRouter() *Router {
+	return &Router{
+		sinks:    make(map[string]Sink),
+		routes:   make(map[Event][]string),
+		defaults: DefaultChannels(),
+	}
+}
+
+// Register 注册渠道（同名渠道会被替换）
+func (r *Router) Register(sink Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks[sink.Name()] = sink
+}
+
+// Unregister 移除渠道
+func (r *Router) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sinks, name)
+}
+
+// SetRoutes 设置各事件类型的渠道；未配置的事件使用事件自身的默认渠道或 defaults（为 nil 时使用 DefaultChannels）
+func (r *Router) SetRoutes(routes map[Event][]string, defaults []string) {
+	if defaults == nil {
+		defaults = DefaultChannels()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = make(map[Event][]string, len(routes))
+	for event, channels := range routes {
+		r.routes[event] = append([]string(nil), channels...)
+	}
+	r.defaults = append([]string(nil), defaults...)
+}
+
+// SetErrorHandler 设置发送失败回调
+func (r *Router) SetErrorHandler(fn func(channel string, n Notification, err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onError = fn
+}
+
+// Route 返回事件类型当前使用的渠道
+func (r *Router) Route(event Event) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.routeLocked(event)...)
+}
+
+// Notify 异步发送通知到事件配置的渠道（未注册的渠道跳过）
+func (r *Router) Notify(event Event, title, message string) Notification {
+	return r.Dispatch(Notification{Event: event, Title: title, Message: message})
+}
+
+// Dispatch 异步发送填写了节点等附加信息的通知，自动分配 ID 和时间
+func (r *Router) Dispatch(n Notification) Notification {
+	n.ID = atomic.AddUint64(&r.nextID, 1)
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	r.mu.RLock()
+	onError := r.onError
+	var sinks []Sink
+	for _, name := range r.routeLocked(n.Event) {
+		if sink, ok := r.sinks[name]; ok {
+			sinks = append(sinks, sink)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, sink := range sinks {
+		go func(sink Sink) {
+			ctx, cancel := context.WithTimeout(context.Background(), SendTimeout)
+			defer cancel()
+			if err := sink.Send(ctx, n); err != nil && onError != nil {
+				onError(sink.Name(), n, err)
+			}
+		}(sink)
+	}
+	return n
+}
+
+// Test 向指定渠道同步发送一条测试通知
+func (r *Router) Test(channel, title, message string) error {
+	r.mu.RLock()
+	sink, ok := r.sinks[channel]
+	r.mu.RUnlock()
+	if !ok {
+		return i18n.Errorf("通知渠道 %s 未配置", channel)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SendTimeout)
+	defer cancel()
+	n := Notification{
+		ID:      atomic.AddUint64(&r.nextID, 1),
+		Event:   EventSystem,
+		Title:   title,
+		Message: message,
+		Time:    time.Now(),
+	}
+	if t, ok := sink.(interface {
+		SendTest(ctx context.Context, n Notification) error
+	}); ok {
+		return t.SendTest(ctx, n)
+	}
+	return sink.Send(ctx, n)
+}
+
+func (r *Router) routeLocked(event Event) []string {
+	if channels, ok := r.routes[event]; ok {
+		return channels
+	}
+	if channels, ok := eventDefaults[event]; ok {
+		return channels
+	}
+	return r.defaults
+}
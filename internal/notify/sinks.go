@@ -0,0 +1,284 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// =============================================================================
+// Webhook
+// =============================================================================
+
+// WebhookSink 以 POST 发送通知到指定地址：设置了模板时按模板生成请求体，否则发送默认 JSON
+type WebhookSink struct {
+	URL         string
+	Template    *template.Template  // 为空时发送默认 JSON
+	ContentType string              // 为空时为 application/json
+	Events      []Event             // 只发送这些事件，为空时发送全部事件
+	Client      func() *http.Client // 每次发送时获取（跟随内部请求出口设置）
+}
+
+// webhookPayload Webhook 请求体，同时作为模板数据（{{.Title}}、{{.Message}}、{{.Node}}、{{.Status}} 等）
+type webhookPayload struct {
+	App string `json:"app"`
+	Notification
+}
+
+// webhookFuncs 模板函数：json 把值编码为 JSON（字符串带引号并转义），用于拼接 JSON 请求体
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseWebhookTemplate 解析请求体模板
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Name 渠道名称
+func (s *WebhookSink) Name() string { return ChannelWebhook }
+
+// Accepts 是否发送该事件
+func (s *WebhookSink) Accepts(event Event) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Send 发送通知
+func (s *WebhookSink) Send(ctx context.Context, n Notification) error {
+	body, contentType, err := s.render(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Webhook 地址无效: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := doRequest(s.Client(), req); err != nil {
+		// 地址中常包含机器人令牌（Telegram、Discord、Slack），错误信息只保留主机名
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			if u, perr := url.Parse(s.URL); perr == nil {
+				return fmt.Errorf("%s: %w", u.Host, ue.Err)
+			}
+			return ue.Err
+		}
+		return err
+	}
+	return nil
+}
+
+// render 生成请求体与 Content-Type
+func (s *WebhookSink) render(n Notification) ([]byte, string, error) {
+	payload := webhookPayload{App: "xlink", Notification: n}
+	contentType := s.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	if s.Template == nil {
+		body, err := json.Marshal(payload)
+		return body, contentType, err
+	}
+	var buf bytes.Buffer
+	if err := s.Template.Execute(&buf, payload); err != nil {
+		return nil, "", fmt.Errorf("Webhook 模板执行失败: %w", err)
+	}
+	return buf.Bytes(), contentType, nil
+}
+
+// WebhookGroup 多个 Webhook 组成的渠道，通知发送到接受该事件的每个 Webhook
+type WebhookGroup struct {
+	Hooks []*WebhookSink
+}
+
+// Name 渠道名称
+func (g *WebhookGroup) Name() string { return ChannelWebhook }
+
+// Send 并发发送到接受该事件的各 Webhook，返回第一个错误
+func (g *WebhookGroup) Send(ctx context.Context, n Notification) error {
+	return g.send(ctx, n, false)
+}
+
+// SendTest 测试通知发送到全部 Webhook（不按事件筛选）
+func (g *WebhookGroup) SendTest(ctx context.Context, n Notification) error {
+	return g.send(ctx, n, true)
+}
+
+func (g *WebhookGroup) send(ctx context.Context, n Notification, all bool) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.Hooks))
+	for i, hook := range g.Hooks {
+		if !all && !hook.Accepts(n.Event) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, hook *WebhookSink) {
+			defer wg.Done()
+			errs[i] = hook.Send(ctx, n)
+		}(i, hook)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookPreset 常用机器人的 Webhook 模板
+type WebhookPreset struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"` // 地址示例，尖括号中的部分需替换
+	Template string `json:"template"`
+}
+
+// WebhookPresets 内置的 Telegram / Discord / Slack 模板
+func WebhookPresets() []WebhookPreset {
+	return []WebhookPreset{
+		{
+			Name:     "Telegram",
+			URL:      TelegramAPIBase + "/bot<token>/sendMessage",
+			Template: `{"chat_id": "<chat_id>", "text": {{json (printf "%s\n%s" .Title .Message)}}}`,
+		},
+		{
+			Name:     "Discord",
+			URL:      "https://discord.com/api/webhooks/<id>/<token>",
+			Template: `{"content": {{json (printf "**%s**\n%s" .Title .Message)}}}`,
+		},
+		{
+			Name:     "Slack",
+			URL:      "https://hooks.slack.com/services/<path>",
+			Template: `{"text": {{json (printf "*%s*\n%s" .Title .Message)}}}`,
+		},
+	}
+}
+
+// =============================================================================
+// Telegram
+// =============================================================================
+
+// TelegramAPIBase Telegram Bot API 地址
+const TelegramAPIBase = "https://api.telegram.org"
+
+// TelegramSink 通过 Telegram Bot 发送消息
+type TelegramSink struct {
+	Token  string
+	ChatID string
+	Client func() *http.Client
+}
+
+// Name 渠道名称
+func (s *TelegramSink) Name() string { return ChannelTelegram }
+
+// Send 发送通知
+func (s *TelegramSink) Send(ctx context.Context, n Notification) error {
+	form := url.Values{}
+	form.Set("chat_id", s.ChatID)
+	form.Set("text", n.Title+"\n"+n.Message)
+	form.Set("disable_web_page_preview", "true")
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", TelegramAPIBase, s.Token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := doRequest(s.Client(), req); err != nil {
+		// 请求地址中包含 Token，避免写入日志
+		return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), s.Token, "***"))
+	}
+	return nil
+}
+
+// doRequest 发送请求，非 2xx 响应视为失败
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// =============================================================================
+// 应用内通知中心
+// =============================================================================
+
+// CenterCapacity 通知中心保留的最大条数
+const CenterCapacity = 200
+
+// Center 应用内通知中心，保留最近的通知供界面展示
+type Center struct {
+	mu    sync.Mutex
+	items []Notification
+	onNew func(n Notification)
+}
+
+// NewCenter 创建通知中心，onNew 在收到新通知时调用（用于推送到前端）
+func NewCenter(onNew func(n Notification)) *Center {
+	return &Center{onNew: onNew}
+}
+
+// Name 渠道名称
+func (c *Center) Name() string { return ChannelCenter }
+
+// Send 记录通知
+func (c *Center) Send(ctx context.Context, n Notification) error {
+	c.mu.Lock()
+	c.items = append(c.items, n)
+	if len(c.items) > CenterCapacity {
+		c.items = c.items[len(c.items)-CenterCapacity:]
+	}
+	c.mu.Unlock()
+
+	if c.onNew != nil {
+		c.onNew(n)
+	}
+	return nil
+}
+
+// List 返回全部通知（最新的在前）
+func (c *Center) List() []Notification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]Notification, len(c.items))
+	for i, n := range c.items {
+		result[len(c.items)-1-i] = n
+	}
+	return result
+}
+
+// Clear 清空通知
+func (c *Center) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = nil
+}
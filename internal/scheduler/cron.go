@@ -0,0 +1,175 @@
+// Package scheduler 提供定时任务的时间表达式解析
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// 时间表达式
+// =============================================================================
+
+// 支持两种写法:
+//   "HH:MM"          每天的固定时间，如 "09:00"
+//   "分 时 日 月 周"  五段式 cron 表达式，如 "0 9 * * 1-5"（工作日 9:00）
+// 每段支持 *、数字、a-b 范围、逗号列表以及 /n 步长；周日可写作 0 或 7。
+// 与标准 cron 一致：日和周都不是 * 时，满足其一即可。
+
+// Spec 解析后的时间表达式
+type Spec struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"分", 0, 59},
+	{"时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"周", 0, 7},
+}
+
+// Parse 解析时间表达式
+func Parse(expr string) (*Spec, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("时间表达式为空")
+	}
+
+	if !strings.Contains(expr, " ") && strings.Contains(expr, ":") {
+		t, err := time.Parse("15:04", expr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时间: %s", expr)
+		}
+		expr = fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour())
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron 表达式需要 5 段（分 时 日 月 周），实际 %d 段", len(parts))
+	}
+
+	var bits [5]uint64
+	for i, p := range parts {
+		b, err := parseField(p, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	// 周日 7 等同于 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Spec{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField 解析单段，返回取值位图
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		step := 1
+		if idx := strings.Index(item, "/"); idx != -1 {
+			n, err := strconv.Atoi(item[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s段步长无效: %s", f.name, item)
+			}
+			step = n
+			item = item[:idx]
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case item == "*":
+		case strings.Contains(item, "-"):
+			r := strings.SplitN(item, "-", 2)
+			a, err1 := strconv.Atoi(r[0])
+			b, err2 := strconv.Atoi(r[1])
+			if err1 != nil || err2 != nil || a > b {
+				return 0, fmt.Errorf("%s段范围无效: %s", f.name, item)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(item)
+			if err != nil {
+				return 0, fmt.Errorf("%s段取值无效: %s", f.name, item)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > f.max {
+			return 0, fmt.Errorf("%s段超出范围 %d-%d: %s", f.name, f.min, f.max, s)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Matches 指定时间（精确到分钟）是否满足表达式
+func (s *Spec) Matches(t time.Time) bool {
+	return s.minute&(1<<uint(t.Minute())) != 0 &&
+		s.hour&(1<<uint(t.Hour())) != 0 &&
+		s.month&(1<<uint(t.Month())) != 0 &&
+		s.dayMatches(t)
+}
+
+// Next 返回 after 之后第一个满足表达式的时间（一年内找不到时返回零值）
+func (s *Spec) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(1, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches 日期是否满足（日、周段按 cron 规则组合）
+func (s *Spec) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dowOK
+	case s.dowStar:
+		return domOK
+	default:
+		return domOK || dowOK
+	}
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// at 构造 UTC 时间（2026-10-16 是周五）
+func at(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"25:00",
+		"9:60",
+		"* * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"09:00", at(10, 16, 8, 30), at(10, 16, 9, 0)},
+		{"09:00", at(10, 16, 9, 0), at(10, 17, 9, 0)},
+		{" 23:59 ", at(10, 16, 23, 59), at(10, 17, 23, 59)},
+		{"*/15 * * * *", at(10, 16, 10, 7), at(10, 16, 10, 15)},
+		{"*/15 * * * *", at(10, 16, 10, 45), at(10, 16, 11, 0)},
+		{"5/20 * * * *", at(10, 16, 10, 30), at(10, 16, 10, 45)},
+		{"0 9 * * 1-5", at(10, 16, 10, 0), at(10, 19, 9, 0)}, // 周五之后是下周一
+		{"30 8 * * 7", at(10, 16, 0, 0), at(10, 18, 8, 30)},  // 周日写作 7
+		{"30 8 * * 0", at(10, 16, 0, 0), at(10, 18, 8, 30)},  // 周日写作 0
+		{"0 0 1 * 0", at(10, 16, 12, 0), at(10, 18, 0, 0)},   // 日和周满足其一即可
+		{"0 0 1 * 0", at(10, 25, 12, 0), at(11, 1, 0, 0)},    // 11 月 1 日是周日
+		{"0 0 20 * *", at(10, 16, 12, 0), at(10, 20, 0, 0)},
+		{"5 4 * 12 *", at(10, 16, 0, 0), at(12, 1, 4, 5)},
+		{"0 6,18 * * *", at(10, 16, 6, 0), at(10, 16, 18, 0)},
+		{"0 0 31 * *", at(10, 31, 0, 0), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)}, // 跳过 11 月
+		{"0 12 29 2 *", at(10, 16, 0, 0), time.Time{}},                                  // 一年内没有 2 月 29 日
+	}
+	for _, tt := range tests {
+		spec, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := spec.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.expr, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"09:00", at(10, 16, 9, 0), true},
+		{"09:00", at(10, 16, 9, 1), false},
+		{"0 9 * * 1-5", at(10, 16, 9, 0), true},  // 周五
+		{"0 9 * * 1-5", at(10, 17, 9, 0), false}, // 周六
+		{"* * 16 10 *", at(10, 16, 13, 37), true},
+		{"* * 16 11 *", at(10, 16, 13, 37), false},
+	}
+	for _, tt := range tests {
+		spec, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := spec.Matches(tt.t); got != tt.want {
+			t.Errorf("Parse(%q).Matches(%s) = %v, want %v", tt.expr, tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Package socks5 SOCKS5 客户端（CONNECT 与 UDP ASSOCIATE），用于经节点的本地入站发起检测请求
+package socks5
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// =============================================================================
+// SOCKS5 客户端
+// =============================================================================
+
+// 只实现检测功能需要的部分：无认证或用户名密码认证（RFC 1929）、CONNECT 和 UDP ASSOCIATE。
+// 目标地址以域名发送，由代理解析（不在本机解析，避免检测请求本身产生 DNS 泄露）。
+
+// DefaultTimeout 未设置超时时握手的超时
+const DefaultTimeout = 10 * time.Second
+
+// 命令
+const (
+	cmdConnect      = 0x01
+	cmdUDPAssociate = 0x03
+)
+
+// Dialer 经 SOCKS5 代理建立连接
+type Dialer struct {
+	Addr     string // 代理地址 host:port
+	Username string // 为空时不认证
+	Password string
+	Timeout  time.Duration // 连接和握手的超时，0 表示 DefaultTimeout
+}
+
+func (d *Dialer) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return DefaultTimeout
+}
+
+// connect 连接代理并完成认证
+func (d *Dialer) connect(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: d.timeout()}
+	conn, err := dialer.DialContext(ctx, "tcp", d.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接代理失败: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(d.timeout()))
+	if err := Handshake(conn, d.Username, d.Password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// DialContext 经代理建立 TCP 连接（CONNECT），可用作 http.Transport.DialContext
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("SOCKS5 不支持的网络类型: %s", network)
+	}
+	addr, err := encodeAddr(address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := append([]byte{0x05, cmdConnect, 0x00}, addr...)
+	if _, err := conn.Write(req); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SOCKS 请求失败: %w", err)
+	}
+	if _, err := ReadReply(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// HTTPClient 所有请求都经代理发出的 HTTP 客户端
+func (d *Dialer) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         d.DialContext,
+			TLSHandshakeTimeout: d.timeout(),
+			DisableKeepAlives:   true,
+		},
+		Timeout: timeout,
+	}
+}
+
+// ListenUDP 建立 UDP 关联（UDP ASSOCIATE），之后发往任意目标的数据包都经代理转发
+func (d *Dialer) ListenUDP(ctx context.Context) (*UDPConn, error) {
+	ctrl, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 客户端地址填 0.0.0.0:0
+	if _, err := ctrl.Write([]byte{0x05, cmdUDPAssociate, 0x00, 0x01, 0, 0, 0, 0, 0, 0}); err != nil {
+		ctrl.Close()
+		return nil, fmt.Errorf("SOCKS 请求失败: %w", err)
+	}
+	relay, err := ReadReply(ctrl)
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	// 代理返回通配地址时改用代理的地址
+	if relay.IP == nil || relay.IP.IsUnspecified() {
+		host, _, _ := net.SplitHostPort(d.Addr)
+		relay.IP = net.ParseIP(host)
+	}
+
+	conn, err := net.DialUDP("udp", nil, relay)
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	ctrl.SetDeadline(time.Time{})
+	return &UDPConn{ctrl: ctrl, conn: conn, relay: relay}, nil
+}
+
+// UDPConn UDP 关联，控制连接关闭时关联结束
+type UDPConn struct {
+	ctrl  net.Conn
+	conn  *net.UDPConn
+	relay *net.UDPAddr
+}
+
+// RelayAddr 代理的 UDP 转发地址
+func (c *UDPConn) RelayAddr() *net.UDPAddr {
+	return c.relay
+}
+
+// WriteTo 经代理向 target（host:port）发送数据包
+func (c *UDPConn) WriteTo(p []byte, target string) (int, error) {
+	header, err := UDPHeader(target)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.conn.Write(append(header, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Read 读取一个经代理返回的数据包（去掉 SOCKS5 头）
+func (c *UDPConn) Read(p []byte) (int, error) {
+	buf := make([]byte, 65535)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	payload, err := StripUDPHeader(buf[:n])
+	if err != nil {
+		return 0, err
+	}
+	return copy(p, payload), nil
+}
+
+// SetDeadline 设置读写超时
+func (c *UDPConn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+// Close 关闭 UDP 套接字和控制连接
+func (c *UDPConn) Close() error {
+	c.conn.Close()
+	return c.ctrl.Close()
+}
+
+// =============================================================================
+// 协议编解码
+// =============================================================================
+
+// Handshake SOCKS5 方法协商（及 RFC 1929 用户名密码认证）
+func Handshake(conn net.Conn, username, password string) error {
+	method := byte(0x00)
+	if username != "" {
+		method = 0x02
+	}
+	if _, err := conn.Write([]byte{0x05, 0x01, method}); err != nil {
+		return fmt.Errorf("SOCKS握手失败: %w", err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("SOCKS握手失败: %w", err)
+	}
+	if reply[0] != 0x05 || reply[1] != method {
+		return fmt.Errorf("SOCKS握手被拒绝: %x", reply)
+	}
+	if username == "" {
+		return nil
+	}
+
+	req := []byte{0x01, byte(len(username))}
+	req = append(req, username...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("SOCKS认证失败: %w", err)
+	}
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("SOCKS认证失败: %w", err)
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("SOCKS认证被拒绝")
+	}
+	return nil
+}
+
+// ReadReply 读取命令响应，返回绑定地址（域名类型的地址只返回端口）
+func ReadReply(conn net.Conn) (*net.UDPAddr, error) {
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return nil, fmt.Errorf("读取 SOCKS 响应失败: %w", err)
+	}
+	if head[1] != 0x00 {
+		return nil, fmt.Errorf("代理拒绝了请求 (错误码 %d)", head[1])
+	}
+
+	var ip net.IP
+	switch head[3] {
+	case 0x01:
+		ip = make(net.IP, 4)
+	case 0x04:
+		ip = make(net.IP, 16)
+	case 0x03:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(conn, l); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(conn, make([]byte, l[0])); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的地址类型: %d", head[3])
+	}
+	if ip != nil {
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return nil, err
+		}
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return nil, err
+	}
+	return &net.UDPAddr{IP: ip, Port: int(binary.BigEndian.Uint16(port))}, nil
+}
+
+// UDPHeader UDP 数据包头（RSV, FRAG, 目标地址）
+func UDPHeader(target string) ([]byte, error) {
+	addr, err := encodeAddr(target)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{0, 0, 0}, addr...), nil
+}
+
+// StripUDPHeader 去掉 UDP 数据包头
+func StripUDPHeader(packet []byte) ([]byte, error) {
+	if len(packet) < 4 || !bytes.Equal(packet[:3], []byte{0, 0, 0}) {
+		return nil, fmt.Errorf("UDP 响应格式无效")
+	}
+	var addrLen int
+	switch packet[3] {
+	case 0x01:
+		addrLen = 4
+	case 0x04:
+		addrLen = 16
+	case 0x03:
+		if len(packet) < 5 {
+			return nil, fmt.Errorf("UDP 响应格式无效")
+		}
+		addrLen = 1 + int(packet[4])
+	default:
+		return nil, fmt.Errorf("UDP 响应格式无效")
+	}
+	offset := 4 + addrLen + 2
+	if len(packet) < offset {
+		return nil, fmt.Errorf("UDP 响应格式无效")
+	}
+	return packet[offset:], nil
+}
+
+// encodeAddr 编码目标地址（ATYP, 地址, 端口）
+func encodeAddr(target string) ([]byte, error) {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("端口无效: %s", portStr)
+	}
+
+	var addr []byte
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			addr = append([]byte{0x01}, ip4...)
+		} else {
+			addr = append([]byte{0x04}, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return nil, fmt.Errorf("域名过长: %s", host)
+		}
+		addr = append([]byte{0x03, byte(len(host))}, host...)
+	}
+	return binary.BigEndian.AppendUint16(addr, uint16(port)), nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package syschange
+
+import "os/exec"
+
+// hideWindow 非 Windows 平台无需处理
+func hideWindow(cmd *exec.Cmd) {}
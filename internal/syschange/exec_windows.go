@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package syschange
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow 不弹出控制台窗口
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
@@ -0,0 +1,158 @@
+package syschange
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// 系统修改日志（崩溃恢复）
+// =============================================================================
+
+// 每次修改系统设置前先写入一条带撤销信息的记录，正常撤销后删除。
+// 程序异常退出后残留的记录在下次启动时按相反顺序撤销，避免系统代理、DNS、路由等停留在修改后的状态。
+
+// 记录类型
+const (
+	KindProxy      = "proxy"       // 系统代理 / PAC
+	KindDNS        = "dns"         // 网卡 DNS
+	KindRoute      = "route"       // 默认路由
+	KindKillSwitch = "kill_switch" // 断线保护防火墙规则
+)
+
+// Entry 一条尚未撤销的系统修改
+type Entry struct {
+	Key   string          `json:"key"` // 同一 Key 的新记录覆盖旧记录
+	Kind  string          `json:"kind"`
+	Title string          `json:"title"`
+	Time  time.Time       `json:"time"`
+	Undo  *Plan           `json:"undo,omitempty"` // 撤销命令
+	Data  json.RawMessage `json:"data,omitempty"` // 无法用命令表示的撤销数据（如原系统代理设置）
+}
+
+// Journal 系统修改日志，每次变更都同步写入磁盘
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	entries []Entry
+}
+
+// OpenJournal 打开日志文件，文件不存在时为空
+// 文件损坏时返回空日志和错误，后续写入会覆盖损坏的文件
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return j, err
+	}
+	if err := json.Unmarshal(data, &j.entries); err != nil {
+		return j, fmt.Errorf("系统修改日志已损坏: %w", err)
+	}
+	return j, nil
+}
+
+// Record 在修改系统设置前记录撤销方式；undo 与 data 至少提供一个
+func (j *Journal) Record(kind, key, title string, undo *Plan, data interface{}) error {
+	entry := Entry{Key: key, Kind: kind, Title: title, Time: time.Now(), Undo: undo}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		entry.Data = raw
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.removeLocked(key)
+	j.entries = append(j.entries, entry)
+	return j.saveLocked()
+}
+
+// Resolve 修改已撤销，删除记录
+func (j *Journal) Resolve(key string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.removeLocked(key) {
+		return nil
+	}
+	return j.saveLocked()
+}
+
+// Has 是否存在指定记录
+func (j *Journal) Has(key string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, e := range j.entries {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending 未撤销的记录，按撤销顺序（最近的在前）
+func (j *Journal) Pending() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	result := make([]Entry, 0, len(j.entries))
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		result = append(result, j.entries[i])
+	}
+	return result
+}
+
+func (j *Journal) removeLocked(key string) bool {
+	for i, e := range j.entries {
+		if e.Key == key {
+			j.entries = append(j.entries[:i], j.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// saveLocked 写入临时文件并同步到磁盘后改名，保证崩溃时文件完整
+func (j *Journal) saveLocked() error {
+	if len(j.entries) == 0 {
+		if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(j.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package syschange 描述并执行会修改系统设置的命令（DNS、路由、防火墙）
+// 所有修改先生成执行计划，预览模式下只返回计划供用户确认，不实际执行
+package syschange
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// 执行计划
+// =============================================================================
+
+// Command 一条系统命令
+type Command struct {
+	Description string   `json:"description"`
+	Program     string   `json:"program"`
+	Args        []string `json:"args"`
+	Line        string   `json:"line"`         // 可直接粘贴到命令行执行的完整命令
+	IgnoreError bool     `json:"ignore_error"` // 失败时继续执行后续命令
+
+	// 优先执行的内置实现（直接调用系统 API，见 RegisterNative），不可用或失败时执行上面的命令
+	Native     string   `json:"native,omitempty"`
+	NativeArgs []string `json:"native_args,omitempty"`
+}
+
+// NativeFunc 命令的内置实现
+type NativeFunc func(args []string) error
+
+var (
+	nativeMu sync.RWMutex
+	natives  = make(map[string]NativeFunc)
+)
+
+// RegisterNative 注册内置实现（在 init 中调用）；日志中恢复的计划同样按名称使用内置实现
+func RegisterNative(name string, fn NativeFunc) {
+	nativeMu.Lock()
+	defer nativeMu.Unlock()
+	natives[name] = fn
+}
+
+// Plan 一组按顺序执行的系统命令
+type Plan struct {
+	Title         string    `json:"title"`
+	DryRun        bool      `json:"dry_run"` // 仅预览，未执行
+	RequiresAdmin bool      `json:"requires_admin"`
+	Commands      []Command `json:"commands"`
+}
+
+// NewPlan 创建执行计划
+func NewPlan(title string, requiresAdmin bool) *Plan {
+	return &Plan{Title: title, RequiresAdmin: requiresAdmin}
+}
+
+// Add 追加命令，失败时中止
+func (p *Plan) Add(description, program string, args ...string) *Plan {
+	p.Commands = append(p.Commands, newCommand(description, false, program, args))
+	return p
+}
+
+// AddOptional 追加命令，失败时忽略
+func (p *Plan) AddOptional(description, program string, args ...string) *Plan {
+	p.Commands = append(p.Commands, newCommand(description, true, program, args))
+	return p
+}
+
+// AddNative 追加优先以内置实现 native 执行的命令，内置实现不可用或失败时执行命令行，仍失败时中止
+func (p *Plan) AddNative(description, native string, nativeArgs []string, program string, args ...string) *Plan {
+	c := newCommand(description, false, program, args)
+	c.Native, c.NativeArgs = native, nativeArgs
+	p.Commands = append(p.Commands, c)
+	return p
+}
+
+// Lines 所有命令的命令行形式
+func (p *Plan) Lines() []string {
+	lines := make([]string, 0, len(p.Commands))
+	for _, c := range p.Commands {
+		lines = append(lines, c.Line)
+	}
+	return lines
+}
+
+// Run 依次执行命令（隐藏窗口），遇到非可选命令失败时停止
+func (p *Plan) Run() error {
+	for _, c := range p.Commands {
+		if err := c.run(); err != nil && !c.IgnoreError {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunAll 执行全部命令，不因失败中止，返回各条命令的错误
+func (p *Plan) RunAll() []error {
+	var errs []error
+	for _, c := range p.Commands {
+		if err := c.run(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (c Command) run() error {
+	nativeMu.RLock()
+	native := natives[c.Native]
+	nativeMu.RUnlock()
+	var nativeErr error
+	if native != nil {
+		if nativeErr = native(c.NativeArgs); nativeErr == nil {
+			return nil
+		}
+	}
+
+	err := c.runProgram()
+	if err != nil && nativeErr != nil {
+		return fmt.Errorf("%w；系统 API: %v", err, nativeErr)
+	}
+	return err
+}
+
+func (c Command) runProgram() error {
+	cmd := exec.Command(c.Program, c.Args...)
+	hideWindow(cmd)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(string(output)); msg != "" {
+		return fmt.Errorf("%s失败: %v (%s)", c.Description, err, msg)
+	}
+	return fmt.Errorf("%s失败: %v", c.Description, err)
+}
+
+func newCommand(description string, optional bool, program string, args []string) Command {
+	return Command{
+		Description: description,
+		Program:     program,
+		Args:        args,
+		Line:        commandLine(program, args),
+		IgnoreError: optional,
+	}
+}
+
+// commandLine 拼接命令行，含空格的参数加引号
+// netsh 的 key=value 参数只给 value 部分加引号，与手工输入时的写法一致
+func commandLine(program string, args []string) string {
+	parts := []string{program}
+	for _, a := range args {
+		parts = append(parts, quoteArg(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoteArg(a string) string {
+	if a != "" && !strings.ContainsAny(a, " \t\"") {
+		return a
+	}
+	if k, v, ok := strings.Cut(a, "="); ok && !strings.ContainsAny(k, " \t\"") {
+		return k + `="` + v + `"`
+	}
+	return `"` + a + `"`
+}
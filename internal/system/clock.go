@@ -0,0 +1,148 @@
+package system
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// =============================================================================
+// 系统时间校验
+// =============================================================================
+
+// 系统时间偏差过大时 TLS 证书校验失败，ECH / REALITY 等协议握手被服务器拒绝，表现为莫名的连接失败。
+// 优先直连 NTP 服务器测量偏差；UDP 123 端口被封锁时改为读取 HTTP 响应的 Date 头（可经代理，精度 1 秒）。
+
+// ClockSkewThreshold 时间偏差超过该值时提醒
+const ClockSkewThreshold = 30 * time.Second
+
+// DefaultNTPServers 默认 NTP 服务器
+var DefaultNTPServers = []string{"ntp.aliyun.com", "time.windows.com", "pool.ntp.org"}
+
+// DefaultTimeURLs 无法使用 NTP 时读取 Date 头的地址（使用 HTTP，避免时间偏差导致证书校验失败）
+var DefaultTimeURLs = []string{"http://www.msftconnecttest.com/connecttest.txt", "http://www.baidu.com"}
+
+// ntpEpochOffset NTP 纪元（1900 年）与 Unix 纪元之间的秒数
+const ntpEpochOffset = 2208988800
+
+// ClockCheckResult 时间校验结果
+type ClockCheckResult struct {
+	Source    string `json:"source"`    // 测量来源，如 "ntp://ntp.aliyun.com" 或 HTTP 地址
+	OffsetMs  int64  `json:"offset_ms"` // 本机时间减标准时间，正数表示本机时间偏快
+	RTTMs     int64  `json:"rtt_ms"`
+	Skewed    bool   `json:"skewed"`    // 偏差超过提醒阈值
+	Threshold int    `json:"threshold"` // 提醒阈值（秒）
+	CheckedAt int64  `json:"checked_at"`
+	Error     string `json:"error,omitempty"` // 所有来源都无法测量时的错误
+}
+
+// CheckClock 依次尝试 NTP 服务器与 HTTP 地址，以第一个成功的测量结果为准
+func CheckClock(ntpServers []string, client *http.Client, timeURLs []string) *ClockCheckResult {
+	result := &ClockCheckResult{
+		Threshold: int(ClockSkewThreshold / time.Second),
+		CheckedAt: time.Now().Unix(),
+	}
+
+	var lastErr error
+	measured := func(source string, offset, rtt time.Duration) *ClockCheckResult {
+		result.Source = source
+		result.OffsetMs = offset.Milliseconds()
+		result.RTTMs = rtt.Milliseconds()
+		result.Skewed = offset > ClockSkewThreshold || offset < -ClockSkewThreshold
+		return result
+	}
+
+	for _, server := range ntpServers {
+		offset, rtt, err := QueryNTP(server, 3*time.Second)
+		if err == nil {
+			return measured("ntp://"+server, offset, rtt)
+		}
+		lastErr = err
+	}
+	if client != nil {
+		for _, u := range timeURLs {
+			offset, rtt, err := HTTPDateOffset(client, u)
+			if err == nil {
+				return measured(u, offset, rtt)
+			}
+			lastErr = err
+		}
+	}
+
+	if lastErr != nil {
+		result.Error = lastErr.Error()
+	} else {
+		result.Error = "没有可用的时间来源"
+	}
+	return result
+}
+
+// QueryNTP 向 NTP 服务器发送 SNTP 请求，返回本机时间相对服务器时间的偏差和往返时延
+func QueryNTP(server string, timeout time.Duration) (offset, rtt time.Duration, err error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(server, "123"), timeout)
+	if err != nil {
+		return 0, 0, fmt.Errorf("连接 NTP 服务器 %s 失败: %w", server, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// LI=0, VN=4, Mode=3 (客户端)
+	req := make([]byte, 48)
+	req[0] = 0x23
+
+	t1 := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, 0, fmt.Errorf("NTP 请求失败: %w", err)
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	t4 := time.Now()
+	if err != nil {
+		return 0, 0, fmt.Errorf("NTP 服务器 %s 无响应: %w", server, err)
+	}
+	if n < 48 || resp[0]&0x07 != 4 || resp[1] == 0 {
+		return 0, 0, fmt.Errorf("NTP 服务器 %s 的响应无效", server)
+	}
+
+	t2 := ntpTime(resp[32:40]) // 服务器收到请求的时间
+	t3 := ntpTime(resp[40:48]) // 服务器发出响应的时间
+
+	// 标准 NTP 计算：偏差 = ((t2 - t1) + (t3 - t4)) / 2，结果取反为本机相对服务器的偏差
+	offset = -(t2.Sub(t1) + t3.Sub(t4)) / 2
+	rtt = t4.Sub(t1) - t3.Sub(t2)
+	return offset, rtt, nil
+}
+
+// ntpTime 解析 64 位 NTP 时间戳
+func ntpTime(b []byte) time.Time {
+	sec := binary.BigEndian.Uint32(b[0:4])
+	frac := binary.BigEndian.Uint32(b[4:8])
+	nsec := (int64(frac) * 1e9) >> 32
+	return time.Unix(int64(sec)-ntpEpochOffset, nsec)
+}
+
+// HTTPDateOffset 根据 HTTP 响应的 Date 头估算本机时间偏差（以请求往返的中点为准）
+func HTTPDateOffset(client *http.Client, url string) (offset, rtt time.Duration, err error) {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("请求 %s 失败: %w", url, err)
+	}
+	resp.Body.Close()
+	rtt = time.Since(start)
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s 的响应没有有效的 Date 头", url)
+	}
+	// Date 只精确到秒，按半秒补偿截断误差
+	server := date.Add(500 * time.Millisecond)
+	local := start.Add(rtt / 2)
+	return local.Sub(server), rtt, nil
+}
@@ -0,0 +1,185 @@
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// 组件完整性自检
+// =============================================================================
+
+// 组件建议操作
+const (
+	ComponentActionNone     = ""
+	ComponentActionDownload = "download" // 缺失，需要下载
+	ComponentActionUpdate   = "update"   // 已过期，建议更新
+)
+
+// geoDataMaxAge 规则数据超过此时间未更新时建议更新
+const geoDataMaxAge = 30 * 24 * time.Hour
+
+// ComponentStatus 单个配套组件的状态
+type ComponentStatus struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Required    bool      `json:"required"` // 缺失时客户端无法运行
+	Present     bool      `json:"present"`
+	Path        string    `json:"path,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	SHA256      string    `json:"sha256,omitempty"`
+	Size        int64     `json:"size"`
+	LastUpdated time.Time `json:"last_updated"`
+	Action      string    `json:"action,omitempty"`
+	Hint        string    `json:"hint,omitempty"`
+}
+
+// componentSpec 组件定义
+type componentSpec struct {
+	name        string
+	description string
+	required    bool
+	geoData     bool     // 规则数据，按修改时间判断是否过期
+	extraPaths  []string // 除程序目录外的查找位置
+	missingHint string
+}
+
+var componentSpecs = []componentSpec{
+	{
+		name:        "xlink-cli-binary.exe",
+		description: "Xlink 核心",
+		required:    true,
+		missingHint: "缺少核心程序，节点无法启动，请重新下载完整安装包",
+	},
+	{
+		name:        "xray.exe",
+		description: "Xray 分流前端（智能分流模式）",
+		missingHint: "缺少 Xray，智能分流模式不可用",
+	},
+	{
+		name:        "sing-box.exe",
+		description: "sing-box 分流前端（智能分流模式，可选）",
+		missingHint: "缺少 sing-box，选择 sing-box 作为前端的节点无法启动",
+	},
+	{
+		name:        "wintun.dll",
+		description: "Wintun 驱动（TUN 模式）",
+		extraPaths:  []string{`C:\Windows\System32\wintun.dll`},
+		missingHint: "缺少 Wintun 驱动，TUN 模式不可用",
+	},
+	{
+		name:        "geosite.dat",
+		description: "域名分流规则数据",
+		geoData:     true,
+		missingHint: "缺少 geosite.dat，geosite: 规则和中国域名直连将不生效",
+	},
+	{
+		name:        "geoip.dat",
+		description: "IP 分流规则数据",
+		geoData:     true,
+		missingHint: "缺少 geoip.dat，geoip: 规则和中国IP直连将不生效",
+	},
+}
+
+// CheckComponents 检查程序目录中的配套组件
+func CheckComponents(exeDir string) []ComponentStatus {
+	result := make([]ComponentStatus, 0, len(componentSpecs))
+	for _, spec := range componentSpecs {
+		result = append(result, checkComponent(exeDir, spec))
+	}
+	return result
+}
+
+func checkComponent(exeDir string, spec componentSpec) ComponentStatus {
+	status := ComponentStatus{
+		Name:        spec.name,
+		Description: spec.description,
+		Required:    spec.required,
+	}
+
+	paths := append([]string{filepath.Join(exeDir, spec.name)}, spec.extraPaths...)
+	var info os.FileInfo
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			status.Path, info = p, fi
+			break
+		}
+	}
+
+	if info == nil {
+		status.Action = ComponentActionDownload
+		status.Hint = spec.missingHint
+		return status
+	}
+
+	status.Present = true
+	status.Size = info.Size()
+	status.LastUpdated = info.ModTime()
+	status.SHA256 = fileSHA256(status.Path)
+
+	switch {
+	case spec.geoData:
+		status.Version = info.ModTime().Format("20060102")
+		if time.Since(info.ModTime()) > geoDataMaxAge {
+			status.Action = ComponentActionUpdate
+			status.Hint = "规则数据已超过 30 天未更新，可在设置中立即更新"
+		}
+	case spec.name == "xray.exe":
+		status.Version = xrayVersion(status.Path)
+	case spec.name == "sing-box.exe":
+		status.Version = singBoxVersion(status.Path)
+	default:
+		status.Version = fileVersion(status.Path)
+	}
+
+	if info.Size() == 0 {
+		status.Action = ComponentActionDownload
+		status.Hint = "文件为空，可能已损坏"
+	}
+	return status
+}
+
+// xrayVersion 通过 `xray version` 获取版本（输出首行形如 "Xray 1.8.4 (...)"）
+func xrayVersion(path string) string {
+	output, err := runVersionCommand(path, "version")
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(strings.SplitN(output, "\n", 2)[0])
+	if len(fields) >= 2 && strings.EqualFold(fields[0], "xray") {
+		return fields[1]
+	}
+	return ""
+}
+
+// singBoxVersion 通过 `sing-box version` 获取版本（输出首行形如 "sing-box version 1.11.0"）
+func singBoxVersion(path string) string {
+	output, err := runVersionCommand(path, "version")
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(strings.SplitN(output, "\n", 2)[0])
+	if len(fields) >= 3 && strings.EqualFold(fields[0], "sing-box") && fields[1] == "version" {
+		return fields[2]
+	}
+	return ""
+}
+
+func fileSHA256(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// fileVersion 非 Windows 平台没有 PE 版本资源
+func fileVersion(path string) string {
+	return ""
+}
+
+// runVersionCommand 运行组件的版本命令
+func runVersionCommand(path string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, args...).Output()
+	return string(output), err
+}
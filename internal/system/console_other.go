@@ -0,0 +1,7 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// AttachParentConsole 非Windows平台标准输出始终可用
+func AttachParentConsole() {}
@@ -0,0 +1,51 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	modkernel32Console    = syscall.NewLazyDLL("kernel32.dll")
+	procAttachConsole     = modkernel32Console.NewProc("AttachConsole")
+	attachParentProcessID = ^uintptr(0) // ATTACH_PARENT_PROCESS (DWORD -1)
+)
+
+// AttachParentConsole 将 GUI 程序的标准输出/错误连接到父进程控制台
+// 程序以 windowsgui 子系统构建，默认没有控制台；已被重定向的句柄保持不变
+func AttachParentConsole() {
+	if isValidHandle(os.Stdout) && isValidHandle(os.Stderr) {
+		return
+	}
+
+	r, _, _ := procAttachConsole.Call(attachParentProcessID)
+	if r == 0 {
+		return
+	}
+
+	if !isValidHandle(os.Stdout) {
+		if f, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+			os.Stdout = f
+		}
+	}
+	if !isValidHandle(os.Stderr) {
+		if f, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+			os.Stderr = f
+		}
+	}
+}
+
+func isValidHandle(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	h := syscall.Handle(f.Fd())
+	if h == 0 || h == syscall.InvalidHandle {
+		return false
+	}
+	_, err := syscall.GetFileType(h)
+	return err == nil
+}
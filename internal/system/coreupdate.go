@@ -0,0 +1,299 @@
+package system
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// 内核程序更新
+// =============================================================================
+
+// 更新源是一个 JSON 清单，列出各内核程序的最新版本:
+//
+//	{
+//	  "components": [
+//	    {"name": "xray.exe", "version": "1.8.24", "url": "xray-1.8.24.exe", "sha256": "...", "size": 28311552}
+//	  ]
+//	}
+//
+// url 可以是相对于清单地址的路径；sha256 必填，下载后校验不一致时放弃更新。
+
+// CoreUpdateTargets 支持自动更新的内核程序
+var CoreUpdateTargets = []string{"xlink-cli-binary.exe", "xray.exe"}
+
+const (
+	// coreManifestMaxSize 清单大小上限
+	coreManifestMaxSize = 1 << 20
+	// coreBinaryMaxSize 内核程序大小上限，防止更新源异常时写满磁盘
+	coreBinaryMaxSize = 256 << 20
+	// coreBackupSuffix 替换时旧文件的备份后缀（运行中的程序不能删除，但可以改名）
+	coreBackupSuffix = ".old"
+)
+
+// CoreRelease 更新源中的一个内核版本
+type CoreRelease struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size,omitempty"`
+	Feed    string `json:"feed,omitempty"` // 来源清单（解析后填充）
+}
+
+// coreManifest 更新源清单
+type coreManifest struct {
+	Components []CoreRelease `json:"components"`
+}
+
+// CoreUpdateInfo 单个内核程序的更新检查结果
+type CoreUpdateInfo struct {
+	Name           string       `json:"name"`
+	CurrentVersion string       `json:"current_version"`
+	CurrentSHA256  string       `json:"current_sha256"`
+	Present        bool         `json:"present"`
+	Latest         *CoreRelease `json:"latest,omitempty"`
+	Available      bool         `json:"available"`
+}
+
+// FetchCoreReleases 依次读取更新源，返回每个内核程序的最新版本（先出现的更新源优先）
+// 全部更新源都失败时返回错误
+func FetchCoreReleases(ctx context.Context, client *http.Client, feeds []string) (map[string]CoreRelease, error) {
+	releases := make(map[string]CoreRelease)
+	var errs []string
+	ok := false
+
+	for _, feed := range feeds {
+		manifest, err := fetchCoreManifest(ctx, client, feed)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", feed, err))
+			continue
+		}
+		ok = true
+
+		for _, rel := range manifest.Components {
+			if !isCoreUpdateTarget(rel.Name) || rel.URL == "" || len(rel.SHA256) != sha256.Size*2 {
+				continue
+			}
+			if _, exists := releases[rel.Name]; exists {
+				continue
+			}
+			resolved, err := resolveURL(feed, rel.URL)
+			if err != nil {
+				continue
+			}
+			rel.URL, rel.Feed = resolved, feed
+			releases[rel.Name] = rel
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("读取更新源失败: %s", strings.Join(errs, "; "))
+	}
+	return releases, nil
+}
+
+// CheckCoreUpdates 对比本地内核程序与更新源中的版本
+func CheckCoreUpdates(exeDir string, releases map[string]CoreRelease) []CoreUpdateInfo {
+	result := make([]CoreUpdateInfo, 0, len(CoreUpdateTargets))
+	for _, spec := range componentSpecs {
+		if !isCoreUpdateTarget(spec.name) {
+			continue
+		}
+		status := checkComponent(exeDir, spec)
+		info := CoreUpdateInfo{
+			Name:           spec.name,
+			CurrentVersion: status.Version,
+			CurrentSHA256:  status.SHA256,
+			Present:        status.Present && status.Size > 0,
+		}
+		if rel, ok := releases[spec.name]; ok {
+			info.Latest = &rel
+			info.Available = coreUpdateAvailable(info, rel)
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// coreUpdateAvailable 文件内容不同且更新源版本不比本地旧时视为有更新
+func coreUpdateAvailable(info CoreUpdateInfo, rel CoreRelease) bool {
+	if !info.Present {
+		return true
+	}
+	if strings.EqualFold(info.CurrentSHA256, rel.SHA256) {
+		return false
+	}
+	return CompareVersions(rel.Version, info.CurrentVersion) >= 0
+}
+
+// DownloadCoreRelease 下载内核程序到程序目录下的临时文件并校验 SHA256，返回临时文件路径
+// 调用方负责在替换后或放弃时删除临时文件
+func DownloadCoreRelease(ctx context.Context, client *http.Client, exeDir string, rel CoreRelease) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rel.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	// 临时文件与目标在同一目录，保证替换时的改名是原子的
+	tmp, err := os.CreateTemp(exeDir, rel.Name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败: %w", err)
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(resp.Body, coreBinaryMaxSize+1))
+	tmp.Close()
+	switch {
+	case err != nil:
+		err = fmt.Errorf("下载失败: %w", err)
+	case size > coreBinaryMaxSize:
+		err = fmt.Errorf("文件超过大小上限")
+	case rel.Size > 0 && size != rel.Size:
+		err = fmt.Errorf("文件大小不一致: %d / %d", size, rel.Size)
+	case !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), rel.SHA256):
+		err = fmt.Errorf("SHA256 校验失败")
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// SwapCoreBinary 用已校验的临时文件替换内核程序
+// 旧文件先改名为 .old 备份，新文件就位失败时恢复备份；备份在替换成功后尽量删除，
+// 删除失败（文件仍被占用）时留待 CleanupCoreBackups 处理
+func SwapCoreBinary(exeDir, name, tmpPath string) error {
+	target := filepath.Join(exeDir, name)
+	backup := target + coreBackupSuffix
+
+	os.Remove(backup)
+	hadOld := false
+	if _, err := os.Stat(target); err == nil {
+		if err := os.Rename(target, backup); err != nil {
+			return fmt.Errorf("备份旧文件失败: %w", err)
+		}
+		hadOld = true
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		if hadOld {
+			os.Rename(backup, target)
+		}
+		return fmt.Errorf("替换文件失败: %w", err)
+	}
+
+	if hadOld {
+		os.Remove(backup)
+	}
+	return nil
+}
+
+// CleanupCoreBackups 删除上次更新遗留的备份和临时文件
+func CleanupCoreBackups(exeDir string) {
+	for _, name := range CoreUpdateTargets {
+		os.Remove(filepath.Join(exeDir, name+coreBackupSuffix))
+		matches, _ := filepath.Glob(filepath.Join(exeDir, name+".*.tmp"))
+		for _, m := range matches {
+			os.Remove(m)
+		}
+	}
+}
+
+// CompareVersions 比较点分数字版本（忽略前缀 v 和预发布后缀），无法解析的部分按 0 处理
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexAny(v, "-+ "); idx != -1 {
+		v = v[:idx]
+	}
+	if v == "" {
+		return nil
+	}
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+func fetchCoreManifest(ctx context.Context, client *http.Client, feed string) (*coreManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var manifest coreManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, coreManifestMaxSize)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("清单格式错误: %w", err)
+	}
+	return &manifest, nil
+}
+
+func resolveURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+func isCoreUpdateTarget(name string) bool {
+	for _, t := range CoreUpdateTargets {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// =============================================================================
+// 用户数据目录（多用户隔离）
+// =============================================================================
+
+// PortableMarkerFile 程序目录中存在该文件时为便携模式，配置、日志等数据仍保存在程序目录
+const PortableMarkerFile = "portable"
+
+// userDataAppName 用户数据目录名（%APPDATA% 下）
+const userDataAppName = "XlinkClient"
+
+// ResolveDataDir 确定保存配置、日志、备份等数据的目录
+// 默认为每个 Windows 用户独立的目录，同一台电脑的多个用户共用程序目录时互不影响
+func ResolveDataDir(exeDir string) (dir string, portable bool, err error) {
+	if IsPortable(exeDir) {
+		return exeDir, true, nil
+	}
+	dir, err = GetAppDataDir(userDataAppName)
+	if err != nil {
+		return exeDir, false, fmt.Errorf("创建用户数据目录失败: %w", err)
+	}
+	return dir, false, nil
+}
+
+// IsPortable 程序目录中是否有便携模式标记
+func IsPortable(exeDir string) bool {
+	return fileExists(filepath.Join(exeDir, PortableMarkerFile))
+}
+
+// MigrateLegacyData 检测旧版共享布局（数据保存在程序目录）并复制到用户数据目录，返回已复制的文件
+// 只在用户数据目录中还没有任何配置文件时迁移；程序目录中的文件保留，供其他用户首次启动时迁移
+// configFiles 为配置文件（用于检测旧布局），stateFiles 为一并复制的文件或目录（不存在时跳过）
+func MigrateLegacyData(exeDir, dataDir string, configFiles, stateFiles []string) ([]string, error) {
+	if filepath.Clean(exeDir) == filepath.Clean(dataDir) {
+		return nil, nil
+	}
+	legacy := false
+	for _, name := range configFiles {
+		if fileExists(filepath.Join(dataDir, name)) {
+			return nil, nil
+		}
+		if fileExists(filepath.Join(exeDir, name)) {
+			legacy = true
+		}
+	}
+	if !legacy {
+		return nil, nil
+	}
+
+	var copied []string
+	for _, name := range append(append([]string(nil), configFiles...), stateFiles...) {
+		src := filepath.Join(exeDir, name)
+		info, err := os.Stat(src)
+		if err != nil {
+			continue
+		}
+		dst := filepath.Join(dataDir, name)
+		if info.IsDir() {
+			err = copyDir(src, dst)
+		} else {
+			err = copyFile(src, dst)
+		}
+		if err != nil {
+			os.RemoveAll(dst) // 不留下不完整的文件，下次启动重新迁移
+			return copied, fmt.Errorf("迁移 %s 失败: %w", name, err)
+		}
+		copied = append(copied, name)
+	}
+	return copied, nil
+}
+
+// =============================================================================
+// 存储模式切换
+// =============================================================================
+
+// storageMoveFile 切换存储模式后写在新数据目录中，记录待迁移的旧目录。
+// 迁移在下次启动、打开配置和日志之前进行，运行中的文件不会被移动。
+const storageMoveFile = "move_pending"
+
+// UserDataDir 当前用户的数据目录（%APPDATA%\XlinkClient）
+func UserDataDir() (string, error) {
+	return GetAppDataDir(userDataAppName)
+}
+
+// SetStorageMode 切换存储模式（重启后生效），返回新的数据目录
+// portable 为 true 时写入便携模式标记，数据保存在程序目录（程序目录须可写），否则删除标记改用用户数据目录；
+// 新目录与 currentDir 不同时记录待迁移，下次启动由 MovePendingData 把数据移动过去
+func SetStorageMode(exeDir, currentDir string, portable bool) (string, error) {
+	marker := filepath.Join(exeDir, PortableMarkerFile)
+	var next string
+	if portable {
+		if err := checkDirWritable(exeDir); err != nil {
+			return "", fmt.Errorf("程序目录不可写（如安装在 Program Files 中），不能使用便携模式: %w", err)
+		}
+		if err := os.WriteFile(marker, nil, 0644); err != nil {
+			return "", fmt.Errorf("写入便携模式标记失败: %w", err)
+		}
+		next = exeDir
+	} else {
+		dir, err := UserDataDir()
+		if err != nil {
+			return "", fmt.Errorf("创建用户数据目录失败: %w", err)
+		}
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("删除便携模式标记失败: %w", err)
+		}
+		next = dir
+	}
+
+	if filepath.Clean(next) == filepath.Clean(currentDir) {
+		// 切换后又切换回来，取消未执行的迁移
+		os.Remove(filepath.Join(exeDir, storageMoveFile))
+		if dir, err := UserDataDir(); err == nil {
+			os.Remove(filepath.Join(dir, storageMoveFile))
+		}
+		return next, nil
+	}
+	if err := os.WriteFile(filepath.Join(next, storageMoveFile), []byte(currentDir), 0644); err != nil {
+		return "", fmt.Errorf("记录待迁移的数据失败: %w", err)
+	}
+	return next, nil
+}
+
+// MovePendingData 执行切换存储模式后记录的迁移：把文件或目录从旧目录移动到 dataDir，返回旧目录和已移动的文件
+// 旧目录是切换前正在使用的数据，同名文件以它为准：旧目录有配置文件时，先删除 dataDir 中残留的配置文件
+// （configFiles 作为一组替换），stateFiles 中的目录逐个文件合并。全部成功后删除迁移记录，
+// 失败时保留记录，下次启动继续迁移剩余的文件
+func MovePendingData(dataDir string, configFiles, stateFiles []string) (from string, moved []string, err error) {
+	pending := filepath.Join(dataDir, storageMoveFile)
+	data, err := os.ReadFile(pending)
+	if err != nil {
+		return "", nil, nil
+	}
+	from = filepath.Clean(string(data))
+	if from == "." || from == filepath.Clean(dataDir) || !dirExists(from) {
+		os.Remove(pending)
+		return "", nil, nil
+	}
+
+	for _, name := range configFiles {
+		if fileExists(filepath.Join(from, name)) {
+			for _, stale := range configFiles {
+				if err := os.Remove(filepath.Join(dataDir, stale)); err != nil && !os.IsNotExist(err) {
+					return from, nil, fmt.Errorf("删除 %s 失败: %w", stale, err)
+				}
+			}
+			break
+		}
+	}
+
+	for _, name := range append(append([]string(nil), configFiles...), stateFiles...) {
+		src := filepath.Join(from, name)
+		if !fileExists(src) {
+			continue
+		}
+		if err := movePath(src, filepath.Join(dataDir, name)); err != nil {
+			return from, moved, fmt.Errorf("迁移 %s 失败: %w", name, err)
+		}
+		moved = append(moved, name)
+	}
+	os.Remove(pending)
+	return from, moved, nil
+}
+
+// movePath 移动文件或目录（覆盖目标中的同名文件），跨磁盘时复制后删除原文件
+func movePath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := movePath(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+		return os.Remove(src)
+	}
+
+	if dirExists(dst) {
+		return fmt.Errorf("%s 已存在同名目录", dst)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// checkDirWritable 在目录中创建并删除临时文件，检查是否可写
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,98 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// =============================================================================
+// Windows 防火墙入站规则
+// =============================================================================
+
+// 首次运行时 Windows 会为核心弹出防火墙提示，用户拒绝后系统自动创建入站阻止规则，
+// 导致局域网共享和 UDP 转发失效。这里为程序目录下的核心显式创建入站允许规则。
+
+// firewallRulePrefix 本程序创建的规则名前缀
+const firewallRulePrefix = "Xlink - "
+
+// firewallPrograms 需要入站放行的核心程序
+var firewallPrograms = []string{
+	"xlink-cli-binary.exe",
+	"xray.exe",
+}
+
+// FirewallProgramStatus 单个核心的防火墙规则状态
+type FirewallProgramStatus struct {
+	Program  string `json:"program"`
+	Path     string `json:"path"`
+	Present  bool   `json:"present"`   // 程序文件存在
+	RuleName string `json:"rule_name"` // 本程序创建的允许规则名
+	Allowed  bool   `json:"allowed"`   // 存在已启用的入站允许规则
+	Blocked  bool   `json:"blocked"`   // 存在已启用的入站阻止规则（通常是拒绝了防火墙提示）
+}
+
+// FirewallStatus 防火墙规则诊断结果
+type FirewallStatus struct {
+	Supported bool                    `json:"supported"`
+	IsAdmin   bool                    `json:"is_admin"`
+	OK        bool                    `json:"ok"` // 所有已存在的核心均已放行且未被阻止
+	Programs  []FirewallProgramStatus `json:"programs"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// FirewallRuleName 核心对应的规则名
+func FirewallRuleName(program string) string {
+	return firewallRulePrefix + program
+}
+
+// GetFirewallStatus 查询程序目录下核心的入站规则状态
+func GetFirewallStatus(exeDir string) *FirewallStatus {
+	status := &FirewallStatus{Supported: firewallSupported, IsAdmin: IsAdmin(), OK: true}
+	if !firewallSupported {
+		return status
+	}
+
+	for _, p := range firewallProgramPaths(exeDir) {
+		ps := FirewallProgramStatus{
+			Program:  filepath.Base(p),
+			Path:     p,
+			RuleName: FirewallRuleName(filepath.Base(p)),
+		}
+		if _, err := os.Stat(p); err == nil {
+			ps.Present = true
+			allowed, blocked, err := queryFirewallRules(p)
+			if err != nil {
+				status.Error = err.Error()
+			}
+			ps.Allowed, ps.Blocked = allowed, blocked
+			if !allowed || blocked {
+				status.OK = false
+			}
+		}
+		status.Programs = append(status.Programs, ps)
+	}
+	if status.Error != "" {
+		status.OK = false
+	}
+	return status
+}
+
+// firewallProgramPaths 程序目录下核心的完整路径
+func firewallProgramPaths(exeDir string) []string {
+	paths := make([]string, 0, len(firewallPrograms))
+	for _, name := range firewallPrograms {
+		paths = append(paths, filepath.Join(exeDir, name))
+	}
+	return paths
+}
+
+// existingFirewallPrograms 程序目录下实际存在的核心
+func existingFirewallPrograms(exeDir string) []string {
+	var paths []string
+	for _, p := range firewallProgramPaths(exeDir) {
+		if _, err := os.Stat(p); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/syschange"
+)
+
+const firewallSupported = false
+
+// AddFirewallRules 非 Windows 平台不支持
+func AddFirewallRules(exeDir string) error {
+	return fmt.Errorf("仅 Windows 支持防火墙规则管理")
+}
+
+// RemoveFirewallRules 非 Windows 平台不支持
+func RemoveFirewallRules(exeDir string) error {
+	return fmt.Errorf("仅 Windows 支持防火墙规则管理")
+}
+
+// PlanFirewallRules 非 Windows 平台不支持
+func PlanFirewallRules(exeDir string) (*syschange.Plan, error) {
+	return nil, fmt.Errorf("仅 Windows 支持防火墙规则管理")
+}
+
+// PlanRemoveFirewallRules 非 Windows 平台不支持
+func PlanRemoveFirewallRules(exeDir string) (*syschange.Plan, error) {
+	return nil, fmt.Errorf("仅 Windows 支持防火墙规则管理")
+}
+
+// queryFirewallRules 非 Windows 平台不支持
+func queryFirewallRules(program string) (allowed, blocked bool, err error) {
+	return false, false, nil
+}
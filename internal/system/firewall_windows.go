@@ -0,0 +1,159 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"xlink-wails/internal/syschange"
+)
+
+const firewallSupported = true
+
+// errorCancelled 用户在 UAC 提示中取消 (ERROR_CANCELLED)
+const errorCancelled = 1223
+
+// AddFirewallRules 为程序目录下的核心创建入站允许规则（TCP/UDP，所有网络配置文件）
+// 会先删除这些程序已有的入站规则，以清除拒绝防火墙提示时生成的阻止规则。
+// 非管理员运行时会弹出 UAC 提示。
+func AddFirewallRules(exeDir string) error {
+	plan, err := PlanFirewallRules(exeDir)
+	if err != nil {
+		return err
+	}
+	return runFirewallScript(plan)
+}
+
+// PlanFirewallRules 生成创建入站允许规则的命令（不执行）
+func PlanFirewallRules(exeDir string) (*syschange.Plan, error) {
+	programs := existingFirewallPrograms(exeDir)
+	if len(programs) == 0 {
+		return nil, fmt.Errorf("程序目录中没有找到核心程序")
+	}
+
+	plan := syschange.NewPlan("添加核心程序的防火墙入站允许规则", true)
+	for _, p := range programs {
+		name := filepath.Base(p)
+		plan.AddOptional(fmt.Sprintf("删除 %s 已有的入站规则", name),
+			"netsh", "advfirewall", "firewall", "delete", "rule", "name=all", "dir=in", "program="+p)
+		plan.Add(fmt.Sprintf("允许 %s 入站连接", name),
+			"netsh", "advfirewall", "firewall", "add", "rule", "name="+FirewallRuleName(name),
+			"dir=in", "action=allow", "program="+p, "enable=yes", "profile=any")
+	}
+	return plan, nil
+}
+
+// RemoveFirewallRules 删除本程序创建的入站允许规则
+func RemoveFirewallRules(exeDir string) error {
+	plan, err := PlanRemoveFirewallRules(exeDir)
+	if err != nil {
+		return err
+	}
+	return runFirewallScript(plan)
+}
+
+// PlanRemoveFirewallRules 生成删除入站允许规则的命令（不执行）
+func PlanRemoveFirewallRules(exeDir string) (*syschange.Plan, error) {
+	plan := syschange.NewPlan("删除核心程序的防火墙入站规则", true)
+	for _, name := range firewallPrograms {
+		plan.AddOptional(fmt.Sprintf("删除规则 %s", FirewallRuleName(name)),
+			"netsh", "advfirewall", "firewall", "delete", "rule", "name="+FirewallRuleName(name), "dir=in")
+	}
+	return plan, nil
+}
+
+// queryFirewallRules 查询程序是否有已启用的入站允许/阻止规则
+func queryFirewallRules(program string) (allowed, blocked bool, err error) {
+	script := fmt.Sprintf(
+		`$a = @(Get-NetFirewallApplicationFilter -Program '%s' -ErrorAction SilentlyContinue | Get-NetFirewallRule | `+
+			`Where-Object { $_.Direction -eq 'Inbound' -and $_.Enabled -eq 'True' } | ForEach-Object { [string]$_.Action }); `+
+			`ConvertTo-Json -Compress -InputObject $a`,
+		psQuote(program))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.Output()
+	if err != nil {
+		return false, false, fmt.Errorf("查询防火墙规则失败: %w", err)
+	}
+
+	var actions []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &actions); err != nil {
+		return false, false, fmt.Errorf("解析防火墙规则失败: %w", err)
+	}
+	for _, action := range actions {
+		switch action {
+		case "Allow":
+			allowed = true
+		case "Block":
+			blocked = true
+		}
+	}
+	return allowed, blocked, nil
+}
+
+// runFirewallScript 以管理员权限执行 netsh 命令（合并为一个批处理，只弹一次 UAC）
+func runFirewallScript(plan *syschange.Plan) error {
+	lines := []string{"@echo off", "chcp 65001 >nul", "set FAIL=0"}
+	for _, c := range plan.Commands {
+		if c.IgnoreError {
+			lines = append(lines, c.Line+" >nul")
+		} else {
+			lines = append(lines, c.Line+" >nul || set FAIL=1")
+		}
+	}
+	lines = append(lines, "exit /b %FAIL%")
+	content := strings.Join(lines, "\r\n") + "\r\n"
+
+	f, err := os.CreateTemp("", "xlink-firewall-*.cmd")
+	if err != nil {
+		return fmt.Errorf("创建临时脚本失败: %w", err)
+	}
+	script := f.Name()
+	defer os.Remove(script)
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("写入临时脚本失败: %w", err)
+	}
+	f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if IsAdmin() {
+		cmd = exec.CommandContext(ctx, "cmd", "/c", script)
+	} else {
+		elevate := fmt.Sprintf(
+			`try { $p = Start-Process -FilePath '%s' -Verb RunAs -Wait -PassThru -WindowStyle Hidden -ErrorAction Stop; exit $p.ExitCode } catch { exit %d }`,
+			psQuote(script), errorCancelled)
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", elevate)
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == errorCancelled {
+			return fmt.Errorf("已取消管理员授权")
+		}
+		return fmt.Errorf("设置防火墙规则失败: %w", err)
+	}
+	return nil
+}
+
+// psQuote 转义 PowerShell 单引号字符串
+func psQuote(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"fmt"
+
+	"xlink-wails/internal/syschange"
+)
+
+// PlanKillSwitchEngage 非 Windows 平台不支持
+func PlanKillSwitchEngage(exeDir string) (*syschange.Plan, error) {
+	return nil, fmt.Errorf("仅 Windows 支持断线保护")
+}
+
+// PlanKillSwitchRelease 非 Windows 平台不支持
+func PlanKillSwitchRelease(exeDir string, previous map[string]string) *syschange.Plan {
+	return syschange.NewPlan("仅 Windows 支持断线保护", true)
+}
+
+// FirewallPolicies 非 Windows 平台不支持
+func FirewallPolicies() (map[string]string, error) {
+	return nil, fmt.Errorf("仅 Windows 支持断线保护")
+}
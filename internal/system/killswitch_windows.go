@@ -0,0 +1,117 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"xlink-wails/internal/syschange"
+)
+
+// =============================================================================
+// 断线保护（Kill Switch）
+// =============================================================================
+
+// 通过 Windows 防火墙实现：把默认出站策略改为阻止，只放行核心程序、本地子网和 DHCP。
+// 核心崩溃后系统中其他程序无法绕过代理直连，核心本身仍可重新连接服务器。
+// 解除时恢复启用前记录的各配置文件的默认策略（未记录时恢复 Windows 默认值），并删除本程序添加的放行规则。
+// 防火墙处于关闭状态时出站策略不生效。
+
+const killSwitchRulePrefix = "Xlink Kill Switch - "
+
+// PlanKillSwitchEngage 生成启用断线保护的命令
+func PlanKillSwitchEngage(exeDir string) (*syschange.Plan, error) {
+	programs := existingFirewallPrograms(exeDir)
+	if len(programs) == 0 {
+		return nil, fmt.Errorf("程序目录中没有找到核心程序")
+	}
+
+	plan := syschange.NewPlan("启用断线保护（阻止非代理出站流量）", true)
+	for _, p := range programs {
+		name := filepath.Base(p)
+		plan.Add(fmt.Sprintf("放行 %s 出站连接", name),
+			"netsh", "advfirewall", "firewall", "add", "rule", "name="+killSwitchRulePrefix+name,
+			"dir=out", "action=allow", "program="+p, "enable=yes", "profile=any")
+	}
+	plan.Add("放行本地子网",
+		"netsh", "advfirewall", "firewall", "add", "rule", "name="+killSwitchRulePrefix+"LAN",
+		"dir=out", "action=allow", "remoteip=LocalSubnet", "enable=yes", "profile=any")
+	plan.Add("放行 DHCP",
+		"netsh", "advfirewall", "firewall", "add", "rule", "name="+killSwitchRulePrefix+"DHCP",
+		"dir=out", "action=allow", "protocol=udp", "localport=68", "remoteport=67", "enable=yes", "profile=any")
+	plan.Add("默认阻止出站连接",
+		"netsh", "advfirewall", "set", "allprofiles", "firewallpolicy", "blockinbound,blockoutbound")
+	return plan, nil
+}
+
+// PlanKillSwitchRelease 生成解除断线保护的命令，previous 为启用前各配置文件的默认策略（见 FirewallPolicies）
+func PlanKillSwitchRelease(exeDir string, previous map[string]string) *syschange.Plan {
+	plan := syschange.NewPlan("解除断线保护", true)
+	if len(previous) == 0 {
+		plan.Add("恢复默认允许出站连接",
+			"netsh", "advfirewall", "set", "allprofiles", "firewallpolicy", "blockinbound,allowoutbound")
+	}
+	profiles := make([]string, 0, len(previous))
+	for profile := range previous {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	for _, profile := range profiles {
+		plan.Add(fmt.Sprintf("恢复 %s 的默认策略 %s", profile, previous[profile]),
+			"netsh", "advfirewall", "set", profile, "firewallpolicy", previous[profile])
+	}
+
+	names := []string{"LAN", "DHCP"}
+	names = append(names, firewallPrograms...)
+	for _, name := range names {
+		plan.AddOptional(fmt.Sprintf("删除规则 %s%s", killSwitchRulePrefix, name),
+			"netsh", "advfirewall", "firewall", "delete", "rule", "name="+killSwitchRulePrefix+name)
+	}
+	return plan
+}
+
+// FirewallPolicies 读取各配置文件当前的默认入站 / 出站策略（netsh 配置文件名 → firewallpolicy 参数）
+func FirewallPolicies() (map[string]string, error) {
+	const script = `Get-NetFirewallProfile | ForEach-Object { '{0} {1} {2}' -f $_.Name, $_.DefaultInboundAction, $_.DefaultOutboundAction }`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("查询防火墙策略失败: %w", err)
+	}
+
+	actions := map[string][2]string{
+		"Allow":         {"allowinbound", "allowoutbound"},
+		"Block":         {"blockinbound", "blockoutbound"},
+		"NotConfigured": {"notconfigured", "notconfigured"},
+	}
+	policies := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		in, okIn := actions[fields[1]]
+		out, okOut := actions[fields[2]]
+		if !okIn || !okOut {
+			return nil, fmt.Errorf("无法识别的防火墙策略: %s", strings.TrimSpace(line))
+		}
+		policies[strings.ToLower(fields[0])+"profile"] = in[0] + "," + out[1]
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("未读取到防火墙策略")
+	}
+	return policies, nil
+}
@@ -0,0 +1,170 @@
+package system
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"xlink-wails/internal/socks5"
+)
+
+// =============================================================================
+// 局域网设备（游戏机/电视盒子）配置助手
+// =============================================================================
+
+// LANDeviceGuide 单类设备的配置步骤
+type LANDeviceGuide struct {
+	Device string   `json:"device"` // "playstation", "switch", "android-tv"
+	Title  string   `json:"title"`
+	Steps  []string `json:"steps"`
+}
+
+// LANSetupGuide 局域网共享配置指引
+type LANSetupGuide struct {
+	LANIP          string           `json:"lan_ip"`
+	SocksPort      int              `json:"socks_port"`
+	HTTPPort       int              `json:"http_port,omitempty"`
+	Username       string           `json:"username,omitempty"`
+	Password       string           `json:"password,omitempty"`
+	DNSSuggestions []string         `json:"dns_suggestions"`
+	QRPayload      string           `json:"qr_payload"` // 二维码内容（socks5:// 链接），由前端渲染
+	Devices        []LANDeviceGuide `json:"devices"`
+	Warnings       []string         `json:"warnings,omitempty"`
+}
+
+// LANReachabilityResult 局域网可达性自检结果
+type LANReachabilityResult struct {
+	Address   string `json:"address"`
+	Reachable bool   `json:"reachable"` // TCP 是否可连接
+	SocksOK   bool   `json:"socks_ok"`  // SOCKS5 握手是否成功
+	LatencyMs int    `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// GetLANIP 获取默认出口网卡的局域网 IPv4 地址
+// 通过 UDP "连接" 公网地址让系统选择出口网卡，不会真正发送数据
+func GetLANIP() (string, error) {
+	conn, err := net.Dial("udp4", "223.5.5.5:53")
+	if err == nil {
+		defer conn.Close()
+		if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsLoopback() {
+			return addr.IP.String(), nil
+		}
+	}
+	return GetLocalIP()
+}
+
+// BuildLANSetupGuide 生成局域网设备配置指引，username 非空时代理需要认证
+func BuildLANSetupGuide(listenHost string, socksPort, httpPort int, username, password string, dnsSuggestions []string) (*LANSetupGuide, error) {
+	lanIP, err := GetLANIP()
+	if err != nil {
+		return nil, fmt.Errorf("获取局域网IP失败: %w", err)
+	}
+
+	guide := &LANSetupGuide{
+		LANIP:          lanIP,
+		SocksPort:      socksPort,
+		HTTPPort:       httpPort,
+		Username:       username,
+		Password:       password,
+		DNSSuggestions: dnsSuggestions,
+	}
+	qr := url.URL{Scheme: "socks5", Host: net.JoinHostPort(lanIP, fmt.Sprintf("%d", socksPort))}
+	auth := "认证: 不使用"
+	if username != "" {
+		qr.User = url.UserPassword(username, password)
+		auth = fmt.Sprintf("认证: 使用，用户名 %s，密码 %s", username, password)
+		guide.Warnings = append(guide.Warnings, "代理需要用户名和密码，系统代理不支持认证的设备需安装支持认证的代理应用")
+	}
+	guide.QRPayload = qr.String()
+
+	if isLoopbackHost(listenHost) {
+		guide.Warnings = append(guide.Warnings,
+			fmt.Sprintf("节点监听在 %s，局域网设备无法访问，请先开启局域网共享（监听 0.0.0.0）", listenHost))
+	}
+
+	// 游戏机和电视系统设置中只有 HTTP 代理选项
+	proxyPort := httpPort
+	if proxyPort <= 0 {
+		proxyPort = socksPort
+		guide.Warnings = append(guide.Warnings, "PlayStation/Switch/Android TV 系统代理仅支持 HTTP，请为节点开启 HTTP 入站")
+	}
+	dns1, dns2 := "", ""
+	if len(dnsSuggestions) > 0 {
+		dns1 = dnsSuggestions[0]
+	}
+	if len(dnsSuggestions) > 1 {
+		dns2 = dnsSuggestions[1]
+	}
+
+	guide.Devices = []LANDeviceGuide{
+		{
+			Device: "playstation",
+			Title:  "PlayStation 4 / 5",
+			Steps: []string{
+				"设置 → 网络 → 设置互联网连接，选择当前使用的网络并进入高级设置",
+				"IP 地址设置: 自动",
+				fmt.Sprintf("DNS 设置: 手动，主要 DNS %s，次要 DNS %s", dns1, dns2),
+				"MTU 设置: 自动",
+				fmt.Sprintf("代理服务器: 使用，地址 %s，端口 %d", lanIP, proxyPort),
+				"保存后执行「测试互联网连接」",
+			},
+		},
+		{
+			Device: "switch",
+			Title:  "Nintendo Switch",
+			Steps: []string{
+				"设置 → 互联网 → 互联网设置，选择当前网络 → 更改设置",
+				fmt.Sprintf("DNS 设置: 手动，首选 DNS %s，备用 DNS %s", dns1, dns2),
+				fmt.Sprintf("代理服务器设置: 启用，服务器 %s，端口 %d，%s", lanIP, proxyPort, auth),
+				"保存后执行「连接到此网络」测试",
+			},
+		},
+		{
+			Device: "android-tv",
+			Title:  "Android TV / 电视盒子",
+			Steps: []string{
+				"设置 → 网络和互联网 → 当前 Wi-Fi → 代理设置 → 手动",
+				fmt.Sprintf("代理主机名: %s，代理端口: %d", lanIP, proxyPort),
+				"绕过代理: localhost,127.0.0.1",
+				fmt.Sprintf("如需修改 DNS: IP 设置改为静态，DNS1 %s，DNS2 %s", dns1, dns2),
+				"也可安装支持 SOCKS5 的代理应用，扫描二维码导入",
+			},
+		},
+	}
+
+	return guide, nil
+}
+
+// TestLANReachability 从局域网网卡地址连接代理端口并完成 SOCKS5 握手，username 非空时进行用户名密码认证
+// 源地址绑定到局域网 IP，模拟局域网设备的访问路径（不走回环）
+func TestLANReachability(lanIP string, port int, username, password string) *LANReachabilityResult {
+	addr := net.JoinHostPort(lanIP, fmt.Sprintf("%d", port))
+	result := &LANReachabilityResult{Address: addr}
+
+	dialer := &net.Dialer{
+		Timeout:   3 * time.Second,
+		LocalAddr: &net.TCPAddr{IP: net.ParseIP(lanIP)},
+	}
+
+	start := time.Now()
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+
+	result.Reachable = true
+	result.LatencyMs = int(time.Since(start).Milliseconds())
+
+	conn.SetDeadline(time.Now().Add(3 * time.Second))
+	if err := socks5.Handshake(conn, username, password); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.SocksOK = true
+	return result
+}
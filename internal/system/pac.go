@@ -0,0 +1,324 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xlink-wails/internal/models"
+)
+
+// =============================================================================
+// PAC 自动代理配置
+// =============================================================================
+
+const (
+	// PACPath PAC 文件的访问路径
+	PACPath = "/proxy.pac"
+	// pacBlackhole 拦截规则使用的不可用代理地址
+	pacBlackhole = "PROXY 127.0.0.1:9"
+)
+
+// BuildPAC 根据节点分流规则生成 PAC 脚本
+// 浏览器无法识别 geosite/geoip，这类规则交给代理端按内核规则分流；
+// 不代理列表中的地址始终直连（未设置时为默认列表：私有网段和无点主机名）
+func BuildPAC(rules []models.RoutingRule, opts ProxySettings) string {
+	bypass := opts.BypassList
+	if len(bypass) == 0 {
+		bypass = models.DefaultProxyBypass
+	}
+
+	server := opts.Server
+	if server == "" || server == "0.0.0.0" || server == "::" {
+		server = "127.0.0.1"
+	}
+	if strings.Contains(server, ":") {
+		server = "[" + strings.Trim(server, "[]") + "]"
+	}
+
+	proxies := []string{
+		fmt.Sprintf("SOCKS5 %s:%d", server, opts.Port),
+		fmt.Sprintf("SOCKS %s:%d", server, opts.Port),
+	}
+	if opts.HTTPPort > 0 {
+		proxies = append(proxies, fmt.Sprintf("PROXY %s:%d", server, opts.HTTPPort))
+	}
+	proxy := strings.Join(proxies, "; ")
+
+	var b strings.Builder
+	b.WriteString("// Generated by " + models.AppTitle + "\n")
+	fmt.Fprintf(&b, "var PROXY = %q;\n", proxy)
+	fmt.Fprintf(&b, "var DIRECT = %q;\n", "DIRECT")
+	fmt.Fprintf(&b, "var BLOCK = %q;\n\n", pacBlackhole)
+
+	b.WriteString("function isIPv4(host) {\n")
+	b.WriteString("  return /^\\d{1,3}(\\.\\d{1,3}){3}$/.test(host);\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString("function FindProxyForURL(url, host) {\n")
+	b.WriteString("  host = host.toLowerCase();\n")
+	for _, entry := range bypass {
+		if cond := pacBypassCondition(entry); cond != "" {
+			fmt.Fprintf(&b, "  if (%s) return DIRECT; // %s\n", cond, entry)
+		}
+	}
+
+	for _, r := range rules {
+		cond := pacCondition(r)
+		if cond == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  if (%s) return %s; // %s%s\n", cond, pacAction(r.Target), r.Type, r.Match)
+	}
+
+	b.WriteString("  return PROXY;\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// pacAction 规则目标对应的 PAC 返回值（与内核规则的出站判断一致）
+func pacAction(target string) string {
+	target = strings.ToLower(target)
+	switch {
+	case strings.Contains(target, "direct"):
+		return "DIRECT"
+	case strings.Contains(target, "block"):
+		return "BLOCK"
+	default:
+		return "PROXY"
+	}
+}
+
+// pacBypassCondition 将不代理列表的条目转换为 JS 条件（IPv6 网段无法用 isInNet 判断，跳过）
+func pacBypassCondition(entry string) string {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	switch {
+	case entry == "":
+		return ""
+	case entry == models.ProxyBypassLocal:
+		return "isPlainHostName(host)"
+	case strings.Contains(entry, "/"):
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil || ipNet.IP.To4() == nil {
+			return ""
+		}
+		return fmt.Sprintf("isIPv4(host) && isInNet(host, %q, %q)", ipNet.IP.String(), net.IP(ipNet.Mask).String())
+	case strings.ContainsAny(entry, "*?"):
+		return fmt.Sprintf("shExpMatch(host, %s)", strconv.Quote(entry))
+	default:
+		return "host === " + strconv.Quote(strings.Trim(entry, "[]"))
+	}
+}
+
+// pacCondition 将单条规则转换为 JS 条件，无法在浏览器端判断的规则返回空
+func pacCondition(r models.RoutingRule) string {
+	match := strings.ToLower(strings.TrimSpace(r.Match))
+	if match == "" {
+		return ""
+	}
+	quoted := strconv.Quote(match)
+
+	switch strings.ToLower(r.Type) {
+	case "domain:", "domain":
+		return fmt.Sprintf("host === %s || dnsDomainIs(host, %s)", quoted, strconv.Quote("."+match))
+	case "regexp:", "regexp":
+		return fmt.Sprintf("new RegExp(%s).test(host)", strconv.Quote(strings.TrimSpace(r.Match)))
+	case "geosite:", "geosite", "geoip:", "geoip":
+		return ""
+	case "ip:", "ip":
+		if net.ParseIP(match) == nil {
+			return ""
+		}
+		return "host === " + quoted
+	case "ip-cidr:", "ip-cidr", "cidr":
+		_, ipNet, err := net.ParseCIDR(match)
+		if err != nil || ipNet.IP.To4() == nil {
+			return ""
+		}
+		// 只对 IP 字面量判断，避免 isInNet 触发 DNS 查询
+		return fmt.Sprintf("isIPv4(host) && isInNet(host, %q, %q)", ipNet.IP.String(), net.IP(ipNet.Mask).String())
+	default:
+		return fmt.Sprintf("host.indexOf(%s) >= 0", quoted)
+	}
+}
+
+// =============================================================================
+// PAC 服务
+// =============================================================================
+
+// PACServer 在本地端口提供 PAC 文件，每次请求实时生成（规则修改后无需重启）
+type PACServer struct {
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	build    func() (string, error)
+}
+
+// NewPACServer 创建 PAC 服务
+func NewPACServer() *PACServer {
+	return &PACServer{}
+}
+
+// Start 在 listen 上启动服务（已运行时先停止），build 用于生成 PAC 内容
+func (s *PACServer) Start(listen string, build func() (string, error)) error {
+	s.Stop()
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("PAC 服务监听失败: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PACPath, s.handle)
+
+	s.mu.Lock()
+	s.build = build
+	s.listener = ln
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv := s.server
+	s.mu.Unlock()
+
+	go srv.Serve(ln)
+	return nil
+}
+
+// Stop 停止服务
+func (s *PACServer) Stop() {
+	s.mu.Lock()
+	srv := s.server
+	s.server = nil
+	s.listener = nil
+	s.mu.Unlock()
+
+	if srv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}
+}
+
+// IsRunning 是否正在运行
+func (s *PACServer) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server != nil
+}
+
+// URL PAC 访问地址（带时间戳，避免系统缓存旧文件）
+func (s *PACServer) URL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return fmt.Sprintf("http://%s%s?t=%d", s.listener.Addr().String(), PACPath, time.Now().Unix())
+}
+
+func (s *PACServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	build := s.build
+	s.mu.Unlock()
+
+	script, err := build()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write([]byte(script))
+}
+
+// =============================================================================
+// 系统 AutoConfigURL
+// =============================================================================
+
+// SetAutoConfigURL 将系统代理切换为 PAC 模式（同时关闭手动代理，避免两者同时生效）
+func (p *ProxyManager) SetAutoConfigURL(url string) error {
+	if p.originalSettings == nil {
+		settings, _ := p.GetSystemProxy()
+		p.originalSettings = settings
+	}
+
+	var err error
+	switch runtime.GOOS {
+	case "windows":
+		if err = p.clearWindowsProxy(); err == nil {
+			err = setWindowsAutoConfigURL(url)
+		}
+	case "darwin":
+		p.clearMacOSProxy()
+		err = p.setMacOSAutoConfigURL(url)
+	case "linux":
+		exec.Command("gsettings", "set", "org.gnome.system.proxy", "autoconfig-url", url).Run()
+		err = exec.Command("gsettings", "set", "org.gnome.system.proxy", "mode", "auto").Run()
+	default:
+		err = fmt.Errorf("不支持的操作系统")
+	}
+	if err == nil {
+		p.pacURL = url
+	}
+	return err
+}
+
+// clearAutoConfigURL 移除本程序设置的 PAC 地址
+func (p *ProxyManager) clearAutoConfigURL() {
+	if p.pacURL == "" {
+		return
+	}
+	p.pacURL = ""
+
+	switch runtime.GOOS {
+	case "windows":
+		exec.Command("reg", "delete",
+			`HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`,
+			"/v", "AutoConfigURL", "/f").Run()
+		refreshSystemProxy()
+	case "darwin":
+		if services, err := p.getMacOSNetworkServices(); err == nil {
+			for _, service := range services {
+				exec.Command("networksetup", "-setautoproxystate", service, "off").Run()
+			}
+		}
+	case "linux":
+		exec.Command("gsettings", "reset", "org.gnome.system.proxy", "autoconfig-url").Run()
+	}
+}
+
+// IsPACActive 当前是否由本程序设置了 PAC
+func (p *ProxyManager) IsPACActive() bool {
+	return p.pacURL != ""
+}
+
+func setWindowsAutoConfigURL(url string) error {
+	cmd := exec.Command("reg", "add",
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`,
+		"/v", "AutoConfigURL", "/t", "REG_SZ", "/d", url, "/f")
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	refreshSystemProxy()
+	return nil
+}
+
+func (p *ProxyManager) setMacOSAutoConfigURL(url string) error {
+	services, err := p.getMacOSNetworkServices()
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		exec.Command("networksetup", "-setautoproxyurl", service, url).Run()
+		exec.Command("networksetup", "-setautoproxystate", service, "on").Run()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package system
+
+import (
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// 运行中的程序（供按应用分流选择程序）
+// =============================================================================
+
+// RunningApp 运行中的程序
+type RunningApp struct {
+	Name string `json:"name"`           // 程序名，如 chrome.exe
+	Path string `json:"path,omitempty"` // 完整路径（无权限读取时为空）
+}
+
+// ListRunningApps 列出运行中的程序（同名程序只保留一个），按名称排序
+func ListRunningApps() ([]RunningApp, error) {
+	apps, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(apps))
+	out := make([]RunningApp, 0, len(apps))
+	for _, app := range apps {
+		key := strings.ToLower(app.Name)
+		if app.Name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, app)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name) })
+	return out, nil
+}
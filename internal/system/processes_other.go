@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listProcesses 读取 /proc 枚举进程（没有 /proc 的平台返回空列表）
+func listProcesses() ([]RunningApp, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, nil
+	}
+	var apps []RunningApp
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		dir := filepath.Join("/proc", e.Name())
+		path, _ := os.Readlink(filepath.Join(dir, "exe"))
+		name := filepath.Base(path)
+		if path == "" {
+			comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+			if err != nil {
+				continue
+			}
+			name = strings.TrimSpace(string(comm))
+		}
+		apps = append(apps, RunningApp{Name: name, Path: path})
+	}
+	return apps, nil
+}
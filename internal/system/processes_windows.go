@@ -0,0 +1,49 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// listProcesses 通过进程快照枚举进程
+func listProcesses() ([]RunningApp, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var apps []RunningApp
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if entry.ProcessID == 0 {
+			continue
+		}
+		apps = append(apps, RunningApp{
+			Name: windows.UTF16ToString(entry.ExeFile[:]),
+			Path: processPath(entry.ProcessID),
+		})
+	}
+	return apps, nil
+}
+
+// processPath 读取进程的完整路径，系统进程等无权限访问时返回空
+func processPath(pid uint32) string {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(h)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buf[:size])
+}
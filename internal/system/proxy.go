@@ -1,11 +1,15 @@
 package system
 
 import (
+	"encoding/binary"
 	"fmt"
+	"net/netip"
 	"os/exec"
 	"runtime"
 	"strings"
 	"syscall"
+
+	"xlink-wails/internal/models"
 )
 
 // =============================================================================
@@ -15,14 +19,20 @@ import (
 // ProxyManager 系统代理管理器
 type ProxyManager struct {
 	originalSettings *ProxySettings
+	pacURL           string // 本程序设置的 PAC 地址（为空表示未使用 PAC）
 }
 
 // ProxySettings 代理设置
 type ProxySettings struct {
 	Enabled    bool
 	Server     string
-	Port       int
-	BypassList []string
+	Port       int      // SOCKS 端口
+	HTTPPort   int      // HTTP 端口（0 表示没有 HTTP 入站）
+	Mode       int      // 写入模式 (models.SystemProxyMode*)
+	Raw        string   // 原始 ProxyServer 值（用于精确恢复）
+	BypassList []string // 不代理的地址（models.ValidateProxyBypass 的写法），为空时使用默认列表
+
+	AutoConfigURL string // 原始 PAC 地址（Windows，用于恢复）
 }
 
 // NewProxyManager 创建代理管理器
@@ -30,28 +40,137 @@ func NewProxyManager() *ProxyManager {
 	return &ProxyManager{}
 }
 
-// SetSystemProxy 设置系统代理
+// SetSystemProxy 设置系统代理（仅 SOCKS）
 func (p *ProxyManager) SetSystemProxy(server string, port int) error {
+	return p.SetSystemProxyWithOptions(ProxySettings{
+		Server: server,
+		Port:   port,
+		Mode:   models.SystemProxyModeSocks,
+	})
+}
+
+// SetSystemProxyWithOptions 按指定模式设置系统代理
+func (p *ProxyManager) SetSystemProxyWithOptions(opts ProxySettings) error {
 	// 保存原始设置 (仅第一次)
 	if p.originalSettings == nil {
 		settings, _ := p.GetSystemProxy()
 		p.originalSettings = settings
 	}
 
+	// 手动代理与 PAC 互斥
+	p.clearAutoConfigURL()
+
+	if len(opts.BypassList) == 0 {
+		opts.BypassList = models.DefaultProxyBypass
+	}
 	switch runtime.GOOS {
 	case "windows":
-		return p.setWindowsProxy(server, port)
+		return p.setWindowsProxy(BuildProxyServerString(opts), WindowsProxyBypass(opts.BypassList))
 	case "darwin":
-		return p.setMacOSProxy(server, port)
+		return p.setMacOSProxy(opts)
 	case "linux":
-		return p.setLinuxProxy(server, port)
+		return p.setLinuxProxy(opts)
 	default:
 		return fmt.Errorf("不支持的操作系统")
 	}
 }
 
+// BuildProxyServerString 构建 Windows ProxyServer 注册表值
+// 分协议模式下如果没有 HTTP 入站，退回仅 SOCKS
+func BuildProxyServerString(opts ProxySettings) string {
+	socks := fmt.Sprintf("socks=%s:%d", opts.Server, opts.Port)
+	if opts.Mode != models.SystemProxyModePerProtocol || opts.HTTPPort <= 0 {
+		return socks
+	}
+
+	http := fmt.Sprintf("%s:%d", opts.Server, opts.HTTPPort)
+	return strings.Join([]string{
+		"http=" + http,
+		"https=" + http,
+		"ftp=" + http,
+		socks,
+	}, ";")
+}
+
+// =============================================================================
+// 不代理列表
+// =============================================================================
+
+// WindowsProxyBypass 转换为 Windows ProxyOverride 值：WinINet 只支持通配符，
+// IPv4 网段按字节边界展开为通配写法（172.16.0.0/12 → 172.16.* … 172.31.*），IPv6 网段不支持，跳过
+func WindowsProxyBypass(list []string) string {
+	var entries []string
+	for _, e := range list {
+		if !strings.Contains(e, "/") {
+			entries = append(entries, e)
+			continue
+		}
+		prefix, err := netip.ParsePrefix(e)
+		if err != nil || !prefix.Addr().Is4() {
+			continue
+		}
+		entries = append(entries, ipv4Wildcards(prefix.Masked())...)
+	}
+	return strings.Join(entries, ";")
+}
+
+// ipv4Wildcards 将 IPv4 网段展开为按字节的通配写法（最多 128 条）
+func ipv4Wildcards(prefix netip.Prefix) []string {
+	if prefix.Bits() == 0 {
+		return []string{"*"}
+	}
+	octets := (prefix.Bits() + 7) / 8
+	shift := uint(32 - octets*8)
+	a4 := prefix.Addr().As4()
+	base := binary.BigEndian.Uint32(a4[:])
+	count := uint32(1) << uint(octets*8-prefix.Bits())
+
+	result := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], base+i<<shift)
+		parts := make([]string, 0, 4)
+		for j := 0; j < octets; j++ {
+			parts = append(parts, fmt.Sprint(b[j]))
+		}
+		if octets < 4 {
+			parts = append(parts, "*")
+		}
+		result = append(result, strings.Join(parts, "."))
+	}
+	return result
+}
+
+// macOSProxyBypass 转换为 networksetup -setproxybypassdomains 的参数（支持通配符和网段）
+func macOSProxyBypass(list []string) []string {
+	var entries []string
+	for _, e := range list {
+		if e == models.ProxyBypassLocal {
+			e = "*.local"
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return []string{"Empty"}
+	}
+	return entries
+}
+
+// gnomeIgnoreHosts 转换为 org.gnome.system.proxy ignore-hosts 的值（支持通配符和网段，没有 <local> 的写法）
+func gnomeIgnoreHosts(list []string) string {
+	quoted := make([]string, 0, len(list))
+	for _, e := range list {
+		if e != models.ProxyBypassLocal {
+			quoted = append(quoted, "'"+e+"'")
+		}
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 // ClearSystemProxy 清除系统代理
 func (p *ProxyManager) ClearSystemProxy() error {
+	p.clearAutoConfigURL()
+
 	switch runtime.GOOS {
 	case "windows":
 		return p.clearWindowsProxy()
@@ -70,12 +189,40 @@ func (p *ProxyManager) RestoreSystemProxy() error {
 		return p.ClearSystemProxy()
 	}
 
+	// 恢复用户原有的 PAC 地址
+	if runtime.GOOS == "windows" && p.originalSettings.AutoConfigURL != "" {
+		p.clearAutoConfigURL()
+		setWindowsAutoConfigURL(p.originalSettings.AutoConfigURL)
+	}
+
 	if p.originalSettings.Enabled {
-		return p.SetSystemProxy(p.originalSettings.Server, p.originalSettings.Port)
+		if runtime.GOOS == "windows" && p.originalSettings.Raw != "" {
+			return p.setWindowsProxy(p.originalSettings.Raw, strings.Join(p.originalSettings.BypassList, ";"))
+		}
+		return p.SetSystemProxyWithOptions(*p.originalSettings)
 	}
 	return p.ClearSystemProxy()
 }
 
+// OriginalSettings 返回修改前的系统代理设置（尚未保存时先读取当前设置）
+// 在修改系统代理前调用，用于记录撤销信息
+func (p *ProxyManager) OriginalSettings() *ProxySettings {
+	if p.originalSettings == nil {
+		settings, _ := p.GetSystemProxy()
+		p.originalSettings = settings
+	}
+	return p.originalSettings
+}
+
+// RecoverSystemProxy 按上次记录的原始设置恢复系统代理（程序异常退出后启动时调用）
+// pacURL 为上次设置的 PAC 地址，用于清除残留的 PAC
+func (p *ProxyManager) RecoverSystemProxy(original *ProxySettings, pacURL string) error {
+	p.originalSettings, p.pacURL = original, pacURL
+	err := p.RestoreSystemProxy()
+	p.originalSettings = nil
+	return err
+}
+
 // GetSystemProxy 获取当前系统代理设置
 func (p *ProxyManager) GetSystemProxy() (*ProxySettings, error) {
 	switch runtime.GOOS {
@@ -108,10 +255,9 @@ func refreshSystemProxy() {
 	procInternetSetOption.Call(0, 37, 0, 0)
 }
 
-func (p *ProxyManager) setWindowsProxy(server string, port int) error {
-	// ⚠️【核心逻辑】添加 socks= 前缀
+func (p *ProxyManager) setWindowsProxy(proxyServer, bypass string) error {
+	// ⚠️【核心逻辑】proxyServer 至少包含 socks= 条目
 	// 强制 Windows 使用 SOCKS 协议连接本地端口
-	proxyServer := fmt.Sprintf("socks=%s:%d", server, port)
 
 	// 1. 设置代理服务器地址
 	cmd := exec.Command("reg", "add",
@@ -130,10 +276,9 @@ func (p *ProxyManager) setWindowsProxy(server string, port int) error {
 	}
 
 	// 3. 设置绕过列表 (本地回环不走代理)
-	bypassList := "localhost;127.*;10.*;172.16.*;172.17.*;172.18.*;172.19.*;172.20.*;172.21.*;172.22.*;172.23.*;172.24.*;172.25.*;172.26.*;172.27.*;172.28.*;172.29.*;172.30.*;172.31.*;192.168.*;<local>"
 	cmd = exec.Command("reg", "add",
 		`HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`,
-		"/v", "ProxyOverride", "/t", "REG_SZ", "/d", bypassList, "/f")
+		"/v", "ProxyOverride", "/t", "REG_SZ", "/d", bypass, "/f")
 	if err := cmd.Run(); err != nil {
 		return err
 	}
@@ -181,13 +326,39 @@ func (p *ProxyManager) getWindowsProxy() (*ProxySettings, error) {
 		for _, line := range lines {
 			if strings.Contains(line, "ProxyServer") {
 				// 输出格式通常为: ProxyServer    REG_SZ    socks=127.0.0.1:10808
+				// 分协议时为: http=127.0.0.1:10809;https=...;socks=127.0.0.1:10808
 				parts := strings.Fields(line)
 				if len(parts) >= 3 {
-					raw := parts[len(parts)-1]
-					// 移除可能存在的协议前缀，只保留 ip:port
-					raw = strings.TrimPrefix(raw, "socks=")
-					raw = strings.TrimPrefix(raw, "http://")
-					settings.Server = raw
+					settings.Raw = parts[len(parts)-1]
+					parseWindowsProxyServer(settings.Raw, settings)
+				}
+			}
+		}
+	}
+
+	// 获取绕过列表（恢复时写回）
+	cmd = exec.Command("reg", "query",
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`,
+		"/v", "ProxyOverride")
+	if output, err = cmd.Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.Contains(line, "ProxyOverride") {
+				if parts := strings.Fields(line); len(parts) >= 3 {
+					settings.BypassList = strings.Split(parts[len(parts)-1], ";")
+				}
+			}
+		}
+	}
+
+	// 获取 PAC 地址
+	cmd = exec.Command("reg", "query",
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`,
+		"/v", "AutoConfigURL")
+	if output, err = cmd.Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.Contains(line, "AutoConfigURL") {
+				if parts := strings.Fields(line); len(parts) >= 3 {
+					settings.AutoConfigURL = parts[len(parts)-1]
 				}
 			}
 		}
@@ -196,21 +367,69 @@ func (p *ProxyManager) getWindowsProxy() (*ProxySettings, error) {
 	return settings, nil
 }
 
+// parseWindowsProxyServer 解析 ProxyServer 值到 settings
+func parseWindowsProxyServer(raw string, settings *ProxySettings) {
+	splitHostPort := func(addr string) (string, int) {
+		addr = strings.TrimPrefix(addr, "http://")
+		idx := strings.LastIndex(addr, ":")
+		if idx == -1 {
+			return addr, 0
+		}
+		var port int
+		fmt.Sscanf(addr[idx+1:], "%d", &port)
+		return addr[:idx], port
+	}
+
+	if !strings.Contains(raw, "=") {
+		// 所有协议共用同一代理
+		settings.Server, settings.HTTPPort = splitHostPort(raw)
+		return
+	}
+
+	for _, entry := range strings.Split(raw, ";") {
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		host, port := splitHostPort(kv[1])
+		switch strings.ToLower(kv[0]) {
+		case "socks":
+			settings.Server, settings.Port = host, port
+		case "http":
+			settings.HTTPPort = port
+			if settings.Server == "" {
+				settings.Server = host
+			}
+		}
+	}
+	if settings.HTTPPort > 0 && settings.Port > 0 {
+		settings.Mode = models.SystemProxyModePerProtocol
+	}
+}
+
 // =============================================================================
-// macOS 实现 (保持不变)
+// macOS 实现
 // =============================================================================
 
-func (p *ProxyManager) setMacOSProxy(server string, port int) error {
+func (p *ProxyManager) setMacOSProxy(opts ProxySettings) error {
 	services, err := p.getMacOSNetworkServices()
 	if err != nil {
 		return err
 	}
 
+	perProtocol := opts.Mode == models.SystemProxyModePerProtocol && opts.HTTPPort > 0
 	for _, service := range services {
-		cmd := exec.Command("networksetup", "-setsocksfirewallproxy", service, server, fmt.Sprintf("%d", port))
+		cmd := exec.Command("networksetup", "-setsocksfirewallproxy", service, opts.Server, fmt.Sprintf("%d", opts.Port))
 		cmd.Run()
 		cmd = exec.Command("networksetup", "-setsocksfirewallproxystate", service, "on")
 		cmd.Run()
+
+		if perProtocol {
+			httpPort := fmt.Sprintf("%d", opts.HTTPPort)
+			exec.Command("networksetup", "-setwebproxy", service, opts.Server, httpPort).Run()
+			exec.Command("networksetup", "-setsecurewebproxy", service, opts.Server, httpPort).Run()
+		}
+		exec.Command("networksetup", append([]string{"-setproxybypassdomains", service}, macOSProxyBypass(opts.BypassList)...)...).Run()
 	}
 	return nil
 }
@@ -224,6 +443,8 @@ func (p *ProxyManager) clearMacOSProxy() error {
 	for _, service := range services {
 		cmd := exec.Command("networksetup", "-setsocksfirewallproxystate", service, "off")
 		cmd.Run()
+		exec.Command("networksetup", "-setwebproxystate", service, "off").Run()
+		exec.Command("networksetup", "-setsecurewebproxystate", service, "off").Run()
 	}
 	return nil
 }
@@ -250,13 +471,21 @@ func (p *ProxyManager) getMacOSProxy() (*ProxySettings, error) {
 }
 
 // =============================================================================
-// Linux 实现 (保持不变)
+// Linux 实现
 // =============================================================================
 
-func (p *ProxyManager) setLinuxProxy(server string, port int) error {
+func (p *ProxyManager) setLinuxProxy(opts ProxySettings) error {
 	exec.Command("gsettings", "set", "org.gnome.system.proxy", "mode", "manual").Run()
-	exec.Command("gsettings", "set", "org.gnome.system.proxy.socks", "host", server).Run()
-	exec.Command("gsettings", "set", "org.gnome.system.proxy.socks", "port", fmt.Sprintf("%d", port)).Run()
+	exec.Command("gsettings", "set", "org.gnome.system.proxy", "ignore-hosts", gnomeIgnoreHosts(opts.BypassList)).Run()
+	exec.Command("gsettings", "set", "org.gnome.system.proxy.socks", "host", opts.Server).Run()
+	exec.Command("gsettings", "set", "org.gnome.system.proxy.socks", "port", fmt.Sprintf("%d", opts.Port)).Run()
+
+	if opts.Mode == models.SystemProxyModePerProtocol && opts.HTTPPort > 0 {
+		for _, schema := range []string{"org.gnome.system.proxy.http", "org.gnome.system.proxy.https"} {
+			exec.Command("gsettings", "set", schema, "host", opts.Server).Run()
+			exec.Command("gsettings", "set", schema, "port", fmt.Sprintf("%d", opts.HTTPPort)).Run()
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"context"
+	"time"
+)
+
+// =============================================================================
+// 睡眠唤醒检测
+// =============================================================================
+
+// 睡眠期间进程被挂起，单调时钟（Windows 的 QueryUnbiasedInterruptTime、Linux 的 CLOCK_MONOTONIC）
+// 停止计时而墙上时钟照常走动。每次轮询比较两者的增量，差值超过阈值即认为系统刚从睡眠/休眠中唤醒。
+// 进程繁忙导致轮询延迟时两者同步增长，不会误判。
+
+const (
+	// resumePollInterval 时钟轮询间隔
+	resumePollInterval = 10 * time.Second
+	// ResumeMinSleep 墙上时钟比单调时钟多走超过此时长时视为发生过睡眠
+	ResumeMinSleep = 30 * time.Second
+)
+
+// ResumeWatcher 检测系统从睡眠/休眠中唤醒
+type ResumeWatcher struct {
+	onResume func(slept time.Duration)
+}
+
+// NewResumeWatcher 创建唤醒检测器，onResume 在检测到唤醒时调用，slept 为估算的睡眠时长
+func NewResumeWatcher(onResume func(slept time.Duration)) *ResumeWatcher {
+	return &ResumeWatcher{onResume: onResume}
+}
+
+// Run 运行检测循环，直到 ctx 取消
+func (w *ResumeWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(resumePollInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		// Round(0) 去掉单调时钟读数，Sub 改为按墙上时钟计算
+		slept := now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+		last = now
+		if slept >= ResumeMinSleep && w.onResume != nil {
+			w.onResume(slept)
+		}
+	}
+}
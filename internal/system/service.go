@@ -0,0 +1,42 @@
+package system
+
+// =============================================================================
+// 后台服务
+// =============================================================================
+
+// 以 Windows 服务运行时，节点在用户注销或尚未登录桌面时继续运行。
+// 服务进程没有界面，由本地控制接口接受界面进程转发的启动 / 停止命令。
+
+const (
+	// ServiceName 服务名
+	ServiceName = "XlinkClientService"
+	// ServiceArg 服务管理器启动程序时的参数
+	ServiceArg = "--service"
+	// ServiceDataDirArg 服务使用的数据目录（服务以 LocalSystem 运行，不能使用安装用户的 %APPDATA%）
+	ServiceDataDirArg = "--data-dir"
+
+	serviceDisplayName = "Xlink Client Service"
+	serviceDescription = "在后台运行 Xlink 代理节点，用户未登录时保持连接"
+)
+
+// 服务运行状态
+const (
+	ServiceStateNotInstalled = "not_installed"
+	ServiceStateStopped      = "stopped"
+	ServiceStateStarting     = "starting"
+	ServiceStateStopping     = "stopping"
+	ServiceStateRunning      = "running"
+	ServiceStatePaused       = "paused"
+)
+
+// ServiceStatus 后台服务状态
+type ServiceStatus struct {
+	Supported bool   `json:"supported"` // 当前平台支持后台服务
+	Installed bool   `json:"installed"`
+	State     string `json:"state"`
+}
+
+// Running 服务是否正在运行
+func (s ServiceStatus) Running() bool {
+	return s.State == ServiceStateRunning
+}
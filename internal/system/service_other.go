@@ -0,0 +1,50 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// InstallService 非 Windows 平台不支持
+func InstallService(exePath, dataDir string) error {
+	return fmt.Errorf("仅 Windows 支持后台服务")
+}
+
+// UninstallService 非 Windows 平台不支持
+func UninstallService() error {
+	return fmt.Errorf("仅 Windows 支持后台服务")
+}
+
+// StartService 非 Windows 平台不支持
+func StartService() error {
+	return fmt.Errorf("仅 Windows 支持后台服务")
+}
+
+// StopService 非 Windows 平台不支持
+func StopService() error {
+	return fmt.Errorf("仅 Windows 支持后台服务")
+}
+
+// QueryService 非 Windows 平台不支持
+func QueryService() ServiceStatus {
+	return ServiceStatus{State: ServiceStateNotInstalled}
+}
+
+// IsWindowsService 非 Windows 平台始终为 false
+func IsWindowsService() bool {
+	return false
+}
+
+// ReportServiceError 非 Windows 平台没有事件日志，写入标准错误
+func ReportServiceError(msg string) {
+	log.Println(msg)
+}
+
+// RunService 非 Windows 平台不支持
+func RunService(run func(ctx context.Context)) error {
+	return fmt.Errorf("仅 Windows 支持后台服务")
+}
@@ -0,0 +1,273 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceStopTimeout 等待服务停止的最长时间（服务停止时需要关闭所有节点）
+const serviceStopTimeout = 30 * time.Second
+
+// serviceEventID 服务写入 Windows 事件日志使用的事件 ID
+const serviceEventID = 1
+
+// InstallService 安装为自动启动的服务，服务异常退出后 5 秒重启（需要管理员权限）
+func InstallService(exePath, dataDir string) error {
+	m, err := connectServiceManager()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(ServiceName); err == nil {
+		s.Close()
+		return fmt.Errorf("服务已安装")
+	}
+
+	s, err := m.CreateService(ServiceName, exePath, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, ServiceArg, ServiceDataDirArg, dataDir)
+	if err != nil {
+		return fmt.Errorf("创建服务失败: %w", err)
+	}
+	defer s.Close()
+
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: 5 * time.Second}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, 24*60*60); err != nil {
+		return fmt.Errorf("设置服务恢复策略失败: %w", err)
+	}
+	// 注册事件源，服务无控制台，运行失败时写入 Windows 事件日志；已注册时忽略
+	_ = eventlog.InstallAsEventCreate(ServiceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	return nil
+}
+
+// UninstallService 停止并删除服务
+func UninstallService() error {
+	m, err := connectServiceManager()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("服务未安装")
+	}
+	defer s.Close()
+
+	if err := stopService(s); err != nil {
+		return err
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("删除服务失败: %w", err)
+	}
+	_ = eventlog.Remove(ServiceName)
+	return nil
+}
+
+// StartService 启动服务
+func StartService() error {
+	m, err := connectServiceManager()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("服务未安装")
+	}
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		if errors.Is(err, windows.ERROR_SERVICE_ALREADY_RUNNING) {
+			return nil
+		}
+		return fmt.Errorf("启动服务失败: %w", err)
+	}
+	return waitServiceState(s, svc.Running)
+}
+
+// StopService 停止服务（等待服务关闭所有节点）
+func StopService() error {
+	m, err := connectServiceManager()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("服务未安装")
+	}
+	defer s.Close()
+	return stopService(s)
+}
+
+// QueryService 查询服务状态（只请求查询权限，普通用户也可调用）
+func QueryService() ServiceStatus {
+	status := ServiceStatus{Supported: true, State: ServiceStateNotInstalled}
+
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return status
+	}
+	defer windows.CloseServiceHandle(scm)
+
+	name, err := windows.UTF16PtrFromString(ServiceName)
+	if err != nil {
+		return status
+	}
+	h, err := windows.OpenService(scm, name, windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return status
+	}
+	s := &mgr.Service{Name: ServiceName, Handle: h}
+	defer s.Close()
+
+	status.Installed = true
+	st, err := s.Query()
+	if err != nil {
+		status.State = ServiceStateStopped
+		return status
+	}
+	status.State = serviceStateName(st.State)
+	return status
+}
+
+// IsWindowsService 当前进程是否由服务管理器启动
+func IsWindowsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// RunService 以服务方式运行：run 在服务启动后调用，收到停止请求时取消 ctx，run 返回后服务结束
+func RunService(run func(ctx context.Context)) error {
+	return svc.Run(ServiceName, &serviceHandler{run: run})
+}
+
+// ReportServiceError 把服务运行错误写入 Windows 事件日志（服务进程的标准输出无人读取）
+func ReportServiceError(msg string) {
+	elog, err := eventlog.Open(ServiceName)
+	if err != nil {
+		return
+	}
+	defer elog.Close()
+	elog.Error(serviceEventID, msg)
+}
+
+// serviceHandler 响应服务管理器的控制请求
+type serviceHandler struct {
+	run func(ctx context.Context)
+}
+
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.run(ctx)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case <-done:
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
+
+// connectServiceManager 以完全权限连接服务管理器
+func connectServiceManager() (*mgr.Mgr, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("连接服务管理器失败（需要管理员权限）: %w", err)
+	}
+	return m, nil
+}
+
+// stopService 发送停止请求并等待服务停止
+func stopService(s *mgr.Service) error {
+	st, err := s.Query()
+	if err != nil {
+		return fmt.Errorf("查询服务状态失败: %w", err)
+	}
+	if st.State == svc.Stopped {
+		return nil
+	}
+	if st.State != svc.StopPending {
+		if _, err := s.Control(svc.Stop); err != nil {
+			return fmt.Errorf("停止服务失败: %w", err)
+		}
+	}
+	return waitServiceState(s, svc.Stopped)
+}
+
+// waitServiceState 等待服务进入指定状态
+func waitServiceState(s *mgr.Service, want svc.State) error {
+	deadline := time.Now().Add(serviceStopTimeout)
+	for {
+		st, err := s.Query()
+		if err != nil {
+			return fmt.Errorf("查询服务状态失败: %w", err)
+		}
+		if st.State == want {
+			return nil
+		}
+		if want == svc.Running && st.State == svc.Stopped {
+			return fmt.Errorf("服务启动后立即退出，请查看服务数据目录中的日志")
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待服务%s超时", serviceStateText(want))
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+}
+
+func serviceStateName(state svc.State) string {
+	switch state {
+	case svc.Running:
+		return ServiceStateRunning
+	case svc.StartPending:
+		return ServiceStateStarting
+	case svc.StopPending:
+		return ServiceStateStopping
+	case svc.Paused, svc.PausePending, svc.ContinuePending:
+		return ServiceStatePaused
+	default:
+		return ServiceStateStopped
+	}
+}
+
+func serviceStateText(state svc.State) string {
+	if state == svc.Running {
+		return "启动"
+	}
+	return "停止"
+}
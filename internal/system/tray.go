@@ -1,6 +1,7 @@
 package system
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -9,23 +10,29 @@ import (
 // =============================================================================
 
 // TrayManager 系统托盘管理器
+// 菜单在每次弹出时由 menuItems 重新构建，调用方只需维护菜单项结构
 type TrayManager struct {
-	mu          sync.RWMutex
-	isVisible   bool
-	tooltip     string
-	menuItems   []TrayMenuItem
-	onClick     func()
-	onDblClick  func()
+	mu         sync.RWMutex
+	isVisible  bool
+	tooltip    string
+	menuItems  []TrayMenuItem
+	onClick    func()
+	onDblClick func()
+	onMenuOpen func()
+
+	// 平台托盘图标（未启动时为 nil）
+	native *nativeTray
 }
 
 // TrayMenuItem 托盘菜单项
 type TrayMenuItem struct {
-	ID       string
-	Label    string
-	Enabled  bool
-	Checked  bool
-	OnClick  func()
-	SubMenu  []TrayMenuItem
+	ID        string
+	Label     string
+	Enabled   bool
+	Checked   bool
+	Separator bool // 分隔线（忽略其他字段）
+	OnClick   func()
+	SubMenu   []TrayMenuItem
 }
 
 // NewTrayManager 创建托盘管理器
@@ -36,11 +43,60 @@ func NewTrayManager() *TrayManager {
 	}
 }
 
+// Start 创建托盘图标（Windows），其他平台返回错误
+func (t *TrayManager) Start() error {
+	t.mu.Lock()
+	if t.native != nil {
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+
+	native, err := startNativeTray(t)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.native = native
+	visible := t.isVisible
+	t.mu.Unlock()
+
+	if !visible {
+		native.setVisible(false)
+	}
+	return nil
+}
+
+// Stop 移除托盘图标
+func (t *TrayManager) Stop() {
+	t.mu.Lock()
+	native := t.native
+	t.native = nil
+	t.mu.Unlock()
+
+	if native != nil {
+		native.stop()
+	}
+}
+
+// Running 托盘图标是否已创建
+func (t *TrayManager) Running() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.native != nil
+}
+
 // SetTooltip 设置托盘提示文字
 func (t *TrayManager) SetTooltip(tooltip string) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 	t.tooltip = tooltip
+	native := t.native
+	t.mu.Unlock()
+
+	if native != nil {
+		native.setTooltip(tooltip)
+	}
 }
 
 // SetMenuItems 设置菜单项
@@ -64,31 +120,89 @@ func (t *TrayManager) SetOnDoubleClick(handler func()) {
 	t.onDblClick = handler
 }
 
-// Show 显示托盘图标
-func (t *TrayManager) Show() {
+// SetOnMenuOpen 设置菜单弹出前的回调（可在此刷新菜单项）
+func (t *TrayManager) SetOnMenuOpen(handler func()) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.isVisible = true
+	t.onMenuOpen = handler
+}
+
+// Show 显示托盘图标
+func (t *TrayManager) Show() {
+	t.setVisible(true)
 }
 
 // Hide 隐藏托盘图标
 func (t *TrayManager) Hide() {
+	t.setVisible(false)
+}
+
+func (t *TrayManager) setVisible(visible bool) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-	t.isVisible = false
+	t.isVisible = visible
+	native := t.native
+	t.mu.Unlock()
+
+	if native != nil {
+		native.setVisible(visible)
+	}
+}
+
+// ShowBalloon 在托盘图标上显示气泡通知
+func (t *TrayManager) ShowBalloon(title, message string) error {
+	t.mu.RLock()
+	native := t.native
+	visible := t.isVisible
+	t.mu.RUnlock()
+
+	if native == nil || !visible {
+		return fmt.Errorf("托盘图标未显示")
+	}
+	return native.showBalloon(title, message)
 }
 
 // UpdateStatus 更新状态图标
 func (t *TrayManager) UpdateStatus(isRunning bool, nodeCount int) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
+	tooltip := "Xlink 客户端 - 已停止"
 	if isRunning {
-		t.tooltip = "Xlink 客户端 - 运行中"
+		tooltip = "Xlink 客户端 - 运行中"
 		if nodeCount > 0 {
-			t.tooltip = "Xlink 客户端 - " + string(rune(nodeCount)) + " 个节点运行中"
+			tooltip = fmt.Sprintf("Xlink 客户端 - %d 个节点运行中", nodeCount)
 		}
-	} else {
-		t.tooltip = "Xlink 客户端 - 已停止"
 	}
+	t.SetTooltip(tooltip)
+}
+
+// snapshot 读取当前提示文字和菜单项（供平台实现使用）
+func (t *TrayManager) snapshot() (string, []TrayMenuItem) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tooltip, t.menuItems
+}
+
+// handleClick 处理单击/双击
+func (t *TrayManager) handleClick(double bool) {
+	t.mu.RLock()
+	handler := t.onClick
+	if double {
+		handler = t.onDblClick
+	}
+	t.mu.RUnlock()
+
+	if handler != nil {
+		go handler()
+	}
+}
+
+// menuOpening 菜单弹出前刷新菜单项
+func (t *TrayManager) menuOpening() []TrayMenuItem {
+	t.mu.RLock()
+	handler := t.onMenuOpen
+	t.mu.RUnlock()
+
+	if handler != nil {
+		handler()
+	}
+	_, items := t.snapshot()
+	return items
 }
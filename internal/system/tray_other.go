@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import "fmt"
+
+// nativeTray 非 Windows 平台没有托盘实现
+type nativeTray struct{}
+
+func startNativeTray(t *TrayManager) (*nativeTray, error) {
+	return nil, fmt.Errorf("仅 Windows 支持系统托盘")
+}
+
+func (n *nativeTray) stop()                     {}
+func (n *nativeTray) setTooltip(tooltip string) {}
+func (n *nativeTray) setVisible(visible bool)   {}
+
+func (n *nativeTray) showBalloon(title, message string) error {
+	return fmt.Errorf("仅 Windows 支持系统托盘")
+}
@@ -0,0 +1,464 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// =============================================================================
+// Windows 托盘图标 (Shell_NotifyIcon)
+// =============================================================================
+
+// 托盘图标由一个隐藏窗口承载，窗口和消息循环运行在独占的系统线程上。
+// 右键菜单在弹出时按 TrayMenuItem 重新构建，TrackPopupMenu 返回所选命令后执行 OnClick。
+
+var (
+	modUser32Tray  = windows.NewLazySystemDLL("user32.dll")
+	modShell32Tray = windows.NewLazySystemDLL("shell32.dll")
+
+	procRegisterClassExW       = modUser32Tray.NewProc("RegisterClassExW")
+	procCreateWindowExW        = modUser32Tray.NewProc("CreateWindowExW")
+	procDestroyWindow          = modUser32Tray.NewProc("DestroyWindow")
+	procDefWindowProcW         = modUser32Tray.NewProc("DefWindowProcW")
+	procGetMessageW            = modUser32Tray.NewProc("GetMessageW")
+	procTranslateMessage       = modUser32Tray.NewProc("TranslateMessage")
+	procDispatchMessageW       = modUser32Tray.NewProc("DispatchMessageW")
+	procPostMessageW           = modUser32Tray.NewProc("PostMessageW")
+	procPostQuitMessage        = modUser32Tray.NewProc("PostQuitMessage")
+	procRegisterWindowMessageW = modUser32Tray.NewProc("RegisterWindowMessageW")
+	procCreatePopupMenu        = modUser32Tray.NewProc("CreatePopupMenu")
+	procAppendMenuW            = modUser32Tray.NewProc("AppendMenuW")
+	procTrackPopupMenu         = modUser32Tray.NewProc("TrackPopupMenu")
+	procDestroyMenu            = modUser32Tray.NewProc("DestroyMenu")
+	procGetCursorPos           = modUser32Tray.NewProc("GetCursorPos")
+	procSetForegroundWindow    = modUser32Tray.NewProc("SetForegroundWindow")
+	procLoadIconW              = modUser32Tray.NewProc("LoadIconW")
+	procDestroyIcon            = modUser32Tray.NewProc("DestroyIcon")
+	procShellNotifyIconW       = modShell32Tray.NewProc("Shell_NotifyIconW")
+	procExtractIconW           = modShell32Tray.NewProc("ExtractIconW")
+)
+
+const (
+	wmNull          = 0x0000
+	wmDestroy       = 0x0002
+	wmClose         = 0x0010
+	wmLButtonUp     = 0x0202
+	wmLButtonDblClk = 0x0203
+	wmRButtonUp     = 0x0205
+	wmTrayCallback  = 0x8000 + 1 // WM_APP + 1
+
+	nimAdd    = 0x0
+	nimModify = 0x1
+	nimDelete = 0x2
+
+	nifMessage = 0x1
+	nifIcon    = 0x2
+	nifTip     = 0x4
+	nifInfo    = 0x10
+
+	niifInfo = 0x1
+
+	mfString    = 0x0
+	mfGrayed    = 0x1
+	mfChecked   = 0x8
+	mfPopup     = 0x10
+	mfSeparator = 0x800
+
+	tpmRightButton = 0x2
+	tpmNoNotify    = 0x80
+	tpmReturnCmd   = 0x100
+
+	idiApplication = 32512
+
+	// errorClassAlreadyExists 托盘重新启动时窗口类已注册
+	errorClassAlreadyExists = 1410
+
+	trayWindowClass = "XlinkTrayWindow"
+	trayStopTimeout = 2 * time.Second
+)
+
+// notifyIconData NOTIFYICONDATAW
+type notifyIconData struct {
+	Size             uint32
+	Wnd              windows.HWND
+	ID               uint32
+	Flags            uint32
+	CallbackMessage  uint32
+	Icon             windows.Handle
+	Tip              [128]uint16
+	State            uint32
+	StateMask        uint32
+	Info             [256]uint16
+	TimeoutOrVersion uint32
+	InfoTitle        [64]uint16
+	InfoFlags        uint32
+	GUIDItem         windows.GUID
+	BalloonIcon      windows.Handle
+}
+
+// wndClassEx WNDCLASSEXW
+type wndClassEx struct {
+	Size       uint32
+	Style      uint32
+	WndProc    uintptr
+	ClsExtra   int32
+	WndExtra   int32
+	Instance   windows.Handle
+	Icon       windows.Handle
+	Cursor     windows.Handle
+	Background windows.Handle
+	MenuName   *uint16
+	ClassName  *uint16
+	IconSm     windows.Handle
+}
+
+// winMsg MSG
+type winMsg struct {
+	Hwnd    windows.HWND
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      winPoint
+}
+
+type winPoint struct {
+	X, Y int32
+}
+
+var (
+	trayWndProcOnce sync.Once
+	trayWndProcPtr  uintptr
+
+	// currentTray 正在运行的托盘（只在托盘线程上读写）
+	currentTray *nativeTray
+)
+
+// nativeTray Windows 托盘图标
+type nativeTray struct {
+	owner *TrayManager
+
+	hwnd           windows.HWND
+	icon           windows.Handle
+	ownIcon        bool // 图标由 ExtractIcon 创建，退出时需要销毁
+	taskbarCreated uint32
+
+	mu      sync.Mutex
+	visible bool
+	added   bool
+
+	done chan struct{}
+}
+
+// startNativeTray 在独立线程上创建隐藏窗口和托盘图标
+func startNativeTray(t *TrayManager) (*nativeTray, error) {
+	n := &nativeTray{owner: t, visible: true, done: make(chan struct{})}
+	ready := make(chan error, 1)
+	go n.run(ready)
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// run 创建窗口并运行消息循环（窗口销毁后返回）
+func (n *nativeTray) run(ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(n.done)
+
+	var instance windows.Handle
+	if err := windows.GetModuleHandleEx(0, nil, &instance); err != nil {
+		ready <- fmt.Errorf("获取模块句柄失败: %w", err)
+		return
+	}
+
+	trayWndProcOnce.Do(func() {
+		trayWndProcPtr = windows.NewCallback(trayWndProc)
+	})
+
+	className, _ := windows.UTF16PtrFromString(trayWindowClass)
+	wc := wndClassEx{
+		WndProc:   trayWndProcPtr,
+		Instance:  instance,
+		ClassName: className,
+	}
+	wc.Size = uint32(unsafe.Sizeof(wc))
+	if r, _, e := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))); r == 0 && e != windows.Errno(errorClassAlreadyExists) {
+		ready <- fmt.Errorf("注册托盘窗口类失败: %v", e)
+		return
+	}
+
+	currentTray = n
+	title, _ := windows.UTF16PtrFromString("Xlink Tray")
+	hwnd, _, e := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(className)), uintptr(unsafe.Pointer(title)),
+		0, 0, 0, 0, 0, 0, 0, uintptr(instance), 0)
+	if hwnd == 0 {
+		ready <- fmt.Errorf("创建托盘窗口失败: %v", e)
+		return
+	}
+	n.hwnd = windows.HWND(hwnd)
+
+	// 资源管理器重启后任务栏会广播此消息，需要重新添加图标
+	taskbarCreated, _ := windows.UTF16PtrFromString("TaskbarCreated")
+	r, _, _ := procRegisterWindowMessageW.Call(uintptr(unsafe.Pointer(taskbarCreated)))
+	n.taskbarCreated = uint32(r)
+
+	n.icon, n.ownIcon = loadTrayIcon(instance)
+
+	n.mu.Lock()
+	err := n.addLocked()
+	n.mu.Unlock()
+	if err != nil {
+		procDestroyWindow.Call(hwnd)
+		ready <- err
+		return
+	}
+	ready <- nil
+
+	var m winMsg
+	for {
+		r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(r) <= 0 {
+			break
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&m)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+	}
+
+	if n.ownIcon {
+		procDestroyIcon.Call(uintptr(n.icon))
+	}
+}
+
+// trayWndProc 托盘窗口过程
+func trayWndProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
+	n := currentTray
+	if n == nil || uintptr(n.hwnd) != hwnd {
+		r, _, _ := procDefWindowProcW.Call(hwnd, uintptr(msg), wParam, lParam)
+		return r
+	}
+
+	switch msg {
+	case wmTrayCallback:
+		switch lParam & 0xffff {
+		case wmLButtonUp:
+			n.owner.handleClick(false)
+		case wmLButtonDblClk:
+			n.owner.handleClick(true)
+		case wmRButtonUp:
+			n.showMenu()
+		}
+		return 0
+	case wmClose:
+		procDestroyWindow.Call(hwnd)
+		return 0
+	case wmDestroy:
+		n.mu.Lock()
+		n.deleteLocked()
+		n.mu.Unlock()
+		procPostQuitMessage.Call(0)
+		return 0
+	}
+
+	if msg == n.taskbarCreated && n.taskbarCreated != 0 {
+		n.mu.Lock()
+		n.added = false
+		if n.visible {
+			n.addLocked()
+		}
+		n.mu.Unlock()
+		return 0
+	}
+
+	r, _, _ := procDefWindowProcW.Call(hwnd, uintptr(msg), wParam, lParam)
+	return r
+}
+
+// showMenu 构建并弹出右键菜单，执行所选菜单项
+func (n *nativeTray) showMenu() {
+	items := n.owner.menuOpening()
+	if len(items) == 0 {
+		return
+	}
+
+	commands := make(map[uintptr]func())
+	nextID := uintptr(1)
+	menu := buildTrayMenu(items, commands, &nextID)
+	defer procDestroyMenu.Call(menu)
+
+	var pt winPoint
+	procGetCursorPos.Call(uintptr(unsafe.Pointer(&pt)))
+
+	// 必须先置前，否则点击菜单外部时菜单不会关闭
+	procSetForegroundWindow.Call(uintptr(n.hwnd))
+	cmd, _, _ := procTrackPopupMenu.Call(menu, tpmRightButton|tpmNoNotify|tpmReturnCmd,
+		uintptr(pt.X), uintptr(pt.Y), 0, uintptr(n.hwnd), 0)
+	procPostMessageW.Call(uintptr(n.hwnd), wmNull, 0, 0)
+
+	if handler := commands[cmd]; handler != nil {
+		go handler()
+	}
+}
+
+// buildTrayMenu 递归创建弹出菜单，为每个可点击项分配命令ID
+func buildTrayMenu(items []TrayMenuItem, commands map[uintptr]func(), nextID *uintptr) uintptr {
+	menu, _, _ := procCreatePopupMenu.Call()
+
+	for _, item := range items {
+		if item.Separator {
+			procAppendMenuW.Call(menu, mfSeparator, 0, 0)
+			continue
+		}
+
+		flags := uintptr(mfString)
+		if !item.Enabled {
+			flags |= mfGrayed
+		}
+		if item.Checked {
+			flags |= mfChecked
+		}
+		label, err := windows.UTF16PtrFromString(item.Label)
+		if err != nil {
+			continue
+		}
+
+		if len(item.SubMenu) > 0 {
+			sub := buildTrayMenu(item.SubMenu, commands, nextID)
+			procAppendMenuW.Call(menu, flags|mfPopup, sub, uintptr(unsafe.Pointer(label)))
+			continue
+		}
+
+		id := *nextID
+		*nextID++
+		if item.OnClick != nil {
+			commands[id] = item.OnClick
+		}
+		procAppendMenuW.Call(menu, flags, id, uintptr(unsafe.Pointer(label)))
+	}
+	return menu
+}
+
+// stop 销毁窗口（同时移除图标）并等待消息循环退出
+func (n *nativeTray) stop() {
+	procPostMessageW.Call(uintptr(n.hwnd), wmClose, 0, 0)
+	select {
+	case <-n.done:
+	case <-time.After(trayStopTimeout):
+	}
+}
+
+// setTooltip 更新提示文字
+func (n *nativeTray) setTooltip(tooltip string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.added {
+		return
+	}
+	data := n.iconData(nifTip, tooltip)
+	procShellNotifyIconW.Call(nimModify, uintptr(unsafe.Pointer(data)))
+}
+
+// showBalloon 显示气泡通知（标题最多 63 个字符，内容最多 255 个字符）
+func (n *nativeTray) showBalloon(title, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.added {
+		return fmt.Errorf("托盘图标未显示")
+	}
+
+	data := n.iconData(nifInfo, "")
+	data.InfoFlags = niifInfo
+	copyUTF16(data.InfoTitle[:], title)
+	copyUTF16(data.Info[:], message)
+	if r, _, e := procShellNotifyIconW.Call(nimModify, uintptr(unsafe.Pointer(data))); r == 0 {
+		return fmt.Errorf("显示托盘通知失败: %v", e)
+	}
+	return nil
+}
+
+// setVisible 显示或移除图标
+func (n *nativeTray) setVisible(visible bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.visible = visible
+	if visible {
+		n.addLocked()
+	} else {
+		n.deleteLocked()
+	}
+}
+
+// addLocked 添加图标（需持有 mu）
+func (n *nativeTray) addLocked() error {
+	if n.added {
+		return nil
+	}
+	tooltip, _ := n.owner.snapshot()
+	data := n.iconData(nifMessage|nifIcon|nifTip, tooltip)
+	if r, _, e := procShellNotifyIconW.Call(nimAdd, uintptr(unsafe.Pointer(data))); r == 0 {
+		return fmt.Errorf("添加托盘图标失败: %v", e)
+	}
+	n.added = true
+	return nil
+}
+
+// deleteLocked 移除图标（需持有 mu）
+func (n *nativeTray) deleteLocked() {
+	if !n.added {
+		return
+	}
+	data := n.iconData(0, "")
+	procShellNotifyIconW.Call(nimDelete, uintptr(unsafe.Pointer(data)))
+	n.added = false
+}
+
+// iconData 构建 NOTIFYICONDATAW（提示文字超过 127 个字符时截断）
+func (n *nativeTray) iconData(flags uint32, tooltip string) *notifyIconData {
+	data := &notifyIconData{
+		Wnd:             n.hwnd,
+		ID:              1,
+		Flags:           flags,
+		CallbackMessage: wmTrayCallback,
+		Icon:            n.icon,
+	}
+	data.Size = uint32(unsafe.Sizeof(*data))
+
+	copyUTF16(data.Tip[:], tooltip)
+	return data
+}
+
+// copyUTF16 把字符串写入定长 UTF-16 缓冲区，超长时截断并保留结尾的 0
+func copyUTF16(dst []uint16, s string) {
+	src, err := windows.UTF16FromString(s)
+	if err != nil {
+		return
+	}
+	if len(src) > len(dst) {
+		src = append(src[:len(dst)-1], 0)
+	}
+	copy(dst, src)
+}
+
+// loadTrayIcon 使用程序自身的图标，失败时使用系统默认应用图标
+func loadTrayIcon(instance windows.Handle) (windows.Handle, bool) {
+	if exe, err := os.Executable(); err == nil {
+		if path, err := windows.UTF16PtrFromString(exe); err == nil {
+			icon, _, _ := procExtractIconW.Call(uintptr(instance), uintptr(unsafe.Pointer(path)), 0)
+			if icon > 1 {
+				return windows.Handle(icon), true
+			}
+		}
+	}
+	icon, _, _ := procLoadIconW.Call(0, idiApplication)
+	return windows.Handle(icon), false
+}
@@ -0,0 +1,227 @@
+package system
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// =============================================================================
+// 界面数据目录（WebView2 用户数据）维护
+// =============================================================================
+
+// WebviewDataDirName 界面数据目录名
+const WebviewDataDirName = "webview_data"
+
+// webviewCleanMarker 待清理标记：界面运行时缓存文件被 WebView2 占用，清理在下次启动、界面打开前进行
+const webviewCleanMarker = ".clean-pending"
+
+// webviewCacheDirs 可安全删除的缓存目录（Local Storage、IndexedDB 等界面数据不在其中）
+var webviewCacheDirs = map[string]bool{
+	"Cache":               true,
+	"Code Cache":          true,
+	"GPUCache":            true,
+	"DawnCache":           true,
+	"DawnGraphiteCache":   true,
+	"DawnWebGPUCache":     true,
+	"GraphiteDawnCache":   true,
+	"ShaderCache":         true,
+	"GrShaderCache":       true,
+	"CacheStorage":        true,
+	"ScriptCache":         true,
+	"component_crx_cache": true,
+}
+
+// WebviewDataUsage 界面数据目录占用
+type WebviewDataUsage struct {
+	Path         string `json:"path"`
+	TotalBytes   int64  `json:"total_bytes"`
+	CacheBytes   int64  `json:"cache_bytes"` // 可清理的缓存
+	CleanPending bool   `json:"clean_pending"`
+}
+
+// WebviewPrepareResult 启动时整理界面数据目录的结果
+type WebviewPrepareResult struct {
+	Dir        string // 本次使用的目录
+	MovedFrom  string // 从该目录迁移而来
+	FreedBytes int64  // 清理缓存释放的空间
+	Err        error  // 迁移或清理失败（仍可使用 Dir）
+}
+
+// WebviewDataDir 界面数据目录：数据目录下，或本机用户数据目录（Windows 为 %LOCALAPPDATA%）下
+func WebviewDataDir(dataDir string, inUserDir bool) string {
+	if inUserDir {
+		if base, err := os.UserCacheDir(); err == nil {
+			return filepath.Join(base, "XlinkClient", WebviewDataDirName)
+		}
+	}
+	return filepath.Join(dataDir, WebviewDataDirName)
+}
+
+// PrepareWebviewData 在界面打开前整理界面数据目录：位置变化时迁移旧目录，有待清理标记时清理缓存
+func PrepareWebviewData(dataDir string, inUserDir bool) *WebviewPrepareResult {
+	dir := WebviewDataDir(dataDir, inUserDir)
+	result := &WebviewPrepareResult{Dir: dir}
+
+	old := WebviewDataDir(dataDir, !inUserDir)
+	if old != dir && dirExists(old) && !dirExists(dir) {
+		if err := moveDir(old, dir); err != nil {
+			// 迁移失败时继续使用旧目录，避免丢失界面设置
+			result.Dir = old
+			result.Err = fmt.Errorf("迁移界面数据目录失败: %w", err)
+			return result
+		}
+		result.MovedFrom = old
+	}
+
+	if fileExists(filepath.Join(result.Dir, webviewCleanMarker)) {
+		freed, err := clearWebviewCache(result.Dir)
+		result.FreedBytes = freed
+		if err != nil {
+			result.Err = fmt.Errorf("清理界面缓存失败: %w", err)
+		}
+		os.Remove(filepath.Join(result.Dir, webviewCleanMarker))
+	}
+	return result
+}
+
+// GetWebviewDataUsage 统计界面数据目录及其中缓存的大小
+func GetWebviewDataUsage(dir string) WebviewDataUsage {
+	usage := WebviewDataUsage{
+		Path:         dir,
+		CleanPending: fileExists(filepath.Join(dir, webviewCleanMarker)),
+	}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		usage.TotalBytes += info.Size()
+		if inWebviewCache(dir, path) {
+			usage.CacheBytes += info.Size()
+		}
+		return nil
+	})
+	return usage
+}
+
+// ScheduleWebviewClean 标记在下次启动时清理界面缓存
+func ScheduleWebviewClean(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, webviewCleanMarker), nil, 0644)
+}
+
+// clearWebviewCache 删除缓存目录，返回释放的空间；单个目录删除失败时继续清理其他目录
+func clearWebviewCache(dir string) (int64, error) {
+	var caches []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && webviewCacheDirs[d.Name()] {
+			caches = append(caches, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	var freed int64
+	var firstErr error
+	for _, cache := range caches {
+		size := dirSize(cache)
+		if err := os.RemoveAll(cache); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			size -= dirSize(cache)
+		}
+		freed += size
+	}
+	return freed, firstErr
+}
+
+// inWebviewCache 文件是否位于缓存目录中
+func inWebviewCache(root, path string) bool {
+	for p := filepath.Dir(path); len(p) > len(root); p = filepath.Dir(p) {
+		if webviewCacheDirs[filepath.Base(p)] {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize 目录中文件的总大小
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// moveDir 移动目录，不在同一磁盘时复制后删除原目录
+func moveDir(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	if err := copyDir(from, to); err != nil {
+		os.RemoveAll(to)
+		return err
+	}
+	return os.RemoveAll(from)
+}
+
+// copyDir 递归复制目录
+func copyDir(from, to string) error {
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(from, path)
+		target := filepath.Join(to, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+// copyFile 复制单个文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,235 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// =============================================================================
+// WSL2 / Docker Desktop 代理辅助
+// =============================================================================
+
+// WSL2 默认使用 NAT 网络，发行版内的 localhost 指向虚拟机自身，
+// 无法访问宿主机 127.0.0.1 上的代理端口。这里负责探测宿主机地址并生成/写入代理环境。
+
+const (
+	wslProfileMarkBegin = "# >>> xlink proxy >>>"
+	wslProfileMarkEnd   = "# <<< xlink proxy <<<"
+	dockerHostName      = "host.docker.internal"
+)
+
+// WSLProxyGuide WSL/Docker 代理配置指引
+type WSLProxyGuide struct {
+	HostIP       string            `json:"host_ip"`       // WSL 虚拟网卡上的宿主机地址
+	Mirrored     bool              `json:"mirrored"`      // .wslconfig 是否启用了 mirrored 网络
+	Distros      []string          `json:"distros"`       // 已安装的发行版
+	ProxyURL     string            `json:"proxy_url"`     // WSL 内使用的代理地址
+	ShellExports []string          `json:"shell_exports"` // 可直接粘贴到 shell 的 export 语句
+	DockerProxy  map[string]string `json:"docker_proxy"`  // ~/.docker/config.json 中 proxies.default 的内容
+	Warnings     []string          `json:"warnings,omitempty"`
+}
+
+// BuildWSLProxyGuide 生成 WSL/Docker 代理指引
+// listenHost/socksPort 为节点监听地址，httpPort 为 HTTP 入站端口（0 表示没有）
+func BuildWSLProxyGuide(listenHost string, socksPort, httpPort int) *WSLProxyGuide {
+	guide := &WSLProxyGuide{
+		Mirrored: isWSLMirrored(),
+	}
+	guide.Distros, _ = ListWSLDistros()
+
+	host := DetectWSLHostIP()
+	if guide.Mirrored {
+		// mirrored 模式下 WSL 与宿主机共享 localhost
+		host = "127.0.0.1"
+	}
+	guide.HostIP = host
+
+	if host == "" {
+		guide.Warnings = append(guide.Warnings, "未找到 WSL 虚拟网卡，请确认已安装并启动过 WSL2")
+		host = "<宿主机IP>"
+	}
+
+	if !guide.Mirrored && isLoopbackHost(listenHost) {
+		guide.Warnings = append(guide.Warnings,
+			fmt.Sprintf("节点监听在 %s，WSL2/Docker 无法访问；请改为 0.0.0.0 或在 .wslconfig 中设置 networkingMode=mirrored", listenHost))
+	}
+
+	socksURL := fmt.Sprintf("socks5h://%s:%d", host, socksPort)
+	httpURL := socksURL
+	if httpPort > 0 {
+		httpURL = fmt.Sprintf("http://%s:%d", host, httpPort)
+	} else {
+		guide.Warnings = append(guide.Warnings, "当前节点没有 HTTP 入站，部分工具（如 apt）不支持 SOCKS 代理")
+	}
+	guide.ProxyURL = httpURL
+
+	noProxy := "localhost,127.0.0.1,::1," + dockerHostName
+	guide.ShellExports = []string{
+		"export HTTP_PROXY=" + httpURL,
+		"export HTTPS_PROXY=" + httpURL,
+		"export ALL_PROXY=" + socksURL,
+		"export http_proxy=" + httpURL,
+		"export https_proxy=" + httpURL,
+		"export all_proxy=" + socksURL,
+		"export NO_PROXY=" + noProxy,
+		"export no_proxy=" + noProxy,
+	}
+
+	// Docker Desktop 容器内通过 host.docker.internal 访问宿主机
+	dockerURL := strings.Replace(httpURL, host, dockerHostName, 1)
+	guide.DockerProxy = map[string]string{
+		"httpProxy":  dockerURL,
+		"httpsProxy": dockerURL,
+		"noProxy":    "localhost,127.0.0.1",
+	}
+
+	return guide
+}
+
+// DetectWSLHostIP 获取 "vEthernet (WSL)" 网卡上的宿主机 IPv4 地址
+func DetectWSLHostIP() string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+
+	for _, iface := range interfaces {
+		if !strings.Contains(strings.ToLower(iface.Name), "wsl") {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+				return ipnet.IP.String()
+			}
+		}
+	}
+	return ""
+}
+
+// ListWSLDistros 列出已安装的 WSL 发行版
+func ListWSLDistros() ([]string, error) {
+	if runtime.GOOS != "windows" {
+		return nil, fmt.Errorf("仅支持Windows")
+	}
+
+	output, err := exec.Command("wsl.exe", "--list", "--quiet").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var distros []string
+	for _, line := range strings.Split(decodeWSLOutput(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			distros = append(distros, line)
+		}
+	}
+	return distros, nil
+}
+
+// ApplyWSLProxy 将代理环境写入指定发行版的 ~/.profile（重复执行会替换旧的配置块）
+func ApplyWSLProxy(distro string, guide *WSLProxyGuide) error {
+	if runtime.GOOS != "windows" {
+		return fmt.Errorf("仅支持Windows")
+	}
+	if distro == "" {
+		return fmt.Errorf("未指定发行版")
+	}
+
+	block := wslProfileMarkBegin + "\n" + strings.Join(guide.ShellExports, "\n") + "\n" + wslProfileMarkEnd
+	script := fmt.Sprintf(
+		`f="$HOME/.profile"; touch "$f"; sed -i '/%s/,/%s/d' "$f"; printf '%%s\n' '%s' >> "$f"`,
+		wslProfileMarkBegin, wslProfileMarkEnd, block,
+	)
+
+	cmd := exec.Command("wsl.exe", "-d", distro, "--", "sh", "-c", script)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v %s", distro, err, decodeWSLOutput(output))
+	}
+	return nil
+}
+
+// ApplyDockerProxy 将代理写入当前用户的 ~/.docker/config.json（保留其他字段）
+func ApplyDockerProxy(guide *WSLProxyGuide) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(home, ".docker", "config.json")
+	cfg := make(map[string]interface{})
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("解析 Docker 配置失败: %w", err)
+		}
+	}
+
+	proxies, _ := cfg["proxies"].(map[string]interface{})
+	if proxies == nil {
+		proxies = make(map[string]interface{})
+	}
+	proxies["default"] = guide.DockerProxy
+	cfg["proxies"] = proxies
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// isWSLMirrored 检查 %USERPROFILE%\.wslconfig 是否启用 mirrored 网络
+func isWSLMirrored() bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".wslconfig"))
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.ToLower(strings.ReplaceAll(line, " ", ""))
+		if strings.HasPrefix(line, "networkingmode=mirrored") {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackHost 判断监听地址是否只绑定回环
+func isLoopbackHost(host string) bool {
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// decodeWSLOutput wsl.exe 输出为 UTF-16LE，这里转换为普通字符串
+func decodeWSLOutput(b []byte) string {
+	if len(b) < 2 || b[1] != 0 {
+		return string(b)
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])|uint16(b[i+1])<<8)
+	}
+	return strings.TrimPrefix(string(utf16.Decode(u)), "\ufeff")
+}
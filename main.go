@@ -14,6 +14,7 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 
+	"xlink-wails/internal/command"
 	"xlink-wails/internal/models"
 )
 
@@ -37,10 +38,32 @@ func main() {
 	}
 	exeDir := filepath.Dir(exePath)
 
+	// 由服务管理器启动时无界面运行（数据目录为安装服务时的用户数据目录）
+	if dataDir, ok := parseServiceArg(os.Args[1:]); ok {
+		os.Exit(runService(exeDir, dataDir))
+	}
+
+	// 配置、日志等保存在每个用户独立的目录（便携模式下为程序目录）
+	layout := resolveDataLayout(exeDir)
+
+	// 无界面校验配置后直接退出
+	if path, ok := parseValidateArg(os.Args[1:]); ok {
+		os.Exit(runValidate(layout.Dir, path))
+	}
+
+	// 无界面查询运行中的程序的节点状态后直接退出（不启动界面，也不转发给已运行的实例）
+	if nodeRef, ok, err := parseStatusArg(os.Args[1:]); ok {
+		os.Exit(runStatus(layout.Dir, nodeRef, err))
+	}
+
 	// 创建应用实例
 	app := NewApp()
 	app.state.ExeDir = exeDir
+	app.state.DataDir = layout.Dir
+	app.dataLayout = layout
 	app.state.IsAutoStart = isAutoStart
+	app.pendingCommands = command.ParseArgs(os.Args[1:], command.SourceCLI)
+	app.webview = prepareWebviewData(layout.Dir)
 
 	// 创建 Wails 应用
 	err = wails.Run(&options.App{
@@ -57,21 +80,25 @@ func main() {
 		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 1},
 
 		// 绑定生命周期
-		OnStartup:  app.startup,
-		OnShutdown: app.shutdown,
+		OnStartup:     app.startup,
+		OnShutdown:    app.shutdown,
+		OnBeforeClose: app.beforeClose,
 
 		// 绑定后端方法供前端调用
 		Bind: []interface{}{
 			app,
 		},
 
+		// 后端方法返回的错误按界面语言翻译
+		ErrorFormatter: app.formatError,
+
 		// Windows 特定配置
 		Windows: &windows.Options{
 			WebviewIsTransparent:              false,
 			WindowIsTranslucent:               false,
 			DisableWindowIcon:                 false,
 			DisableFramelessWindowDecorations: false,
-			WebviewUserDataPath:               filepath.Join(exeDir, "webview_data"),
+			WebviewUserDataPath:               app.webview.Dir,
 			Theme:                             windows.SystemDefault,
 		},
 
@@ -79,8 +106,8 @@ func main() {
 		SingleInstanceLock: &options.SingleInstanceLock{
 			UniqueId: "xlink-client-v22-unique-lock",
 			OnSecondInstanceLaunch: func(data options.SecondInstanceData) {
-				// 当第二个实例启动时，唤醒主窗口
-				app.ShowWindow()
+				// 当第二个实例启动时，转发控制命令或唤醒主窗口
+				app.handleSecondInstance(data.Args)
 			},
 		},
 	})